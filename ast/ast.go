@@ -2,12 +2,36 @@ package ast
 
 import (
 	"fmt"
+	"path"
+	"sort"
+	"strconv"
 	"strings" // Added for strings.Join
+	"unicode"
 )
 
 // Node represents any node in the AST
 type Node interface {
 	String() string
+	// Pos returns the location of the node in the source, or the zero
+	// Position for nodes built outside the parser
+	Pos() Position
+}
+
+// Position is a location in Zeno source code. Lines and columns start at 1.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// Pos returns the position itself, so that nodes embedding a Position
+// implement Node.
+func (p Position) Pos() Position { return p }
+
+// IsValid reports whether the position is known
+func (p Position) IsValid() bool { return p.Line > 0 }
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
 }
 
 // Statement represents all statement nodes
@@ -24,7 +48,9 @@ type Expression interface {
 
 // Program represents the root node of the AST
 type Program struct {
+	Position
 	Statements []Statement
+	Comments   []*Comment // All comments in source order, if the lexer kept them
 }
 
 func (p *Program) String() string {
@@ -35,6 +61,79 @@ func (p *Program) String() string {
 	return result
 }
 
+// ModuleLevel reports whether the top-level variables of the program are
+// module-level, seen by all of its functions: whether it declares fn main
+// or has nothing but declarations at the top level. Otherwise its top-level
+// statements are run as the body of main, and their variables are local to
+// it; only consts are module-level then.
+func (p *Program) ModuleLevel() bool {
+	declarations := true
+	for _, stmt := range p.Statements {
+		switch s := stmt.(type) {
+		case *FunctionDefinition:
+			if s.Name == "main" {
+				return true
+			}
+		case *LetDeclaration, *ImportStatement, *TypeDeclaration, *EnumDeclaration, *ImplDeclaration:
+		default:
+			declarations = false
+		}
+	}
+	return declarations
+}
+
+// Exports returns the names of the public functions of the program,
+// including the ones it re-exports
+func (p *Program) Exports() []string {
+	var names []string
+	for _, stmt := range p.Statements {
+		switch s := stmt.(type) {
+		case *FunctionDefinition:
+			if s.IsPublic {
+				names = append(names, s.Name)
+			}
+		case *ImportStatement:
+			if !s.IsPublic {
+				continue
+			}
+			for _, item := range s.Imports {
+				if !item.IsType {
+					names = append(names, item.LocalName())
+				}
+			}
+		}
+	}
+	return names
+}
+
+// Reexport returns the pub import of the program that re-exports name, and
+// its item for name
+func (p *Program) Reexport(name string, isType bool) (*ImportStatement, ImportItem, bool) {
+	for _, stmt := range p.Statements {
+		imp, ok := stmt.(*ImportStatement)
+		if !ok || !imp.IsPublic {
+			continue
+		}
+		for _, item := range imp.Imports {
+			if item.LocalName() == name && item.IsType == isType {
+				return imp, item, true
+			}
+		}
+	}
+	return nil, ImportItem{}, false
+}
+
+// Comment represents a // or /* */ comment. Comments are not part of the
+// statement tree; they are collected in Program.Comments.
+type Comment struct {
+	Position
+	Text string // Comment text including the // or /* */ markers
+}
+
+func (c *Comment) String() string {
+	return c.Text
+}
+
 // BinaryOperator represents binary operators
 type BinaryOperator int
 
@@ -52,6 +151,11 @@ const (
 	BinaryOpGte
 	BinaryOpAnd
 	BinaryOpOr
+	BinaryOpBitAnd
+	BinaryOpBitOr
+	BinaryOpBitXor
+	BinaryOpShiftLeft
+	BinaryOpShiftRight
 )
 
 func (op BinaryOperator) String() string {
@@ -82,11 +186,31 @@ func (op BinaryOperator) String() string {
 		return "&&"
 	case BinaryOpOr:
 		return "||"
+	case BinaryOpBitAnd:
+		return "&"
+	case BinaryOpBitOr:
+		return "|"
+	case BinaryOpBitXor:
+		return "^"
+	case BinaryOpShiftLeft:
+		return "<<"
+	case BinaryOpShiftRight:
+		return ">>"
 	default:
 		return "UNKNOWN"
 	}
 }
 
+// IntegerOnly reports whether op is only defined on integers: %, the
+// bitwise operators and shifts
+func (op BinaryOperator) IntegerOnly() bool {
+	switch op {
+	case BinaryOpModulo, BinaryOpBitAnd, BinaryOpBitOr, BinaryOpBitXor, BinaryOpShiftLeft, BinaryOpShiftRight:
+		return true
+	}
+	return false
+}
+
 // UnaryOperator represents unary operators
 type UnaryOperator int
 
@@ -106,16 +230,62 @@ func (op UnaryOperator) String() string {
 	}
 }
 
+// Destructure is the pattern of a let that takes a value apart
+type Destructure int
+
+const (
+	DestructureTuple  Destructure = iota // let (a, b) = pair
+	DestructureFields                    // let {name, age} = person, a struct or map
+	DestructureArray                     // let [first, second] = items
+)
+
+// Enclose writes names in the brackets of the pattern, e.g. {name, age}
+func (d Destructure) Enclose(names []string) string {
+	list := strings.Join(names, ", ")
+	switch d {
+	case DestructureFields:
+		return "{" + list + "}"
+	case DestructureArray:
+		return "[" + list + "]"
+	default:
+		return "(" + list + ")"
+	}
+}
+
 // LetDeclaration represents let declarations
 type LetDeclaration struct {
-	Name            string
+	Position
+	Name string
+	// Names are the names of a let that takes a value apart with the
+	// pattern Destructure; Name is empty then
+	Names           []string
+	Destructure     Destructure
+	Mutable         bool    // declared with let mut, so it can be reassigned
+	Constant        bool    // declared with const, whose value is known when compiling
 	TypeAnn         *string // allow generic type annotations
 	ValueExpression Expression
 }
 
+// BoundNames returns the names the declaration defines
+func (ld *LetDeclaration) BoundNames() []string {
+	if ld.Names != nil {
+		return ld.Names
+	}
+	return []string{ld.Name}
+}
+
 func (ld *LetDeclaration) statementNode() {}
 func (ld *LetDeclaration) String() string {
-	result := "let " + ld.Name
+	name := ld.Name
+	if ld.Names != nil {
+		name = ld.Destructure.Enclose(ld.Names)
+	}
+	result := "let " + name
+	if ld.Mutable {
+		result = "let mut " + name
+	} else if ld.Constant {
+		result = "const " + name
+	}
 	if ld.TypeAnn != nil {
 		result += ": " + *ld.TypeAnn
 	}
@@ -125,6 +295,7 @@ func (ld *LetDeclaration) String() string {
 
 // AssignmentStatement represents assignment statements (x = value)
 type AssignmentStatement struct {
+	Position
 	Name  string     // Variable name being assigned to
 	Value Expression // Value being assigned
 }
@@ -136,6 +307,7 @@ func (as *AssignmentStatement) String() string {
 
 // ExpressionStatement represents expression statements
 type ExpressionStatement struct {
+	Position
 	Expression Expression
 }
 
@@ -146,38 +318,72 @@ func (es *ExpressionStatement) String() string {
 
 // IntegerLiteral represents integer literals
 type IntegerLiteral struct {
+	Position
+	// Value holds the bits of the value for a u64 literal, which may be
+	// above the largest int
 	Value int
+	// Suffix is the sized integer type the literal ends with, as in 10u64,
+	// empty for an int
+	Suffix string
 }
 
 func (il *IntegerLiteral) expressionNode() {}
 func (il *IntegerLiteral) String() string {
-	return fmt.Sprintf("%d", il.Value)
+	if il.Suffix == "u64" {
+		return fmt.Sprintf("%du64", uint64(il.Value))
+	}
+	return fmt.Sprintf("%d%s", il.Value, il.Suffix)
 }
 
 // FloatLiteral represents float literals
 type FloatLiteral struct {
+	Position
 	Value float64
 }
 
 func (fl *FloatLiteral) expressionNode() {}
 func (fl *FloatLiteral) String() string {
-	// Use a general format that avoids trailing zeros for whole numbers
-	// and preserves precision. %g is often good for this.
-	return fmt.Sprintf("%g", fl.Value)
+	// Keep a decimal point so that whole numbers still read as floats
+	s := strconv.FormatFloat(fl.Value, 'f', -1, 64)
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	return s
 }
 
 // StringLiteral represents string literals
 type StringLiteral struct {
-	Value string
+	Position
+	Value string // Value with escape sequences resolved
+	Raw   string // Source text between the quotes, empty for synthesized literals
 }
 
 func (sl *StringLiteral) expressionNode() {}
 func (sl *StringLiteral) String() string {
+	if sl.Raw != "" {
+		return "\"" + sl.Raw + "\""
+	}
 	return "\"" + sl.Value + "\""
 }
 
+// CharLiteral represents character literals, as in 'a'
+type CharLiteral struct {
+	Position
+	Value rune
+	Raw   string // Source text between the quotes, empty for synthesized literals
+}
+
+func (cl *CharLiteral) expressionNode() {}
+func (cl *CharLiteral) String() string {
+	if cl.Raw != "" {
+		return "'" + cl.Raw + "'"
+	}
+	return strconv.QuoteRune(cl.Value)
+}
+
 // BooleanLiteral represents boolean literals
 type BooleanLiteral struct {
+	Position
 	Value bool
 }
 
@@ -192,6 +398,7 @@ func (bl *BooleanLiteral) String() string {
 // ArrayLiteral represents an array literal expression.
 // Example: [1, 2, 3] or ["a", "b", "c"]
 type ArrayLiteral struct {
+	Position
 	Elements []Expression // The elements of the array
 }
 
@@ -206,26 +413,60 @@ func (al *ArrayLiteral) String() string {
 	return "[" + strings.Join(elements, ", ") + "]"
 }
 
+// TupleLiteral groups several values into a tuple, which a function returns
+// as Go multiple values and a let takes apart.
+// Example: (quotient, "ok")
+type TupleLiteral struct {
+	Position
+	Elements []Expression
+}
+
+func (tl *TupleLiteral) expressionNode() {}
+func (tl *TupleLiteral) String() string {
+	elements := make([]string, len(tl.Elements))
+	for i, el := range tl.Elements {
+		elements[i] = el.String()
+	}
+	return "(" + strings.Join(elements, ", ") + ")"
+}
+
 // MapLiteral represents a map literal expression.
 // Example: {key1: value1, "key2": value2}
 type MapLiteral struct {
+	Position
 	Pairs map[Expression]Expression // The key-value pairs of the map
+	Keys  []Expression              // The keys of Pairs in source order
 }
 
 func (ml *MapLiteral) expressionNode() {}
 func (ml *MapLiteral) String() string {
 	var pairs []string
-	for k, v := range ml.Pairs {
-		if k != nil && v != nil { // Add nil check for safety
+	for _, k := range ml.OrderedKeys() {
+		if v := ml.Pairs[k]; k != nil && v != nil { // Add nil check for safety
 			pairs = append(pairs, k.String()+": "+v.String())
 		}
 	}
 	return "{" + strings.Join(pairs, ", ") + "}"
 }
 
+// OrderedKeys returns the keys in source order. Literals built without Keys
+// fall back to the keys of Pairs sorted by their source text.
+func (ml *MapLiteral) OrderedKeys() []Expression {
+	if len(ml.Keys) == len(ml.Pairs) {
+		return ml.Keys
+	}
+	keys := make([]Expression, 0, len(ml.Pairs))
+	for k := range ml.Pairs {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	return keys
+}
+
 // ResultLiteral represents a Result literal expression
 // Example: Result{ok: true, value: 42, error: ""}
 type ResultLiteral struct {
+	Position
 	Ok    bool       // Whether this is a success or error result
 	Value Expression // The value (for success) or nil (for error)
 	Error string     // The error message (for error) or empty (for success)
@@ -244,6 +485,7 @@ func (rl *ResultLiteral) String() string {
 
 // Identifier represents identifiers
 type Identifier struct {
+	Position
 	Value string
 }
 
@@ -254,6 +496,7 @@ func (i *Identifier) String() string {
 
 // BinaryExpression represents binary expressions
 type BinaryExpression struct {
+	Position
 	Left     Expression
 	Operator BinaryOperator
 	Right    Expression
@@ -266,6 +509,7 @@ func (be *BinaryExpression) String() string {
 
 // UnaryExpression represents unary expressions
 type UnaryExpression struct {
+	Position
 	Operator UnaryOperator
 	Right    Expression
 }
@@ -278,26 +522,66 @@ func (ue *UnaryExpression) String() string {
 // ImportItem represents a single import item with type information
 type ImportItem struct {
 	Name   string // The name of the imported item
+	Alias  string // The name given to it with as, empty if it keeps its own
 	IsType bool   // Whether this is a type import
 }
 
+// LocalName returns the name the item is known by in the importing file
+func (item ImportItem) LocalName() string {
+	if item.Alias != "" {
+		return item.Alias
+	}
+	return item.Name
+}
+
+func (item ImportItem) String() string {
+	if item.IsType {
+		return "type " + item.Name
+	}
+	if item.Alias != "" {
+		return item.Name + " as " + item.Alias
+	}
+	return item.Name
+}
+
 // ImportStatement represents import statements
 type ImportStatement struct {
-	Imports []ImportItem // List of imported items
-	Module  string       // Module name to import from
+	Position
+	Imports   []ImportItem // List of imported items
+	Module    string       // Module name to import from
+	Namespace string       // The name the whole module is imported as, for import * as io
+	IsPublic  bool         // pub import re-exports the imported items
+}
+
+// ModulePath returns the path of the imported module relative to the
+// program importing from, itself a module path: "./loud" imported by
+// "./lib/text" is "./lib/loud", and by "github.com/user/pkg/text" is
+// "github.com/user/pkg/loud". Standard library modules keep their path.
+func (is *ImportStatement) ModulePath(from string) string {
+	if !strings.HasPrefix(is.Module, "./") && !strings.HasPrefix(is.Module, "../") {
+		return is.Module
+	}
+	joined := path.Join(path.Dir(from), is.Module)
+	if strings.HasPrefix(joined, "../") || !strings.HasPrefix(from, ".") {
+		return joined
+	}
+	return "./" + joined
 }
 
 func (is *ImportStatement) statementNode() {}
 func (is *ImportStatement) String() string {
+	if is.Namespace != "" {
+		return "import * as " + is.Namespace + " from \"" + is.Module + "\""
+	}
 	result := "import {"
+	if is.IsPublic {
+		result = "pub " + result
+	}
 	for i, imp := range is.Imports {
 		if i > 0 {
 			result += ", "
 		}
-		if imp.IsType {
-			result += "type "
-		}
-		result += imp.Name
+		result += imp.String()
 	}
 	result += "} from \"" + is.Module + "\""
 	return result
@@ -343,17 +627,28 @@ func (p *Parameter) String() string {
 
 // FunctionDefinition represents function definitions
 type FunctionDefinition struct {
+	Position
 	Name       string
 	Generics   []string // generic type parameters
 	Parameters []Parameter
 	ReturnType *string // allow generic type annotations
 	Body       []Statement
-	IsPublic   bool // Whether the function is public (pub fn)
+	Lbrace     Position     // Position of the opening brace of Body
+	Rbrace     Position     // Position of the closing brace of Body
+	IsPublic   bool         // Whether the function is public (pub fn)
+	Deprecated *Deprecation // Set by @deprecated
 }
 
 func (fd *FunctionDefinition) statementNode() {}
 func (fd *FunctionDefinition) String() string {
-	result := "fn " + fd.Name + "("
+	result := "fn " + fd.Name
+	if fd.IsPublic {
+		result = "pub " + result
+	}
+	if len(fd.Generics) > 0 {
+		result += "<" + strings.Join(fd.Generics, ", ") + ">"
+	}
+	result += "("
 	for i, param := range fd.Parameters {
 		if i > 0 {
 			result += ", "
@@ -372,15 +667,53 @@ func (fd *FunctionDefinition) String() string {
 	return result
 }
 
+// Instantiate returns typeName with the type parameters of the function
+// replaced by typeArgs, in order, e.g. Result<User> for Result<T> when
+// called as decode<User>(text)
+func (fd *FunctionDefinition) Instantiate(typeName string, typeArgs []string) string {
+	if len(typeArgs) == 0 {
+		return typeName
+	}
+	var builder strings.Builder
+	word := func(r rune) bool { return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) }
+	for len(typeName) > 0 {
+		end := strings.IndexFunc(typeName, func(r rune) bool { return !word(r) })
+		if end == 0 {
+			builder.WriteByte(typeName[0])
+			typeName = typeName[1:]
+			continue
+		}
+		if end < 0 {
+			end = len(typeName)
+		}
+		name := typeName[:end]
+		for i, param := range fd.Generics {
+			if param == name && i < len(typeArgs) {
+				name = typeArgs[i]
+				break
+			}
+		}
+		builder.WriteString(name)
+		typeName = typeName[end:]
+	}
+	return builder.String()
+}
+
 // FunctionCall represents function calls
 type FunctionCall struct {
-	Name      string
-	Arguments []Expression
+	Position
+	Name          string
+	TypeArguments []string // explicit type arguments, as in decode<User>(text)
+	Arguments     []Expression
 }
 
 func (fc *FunctionCall) expressionNode() {}
 func (fc *FunctionCall) String() string {
-	result := fc.Name + "("
+	result := fc.Name
+	if len(fc.TypeArguments) > 0 {
+		result += "<" + strings.Join(fc.TypeArguments, ", ") + ">"
+	}
+	result += "("
 	for i, arg := range fc.Arguments {
 		if i > 0 {
 			result += ", "
@@ -394,8 +727,9 @@ func (fc *FunctionCall) String() string {
 // MemberAccessExpression represents accessing a field of an expression.
 // Example: object.field
 type MemberAccessExpression struct {
-	Expression Expression // The expression being accessed (e.g., an Identifier for an object)
-	Field      *Identifier  // The field being accessed
+	Position
+	Expression Expression  // The expression being accessed (e.g., an Identifier for an object)
+	Field      *Identifier // The field being accessed
 }
 
 func (mae *MemberAccessExpression) expressionNode() {}
@@ -405,7 +739,9 @@ func (mae *MemberAccessExpression) String() string {
 
 // Block represents a block of statements
 type Block struct {
+	Position   // Position of the opening brace
 	Statements []Statement
+	Rbrace     Position // Position of the closing brace
 }
 
 func (b *Block) String() string {
@@ -419,6 +755,7 @@ func (b *Block) String() string {
 
 // IfStatement represents if/else if/else statements
 type IfStatement struct {
+	Position
 	Condition     Expression
 	ThenBlock     *Block
 	ElseIfClauses []ElseIfClause
@@ -440,6 +777,36 @@ func (ifs *IfStatement) String() string {
 	return result
 }
 
+// IfExpression is an if used as a value
+// Example: let sign = if n < 0 { -1 } else { 1 }
+// The last expression statement of each block gives its value, so the else
+// block is required.
+type IfExpression struct {
+	Position
+	Condition     Expression
+	ThenBlock     *Block
+	ElseIfClauses []ElseIfClause
+	ElseBlock     *Block
+}
+
+func (ie *IfExpression) expressionNode() {}
+func (ie *IfExpression) String() string {
+	result := "if " + ie.Condition.String() + " " + ie.ThenBlock.String()
+	for _, elseif := range ie.ElseIfClauses {
+		result += " else if " + elseif.Condition.String() + " " + elseif.Block.String()
+	}
+	return result + " else " + ie.ElseBlock.String()
+}
+
+// Blocks returns the blocks of the branches in order, the else block last
+func (ie *IfExpression) Blocks() []*Block {
+	blocks := []*Block{ie.ThenBlock}
+	for _, elseif := range ie.ElseIfClauses {
+		blocks = append(blocks, elseif.Block)
+	}
+	return append(blocks, ie.ElseBlock)
+}
+
 // ElseIfClause represents an else if clause
 type ElseIfClause struct {
 	Condition Expression
@@ -448,6 +815,7 @@ type ElseIfClause struct {
 
 // ReturnStatement represents return statements
 type ReturnStatement struct {
+	Position
 	Value Expression // Optional return value
 }
 
@@ -461,6 +829,7 @@ func (rs *ReturnStatement) String() string {
 
 // WhileStatement represents while loops
 type WhileStatement struct {
+	Position
 	Condition Expression
 	Block     *Block
 }
@@ -470,32 +839,287 @@ func (ws *WhileStatement) String() string {
 	return "while " + ws.Condition.String() + " " + ws.Block.String()
 }
 
+// LoopStatement represents an infinite loop, left with break or return
+// Example: loop { ... }
+type LoopStatement struct {
+	Position
+	Body *Block
+}
+
+func (ls *LoopStatement) statementNode() {}
+func (ls *LoopStatement) String() string {
+	return "loop " + ls.Body.String()
+}
+
+// BreakStatement exits the innermost loop
+type BreakStatement struct {
+	Position
+}
+
+func (bs *BreakStatement) statementNode() {}
+func (bs *BreakStatement) String() string { return "break" }
+
+// ContinueStatement skips to the next iteration of the innermost loop
+type ContinueStatement struct {
+	Position
+}
+
+func (cs *ContinueStatement) statementNode() {}
+func (cs *ContinueStatement) String() string { return "continue" }
+
+// SpawnStatement runs a block, or a single function call, concurrently with
+// the statements after it. The builtin wait waits for the spawned code.
+// Example: spawn { work(1) }, spawn work(2)
+type SpawnStatement struct {
+	Position
+	Body *Block     // block run, nil for a call
+	Call Expression // call run without a block, nil for a block
+}
+
+func (ss *SpawnStatement) statementNode() {}
+func (ss *SpawnStatement) String() string {
+	if ss.Body == nil {
+		return "spawn " + ss.Call.String()
+	}
+	return "spawn " + ss.Body.String()
+}
+
+// Statements returns the statements run by the spawn: those of its block,
+// or the call as a statement
+func (ss *SpawnStatement) Statements() []Statement {
+	if ss.Body == nil {
+		return []Statement{&ExpressionStatement{Position: ss.Position, Expression: ss.Call}}
+	}
+	return ss.Body.Statements
+}
+
+// TryStatement runs its block and, if the block panics, the catch block,
+// with the message of the panic in CatchName when there is one.
+// Example: try { risky() } catch e { println(e) }
+type TryStatement struct {
+	Position
+	Body      *Block
+	CatchName string // variable given the message of the panic, empty if none
+	Catch     *Block
+}
+
+func (ts *TryStatement) statementNode() {}
+func (ts *TryStatement) String() string {
+	catch := "catch "
+	if ts.CatchName != "" {
+		catch += ts.CatchName + " "
+	}
+	return "try " + ts.Body.String() + " " + catch + ts.Catch.String()
+}
+
+// WhenStatement waits until one of its arms can receive from or send to a
+// channel and runs it, like a Go select. An arm `_ => ...` runs when no
+// other one can, without waiting.
+// Example: when { msg from inbox => println(msg), send(out, 1) => done(), _ => idle() }
+type WhenStatement struct {
+	Position
+	Arms   []WhenArm
+	Rbrace Position // Position of the closing brace of Arms
+}
+
+// WhenArm is one arm of a when: a receive, `ch` or `name from ch`, or a
+// send, `send(ch, value)`, and the expression or block run after it
+type WhenArm struct {
+	Position
+	Name    string     // variable given the received value, empty if none
+	Channel Expression // channel received from or sent to, nil for _
+	Sent    Expression // value sent, nil for a receive
+	Value   Expression // nil if the arm has a Block
+	Block   *Block
+}
+
+// IsDefault reports whether the arm is `_`, which runs when no other can
+func (wa *WhenArm) IsDefault() bool { return wa.Channel == nil }
+
+// Head returns the part of the arm before its arrow
+func (wa *WhenArm) Head() string {
+	switch {
+	case wa.IsDefault():
+		return "_"
+	case wa.Sent != nil:
+		return "send(" + wa.Channel.String() + ", " + wa.Sent.String() + ")"
+	case wa.Name != "":
+		return wa.Name + " from " + wa.Channel.String()
+	}
+	return wa.Channel.String()
+}
+
+func (wa *WhenArm) String() string {
+	head := wa.Head()
+	if wa.Block != nil {
+		return head + " => " + wa.Block.String()
+	}
+	return head + " => " + wa.Value.String()
+}
+
+func (ws *WhenStatement) statementNode() {}
+func (ws *WhenStatement) String() string {
+	arms := make([]string, len(ws.Arms))
+	for i := range ws.Arms {
+		arms[i] = ws.Arms[i].String()
+	}
+	return "when { " + strings.Join(arms, ", ") + " }"
+}
+
 // ForStatement represents for-in loops
-// Example: for i in [1, 2, 3] { ... }
+// Example: for v in [1, 2, 3] { ... }, for i, v in items { ... }
 type ForStatement struct {
-	VarName  string     // loop variable name
-	Iterable Expression // expression to iterate over (array)
-	Body     *Block     // loop body
+	Position
+	IndexName string     // index variable name, empty without one
+	VarName   string     // loop variable name
+	Iterable  Expression // expression to iterate over (array)
+	Body      *Block     // loop body
 }
 
 func (fs *ForStatement) statementNode() {}
 func (fs *ForStatement) String() string {
-	result := "for " + fs.VarName + " in " + fs.Iterable.String() + " " + fs.Body.String()
+	vars := fs.VarName
+	if fs.IndexName != "" {
+		vars = fs.IndexName + ", " + fs.VarName
+	}
+	result := "for " + vars + " in " + fs.Iterable.String() + " " + fs.Body.String()
 	return result
 }
 
 // TypeField represents a field in a type declaration
 type TypeField struct {
+	Position
 	Name    string
 	TypeAnn string
 }
 
 // TypeDeclaration represents type declarations
 type TypeDeclaration struct {
-	Name     string
-	Generics []string
-	Fields   []TypeField
-	IsPublic bool // Whether the type is public (pub type)
+	Position
+	Name       string
+	Generics   []string
+	Fields     []TypeField
+	Rbrace     Position     // Position of the closing brace of Fields
+	IsPublic   bool         // Whether the type is public (pub type)
+	Deprecated *Deprecation // Set by @deprecated
+}
+
+// EnumDeclaration declares a tagged union: a type whose values are one of
+// its variants, each with its own fields
+// Example: enum Shape { Circle(float), Rect(float, float), Empty }
+type EnumDeclaration struct {
+	Position
+	Name       string
+	Variants   []EnumVariant
+	Rbrace     Position     // Position of the closing brace of Variants
+	Deprecated *Deprecation // Set by @deprecated
+}
+
+// EnumVariant is a variant of an enum. Its fields are positional and
+// named only by their types.
+type EnumVariant struct {
+	Position
+	Name   string
+	Fields []string // Type annotations of the fields
+}
+
+func (ed *EnumDeclaration) statementNode() {}
+func (ed *EnumDeclaration) String() string {
+	result := "enum " + ed.Name + " {\n"
+	for _, variant := range ed.Variants {
+		result += "  " + variant.String() + ",\n"
+	}
+	result += "}"
+	return result
+}
+
+// Variant returns the variant called name
+func (ed *EnumDeclaration) Variant(name string) (*EnumVariant, bool) {
+	for i := range ed.Variants {
+		if ed.Variants[i].Name == name {
+			return &ed.Variants[i], true
+		}
+	}
+	return nil, false
+}
+
+func (ev *EnumVariant) String() string {
+	if len(ev.Fields) == 0 {
+		return ev.Name
+	}
+	return ev.Name + "(" + strings.Join(ev.Fields, ", ") + ")"
+}
+
+// ImplDeclaration implements a trait for a struct type with the function
+// the trait requires, which the operators of the trait call
+// Example: impl Add for Money { fn add(a: Money, b: Money): Money { ... } }
+type ImplDeclaration struct {
+	Position
+	Trait    string
+	TypeName string
+	Methods  []*FunctionDefinition
+	Rbrace   Position // Position of the closing brace of Methods
+}
+
+func (id *ImplDeclaration) statementNode() {}
+func (id *ImplDeclaration) String() string {
+	result := "impl " + id.Trait + " for " + id.TypeName + " {\n"
+	for _, method := range id.Methods {
+		result += "  " + method.String() + "\n"
+	}
+	result += "}"
+	return result
+}
+
+// Method returns the function of the impl called name
+func (id *ImplDeclaration) Method(name string) (*FunctionDefinition, bool) {
+	for _, method := range id.Methods {
+		if method.Name == name {
+			return method, true
+		}
+	}
+	return nil, false
+}
+
+// Trait is one of the traits a struct type can implement to give its values
+// operators: the function an impl of it declares, which takes two values of
+// the type, and what the function returns
+type Trait struct {
+	Method     string
+	ReturnType string // empty for the type itself
+}
+
+// Traits are the traits by name: Add gives +, Eq == and !=, and Ord <, <=, >
+// and >=, through compare, which returns a negative number, zero or a
+// positive number as its first argument is less than, equal to or greater
+// than its second
+var Traits = map[string]Trait{
+	"Add": {Method: "add"},
+	"Eq":  {Method: "eq", ReturnType: "bool"},
+	"Ord": {Method: "compare", ReturnType: "int"},
+}
+
+// TraitNames are the names of Traits, in order
+var TraitNames = []string{"Add", "Eq", "Ord"}
+
+// OperatorTrait returns the name of the trait that gives op to a type, ""
+// if no trait does
+func OperatorTrait(op BinaryOperator) string {
+	switch op {
+	case BinaryOpPlus:
+		return "Add"
+	case BinaryOpEq, BinaryOpNotEq:
+		return "Eq"
+	case BinaryOpLt, BinaryOpLte, BinaryOpGt, BinaryOpGte:
+		return "Ord"
+	}
+	return ""
+}
+
+// Deprecation marks a declaration as deprecated
+// Example: @deprecated("use readText instead")
+type Deprecation struct {
+	Message string // Optional replacement hint
 }
 
 func (td *TypeDeclaration) statementNode() {}
@@ -504,7 +1128,7 @@ func (td *TypeDeclaration) String() string {
 	if len(td.Generics) > 0 {
 		result += "<" + strings.Join(td.Generics, ", ") + ">"
 	}
-	result += " {\n"
+	result += " = {\n"
 	for _, field := range td.Fields {
 		result += "  " + field.Name + ": " + field.TypeAnn + "\n"
 	}
@@ -515,23 +1139,40 @@ func (td *TypeDeclaration) String() string {
 // StructLiteral represents a typed struct literal expression
 // Example: Result{ok: true, value: 42, error: ""}
 type StructLiteral struct {
-	TypeName string                // The name of the struct type
-	Fields   map[string]Expression // Field name to value mapping
+	Position
+	TypeName   string                // The name of the struct type
+	Fields     map[string]Expression // Field name to value mapping
+	FieldNames []string              // The keys of Fields in source order
 }
 
 func (sl *StructLiteral) expressionNode() {}
 func (sl *StructLiteral) String() string {
 	var fields []string
-	for name, value := range sl.Fields {
-		if value != nil {
+	for _, name := range sl.OrderedFieldNames() {
+		if value := sl.Fields[name]; value != nil {
 			fields = append(fields, name+": "+value.String())
 		}
 	}
 	return sl.TypeName + "{" + strings.Join(fields, ", ") + "}"
 }
 
+// OrderedFieldNames returns the field names in source order. Literals built
+// without FieldNames fall back to the sorted keys of Fields.
+func (sl *StructLiteral) OrderedFieldNames() []string {
+	if len(sl.FieldNames) == len(sl.Fields) {
+		return sl.FieldNames
+	}
+	names := make([]string, 0, len(sl.Fields))
+	for name := range sl.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // MemberExpression represents property access (e.g., obj.field)
 type MemberExpression struct {
+	Position
 	Object   Expression
 	Property string
 }
@@ -540,3 +1181,122 @@ func (me *MemberExpression) expressionNode() {}
 func (me *MemberExpression) String() string {
 	return fmt.Sprintf("%s.%s", me.Object.String(), me.Property)
 }
+
+// MatchExpression selects the first arm whose pattern equals the subject
+// Example: match x { 1 => "one", 2 => "two", _ => "many" }
+type MatchExpression struct {
+	Position
+	Subject Expression
+	Arms    []MatchArm
+	Rbrace  Position // Position of the closing brace of Arms
+}
+
+// MatchArm is one `pattern => value` arm of a match. The value is an
+// expression or a block; in a block used as a value, the last expression
+// statement gives the value.
+type MatchArm struct {
+	Position
+	Pattern Expression // nil for the wildcard _
+	Value   Expression // nil if the arm has a Block
+	Block   *Block
+}
+
+// IsWildcard reports whether the arm matches any value
+func (ma *MatchArm) IsWildcard() bool { return ma.Pattern == nil }
+
+func (me *MatchExpression) expressionNode() {}
+func (me *MatchExpression) String() string {
+	var arms []string
+	for _, arm := range me.Arms {
+		pattern := "_"
+		if !arm.IsWildcard() {
+			pattern = arm.Pattern.String()
+		}
+		if arm.Block != nil {
+			arms = append(arms, pattern+" => "+arm.Block.String())
+		} else {
+			arms = append(arms, pattern+" => "+arm.Value.String())
+		}
+	}
+	return "match " + me.Subject.String() + " { " + strings.Join(arms, ", ") + " }"
+}
+
+// TryExpression unwraps a Result, returning its error from the enclosing
+// function if it has one
+// Example: let n = parse(s)?
+type TryExpression struct {
+	Position
+	Value Expression
+}
+
+func (te *TryExpression) expressionNode() {}
+func (te *TryExpression) String() string {
+	return te.Value.String() + "?"
+}
+
+// IndexExpression reads an element of an array, a character of a string or
+// a value of a map
+// Example: items[0]
+type IndexExpression struct {
+	Position
+	Left  Expression
+	Index Expression
+}
+
+func (ie *IndexExpression) expressionNode() {}
+func (ie *IndexExpression) String() string {
+	return ie.Left.String() + "[" + ie.Index.String() + "]"
+}
+
+// SliceExpression takes the elements of an array or the characters of a
+// string from Low up to High. Either bound may be omitted.
+// Example: items[1:3]
+type SliceExpression struct {
+	Position
+	Left Expression
+	Low  Expression // nil for the start
+	High Expression // nil for the end
+}
+
+func (se *SliceExpression) expressionNode() {}
+func (se *SliceExpression) String() string {
+	var low, high string
+	if se.Low != nil {
+		low = se.Low.String()
+	}
+	if se.High != nil {
+		high = se.High.String()
+	}
+	return se.Left.String() + "[" + low + ":" + high + "]"
+}
+
+// MethodCallExpression calls a builtin method of a string or an array
+// Example: name.toUpper(), items.push(4)
+type MethodCallExpression struct {
+	Position
+	Object    Expression
+	Method    string
+	Arguments []Expression
+}
+
+func (mc *MethodCallExpression) expressionNode() {}
+
+// QualifiedCall returns object.method(args), where object is a name, as a
+// call of the function object.method, which is how the functions of a module
+// imported with import * as object are called. ok is false for other
+// receivers.
+func (mc *MethodCallExpression) QualifiedCall() (object string, call *FunctionCall, ok bool) {
+	ident, isIdent := mc.Object.(*Identifier)
+	if !isIdent {
+		return "", nil, false
+	}
+	call = &FunctionCall{Position: mc.Position, Name: ident.Value + "." + mc.Method, Arguments: mc.Arguments}
+	return ident.Value, call, true
+}
+func (mc *MethodCallExpression) String() string {
+	args := make([]string, len(mc.Arguments))
+	for i, arg := range mc.Arguments {
+		args[i] = arg.String()
+	}
+	return mc.Object.String() + "." + mc.Method + "(" + strings.Join(args, ", ") + ")"
+}
@@ -0,0 +1,138 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Dump returns node as maps, slices and values that encoding/json can
+// write, for tools reading the AST. A node becomes a map with its kind, such
+// as "LetDeclaration", its position and its fields, named in camel case;
+// fields that are nil, empty or false are left out. When typeOf is not nil, expressions
+// also get the type it returns for them, unless it is empty.
+func Dump(node Node, typeOf func(Expression) string) interface{} {
+	d := dumper{typeOf: typeOf}
+	return d.value(reflect.ValueOf(node))
+}
+
+type dumper struct {
+	typeOf func(Expression) string
+}
+
+var (
+	positionType = reflect.TypeOf(Position{})
+	nodeType     = reflect.TypeOf((*Node)(nil)).Elem()
+	stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+func (d dumper) value(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Type().Implements(nodeType) && v.Kind() == reflect.Pointer {
+			return d.node(v)
+		}
+		return d.value(v.Elem())
+	case reflect.Struct:
+		if v.Type() == positionType {
+			return position(v.Interface().(Position))
+		}
+		return d.fields(v, make(map[string]interface{}))
+	case reflect.Slice:
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = d.value(v.Index(i))
+		}
+		return items
+	case reflect.Map:
+		return d.pairs(v)
+	case reflect.Int:
+		// Operators are written as in the source
+		if v.Type().Implements(stringerType) {
+			return v.Interface().(fmt.Stringer).String()
+		}
+	}
+	return v.Interface()
+}
+
+// node dumps the node v points to
+func (d dumper) node(v reflect.Value) interface{} {
+	result := map[string]interface{}{"kind": v.Elem().Type().Name()}
+	node := v.Interface().(Node)
+	if pos := node.Pos(); pos.IsValid() {
+		result["pos"] = position(pos)
+	}
+	if expr, ok := node.(Expression); ok && d.typeOf != nil {
+		if t := d.typeOf(expr); t != "" {
+			result["type"] = t
+		}
+	}
+	return d.fields(v.Elem(), result)
+}
+
+// fields adds the exported fields of the struct v with non-zero values to
+// result, leaving out the embedded position
+func (d dumper) fields(v reflect.Value, result map[string]interface{}) map[string]interface{} {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() || (field.Anonymous && field.Type == positionType) || omitted(v.Field(i)) {
+			continue
+		}
+		result[camelCase(field.Name)] = d.value(v.Field(i))
+	}
+	if pos, ok := v.Interface().(interface{ Pos() Position }); ok && result["pos"] == nil && pos.Pos().IsValid() {
+		result["pos"] = position(pos.Pos())
+	}
+	return result
+}
+
+// pairs dumps a map, whose keys may be expressions, as a list of key and
+// value pairs ordered by the position of the keys
+func (d dumper) pairs(v reflect.Value) interface{} {
+	keys := v.MapKeys()
+	sort.SliceStable(keys, func(i, j int) bool {
+		a, aOK := keys[i].Interface().(Node)
+		b, bOK := keys[j].Interface().(Node)
+		if !aOK || !bOK {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		}
+		if a.Pos().Line != b.Pos().Line {
+			return a.Pos().Line < b.Pos().Line
+		}
+		return a.Pos().Column < b.Pos().Column
+	})
+	pairs := make([]interface{}, len(keys))
+	for i, key := range keys {
+		pairs[i] = map[string]interface{}{"key": d.value(key), "value": d.value(v.MapIndex(key))}
+	}
+	return pairs
+}
+
+// omitted reports whether a field is left out of the dump. Numbers are
+// kept, since 0 is a value like any other, and so are operators; other named
+// integer types are kinds whose zero is the default.
+func omitted(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Float64:
+		return v.Type().PkgPath() != "" && !v.Type().Implements(stringerType) && v.IsZero()
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+func position(pos Position) map[string]int {
+	return map[string]int{"line": pos.Line, "column": pos.Column}
+}
+
+// camelCase turns the name of a field, such as ValueExpression, into the
+// name of its JSON key, valueExpression
+func camelCase(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToLower(r)) + name[size:]
+}
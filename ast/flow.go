@@ -0,0 +1,140 @@
+package ast
+
+// FallThroughKind tells how a path through statements reaches their end
+type FallThroughKind int
+
+const (
+	// FallsOffEnd is a branch, or a body, whose last statement completes
+	FallsOffEnd FallThroughKind = iota
+	// IfWithoutElse is an if without an else, whose conditions may all be
+	// false
+	IfWithoutElse
+	// LoopExits is a while or for loop, which ends when its condition or
+	// its values do, or a loop with a break
+	LoopExits
+	// MatchWithoutWildcard is a match statement without a _ arm, which may
+	// match none of its arms
+	MatchWithoutWildcard
+)
+
+// FallThrough is a path through the body of a function that reaches its
+// end, so that the function ends without returning a value
+type FallThrough struct {
+	Position // the statement, or the closing brace of the branch, the path leaves by
+	Kind     FallThroughKind
+}
+
+// FindFallThrough returns a path through statements, the body of a function
+// whose closing brace is at end, that reaches their end, if there is one.
+// It follows the rules of Go for the statements that end a function on the
+// Go code the statements compile to, so that the functions it finds a path
+// through are the ones Go rejects with "missing return".
+func FindFallThrough(statements []Statement, end Position) (FallThrough, bool) {
+	if len(statements) == 0 {
+		return FallThrough{Position: end, Kind: FallsOffEnd}, true
+	}
+	switch s := statements[len(statements)-1].(type) {
+	case *ReturnStatement, *BreakStatement, *ContinueStatement:
+		return FallThrough{}, false
+	case *WhenStatement:
+		// The Go select waits for one of the arms, which must all end the
+		// function
+		for i := range s.Arms {
+			arm := &s.Arms[i]
+			if arm.Block == nil {
+				return FallThrough{Position: arm.Value.Pos(), Kind: FallsOffEnd}, true
+			}
+			if fallThrough, ok := FindFallThrough(arm.Block.Statements, arm.Block.Rbrace); ok {
+				return fallThrough, true
+			}
+		}
+		return FallThrough{}, false
+	case *IfStatement:
+		if s.ElseBlock == nil {
+			return FallThrough{Position: s.Position, Kind: IfWithoutElse}, true
+		}
+		blocks := []*Block{s.ThenBlock}
+		for _, clause := range s.ElseIfClauses {
+			blocks = append(blocks, clause.Block)
+		}
+		for _, block := range append(blocks, s.ElseBlock) {
+			if fallThrough, ok := FindFallThrough(block.Statements, block.Rbrace); ok {
+				return fallThrough, true
+			}
+		}
+		return FallThrough{}, false
+	case *LoopStatement:
+		if Breaks(s.Body.Statements) {
+			return FallThrough{Position: s.Position, Kind: LoopExits}, true
+		}
+		return FallThrough{}, false
+	case *WhileStatement:
+		return FallThrough{Position: s.Position, Kind: LoopExits}, true
+	case *ForStatement:
+		return FallThrough{Position: s.Position, Kind: LoopExits}, true
+	case *ExpressionStatement:
+		match, ok := s.Expression.(*MatchExpression)
+		if !ok {
+			break
+		}
+		for i := range match.Arms {
+			arm := &match.Arms[i]
+			if arm.Block == nil {
+				return FallThrough{Position: arm.Value.Pos(), Kind: FallsOffEnd}, true
+			}
+			if fallThrough, ok := FindFallThrough(arm.Block.Statements, arm.Block.Rbrace); ok {
+				return fallThrough, true
+			}
+			// The arms after the wildcard are never taken
+			if arm.IsWildcard() {
+				return FallThrough{}, false
+			}
+		}
+		return FallThrough{Position: match.Position, Kind: MatchWithoutWildcard}, true
+	}
+	return FallThrough{Position: end, Kind: FallsOffEnd}, true
+}
+
+// Breaks reports whether a break in statements ends the loop holding them.
+// The breaks of nested loops end them, while the ones in the arms of a match
+// or a when end the loop around it, as the generated Go sets a flag that
+// breaks out of the loop after the switch or select.
+func Breaks(statements []Statement) bool {
+	for _, stmt := range statements {
+		switch s := stmt.(type) {
+		case *BreakStatement:
+			return true
+		case *IfStatement:
+			if Breaks(s.ThenBlock.Statements) {
+				return true
+			}
+			for _, clause := range s.ElseIfClauses {
+				if Breaks(clause.Block.Statements) {
+					return true
+				}
+			}
+			if s.ElseBlock != nil && Breaks(s.ElseBlock.Statements) {
+				return true
+			}
+		case *TryStatement:
+			if Breaks(s.Catch.Statements) {
+				return true
+			}
+		case *WhenStatement:
+			for i := range s.Arms {
+				if s.Arms[i].Block != nil && Breaks(s.Arms[i].Block.Statements) {
+					return true
+				}
+			}
+		case *ExpressionStatement:
+			if match, ok := s.Expression.(*MatchExpression); ok {
+				for i := range match.Arms {
+					if match.Arms[i].Block != nil && Breaks(match.Arms[i].Block.Statements) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/lexer"
+	"github.com/linkalls/zeno-lang/parser"
+)
+
+// parseFunction parses source and returns its first function
+func parseFunction(t *testing.T, source string) *ast.FunctionDefinition {
+	t.Helper()
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("parser errors for %q: %v", source, p.Errors())
+	}
+	fn, ok := program.Statements[0].(*ast.FunctionDefinition)
+	if !ok {
+		t.Fatalf("expected a function, got %s", program.Statements[0])
+	}
+	return fn
+}
+
+func TestFindFallThrough(t *testing.T) {
+	tests := []struct {
+		source   string
+		falls    bool
+		kind     ast.FallThroughKind
+		position ast.Position
+	}{
+		{"fn f(): int {\n    return 1\n}", false, 0, ast.Position{}},
+		{"fn f(): int {\n    println(1)\n}", true, ast.FallsOffEnd, ast.Position{Line: 3, Column: 1}},
+		{"fn f(): int {\n    if a {\n        return 1\n    }\n}", true, ast.IfWithoutElse, ast.Position{Line: 2, Column: 5}},
+		{"fn f(): int {\n    loop {\n        tick()\n    }\n}", false, 0, ast.Position{}},
+		{"fn f(): int {\n    loop {\n        match x {\n            1 => {\n                break\n            }\n            _ => tick(),\n        }\n    }\n}", true, ast.LoopExits, ast.Position{Line: 2, Column: 5}},
+		{"fn f(ch: Channel<int>): int {\n    when {\n        v from ch => {\n            return v\n        }\n        _ => {\n            return 0\n        }\n    }\n}", false, 0, ast.Position{}},
+		// A when ends the function only if all of its arms do
+		{"fn f(ch: Channel<int>): int {\n    when {\n        v from ch => println(v),\n    }\n}", true, ast.FallsOffEnd, ast.Position{Line: 3, Column: 22}},
+		{"fn f(ch: Channel<int>): int {\n    when {\n        v from ch => {\n            return v\n        }\n        _ => {\n            println(0)\n        }\n    }\n}", true, ast.FallsOffEnd, ast.Position{Line: 8, Column: 9}},
+		// A break in an arm of a when leaves the loop around it
+		{"fn f(ch: Channel<int>): int {\n    loop {\n        when {\n            v from ch => {\n                break\n            }\n        }\n    }\n}", true, ast.LoopExits, ast.Position{Line: 2, Column: 5}},
+	}
+	for _, tt := range tests {
+		fn := parseFunction(t, tt.source)
+		fallThrough, ok := ast.FindFallThrough(fn.Body, fn.Rbrace)
+		if ok != tt.falls {
+			t.Errorf("expected %v for %q, got %v", tt.falls, tt.source, ok)
+			continue
+		}
+		if ok && (fallThrough.Kind != tt.kind || fallThrough.Position != tt.position) {
+			t.Errorf("expected kind %d at %v for %q, got kind %d at %v", tt.kind, tt.position, tt.source, fallThrough.Kind, fallThrough.Position)
+		}
+	}
+}
+
+func TestBreaks(t *testing.T) {
+	tests := []struct {
+		body   string
+		breaks bool
+	}{
+		{"tick()", false},
+		{"if done {\n    break\n}", true},
+		{"while a {\n    break\n}", false},
+		{"match x {\n    1 => {\n        break\n    }\n    _ => tick(),\n}", true},
+		{"when {\n    v from ch => {\n        break\n    }\n}", true},
+		{"when {\n    v from ch => {\n        loop {\n            break\n        }\n    }\n}", false},
+		{"try {\n    tick()\n} catch {\n    break\n}", true},
+	}
+	for _, tt := range tests {
+		fn := parseFunction(t, "fn f() {\n    loop {\n"+tt.body+"\n    }\n}")
+		loop := fn.Body[0].(*ast.LoopStatement)
+		if got := ast.Breaks(loop.Body.Statements); got != tt.breaks {
+			t.Errorf("expected %v for %q, got %v", tt.breaks, tt.body, got)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/diagnostics"
+	"github.com/linkalls/zeno-lang/lexer"
+	"github.com/linkalls/zeno-lang/parser"
+	"github.com/linkalls/zeno-lang/typechecker"
+	"github.com/spf13/cobra"
+)
+
+// astTree prints the AST as an indented tree instead of JSON (ast --tree)
+var astTree bool
+
+var astCmd = &cobra.Command{
+	Use:   "ast <file>",
+	Short: "Print the syntax tree of a Zeno file",
+	Long: `Parses a Zeno file and prints its syntax tree as JSON, for debugging the
+compiler and for tools. Each node has its kind, such as "LetDeclaration", its
+position and its fields; expressions also have the type the type checker
+infers for them. Type errors do not stop the output, but the types of the
+expressions involved may be missing or any. --tree prints an indented tree
+instead.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := printAST(os.Stdout, args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", args[0], err)
+			os.Exit(1)
+		}
+	},
+}
+
+// printAST parses and type checks filename and prints its syntax tree
+func printAST(w io.Writer, filename string) error {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", filename, err)
+	}
+	p := parser.NewWithInput(lexer.New(string(content)), filename, string(content))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		diagnostics.ReportAll(reporter(map[string]string{filename: string(content)}), p.Diagnostics())
+		return fmt.Errorf("parser errors found")
+	}
+	exprTypes, _ := typechecker.Types(program, filename)
+	tree := ast.Dump(program, func(expr ast.Expression) string {
+		if t, ok := exprTypes[expr]; ok {
+			return t.String()
+		}
+		return ""
+	})
+	if astTree {
+		writeTree(w, tree, "", "")
+		return nil
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(tree)
+}
+
+// writeTree writes a value dumped by ast.Dump, labelled with the field
+// holding it, one node per line: its kind, position, scalar fields and type,
+// followed by its children indented
+func writeTree(w io.Writer, value interface{}, indent, label string) {
+	if label != "" {
+		label += ": "
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		var line strings.Builder
+		line.WriteString(indent + label)
+		if kind, ok := v["kind"].(string); ok {
+			line.WriteString(kind)
+		} else if label == "" {
+			// A list item that is not a node, such as a pair of a map
+			line.WriteString("-")
+		}
+		if pos, ok := v["pos"].(map[string]int); ok {
+			fmt.Fprintf(&line, " %d:%d", pos["line"], pos["column"])
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			if key != "kind" && key != "pos" && key != "type" {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		var children []string
+		for _, key := range keys {
+			switch field := v[key].(type) {
+			case map[string]interface{}, []interface{}, map[string]int:
+				children = append(children, key)
+			case string:
+				fmt.Fprintf(&line, " %s=%q", key, field)
+			default:
+				fmt.Fprintf(&line, " %s=%v", key, field)
+			}
+		}
+		if t, ok := v["type"].(string); ok {
+			line.WriteString(" : " + t)
+		}
+		fmt.Fprintln(w, strings.TrimRight(line.String(), " "))
+		for _, key := range children {
+			writeTree(w, v[key], indent+"  ", key)
+		}
+	case []interface{}:
+		fmt.Fprintf(w, "%s%s[%d]\n", indent, label, len(v))
+		for _, item := range v {
+			writeTree(w, item, indent+"  ", "")
+		}
+	case map[string]int:
+		fmt.Fprintf(w, "%s%s%d:%d\n", indent, label, v["line"], v["column"])
+	default:
+		fmt.Fprintf(w, "%s%s%v\n", indent, label, v)
+	}
+}
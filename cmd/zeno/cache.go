@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/linkalls/zeno-lang/generator"
+	"github.com/spf13/cobra"
+)
+
+// cacheDirName is the directory, next to the main Zeno file, holding the
+// Go packages generated for the user modules it imports
+const cacheDirName = ".zeno-cache"
+
+// fileCache is a generator.PackageCache keeping each package in a JSON file
+// under the file name of its key, which identifies the zeno executable too
+type fileCache struct {
+	dir string
+}
+
+// newFileCache returns the cache of the packages of the program in
+// filename
+func newFileCache(filename string) *fileCache {
+	return &fileCache{dir: filepath.Join(filepath.Dir(filename), cacheDirName)}
+}
+
+// path returns the file holding the package stored under key
+func (c *fileCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// Load reads the package stored under key. Unreadable entries are misses.
+func (c *fileCache) Load(key string) (*generator.CachedPackage, bool) {
+	content, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var pkg generator.CachedPackage
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return nil, false
+	}
+	return &pkg, true
+}
+
+// Store writes the package under key. The cache only speeds up later
+// builds, so failing to write it is not an error.
+func (c *fileCache) Store(key string, pkg *generator.CachedPackage) {
+	content, err := json.Marshal(pkg)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return
+	}
+	// Written to a temporary file first so that a concurrent build never
+	// reads a partial entry
+	temp, err := os.CreateTemp(c.dir, "entry-*")
+	if err != nil {
+		return
+	}
+	_, err = temp.Write(content)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), c.path(key))
+	}
+	if err != nil {
+		os.Remove(temp.Name())
+	}
+}
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean [dir]...",
+	Short: "Remove the compilation cache",
+	Long: `Removes the ` + cacheDirName + ` directory, where run, compile and build cache the
+Go code generated for the modules of a program, from each directory given,
+or from the current directory.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			args = []string{"."}
+		}
+		failed := false
+		for _, dir := range args {
+			cacheDir := filepath.Join(dir, cacheDirName)
+			if _, err := os.Stat(cacheDir); os.IsNotExist(err) {
+				continue
+			}
+			if err := os.RemoveAll(cacheDir); err != nil {
+				fmt.Fprintf(os.Stderr, "Error removing %s: %v\n", cacheDir, err)
+				failed = true
+				continue
+			}
+			fmt.Fprintf(messages(), "Removed %s\n", cacheDir)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/linkalls/zeno-lang/diagnostics"
+	"github.com/linkalls/zeno-lang/lexer"
+	"github.com/linkalls/zeno-lang/linter"
+	"github.com/linkalls/zeno-lang/parser"
+	"github.com/spf13/cobra"
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check <file|dir>...",
+	Short: "Check Zeno files for errors without generating Go code",
+	Long: `Runs the front end of the compiler on Zeno source files: parsing, type
+checking and import resolution, as compile does, and the lint rules, whose
+issues are reported with the severities of .zenolint.toml, warnings by
+default. Directories are walked recursively. No Go file is written and the Go
+toolchain is not invoked. Exits with status 1 if any file has errors,
+including lint errors, or warnings with --werror.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Fprintf(progress(), "=== Zeno Check Command ===\n")
+		checked, failed := 0, 0
+		for _, pathArg := range args {
+			files, err := zenoFiles(pathArg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error accessing path %s: %v\n", pathArg, err)
+				failed++
+				continue
+			}
+			for _, file := range files {
+				checked++
+				if err := checkFile(file); err != nil {
+					fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+					failed++
+				}
+			}
+		}
+		writeDiagnostics()
+		if failed > 0 {
+			fmt.Fprintf(messages(), "Checked %d file(s), %d failed\n", checked, failed)
+			os.Exit(1)
+		}
+		fmt.Fprintf(messages(), "✅ Checked %d file(s), no errors found\n", checked)
+	},
+}
+
+// checkRules returns the lint rules run by check, compile and build: the
+// ones whose issues the compiler does not already report
+func checkRules() []linter.Rule {
+	var rules []linter.Rule
+	for _, rule := range linter.DefaultRules() {
+		switch rule.(type) {
+		case *linter.UnusedVariableRule, *linter.UnusedFunctionRule, *linter.MissingImportRule, *linter.DeprecatedUsageRule, *linter.MissingReturnRule:
+		default:
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// checkFile parses and type checks a file and generates its Go code in
+// memory, which resolves its imports, then reports its lint issues as
+// warnings
+func checkFile(filename string) error {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", filename, err)
+	}
+	if _, err := generateGoCode(filename, string(content)); err != nil {
+		return err
+	}
+
+	found, err := lintDiagnostics(filename, string(content))
+	if err != nil {
+		return err
+	}
+	diagnostics.ReportAll(reporter(map[string]string{filename: string(content)}), found)
+	if errorCount := found.Count(diagnostics.Error); errorCount > 0 {
+		return fmt.Errorf("%d lint error(s)", errorCount)
+	}
+	if warningCount := found.Count(diagnostics.Warning); werror && warningCount > 0 {
+		return fmt.Errorf("%d lint warning(s) treated as errors (--werror)", warningCount)
+	}
+	return nil
+}
+
+// lintDiagnostics returns the issues of the rules of checkRules in a file,
+// parsed from content, with the severities of its configuration
+func lintDiagnostics(filename, content string) (diagnostics.List, error) {
+	program := parser.NewWithInput(lexer.NewWithComments(content), filename, content).ParseProgram()
+	absFilePath, _ := filepath.Abs(filename)
+	config, err := lintConfig(absFilePath)
+	if err != nil {
+		return nil, err
+	}
+	l := linter.NewLinter(checkRules())
+	l.SetConfig(config)
+	issues, err := l.Lint(program, absFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("linter error: %w", err)
+	}
+	var found diagnostics.List
+	for _, issue := range issues {
+		d := issue.Diagnostic()
+		d.File = filename
+		found.Report(d)
+	}
+	return found, nil
+}
+
+// The values of --lint, which sets how compile and build report lint issues
+const (
+	lintOff   = "off"
+	lintWarn  = "warn"
+	lintError = "error"
+)
+
+// checkLintMode rejects the values of --lint other than off, warn and error
+func checkLintMode(cmd *cobra.Command, args []string) error {
+	switch lintMode {
+	case lintOff, lintWarn, lintError:
+		return nil
+	}
+	return fmt.Errorf("invalid --lint value %q, expected off, warn or error", lintMode)
+}
+
+// lintCompiled reports the lint issues of a file compile or build turned
+// into Go code, as --lint asks: not at all with off, as warnings with warn
+// and as errors, which fail the compilation, with error. Issues of rules
+// configured with the info severity stay informational.
+func lintCompiled(filename, content string) error {
+	if lintMode == lintOff {
+		return nil
+	}
+	found, err := lintDiagnostics(filename, content)
+	if err != nil {
+		return err
+	}
+	severity := diagnostics.Warning
+	if lintMode == lintError {
+		severity = diagnostics.Error
+	}
+	for i := range found {
+		if found[i].Severity != diagnostics.Info {
+			found[i].Severity = severity
+		}
+	}
+	diagnostics.ReportAll(reporter(map[string]string{filename: content}), found)
+	if errorCount := found.Count(diagnostics.Error); errorCount > 0 {
+		return fmt.Errorf("%d lint error(s) (--lint=error)", errorCount)
+	}
+	if warningCount := found.Count(diagnostics.Warning); werror && warningCount > 0 {
+		return fmt.Errorf("%d lint warning(s) treated as errors (--werror)", warningCount)
+	}
+	return nil
+}
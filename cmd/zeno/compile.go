@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/lexer"
+	"github.com/linkalls/zeno-lang/parser"
+)
+
+// compileInput is a Zeno file given to compile, with the directory its
+// output is placed relative to under --out-dir
+type compileInput struct {
+	file string
+	root string
+}
+
+// outputPath returns the Go file written for the input: next to it, or at
+// the same path relative to its root under outDir
+func (input compileInput) outputPath(outDir string) string {
+	output := strings.TrimSuffix(strings.TrimSuffix(input.file, ".zeno"), ".zn") + ".go"
+	if outDir == "" {
+		return output
+	}
+	relative, err := filepath.Rel(input.root, output)
+	if err != nil || strings.HasPrefix(relative, "..") {
+		relative = filepath.Base(output)
+	}
+	return filepath.Join(outDir, relative)
+}
+
+// compileInputs expands the arguments of compile: files, directories,
+// which are walked recursively, and glob patterns. A file given twice is
+// compiled once.
+func compileInputs(args []string) ([]compileInput, error) {
+	var inputs []compileInput
+	seen := make(map[string]bool)
+	add := func(file, root string) {
+		key, err := filepath.Abs(file)
+		if err != nil {
+			key = file
+		}
+		if !seen[key] {
+			seen[key] = true
+			inputs = append(inputs, compileInput{file: file, root: root})
+		}
+	}
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil && strings.ContainsAny(arg, "*?[") {
+			matches, err := filepath.Glob(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %s: %w", arg, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %s", arg)
+			}
+			for _, match := range matches {
+				files, err := zenoFiles(match)
+				if err != nil {
+					return nil, err
+				}
+				for _, file := range files {
+					add(file, globRoot(arg))
+				}
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		root := filepath.Dir(arg)
+		if info.IsDir() {
+			root = arg
+		}
+		files, err := zenoFiles(arg)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			add(file, root)
+		}
+	}
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("no .zeno or .zn files found in %s", strings.Join(args, ", "))
+	}
+	return inputs, nil
+}
+
+// globRoot returns the directory of the part of a pattern before its first
+// wildcard, e.g. src for src/*/main.zeno
+func globRoot(pattern string) string {
+	parts := strings.Split(filepath.ToSlash(pattern), "/")
+	for i, part := range parts {
+		if strings.ContainsAny(part, "*?[") {
+			return filepath.FromSlash(strings.Join(parts[:i], "/"))
+		}
+	}
+	return filepath.Dir(pattern)
+}
+
+// dependencyOrder sorts the inputs so that the user modules a file imports
+// come before it, keeping the order of the arguments otherwise. Import
+// cycles are left to the compiler to report.
+func dependencyOrder(inputs []compileInput) []compileInput {
+	byPath := make(map[string]compileInput)
+	for _, input := range inputs {
+		if path, err := filepath.Abs(input.file); err == nil {
+			byPath[path] = input
+		}
+	}
+	var ordered []compileInput
+	visited := make(map[string]bool)
+	var visit func(input compileInput)
+	visit = func(input compileInput) {
+		path, err := filepath.Abs(input.file)
+		if err != nil {
+			path = input.file
+		}
+		if visited[path] {
+			return
+		}
+		visited[path] = true
+		for _, imported := range localImports(input.file) {
+			if dependency, ok := byPath[imported]; ok {
+				visit(dependency)
+			}
+		}
+		ordered = append(ordered, input)
+	}
+	for _, input := range inputs {
+		visit(input)
+	}
+	return ordered
+}
+
+// localImports returns the absolute paths of the user modules a file
+// imports with a relative path. Files that do not parse import nothing.
+func localImports(filename string) []string {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil
+	}
+	p := parser.New(lexer.New(string(content)))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		return nil
+	}
+	var imports []string
+	for _, stmt := range program.Statements {
+		imp, ok := stmt.(*ast.ImportStatement)
+		if !ok || !(strings.HasPrefix(imp.Module, "./") || strings.HasPrefix(imp.Module, "../")) {
+			continue
+		}
+		module := imp.Module
+		if !strings.HasSuffix(module, ".zeno") && !strings.HasSuffix(module, ".zn") {
+			module += ".zeno"
+		}
+		if path, err := filepath.Abs(filepath.Join(filepath.Dir(filename), module)); err == nil {
+			imports = append(imports, path)
+		}
+	}
+	return imports
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around a change
+const diffContext = 3
+
+// diffLine is one line of an edit script
+type diffLine struct {
+	op   byte // ' ', '-' or '+'
+	text string
+}
+
+// unifiedDiff returns the changes from before to after in unified diff
+// format, or an empty string if they are equal
+func unifiedDiff(name, before, after string) string {
+	if before == after {
+		return ""
+	}
+	edits := diffLines(splitLines(before), splitLines(after))
+
+	// line numbers in before and after at which each edit applies
+	oldAt, newAt := make([]int, len(edits)+1), make([]int, len(edits)+1)
+	oldAt[0], newAt[0] = 1, 1
+	for k, edit := range edits {
+		oldAt[k+1], newAt[k+1] = oldAt[k], newAt[k]
+		if edit.op != '+' {
+			oldAt[k+1]++
+		}
+		if edit.op != '-' {
+			newAt[k+1]++
+		}
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "--- %s\n+++ %s\n", name, name)
+	for start := 0; start < len(edits); {
+		for start < len(edits) && edits[start].op == ' ' {
+			start++
+		}
+		if start == len(edits) {
+			break
+		}
+		// extend the hunk while changes are close together
+		end := start
+		for end < len(edits) && edits[end].op != ' ' {
+			end++
+		}
+		for end < len(edits) {
+			next := end
+			for next < len(edits) && edits[next].op == ' ' {
+				next++
+			}
+			if next == len(edits) || next-end > 2*diffContext {
+				break
+			}
+			for next < len(edits) && edits[next].op != ' ' {
+				next++
+			}
+			end = next
+		}
+		from, to := max(start-diffContext, 0), min(end+diffContext, len(edits))
+		oldCount, newCount := oldAt[to]-oldAt[from], newAt[to]-newAt[from]
+		oldStart, newStart := oldAt[from], newAt[from]
+		if oldCount == 0 {
+			oldStart--
+		}
+		if newCount == 0 {
+			newStart--
+		}
+		fmt.Fprintf(&builder, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+		for _, edit := range edits[from:to] {
+			builder.WriteByte(edit.op)
+			builder.WriteString(edit.text)
+			builder.WriteByte('\n')
+		}
+		start = to
+	}
+	return builder.String()
+}
+
+func splitLines(s string) []string {
+	lines := strings.Split(s, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes an edit script turning a into b from their longest
+// common subsequence
+func diffLines(a, b []string) []diffLine {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var edits []diffLine
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			edits = append(edits, diffLine{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, diffLine{'-', a[i]})
+			i++
+		default:
+			edits = append(edits, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	return edits
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/formatter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// fmtWrite rewrites files in place (fmt --write)
+	fmtWrite bool
+	// fmtDiff prints the changes instead of the formatted source (fmt --diff)
+	fmtDiff bool
+)
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt <file|dir>...",
+	Short: "Format Zeno source files",
+	Long: `Formats Zeno source files (.zeno) in the canonical layout. Directories are
+walked recursively. By default the formatted source is printed; --write
+updates the files in place and --diff shows what would change.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		failed := false
+		for _, pathArg := range args {
+			files, err := zenoFiles(pathArg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error accessing path %s: %v\n", pathArg, err)
+				failed = true
+				continue
+			}
+			for _, file := range files {
+				if err := formatFile(file); err != nil {
+					fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+					failed = true
+				}
+			}
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// zenoFiles returns path if it is a file, or the Zeno sources below it if
+// it is a directory
+func zenoFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	var files []string
+	err = filepath.WalkDir(path, func(current string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && (strings.HasSuffix(current, ".zeno") || strings.HasSuffix(current, ".zn")) {
+			files = append(files, current)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// formatFile formats one file according to the --write and --diff flags
+func formatFile(filename string) error {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	formatted, err := formatter.Source(src)
+	if err != nil {
+		return err
+	}
+	if fmtDiff {
+		fmt.Print(unifiedDiff(filename, string(src), string(formatted)))
+	}
+	if fmtWrite {
+		if string(formatted) == string(src) {
+			return nil
+		}
+		if err := os.WriteFile(filename, formatted, 0644); err != nil {
+			return err
+		}
+		fmt.Println(filename)
+	}
+	if !fmtDiff && !fmtWrite {
+		os.Stdout.Write(formatted)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/linkalls/zeno-lang/deps"
+	"github.com/spf13/cobra"
+)
+
+var getCmd = &cobra.Command{
+	Use:   "get [repository[@constraint]]...",
+	Short: "Fetch remote modules and pin their versions",
+	Long: `Fetches repositories whose modules are imported by path, as in
+import { x } from "github.com/user/pkg/module", into the module cache and pins
+their versions in ` + deps.LockFile + `, in the current directory or the nearest
+parent directory holding one.
+
+The highest version tag satisfying the constraint is fetched, any version by
+default, as in zeno get github.com/user/pkg@^1.2.0. A repository without
+version tags is pinned to the latest commit of its default branch. Without
+arguments, the versions already pinned are fetched.
+
+The module cache is $ZENO_MODCACHE, or zeno/mod in the user cache directory.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		lock, err := deps.ReadLock(".")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Get failed: %v\n", err)
+			os.Exit(1)
+		}
+		if len(args) == 0 {
+			if err := deps.Sync(lock); err != nil {
+				fmt.Fprintf(os.Stderr, "Get failed: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Fprintf(messages(), "Fetched %d dependencies\n", len(lock.Deps))
+			return
+		}
+		for _, spec := range args {
+			dep, err := deps.Get(lock, spec)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Get failed: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Fprintf(messages(), "Fetched %s %s\n", dep.Repo, dep.Version)
+		}
+		if err := lock.Write(); err != nil {
+			fmt.Fprintf(os.Stderr, "Get failed: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/generator"
+)
+
+// goErrorPattern matches Go compiler diagnostics such as
+// "/tmp/zeno_build_123/main.go:12:5: undefined: foo".
+var goErrorPattern = regexp.MustCompile(`^(.*\.go):(\d+):(?:(\d+):)? (.*)$`)
+
+// zenoErrorPattern matches the ones the line directives of the generated
+// code point at Zeno sources, such as "/home/me/app.zeno:4: undefined: foo".
+var zenoErrorPattern = regexp.MustCompile(`^(.*\.(?:zeno|zn)):(\d+):(?:\d+:)? (.*)$`)
+
+// translateGoBuildOutput rewrites Go compiler output that refers to one of
+// the generated files in sourceMaps so that it points at the originating
+// Zeno code. Other lines are passed through unchanged, except for package
+// headers such as "# command-line-arguments" which mean nothing to users.
+func translateGoBuildOutput(output string, sourceMaps map[string]*generator.SourceMap) string {
+	var builder strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		if strings.HasPrefix(line, "# ") {
+			continue
+		}
+		if match := zenoErrorPattern.FindStringSubmatch(line); match != nil {
+			file := match[1]
+			if rel, err := filepath.Rel(".", file); err == nil && !strings.HasPrefix(rel, "..") {
+				file = rel
+			}
+			builder.WriteString(fmt.Sprintf("error: %s\n  --> %s:%s\n", match[3], file, match[2]))
+			continue
+		}
+		match := goErrorPattern.FindStringSubmatch(line)
+		var sourceMap *generator.SourceMap
+		if match != nil {
+			sourceMap = lookupSourceMap(sourceMaps, match[1])
+		}
+		if sourceMap == nil {
+			builder.WriteString(line + "\n")
+			continue
+		}
+		goLine, _ := strconv.Atoi(match[2])
+		message := match[4]
+		loc, ok := sourceMap.Lookup(goLine)
+		if !ok {
+			builder.WriteString(fmt.Sprintf("error: %s\n  --> generated Go line %d\n", message, goLine))
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("error: %s\n", message))
+		if loc.Line > 0 {
+			builder.WriteString(fmt.Sprintf("  --> %s:%d\n", loc.File, loc.Line))
+		} else {
+			builder.WriteString(fmt.Sprintf("  --> %s\n", loc.File))
+		}
+		if loc.Function != "" {
+			builder.WriteString(fmt.Sprintf("   | in function '%s': %s\n", loc.Function, loc.Statement))
+		} else {
+			builder.WriteString(fmt.Sprintf("   | %s\n", loc.Statement))
+		}
+	}
+	return builder.String()
+}
+
+// lookupSourceMap returns the source map of the generated file at path, or
+// nil if path is not a generated file. Go reports paths absolute or relative
+// to the working directory, so files are matched on the end of their path.
+func lookupSourceMap(sourceMaps map[string]*generator.SourceMap, path string) *generator.SourceMap {
+	path = filepath.ToSlash(filepath.Clean(path))
+	for strings.HasPrefix(path, "../") {
+		path = strings.TrimPrefix(path, "../")
+	}
+	for file, sourceMap := range sourceMaps {
+		file = filepath.ToSlash(file)
+		if file == path || strings.HasSuffix(file, "/"+path) {
+			return sourceMap
+		}
+	}
+	return nil
+}
@@ -1,23 +1,46 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 
+	"github.com/linkalls/zeno-lang/diagnostics"
 	"github.com/linkalls/zeno-lang/generator"
+	"github.com/linkalls/zeno-lang/i18n"
 	"github.com/linkalls/zeno-lang/lexer"
 	"github.com/linkalls/zeno-lang/linter"
 	"github.com/linkalls/zeno-lang/parser"
+	"github.com/linkalls/zeno-lang/stdlib"
+	"github.com/linkalls/zeno-lang/typechecker"
 	"github.com/spf13/cobra"
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "zeno",
 	Short: "Zeno Language Compiler and Tools",
-	Long:  `Zeno is a programming language. This CLI provides tools to compile, run, build, and lint Zeno source files.`,
+	Long:  `Zeno is a programming language. This CLI provides tools to compile, run, build, lint, and format Zeno source files, and an interactive REPL.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// --lang wins over ZENO_LANG and the system locale
+		if language == "" {
+			language = i18n.DetectLanguage()
+		}
+		if quiet && verbose {
+			return fmt.Errorf("--quiet and --verbose cannot be used together")
+		}
+		if stdPath != "" {
+			stdlib.Dir = stdPath
+		}
+		if outputFormat != "" && !diagnostics.IsFormat(outputFormat) {
+			return fmt.Errorf("unknown --format %q, expected one of: %s", outputFormat, strings.Join(diagnostics.Formats, ", "))
+		}
+		return i18n.SetLanguage(language)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		// Default behavior if no subcommand is given, or print help
 		if len(args) == 0 {
@@ -26,7 +49,7 @@ var rootCmd = &cobra.Command{
 		}
 		// Backward compatibility: if first arg is a .zeno file, try to run it
 		if strings.HasSuffix(args[0], ".zeno") {
-			fmt.Println("Executing default action (run) for .zeno file.")
+			fmt.Fprintln(progress(), "Executing default action (run) for .zeno file.")
 			runCmd.Run(cmd, args)
 		} else {
 			cmd.Help()
@@ -35,12 +58,34 @@ var rootCmd = &cobra.Command{
 }
 
 var runCmd = &cobra.Command{
-	Use:   "run <filename.zeno>",
+	Use:   "run <filename.zeno> [-- args...]",
 	Short: "Compile and run a Zeno file",
-	Args:  cobra.ExactArgs(1),
+	Long: `Compiles and runs a Zeno file. The arguments after the file, or after --
+to pass ones that look like flags, are passed to the program, which reads them
+with args(). The program's stdin is the terminal's. With --watch, the file
+and the modules it imports are checked for changes every 300ms, by polling
+their modification times.`,
+	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("=== Zeno Run Command ===\n")
-		if err := runFile(args[0]); err != nil {
+		if dash := cmd.ArgsLenAtDash(); dash > 1 {
+			fmt.Fprintf(os.Stderr, "Run failed: expected a single file before --, got: %s\n", strings.Join(args[:dash], " "))
+			os.Exit(1)
+		}
+		fmt.Fprintf(progress(), "=== Zeno Run Command ===\n")
+		if watchFiles {
+			watch(args[0], func(stop <-chan struct{}) {
+				if err := runFileUntil(args[0], args[1:], stop); err != nil {
+					fmt.Fprintf(os.Stderr, "Run failed: %v\n", err)
+				}
+			})
+			return
+		}
+		if err := runFile(args[0], args[1:]); err != nil {
+			// The program ran: its exit status is the one of run
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				os.Exit(exitErr.ExitCode())
+			}
 			fmt.Fprintf(os.Stderr, "Run failed: %v\n", err)
 			os.Exit(1)
 		}
@@ -48,26 +93,66 @@ var runCmd = &cobra.Command{
 }
 
 var compileCmd = &cobra.Command{
-	Use:   "compile <filename.zeno>",
-	Short: "Compile a Zeno file to Go",
-	Long:  `Compiles a Zeno source file (.zeno) into a Go source file (.go) in the same directory.`,
-	Args:  cobra.ExactArgs(1),
+	Use:   "compile <file|dir|pattern>...",
+	Short: "Compile Zeno files to Go",
+	Long: `Compiles Zeno source files (.zeno, .zn) into Go source files (.go) in the
+same directory, or in the same relative location under --out-dir. Directories
+are walked recursively and glob patterns such as src/*.zeno are expanded.
+Modules are compiled before the files that import them. The lint rules the
+compiler does not already check are run on each file, and their issues are
+reported as warnings, or as errors with --lint=error.`,
+	Args:    cobra.MinimumNArgs(1),
+	PreRunE: checkLintMode,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("=== Zeno Compile Command ===\n")
-		if err := compileFile(args[0]); err != nil {
+		fmt.Fprintf(progress(), "=== Zeno Compile Command ===\n")
+		inputs, err := compileInputs(args)
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Compilation failed: %v\n", err)
 			os.Exit(1)
 		}
+		failed := 0
+		for _, input := range dependencyOrder(inputs) {
+			if err := compileFile(input.file, input.outputPath(compileOutDir)); err != nil {
+				fmt.Fprintf(os.Stderr, "Compilation of %s failed: %v\n", input.file, err)
+				failed++
+			}
+		}
+		writeDiagnostics()
+		if len(inputs) > 1 {
+			fmt.Fprintf(messages(), "Compiled %d file(s), %d failed\n", len(inputs)-failed, failed)
+		}
+		if failed > 0 {
+			os.Exit(1)
+		}
 	},
 }
 
 var buildCmd = &cobra.Command{
 	Use:   "build <filename.zeno>",
 	Short: "Compile a Zeno file to an executable",
-	Args:  cobra.ExactArgs(1),
+	Long: `Compiles a Zeno file, and the modules it imports, to an executable with
+the Go toolchain. The lint rules the compiler does not already check are run
+on the file, and their issues are reported as warnings, or as errors with
+--lint=error. With --watch, the file and the modules it imports are checked
+for changes every 300ms, by polling their modification times.`,
+	Args:    cobra.ExactArgs(1),
+	PreRunE: checkLintMode,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("=== Zeno Build Command ===\n")
-		if err := buildExecutable(args[0]); err != nil {
+		fmt.Fprintf(progress(), "=== Zeno Build Command ===\n")
+		if watchFiles {
+			watch(args[0], func(<-chan struct{}) {
+				err := buildExecutable(args[0])
+				writeDiagnostics()
+				reported = nil
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Build failed: %v\n", err)
+				}
+			})
+			return
+		}
+		err := buildExecutable(args[0])
+		writeDiagnostics()
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Build failed: %v\n", err)
 			os.Exit(1)
 		}
@@ -80,10 +165,32 @@ var lintCmd = &cobra.Command{
 	Long: `Lints Zeno source files (.zeno) for potential issues, including naming conventions,
 unused variables, unused functions, and unused imports.
 You can specify one or more file paths or directories.
-If a directory is specified, it will be walked recursively for .zeno files.`,
-	Args: cobra.MinimumNArgs(1),
+If a directory is specified, it will be walked recursively for .zeno files.
+
+The rules are configured by the nearest .zenolint.toml found in the
+directory of each file or its parents, which can turn rules off, set the
+severity of their issues and exclude paths. --init writes one listing the
+rules to the given directory, or the current one. Issues with the info
+severity are reported without failing the command.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if lintInit {
+			return cobra.MaximumNArgs(1)(cmd, args)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("=== Zeno Lint Command ===\n")
+		if lintInit {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			if err := initLintConfig(dir); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+		fmt.Fprintf(progress(), "=== Zeno Lint Command ===\n")
 		var allIssues []linter.Issue
 		hasErrors := false
 
@@ -121,7 +228,17 @@ If a directory is specified, it will be walked recursively for .zeno files.`,
 			}
 
 			for _, filePath := range filesToLint {
-				fmt.Printf("Linting file: %s\n", filePath)
+				config, err := lintConfig(filePath)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					hasErrors = true
+					break
+				}
+				if config.Excluded(filePath) {
+					fmt.Fprintf(progress(), "Skipping excluded file: %s\n", filePath)
+					continue
+				}
+				fmt.Fprintf(progress(), "Linting file: %s\n", filePath)
 				content, err := os.ReadFile(filePath)
 				if err != nil {
 					fmt.Fprintf(os.Stderr, "Error reading file %s: %v\n", filePath, err)
@@ -129,45 +246,40 @@ If a directory is specified, it will be walked recursively for .zeno files.`,
 					continue
 				}
 
-				l := lexer.New(string(content))
-				p := parser.NewWithInput(l, filePath, string(content))
-				program := p.ParseProgram()
-
-				if len(p.Errors()) > 0 {
-					fmt.Fprintf(os.Stderr, "Parser errors in %s:\n\n", filePath)
-					// Display detailed errors if available
-					detailedErrors := p.DetailedErrors()
-					if len(detailedErrors) > 0 {
-						for _, err := range detailedErrors {
-							fmt.Fprintf(os.Stderr, "%s", err.String())
-							fmt.Fprintf(os.Stderr, "\n")
-						}
-					} else {
-						// Fallback to simple errors
-						for _, msg := range p.Errors() {
-							fmt.Fprintf(os.Stderr, "  - %s\n", msg)
-						}
-					}
+				issues, err := lintSource(filePath, string(content), config)
+				if err != nil {
 					hasErrors = true
 					continue
 				}
 
-				absFilePath, _ := filepath.Abs(filePath)
-
-				// Initialize linter and register rules
-				rules := []linter.Rule{
-					&linter.UnusedVariableRule{},
-					&linter.UnusedFunctionRule{},
-					&linter.FunctionNameRule{},
-					&linter.VariableNameRule{},
-					&linter.UnusedImportRule{},
-				}
-				zenoFrameworkLinter := linter.NewLinter(rules)
-
-				issues, err := zenoFrameworkLinter.Lint(program, absFilePath)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Linter error in %s: %v\n", filePath, err)
-					hasErrors = true
+				if lintFix {
+					// Fixes that conflict with others, or that others make
+					// possible, are applied by linting the fixed file again
+					fixed, total := string(content), 0
+					for pass := 0; pass < maxFixPasses; pass++ {
+						var count int
+						fixed, count = linter.ApplyFixes(fixed, issues)
+						if count == 0 {
+							break
+						}
+						total += count
+						if issues, err = lintSource(filePath, fixed, config); err != nil {
+							break
+						}
+					}
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "Fixes left %s invalid, so it was not changed\n", filePath)
+						hasErrors = true
+						continue
+					}
+					if total > 0 {
+						if err := os.WriteFile(filePath, []byte(fixed), 0644); err != nil {
+							fmt.Fprintf(os.Stderr, "Error writing fixes to %s: %v\n", filePath, err)
+							hasErrors = true
+						} else {
+							fmt.Fprintf(messages(), "Applied %d fix(es) to %s\n", total, filePath)
+						}
+					}
 				}
 
 				if len(issues) > 0 {
@@ -177,23 +289,25 @@ If a directory is specified, it will be walked recursively for .zeno files.`,
 		}
 
 		if len(allIssues) > 0 {
-			fmt.Printf("\nFound %d linting issue(s):\n", len(allIssues))
+			fmt.Fprintf(messages(), "\nFound %d linting issue(s):\n", len(allIssues))
+			report := diagnostics.Reporter(&reported)
+			if !machineOutput() {
+				report = diagnostics.Printer{W: os.Stdout, Sources: make(map[string]string)}
+			}
 			for _, issue := range allIssues {
-				// Use 1 if line/col is 0 from placeholder
-				line := issue.Line
-				if line == 0 {
-					line = 1
+				d := issue.Diagnostic()
+				if issue.Fix != nil {
+					d.Suggestion += " (run with --fix to apply)"
 				}
-				col := issue.Column
-				if col == 0 {
-					col = 1
+				report.Report(d)
+				if issue.Severity != diagnostics.Info {
+					hasErrors = true // Ensure exit code reflects issues found
 				}
-				fmt.Printf("%s:%d:%d: [%s] %s\n", issue.Filepath, line, col, issue.RuleName, issue.Message)
 			}
-			hasErrors = true // Ensure exit code reflects issues found
 		} else {
-			fmt.Println("No linting issues found.")
+			fmt.Fprintln(messages(), "No linting issues found.")
 		}
+		writeDiagnostics()
 
 		if hasErrors {
 			os.Exit(1)
@@ -201,12 +315,148 @@ If a directory is specified, it will be walked recursively for .zeno files.`,
 	},
 }
 
+// lintConfigs caches the lint configuration of each directory
+var lintConfigs = make(map[string]*linter.Config)
+
+// lintConfig returns the configuration of the lint rules for file, read from
+// the nearest .zenolint.toml
+func lintConfig(file string) (*linter.Config, error) {
+	dir := filepath.Dir(file)
+	if config, ok := lintConfigs[dir]; ok {
+		return config, nil
+	}
+	config, err := linter.LoadConfig(dir)
+	if err != nil {
+		return nil, err
+	}
+	lintConfigs[dir] = config
+	return config, nil
+}
+
+// initLintConfig writes a configuration file listing the lint rules to dir
+func initLintConfig(dir string) error {
+	path := filepath.Join(dir, linter.ConfigFile)
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	if err := os.WriteFile(path, []byte(linter.InitConfig()), 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(messages(), "Created %s\n", path)
+	return nil
+}
+
+// maxFixPasses bounds the number of times lint --fix lints a file again to
+// apply the fixes left by the previous pass
+const maxFixPasses = 10
+
+// lintSource parses content, the text of filePath, and returns its lint
+// issues with their fixes. Parser and linter errors are reported.
+func lintSource(filePath, content string, config *linter.Config) ([]linter.Issue, error) {
+	// Comments are kept for the zeno-lint: suppression comments
+	p := parser.NewWithInput(lexer.NewWithComments(content), filePath, content)
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		diagnostics.ReportAll(reporter(map[string]string{filePath: content}), p.Diagnostics())
+		return nil, fmt.Errorf("parser errors found")
+	}
+
+	absFilePath, _ := filepath.Abs(filePath)
+	zenoFrameworkLinter := linter.NewLinter(linter.DefaultRules())
+	zenoFrameworkLinter.SetConfig(config)
+	issues, err := zenoFrameworkLinter.Lint(program, absFilePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Linter error in %s: %v\n", filePath, err)
+		return nil, err
+	}
+	zenoFrameworkLinter.AddFixes(issues, content)
+	return issues, nil
+}
+
+var explainCmd = &cobra.Command{
+	Use:   "explain [code]",
+	Short: "Explain a diagnostic code",
+	Long: `Prints a detailed description of a diagnostic code (such as Z0203) with an
+example that triggers it and how to fix it. Without arguments, lists all codes.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			for _, code := range i18n.Codes() {
+				e, _ := i18n.Explain(code)
+				fmt.Printf("%s  %s\n", code, e.Title)
+			}
+			return
+		}
+		e, ok := i18n.Explain(args[0])
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Unknown diagnostic code: %s\n", args[0])
+			os.Exit(1)
+		}
+		fmt.Printf("%s: %s\n\n%s\n", e.Code, e.Title, e.Description)
+		if e.Example != "" {
+			fmt.Printf("\nExample:\n\n%s\n", indent(e.Example))
+		}
+		if e.Fix != "" {
+			fmt.Printf("\nFix:\n\n%s\n", indent(e.Fix))
+		}
+	},
+}
+
+// indent prefixes every line of s with four spaces
+func indent(s string) string {
+	return "    " + strings.ReplaceAll(s, "\n", "\n    ")
+}
+
 func init() {
 	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(compileCmd)
 	rootCmd.AddCommand(buildCmd)
 	rootCmd.AddCommand(lintCmd)
-	// Potentially add flags here, e.g., for -jp (Japanese error messages) if Cobra handles them globally
+	rootCmd.AddCommand(checkCmd)
+	rootCmd.AddCommand(cleanCmd)
+	rootCmd.AddCommand(getCmd)
+	rootCmd.AddCommand(testCmd)
+	rootCmd.AddCommand(explainCmd)
+	rootCmd.AddCommand(fmtCmd)
+	rootCmd.AddCommand(replCmd)
+	rootCmd.AddCommand(astCmd)
+	rootCmd.AddCommand(tokensCmd)
+	lintCmd.Flags().BoolVar(&lintInit, "init", false, "Write a "+linter.ConfigFile+" listing the rules instead of linting")
+	lintCmd.Flags().BoolVar(&lintFix, "fix", false, "Apply automatic fixes (such as missing imports) to the linted files")
+	fmtCmd.Flags().BoolVarP(&fmtWrite, "write", "w", false, "Write the formatted source back to the files")
+	astCmd.Flags().BoolVar(&astTree, "tree", false, "Print an indented tree instead of JSON")
+	tokensCmd.Flags().BoolVar(&tokensJSON, "json", false, "Print the tokens as JSON")
+	tokensCmd.Flags().BoolVar(&tokensComments, "comments", false, "Include the comments")
+	fmtCmd.Flags().BoolVarP(&fmtDiff, "diff", "d", false, "Print a diff of the changes instead of the formatted source")
+	for _, cmd := range []*cobra.Command{runCmd, compileCmd, buildCmd, checkCmd, testCmd} {
+		cmd.Flags().StringArrayVarP(&buildDefines, "define", "D", nil, "Define a build constant NAME=VALUE, readable as build.NAME")
+		cmd.Flags().BoolVar(&allowUnused, "allow-unused", false, "Report unused variables and functions as warnings instead of errors")
+	}
+	buildCmd.Flags().StringVarP(&buildFlags.output, "output", "o", "", "Path of the executable (default: the name of the source file)")
+	buildCmd.Flags().StringVar(&buildFlags.goos, "os", "", "Target operating system, as GOOS (default: the host's)")
+	buildCmd.Flags().StringVar(&buildFlags.goarch, "arch", "", "Target architecture, as GOARCH (default: the host's)")
+	buildCmd.Flags().BoolVar(&buildFlags.release, "release", false, "Build a smaller executable without debug information or file paths")
+	buildCmd.Flags().BoolVar(&buildFlags.keepGo, "keep-go", false, "Keep the generated Go code in <executable>_go/ for inspection")
+	for _, cmd := range []*cobra.Command{lintCmd, compileCmd, buildCmd, checkCmd} {
+		cmd.Flags().StringVar(&outputFormat, "format", "text", "Output format for diagnostics: text, json or sarif")
+	}
+	for _, cmd := range []*cobra.Command{runCmd, compileCmd, buildCmd} {
+		cmd.Flags().BoolVar(&debugPanics, "debug", false, "Print the full Go stack trace of panics instead of a Zeno message")
+	}
+	for _, cmd := range []*cobra.Command{runCmd, compileCmd, buildCmd, testCmd} {
+		cmd.Flags().BoolVar(&checkOverflow, "check-overflow", false, "Panic when an int operation overflows instead of wrapping around")
+	}
+	runCmd.Flags().BoolVarP(&watchFiles, "watch", "w", false, "Rebuild, and restart the program, when the file or a module it imports changes")
+	buildCmd.Flags().BoolVarP(&watchFiles, "watch", "w", false, "Rebuild when the file or a module it imports changes")
+	for _, cmd := range []*cobra.Command{compileCmd, buildCmd} {
+		cmd.Flags().StringVar(&lintMode, "lint", lintWarn, "Report lint issues as warnings (warn), as errors (error) or not at all (off)")
+	}
+	compileCmd.Flags().StringVar(&compileOutDir, "out-dir", "", "Write the Go files to this directory, keeping the layout of the sources")
+	rootCmd.PersistentFlags().BoolVar(&werror, "werror", false, "Treat warnings as errors")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Print errors only")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Print progress messages")
+	rootCmd.PersistentFlags().StringVar(&stdPath, "std-path", "", "Directory searched for standard library modules before the built-in ones; defaults to $ZENO_HOME/std")
+	rootCmd.PersistentFlags().StringVar(&language, "lang", "", "Language for diagnostics (en, ja); defaults to $ZENO_LANG or the system locale")
 }
 
 func main() {
@@ -217,57 +467,378 @@ func main() {
 	}
 }
 
-// --- Existing helper functions (compileFile, runFile, buildExecutable) ---
-// These are kept as they are called by the new Cobra commands.
+var (
+	// werror promotes warnings to errors (--werror)
+	werror bool
+	// quiet prints errors only (--quiet)
+	quiet bool
+	// verbose prints progress messages (--verbose)
+	verbose bool
+	// language selects the diagnostic message catalog (--lang)
+	language string
+	// stdPath is a directory searched for standard library modules before
+	// the built-in ones (--std-path)
+	stdPath string
+	// lintFix applies automatic fixes in the lint command (--fix)
+	lintFix bool
+	// lintInit scaffolds a lint configuration file (lint --init)
+	lintInit bool
+	// lintMode sets how compile and build report lint issues (--lint)
+	lintMode string
+	// buildDefines holds the -D NAME=VALUE build constants
+	buildDefines []string
+	// testProgram generates programs running the tests of the files
+	// instead of their main functions (zeno test)
+	testProgram bool
+	// watchFiles rebuilds when the sources change (--watch)
+	watchFiles bool
+	// compileOutDir is the directory compile writes Go files to (--out-dir)
+	compileOutDir string
+	// buildFlags holds the flags of the build command
+	buildFlags buildOptions
+	// allowUnused demotes unused variables and functions to warnings
+	// (--allow-unused)
+	allowUnused bool
+	// debugPanics leaves panics to the Go runtime, which prints the full
+	// stack trace (--debug)
+	debugPanics bool
+	// checkOverflow makes the int operations panic on overflow
+	// (--check-overflow)
+	checkOverflow bool
+	// outputFormat selects how diagnostics are printed (--format)
+	outputFormat string
+	// reported collects the diagnostics written by writeDiagnostics when
+	// they are printed in a machine readable format
+	reported diagnostics.List
+)
 
-func compileFile(filename string) error {
-	content, err := os.ReadFile(filename)
-	if err != nil {
-		return fmt.Errorf("failed to read file %s: %w", filename, err)
+// machineOutput reports whether diagnostics are printed as JSON or SARIF on
+// stdout, in which case the other messages go to stderr
+func machineOutput() bool {
+	return outputFormat == "json" || outputFormat == "sarif"
+}
+
+// reporter returns where the phases report their diagnostics. sources
+// holds the contents of the files already read, by name.
+func reporter(sources map[string]string) diagnostics.Reporter {
+	if machineOutput() {
+		return &reported
 	}
-	// fmt.Printf("Compiling file: %s\n", filename) // Cobra command will print this
-	// fmt.Printf("Source code:\n%s\n", string(content)) // Too verbose for default compile
+	printer := diagnostics.Printer{W: os.Stderr, Sources: sources}
+	if quiet {
+		return errorsOnly{printer}
+	}
+	return printer
+}
+
+// errorsOnly is a Reporter that drops the diagnostics that are not errors,
+// for --quiet
+type errorsOnly struct {
+	diagnostics.Reporter
+}
+
+func (r errorsOnly) Report(d diagnostics.Diagnostic) {
+	if d.Severity == diagnostics.Error {
+		r.Reporter.Report(d)
+	}
+}
+
+// messages returns where the outcome of a command, such as the files it
+// wrote, is printed: nowhere with --quiet, and stderr when stdout holds
+// machine readable diagnostics
+func messages() io.Writer {
+	if quiet {
+		return io.Discard
+	}
+	if machineOutput() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// progress returns where progress messages, such as the files being
+// processed, are printed: stderr with --verbose, so that they never mix
+// with the output of a program, and nowhere otherwise
+func progress() io.Writer {
+	if verbose {
+		return os.Stderr
+	}
+	return io.Discard
+}
+
+// writeDiagnostics prints the collected diagnostics to stdout in the
+// machine readable format, if one was chosen
+func writeDiagnostics() {
+	if !machineOutput() {
+		return
+	}
+	if err := diagnostics.Write(os.Stdout, outputFormat, reported); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing diagnostics: %v\n", err)
+	}
+}
+
+// generatedCode is the output of generateGoCode
+type generatedCode struct {
+	goCode    string
+	sourceMap *generator.SourceMap
+	// goModules lists third-party Go modules the code depends on
+	goModules []string
+	// workspace holds the main package and the packages of user modules
+	workspace *generator.Workspace
+}
 
-	l := lexer.New(string(content))
-	p := parser.NewWithInput(l, filename, string(content))
+// generateGoCode parses and generates Go code for a Zeno source file,
+// printing the diagnostics of all phases to stderr.
+func generateGoCode(filename, content string) (*generatedCode, error) {
+	l := lexer.New(content)
+	p := parser.NewWithInput(l, filename, content)
 	program := p.ParseProgram()
+	report := reporter(map[string]string{filename: content})
 
 	if len(p.Errors()) > 0 {
-		fmt.Fprintf(os.Stderr, "Parser errors in %s:\n\n", filename)
-		detailedErrors := p.DetailedErrors()
-		if len(detailedErrors) > 0 {
-			for _, err := range detailedErrors {
-				fmt.Fprintf(os.Stderr, "%s", err.String())
-				fmt.Fprintf(os.Stderr, "\n")
-			}
-		} else {
-			for _, msg := range p.Errors() {
-				fmt.Fprintf(os.Stderr, "  - %s\n", msg)
+		diagnostics.ReportAll(report, p.Diagnostics())
+		return nil, fmt.Errorf("parser errors found")
+	}
+
+	if typeErrors := typechecker.Check(program, filename); len(typeErrors) > 0 {
+		for _, err := range typeErrors {
+			report.Report(err.Diagnostic(filename))
+		}
+		return nil, fmt.Errorf("type errors found")
+	}
+
+	constants, err := generator.ParseBuildConstants(buildDefines)
+	if err != nil {
+		return nil, err
+	}
+	gen := generator.NewGenerator()
+	gen.SetBuildConstants(constants)
+	gen.SetCache(newFileCache(filename))
+	options := generator.GeneratorOptions{Test: testProgram, Debug: debugPanics, CheckOverflow: checkOverflow}
+	if allowUnused {
+		options.Strictness = generator.StrictnessWarn
+	}
+	gen.SetOptions(options)
+	workspace, err := gen.GenerateWorkspace(program, filename)
+
+	found := diagnostics.List(p.Diagnostics())
+	for _, w := range gen.Warnings() {
+		found.Report(w.Diagnostic(filename))
+	}
+	genErrs := generator.Errors(err)
+	for _, genErr := range genErrs {
+		found.Report(genErr.Diagnostic(filename))
+	}
+	diagnostics.ReportAll(report, found)
+
+	if len(genErrs) > 0 {
+		return nil, fmt.Errorf("generation errors found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("generation error: %w", err)
+	}
+	if warningCount := found.Count(diagnostics.Warning); werror && warningCount > 0 {
+		return nil, fmt.Errorf("%d warning(s) treated as errors (--werror)", warningCount)
+	}
+	return &generatedCode{
+		goCode:    workspace.Main().Code,
+		sourceMap: workspace.Main().SourceMap,
+		goModules: workspace.GoModules(),
+		workspace: workspace,
+	}, nil
+}
+
+// hasUserPackages reports whether the code imports user modules and thus
+// has to be built as a Go module
+func (code *generatedCode) hasUserPackages() bool {
+	return len(code.workspace.Packages) > 1
+}
+
+// writeWorkspace writes the packages of user modules below dir and the main
+// package to mainFile, which must be in dir. With directives, the code
+// carries //line directives pointing Go errors and panics at the Zeno
+// sources, for code built and run without zeno translating them.
+func writeWorkspace(dir, mainFile string, code *generatedCode, directives bool) error {
+	for _, pkg := range code.workspace.Packages {
+		path := mainFile
+		if pkg.Dir != "" {
+			path = filepath.Join(dir, filepath.FromSlash(pkg.File()))
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return fmt.Errorf("failed to create package directory: %w", err)
 			}
 		}
-		return fmt.Errorf("parser errors found")
+		goCode := pkg.Code
+		if directives {
+			goCode = pkg.SourceMap.LineDirectives(goCode, filepath.Base(path))
+		}
+		if err := os.WriteFile(path, []byte(goCode), 0644); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// sourceMaps maps the generated Go files written by writeWorkspace to their
+// source maps
+func sourceMaps(dir, mainFile string, code *generatedCode) map[string]*generator.SourceMap {
+	maps := map[string]*generator.SourceMap{mainFile: code.sourceMap}
+	for _, pkg := range code.workspace.Packages[1:] {
+		maps[filepath.Join(dir, filepath.FromSlash(pkg.File()))] = pkg.SourceMap
+	}
+	return maps
+}
+
+// buildOptions are the flags of the build command
+type buildOptions struct {
+	// output is the path of the executable (-o), by default the name of
+	// the source file
+	output string
+	// goos and goarch select the target platform (--os, --arch), the host
+	// one if empty
+	goos, goarch string
+	// release strips debug information and file paths (--release)
+	release bool
+	// keepGo keeps the generated Go code next to the executable (--keep-go)
+	keepGo bool
+}
+
+// goBuild compiles a generated Go file, written with writeWorkspace, into an
+// executable. Compiler errors are translated back to Zeno sources before
+// being printed. Code that imports user modules or depends on third-party Go
+// modules is built as a module in the directory of goFile.
+func goBuild(goFile, executable string, code *generatedCode, options buildOptions) error {
+	dir := filepath.Dir(goFile)
+	target := goFile
+	if len(code.goModules) > 0 || code.hasUserPackages() {
+		if err := prepareGoModule(dir, code.goModules); err != nil {
+			return err
+		}
+		if abs, err := filepath.Abs(executable); err == nil {
+			executable = abs
+		}
+		target = "."
+	}
+	args := []string{"build", "-o", executable}
+	if options.release {
+		args = append(args, "-trimpath", "-ldflags=-s -w")
+	}
+	cmd := exec.Command("go", append(args, target)...)
+	if target == "." {
+		cmd.Dir = dir
+	}
+	cmd.Env = os.Environ()
+	if options.goos != "" {
+		cmd.Env = append(cmd.Env, "GOOS="+options.goos)
+	}
+	if options.goarch != "" {
+		cmd.Env = append(cmd.Env, "GOARCH="+options.goarch)
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if len(output) > 0 {
+			fmt.Fprint(os.Stderr, translateGoBuildOutput(string(output), sourceMaps(dir, goFile, code)))
+		}
+		return err
+	}
+	return nil
+}
+
+// prepareGoModule writes a go.mod requiring modules ("path version") to dir
+// and downloads them.
+func prepareGoModule(dir string, modules []string) error {
+	if err := writeGoMod(dir, modules); err != nil {
+		return err
+	}
+	if len(modules) == 0 {
+		return nil
+	}
+	cmd := exec.Command("go", "mod", "tidy")
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to download Go dependencies %s: %w\n%s", strings.Join(modules, ", "), err, output)
+	}
+	return nil
+}
+
+// writeGoMod writes the go.mod of a generated workspace to dir
+func writeGoMod(dir string, modules []string) error {
+	var goMod strings.Builder
+	goMod.WriteString("module " + generator.GoModulePath + "\n\ngo 1.21\n")
+	if len(modules) > 0 {
+		goMod.WriteString("\nrequire (\n")
+		for _, module := range modules {
+			goMod.WriteString("\t" + module + "\n")
+		}
+		goMod.WriteString(")\n")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write go.mod: %w", err)
+	}
+	return nil
+}
+
+// --- Existing helper functions (compileFile, runFile, buildExecutable) ---
+// These are kept as they are called by the new Cobra commands.
+
+// compileFile compiles a Zeno file to outputFile, or to a Go module
+// directory next to it when the file imports user modules
+func compileFile(filename, outputFile string) error {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", filename, err)
 	}
+	// fmt.Printf("Compiling file: %s\n", filename) // Cobra command will print this
+	// fmt.Printf("Source code:\n%s\n", string(content)) // Too verbose for default compile
 
-	goCode, err := generator.GenerateWithFile(program, filename)
+	code, err := generateGoCode(filename, string(content))
 	if err != nil {
-		return fmt.Errorf("generation error: %w", err)
+		return err
+	}
+	if err := lintCompiled(filename, string(content)); err != nil {
+		return err
 	}
 
-	outputFile := strings.TrimSuffix(filename, ".zeno") + ".go"
-	if strings.HasSuffix(filename, ".zn") { // also handle .zn
-		outputFile = strings.TrimSuffix(filename, ".zn") + ".go"
+	if code.hasUserPackages() {
+		// User modules are separate packages, so the output is a Go module
+		// directory, e.g. app_go/ with main.go and a directory per module
+		outputDir := strings.TrimSuffix(outputFile, ".go") + "_go"
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory %s: %w", outputDir, err)
+		}
+		if err := writeWorkspace(outputDir, filepath.Join(outputDir, "main.go"), code, true); err != nil {
+			return err
+		}
+		if err := writeGoMod(outputDir, code.goModules); err != nil {
+			return err
+		}
+		fmt.Fprintf(messages(), "✅ Successfully compiled %s to: %s\n", filename, outputDir)
+		fmt.Fprintf(messages(), "   Build it with: cd %s && go build\n", outputDir)
+		return nil
 	}
 
+	if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory %s: %w", filepath.Dir(outputFile), err)
+	}
+	goCode := code.sourceMap.LineDirectives(code.goCode, filepath.Base(outputFile))
 	err = os.WriteFile(outputFile, []byte(goCode), 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write output file %s: %w", outputFile, err)
 	}
 
-	fmt.Printf("✅ Successfully compiled %s to: %s\n", filename, outputFile)
+	fmt.Fprintf(messages(), "✅ Successfully compiled %s to: %s\n", filename, outputFile)
+	for _, module := range code.goModules {
+		fmt.Fprintf(messages(), "   Requires Go module: %s\n", module)
+	}
 	return nil
 }
 
-func runFile(filename string) error {
+// runFile compiles and runs a Zeno file, passing programArgs to the program
+func runFile(filename string, programArgs []string) error {
+	return runFileUntil(filename, programArgs, nil)
+}
+
+// runFileUntil is runFile that kills the program when stop is closed
+func runFileUntil(filename string, programArgs []string, stop <-chan struct{}) error {
 	if !strings.HasSuffix(filename, ".zeno") && !strings.HasSuffix(filename, ".zn") {
 		return fmt.Errorf("expected .zeno or .zn file, got: %s", filename)
 	}
@@ -278,52 +849,56 @@ func runFile(filename string) error {
 	}
 	// fmt.Printf("Running file: %s\n", filename) // Cobra command will print this
 
-	l := lexer.New(string(content))
-	p := parser.NewWithInput(l, filename, string(content))
-	program := p.ParseProgram()
-
-	if len(p.Errors()) > 0 {
-		fmt.Fprintf(os.Stderr, "Parser errors in %s:\n\n", filename)
-		detailedErrors := p.DetailedErrors()
-		if len(detailedErrors) > 0 {
-			for _, err := range detailedErrors {
-				fmt.Fprintf(os.Stderr, "%s", err.String())
-				fmt.Fprintf(os.Stderr, "\n")
-			}
-		} else {
-			for _, msg := range p.Errors() {
-				fmt.Fprintf(os.Stderr, "  - %s\n", msg)
-			}
-		}
-		return fmt.Errorf("parser errors found")
+	code, err := generateGoCode(filename, string(content))
+	if err != nil {
+		return err
 	}
 
-	// fmt.Printf("Generating Go code...\n") // Too verbose
-	goCode, err := generator.GenerateWithFile(program, filename)
+	tempDir, err := os.MkdirTemp("", "zeno_run_*")
 	if err != nil {
-		// fmt.Printf("Generation error details: %v\n", err) // Too verbose
-		return fmt.Errorf("generation error: %w", err)
+		return fmt.Errorf("failed to create temporary directory: %w", err)
 	}
-
-	tempDir := os.TempDir()
-	// Ensure generated Go file does not end with _test.go to allow go run
+	defer os.RemoveAll(tempDir)
+	// Ensure generated Go file does not end with _test.go to allow go build
 	baseName := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
 	tempGoFile := filepath.Join(tempDir, baseName+"_zeno_run.go")
+	tempExecutable := filepath.Join(tempDir, baseName)
 
-	err = os.WriteFile(tempGoFile, []byte(goCode), 0644)
-	if err != nil {
-		return fmt.Errorf("failed to write temporary file %s: %w", tempGoFile, err)
+	// The output of the program is translated with the source maps instead
+	if err := writeWorkspace(tempDir, tempGoFile, code, false); err != nil {
+		return err
 	}
-	defer os.Remove(tempGoFile)
 	// fmt.Printf("Generated temporary Go file: %s\n", tempGoFile)
 
-	cmd := exec.Command("go", "run", tempGoFile)
+	if err := goBuild(tempGoFile, tempExecutable, code, buildOptions{}); err != nil {
+		return fmt.Errorf("failed to compile generated Go code: %w", err)
+	}
+
+	cmd := exec.Command(tempExecutable, programArgs...)
+	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	traceWriter := newPanicTraceWriter(os.Stderr, sourceMaps(tempDir, tempGoFile, code))
+	cmd.Stderr = traceWriter
+	if debugPanics {
+		// The Go stack trace is printed as it is
+		cmd.Stderr = os.Stderr
+	}
 
-	fmt.Println("\n--- Program Output ---")
-	err = cmd.Run()
-	fmt.Println("--- End Output ---")
+	fmt.Fprintln(progress(), "\n--- Program Output ---")
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to run Go program: %w", err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	select {
+	case err = <-exited:
+	case <-stop:
+		cmd.Process.Kill()
+		<-exited
+		err = nil
+	}
+	traceWriter.Flush()
+	fmt.Fprintln(progress(), "--- End Output ---")
 	if err != nil {
 		// fmt.Printf("Go command failed: %v\n", err) // Error is usually printed by cmd.Stderr
 		return fmt.Errorf("failed to run Go program: %w", err)
@@ -342,30 +917,12 @@ func buildExecutable(filename string) error {
 	}
 	// fmt.Printf("Building executable from: %s\n", filename) // Cobra command handles this
 
-	l := lexer.New(string(content))
-	p := parser.NewWithInput(l, filename, string(content))
-	program := p.ParseProgram()
-
-	if len(p.Errors()) > 0 {
-		fmt.Fprintf(os.Stderr, "Parser errors in %s:\n\n", filename)
-		detailedErrors := p.DetailedErrors()
-		if len(detailedErrors) > 0 {
-			for _, err := range detailedErrors {
-				fmt.Fprintf(os.Stderr, "%s", err.String())
-				fmt.Fprintf(os.Stderr, "\n")
-			}
-		} else {
-			for _, msg := range p.Errors() {
-				fmt.Fprintf(os.Stderr, "  - %s\n", msg)
-			}
-		}
-		return fmt.Errorf("parser errors found")
-	}
-
-	// fmt.Printf("Generating Go code...\n")
-	goCode, err := generator.GenerateWithFile(program, filename)
+	code, err := generateGoCode(filename, string(content))
 	if err != nil {
-		return fmt.Errorf("generation error: %w", err)
+		return err
+	}
+	if err := lintCompiled(filename, string(content)); err != nil {
+		return err
 	}
 
 	baseName := strings.TrimSuffix(filename, ".zeno")
@@ -373,33 +930,50 @@ func buildExecutable(filename string) error {
 		baseName = strings.TrimSuffix(filename, ".zn")
 	}
 
-	// Create a temporary directory for the build process
-	buildDir, err := os.MkdirTemp("", "zeno_build_*")
-	if err != nil {
-		return fmt.Errorf("failed to create temporary build directory: %w", err)
+	executableName := buildFlags.output
+	if executableName == "" {
+		executableName = filepath.Base(baseName) // Executable in current dir, not temp
+		if buildFlags.goos == "windows" || (buildFlags.goos == "" && runtime.GOOS == "windows") {
+			executableName += ".exe"
+		}
 	}
-	defer os.RemoveAll(buildDir) // Clean up the temporary directory
 
-	goFile := filepath.Join(buildDir, filepath.Base(baseName)+".go")
-	executableName := filepath.Base(baseName) // Executable in current dir, not temp
-
-	err = os.WriteFile(goFile, []byte(goCode), 0644)
-	if err != nil {
-		return fmt.Errorf("failed to write Go file %s: %w", goFile, err)
+	var buildDir string
+	if buildFlags.keepGo {
+		// The generated code is kept next to the executable, e.g. app_go/
+		buildDir = strings.TrimSuffix(executableName, ".exe") + "_go"
+		if err := os.MkdirAll(buildDir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", buildDir, err)
+		}
+	} else {
+		// Create a temporary directory for the build process
+		buildDir, err = os.MkdirTemp("", "zeno_build_*")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary build directory: %w", err)
+		}
+		defer os.RemoveAll(buildDir) // Clean up the temporary directory
 	}
-	// fmt.Printf("Generated Go file: %s\n", goFile)
 
-	cmd := exec.Command("go", "build", "-o", executableName, goFile)
-	cmd.Stdout = os.Stdout // Show build output/errors directly
-	cmd.Stderr = os.Stderr
-	// fmt.Printf("Building executable: %s\n", executableName)
+	goFile := filepath.Join(buildDir, filepath.Base(baseName)+".go")
+	// Release executables leave out the paths of the sources
+	if err := writeWorkspace(buildDir, goFile, code, !buildFlags.release); err != nil {
+		return err
+	}
 
-	err = cmd.Run()
-	if err != nil {
+	if err := goBuild(goFile, executableName, code, buildFlags); err != nil {
 		return fmt.Errorf("failed to build executable: %w", err)
 	}
 
-	fmt.Printf("✅ Successfully built executable: %s\n", executableName)
-	fmt.Printf("   You can run it with: ./%s\n", executableName)
+	fmt.Fprintf(messages(), "✅ Successfully built executable: %s\n", executableName)
+	if buildFlags.keepGo {
+		fmt.Fprintf(messages(), "   Generated Go code kept in: %s\n", buildDir)
+	}
+	if (buildFlags.goos == "" || buildFlags.goos == runtime.GOOS) && (buildFlags.goarch == "" || buildFlags.goarch == runtime.GOARCH) {
+		run := executableName
+		if !filepath.IsAbs(run) && !strings.Contains(run, string(filepath.Separator)) {
+			run = "./" + run
+		}
+		fmt.Fprintf(messages(), "   You can run it with: %s\n", run)
+	}
 	return nil
 }
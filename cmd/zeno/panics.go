@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/generator"
+)
+
+var (
+	// traceFuncPattern matches the function line of a goroutine stack frame,
+	// e.g. "main.divide(...)" or "main.main()".
+	traceFuncPattern = regexp.MustCompile(`^[\w./*()\[\]]+\(.*\)$`)
+	// traceFilePattern matches the location line that follows it,
+	// e.g. "\t/tmp/zeno_run_1/app.go:101 +0x1d".
+	traceFilePattern = regexp.MustCompile(`^\t(.*\.go):(\d+)(?: \+0x[0-9a-f]+)?$`)
+)
+
+// panicTraceWriter rewrites Go panic stack traces written by a compiled Zeno
+// program so that frames refer to Zeno files and functions. Frames outside
+// the generated files (the Go runtime) are dropped; all other output is passed
+// through unchanged.
+type panicTraceWriter struct {
+	dst         io.Writer
+	sourceMaps  map[string]*generator.SourceMap // by generated Go file
+	partial     []byte
+	pendingFunc string // function line waiting for its location line
+	hasPending  bool
+}
+
+func newPanicTraceWriter(dst io.Writer, sourceMaps map[string]*generator.SourceMap) *panicTraceWriter {
+	return &panicTraceWriter{dst: dst, sourceMaps: sourceMaps}
+}
+
+func (w *panicTraceWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		line := string(w.partial[:i])
+		w.partial = w.partial[i+1:]
+		if err := w.writeLine(line); err != nil {
+			return len(p), err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush writes any buffered output that did not end in a newline.
+func (w *panicTraceWriter) Flush() error {
+	if w.hasPending {
+		w.hasPending = false
+		if _, err := fmt.Fprintln(w.dst, w.pendingFunc); err != nil {
+			return err
+		}
+	}
+	if len(w.partial) > 0 {
+		_, err := w.dst.Write(w.partial)
+		w.partial = nil
+		return err
+	}
+	return nil
+}
+
+func (w *panicTraceWriter) writeLine(line string) error {
+	if w.hasPending {
+		funcLine := w.pendingFunc
+		w.hasPending = false
+		if match := traceFilePattern.FindStringSubmatch(line); match != nil {
+			return w.writeFrame(funcLine, match[1], match[2])
+		}
+		if _, err := fmt.Fprintln(w.dst, funcLine); err != nil {
+			return err
+		}
+	}
+	if traceFuncPattern.MatchString(line) {
+		w.pendingFunc = line
+		w.hasPending = true
+		return nil
+	}
+	_, err := fmt.Fprintln(w.dst, line)
+	return err
+}
+
+func (w *panicTraceWriter) writeFrame(funcLine, file, lineStr string) error {
+	sourceMap := lookupSourceMap(w.sourceMaps, file)
+	if sourceMap == nil {
+		// Go runtime or standard library frame: not meaningful to Zeno users.
+		return nil
+	}
+	goLine, _ := strconv.Atoi(lineStr)
+	loc, ok := sourceMap.Lookup(goLine)
+	if !ok {
+		_, err := fmt.Fprintf(w.dst, "%s\n\t(generated Go line %d)\n", funcLine, goLine)
+		return err
+	}
+	function := loc.Function
+	if function == "" {
+		function = strings.TrimPrefix(funcLine[:strings.Index(funcLine, "(")], "main.")
+	}
+	if loc.Line > 0 {
+		_, err := fmt.Fprintf(w.dst, "%s()\n\t%s:%d: %s\n", function, loc.File, loc.Line, loc.Statement)
+		return err
+	}
+	_, err := fmt.Fprintf(w.dst, "%s()\n\t%s: %s\n", function, loc.File, loc.Statement)
+	return err
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/linkalls/zeno-lang/repl"
+	"github.com/spf13/cobra"
+)
+
+var replCmd = &cobra.Command{
+	Use:   "repl",
+	Short: "Start an interactive Zeno session",
+	Long: `Starts an interactive prompt that evaluates Zeno statements and expressions
+as they are entered. Variables, functions and imports persist for the rest
+of the session, and the value of each expression is printed.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := repl.Start(os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "REPL failed: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var testCmd = &cobra.Command{
+	Use:   "test [file|dir]...",
+	Short: "Run the tests of Zeno files",
+	Long: `Runs the tests of the *_test.zeno files given, or found in the directories
+given, which are walked recursively, by default the current directory.
+
+Tests are the functions without parameters whose names start with test_. A
+test fails when an assertion, assertEq(got, want) or assertTrue(condition),
+fails or when it panics. Exits with status 1 if any test failed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Fprintf(progress(), "=== Zeno Test Command ===\n")
+		if len(args) == 0 {
+			args = []string{"."}
+		}
+		var files []string
+		for _, pathArg := range args {
+			found, err := zenoFiles(pathArg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error accessing path %s: %v\n", pathArg, err)
+				os.Exit(1)
+			}
+			for _, file := range found {
+				if isTestFile(file) {
+					files = append(files, file)
+				}
+			}
+		}
+		if len(files) == 0 {
+			fmt.Fprintf(messages(), "No test files found\n")
+			return
+		}
+
+		testProgram = true
+		failed := 0
+		for _, file := range files {
+			fmt.Fprintf(messages(), "=== %s\n", file)
+			if err := runFile(file, nil); err != nil {
+				failed++
+				// The runner has reported the tests that failed
+				var exitErr *exec.ExitError
+				if !errors.As(err, &exitErr) {
+					fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+				}
+			}
+		}
+		if failed > 0 {
+			fmt.Fprintf(messages(), "FAIL: %d of %d test file(s) failed\n", failed, len(files))
+			os.Exit(1)
+		}
+		fmt.Fprintf(messages(), "PASS: %d test file(s)\n", len(files))
+	},
+}
+
+// isTestFile reports whether a Zeno file holds tests
+func isTestFile(file string) bool {
+	return strings.HasSuffix(file, "_test.zeno") || strings.HasSuffix(file, "_test.zn")
+}
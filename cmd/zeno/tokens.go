@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/linkalls/zeno-lang/lexer"
+	"github.com/linkalls/zeno-lang/token"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// tokensJSON prints the tokens as JSON (tokens --json)
+	tokensJSON bool
+	// tokensComments includes the comments (tokens --comments)
+	tokensComments bool
+)
+
+var tokensCmd = &cobra.Command{
+	Use:   "tokens <file>",
+	Short: "Print the tokens of a Zeno file",
+	Long: `Runs the lexer on a Zeno file and prints its tokens, one per line with the
+line and column of their first character, their type and their text, for
+diagnosing lexer issues such as ILLEGAL tokens for unsupported characters.
+--comments includes the comments, which the parser does not see, and --json
+prints a JSON array of {type, literal, pos} objects, with the positions
+written like the ones of zeno ast.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := printTokens(os.Stdout, args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", args[0], err)
+			os.Exit(1)
+		}
+	},
+}
+
+// printTokens prints the tokens of filename up to EOF, included
+func printTokens(w io.Writer, filename string) error {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", filename, err)
+	}
+	l := lexer.New(string(content))
+	if tokensComments {
+		l = lexer.NewWithComments(string(content))
+	}
+	var tokens []map[string]interface{}
+	for {
+		tok := l.NextToken()
+		if tokensJSON {
+			tokens = append(tokens, map[string]interface{}{
+				"type":    tok.Type,
+				"literal": tok.Literal,
+				"pos":     map[string]int{"line": tok.Line, "column": tok.Column},
+			})
+		} else {
+			fmt.Fprintf(w, "%-8s %-10s %q\n", fmt.Sprintf("%d:%d", tok.Line, tok.Column), tok.Type, tok.Literal)
+		}
+		if tok.Type == token.EOF {
+			break
+		}
+	}
+	if !tokensJSON {
+		return nil
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(tokens)
+}
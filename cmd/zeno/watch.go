@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// The watched files are polled rather than watched with file system
+// notifications, which would need a dependency such as fsnotify for a
+// handful of files whose modification times are cheap to read.
+const (
+	// watchInterval is how often the watched files are polled
+	watchInterval = 300 * time.Millisecond
+	// watchDebounce is how long the files must stay unchanged after a
+	// change before rebuilding, so that saving several files rebuilds once
+	watchDebounce = 200 * time.Millisecond
+)
+
+// watchedFiles returns the absolute paths of a Zeno file and of the user
+// modules it imports, directly or not
+func watchedFiles(filename string) []string {
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		path = filename
+	}
+	files := []string{path}
+	seen := map[string]bool{path: true}
+	for i := 0; i < len(files); i++ {
+		for _, imported := range localImports(files[i]) {
+			if !seen[imported] {
+				seen[imported] = true
+				files = append(files, imported)
+			}
+		}
+	}
+	return files
+}
+
+// modTimes returns the modification time of each file, zero for the ones
+// that cannot be read
+func modTimes(files []string) map[string]time.Time {
+	times := make(map[string]time.Time, len(files))
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			times[file] = info.ModTime()
+		} else {
+			times[file] = time.Time{}
+		}
+	}
+	return times
+}
+
+// changed reports whether a file of before was modified, created or removed
+func changed(before map[string]time.Time) bool {
+	for file, modTime := range before {
+		info, err := os.Stat(file)
+		if err != nil {
+			if !modTime.IsZero() {
+				return true
+			}
+			continue
+		}
+		if !info.ModTime().Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// waitForChange blocks until one of files changes and then stays unchanged
+// for watchDebounce
+func waitForChange(files []string) {
+	before := modTimes(files)
+	for !changed(before) {
+		time.Sleep(watchInterval)
+	}
+	for {
+		before = modTimes(files)
+		time.Sleep(watchDebounce)
+		if !changed(before) {
+			return
+		}
+	}
+}
+
+// watch calls action for a Zeno file, then again each time the file or a
+// module it imports changes, until the process is interrupted. The channel
+// passed to action is closed when a change is detected, and action must
+// then return.
+func watch(filename string, action func(stop <-chan struct{})) {
+	for {
+		files := watchedFiles(filename)
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			action(stop)
+		}()
+		waitForChange(files)
+		close(stop)
+		<-done
+		if !quiet {
+			fmt.Fprintf(os.Stderr, "\n--- %s changed, rebuilding (%s) ---\n", filepath.Base(filename), time.Now().Format("15:04:05"))
+		}
+	}
+}
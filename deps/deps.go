@@ -0,0 +1,419 @@
+// Package deps fetches the remote modules a program imports, such as
+// "github.com/user/pkg/module", pins their versions in a zeno.lock file and
+// locates their sources in the module cache.
+package deps
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/semver"
+)
+
+// LockFile is the name of the file pinning the versions of the dependencies
+// of the modules in its directory and below
+const LockFile = "zeno.lock"
+
+// CacheDir is the directory holding the fetched dependencies, one
+// directory per version: "github.com/user/pkg@v1.2.0"
+var CacheDir = defaultCacheDir()
+
+// NotFetchedError is returned by Resolve for a module whose repository is
+// not pinned in the lock file, or not in the cache
+type NotFetchedError struct {
+	Module string
+	Repo   string
+	Pinned bool // the repository is pinned but was not fetched
+}
+
+func (e *NotFetchedError) Error() string {
+	return fmt.Sprintf("module %s has not been fetched; run %s", e.Module, e.Command())
+}
+
+// Command returns the zeno get command fetching the module
+func (e *NotFetchedError) Command() string {
+	if e.Pinned {
+		return "zeno get"
+	}
+	return "zeno get " + e.Repo
+}
+
+// knownHosts are the hosts whose repositories are at host/user/name
+var knownHosts = map[string]bool{"github.com": true, "gitlab.com": true, "bitbucket.org": true}
+
+func defaultCacheDir() string {
+	if dir := os.Getenv("ZENO_MODCACHE"); dir != "" {
+		return dir
+	}
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "zeno", "mod")
+	}
+	return filepath.Join(os.TempDir(), "zeno", "mod")
+}
+
+// IsRemote reports whether module is imported from a repository, which is
+// the case when its first path element is a host name such as github.com
+func IsRemote(module string) bool {
+	host, _, found := strings.Cut(module, "/")
+	return found && strings.Contains(host, ".") && host != "." && host != ".."
+}
+
+// RepoOf returns the repository a remote module is presumably in: the
+// first three path elements for well-known hosts, otherwise the directory
+// of the module
+func RepoOf(module string) string {
+	elements := strings.Split(module, "/")
+	if knownHosts[elements[0]] && len(elements) > 3 {
+		return strings.Join(elements[:3], "/")
+	}
+	if len(elements) > 2 {
+		return strings.Join(elements[:len(elements)-1], "/")
+	}
+	return module
+}
+
+// Dependency is a repository pinned to a version
+type Dependency struct {
+	Repo    string // "github.com/user/pkg"
+	Version string // the tag checked out, or v0.0.0-<commit> without tags
+	Commit  string // the commit of the version, checked when fetching again
+}
+
+// Dir returns the directory of the dependency in the module cache
+func (d Dependency) Dir() string {
+	return filepath.Join(CacheDir, filepath.FromSlash(d.Repo)+"@"+d.Version)
+}
+
+// check returns an error when the repository or the version of d would
+// make Dir point outside of the module cache
+func (d Dependency) check() error {
+	if !localPath(d.Repo) {
+		return fmt.Errorf("invalid repository %q", d.Repo)
+	}
+	if !localPath(d.Version) || strings.Contains(d.Version, "/") {
+		return fmt.Errorf("invalid version %q of %s", d.Version, d.Repo)
+	}
+	// The commit is checked out, so it must not read as an option or a
+	// branch
+	if !objectID(d.Commit) {
+		return fmt.Errorf("invalid commit %q of %s %s, expected a hexadecimal object id", d.Commit, d.Repo, d.Version)
+	}
+	return nil
+}
+
+// objectID reports whether s is a full git object id: 40 hexadecimal digits
+// for SHA-1, or 64 for SHA-256
+func objectID(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// localPath reports whether path is a relative slash-separated path without
+// empty, . or .. elements, which stays in the directory it is joined to
+func localPath(path string) bool {
+	for _, element := range strings.Split(path, "/") {
+		if element == "" || element == "." || element == ".." || strings.Contains(element, `\`) {
+			return false
+		}
+	}
+	return filepath.IsLocal(filepath.FromSlash(path))
+}
+
+// Lock is the content of a lock file: one "repo version commit" line per
+// dependency, sorted by repository
+type Lock struct {
+	Path string
+	Deps []Dependency
+}
+
+// ReadLock reads the lock file of dir, found in dir or the nearest parent
+// directory holding one. Without a lock file, the lock is empty and is
+// written to dir.
+func ReadLock(dir string) (*Lock, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for search := dir; ; {
+		path := filepath.Join(search, LockFile)
+		if _, err := os.Stat(path); err == nil {
+			return parseLock(path)
+		}
+		parent := filepath.Dir(search)
+		if parent == search {
+			return &Lock{Path: filepath.Join(dir, LockFile)}, nil
+		}
+		search = parent
+	}
+}
+
+func parseLock(path string) (*Lock, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	lock := &Lock{Path: path}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%s:%d: expected \"repository version commit\", got %q", path, line, text)
+		}
+		dep := Dependency{Repo: fields[0], Version: fields[1], Commit: fields[2]}
+		if err := dep.check(); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		lock.Deps = append(lock.Deps, dep)
+	}
+	return lock, scanner.Err()
+}
+
+// Write writes the lock file
+func (l *Lock) Write() error {
+	sort.Slice(l.Deps, func(i, j int) bool { return l.Deps[i].Repo < l.Deps[j].Repo })
+	var builder strings.Builder
+	builder.WriteString("# Generated by zeno get. Do not edit.\n")
+	for _, dep := range l.Deps {
+		fmt.Fprintf(&builder, "%s %s %s\n", dep.Repo, dep.Version, dep.Commit)
+	}
+	return os.WriteFile(l.Path, []byte(builder.String()), 0644)
+}
+
+// Find returns the dependency providing module, the one with the longest
+// repository path that module is in, and the path of module in it
+func (l *Lock) Find(module string) (Dependency, string, bool) {
+	var found Dependency
+	var sub string
+	for _, dep := range l.Deps {
+		rest, ok := strings.CutPrefix(module, dep.Repo+"/")
+		if ok && len(dep.Repo) > len(found.Repo) {
+			found, sub = dep, rest
+		}
+	}
+	return found, sub, found.Repo != ""
+}
+
+// set adds dep to the lock, replacing the version of its repository
+func (l *Lock) set(dep Dependency) {
+	for i := range l.Deps {
+		if l.Deps[i].Repo == dep.Repo {
+			l.Deps[i] = dep
+			return
+		}
+	}
+	l.Deps = append(l.Deps, dep)
+}
+
+// Resolve returns the source file of the remote module imported by a file
+// in dir, using the lock file of dir. It returns a *NotFetchedError when
+// the module has not been fetched with zeno get.
+func Resolve(dir, module string) (string, error) {
+	lock, err := ReadLock(dir)
+	if err != nil {
+		return "", err
+	}
+	dep, sub, ok := lock.Find(module)
+	if !ok {
+		return "", &NotFetchedError{Module: module, Repo: RepoOf(module)}
+	}
+	if !localPath(sub) {
+		return "", fmt.Errorf("invalid module %q: its path in %s leaves the repository", module, dep.Repo)
+	}
+	if _, err := os.Stat(dep.Dir()); err != nil {
+		return "", &NotFetchedError{Module: module, Repo: dep.Repo, Pinned: true}
+	}
+	file := filepath.Join(dep.Dir(), filepath.FromSlash(sub))
+	if !strings.HasSuffix(file, ".zeno") {
+		file += ".zeno"
+	}
+	return file, nil
+}
+
+// Get fetches the repository of spec, "github.com/user/pkg" or
+// "github.com/user/pkg@^1.2.0", at the highest version tag satisfying the
+// constraint and pins it in lock. A repository without version tags is
+// pinned to the commit of its default branch. The dependencies pinned by
+// the lock file of the repository are fetched too.
+func Get(lock *Lock, spec string) (Dependency, error) {
+	repo, constraint, hasConstraint := strings.Cut(spec, "@")
+	if !IsRemote(repo+"/") || !localPath(repo) {
+		return Dependency{}, fmt.Errorf("invalid repository %q, expected a path such as github.com/user/pkg", repo)
+	}
+	if !hasConstraint {
+		constraint = "*"
+	}
+	dep, err := latest(repo, constraint, hasConstraint)
+	if err != nil {
+		return Dependency{}, err
+	}
+	if err := fetch(&dep); err != nil {
+		return Dependency{}, err
+	}
+	lock.set(dep)
+	return dep, fetchLocked(dep.Dir())
+}
+
+// Sync fetches the dependencies of lock that are not in the cache, at their
+// pinned commit
+func Sync(lock *Lock) error {
+	for i := range lock.Deps {
+		if err := fetch(&lock.Deps[i]); err != nil {
+			return err
+		}
+		if err := fetchLocked(lock.Deps[i].Dir()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchLocked fetches the dependencies pinned by the lock file of a fetched
+// repository, which its modules import
+func fetchLocked(dir string) error {
+	path := filepath.Join(dir, LockFile)
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	lock, err := parseLock(path)
+	if err != nil {
+		return err
+	}
+	return Sync(lock)
+}
+
+// latest returns the highest version of repo satisfying constraint, or the
+// commit of its default branch if it has no version tags and no constraint
+// was given
+func latest(repo, constraint string, hasConstraint bool) (Dependency, error) {
+	c, err := semver.ParseConstraint(constraint)
+	if err != nil {
+		return Dependency{}, err
+	}
+	output, err := git("", "ls-remote", "--tags", url(repo))
+	if err != nil {
+		return Dependency{}, err
+	}
+	var best Dependency
+	var bestVersion semver.Version
+	tags := 0
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		_, ref, ok := strings.Cut(line, "\t")
+		tag, isTag := strings.CutPrefix(ref, "refs/tags/")
+		if !ok || !isTag {
+			continue
+		}
+		// Annotated tags are listed again with the commit they point to
+		tag = strings.TrimSuffix(tag, "^{}")
+		v, err := semver.Parse(tag)
+		if err != nil {
+			continue
+		}
+		tags++
+		if !c.Check(v) {
+			continue
+		}
+		if best.Version == "" || semver.Compare(v, bestVersion) > 0 {
+			best, bestVersion = Dependency{Repo: repo, Version: tag}, v
+		}
+	}
+	if best.Version != "" {
+		return best, nil
+	}
+	if tags > 0 || hasConstraint {
+		return Dependency{}, fmt.Errorf("no version of %s satisfies %s", repo, constraint)
+	}
+	output, err = git("", "ls-remote", url(repo), "HEAD")
+	if err != nil {
+		return Dependency{}, err
+	}
+	commit, _, _ := strings.Cut(output, "\t")
+	if len(commit) < 12 {
+		return Dependency{}, fmt.Errorf("%s has no commits", repo)
+	}
+	return Dependency{Repo: repo, Version: "v0.0.0-" + commit[:12], Commit: commit}, nil
+}
+
+// fetch copies the version of dep into the module cache, unless it is
+// there already, and sets the commit of dep. A commit that differs from the
+// pinned one is an error, as the tag was moved.
+func fetch(dep *Dependency) error {
+	dir := dep.Dir()
+	if _, err := os.Stat(dir); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), ".fetch-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	if _, err := git("", "clone", "--quiet", url(dep.Repo), tmp); err != nil {
+		return err
+	}
+	ref := dep.Commit
+	if ref == "" {
+		ref = "refs/tags/" + dep.Version
+	}
+	if _, err := git(tmp, "checkout", "--quiet", "--detach", ref); err != nil {
+		return err
+	}
+	commit, err := git(tmp, "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	commit = strings.TrimSpace(commit)
+	if dep.Commit != "" && dep.Commit != commit {
+		return fmt.Errorf("%s %s is commit %s, but %s is pinned", dep.Repo, dep.Version, commit, dep.Commit)
+	}
+	dep.Commit = commit
+	if err := os.RemoveAll(filepath.Join(tmp, ".git")); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, 0755); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dir)
+}
+
+// url returns the address of a repository. Git's url.<base>.insteadOf
+// settings can point it elsewhere, such as to a mirror.
+func url(repo string) string {
+	return "https://" + repo
+}
+
+// git runs a git command in dir and returns its output
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	// Never wait for credentials on the terminal
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(output), nil
+}
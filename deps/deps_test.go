@@ -0,0 +1,229 @@
+package deps
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIsRemote(t *testing.T) {
+	tests := []struct {
+		module   string
+		expected bool
+	}{
+		{"github.com/user/pkg/text", true},
+		{"example.com/text", true},
+		{"./lib/text", false},
+		{"../text", false},
+		{"std/fmt", false},
+		{"text", false},
+	}
+	for _, tt := range tests {
+		if got := IsRemote(tt.module); got != tt.expected {
+			t.Errorf("IsRemote(%q) = %v, want %v", tt.module, got, tt.expected)
+		}
+	}
+}
+
+func TestRepoOf(t *testing.T) {
+	tests := map[string]string{
+		"github.com/user/pkg/text":     "github.com/user/pkg",
+		"github.com/user/pkg/lib/text": "github.com/user/pkg",
+		"example.com/tools/text":       "example.com/tools",
+		"example.com/text":             "example.com/text",
+	}
+	for module, expected := range tests {
+		if got := RepoOf(module); got != expected {
+			t.Errorf("RepoOf(%q) = %q, want %q", module, got, expected)
+		}
+	}
+}
+
+// Commits of the lock files of the tests
+var (
+	commitA = strings.Repeat("a", 40)
+	commitB = strings.Repeat("b", 40)
+	commitC = strings.Repeat("c", 40)
+)
+
+func TestLock(t *testing.T) {
+	dir := t.TempDir()
+	lock, err := ReadLock(dir)
+	if err != nil || len(lock.Deps) != 0 || lock.Path != filepath.Join(dir, LockFile) {
+		t.Fatalf("expected an empty lock in %s, got %+v, %v", dir, lock, err)
+	}
+	lock.set(Dependency{Repo: "github.com/user/pkg", Version: "v1.0.0", Commit: commitA})
+	lock.set(Dependency{Repo: "github.com/user/pkg/sub", Version: "v0.1.0", Commit: commitB})
+	lock.set(Dependency{Repo: "github.com/user/pkg", Version: "v1.1.0", Commit: commitC})
+	if err := lock.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The lock file of a parent directory is found
+	sub := filepath.Join(dir, "src", "lib")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadLock(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read.Deps, lock.Deps) || read.Path != lock.Path {
+		t.Errorf("expected %+v, got %+v", lock, read)
+	}
+	dep, module, ok := read.Find("github.com/user/pkg/sub/text")
+	if !ok || dep.Commit != commitB || module != "text" {
+		t.Errorf("expected the longest repository, got %+v %q", dep, module)
+	}
+	if _, _, ok := read.Find("github.com/user/pkgs/text"); ok {
+		t.Errorf("expected no dependency for github.com/user/pkgs/text")
+	}
+
+	if err := os.WriteFile(lock.Path, []byte("github.com/user/pkg v1.0.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadLock(dir); err == nil {
+		t.Errorf("expected an error for a malformed lock file")
+	}
+}
+
+func TestLockPaths(t *testing.T) {
+	defer func(dir string) { CacheDir = dir }(CacheDir)
+	CacheDir = t.TempDir()
+	dir := t.TempDir()
+	path := filepath.Join(dir, LockFile)
+
+	// Versions and repositories leaving the module cache are rejected
+	for _, line := range []string{
+		"github.com/user/pkg ../../../etc " + commitA,
+		"github.com/user/pkg v1.0.0/../.. " + commitA,
+		"github.com/user/../../pkg v1.0.0 " + commitA,
+		"/tmp/pkg v1.0.0 " + commitA,
+	} {
+		if err := os.WriteFile(path, []byte(line+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadLock(dir); err == nil {
+			t.Errorf("expected an error for %q", line)
+		}
+	}
+	if _, err := Get(&Lock{}, "github.com/user/../../pkg"); err == nil {
+		t.Errorf("expected an error for a repository with .. elements")
+	}
+
+	// So are commits that are not object ids, which git checkout would
+	// read as options or branches
+	for _, commit := range []string{"aaa", "--upload-pack=touch", "HEAD", strings.ToUpper(commitA), commitA + "0"} {
+		if err := os.WriteFile(path, []byte("github.com/user/pkg v1.0.0 "+commit+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadLock(dir); err == nil {
+			t.Errorf("expected an error for commit %q", commit)
+		}
+	}
+
+	// So are modules leaving their repository
+	if err := os.WriteFile(path, []byte("github.com/user/pkg v1.0.0 "+commitA+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(CacheDir, "github.com", "user", "pkg@v1.0.0"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Resolve(dir, "github.com/user/pkg/text"); err != nil {
+		t.Errorf("expected github.com/user/pkg/text to resolve, got %v", err)
+	}
+	for _, module := range []string{"github.com/user/pkg/../../../etc/passwd", "github.com/user/pkg//etc/passwd"} {
+		if _, err := Resolve(dir, module); err == nil {
+			t.Errorf("expected an error for %s", module)
+		}
+	}
+}
+
+// gitRepo creates a repository served at https://example.com/user/pkg, with
+// a commit and a version tag for each of versions
+func gitRepo(t *testing.T, versions ...string) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repos := t.TempDir()
+	repo := filepath.Join(repos, "example.com", "user", "pkg")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "url.file://"+filepath.ToSlash(repos)+"/.insteadOf")
+	t.Setenv("GIT_CONFIG_VALUE_0", "https://")
+	run := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repo
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	run("init", "--quiet")
+	for _, version := range versions {
+		source := "pub fn version(): string {\n    return \"" + version + "\"\n}\n"
+		if err := os.WriteFile(filepath.Join(repo, "text.zeno"), []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+		run("add", "-A")
+		run("commit", "--quiet", "-m", version)
+		run("tag", version)
+	}
+}
+
+func TestGetAndResolve(t *testing.T) {
+	gitRepo(t, "v1.0.0", "v1.2.0", "v2.0.0-rc.1")
+	defer func(dir string) { CacheDir = dir }(CacheDir)
+	CacheDir = t.TempDir()
+	dir := t.TempDir()
+
+	_, err := Resolve(dir, "example.com/user/pkg/text")
+	var notFetched *NotFetchedError
+	if !errors.As(err, &notFetched) || notFetched.Command() != "zeno get example.com/user/pkg" {
+		t.Fatalf("expected a module not fetched, got %v", err)
+	}
+
+	lock, _ := ReadLock(dir)
+	dep, err := Get(lock, "example.com/user/pkg@^1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dep.Version != "v1.2.0" || len(dep.Commit) != 40 {
+		t.Errorf("expected v1.2.0 with its commit, got %+v", dep)
+	}
+	if err := lock.Write(); err != nil {
+		t.Fatal(err)
+	}
+	file, err := Resolve(dir, "example.com/user/pkg/text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil || string(content) != "pub fn version(): string {\n    return \"v1.2.0\"\n}\n" {
+		t.Errorf("expected the source of v1.2.0 in %s, got %q, %v", file, content, err)
+	}
+
+	// The pinned versions are fetched again at their commit
+	if err := os.RemoveAll(CacheDir); err != nil {
+		t.Fatal(err)
+	}
+	_, err = Resolve(dir, "example.com/user/pkg/text")
+	if !errors.As(err, &notFetched) || notFetched.Command() != "zeno get" {
+		t.Fatalf("expected a pinned module not fetched, got %v", err)
+	}
+	if err := Sync(lock); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Resolve(dir, "example.com/user/pkg/text"); err != nil {
+		t.Errorf("expected the module after Sync, got %v", err)
+	}
+
+	if _, err := Get(lock, "example.com/user/pkg@^3.0.0"); err == nil {
+		t.Errorf("expected no version satisfying ^3.0.0")
+	}
+}
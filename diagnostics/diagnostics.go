@@ -0,0 +1,183 @@
+// Package diagnostics defines the errors and warnings reported by every
+// phase of the compiler, so that the CLI renders them the same way and tools
+// can read them without parsing text.
+package diagnostics
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+)
+
+// Severity says whether a diagnostic stops the compilation
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+	Info
+)
+
+// String returns the name of the severity, which does not depend on the
+// language of the messages
+func (s Severity) String() string {
+	switch s {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// label returns the name of the severity in the language of the messages
+func (s Severity) label() string {
+	switch s {
+	case Error:
+		return i18n.T(i18n.LabelError)
+	case Warning:
+		return i18n.T(i18n.LabelWarning)
+	default:
+		return i18n.T(i18n.LabelInfo)
+	}
+}
+
+// Related is another location that explains a diagnostic, e.g. the first
+// declaration of a name declared twice
+type Related struct {
+	File    string
+	Pos     ast.Position
+	Message string
+}
+
+// Diagnostic is an error, warning or note about a Zeno source file
+type Diagnostic struct {
+	Severity   Severity
+	Code       string       // stable diagnostic code, e.g. Z0104
+	Source     string       // phase or lint rule that reported it, e.g. parser
+	File       string       // Zeno source file, empty if unknown
+	Pos        ast.Position // location in the file, zero if unknown
+	Length     int          // characters of the span at Pos, 0 if unknown
+	Message    string
+	Context    string   // source text the diagnostic is about
+	Notes      []string // further explanations, e.g. what was expected
+	Suggestion string   // optional fix shown as help
+	Related    []Related
+}
+
+// String renders the diagnostic for a terminal, on several lines
+func (d Diagnostic) String() string {
+	return d.Render("")
+}
+
+// Render renders the diagnostic like String, with the line of source it is
+// located at and its span underlined instead of the context
+func (d Diagnostic) Render(source string) string {
+	var builder strings.Builder
+	if d.File != "" {
+		builder.WriteString(d.File + ": ")
+	}
+	builder.WriteString(d.Severity.label())
+	if d.Code != "" {
+		builder.WriteString("[" + d.Code + "]")
+	}
+	builder.WriteString(": " + d.Message + "\n")
+	if d.Pos.IsValid() {
+		builder.WriteString(fmt.Sprintf("  --> %s\n", i18n.T(i18n.LabelLocation, d.Pos.Line, d.Pos.Column)))
+	}
+	if code := snippet(source, d.Pos, d.Length); code != "" {
+		builder.WriteString(code)
+	} else if d.Context != "" {
+		builder.WriteString(fmt.Sprintf("   | %s\n", d.Context))
+	}
+	for _, note := range d.Notes {
+		builder.WriteString(fmt.Sprintf("   = %s\n", note))
+	}
+	for _, related := range d.Related {
+		builder.WriteString(fmt.Sprintf("%s: %s\n", i18n.T(i18n.LabelNote), related.Message))
+		location := i18n.T(i18n.LabelLocation, related.Pos.Line, related.Pos.Column)
+		if related.File != "" {
+			location = related.File + ", " + location
+		}
+		if related.Pos.IsValid() {
+			builder.WriteString(fmt.Sprintf("  --> %s\n", location))
+		}
+	}
+	if d.Suggestion != "" {
+		builder.WriteString(fmt.Sprintf("%s: %s\n", i18n.T(i18n.LabelHelp), d.Suggestion))
+	}
+	return builder.String()
+}
+
+// Reporter receives the diagnostics of the phases
+type Reporter interface {
+	Report(d Diagnostic)
+}
+
+// List is a Reporter that keeps the diagnostics in the order reported
+type List []Diagnostic
+
+// Report appends d to the list
+func (l *List) Report(d Diagnostic) {
+	*l = append(*l, d)
+}
+
+// Count returns the number of diagnostics of the given severity
+func (l List) Count(severity Severity) int {
+	count := 0
+	for _, d := range l {
+		if d.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// HasErrors reports whether the list holds an error
+func (l List) HasErrors() bool {
+	return l.Count(Error) > 0
+}
+
+// Printer is a Reporter that writes each diagnostic to W as it is reported,
+// followed by an empty line
+type Printer struct {
+	W io.Writer
+	// Sources holds the contents of files by name, to show the source the
+	// diagnostics are about. Files missing from it are read from disk.
+	Sources map[string]string
+}
+
+// Report writes d to the printer
+func (p Printer) Report(d Diagnostic) {
+	fmt.Fprintln(p.W, d.Render(p.source(d.File)))
+}
+
+// source returns the contents of file, or "" if it cannot be read
+func (p Printer) source(file string) string {
+	if file == "" {
+		return ""
+	}
+	if source, ok := p.Sources[file]; ok {
+		return source
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+	if p.Sources != nil {
+		p.Sources[file] = string(content)
+	}
+	return string(content)
+}
+
+// ReportAll passes each diagnostic to r in order
+func ReportAll(r Reporter, diagnostics []Diagnostic) {
+	for _, d := range diagnostics {
+		r.Report(d)
+	}
+}
@@ -0,0 +1,156 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/linkalls/zeno-lang/ast"
+)
+
+func TestDiagnosticString(t *testing.T) {
+	d := Diagnostic{
+		Severity:   Error,
+		Code:       "Z0002",
+		File:       "main.zeno",
+		Pos:        ast.Position{Line: 3, Column: 1},
+		Message:    "no prefix parse function for } found",
+		Context:    "at '}'",
+		Notes:      []string{"expected expression, but got }"},
+		Suggestion: "add a value after '='",
+		Related: []Related{
+			{File: "main.zeno", Pos: ast.Position{Line: 2, Column: 5}, Message: "the declaration starts here"},
+		},
+	}
+	expected := `main.zeno: error[Z0002]: no prefix parse function for } found
+  --> line 3, column 1
+   | at '}'
+   = expected expression, but got }
+note: the declaration starts here
+  --> main.zeno, line 2, column 5
+help: add a value after '='
+`
+	if got := d.String(); got != expected {
+		t.Errorf("unexpected rendering:\n%s\nexpected:\n%s", got, expected)
+	}
+
+	minimal := Diagnostic{Severity: Warning, Message: "empty block"}
+	if got := minimal.String(); got != "warning: empty block\n" {
+		t.Errorf("unexpected rendering of a diagnostic without location: %q", got)
+	}
+}
+
+func TestList(t *testing.T) {
+	var list List
+	var reporter Reporter = &list
+	ReportAll(reporter, []Diagnostic{
+		{Severity: Warning, Message: "a"},
+		{Severity: Info, Message: "b"},
+		{Severity: Warning, Message: "c"},
+	})
+	if len(list) != 3 || list[2].Message != "c" {
+		t.Fatalf("expected the 3 diagnostics in order, got %v", list)
+	}
+	if list.Count(Warning) != 2 || list.HasErrors() {
+		t.Errorf("unexpected counts: %d warnings, errors %v", list.Count(Warning), list.HasErrors())
+	}
+	list.Report(Diagnostic{Severity: Error, Message: "d"})
+	if !list.HasErrors() {
+		t.Errorf("expected the list to hold an error")
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var out strings.Builder
+	if err := Write(&out, "json", nil); err != nil || out.String() != "[]\n" {
+		t.Errorf("expected an empty array, got %q, %v", out.String(), err)
+	}
+
+	out.Reset()
+	err := Write(&out, "json", []Diagnostic{{
+		Severity: Warning,
+		Code:     "Z0304",
+		Source:   "unused-variable",
+		File:     "main.zeno",
+		Pos:      ast.Position{Line: 8, Column: 5},
+		Message:  "Variable 'x' is declared but not used.",
+	}})
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal([]byte(out.String()), &decoded); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out.String(), err)
+	}
+	if len(decoded) != 1 || decoded[0]["file"] != "main.zeno" || decoded[0]["line"] != 8.0 ||
+		decoded[0]["column"] != 5.0 || decoded[0]["severity"] != "warning" || decoded[0]["code"] != "Z0304" {
+		t.Errorf("unexpected JSON: %s", out.String())
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var out strings.Builder
+	err := Write(&out, "sarif", []Diagnostic{
+		{Severity: Error, Code: "Z0117", File: "src/main.zeno", Pos: ast.Position{Line: 2, Column: 5}, Message: "type mismatch"},
+		{Severity: Error, Code: "Z0117", File: "src/main.zeno", Pos: ast.Position{Line: 4, Column: 1}, Message: "type mismatch"},
+	})
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var log sarifDocument
+	if err := json.Unmarshal([]byte(out.String()), &log); err != nil {
+		t.Fatalf("invalid SARIF %q: %v", out.String(), err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected SARIF log: %s", out.String())
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 1 || run.Tool.Driver.Rules[0].ID != "Z0117" {
+		t.Errorf("expected Z0117 to be described once, got %v", run.Tool.Driver.Rules)
+	}
+	if len(run.Results) != 2 || run.Results[1].Level != "error" {
+		t.Fatalf("unexpected results: %v", run.Results)
+	}
+	location := run.Results[1].Locations[0].PhysicalLocation
+	if location.ArtifactLocation.URI != "src/main.zeno" || location.Region.StartLine != 4 {
+		t.Errorf("unexpected location: %+v", location)
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	if err := Write(&strings.Builder{}, "xml", nil); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+func TestDiagnosticRender(t *testing.T) {
+	source := "fn main() {\n\tlet x: int = \"s\"\n}\n"
+	d := Diagnostic{
+		Severity: Error,
+		Code:     "Z0117",
+		Pos:      ast.Position{Line: 2, Column: 15},
+		Length:   3,
+		Message:  "Variable 'x' is declared as int but initialized with string",
+		Context:  "let x",
+	}
+	expected := "error[Z0117]: Variable 'x' is declared as int but initialized with string\n" +
+		"  --> line 2, column 15\n" +
+		"   |\n" +
+		" 2 | \tlet x: int = \"s\"\n" +
+		"   | \t             ^~~\n"
+	if got := d.Render(source); got != expected {
+		t.Errorf("unexpected rendering:\n%q\nexpected:\n%q", got, expected)
+	}
+
+	// Without a length, the word at the position is underlined
+	d.Pos, d.Length = ast.Position{Line: 2, Column: 2}, 0
+	if got := d.Render(source); !strings.Contains(got, "   | \t^~~\n") {
+		t.Errorf("expected 'let' to be underlined:\n%s", got)
+	}
+
+	// Without the source line, the context is shown
+	d.Pos = ast.Position{Line: 9, Column: 1}
+	if got := d.Render(source); !strings.Contains(got, "   | let x\n") {
+		t.Errorf("expected the context:\n%s", got)
+	}
+}
@@ -0,0 +1,217 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/linkalls/zeno-lang/i18n"
+)
+
+// Formats are the output formats accepted by Write
+var Formats = []string{"text", "json", "sarif"}
+
+// IsFormat reports whether format is one of Formats
+func IsFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// Write writes the diagnostics to w in format: text renders them as String
+// does, json as an array of objects and sarif as a SARIF 2.1.0 log
+func Write(w io.Writer, format string, diagnostics []Diagnostic) error {
+	switch format {
+	case "text":
+		for _, d := range diagnostics {
+			if _, err := fmt.Fprintln(w, d.String()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "json":
+		return writeJSON(w, jsonDiagnostics(diagnostics))
+	case "sarif":
+		return writeJSON(w, sarifLog(diagnostics))
+	}
+	return fmt.Errorf("unknown diagnostics format %q", format)
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+type jsonRelated struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+type jsonDiagnostic struct {
+	File       string        `json:"file,omitempty"`
+	Line       int           `json:"line,omitempty"`
+	Column     int           `json:"column,omitempty"`
+	Severity   string        `json:"severity"`
+	Code       string        `json:"code,omitempty"`
+	Source     string        `json:"source,omitempty"`
+	Message    string        `json:"message"`
+	Notes      []string      `json:"notes,omitempty"`
+	Suggestion string        `json:"suggestion,omitempty"`
+	Related    []jsonRelated `json:"related,omitempty"`
+}
+
+// jsonDiagnostics converts the diagnostics to the objects written by the
+// json format. The list is never null, so that no diagnostics is [].
+func jsonDiagnostics(diagnostics []Diagnostic) []jsonDiagnostic {
+	result := []jsonDiagnostic{}
+	for _, d := range diagnostics {
+		j := jsonDiagnostic{
+			File:       d.File,
+			Line:       d.Pos.Line,
+			Column:     d.Pos.Column,
+			Severity:   d.Severity.String(),
+			Code:       d.Code,
+			Source:     d.Source,
+			Message:    d.Message,
+			Notes:      d.Notes,
+			Suggestion: d.Suggestion,
+		}
+		for _, related := range d.Related {
+			j.Related = append(j.Related, jsonRelated{
+				File:    related.File,
+				Line:    related.Pos.Line,
+				Column:  related.Pos.Column,
+				Message: related.Message,
+			})
+		}
+		result = append(result, j)
+	}
+	return result
+}
+
+// The SARIF types below cover the part of the format that code scanning
+// tools read, see https://docs.oasis-open.org/sarif/sarif/v2.1.0/
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID           string          `json:"ruleId,omitempty"`
+	Level            string          `json:"level"`
+	Message          sarifMessage    `json:"message"`
+	Locations        []sarifLocation `json:"locations,omitempty"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifDocument struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+// sarifLocationOf returns the location of a diagnostic in a file, or nil
+// if the file is unknown
+func sarifLocationOf(file string, line, column int, message string) *sarifLocation {
+	if file == "" {
+		return nil
+	}
+	location := &sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(file)},
+	}}
+	if line > 0 {
+		location.PhysicalLocation.Region = &sarifRegion{StartLine: line, StartColumn: column}
+	}
+	if message != "" {
+		location.Message = &sarifMessage{Text: message}
+	}
+	return location
+}
+
+// sarifLog converts the diagnostics to a SARIF log with one run, whose
+// rules are the codes of the diagnostics described by zeno explain
+func sarifLog(diagnostics []Diagnostic) sarifDocument {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: "zeno", Rules: []sarifRule{}}},
+		Results: []sarifResult{},
+	}
+	seen := make(map[string]bool)
+	for _, d := range diagnostics {
+		if d.Code != "" && !seen[d.Code] {
+			seen[d.Code] = true
+			rule := sarifRule{ID: d.Code, ShortDescription: sarifMessage{Text: d.Code}}
+			if e, ok := i18n.Explain(d.Code); ok {
+				rule.ShortDescription.Text = e.Title
+			}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+
+		level := "note"
+		switch d.Severity {
+		case Error:
+			level = "error"
+		case Warning:
+			level = "warning"
+		}
+		result := sarifResult{RuleID: d.Code, Level: level, Message: sarifMessage{Text: d.Message}}
+		if location := sarifLocationOf(d.File, d.Pos.Line, d.Pos.Column, ""); location != nil {
+			result.Locations = append(result.Locations, *location)
+		}
+		for _, related := range d.Related {
+			if location := sarifLocationOf(related.File, related.Pos.Line, related.Pos.Column, related.Message); location != nil {
+				result.RelatedLocations = append(result.RelatedLocations, *location)
+			}
+		}
+		run.Results = append(run.Results, result)
+	}
+	return sarifDocument{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{run},
+	}
+}
@@ -0,0 +1,74 @@
+package diagnostics
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/linkalls/zeno-lang/ast"
+)
+
+// sourceLine returns the text of line (starting at 1) of source
+func sourceLine(source string, line int) (string, bool) {
+	lines := strings.Split(source, "\n")
+	if source == "" || line < 1 || line > len(lines) {
+		return "", false
+	}
+	return strings.TrimRight(lines[line-1], "\r"), true
+}
+
+// spanLength returns the number of characters to underline from column in
+// text: length when it is known, otherwise the word starting there, or a
+// single character
+func spanLength(text []rune, column, length int) int {
+	start := column - 1
+	if length <= 0 {
+		length = 0
+		for i := start; i < len(text) && (unicode.IsLetter(text[i]) || unicode.IsDigit(text[i]) || text[i] == '_'); i++ {
+			length++
+		}
+	}
+	if start+length > len(text) {
+		length = len(text) - start
+	}
+	if length < 1 {
+		length = 1
+	}
+	return length
+}
+
+// snippet renders the line of source at pos with the span of length
+// characters underlined, a caret under its first one:
+//
+//	2 |     let x: int = "s"
+//	  |     ^~~
+//
+// It returns "" if the line is not in source.
+func snippet(source string, pos ast.Position, length int) string {
+	line, ok := sourceLine(source, pos.Line)
+	if !ok || pos.Column < 1 {
+		return ""
+	}
+	text := []rune(line)
+	if pos.Column > len(text)+1 {
+		return ""
+	}
+	// The bar lines up with the one of the context, "   | "
+	number := fmt.Sprintf("%2d", pos.Line)
+	gutter := strings.Repeat(" ", len(number))
+
+	var builder strings.Builder
+	builder.WriteString(gutter + " |\n")
+	builder.WriteString(number + " | " + line + "\n")
+	builder.WriteString(gutter + " | ")
+	// Tabs are kept so that the underline lines up with the text
+	for _, r := range text[:pos.Column-1] {
+		if r == '\t' {
+			builder.WriteRune('\t')
+		} else {
+			builder.WriteRune(' ')
+		}
+	}
+	builder.WriteString("^" + strings.Repeat("~", spanLength(text, pos.Column, length)-1) + "\n")
+	return builder.String()
+}
@@ -0,0 +1,250 @@
+package evaluator
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/linkalls/zeno-lang/i18n"
+)
+
+// builtin is a function available without an import, mirroring the
+// helpers the generator emits for compiled programs
+type builtin struct {
+	params   int
+	optional int // trailing parameters that may be left out
+	fn       func(args []interface{}) (interface{}, error)
+}
+
+var builtins = map[string]builtin{
+	"len":       {params: 1, fn: builtinLen},
+	"str":       {params: 1, fn: func(args []interface{}) (interface{}, error) { return str(args[0]), nil }},
+	"int":       {params: 1, fn: builtinInt},
+	"float":     {params: 1, fn: builtinFloat},
+	"typeOf":    {params: 1, fn: func(args []interface{}) (interface{}, error) { return typeOf(args[0]), nil }},
+	"toString":  {params: 1, fn: func(args []interface{}) (interface{}, error) { return str(args[0]), nil }},
+	"toInt":     {params: 1, fn: builtinInt},
+	"toFloat":   {params: 1, fn: builtinFloat},
+	"parseBool": {params: 1, fn: builtinParseBool},
+	"char":      {params: 1, fn: builtinChar},
+	"ok":        {params: 1, fn: func(args []interface{}) (interface{}, error) { return ok(args[0]), nil }},
+	"err":       {params: 1, fn: func(args []interface{}) (interface{}, error) { return &Result{Error: args[0]}, nil }},
+	"some":      {params: 1, fn: func(args []interface{}) (interface{}, error) { return &Option{Some: true, Value: args[0]}, nil }},
+	"unwrapOr":  {params: 2, fn: builtinUnwrapOr},
+	"range":     {params: 3, optional: 2, fn: builtinRange},
+	// The REPL runs no program, so there are no arguments
+	"args": {params: 0, fn: func(args []interface{}) (interface{}, error) { return []interface{}{}, nil }},
+	// spawn runs its code before returning, so there is nothing to wait for
+	"wait":       {params: 0, fn: func(args []interface{}) (interface{}, error) { return nil, nil }},
+	"panic":      {params: 1, fn: builtinPanic},
+	"chan":       {params: 1, optional: 1, fn: builtinChan},
+	"send":       {params: 2, fn: builtinSend},
+	"recv":       {params: 1, fn: builtinRecv},
+	"close":      {params: 1, fn: builtinClose},
+	"assertEq":   {params: 2, fn: builtinAssertEq},
+	"assertTrue": {params: 1, fn: builtinAssertTrue},
+}
+
+func builtinPanic(args []interface{}) (interface{}, error) {
+	return nil, &RuntimeError{Message: "panic: " + str(args[0]), panicked: str(args[0])}
+}
+
+func builtinAssertEq(args []interface{}) (interface{}, error) {
+	if !reflect.DeepEqual(args[0], args[1]) {
+		return nil, &RuntimeError{Message: fmt.Sprintf("assertEq failed: got %s, want %s", assertValue(args[0]), assertValue(args[1])), assertion: true}
+	}
+	return nil, nil
+}
+
+func builtinAssertTrue(args []interface{}) (interface{}, error) {
+	if condition, ok := args[0].(bool); !ok || !condition {
+		return nil, &RuntimeError{Message: "assertTrue failed", assertion: true}
+	}
+	return nil, nil
+}
+
+// assertValue writes a value in an assertion message, strings quoted
+func assertValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s)
+	}
+	return str(value)
+}
+
+// native is a Go function that standard library modules call directly
+type native func(ev *Evaluator, args []interface{}) (interface{}, error)
+
+var natives = map[string]native{
+	"zenoNativePrintVariadicWithFirst":   nativePrint(false),
+	"zenoNativePrintlnVariadicWithFirst": nativePrint(true),
+	"zenoBuiltinPanic": func(ev *Evaluator, args []interface{}) (interface{}, error) {
+		return builtinPanic(args)
+	},
+}
+
+// nativePrint prints its first argument and the rest separated by spaces
+func nativePrint(newline bool) native {
+	return func(ev *Evaluator, args []interface{}) (interface{}, error) {
+		fmt.Fprint(ev.out, args[0])
+		if rest, ok := args[1].([]interface{}); ok {
+			for _, arg := range rest {
+				fmt.Fprint(ev.out, " ", arg)
+			}
+		}
+		if newline {
+			fmt.Fprintln(ev.out)
+		}
+		return nil, nil
+	}
+}
+
+func builtinLen(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case string:
+		return len([]rune(v)), nil
+	case []interface{}:
+		return len(v), nil
+	case map[string]interface{}:
+		return len(v), nil
+	}
+	return nil, &RuntimeError{Message: fmt.Sprintf("len: %s has no length", typeOf(args[0]))}
+}
+
+// builtinRange returns the numbers from start up to end by step:
+// range(end), range(start, end) or range(start, end, step)
+func builtinRange(args []interface{}) (interface{}, error) {
+	bounds := []int{0, 0, 1}
+	if len(args) == 1 {
+		args = []interface{}{0, args[0]}
+	}
+	for i, arg := range args {
+		n, isInt := arg.(int)
+		if !isInt {
+			return nil, &RuntimeError{Message: fmt.Sprintf("range: %s is not an int", typeOf(arg))}
+		}
+		bounds[i] = n
+	}
+	start, end, step := bounds[0], bounds[1], bounds[2]
+	if step == 0 {
+		return nil, &RuntimeError{Message: i18n.T(i18n.TypeRangeStep)}
+	}
+	result := []interface{}{}
+	for i := start; (step > 0 && i < end) || (step < 0 && i > end); i += step {
+		result = append(result, i)
+	}
+	return result, nil
+}
+
+func builtinInt(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case int:
+		return ok(v), nil
+	case Char:
+		return ok(int(v)), nil
+	case float64:
+		return ok(int(v)), nil
+	case string:
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return fail(0, fmt.Sprintf("cannot convert %q to int", v)), nil
+		}
+		return ok(n), nil
+	}
+	return fail(0, fmt.Sprintf("cannot convert %s to int", typeOf(args[0]))), nil
+}
+
+func builtinFloat(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case int:
+		return ok(float64(v)), nil
+	case float64:
+		return ok(v), nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return fail(0.0, fmt.Sprintf("cannot convert %q to float", v)), nil
+		}
+		return ok(f), nil
+	}
+	return fail(0.0, fmt.Sprintf("cannot convert %s to float", typeOf(args[0]))), nil
+}
+
+func builtinChar(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case Char:
+		return ok(v), nil
+	case int:
+		if v > utf8.MaxRune || !utf8.ValidRune(rune(v)) {
+			return fail(Char(0), fmt.Sprintf("%d is not a valid character", v)), nil
+		}
+		return ok(Char(v)), nil
+	case string:
+		if r, size := utf8.DecodeRuneInString(v); size > 0 && size == len(v) && (r != utf8.RuneError || size > 1) {
+			return ok(Char(r)), nil
+		}
+		return fail(Char(0), fmt.Sprintf("cannot convert %q to char, which holds one character", v)), nil
+	}
+	return fail(Char(0), fmt.Sprintf("cannot convert %s to char", typeOf(args[0]))), nil
+}
+
+func builtinParseBool(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case bool:
+		return ok(v), nil
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return fail(false, fmt.Sprintf("cannot convert %q to bool", v)), nil
+		}
+		return ok(b), nil
+	}
+	return fail(false, fmt.Sprintf("cannot convert %s to bool", typeOf(args[0]))), nil
+}
+
+func str(value interface{}) string {
+	if value == nil {
+		return "nil"
+	}
+	return fmt.Sprint(value)
+}
+
+// typeOf returns the Zeno name of the type of value
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "nil"
+	case int:
+		return "int"
+	case Char:
+		return "char"
+	case float64:
+		return "float"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case *Function, *constructor:
+		return "function"
+	case *Variant:
+		return v.Enum
+	case *Result:
+		return "Result"
+	case *Option:
+		return "Option"
+	case *Tuple:
+		return "tuple"
+	case *Channel:
+		return "Channel"
+	case *Namespace:
+		return "module"
+	}
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map:
+		return "map"
+	}
+	return reflect.TypeOf(value).String()
+}
@@ -0,0 +1,166 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/linkalls/zeno-lang/ast"
+)
+
+// Channel is a value created with chan. The REPL runs spawned code at once,
+// so a channel holds every value sent to it until it is received: send never
+// waits, and receiving from an empty channel that is still open is an error,
+// as it would wait forever.
+type Channel struct {
+	items  []interface{}
+	closed bool
+}
+
+func (c *Channel) String() string {
+	return fmt.Sprintf("Channel(%d)", len(c.items))
+}
+
+// ready reports whether a receive from the channel returns without waiting
+func (c *Channel) ready() bool {
+	return len(c.items) > 0 || c.closed
+}
+
+// receive takes the oldest value sent to the channel; ok is false once the
+// channel is closed and empty
+func (c *Channel) receive() (value interface{}, ok bool) {
+	if len(c.items) == 0 {
+		return nil, false
+	}
+	value = c.items[0]
+	c.items = c.items[1:]
+	return value, true
+}
+
+// channelArg returns the channel argument of the builtin name
+func channelArg(name string, arg interface{}) (*Channel, error) {
+	channel, isChannel := arg.(*Channel)
+	if !isChannel {
+		return nil, &RuntimeError{Message: fmt.Sprintf("%s: %s is not a Channel", name, typeOf(arg))}
+	}
+	return channel, nil
+}
+
+// builtinChan creates a channel; the capacity is accepted but not needed
+func builtinChan(args []interface{}) (interface{}, error) {
+	if len(args) == 1 {
+		if _, isInt := args[0].(int); !isInt {
+			return nil, &RuntimeError{Message: fmt.Sprintf("chan: %s is not an int", typeOf(args[0]))}
+		}
+	}
+	return &Channel{}, nil
+}
+
+func builtinSend(args []interface{}) (interface{}, error) {
+	channel, err := channelArg("send", args[0])
+	if err != nil {
+		return nil, err
+	}
+	if channel.closed {
+		return nil, &RuntimeError{Message: "send: the channel is closed"}
+	}
+	channel.items = append(channel.items, args[1])
+	return nil, nil
+}
+
+func builtinRecv(args []interface{}) (interface{}, error) {
+	channel, err := channelArg("recv", args[0])
+	if err != nil {
+		return nil, err
+	}
+	if !channel.ready() {
+		return nil, &RuntimeError{Message: "recv: the channel is empty and no code is left to send to it"}
+	}
+	value, ok := channel.receive()
+	return &Option{Some: ok, Value: value}, nil
+}
+
+func builtinClose(args []interface{}) (interface{}, error) {
+	channel, err := channelArg("close", args[0])
+	if err != nil {
+		return nil, err
+	}
+	if channel.closed {
+		return nil, &RuntimeError{Message: "close: the channel is already closed"}
+	}
+	channel.closed = true
+	return nil, nil
+}
+
+// execWhen runs the first arm of a when that can go ahead, or else its _ arm.
+// A compiled program picks one of the ready arms at random, so this is one
+// of the arms it may run.
+func (ev *Evaluator) execWhen(s *ast.WhenStatement, env *Environment) (signal, interface{}, error) {
+	var fallback *ast.WhenArm
+	for i := range s.Arms {
+		arm := &s.Arms[i]
+		if arm.IsDefault() {
+			fallback = arm
+			continue
+		}
+		value, err := ev.eval(arm.Channel, env)
+		if err != nil {
+			return signalNone, nil, err
+		}
+		channel, isChannel := value.(*Channel)
+		if !isChannel {
+			return signalNone, nil, runtimeError(arm.Channel, "when: %s is not a Channel", typeOf(value))
+		}
+		armEnv := newEnclosedEnvironment(env)
+		if arm.Sent != nil {
+			// A send to a closed channel is an error rather than a wait
+			sent, err := ev.eval(arm.Sent, env)
+			if err != nil {
+				return signalNone, nil, err
+			}
+			if _, err := builtinSend([]interface{}{channel, sent}); err != nil {
+				return signalNone, nil, runtimeError(arm, "%s", err.(*RuntimeError).Message)
+			}
+		} else {
+			if !channel.ready() {
+				continue
+			}
+			received, _ := channel.receive()
+			if arm.Name != "" {
+				armEnv.Define(arm.Name, received)
+			}
+		}
+		return ev.execWhenArm(arm, armEnv)
+	}
+	if fallback == nil {
+		return signalNone, nil, runtimeError(s, "when: no arm is ready and no code is left to make one ready")
+	}
+	return ev.execWhenArm(fallback, newEnclosedEnvironment(env))
+}
+
+// execWhenArm runs the body of a chosen arm of a when
+func (ev *Evaluator) execWhenArm(arm *ast.WhenArm, env *Environment) (signal, interface{}, error) {
+	if arm.Block != nil {
+		return ev.execBlock(arm.Block.Statements, env)
+	}
+	_, err := ev.eval(arm.Value, env)
+	return signalNone, nil, err
+}
+
+// execChannelLoop runs a for loop over the values received from a channel
+// until it is closed
+func (ev *Evaluator) execChannelLoop(s *ast.ForStatement, channel *Channel, env *Environment) (signal, interface{}, error) {
+	for {
+		if !channel.ready() {
+			return signalNone, nil, runtimeError(s.Iterable, "for: the channel is empty and no code is left to send to it or close it")
+		}
+		item, ok := channel.receive()
+		if !ok {
+			return signalNone, nil, nil
+		}
+		loopEnv := newEnclosedEnvironment(env)
+		loopEnv.Define(s.VarName, item)
+		sig, value, err := ev.execBlock(s.Body.Statements, loopEnv)
+		if done, sig := loopControl(sig); err != nil || done {
+			return sig, value, err
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package evaluator
+
+import (
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+)
+
+// destructure defines the names of a let that takes value apart with the
+// elements of a tuple, the fields of a struct or map, or the elements of an
+// array. _ discards an element.
+func destructure(s *ast.LetDeclaration, value interface{}, env *Environment) error {
+	elements := make([]interface{}, len(s.Names))
+	switch s.Destructure {
+	case ast.DestructureTuple:
+		tuple, ok := value.(*Tuple)
+		if !ok {
+			return runtimeError(s.ValueExpression, "%s", i18n.T(i18n.TypeNotTuple, typeOf(value)))
+		}
+		if len(tuple.Elements) != len(s.Names) {
+			return runtimeError(s, "%s", i18n.T(i18n.TypeTupleArity, tuple.typeName(), len(tuple.Elements), len(s.Names)))
+		}
+		copy(elements, tuple.Elements)
+	case ast.DestructureFields:
+		// Structs are maps of their fields
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return runtimeError(s.ValueExpression, "%s", i18n.T(i18n.TypeNotStructOrMap, typeOf(value)))
+		}
+		for i, name := range s.Names {
+			elements[i] = fields[name]
+		}
+	case ast.DestructureArray:
+		items, ok := value.([]interface{})
+		if !ok {
+			return runtimeError(s.ValueExpression, "%s", i18n.T(i18n.TypeNotArray, typeOf(value)))
+		}
+		if len(items) < len(s.Names) {
+			return runtimeError(s, "%s", i18n.T(i18n.TypeIndexOutOfRange, len(s.Names)-1, len(items)))
+		}
+		copy(elements, items)
+	}
+	for i, name := range s.Names {
+		switch {
+		case name == "_":
+		case s.Mutable:
+			env.DefineMutable(name, elements[i])
+		default:
+			env.Define(name, elements[i])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package evaluator
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+)
+
+// Variant is a value of an enum variant
+type Variant struct {
+	Enum   string
+	Name   string
+	Fields []interface{}
+}
+
+// String prints a variant like the call that creates it, as generated code
+// does
+func (v *Variant) String() string {
+	if len(v.Fields) == 0 {
+		return v.Name
+	}
+	fields := make([]string, len(v.Fields))
+	for i, field := range v.Fields {
+		if s, ok := field.(string); ok {
+			fields[i] = strconv.Quote(s)
+		} else {
+			fields[i] = str(field)
+		}
+	}
+	return v.Name + "(" + strings.Join(fields, ", ") + ")"
+}
+
+// constructor creates values of an enum variant with fields
+type constructor struct {
+	enum    *ast.EnumDeclaration
+	variant *ast.EnumVariant
+}
+
+// declareEnum binds the variants of decl in env: variants without fields
+// are values, the others constructors
+func declareEnum(decl *ast.EnumDeclaration, env *Environment) {
+	for i := range decl.Variants {
+		variant := &decl.Variants[i]
+		if len(variant.Fields) == 0 {
+			env.Define(variant.Name, &Variant{Enum: decl.Name, Name: variant.Name})
+		} else {
+			env.Define(variant.Name, &constructor{enum: decl, variant: variant})
+		}
+	}
+}
+
+// construct calls a variant constructor
+func (c *constructor) construct(call *ast.FunctionCall, args []interface{}) (interface{}, error) {
+	if len(args) != len(c.variant.Fields) {
+		return nil, runtimeError(call, "%s", i18n.T(i18n.GenArgumentCount, call.Name, len(c.variant.Fields), len(args), call.String()))
+	}
+	return &Variant{Enum: c.enum.Name, Name: c.variant.Name, Fields: args}, nil
+}
+
+// matchVariant matches subject against a pattern naming an enum variant,
+// Empty or Circle(r). ok reports whether pattern is such a pattern; matched
+// whether subject is a value of the variant, in which case the fields are
+// bound in armEnv.
+func matchVariant(pattern ast.Expression, subject interface{}, env, armEnv *Environment) (ok, matched bool) {
+	var name string
+	var bindings []ast.Expression
+	switch p := pattern.(type) {
+	case *ast.Identifier:
+		value, _ := env.Get(p.Value)
+		if _, ok := value.(*Variant); !ok {
+			return false, false
+		}
+		name = p.Value
+	case *ast.FunctionCall:
+		value, _ := env.Get(p.Name)
+		if _, ok := value.(*constructor); !ok {
+			return false, false
+		}
+		name, bindings = p.Name, p.Arguments
+	default:
+		return false, false
+	}
+
+	v, isVariant := subject.(*Variant)
+	if !isVariant || v.Name != name {
+		return true, false
+	}
+	for i, binding := range bindings {
+		if ident, ok := binding.(*ast.Identifier); ok && ident.Value != "_" && i < len(v.Fields) {
+			armEnv.Define(ident.Value, v.Fields[i])
+		}
+	}
+	return true, true
+}
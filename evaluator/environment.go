@@ -0,0 +1,79 @@
+package evaluator
+
+// Environment maps variable names to values. Each function call and block
+// gets its own environment enclosing the one it was created in.
+type Environment struct {
+	values  map[string]interface{}
+	mutable map[string]bool
+	outer   *Environment
+	// reexported are the names a module imports with pub import
+	reexported map[string]bool
+}
+
+// NewEnvironment creates an empty top-level environment
+func NewEnvironment() *Environment {
+	return &Environment{values: make(map[string]interface{}), mutable: make(map[string]bool)}
+}
+
+// newEnclosedEnvironment creates an environment nested in outer
+func newEnclosedEnvironment(outer *Environment) *Environment {
+	env := NewEnvironment()
+	env.outer = outer
+	return env
+}
+
+// Get looks name up in this environment and the ones enclosing it
+func (e *Environment) Get(name string) (interface{}, bool) {
+	for env := e; env != nil; env = env.outer {
+		if value, ok := env.values[name]; ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// Define creates or replaces name in this environment as an immutable
+// variable
+func (e *Environment) Define(name string, value interface{}) {
+	e.values[name] = value
+	delete(e.mutable, name)
+}
+
+// DefineMutable creates or replaces name in this environment as a variable
+// declared with let mut
+func (e *Environment) DefineMutable(name string, value interface{}) {
+	e.values[name] = value
+	e.mutable[name] = true
+}
+
+// Mutable reports whether the variable name resolves to was declared with
+// let mut
+func (e *Environment) Mutable(name string) bool {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.values[name]; ok {
+			return env.mutable[name]
+		}
+	}
+	return false
+}
+
+// Export returns the public function name of the module env is the
+// environment of, declared by it or re-exported with pub import
+func (e *Environment) Export(name string) (*Function, bool) {
+	fn, ok := e.values[name].(*Function)
+	if !ok || !fn.Definition.IsPublic || (fn.env != e && !e.reexported[name]) {
+		return nil, false
+	}
+	return fn, true
+}
+
+// Set assigns to an existing variable and reports whether it was found
+func (e *Environment) Set(name string, value interface{}) bool {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.values[name]; ok {
+			env.values[name] = value
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,975 @@
+// Package evaluator interprets Zeno programs by walking the AST. It backs
+// the REPL, where compiling a Go program for every line would be too slow.
+//
+// Values are represented like in generated code: int, float64, string,
+// bool, []interface{} for arrays and map[string]interface{} for maps and
+// struct literals, so they print the same way.
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/deps"
+	"github.com/linkalls/zeno-lang/i18n"
+	"github.com/linkalls/zeno-lang/lexer"
+	"github.com/linkalls/zeno-lang/parser"
+	"github.com/linkalls/zeno-lang/stdlib"
+)
+
+// Function is a Zeno function value
+type Function struct {
+	Definition *ast.FunctionDefinition
+	env        *Environment // environment the function was defined in
+}
+
+func (f *Function) String() string {
+	return "fn " + f.Definition.Name
+}
+
+// Namespace is a module imported with import * as, whose public functions
+// are called as io.readFile(path)
+type Namespace struct {
+	Module string
+	env    *Environment
+}
+
+func (n *Namespace) String() string {
+	return "module " + n.Module
+}
+
+// Char is a char value, which prints as the character like the zenoChar of
+// generated code
+type Char rune
+
+func (c Char) String() string {
+	return string(c)
+}
+
+// RuntimeError is an error raised while evaluating a program
+type RuntimeError struct {
+	Message string
+	Pos     ast.Position // location in the Zeno source, zero if unknown
+	// panicked is the message given to panic, empty for other errors
+	panicked string
+	// assertion is set for a failed assertion, which catch does not handle
+	assertion bool
+}
+
+func (e *RuntimeError) Error() string {
+	if e.Pos.IsValid() {
+		return e.Message + "\n  --> " + i18n.T(i18n.LabelLocation, e.Pos.Line, e.Pos.Column)
+	}
+	return e.Message
+}
+
+func runtimeError(node ast.Node, format string, args ...interface{}) *RuntimeError {
+	return &RuntimeError{Message: fmt.Sprintf(format, args...), Pos: node.Pos()}
+}
+
+// signal tells enclosing statements how control leaves a statement
+type signal int
+
+const (
+	signalNone signal = iota
+	signalBreak
+	signalContinue
+	signalReturn
+)
+
+// Evaluator runs Zeno statements against a persistent global environment
+type Evaluator struct {
+	// Dir is the directory relative module imports ("./utils") are
+	// resolved against
+	Dir string
+
+	out     io.Writer
+	globals *Environment
+	// modules caches the environments of loaded modules by path
+	modules map[string]*Environment
+	// loading holds the paths of the modules being loaded, importers first
+	loading []string
+	// structs holds the declared struct types by name, and impls the
+	// traits they implement by trait name
+	structs map[string]*ast.TypeDeclaration
+	impls   map[string][]impl
+}
+
+// New creates an Evaluator that prints program output to out
+func New(out io.Writer) *Evaluator {
+	return &Evaluator{
+		Dir:     ".",
+		out:     out,
+		globals: NewEnvironment(),
+		modules: make(map[string]*Environment),
+		structs: make(map[string]*ast.TypeDeclaration),
+		impls:   make(map[string][]impl),
+	}
+}
+
+// Globals returns the environment holding top-level variables and functions
+func (ev *Evaluator) Globals() *Environment {
+	return ev.globals
+}
+
+// Eval runs the statements of program. If the last statement is an
+// expression, its value is returned.
+func (ev *Evaluator) Eval(program *ast.Program) (interface{}, error) {
+	var last interface{}
+	for _, stmt := range program.Statements {
+		last = nil
+		if es, ok := stmt.(*ast.ExpressionStatement); ok && !isMatchStatement(es.Expression) {
+			value, err := ev.eval(es.Expression, ev.globals)
+			if err != nil {
+				return nil, err
+			}
+			last = value
+			continue
+		}
+		sig, _, err := ev.exec(stmt, ev.globals)
+		if err != nil {
+			return nil, err
+		}
+		if sig != signalNone {
+			return nil, runtimeError(stmt, "'%s' outside of a function or loop", stmt.String())
+		}
+	}
+	return last, nil
+}
+
+// execBlock runs statements in a new environment nested in env
+func (ev *Evaluator) execBlock(statements []ast.Statement, env *Environment) (signal, interface{}, error) {
+	blockEnv := newEnclosedEnvironment(env)
+	for _, stmt := range statements {
+		sig, value, err := ev.exec(stmt, blockEnv)
+		if err != nil || sig != signalNone {
+			return sig, value, err
+		}
+	}
+	return signalNone, nil, nil
+}
+
+func (ev *Evaluator) exec(stmt ast.Statement, env *Environment) (signal, interface{}, error) {
+	switch s := stmt.(type) {
+	case *ast.ExpressionStatement:
+		if match, ok := s.Expression.(*ast.MatchExpression); ok {
+			return ev.execMatch(match, env)
+		}
+		_, err := ev.eval(s.Expression, env)
+		return signalNone, nil, err
+	case *ast.LetDeclaration:
+		value, err := ev.eval(s.ValueExpression, env)
+		if err != nil {
+			return signalNone, nil, err
+		}
+		if s.Names != nil {
+			return signalNone, nil, destructure(s, value, env)
+		}
+		if s.Mutable {
+			env.DefineMutable(s.Name, value)
+		} else {
+			env.Define(s.Name, value)
+		}
+	case *ast.AssignmentStatement:
+		value, err := ev.eval(s.Value, env)
+		if err != nil {
+			return signalNone, nil, err
+		}
+		if _, ok := env.Get(s.Name); ok && !env.Mutable(s.Name) {
+			return signalNone, nil, runtimeError(s, "%s", i18n.T(i18n.TypeAssignImmutable, s.Name))
+		}
+		if !env.Set(s.Name, value) {
+			return signalNone, nil, runtimeError(s, "undefined variable '%s'", s.Name)
+		}
+	case *ast.ReturnStatement:
+		if s.Value == nil {
+			return signalReturn, nil, nil
+		}
+		value, err := ev.eval(s.Value, env)
+		return signalReturn, value, err
+	case *ast.BreakStatement:
+		return signalBreak, nil, nil
+	case *ast.ContinueStatement:
+		return signalContinue, nil, nil
+	case *ast.IfStatement:
+		return ev.execIf(s, env)
+	case *ast.WhileStatement:
+		for {
+			condition, err := ev.condition(s.Condition, env)
+			if err != nil || !condition {
+				return signalNone, nil, err
+			}
+			sig, value, err := ev.execBlock(s.Block.Statements, env)
+			if done, sig := loopControl(sig); err != nil || done {
+				return sig, value, err
+			}
+		}
+	case *ast.LoopStatement:
+		for {
+			sig, value, err := ev.execBlock(s.Body.Statements, env)
+			if done, sig := loopControl(sig); err != nil || done {
+				return sig, value, err
+			}
+		}
+	case *ast.SpawnStatement:
+		// The REPL runs spawned code at once, which is one of the orders a
+		// compiled program may run it in
+		_, _, err := ev.execBlock(s.Statements(), env)
+		return signalNone, nil, err
+	case *ast.WhenStatement:
+		return ev.execWhen(s, env)
+	case *ast.TryStatement:
+		return ev.execTry(s, env)
+	case *ast.ForStatement:
+		iterable, err := ev.eval(s.Iterable, env)
+		if err != nil {
+			return signalNone, nil, err
+		}
+		if channel, ok := iterable.(*Channel); ok {
+			return ev.execChannelLoop(s, channel, env)
+		}
+		items, ok := iterable.([]interface{})
+		if text, isString := iterable.(string); isString {
+			// A string is iterated over by character
+			items, ok = nil, true
+			for _, r := range text {
+				items = append(items, Char(r))
+			}
+		}
+		if !ok {
+			return signalNone, nil, runtimeError(s.Iterable, "cannot iterate over %s", typeOf(iterable))
+		}
+		for i, item := range items {
+			loopEnv := newEnclosedEnvironment(env)
+			if s.IndexName != "" {
+				loopEnv.Define(s.IndexName, i)
+			}
+			loopEnv.Define(s.VarName, item)
+			sig, value, err := ev.execBlock(s.Body.Statements, loopEnv)
+			if done, sig := loopControl(sig); err != nil || done {
+				return sig, value, err
+			}
+		}
+	case *ast.FunctionDefinition:
+		env.Define(s.Name, &Function{Definition: s, env: env})
+	case *ast.ImportStatement:
+		return signalNone, nil, ev.importModule(s, env)
+	case *ast.TypeDeclaration:
+		// types are not checked at run time, only known for their impls
+		ev.structs[s.Name] = s
+	case *ast.ImplDeclaration:
+		return signalNone, nil, ev.declareImpl(s, env)
+	case *ast.EnumDeclaration:
+		declareEnum(s, env)
+	default:
+		return signalNone, nil, runtimeError(stmt, "unsupported statement: %s", stmt.String())
+	}
+	return signalNone, nil, nil
+}
+
+// execTry runs a try block and, if it fails with a runtime error other than
+// a failed assertion, the catch block
+func (ev *Evaluator) execTry(s *ast.TryStatement, env *Environment) (signal, interface{}, error) {
+	sig, value, err := ev.execBlock(s.Body.Statements, env)
+	var failure *RuntimeError
+	if !errors.As(err, &failure) || failure.assertion {
+		return sig, value, err
+	}
+	message := failure.panicked
+	if message == "" {
+		message = failure.Message
+	}
+	catchEnv := newEnclosedEnvironment(env)
+	if s.CatchName != "" {
+		catchEnv.Define(s.CatchName, message)
+	}
+	return ev.execBlock(s.Catch.Statements, catchEnv)
+}
+
+// loopControl interprets the signal from a loop body. done reports whether
+// the loop ends; sig is what the loop passes on to its enclosing statement.
+func loopControl(sig signal) (done bool, passed signal) {
+	switch sig {
+	case signalBreak:
+		return true, signalNone
+	case signalReturn:
+		return true, signalReturn
+	}
+	return false, signalNone
+}
+
+func (ev *Evaluator) execIf(s *ast.IfStatement, env *Environment) (signal, interface{}, error) {
+	condition, err := ev.condition(s.Condition, env)
+	if err != nil {
+		return signalNone, nil, err
+	}
+	if condition {
+		return ev.execBlock(s.ThenBlock.Statements, env)
+	}
+	for _, clause := range s.ElseIfClauses {
+		condition, err := ev.condition(clause.Condition, env)
+		if err != nil {
+			return signalNone, nil, err
+		}
+		if condition {
+			return ev.execBlock(clause.Block.Statements, env)
+		}
+	}
+	if s.ElseBlock != nil {
+		return ev.execBlock(s.ElseBlock.Statements, env)
+	}
+	return signalNone, nil, nil
+}
+
+// matchArm returns the first arm of e whose pattern matches the subject,
+// nil if there is none, and the environment of the arm holding the names
+// its pattern binds
+func (ev *Evaluator) matchArm(e *ast.MatchExpression, env *Environment) (*ast.MatchArm, interface{}, *Environment, error) {
+	subject, err := ev.eval(e.Subject, env)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for i := range e.Arms {
+		arm := &e.Arms[i]
+		armEnv := newEnclosedEnvironment(env)
+		if arm.IsWildcard() {
+			return arm, subject, armEnv, nil
+		}
+		if isVariant, matched := matchVariant(arm.Pattern, subject, env, armEnv); isVariant {
+			if matched {
+				return arm, subject, armEnv, nil
+			}
+			continue
+		}
+		if isOption, matched := matchOption(arm.Pattern, subject, env, armEnv); isOption {
+			if matched {
+				return arm, subject, armEnv, nil
+			}
+			continue
+		}
+		pattern, err := ev.eval(arm.Pattern, env)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if equal(subject, pattern) {
+			return arm, subject, armEnv, nil
+		}
+	}
+	return nil, subject, nil, nil
+}
+
+// execMatch runs a match whose value is not used. Statements in its arms
+// may return from the enclosing function or leave the enclosing loop.
+func (ev *Evaluator) execMatch(e *ast.MatchExpression, env *Environment) (signal, interface{}, error) {
+	arm, _, armEnv, err := ev.matchArm(e, env)
+	if err != nil || arm == nil {
+		return signalNone, nil, err
+	}
+	if arm.Block != nil {
+		return ev.execBlock(arm.Block.Statements, armEnv)
+	}
+	_, err = ev.eval(arm.Value, armEnv)
+	return signalNone, nil, err
+}
+
+// evalMatch evaluates a match used as a value: the value of the matching
+// arm, which for a block is its last expression statement
+func (ev *Evaluator) evalMatch(e *ast.MatchExpression, env *Environment) (interface{}, error) {
+	arm, subject, armEnv, err := ev.matchArm(e, env)
+	if err != nil {
+		return nil, err
+	}
+	if arm == nil {
+		return nil, runtimeError(e, "no match arm for %s", str(subject))
+	}
+	if arm.Block == nil {
+		return ev.eval(arm.Value, armEnv)
+	}
+	return ev.evalBlockValue(arm, arm.Block.Statements, armEnv, i18n.GenMatchArmWithoutValue)
+}
+
+// evalIf evaluates an if used as a value: the value of the branch taken,
+// which is its last expression statement
+func (ev *Evaluator) evalIf(e *ast.IfExpression, env *Environment) (interface{}, error) {
+	block := e.ElseBlock
+	condition, err := ev.condition(e.Condition, env)
+	if err != nil {
+		return nil, err
+	}
+	if condition {
+		block = e.ThenBlock
+	} else {
+		for _, clause := range e.ElseIfClauses {
+			condition, err := ev.condition(clause.Condition, env)
+			if err != nil {
+				return nil, err
+			}
+			if condition {
+				block = clause.Block
+				break
+			}
+		}
+	}
+	return ev.evalBlockValue(block, block.Statements, newEnclosedEnvironment(env), i18n.GenIfBranchWithoutValue)
+}
+
+// evalBlockValue runs the statements of a match arm or an if branch used as
+// a value and returns the value of the last one. noValue reports a last
+// statement that is not an expression.
+func (ev *Evaluator) evalBlockValue(node ast.Node, statements []ast.Statement, env *Environment, noValue i18n.MessageID) (interface{}, error) {
+	last, ok := lastExpression(statements)
+	if !ok {
+		return nil, runtimeError(node, "%s", i18n.T(noValue))
+	}
+	for _, stmt := range statements[:len(statements)-1] {
+		sig, _, err := ev.exec(stmt, env)
+		if err != nil {
+			return nil, err
+		}
+		if sig != signalNone {
+			return nil, runtimeError(stmt, "'%s' cannot leave a block used as a value", stmt.String())
+		}
+	}
+	return ev.eval(last, env)
+}
+
+// isMatchStatement reports whether expr is a match with an arm that has no
+// value, which can only be run as a statement
+func isMatchStatement(expr ast.Expression) bool {
+	match, ok := expr.(*ast.MatchExpression)
+	if !ok {
+		return false
+	}
+	for _, arm := range match.Arms {
+		if arm.Block != nil {
+			if _, ok := lastExpression(arm.Block.Statements); !ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func lastExpression(statements []ast.Statement) (ast.Expression, bool) {
+	if len(statements) == 0 {
+		return nil, false
+	}
+	stmt, ok := statements[len(statements)-1].(*ast.ExpressionStatement)
+	if !ok {
+		return nil, false
+	}
+	return stmt.Expression, true
+}
+
+// condition evaluates expr as a condition. Like in compiled code, numbers
+// and strings are true unless zero or empty.
+func (ev *Evaluator) condition(expr ast.Expression, env *Environment) (bool, error) {
+	value, err := ev.eval(expr, env)
+	if err != nil {
+		return false, err
+	}
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case int:
+		return v != 0, nil
+	case float64:
+		return v != 0, nil
+	case string:
+		return v != "", nil
+	}
+	return false, runtimeError(expr, "condition must be a bool, got %s", typeOf(value))
+}
+
+func (ev *Evaluator) eval(expr ast.Expression, env *Environment) (interface{}, error) {
+	switch e := expr.(type) {
+	case *ast.IntegerLiteral:
+		return e.Value, nil
+	case *ast.FloatLiteral:
+		return e.Value, nil
+	case *ast.StringLiteral:
+		return e.Value, nil
+	case *ast.CharLiteral:
+		return Char(e.Value), nil
+	case *ast.BooleanLiteral:
+		return e.Value, nil
+	case *ast.Identifier:
+		if value, ok := env.Get(e.Value); ok {
+			return value, nil
+		}
+		if e.Value == "none" {
+			return &Option{}, nil
+		}
+		return nil, runtimeError(e, "undefined variable '%s'", e.Value)
+	case *ast.ArrayLiteral:
+		elements := make([]interface{}, len(e.Elements))
+		for i, element := range e.Elements {
+			value, err := ev.eval(element, env)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = value
+		}
+		return elements, nil
+	case *ast.TupleLiteral:
+		tuple := &Tuple{Elements: make([]interface{}, len(e.Elements))}
+		for i, element := range e.Elements {
+			value, err := ev.eval(element, env)
+			if err != nil {
+				return nil, err
+			}
+			tuple.Elements[i] = value
+		}
+		return tuple, nil
+	case *ast.MapLiteral:
+		result := make(map[string]interface{}, len(e.Pairs))
+		for _, key := range e.OrderedKeys() {
+			var name string
+			switch k := key.(type) {
+			case *ast.Identifier:
+				name = k.Value
+			case *ast.StringLiteral:
+				name = k.Value
+			default:
+				return nil, runtimeError(key, "unsupported map key: %s", key.String())
+			}
+			value, err := ev.eval(e.Pairs[key], env)
+			if err != nil {
+				return nil, err
+			}
+			result[name] = value
+		}
+		return result, nil
+	case *ast.StructLiteral:
+		result := make(map[string]interface{}, len(e.Fields))
+		for _, name := range e.OrderedFieldNames() {
+			value, err := ev.eval(e.Fields[name], env)
+			if err != nil {
+				return nil, err
+			}
+			result[name] = value
+		}
+		return result, nil
+	case *ast.ResultLiteral:
+		var value interface{}
+		if e.Value != nil {
+			var err error
+			if value, err = ev.eval(e.Value, env); err != nil {
+				return nil, err
+			}
+		}
+		return &Result{Ok: e.Ok, Value: value, Error: e.Error}, nil
+	case *ast.MemberExpression:
+		return ev.evalMember(e, e.Object, e.Property, env)
+	case *ast.MemberAccessExpression:
+		return ev.evalMember(e, e.Expression, e.Field.Value, env)
+	case *ast.UnaryExpression:
+		return ev.evalUnary(e, env)
+	case *ast.BinaryExpression:
+		return ev.evalBinary(e, env)
+	case *ast.FunctionCall:
+		return ev.evalCall(e, env)
+	case *ast.MatchExpression:
+		return ev.evalMatch(e, env)
+	case *ast.IfExpression:
+		return ev.evalIf(e, env)
+	case *ast.TryExpression:
+		return ev.evalTry(e, env)
+	case *ast.IndexExpression:
+		return ev.evalIndex(e, env)
+	case *ast.SliceExpression:
+		return ev.evalSlice(e, env)
+	case *ast.MethodCallExpression:
+		return ev.evalMethodCall(e, env)
+	case nil:
+		return nil, nil
+	}
+	return nil, runtimeError(expr, "unsupported expression: %s", expr.String())
+}
+
+// evalMember reads a field of a struct, map or Result value
+func (ev *Evaluator) evalMember(node ast.Node, objectExpr ast.Expression, field string, env *Environment) (interface{}, error) {
+	object, err := ev.eval(objectExpr, env)
+	if err != nil {
+		return nil, err
+	}
+	if result, isResult := object.(*Result); isResult {
+		if value, ok := result.field(field); ok {
+			return value, nil
+		}
+	}
+	fields, ok := object.(map[string]interface{})
+	if !ok {
+		return nil, runtimeError(node, "%s has no field '%s'", typeOf(object), field)
+	}
+	return fields[field], nil
+}
+
+func (ev *Evaluator) evalUnary(e *ast.UnaryExpression, env *Environment) (interface{}, error) {
+	right, err := ev.eval(e.Right, env)
+	if err != nil {
+		return nil, err
+	}
+	switch e.Operator {
+	case ast.UnaryOpMinus:
+		switch v := right.(type) {
+		case int:
+			return -v, nil
+		case float64:
+			return -v, nil
+		}
+	case ast.UnaryOpBang:
+		if v, ok := right.(bool); ok {
+			return !v, nil
+		}
+	}
+	return nil, runtimeError(e, "operator %s not defined on %s", e.Operator, typeOf(right))
+}
+
+func (ev *Evaluator) evalBinary(e *ast.BinaryExpression, env *Environment) (interface{}, error) {
+	left, err := ev.eval(e.Left, env)
+	if err != nil {
+		return nil, err
+	}
+	// && and || only evaluate the right operand when needed
+	if e.Operator == ast.BinaryOpAnd || e.Operator == ast.BinaryOpOr {
+		l, ok := left.(bool)
+		if !ok {
+			return nil, runtimeError(e, "operator %s not defined on %s", e.Operator, typeOf(left))
+		}
+		if l == (e.Operator == ast.BinaryOpOr) {
+			return l, nil
+		}
+		right, err := ev.eval(e.Right, env)
+		if err != nil {
+			return nil, err
+		}
+		if r, ok := right.(bool); ok {
+			return r, nil
+		}
+		return nil, runtimeError(e, "operator %s not defined on %s", e.Operator, typeOf(right))
+	}
+	right, err := ev.eval(e.Right, env)
+	if err != nil {
+		return nil, err
+	}
+	if method, ok := ev.operatorMethod(e.Operator, left, right); ok {
+		return ev.callOperator(e, method, left, right)
+	}
+
+	switch e.Operator {
+	case ast.BinaryOpEq:
+		return equal(left, right), nil
+	case ast.BinaryOpNotEq:
+		return !equal(left, right), nil
+	}
+
+	if l, ok := left.(string); ok {
+		if r, ok := right.(string); ok {
+			switch e.Operator {
+			case ast.BinaryOpPlus:
+				return l + r, nil
+			case ast.BinaryOpLt:
+				return l < r, nil
+			case ast.BinaryOpLte:
+				return l <= r, nil
+			case ast.BinaryOpGt:
+				return l > r, nil
+			case ast.BinaryOpGte:
+				return l >= r, nil
+			}
+		}
+	}
+
+	if l, ok := left.(Char); ok {
+		if r, ok := right.(Char); ok {
+			switch e.Operator {
+			case ast.BinaryOpLt:
+				return l < r, nil
+			case ast.BinaryOpLte:
+				return l <= r, nil
+			case ast.BinaryOpGt:
+				return l > r, nil
+			case ast.BinaryOpGte:
+				return l >= r, nil
+			}
+		}
+	}
+
+	if l, ok := left.(int); ok {
+		if r, ok := right.(int); ok {
+			switch e.Operator {
+			case ast.BinaryOpPlus:
+				return l + r, nil
+			case ast.BinaryOpMinus:
+				return l - r, nil
+			case ast.BinaryOpMultiply:
+				return l * r, nil
+			case ast.BinaryOpDivide, ast.BinaryOpModulo:
+				if r == 0 {
+					return nil, runtimeError(e, "integer divide by zero")
+				}
+				if e.Operator == ast.BinaryOpDivide {
+					return l / r, nil
+				}
+				return l % r, nil
+			case ast.BinaryOpBitAnd:
+				return l & r, nil
+			case ast.BinaryOpBitOr:
+				return l | r, nil
+			case ast.BinaryOpBitXor:
+				return l ^ r, nil
+			case ast.BinaryOpShiftLeft, ast.BinaryOpShiftRight:
+				if r < 0 {
+					return nil, runtimeError(e, "negative shift amount %d", r)
+				}
+				if e.Operator == ast.BinaryOpShiftLeft {
+					return l << r, nil
+				}
+				return l >> r, nil
+			case ast.BinaryOpLt:
+				return l < r, nil
+			case ast.BinaryOpLte:
+				return l <= r, nil
+			case ast.BinaryOpGt:
+				return l > r, nil
+			case ast.BinaryOpGte:
+				return l >= r, nil
+			}
+		}
+	}
+
+	if l, ok := toFloat(left); ok {
+		if r, ok := toFloat(right); ok {
+			switch e.Operator {
+			case ast.BinaryOpPlus:
+				return l + r, nil
+			case ast.BinaryOpMinus:
+				return l - r, nil
+			case ast.BinaryOpMultiply:
+				return l * r, nil
+			case ast.BinaryOpDivide:
+				return l / r, nil
+			case ast.BinaryOpLt:
+				return l < r, nil
+			case ast.BinaryOpLte:
+				return l <= r, nil
+			case ast.BinaryOpGt:
+				return l > r, nil
+			case ast.BinaryOpGte:
+				return l >= r, nil
+			}
+		}
+	}
+	return nil, runtimeError(e, "operator %s not defined on %s and %s", e.Operator, typeOf(left), typeOf(right))
+}
+
+// toFloat converts ints and floats to float64
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// equal compares values, treating ints and floats of the same value as equal
+func equal(left, right interface{}) bool {
+	if l, ok := toFloat(left); ok {
+		if r, ok := toFloat(right); ok {
+			return l == r
+		}
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+func (ev *Evaluator) evalCall(call *ast.FunctionCall, env *Environment) (interface{}, error) {
+	args := make([]interface{}, len(call.Arguments))
+	for i, arg := range call.Arguments {
+		value, err := ev.eval(arg, env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+
+	if value, ok := env.Get(call.Name); ok {
+		if c, isConstructor := value.(*constructor); isConstructor {
+			return c.construct(call, args)
+		}
+		fn, isFn := value.(*Function)
+		if !isFn {
+			return nil, runtimeError(call, "'%s' is a %s, not a function", call.Name, typeOf(value))
+		}
+		return ev.callFunction(fn, call, args)
+	}
+	if b, ok := builtins[call.Name]; ok {
+		if len(args) < b.params-b.optional && b.optional > 0 {
+			return nil, runtimeError(call, "%s", i18n.T(i18n.GenArgumentCountAtLeast, call.Name, b.params-b.optional, len(args), call.String()))
+		}
+		if len(args) < b.params-b.optional || len(args) > b.params {
+			return nil, runtimeError(call, "%s", i18n.T(i18n.GenArgumentCount, call.Name, b.params, len(args), call.String()))
+		}
+		return b.fn(args)
+	}
+	if native, ok := natives[call.Name]; ok {
+		return native(ev, args)
+	}
+	if modules := stdlib.ModulesExporting(call.Name); len(modules) > 0 {
+		err := runtimeError(call, "%s", i18n.T(i18n.GenMissingImport, call.Name))
+		err.Message += "; " + i18n.T(i18n.GenHintAddImport, stdlib.ImportLine(modules[0], call.Name))
+		return nil, err
+	}
+	return nil, runtimeError(call, "undefined function '%s'", call.Name)
+}
+
+// callFunction calls fn with evaluated arguments
+func (ev *Evaluator) callFunction(fn *Function, call *ast.FunctionCall, args []interface{}) (interface{}, error) {
+	params := fn.Definition.Parameters
+	required := len(params)
+	variadic := required > 0 && params[required-1].Variadic
+	if variadic {
+		required--
+	}
+	if len(args) < required || (!variadic && len(args) > required) {
+		if variadic {
+			return nil, runtimeError(call, "%s", i18n.T(i18n.GenArgumentCountAtLeast, call.Name, required, len(args), call.String()))
+		}
+		return nil, runtimeError(call, "%s", i18n.T(i18n.GenArgumentCount, call.Name, required, len(args), call.String()))
+	}
+
+	callEnv := newEnclosedEnvironment(fn.env)
+	for i := 0; i < required; i++ {
+		callEnv.Define(params[i].Name, args[i])
+	}
+	if variadic {
+		rest := append([]interface{}{}, args[required:]...)
+		callEnv.Define(params[required].Name, rest)
+	}
+	_, value, err := ev.execBlock(fn.Definition.Body, callEnv)
+	if errReturn, ok := err.(*errorReturn); ok {
+		return errReturn.result, nil
+	}
+	return value, err
+}
+
+// importModule binds the imported functions of a standard library or user
+// module in env
+func (ev *Evaluator) importModule(s *ast.ImportStatement, env *Environment) error {
+	moduleEnv, err := ev.loadModule(s)
+	if err != nil {
+		return err
+	}
+	if s.Namespace != "" {
+		env.Define(s.Namespace, &Namespace{Module: s.Module, env: moduleEnv})
+		return nil
+	}
+	for _, item := range s.Imports {
+		if item.IsType {
+			// Importing an enum imports its variants
+			for name, value := range moduleEnv.values {
+				switch v := value.(type) {
+				case *Variant:
+					if v.Enum == item.Name {
+						env.Define(name, v)
+					}
+				case *constructor:
+					if v.enum.Name == item.Name {
+						env.Define(name, v)
+					}
+				}
+			}
+			continue
+		}
+		fn, ok := moduleEnv.Export(item.Name)
+		if !ok {
+			return runtimeError(s, "%s", i18n.T(i18n.GenFunctionNotExported, item.Name, s.Module))
+		}
+		env.Define(item.LocalName(), fn)
+		if s.IsPublic {
+			if env.reexported == nil {
+				env.reexported = make(map[string]bool)
+			}
+			env.reexported[item.LocalName()] = true
+		}
+	}
+	return nil
+}
+
+// loadModule evaluates the declarations of the module imported by s
+func (ev *Evaluator) loadModule(s *ast.ImportStatement) (*Environment, error) {
+	var path string
+	if strings.HasPrefix(s.Module, "std/") {
+		path = stdlib.Path(s.Module)
+	} else if deps.IsRemote(s.Module) {
+		file, err := deps.Resolve(ev.Dir, s.Module)
+		var notFetched *deps.NotFetchedError
+		if errors.As(err, &notFetched) {
+			return nil, runtimeError(s, "%s", i18n.T(i18n.GenModuleNotFetched, s.Module, notFetched.Command()))
+		}
+		if err != nil {
+			return nil, runtimeError(s, "%s", i18n.T(i18n.GenModuleReadFailed, s.Module, err))
+		}
+		path = file
+	} else {
+		path = filepath.Join(ev.Dir, s.Module+".zeno")
+	}
+	for i, loading := range ev.loading {
+		if loading == path {
+			cycle := strings.Join(append(append([]string{}, ev.loading[i:]...), path), " → ")
+			return nil, runtimeError(s, "%s", i18n.T(i18n.GenImportCycle, cycle))
+		}
+	}
+	if moduleEnv, ok := ev.modules[path]; ok {
+		return moduleEnv, nil
+	}
+	var content []byte
+	var err error
+	if strings.HasPrefix(s.Module, "std/") {
+		content, _, err = stdlib.Source(s.Module)
+	} else {
+		content, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, runtimeError(s, "%s", i18n.T(i18n.GenModuleReadFailed, path, err))
+	}
+	p := parser.New(lexer.New(string(content)))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		return nil, runtimeError(s, "%s", i18n.T(i18n.GenModuleParseErrors, path, p.Errors()))
+	}
+
+	moduleEnv := NewEnvironment()
+	ev.modules[path] = moduleEnv
+	ev.loading = append(ev.loading, path)
+	defer func() { ev.loading = ev.loading[:len(ev.loading)-1] }()
+	// The imports of the module are relative to it
+	defer func(dir string) { ev.Dir = dir }(ev.Dir)
+	ev.Dir = filepath.Dir(path)
+	for _, stmt := range program.Statements {
+		switch stmt.(type) {
+		case *ast.FunctionDefinition, *ast.EnumDeclaration, *ast.ImportStatement, *ast.TypeDeclaration, *ast.ImplDeclaration:
+			if _, _, err := ev.exec(stmt, moduleEnv); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// The module-level variables are initialized once the functions their
+	// values may call are declared
+	for _, stmt := range program.Statements {
+		if let, ok := stmt.(*ast.LetDeclaration); ok {
+			if _, _, err := ev.exec(let, moduleEnv); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return moduleEnv, nil
+}
@@ -0,0 +1,370 @@
+package evaluator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/linkalls/zeno-lang/lexer"
+	"github.com/linkalls/zeno-lang/parser"
+	"github.com/linkalls/zeno-lang/stdlib"
+)
+
+// evalInput parses and evaluates input with a fresh evaluator, returning
+// the value of the last expression and the printed output
+func evalInput(t *testing.T, input string) (interface{}, string, error) {
+	t.Helper()
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors for input:\n%s\nErrors: %v", input, p.Errors())
+	}
+	var out bytes.Buffer
+	value, err := New(&out).Eval(program)
+	return value, out.String(), err
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"1 + 2 * 3", 7},
+		{"7 / 2", 3},
+		{"7.0 / 2", 3.5},
+		{"7 % 3 * 2 + 10 % 4", 4},
+		{"6 & 3 | 8 ^ 1", 11},
+		{"1 << 4 >> 2", 4},
+		{"let n = 0\nlet sign = if n < 0 {\n    \"negative\"\n} else if n == 0 {\n    let s = \"zero\"\n    s\n} else {\n    \"positive\"\n}\nsign", "zero"},
+		{"fn divmod(a: int, b: int): (int, int) {\n    return (a / b, a % b)\n}\nlet (q, r) = divmod(7, 2)\nq * 10 + r", 31},
+		{"let (_, s) = (1, \"b\")\ns + str((2, 3))", "b(2, 3)"},
+		{"type Person = {\n    name: string\n    age: int\n}\nlet {name, age} = Person{name: \"a\", age: 2}\nlet [first, _, third] = [1, 2, 3]\nname + str(age + first + third)", "a6"},
+		{"-3 + 1", -2},
+		{`"zen" + "o"`, "zeno"},
+		{"1 < 2 && 2 < 1", false},
+		{"!false || 1 / 0 == 0", true},
+		{"1 == 1.0", true},
+		{"[1, 2] == [1, 2]", true},
+		{"let mut x = 4\nx = x + 1\nx", 5},
+		{"let m = {name: \"zeno\", version: 1}\nm.name", "zeno"},
+		{"fn add(a: int, b: int): int {\n    return a + b\n}\nadd(2, 3)", 5},
+		{"fn count(...items: any): int {\n    return len(items)\n}\ncount(1, 2, 3)", 3},
+		{"fn fact(n: int): int {\n    if n <= 1 {\n        return 1\n    }\n    return n * fact(n - 1)\n}\nfact(5)", 120},
+		{"let mut sum = 0\nfor n in [1, 2, 3, 4] {\n    if n == 3 {\n        continue\n    }\n    sum = sum + n\n}\nsum", 7},
+		{"let mut i = 0\nloop {\n    i = i + 1\n    if i == 3 {\n        break\n    }\n}\ni", 3},
+		{"let mut i = 0\nwhile i < 10 {\n    i = i + 2\n}\ni", 10},
+		{"int(\"42\").value", 42},
+		{"float(\"x\").ok", false},
+		{"typeOf([1])", "array"},
+		{"str(1.5)", "1.5"},
+		{"toString(toInt(\"7\").value + 1) + str(toFloat(\"2.5\").value) + str(parseBool(\" true \").value)", "82.5true"},
+		{"parseBool(\"yes\").error", "cannot convert \"yes\" to bool"},
+		{"let mut s = \"\"\nfor c in \"héllo\" {\n    if c > 'e' {\n        s = s + str(c)\n    }\n}\ns + typeOf('x') + str(int('a').value) + str(char(233).value) + str(char(\"ab\").ok)", "héllochar97éfalse"},
+		{"let x = 1", nil},
+		{"let n = 2\nmatch n {\n    1 => \"one\",\n    2 => \"two\",\n    _ => \"many\",\n}", "two"},
+		{"let n = 5\nlet s = match n {\n    1 => \"one\",\n    _ => {\n        let m = n * 2\n        str(m)\n    },\n}\ns", "10"},
+		{"enum Shape {\n    Circle(float),\n    Empty,\n}\nlet s = Circle(2.0)\nmatch s {\n    Empty => 0.0,\n    Circle(r) => r * 2,\n}", 4.0},
+		{"enum Shape {\n    Rect(float, string),\n}\nstr(Rect(1.5, \"a\"))", "Rect(1.5, \"a\")"},
+		{"fn double(s: string): Result<int> {\n    let n = int(s)?\n    return ok(n * 2)\n}\nstr(double(\"4\")) + \" \" + str(double(\"x\"))", "ok(8) err(cannot convert \"x\" to int)"},
+		{"err(\"bad\").error", "bad"},
+		{"fn find(n: int): Option<int> {\n    if n > 0 {\n        return some(n)\n    }\n    return none\n}\nlet s = match find(2) {\n    some(n) => n * 10,\n    none => 0,\n}\ns + unwrapOr(find(0), 1)", 21},
+		{"let items = [1, 2, 3]\nitems[0] + items[len(items) - 1]", 4},
+		{"str([1, 2, 3][1:]) + \"zénon\"[1:3] + {a: \"x\"}[\"a\"]", "[2 3]énx"},
+		{"str(some(\"a\")) + \" \" + str(none) + \" \" + typeOf(none)", "some(a) none Option"},
+		{"let mut words = \" a,b,c \".trim().split(\",\")\nwords.push(\"d\")\nwords.join(\"-\").toUpper() + str(words.length()) + str(\"héllo\".indexOf(\"l\"))", "A-B-C-D42"},
+		{"fn double(n: int): int {\n    return n * 2\n}\nfn big(n: int): bool {\n    return n > 2\n}\nstr([1, 2, 3].map(double).filter(big)) + str([1, 2].contains(2))", "[4 6]true"},
+		{"let mut total = 0\nfor i in range(1, 5) {\n    total = total * 10 + i\n}\nstr(total) + str(range(3)) + str(range(5, 0, -2))", "1234[0 1 2][5 3 1]"},
+		{"let mut s = \"\"\nfor i, v in [\"a\", \"b\"] {\n    s = s + str(i) + v\n}\ns", "0a1b"},
+		{"fn sign(n: int): int {\n    match n > 0 {\n        true => {\n            return 1\n        }\n    }\n    return 0\n}\nsign(3)", 1},
+	}
+	for _, tt := range tests {
+		value, _, err := evalInput(t, tt.input)
+		if err != nil {
+			t.Errorf("input %q: unexpected error: %v", tt.input, err)
+			continue
+		}
+		if value != tt.expected {
+			t.Errorf("input %q: expected %#v, got %#v", tt.input, tt.expected, value)
+		}
+	}
+}
+
+func TestEvalPrint(t *testing.T) {
+	input := `import { print, println } from "std/fmt"
+println("a", 1, 2.5)
+print("b")
+println(true)`
+	_, out, err := evalInput(t, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "a 1 2.5\nbtrue\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+}
+
+func TestEvalSpawn(t *testing.T) {
+	input := `import { println } from "std/fmt"
+fn work(n: int) {
+    println("work", n)
+}
+spawn work(1)
+spawn {
+    work(2)
+}
+wait()
+println("done")`
+	_, out, err := evalInput(t, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "work 1\nwork 2\ndone\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+}
+
+func TestEvalTry(t *testing.T) {
+	input := `import { println } from "std/fmt"
+fn check(n: int): int {
+    if n < 0 {
+        panic("negative")
+    }
+    return n
+}
+try {
+    check(-1)
+} catch e {
+    println("caught", e)
+}
+try {
+    let q = 1 / 0
+} catch e {
+    println(e)
+}
+try {
+    println(check(2))
+} catch {
+    println("never")
+}`
+	_, out, err := evalInput(t, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "caught negative\ninteger divide by zero\n2\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	// Failed assertions are not caught
+	_, _, err = evalInput(t, "try {\n    assertTrue(false)\n} catch {\n}")
+	if err == nil || !strings.Contains(err.Error(), "assertTrue failed") {
+		t.Errorf("expected the assertion to fail, got %v", err)
+	}
+}
+
+func TestEvalTraits(t *testing.T) {
+	input := `import { println } from "std/fmt"
+type Money = {
+    cents: int
+}
+impl Add for Money {
+    fn add(a: Money, b: Money): Money {
+        return Money{cents: a.cents + b.cents}
+    }
+}
+impl Eq for Money {
+    fn eq(a: Money, b: Money): bool {
+        return a.cents == b.cents
+    }
+}
+impl Ord for Money {
+    fn compare(a: Money, b: Money): int {
+        return a.cents - b.cents
+    }
+}
+let price = Money{cents: 250}
+let total = price + Money{cents: 50}
+println(total.cents, total == Money{cents: 300}, total != price)
+println(price < total, price >= total)`
+	_, out, err := evalInput(t, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "300 true true\ntrue false\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+}
+
+func TestEvalModuleVariables(t *testing.T) {
+	dir := t.TempDir()
+	module := "const step = 2\nlet mut count = start()\n\nfn start(): int {\n    return step * 10\n}\n\npub fn next(): int {\n    count = count + step\n    return count\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "counter.zeno"), []byte(module), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p := parser.New(lexer.New("import { next } from \"./counter\"\nnext()\nnext()"))
+	program := p.ParseProgram()
+	ev := New(&bytes.Buffer{})
+	ev.Dir = dir
+	value, err := ev.Eval(program)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value != 24 {
+		t.Errorf("expected 24, got %v", value)
+	}
+}
+
+func TestEvalImportAliases(t *testing.T) {
+	dir := t.TempDir()
+	modules := map[string]string{
+		"circle.zeno": "pub fn area(r: int): int {\n    return 3 * r * r\n}\n",
+		"square.zeno": "pub fn area(side: int): int {\n    return side * side\n}\n",
+		"shapes.zeno": "pub import { area as circleArea } from \"./circle\"\nimport { area } from \"./square\"\n",
+	}
+	for name, content := range modules {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := parser.New(lexer.New("import { area } from \"./circle\"\nimport { area as squareArea } from \"./square\"\nimport * as square from \"./square\"\narea(2) + squareArea(3) + square.area(1)"))
+	program := p.ParseProgram()
+	ev := New(&bytes.Buffer{})
+	ev.Dir = dir
+	value, err := ev.Eval(program)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value != 22 {
+		t.Errorf("expected 22, got %v", value)
+	}
+
+	// Only the functions imported with pub import are re-exported
+	p = parser.New(lexer.New("import { circleArea } from \"./shapes\"\ncircleArea(1)"))
+	if value, err := ev.Eval(p.ParseProgram()); err != nil || value != 3 {
+		t.Errorf("expected 3, got %v, %v", value, err)
+	}
+	p = parser.New(lexer.New("import { area } from \"./shapes\""))
+	if _, err := ev.Eval(p.ParseProgram()); err == nil || !strings.Contains(err.Error(), "Function 'area' is not exported from module './shapes'") {
+		t.Errorf("expected an export error, got %v", err)
+	}
+}
+
+func TestEvalImportCycle(t *testing.T) {
+	dir := t.TempDir()
+	modules := map[string]string{
+		"a.zeno": "import { b } from \"./b\"\npub fn a(): int {\n    return 1\n}\n",
+		"b.zeno": "import { a } from \"./a\"\npub fn b(): int {\n    return a()\n}\n",
+	}
+	for name, content := range modules {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := parser.New(lexer.New("import { a } from \"./a\""))
+	ev := New(&bytes.Buffer{})
+	ev.Dir = dir
+	_, err := ev.Eval(p.ParseProgram())
+	cycle := filepath.Join(dir, "a.zeno") + " → " + filepath.Join(dir, "b.zeno") + " → " + filepath.Join(dir, "a.zeno")
+	if err == nil || !strings.Contains(err.Error(), "Import cycle: "+cycle) {
+		t.Errorf("expected an import cycle error, got %v", err)
+	}
+}
+
+func TestEvalChannels(t *testing.T) {
+	input := `import { println } from "std/fmt"
+let jobs = chan<int>(3)
+spawn {
+    for i in [1, 2, 3] {
+        send(jobs, i)
+    }
+    close(jobs)
+}
+let mut total = 0
+for j in jobs {
+    total = total + j
+}
+println(total, recv(jobs), typeOf(jobs))
+let done = chan<string>()
+loop {
+    when {
+        msg from done => {
+            println(msg)
+            break
+        }
+        _ => send(done, "stop"),
+    }
+}`
+	_, out, err := evalInput(t, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "6 none Channel\nstop\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 / 0", "integer divide by zero"},
+		{"1 % 0", "integer divide by zero"},
+		{"7.5 % 2", "operator % not defined on float and int"},
+		{"1 << -1", "negative shift amount -1"},
+		{"missing", "undefined variable 'missing'"},
+		{"missing = 1", "undefined variable 'missing'"},
+		{"fn f(a: int) {\n}\nf()", "Function 'f' expects 1 argument(s), got 0 in call f()"},
+		{`"a" - 1`, "operator - not defined on string and int"},
+		{"readFile(\"x\")", "add `import { readFile } from \"std/io\"`"},
+		{`import { panic } from "std/fmt"
+panic("boom")`, "panic: boom"},
+		{`import { nothing } from "std/fmt"`, "Function 'nothing' is not exported from module 'std/fmt'"},
+		{"return 1", "'return 1' outside of a function or loop"},
+		{"match 3 {\n    1 => \"one\",\n}", "no match arm for 3"},
+		{"[1, 2][2]", "Index 2 is out of range for length 2"},
+		{"let i = 2\n[1, 2, 3][i:1]", "Slice bounds 2:1 are inverted"},
+		{"unwrapOr(1, 2)", "unwrapOr: int is not an Option"},
+		{"\"zeno\".upper()", "Type string has no method 'upper'"},
+		{"[1].push(2)", "push must be called on a variable"},
+		{"let n = 1\nn = 2", "Cannot change immutable variable 'n'"},
+		{"let items = [1]\nitems.push(2)", "Cannot change immutable variable 'items'"},
+		{"let {a} = 5", "Cannot take apart int with let {...}"},
+		{"let [a, b] = [1]", "Index 1 is out of range for length 1"},
+		{"let (a, b) = (1, 2, 3)", "Tuple of type (int, int, int) has 3 elements, but 2 names are given"},
+		{"range(0, 1, 0)", "The step of range must not be 0"},
+		{"range()", "Function 'range' expects at least 1 argument(s), got 0"},
+		{"int(\"x\")?", "The ? operator can only be used in a function that returns a Result"},
+		{"fn f(): Result<int> {\n    return ok(1?)\n}\nf()", "The ? operator needs a Result, got int"},
+	}
+	for _, tt := range tests {
+		_, _, err := evalInput(t, tt.input)
+		if err == nil {
+			t.Errorf("input %q: expected an error", tt.input)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("input %q: expected error containing %q, got %q", tt.input, tt.expected, err.Error())
+		}
+	}
+}
+
+func TestEvalPersistsGlobals(t *testing.T) {
+	ev := New(&bytes.Buffer{})
+	for _, input := range []string{"let x = 2", "fn double(n: int): int {\n    return n * 2\n}"} {
+		if _, err := ev.Eval(parser.New(lexer.New(input)).ParseProgram()); err != nil {
+			t.Fatalf("input %q: unexpected error: %v", input, err)
+		}
+	}
+	value, err := ev.Eval(parser.New(lexer.New("double(x)")).ParseProgram())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value != 4 {
+		t.Errorf("expected 4, got %#v", value)
+	}
+}
@@ -0,0 +1,90 @@
+package evaluator
+
+import (
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+)
+
+// evalIndex reads an element of an array, a character of a string or a
+// value of a map
+func (ev *Evaluator) evalIndex(e *ast.IndexExpression, env *Environment) (interface{}, error) {
+	left, err := ev.eval(e.Left, env)
+	if err != nil {
+		return nil, err
+	}
+	index, err := ev.eval(e.Index, env)
+	if err != nil {
+		return nil, err
+	}
+	if m, isMap := left.(map[string]interface{}); isMap {
+		key, isString := index.(string)
+		if !isString {
+			return nil, runtimeError(e.Index, "%s", i18n.T(i18n.TypeIndexType, typeOf(left), "string", typeOf(index)))
+		}
+		return m[key], nil
+	}
+	i, isInt := index.(int)
+	if !isInt {
+		return nil, runtimeError(e.Index, "%s", i18n.T(i18n.TypeIndexType, typeOf(left), "int", typeOf(index)))
+	}
+	switch v := left.(type) {
+	case []interface{}:
+		if i < 0 || i >= len(v) {
+			return nil, runtimeError(e, "%s", i18n.T(i18n.TypeIndexOutOfRange, i, len(v)))
+		}
+		return v[i], nil
+	case string:
+		runes := []rune(v)
+		if i < 0 || i >= len(runes) {
+			return nil, runtimeError(e, "%s", i18n.T(i18n.TypeIndexOutOfRange, i, len(runes)))
+		}
+		return string(runes[i]), nil
+	}
+	return nil, runtimeError(e.Left, "%s", i18n.T(i18n.TypeNotIndexable, typeOf(left)))
+}
+
+// evalSlice takes the elements of an array or the characters of a string
+// from low up to high
+func (ev *Evaluator) evalSlice(e *ast.SliceExpression, env *Environment) (interface{}, error) {
+	left, err := ev.eval(e.Left, env)
+	if err != nil {
+		return nil, err
+	}
+	var length int
+	switch v := left.(type) {
+	case []interface{}:
+		length = len(v)
+	case string:
+		length = len([]rune(v))
+	default:
+		return nil, runtimeError(e.Left, "%s", i18n.T(i18n.TypeNotIndexable, typeOf(left)))
+	}
+	low, high := 0, length
+	for _, bound := range []struct {
+		expr  ast.Expression
+		value *int
+	}{{e.Low, &low}, {e.High, &high}} {
+		if bound.expr == nil {
+			continue
+		}
+		value, err := ev.eval(bound.expr, env)
+		if err != nil {
+			return nil, err
+		}
+		i, isInt := value.(int)
+		if !isInt {
+			return nil, runtimeError(bound.expr, "%s", i18n.T(i18n.TypeIndexType, typeOf(left), "int", typeOf(value)))
+		}
+		if i < 0 || i > length {
+			return nil, runtimeError(e, "%s", i18n.T(i18n.TypeIndexOutOfRange, i, length))
+		}
+		*bound.value = i
+	}
+	if low > high {
+		return nil, runtimeError(e, "%s", i18n.T(i18n.TypeInvertedSlice, low, high))
+	}
+	if s, isString := left.(string); isString {
+		return string([]rune(s)[low:high]), nil
+	}
+	return left.([]interface{})[low:high], nil
+}
@@ -0,0 +1,185 @@
+package evaluator
+
+import (
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+)
+
+// method is a builtin method of strings or arrays, mirroring the ones the
+// generator writes for compiled programs. push, map and filter need the
+// evaluator and are called by evalMethodCall.
+type method struct {
+	params int
+	fn     func(ev *Evaluator, e *ast.MethodCallExpression, receiver interface{}, args []interface{}) (interface{}, error)
+}
+
+var stringMethods = map[string]method{
+	"length": {params: 0, fn: func(ev *Evaluator, e *ast.MethodCallExpression, s interface{}, args []interface{}) (interface{}, error) {
+		return len([]rune(s.(string))), nil
+	}},
+	"toUpper":    stringMethod(nil, func(s string, args []string) interface{} { return strings.ToUpper(s) }),
+	"toLower":    stringMethod(nil, func(s string, args []string) interface{} { return strings.ToLower(s) }),
+	"trim":       stringMethod(nil, func(s string, args []string) interface{} { return strings.TrimSpace(s) }),
+	"contains":   stringMethod([]string{"substr"}, func(s string, args []string) interface{} { return strings.Contains(s, args[0]) }),
+	"startsWith": stringMethod([]string{"prefix"}, func(s string, args []string) interface{} { return strings.HasPrefix(s, args[0]) }),
+	"endsWith":   stringMethod([]string{"suffix"}, func(s string, args []string) interface{} { return strings.HasSuffix(s, args[0]) }),
+	"replace":    stringMethod([]string{"old", "new"}, func(s string, args []string) interface{} { return strings.ReplaceAll(s, args[0], args[1]) }),
+	"split": stringMethod([]string{"sep"}, func(s string, args []string) interface{} {
+		parts := strings.Split(s, args[0])
+		result := make([]interface{}, len(parts))
+		for i, part := range parts {
+			result[i] = part
+		}
+		return result
+	}),
+	"indexOf": stringMethod([]string{"substr"}, func(s string, args []string) interface{} {
+		i := strings.Index(s, args[0])
+		if i < 0 {
+			return -1
+		}
+		return len([]rune(s[:i]))
+	}),
+}
+
+// stringMethod adapts a method whose arguments, named params, are all
+// strings
+func stringMethod(params []string, fn func(s string, args []string) interface{}) method {
+	return method{params: len(params), fn: func(ev *Evaluator, e *ast.MethodCallExpression, receiver interface{}, args []interface{}) (interface{}, error) {
+		strs := make([]string, len(args))
+		for i, arg := range args {
+			s, ok := arg.(string)
+			if !ok {
+				return nil, runtimeError(e.Arguments[i], "%s", i18n.T(i18n.GenArgumentType, i+1, e.Method, params[i], "string", typeOf(arg), e.String()))
+			}
+			strs[i] = s
+		}
+		return fn(receiver.(string), strs), nil
+	}}
+}
+
+var arrayMethods = map[string]method{
+	"length": {params: 0, fn: func(ev *Evaluator, e *ast.MethodCallExpression, items interface{}, args []interface{}) (interface{}, error) {
+		return len(items.([]interface{})), nil
+	}},
+	"contains": {params: 1, fn: func(ev *Evaluator, e *ast.MethodCallExpression, items interface{}, args []interface{}) (interface{}, error) {
+		for _, item := range items.([]interface{}) {
+			if equal(item, args[0]) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}},
+	"join": {params: 1, fn: func(ev *Evaluator, e *ast.MethodCallExpression, items interface{}, args []interface{}) (interface{}, error) {
+		sep, ok := args[0].(string)
+		if !ok {
+			return nil, runtimeError(e.Arguments[0], "%s", i18n.T(i18n.GenArgumentType, 1, e.Method, "sep", "string", typeOf(args[0]), e.String()))
+		}
+		parts := make([]string, len(items.([]interface{})))
+		for i, item := range items.([]interface{}) {
+			parts[i] = str(item)
+		}
+		return strings.Join(parts, sep), nil
+	}},
+}
+
+// evalMethodCall calls a method of a string or an array. push appends to
+// the array stored in a variable and has no value.
+func (ev *Evaluator) evalMethodCall(e *ast.MethodCallExpression, env *Environment) (interface{}, error) {
+	receiver, err := ev.eval(e.Object, env)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]interface{}, len(e.Arguments))
+	for i, arg := range e.Arguments {
+		value, err := ev.eval(arg, env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+
+	var m method
+	var ok bool
+	switch receiver.(type) {
+	case *Namespace:
+		return ev.callNamespace(e, receiver.(*Namespace), args)
+	case string:
+		m, ok = stringMethods[e.Method]
+	case []interface{}:
+		switch e.Method {
+		case "push":
+			return nil, ev.push(e, receiver.([]interface{}), args, env)
+		case "map", "filter":
+			return ev.mapOrFilter(e, receiver.([]interface{}), args)
+		}
+		m, ok = arrayMethods[e.Method]
+	}
+	if !ok {
+		return nil, runtimeError(e, "%s", i18n.T(i18n.TypeUnknownMethod, typeOf(receiver), e.Method))
+	}
+	if len(args) != m.params {
+		return nil, runtimeError(e, "%s", i18n.T(i18n.GenArgumentCount, e.Method, m.params, len(args), e.String()))
+	}
+	return m.fn(ev, e, receiver, args)
+}
+
+// callNamespace calls a public function of the module imported as the
+// receiver of e
+func (ev *Evaluator) callNamespace(e *ast.MethodCallExpression, namespace *Namespace, args []interface{}) (interface{}, error) {
+	fn, ok := namespace.env.Export(e.Method)
+	if !ok {
+		return nil, runtimeError(e, "%s", i18n.T(i18n.GenFunctionNotExported, e.Method, namespace.Module))
+	}
+	_, call, _ := e.QualifiedCall()
+	return ev.callFunction(fn, call, args)
+}
+
+// push appends the argument to the array in the variable e is called on
+func (ev *Evaluator) push(e *ast.MethodCallExpression, items []interface{}, args []interface{}, env *Environment) error {
+	if len(args) != 1 {
+		return runtimeError(e, "%s", i18n.T(i18n.GenArgumentCount, e.Method, 1, len(args), e.String()))
+	}
+	variable, ok := e.Object.(*ast.Identifier)
+	if !ok {
+		return runtimeError(e, "%s", i18n.T(i18n.TypePushTarget))
+	}
+	if !env.Mutable(variable.Value) {
+		return runtimeError(e, "%s", i18n.T(i18n.TypeAssignImmutable, variable.Value))
+	}
+	env.Set(variable.Value, append(items, args[0]))
+	return nil
+}
+
+// mapOrFilter calls the function passed to map or filter with each element
+// of items and returns the results, or the elements for which it is true
+func (ev *Evaluator) mapOrFilter(e *ast.MethodCallExpression, items []interface{}, args []interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, runtimeError(e, "%s", i18n.T(i18n.GenArgumentCount, e.Method, 1, len(args), e.String()))
+	}
+	result := []interface{}{}
+	for _, item := range items {
+		value, err := ev.callMethodArgument(e, args[0], item)
+		if err != nil {
+			return nil, err
+		}
+		if e.Method == "map" {
+			result = append(result, value)
+		} else if value == true {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// callMethodArgument calls the function passed to map or filter with an
+// element of the array
+func (ev *Evaluator) callMethodArgument(e *ast.MethodCallExpression, f interface{}, item interface{}) (interface{}, error) {
+	fn, ok := f.(*Function)
+	if !ok {
+		return nil, runtimeError(e.Arguments[0], "%s", i18n.T(i18n.GenArgumentType, 1, e.Method, "f", "function", typeOf(f), e.String()))
+	}
+	call := &ast.FunctionCall{Position: e.Position, Name: fn.Definition.Name}
+	return ev.callFunction(fn, call, []interface{}{item})
+}
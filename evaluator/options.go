@@ -0,0 +1,65 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/linkalls/zeno-lang/ast"
+)
+
+// Option is a value created with some or none
+type Option struct {
+	Some  bool
+	Value interface{}
+}
+
+// String prints an option like generated code does
+func (o *Option) String() string {
+	if o.Some {
+		return fmt.Sprintf("some(%v)", o.Value)
+	}
+	return "none"
+}
+
+// builtinUnwrapOr returns the value of an Option, or fallback for none
+func builtinUnwrapOr(args []interface{}) (interface{}, error) {
+	option, isOption := args[0].(*Option)
+	if !isOption {
+		return nil, &RuntimeError{Message: fmt.Sprintf("unwrapOr: %s is not an Option", typeOf(args[0]))}
+	}
+	if option.Some {
+		return option.Value, nil
+	}
+	return args[1], nil
+}
+
+// matchOption matches subject against some(x) or none. ok reports whether
+// pattern is such a pattern, which a variable or function of the same name
+// prevents; matched whether subject is a value it matches, in which case
+// the value is bound in armEnv.
+func matchOption(pattern ast.Expression, subject interface{}, env, armEnv *Environment) (ok, matched bool) {
+	var bindings []ast.Expression
+	switch p := pattern.(type) {
+	case *ast.Identifier:
+		if _, defined := env.Get(p.Value); defined || p.Value != "none" {
+			return false, false
+		}
+	case *ast.FunctionCall:
+		if _, defined := env.Get(p.Name); defined || p.Name != "some" {
+			return false, false
+		}
+		bindings = p.Arguments
+	default:
+		return false, false
+	}
+
+	option, isOption := subject.(*Option)
+	if !isOption || option.Some != (bindings != nil) {
+		return true, false
+	}
+	if len(bindings) == 1 {
+		if ident, ok := bindings[0].(*ast.Identifier); ok && ident.Value != "_" {
+			armEnv.Define(ident.Value, option.Value)
+		}
+	}
+	return true, true
+}
@@ -0,0 +1,74 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+)
+
+// Result is a value created with ok or err, or returned by a conversion
+type Result struct {
+	Ok    bool
+	Value interface{}
+	Error interface{}
+}
+
+// String prints a result like generated code does
+func (r *Result) String() string {
+	if r.Ok {
+		return fmt.Sprintf("ok(%v)", r.Value)
+	}
+	return fmt.Sprintf("err(%v)", r.Error)
+}
+
+// field returns the ok, value or error field of r
+func (r *Result) field(name string) (interface{}, bool) {
+	switch name {
+	case "ok":
+		return r.Ok, true
+	case "value":
+		return r.Value, true
+	case "error":
+		return r.Error, true
+	}
+	return nil, false
+}
+
+// ok and fail build the Results of conversions. Failed conversions hold
+// the zero value, like in generated code.
+func ok(value interface{}) *Result {
+	return &Result{Ok: true, Value: value}
+}
+
+func fail(value interface{}, message string) *Result {
+	return &Result{Value: value, Error: message}
+}
+
+// errorReturn carries the error Result unwrapped by a ? operator to the
+// function call that returns it
+type errorReturn struct {
+	result *Result
+	node   ast.Node
+}
+
+// Error is reported for a ? operator outside a function
+func (e *errorReturn) Error() string {
+	return runtimeError(e.node, "%s", i18n.T(i18n.TypeTryOutsideResult)).Error()
+}
+
+// evalTry unwraps the Result of a ? operator
+func (ev *Evaluator) evalTry(e *ast.TryExpression, env *Environment) (interface{}, error) {
+	value, err := ev.eval(e.Value, env)
+	if err != nil {
+		return nil, err
+	}
+	result, isResult := value.(*Result)
+	if !isResult {
+		return nil, runtimeError(e, "%s", i18n.T(i18n.TypeTryOperand, typeOf(value)))
+	}
+	if !result.Ok {
+		return nil, &errorReturn{result: &Result{Error: result.Error}, node: e}
+	}
+	return result.Value, nil
+}
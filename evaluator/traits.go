@@ -0,0 +1,98 @@
+package evaluator
+
+import "github.com/linkalls/zeno-lang/ast"
+
+// impl is a trait implemented for a struct type. Struct values are maps
+// that do not keep the name of their type, so the values of the type are
+// known by their fields.
+type impl struct {
+	fields []string
+	method *Function
+}
+
+// declareImpl records the function an impl implements its trait with
+func (ev *Evaluator) declareImpl(s *ast.ImplDeclaration, env *Environment) error {
+	decl, ok := ev.structs[s.TypeName]
+	if !ok {
+		return runtimeError(s, "impl: %s is not a struct type", s.TypeName)
+	}
+	trait, ok := ast.Traits[s.Trait]
+	if !ok {
+		return runtimeError(s, "impl: unknown trait %s", s.Trait)
+	}
+	method, ok := s.Method(trait.Method)
+	if !ok {
+		return runtimeError(s, "impl: %s for %s does not declare %s", s.Trait, s.TypeName, trait.Method)
+	}
+	fields := make([]string, len(decl.Fields))
+	for i, field := range decl.Fields {
+		fields[i] = field.Name
+	}
+	ev.impls[s.Trait] = append(ev.impls[s.Trait], impl{fields: fields, method: &Function{Definition: method, env: env}})
+	return nil
+}
+
+// operatorMethod returns the function that gives op to the operands, when
+// they are struct values of a type implementing the trait of op
+func (ev *Evaluator) operatorMethod(op ast.BinaryOperator, left, right interface{}) (*Function, bool) {
+	l, ok := left.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	r, ok := right.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	for _, candidate := range ev.impls[ast.OperatorTrait(op)] {
+		if hasFields(l, candidate.fields) && hasFields(r, candidate.fields) {
+			return candidate.method, true
+		}
+	}
+	return nil, false
+}
+
+// hasFields reports whether the keys of value are exactly fields
+func hasFields(value map[string]interface{}, fields []string) bool {
+	if len(value) != len(fields) {
+		return false
+	}
+	for _, field := range fields {
+		if _, ok := value[field]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// callOperator applies an operator by calling the function of its trait:
+// != negates eq, and the comparisons compare the result of compare to 0
+func (ev *Evaluator) callOperator(e *ast.BinaryExpression, method *Function, left, right interface{}) (interface{}, error) {
+	call := &ast.FunctionCall{Position: e.Position, Name: method.Definition.Name, Arguments: []ast.Expression{e.Left, e.Right}}
+	result, err := ev.callFunction(method, call, []interface{}{left, right})
+	if err != nil {
+		return nil, err
+	}
+	switch e.Operator {
+	case ast.BinaryOpPlus, ast.BinaryOpEq:
+		return result, nil
+	case ast.BinaryOpNotEq:
+		eq, ok := result.(bool)
+		if !ok {
+			return nil, runtimeError(e, "eq returned %s, not a bool", typeOf(result))
+		}
+		return !eq, nil
+	}
+	order, ok := result.(int)
+	if !ok {
+		return nil, runtimeError(e, "compare returned %s, not an int", typeOf(result))
+	}
+	switch e.Operator {
+	case ast.BinaryOpLt:
+		return order < 0, nil
+	case ast.BinaryOpLte:
+		return order <= 0, nil
+	case ast.BinaryOpGt:
+		return order > 0, nil
+	}
+	return order >= 0, nil
+}
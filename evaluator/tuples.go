@@ -0,0 +1,27 @@
+package evaluator
+
+import "strings"
+
+// Tuple is the value of a tuple literal, which a function returns and a
+// let (a, b) = value takes apart
+type Tuple struct {
+	Elements []interface{}
+}
+
+// String prints a tuple as it is written, (1, "a") printing as (1, a)
+func (t *Tuple) String() string {
+	elements := make([]string, len(t.Elements))
+	for i, element := range t.Elements {
+		elements[i] = str(element)
+	}
+	return "(" + strings.Join(elements, ", ") + ")"
+}
+
+// typeName returns the type of the tuple written like in Zeno, (int, string)
+func (t *Tuple) typeName() string {
+	elements := make([]string, len(t.Elements))
+	for i, element := range t.Elements {
+		elements[i] = typeOf(element)
+	}
+	return "(" + strings.Join(elements, ", ") + ")"
+}
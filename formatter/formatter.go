@@ -0,0 +1,706 @@
+// Package formatter prints Zeno programs in their canonical layout: four
+// space indentation, one space around binary operators and after commas,
+// sorted imports and at most one blank line between statements. Comments
+// are kept where they were relative to the surrounding statements.
+package formatter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/lexer"
+	"github.com/linkalls/zeno-lang/parser"
+)
+
+// indentation is the indentation of one block level
+const indentation = "    "
+
+// Source formats Zeno source code. It fails if src does not parse.
+func Source(src []byte) ([]byte, error) {
+	p := parser.New(lexer.NewWithComments(string(src)))
+	program := p.ParseProgram()
+	if errs := p.DetailedErrors(); len(errs) > 0 {
+		return nil, fmt.Errorf("%d:%d: %s", errs[0].Line, errs[0].Column, errs[0].Message)
+	}
+	if errs := p.Errors(); len(errs) > 0 {
+		return nil, errors.New(errs[0])
+	}
+	return []byte(Program(program, src)), nil
+}
+
+// Program prints program in canonical layout, including program.Comments.
+// src is the source the program was parsed from and is used to keep the
+// blank lines that separate statements; it may be nil.
+func Program(program *ast.Program, src []byte) string {
+	f := &formatter{comments: program.Comments}
+	if src != nil {
+		f.lines = strings.Split(string(src), "\n")
+	}
+	statements := sortImports(program.Statements)
+	f.statements(statements, true)
+	// comments at the end of the file
+	f.leadingComments(endOfFile, len(statements) > 0)
+	return f.align()
+}
+
+// align lines up the trailing comments of consecutive lines, which are
+// separated from the code by a tab
+func (f *formatter) align() string {
+	var out strings.Builder
+	w := tabwriter.NewWriter(&out, 0, 0, 1, ' ', tabwriter.StripEscape)
+	w.Write([]byte(f.builder.String()))
+	w.Flush()
+	return out.String()
+}
+
+// endOfFile is a position after every comment
+var endOfFile = ast.Position{Line: math.MaxInt}
+
+// sortImports sorts the imports at the top of a file by module, standard
+// library modules first
+func sortImports(statements []ast.Statement) []ast.Statement {
+	n := 0
+	for n < len(statements) {
+		if _, ok := statements[n].(*ast.ImportStatement); !ok {
+			break
+		}
+		n++
+	}
+	sorted := append([]ast.Statement{}, statements...)
+	sort.SliceStable(sorted[:n], func(i, j int) bool {
+		a, b := sorted[i].(*ast.ImportStatement).Module, sorted[j].(*ast.ImportStatement).Module
+		aStd, bStd := strings.HasPrefix(a, "std/"), strings.HasPrefix(b, "std/")
+		if aStd != bStd {
+			return aStd
+		}
+		return a < b
+	})
+	return sorted
+}
+
+type formatter struct {
+	builder  strings.Builder
+	lines    []string       // source lines, for blank line detection
+	depth    int            // current block nesting
+	comments []*ast.Comment // comments not printed yet, in source order
+}
+
+// write prints s. Tabs in s are escaped so that align leaves them alone.
+func (f *formatter) write(s string) {
+	if strings.Contains(s, "\t") {
+		s = string(tabwriter.Escape) + s + string(tabwriter.Escape)
+	}
+	f.builder.WriteString(s)
+}
+
+func (f *formatter) indent() { f.write(strings.Repeat(indentation, f.depth)) }
+
+// statements prints a statement list, one statement per line, each
+// preceded by its leading comments
+func (f *formatter) statements(statements []ast.Statement, topLevel bool) {
+	for i, stmt := range statements {
+		start := stmt.Pos()
+		if f.commentBefore(start) {
+			start = f.comments[0].Pos()
+		}
+		if i > 0 && f.blankLineBetween(statements[i-1], stmt, start.Line, topLevel) {
+			f.write("\n")
+		}
+		if f.leadingComments(stmt.Pos(), false) && f.blankAbove(stmt.Pos().Line) {
+			f.write("\n")
+		}
+		f.indent()
+		f.statement(stmt)
+		f.trailingComments(endLine(stmt))
+		f.write("\n")
+	}
+}
+
+// endLine returns the source line on which stmt ends
+func endLine(stmt ast.Statement) int {
+	switch s := stmt.(type) {
+	case *ast.FunctionDefinition:
+		return s.Rbrace.Line
+	case *ast.TypeDeclaration:
+		return s.Rbrace.Line
+	case *ast.EnumDeclaration:
+		return s.Rbrace.Line
+	case *ast.ImplDeclaration:
+		return s.Rbrace.Line
+	case *ast.IfStatement:
+		if s.ElseBlock != nil {
+			return s.ElseBlock.Rbrace.Line
+		}
+		if n := len(s.ElseIfClauses); n > 0 {
+			return s.ElseIfClauses[n-1].Block.Rbrace.Line
+		}
+		return s.ThenBlock.Rbrace.Line
+	case *ast.WhileStatement:
+		return s.Block.Rbrace.Line
+	case *ast.LoopStatement:
+		return s.Body.Rbrace.Line
+	case *ast.SpawnStatement:
+		if s.Body == nil {
+			return expressionEndLine(s.Call, s.Pos().Line)
+		}
+		return s.Body.Rbrace.Line
+	case *ast.WhenStatement:
+		return s.Rbrace.Line
+	case *ast.TryStatement:
+		return s.Catch.Rbrace.Line
+	case *ast.ForStatement:
+		return s.Body.Rbrace.Line
+	case *ast.LetDeclaration:
+		return expressionEndLine(s.ValueExpression, s.Pos().Line)
+	case *ast.AssignmentStatement:
+		return expressionEndLine(s.Value, s.Pos().Line)
+	case *ast.ExpressionStatement:
+		return expressionEndLine(s.Expression, s.Pos().Line)
+	case *ast.ReturnStatement:
+		return expressionEndLine(s.Value, s.Pos().Line)
+	}
+	return stmt.Pos().Line
+}
+
+// expressionEndLine returns the source line on which a statement ending with
+// expr ends: the closing brace of a match or of the else block of an if,
+// line otherwise
+func expressionEndLine(expr ast.Expression, line int) int {
+	switch e := expr.(type) {
+	case *ast.MatchExpression:
+		return e.Rbrace.Line
+	case *ast.IfExpression:
+		return e.ElseBlock.Rbrace.Line
+	}
+	return line
+}
+
+// commentBefore reports whether a comment not printed yet precedes pos
+func (f *formatter) commentBefore(pos ast.Position) bool {
+	if len(f.comments) == 0 {
+		return false
+	}
+	c := f.comments[0].Pos()
+	return c.Line < pos.Line || c.Line == pos.Line && c.Column < pos.Column
+}
+
+// leadingComments prints the comments before pos on lines of their own and
+// reports whether there were any. Blank lines between the comments are kept,
+// and above the first one only if separate is set.
+func (f *formatter) leadingComments(pos ast.Position, separate bool) bool {
+	printed := false
+	for f.commentBefore(pos) {
+		c := f.comments[0]
+		f.comments = f.comments[1:]
+		if (printed || separate) && f.blankAbove(c.Line) {
+			f.write("\n")
+		}
+		f.indent()
+		f.write(c.Text + "\n")
+		printed = true
+	}
+	return printed
+}
+
+// trailingComments prints the comments on source line line at the end of the
+// current output line and reports whether there were any
+func (f *formatter) trailingComments(line int) bool {
+	printed := false
+	for len(f.comments) > 0 && f.comments[0].Line == line {
+		if printed {
+			f.write(" ")
+		} else {
+			f.builder.WriteByte('\t')
+		}
+		f.write(f.comments[0].Text)
+		f.comments = f.comments[1:]
+		printed = true
+	}
+	return printed
+}
+
+// blankAbove reports whether the source line above line is blank
+func (f *formatter) blankAbove(line int) bool {
+	index := line - 2 // index of the source line above line
+	return index >= 0 && index < len(f.lines) && strings.TrimSpace(f.lines[index]) == ""
+}
+
+// blankLineBetween reports whether a blank line separates two consecutive
+// statements, the second starting (with its leading comments) on line
+// start. Top-level declarations and the import block are always set apart;
+// elsewhere blank lines from the source are kept.
+func (f *formatter) blankLineBetween(prev, next ast.Statement, start int, topLevel bool) bool {
+	if topLevel {
+		if isDeclaration(prev) || isDeclaration(next) {
+			return true
+		}
+		_, prevImport := prev.(*ast.ImportStatement)
+		_, nextImport := next.(*ast.ImportStatement)
+		if prevImport != nextImport {
+			return true
+		}
+	}
+	return f.blankAbove(start)
+}
+
+func isDeclaration(stmt ast.Statement) bool {
+	switch stmt.(type) {
+	case *ast.FunctionDefinition, *ast.TypeDeclaration, *ast.EnumDeclaration, *ast.ImplDeclaration:
+		return true
+	}
+	return false
+}
+
+// block prints a brace-delimited block, starting at the opening brace.
+// lbrace and rbrace are the source positions of the braces.
+func (f *formatter) block(statements []ast.Statement, lbrace, rbrace ast.Position) {
+	f.write("{")
+	trailing := f.trailingComments(lbrace.Line)
+	if len(statements) == 0 && !trailing && !f.commentBefore(rbrace) {
+		f.write("}")
+		return
+	}
+	f.write("\n")
+	f.depth++
+	f.statements(statements, false)
+	f.leadingComments(rbrace, len(statements) > 0)
+	f.depth--
+	f.indent()
+	f.write("}")
+}
+
+// ifChain prints an if statement or expression with its else if clauses
+// and optional else block
+func (f *formatter) ifChain(condition ast.Expression, then *ast.Block, clauses []ast.ElseIfClause, elseBlock *ast.Block) {
+	f.write("if ")
+	f.expression(condition, parser.LOWEST)
+	f.write(" ")
+	f.block(then.Statements, then.Position, then.Rbrace)
+	for _, clause := range clauses {
+		f.write(" else if ")
+		f.expression(clause.Condition, parser.LOWEST)
+		f.write(" ")
+		f.block(clause.Block.Statements, clause.Block.Position, clause.Block.Rbrace)
+	}
+	if elseBlock != nil {
+		f.write(" else ")
+		f.block(elseBlock.Statements, elseBlock.Position, elseBlock.Rbrace)
+	}
+}
+
+func (f *formatter) statement(stmt ast.Statement) {
+	switch s := stmt.(type) {
+	case *ast.ImportStatement:
+		names := make([]string, len(s.Imports))
+		for i, item := range s.Imports {
+			names[i] = item.String()
+		}
+		if s.IsPublic {
+			f.write("pub ")
+		}
+		if s.Namespace != "" {
+			f.write(fmt.Sprintf("import * as %s from %q", s.Namespace, s.Module))
+		} else if len(names) == 0 {
+			f.write(fmt.Sprintf("import {} from %q", s.Module))
+		} else {
+			f.write(fmt.Sprintf("import { %s } from %q", strings.Join(names, ", "), s.Module))
+		}
+	case *ast.LetDeclaration:
+		if s.Constant {
+			f.write("const ")
+		} else if s.Mutable {
+			f.write("let mut ")
+		} else {
+			f.write("let ")
+		}
+		if s.Names != nil {
+			f.write(s.Destructure.Enclose(s.Names))
+		} else {
+			f.write(s.Name)
+		}
+		if s.TypeAnn != nil {
+			f.write(": " + *s.TypeAnn)
+		}
+		f.write(" = ")
+		f.expression(s.ValueExpression, parser.LOWEST)
+	case *ast.AssignmentStatement:
+		f.write(s.Name + " = ")
+		f.expression(s.Value, parser.LOWEST)
+	case *ast.ExpressionStatement:
+		f.expression(s.Expression, parser.LOWEST)
+	case *ast.ReturnStatement:
+		f.write("return")
+		if s.Value != nil {
+			f.write(" ")
+			f.expression(s.Value, parser.LOWEST)
+		}
+	case *ast.IfStatement:
+		f.ifChain(s.Condition, s.ThenBlock, s.ElseIfClauses, s.ElseBlock)
+	case *ast.WhileStatement:
+		f.write("while ")
+		f.expression(s.Condition, parser.LOWEST)
+		f.write(" ")
+		f.block(s.Block.Statements, s.Block.Position, s.Block.Rbrace)
+	case *ast.LoopStatement:
+		f.write("loop ")
+		f.block(s.Body.Statements, s.Body.Position, s.Body.Rbrace)
+	case *ast.SpawnStatement:
+		f.write("spawn ")
+		if s.Body == nil {
+			f.expression(s.Call, parser.LOWEST)
+			break
+		}
+		f.block(s.Body.Statements, s.Body.Position, s.Body.Rbrace)
+	case *ast.WhenStatement:
+		f.when(s)
+	case *ast.TryStatement:
+		f.write("try ")
+		f.block(s.Body.Statements, s.Body.Position, s.Body.Rbrace)
+		f.write(" catch ")
+		if s.CatchName != "" {
+			f.write(s.CatchName + " ")
+		}
+		f.block(s.Catch.Statements, s.Catch.Position, s.Catch.Rbrace)
+	case *ast.ForStatement:
+		f.write("for ")
+		if s.IndexName != "" {
+			f.write(s.IndexName + ", ")
+		}
+		f.write(s.VarName + " in ")
+		f.expression(s.Iterable, parser.LOWEST)
+		f.write(" ")
+		f.block(s.Body.Statements, s.Body.Position, s.Body.Rbrace)
+	case *ast.BreakStatement:
+		f.write("break")
+	case *ast.ContinueStatement:
+		f.write("continue")
+	case *ast.FunctionDefinition:
+		f.deprecation(s.Deprecated)
+		if s.IsPublic {
+			f.write("pub ")
+		}
+		f.write("fn " + s.Name + generics(s.Generics) + "(")
+		for i, param := range s.Parameters {
+			if i > 0 {
+				f.write(", ")
+			}
+			if param.Variadic {
+				f.write("...")
+			}
+			f.write(param.Name)
+			if param.Type != "" {
+				f.write(": " + param.Type)
+			}
+		}
+		f.write(")")
+		if s.ReturnType != nil {
+			f.write(": " + *s.ReturnType)
+		}
+		f.write(" ")
+		f.block(s.Body, s.Lbrace, s.Rbrace)
+	case *ast.TypeDeclaration:
+		f.deprecation(s.Deprecated)
+		if s.IsPublic {
+			f.write("pub ")
+		}
+		f.write("type " + s.Name + generics(s.Generics) + " = {")
+		if len(s.Fields) == 0 && !f.commentBefore(s.Rbrace) {
+			f.write("}")
+			return
+		}
+		f.write("\n")
+		f.depth++
+		for i, field := range s.Fields {
+			if f.leadingComments(field.Pos(), i > 0) && f.blankAbove(field.Line) {
+				f.write("\n")
+			}
+			f.indent()
+			f.write(field.Name + ": " + field.TypeAnn)
+			f.trailingComments(field.Line)
+			f.write("\n")
+		}
+		f.leadingComments(s.Rbrace, len(s.Fields) > 0)
+		f.depth--
+		f.indent()
+		f.write("}")
+	case *ast.EnumDeclaration:
+		f.deprecation(s.Deprecated)
+		f.write("enum " + s.Name + " {")
+		if len(s.Variants) == 0 && !f.commentBefore(s.Rbrace) {
+			f.write("}")
+			return
+		}
+		f.write("\n")
+		f.depth++
+		for i, variant := range s.Variants {
+			if f.leadingComments(variant.Pos(), i > 0) && f.blankAbove(variant.Line) {
+				f.write("\n")
+			}
+			f.indent()
+			f.write(variant.String() + ",")
+			f.trailingComments(variant.Line)
+			f.write("\n")
+		}
+		f.leadingComments(s.Rbrace, len(s.Variants) > 0)
+		f.depth--
+		f.indent()
+		f.write("}")
+	case *ast.ImplDeclaration:
+		f.write("impl " + s.Trait + " for " + s.TypeName + " {")
+		if len(s.Methods) == 0 && !f.commentBefore(s.Rbrace) {
+			f.write("}")
+			return
+		}
+		f.write("\n")
+		f.depth++
+		methods := make([]ast.Statement, len(s.Methods))
+		for i, method := range s.Methods {
+			methods[i] = method
+		}
+		// The functions are set apart like top-level ones
+		f.statements(methods, true)
+		f.leadingComments(s.Rbrace, len(s.Methods) > 0)
+		f.depth--
+		f.indent()
+		f.write("}")
+	default:
+		f.write(stmt.String())
+	}
+}
+
+// deprecation prints a @deprecated attribute on its own line
+func (f *formatter) deprecation(d *ast.Deprecation) {
+	if d == nil {
+		return
+	}
+	f.write("@deprecated")
+	if d.Message != "" {
+		f.write("(\"" + d.Message + "\")")
+	}
+	f.write("\n")
+	f.indent()
+}
+
+func generics(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	return "<" + strings.Join(names, ", ") + ">"
+}
+
+// binaryPrecedence mirrors the parser's precedence table
+var binaryPrecedence = map[ast.BinaryOperator]int{
+	ast.BinaryOpOr:         parser.LOGICAL_OR,
+	ast.BinaryOpAnd:        parser.LOGICAL_AND,
+	ast.BinaryOpEq:         parser.EQUALS,
+	ast.BinaryOpNotEq:      parser.EQUALS,
+	ast.BinaryOpLt:         parser.COMPARISON,
+	ast.BinaryOpLte:        parser.COMPARISON,
+	ast.BinaryOpGt:         parser.COMPARISON,
+	ast.BinaryOpGte:        parser.COMPARISON,
+	ast.BinaryOpPlus:       parser.SUM,
+	ast.BinaryOpMinus:      parser.SUM,
+	ast.BinaryOpMultiply:   parser.PRODUCT,
+	ast.BinaryOpDivide:     parser.PRODUCT,
+	ast.BinaryOpModulo:     parser.PRODUCT,
+	ast.BinaryOpBitOr:      parser.SUM,
+	ast.BinaryOpBitXor:     parser.SUM,
+	ast.BinaryOpBitAnd:     parser.PRODUCT,
+	ast.BinaryOpShiftLeft:  parser.PRODUCT,
+	ast.BinaryOpShiftRight: parser.PRODUCT,
+}
+
+// expression prints expr. Operands binding weaker than minPrecedence are
+// parenthesized.
+func (f *formatter) expression(expr ast.Expression, minPrecedence int) {
+	switch e := expr.(type) {
+	case *ast.BinaryExpression:
+		prec := binaryPrecedence[e.Operator]
+		if prec < minPrecedence {
+			f.write("(")
+			defer f.write(")")
+		}
+		// Operators are left-associative, so a right operand of the same
+		// precedence needs parentheses
+		f.expression(e.Left, prec)
+		f.write(" " + e.Operator.String() + " ")
+		f.expression(e.Right, prec+1)
+	case *ast.UnaryExpression:
+		f.write(e.Operator.String())
+		f.expression(e.Right, parser.PREFIX)
+	case *ast.FunctionCall:
+		f.write(e.Name)
+		if len(e.TypeArguments) > 0 {
+			f.write("<" + strings.Join(e.TypeArguments, ", ") + ">")
+		}
+		f.write("(")
+		f.expressionList(e.Arguments)
+		f.write(")")
+	case *ast.MemberExpression:
+		f.expression(e.Object, parser.CALL)
+		f.write("." + e.Property)
+	case *ast.MethodCallExpression:
+		f.expression(e.Object, parser.CALL)
+		f.write("." + e.Method + "(")
+		f.expressionList(e.Arguments)
+		f.write(")")
+	case *ast.TryExpression:
+		f.expression(e.Value, parser.CALL)
+		f.write("?")
+	case *ast.IndexExpression:
+		f.expression(e.Left, parser.CALL)
+		f.write("[")
+		f.expression(e.Index, parser.LOWEST)
+		f.write("]")
+	case *ast.SliceExpression:
+		f.expression(e.Left, parser.CALL)
+		f.write("[")
+		if e.Low != nil {
+			f.expression(e.Low, parser.LOWEST)
+		}
+		f.write(":")
+		if e.High != nil {
+			f.expression(e.High, parser.LOWEST)
+		}
+		f.write("]")
+	case *ast.ArrayLiteral:
+		f.write("[")
+		f.expressionList(e.Elements)
+		f.write("]")
+	case *ast.TupleLiteral:
+		f.write("(")
+		f.expressionList(e.Elements)
+		f.write(")")
+	case *ast.MapLiteral:
+		keys := e.OrderedKeys()
+		f.fields(e, len(keys), func(i int) {
+			f.expression(keys[i], parser.LOWEST)
+			f.write(": ")
+			f.expression(e.Pairs[keys[i]], parser.LOWEST)
+		}, func(i int) ast.Node { return keys[i] })
+	case *ast.StructLiteral:
+		names := e.OrderedFieldNames()
+		f.write(e.TypeName)
+		f.fields(e, len(names), func(i int) {
+			f.write(names[i] + ": ")
+			f.expression(e.Fields[names[i]], parser.LOWEST)
+		}, func(i int) ast.Node { return e.Fields[names[i]] })
+	case *ast.MatchExpression:
+		f.write("match ")
+		f.expression(e.Subject, parser.LOWEST)
+		f.write(" {\n")
+		f.depth++
+		for _, arm := range e.Arms {
+			f.leadingComments(arm.Pos(), false)
+			f.indent()
+			if arm.IsWildcard() {
+				f.write("_")
+			} else {
+				f.expression(arm.Pattern, parser.LOWEST)
+			}
+			f.write(" => ")
+			if arm.Block != nil {
+				f.block(arm.Block.Statements, arm.Block.Position, arm.Block.Rbrace)
+				f.trailingComments(arm.Block.Rbrace.Line)
+			} else {
+				f.expression(arm.Value, parser.LOWEST)
+				f.write(",")
+				f.trailingComments(arm.Value.Pos().Line)
+			}
+			f.write("\n")
+		}
+		f.leadingComments(e.Rbrace, len(e.Arms) > 0)
+		f.depth--
+		f.indent()
+		f.write("}")
+	case *ast.IfExpression:
+		f.ifChain(e.Condition, e.ThenBlock, e.ElseIfClauses, e.ElseBlock)
+	case nil:
+	default:
+		f.write(expr.String())
+	}
+}
+
+// when prints a when statement, its arms laid out like those of a match
+func (f *formatter) when(s *ast.WhenStatement) {
+	f.write("when {\n")
+	f.depth++
+	for i := range s.Arms {
+		arm := &s.Arms[i]
+		f.leadingComments(arm.Pos(), false)
+		f.indent()
+		switch {
+		case arm.IsDefault():
+			f.write("_")
+		case arm.Sent != nil:
+			f.write("send(")
+			f.expressionList([]ast.Expression{arm.Channel, arm.Sent})
+			f.write(")")
+		default:
+			if arm.Name != "" {
+				f.write(arm.Name + " from ")
+			}
+			f.expression(arm.Channel, parser.LOWEST)
+		}
+		f.write(" => ")
+		if arm.Block != nil {
+			f.block(arm.Block.Statements, arm.Block.Position, arm.Block.Rbrace)
+			f.trailingComments(arm.Block.Rbrace.Line)
+		} else {
+			f.expression(arm.Value, parser.LOWEST)
+			f.write(",")
+			f.trailingComments(arm.Value.Pos().Line)
+		}
+		f.write("\n")
+	}
+	f.leadingComments(s.Rbrace, len(s.Arms) > 0)
+	f.depth--
+	f.indent()
+	f.write("}")
+}
+
+func (f *formatter) expressionList(exprs []ast.Expression) {
+	for i, expr := range exprs {
+		if i > 0 {
+			f.write(", ")
+		}
+		f.expression(expr, parser.LOWEST)
+	}
+}
+
+// fields prints the braces and n entries of a map or struct literal. The
+// literal stays on one line unless its first entry was on a later line than
+// the literal itself in the source, in which case every entry gets a line of
+// its own.
+func (f *formatter) fields(literal ast.Node, n int, entry func(int), node func(int) ast.Node) {
+	if n == 0 {
+		f.write("{}")
+		return
+	}
+	if node(0).Pos().Line <= literal.Pos().Line {
+		f.write("{")
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				f.write(", ")
+			}
+			entry(i)
+		}
+		f.write("}")
+		return
+	}
+	f.write("{\n")
+	f.depth++
+	for i := 0; i < n; i++ {
+		f.indent()
+		entry(i)
+		f.write(",\n")
+	}
+	f.depth--
+	f.indent()
+	f.write("}")
+}
@@ -0,0 +1,316 @@
+package formatter
+
+import (
+	"testing"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			`import {println} from "std/fmt"
+fn main(){
+  let x=1+2*3
+  if x>3 {println("big",x)} else {println("small")}
+}`,
+			`import { println } from "std/fmt"
+
+fn main() {
+    let x = 1 + 2 * 3
+    if x > 3 {
+        println("big", x)
+    } else {
+        println("small")
+    }
+}
+`,
+		},
+		{
+			`import { helper, helper as assist } from "./utils"
+import {type Result, ok} from "std/result"
+import   *  as  io from "std/io"
+pub   import {helper as aid} from "./utils"
+import { println } from "std/fmt"
+pub fn First<T>(...items: T): T {
+  return items
+}
+@deprecated("use First")
+fn first(a: int): int { return a }`,
+			`import { println } from "std/fmt"
+import * as io from "std/io"
+import { type Result, ok } from "std/result"
+import { helper, helper as assist } from "./utils"
+pub import { helper as aid } from "./utils"
+
+pub fn First<T>(...items: T): T {
+    return items
+}
+
+@deprecated("use First")
+fn first(a: int): int {
+    return a
+}
+`,
+		},
+		{
+			`fn parse(s:string):Result<int>{
+  let n=int(s)?*2
+  return ok(n)
+}`,
+			`fn parse(s: string): Result<int> {
+    let n = int(s)? * 2
+    return ok(n)
+}
+`,
+		},
+		{
+			"let sign=if n<0 { -1 } else if n==0 {0} else {1}\nprintln(sign)",
+			"let sign = if n < 0 {\n    -1\n} else if n == 0 {\n    0\n} else {\n    1\n}\nprintln(sign)\n",
+		},
+		{
+			"fn pair():(int,string){return (1,\"a\")}\nlet mut (a,_)=pair()\nlet x=(1+2)*3",
+			"fn pair(): (int, string) {\n    return (1, \"a\")\n}\n\nlet mut (a, _) = pair()\nlet x = (1 + 2) * 3\n",
+		},
+		{
+			"let {name,age}=person\nlet [first,_]=items",
+			"let {name, age} = person\nlet [first, _] = items\n",
+		},
+		{
+			"let m=a|b&c<<2^d%4",
+			"let m = a | b & c << 2 ^ d % 4\n",
+		},
+		{
+			"let items=[1,2,3]\nlet x=items[ len(items)-1 ]+items[1 :][0]\nlet y=items[ :2]",
+			"let items = [1, 2, 3]\nlet x = items[len(items) - 1] + items[1:][0]\nlet y = items[:2]\n",
+		},
+		{
+			"let  mut words=\"a,b\".split( \",\" )\nwords.push(\"c\")\nlet n=words[0].toUpper( ).length()+1",
+			"let mut words = \"a,b\".split(\",\")\nwords.push(\"c\")\nlet n = words[0].toUpper().length() + 1\n",
+		},
+		{
+			`fn main() {
+    let mut a = 1
+
+
+    let b = 2.0
+    while a < 3 { a = a + 1 }
+    loop { break }
+    for x in [1, 2] { continue }
+    for i,x in [1, 2] { continue }
+}`,
+			`fn main() {
+    let mut a = 1
+
+    let b = 2.0
+    while a < 3 {
+        a = a + 1
+    }
+    loop {
+        break
+    }
+    for x in [1, 2] {
+        continue
+    }
+    for i, x in [1, 2] {
+        continue
+    }
+}
+`,
+		},
+		{
+			`type Point = { x: int, y: int }
+fn main() {
+    let p = Point{x: 1, y: 2}
+    let m = {
+        "b": !true,
+        a: -p.x
+    }
+    let s = "tab\té"
+}`,
+			`type Point = {
+    x: int
+    y: int
+}
+
+fn main() {
+    let p = Point{x: 1, y: 2}
+    let m = {
+        "b": !true,
+        a: -p.x,
+    }
+    let s = "tab\té"
+}
+`,
+		},
+		{
+			`// Package greeting
+/* prints
+   a greeting */
+
+import { println } from "std/fmt" // output
+type Pair = {
+  left: int   // first
+  // second
+  right: int
+}
+// main is the entry point
+fn main() { // starts here
+  // say hello
+
+
+  println("hi") // inline
+  if true {
+    // nothing yet
+  }
+  let url = "http://example.com"
+  // trailing
+}
+// end`,
+			`// Package greeting
+/* prints
+   a greeting */
+
+import { println } from "std/fmt" // output
+
+type Pair = {
+    left: int // first
+    // second
+    right: int
+}
+
+// main is the entry point
+fn main() { // starts here
+    // say hello
+
+    println("hi") // inline
+    if true {
+        // nothing yet
+    }
+    let url = "http://example.com"
+    // trailing
+}
+// end
+`,
+		},
+		{
+			`let name = match n {
+  1=>"one",
+  // the rest
+  _ => { let s = str(n)
+  s
+  } // fallback
+}
+match n { 0 => println("zero"), _ => println(n) }
+enum Shape { Circle(float), // round
+  Rect(float,float)
+  Empty }`,
+			`let name = match n {
+    1 => "one",
+    // the rest
+    _ => {
+        let s = str(n)
+        s
+    } // fallback
+}
+match n {
+    0 => println("zero"),
+    _ => println(n),
+}
+
+enum Shape {
+    Circle(float), // round
+    Rect(float, float),
+    Empty,
+}
+`,
+		},
+		{
+			`for i in items { spawn work(i) }
+spawn {work(0)}
+wait()`,
+			`for i in items {
+    spawn work(i)
+}
+spawn {
+    work(0)
+}
+wait()
+`,
+		},
+		{
+			`try {risky()} catch e { println(e) }
+try {
+    risky()
+}   catch   {}`,
+			`try {
+    risky()
+} catch e {
+    println(e)
+}
+try {
+    risky()
+} catch {}
+`,
+		},
+		{
+			`when {
+  v from jobs => { work(v) }
+  send(out,1+2) => println("sent"),   // sent
+  _ => idle(),
+}`,
+			`when {
+    v from jobs => {
+        work(v)
+    }
+    send(out, 1 + 2) => println("sent"), // sent
+    _ => idle(),
+}
+`,
+		},
+		{
+			"const  limit:int=3\nlet  mut  n = limit",
+			"const limit: int = 3\nlet mut n = limit\n",
+		},
+		{
+			`impl Add for Money { fn add(a: Money, b: Money): Money { return Money{cents: a.cents+b.cents} }
+  // scaled
+  fn other(a: Money, b: Money): Money { return a } }
+impl Eq for Money {  }`,
+			`impl Add for Money {
+    fn add(a: Money, b: Money): Money {
+        return Money{cents: a.cents + b.cents}
+    }
+
+    // scaled
+    fn other(a: Money, b: Money): Money {
+        return a
+    }
+}
+
+impl Eq for Money {}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		output, err := Source([]byte(tt.input))
+		if err != nil {
+			t.Fatalf("Source(%q) failed: %v", tt.input, err)
+		}
+		if string(output) != tt.expected {
+			t.Errorf("wrong output for:\n%s\nexpected:\n%s\ngot:\n%s", tt.input, tt.expected, output)
+		}
+		again, err := Source(output)
+		if err != nil || string(again) != string(output) {
+			t.Errorf("formatting is not idempotent for:\n%s\ngot:\n%s", output, again)
+		}
+	}
+}
+
+func TestSourceErrors(t *testing.T) {
+	if _, err := Source([]byte("fn main() {\n    let = 1\n}")); err == nil {
+		t.Error("expected a syntax error")
+	}
+}
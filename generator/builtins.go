@@ -0,0 +1,597 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+	"github.com/linkalls/zeno-lang/types"
+)
+
+// builtinFunction describes a function available in every program without
+// an import. A user-defined or imported function of the same name takes
+// precedence, like print and println.
+type builtinFunction struct {
+	// params is the number of arguments the builtin takes
+	params int
+	// optional is the number of trailing parameters that may be left out
+	optional int
+	// returnType is the Zeno type of the call, nil for the builtins whose
+	// type depends on the call, see builtinCallType
+	returnType types.Type
+	// helper is the Go function emitted in nativeBuiltinHelpers, empty for
+	// ok, err and some which are written as zenoResult and zenoOption
+	// literals
+	helper string
+	// located passes the Zeno location of the call to the helper, after
+	// the arguments, for the messages of assertions
+	located bool
+}
+
+var builtinFunctions = map[string]builtinFunction{
+	"len":        {params: 1, returnType: types.IntType, helper: "zenoBuiltinLen"},
+	"str":        {params: 1, returnType: types.StringType, helper: "zenoBuiltinStr"},
+	"int":        {params: 1, returnType: &types.ResultType{ValueType: types.IntType, ErrorType: types.StringType}, helper: "zenoBuiltinInt"},
+	"float":      {params: 1, returnType: &types.ResultType{ValueType: types.FloatType, ErrorType: types.StringType}, helper: "zenoBuiltinFloat"},
+	"typeOf":     {params: 1, returnType: types.StringType, helper: "zenoBuiltinTypeOf"},
+	"toString":   {params: 1, returnType: types.StringType, helper: "zenoBuiltinStr"},
+	"toInt":      {params: 1, returnType: &types.ResultType{ValueType: types.IntType, ErrorType: types.StringType}, helper: "zenoBuiltinInt"},
+	"toFloat":    {params: 1, returnType: &types.ResultType{ValueType: types.FloatType, ErrorType: types.StringType}, helper: "zenoBuiltinFloat"},
+	"parseBool":  {params: 1, returnType: &types.ResultType{ValueType: types.BoolType, ErrorType: types.StringType}, helper: "zenoBuiltinParseBool"},
+	"i32":        {params: 1, returnType: &types.ResultType{ValueType: types.I32Type, ErrorType: types.StringType}, helper: "zenoBuiltinSized"},
+	"i64":        {params: 1, returnType: &types.ResultType{ValueType: types.I64Type, ErrorType: types.StringType}, helper: "zenoBuiltinSized"},
+	"u32":        {params: 1, returnType: &types.ResultType{ValueType: types.U32Type, ErrorType: types.StringType}, helper: "zenoBuiltinSized"},
+	"u64":        {params: 1, returnType: &types.ResultType{ValueType: types.U64Type, ErrorType: types.StringType}, helper: "zenoBuiltinSized"},
+	"byte":       {params: 1, returnType: &types.ResultType{ValueType: types.ByteType, ErrorType: types.StringType}, helper: "zenoBuiltinSized"},
+	"char":       {params: 1, returnType: &types.ResultType{ValueType: types.CharType, ErrorType: types.StringType}, helper: "zenoBuiltinChar"},
+	"ok":         {params: 1},
+	"err":        {params: 1},
+	"some":       {params: 1},
+	"unwrapOr":   {params: 2, helper: "zenoBuiltinUnwrapOr"},
+	"range":      {params: 3, optional: 2, returnType: &types.ArrayType{ElementType: types.IntType}, helper: "zenoBuiltinRange"},
+	"args":       {params: 0, returnType: &types.ArrayType{ElementType: types.StringType}, helper: "zenoBuiltinArgs"},
+	"wait":       {params: 0, returnType: types.AnyType, helper: "zenoBuiltinWait"},
+	"panic":      {params: 1, returnType: types.AnyType, helper: "zenoBuiltinPanic"},
+	"chan":       {params: 1, optional: 1},
+	"send":       {params: 2, returnType: types.AnyType, helper: "zenoBuiltinSend"},
+	"recv":       {params: 1, helper: "zenoBuiltinRecv"},
+	"close":      {params: 1, returnType: types.AnyType, helper: "zenoBuiltinClose"},
+	"assertEq":   {params: 2, returnType: types.AnyType, helper: "zenoBuiltinAssertEq", located: true},
+	"assertTrue": {params: 1, returnType: types.AnyType, helper: "zenoBuiltinAssertTrue", located: true},
+}
+
+// lookupBuiltin returns the builtin called name unless a function of that
+// name is declared or imported
+func (g *Generator) lookupBuiltin(name string) (builtinFunction, bool) {
+	if _, declared := g.declaredFns[name]; declared {
+		return builtinFunction{}, false
+	}
+	if g.findFunctionDefinition(name) != nil {
+		return builtinFunction{}, false
+	}
+	b, ok := builtinFunctions[name]
+	return b, ok
+}
+
+// generateBuiltinCall writes a call to the Go helper implementing a builtin
+func (g *Generator) generateBuiltinCall(b builtinFunction, call *ast.FunctionCall, builder *strings.Builder) error {
+	if len(call.Arguments) < b.params-b.optional {
+		if b.optional > 0 {
+			return newGenerationErrorAt(call, i18n.GenArgumentCountAtLeast, call.Name, b.params-b.optional, len(call.Arguments), call.String())
+		}
+		return newGenerationErrorAt(call, i18n.GenArgumentCount, call.Name, b.params, len(call.Arguments), call.String())
+	}
+	if len(call.Arguments) > b.params {
+		return newGenerationErrorAt(call, i18n.GenArgumentCount, call.Name, b.params, len(call.Arguments), call.String())
+	}
+	switch call.Name {
+	case "some":
+		return g.generateSome(call, builder)
+	case "range":
+		return g.generateRange(call, builder)
+	case "chan":
+		return g.generateChan(call, builder)
+	case "send":
+		return g.generateSend(b, call, builder)
+	case "i32", "i64", "u32", "u64", "byte":
+		return g.generateSizedConversion(call, builder)
+	}
+	if b.helper == "" {
+		return g.generateResultConstructor(call, builder)
+	}
+	builder.WriteString(b.helper)
+	builder.WriteString("(")
+	defer g.expect(nil)()
+	for i, arg := range call.Arguments {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		generate := g.generateExpression
+		if boxingHelpers[b.helper] {
+			generate = g.generateAny
+		}
+		if err := generate(arg, builder); err != nil {
+			return err
+		}
+	}
+	if b.located {
+		builder.WriteString(fmt.Sprintf(", %q", fmt.Sprintf("%s:%d", filepath.Base(g.currentFile), call.Line)))
+	}
+	builder.WriteString(")")
+	return nil
+}
+
+// builtinCallType returns the type of a call to a builtin
+func (g *Generator) builtinCallType(b builtinFunction, call *ast.FunctionCall) types.Type {
+	if b.returnType != nil {
+		return b.returnType
+	}
+	switch call.Name {
+	case "some":
+		return g.optionConstructorType(call)
+	case "unwrapOr":
+		if len(call.Arguments) == 2 {
+			if option, ok := g.inferType(call.Arguments[0]).(*types.OptionType); ok {
+				return option.ValueType
+			}
+			return g.inferType(call.Arguments[1])
+		}
+		return types.AnyType
+	case "chan":
+		return g.channelConstructorType(call)
+	case "recv":
+		if len(call.Arguments) == 1 {
+			if element := g.channelElement(call.Arguments[0]); element != nil {
+				return &types.OptionType{ValueType: element}
+			}
+		}
+		return &types.OptionType{ValueType: types.AnyType}
+	}
+	return g.resultConstructorType(call)
+}
+
+// nativeBuiltinHelpers implements the builtin functions, the Result type
+// that conversions return, the Option type and spawn, whose goroutines
+// zenoSpawnGroup counts for wait. Channels are Go channels.
+const nativeBuiltinHelpers = `type zenoResult[T any, E any] struct {
+	Ok    bool
+	Value T
+	Error E
+}
+
+func (r zenoResult[T, E]) String() string {
+	if r.Ok {
+		return fmt.Sprintf("ok(%v)", r.Value)
+	}
+	return fmt.Sprintf("err(%v)", r.Error)
+}
+
+func (r zenoResult[T, E]) isResult() {}
+
+type zenoOption[T any] struct {
+	Some  bool
+	Value T
+}
+
+func (o zenoOption[T]) String() string {
+	if o.Some {
+		return fmt.Sprintf("some(%v)", o.Value)
+	}
+	return "none"
+}
+
+func (o zenoOption[T]) isOption() {}
+
+func zenoBuiltinUnwrapOr[T any](o zenoOption[T], fallback T) T {
+	if o.Some {
+		return o.Value
+	}
+	return fallback
+}
+
+func zenoBuiltinRange(start int, end int, step int) []int {
+	if step == 0 {
+		panic("range: step must not be 0")
+	}
+	result := []int{}
+	for i := start; (step > 0 && i < end) || (step < 0 && i > end); i += step {
+		result = append(result, i)
+	}
+	return result
+}
+
+// zenoAssertionFailed is the panic of a failed assertion, reported by zeno
+// test as the failure of the test
+type zenoAssertionFailed struct {
+	message string
+}
+
+func (f zenoAssertionFailed) Error() string { return f.message }
+
+func zenoBuiltinAssertEq(got interface{}, want interface{}, location string) {
+	if !reflect.DeepEqual(got, want) {
+		panic(zenoAssertionFailed{fmt.Sprintf("%s: assertEq failed: got %s, want %s", location, zenoAssertValue(got), zenoAssertValue(want))})
+	}
+}
+
+// zenoAssertValue writes a value in an assertion message, strings quoted
+func zenoAssertValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s)
+	}
+	return zenoBuiltinStr(value)
+}
+
+func zenoBuiltinAssertTrue(condition bool, location string) {
+	if !condition {
+		panic(zenoAssertionFailed{fmt.Sprintf("%s: assertTrue failed", location)})
+	}
+}
+
+func zenoBuiltinPanic(value interface{}) {
+	panic(zenoBuiltinStr(value))
+}
+
+// zenoCatch runs the block of a try and recovers a panic in it, returning
+// its message. Failed assertions are not caught, so that they still fail
+// the test.
+func zenoCatch(body func()) (message string, caught bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			if failure, ok := r.(zenoAssertionFailed); ok {
+				panic(failure)
+			}
+			message, caught = fmt.Sprint(r), true
+		}
+	}()
+	body()
+	return "", false
+}
+
+// zenoRecoverPanic ends the program on a panic with its message, in Zeno
+// terms for the errors of the Go runtime, and the frames of the program,
+// leaving out the runtime and the helpers. Failed assertions and panics
+// with other values are printed as they are.
+func zenoRecoverPanic() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "panic: %s\n\n", zenoPanicMessage(r))
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(1, pcs)])
+	for {
+		frame, more := frames.Next()
+		name := strings.TrimSuffix(frame.Function, "[...]")
+		name = name[strings.LastIndex(name, ".")+1:]
+		// The goroutines of the helpers run function literals, such as
+		// main.zenoSpawn.func1
+		outer := frame.Function[strings.Index(frame.Function, ".")+1:]
+		if !strings.HasPrefix(frame.Function, "runtime.") && !strings.HasPrefix(name, "zeno") && !strings.HasPrefix(outer, "zeno") {
+			fmt.Fprintf(os.Stderr, "%s(...)\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	os.Exit(2)
+}
+
+func zenoPanicMessage(r interface{}) string {
+	err, ok := r.(runtime.Error)
+	if !ok {
+		return fmt.Sprint(r)
+	}
+	message := strings.TrimPrefix(err.Error(), "runtime error: ")
+	var index, length int
+	switch {
+	case message == "integer divide by zero":
+		return "division by zero"
+	case message == "assignment to entry in nil map":
+		return "assignment to a map that was not created"
+	case strings.Contains(message, "nil pointer dereference"):
+		return "use of a nil value"
+	case strings.HasPrefix(message, "index out of range"):
+		if _, err := fmt.Sscanf(message, "index out of range [%d] with length %d", &index, &length); err == nil {
+			return fmt.Sprintf("index %d is out of range for length %d", index, length)
+		}
+	}
+	return message
+}
+
+// zenoCheckedAdd and the functions below compute the operators of ints
+// when overflow checks are on
+func zenoCheckedAdd(a int, b int) int {
+	r := a + b
+	if (b > 0 && r < a) || (b < 0 && r > a) {
+		zenoOverflow(a, "+", b)
+	}
+	return r
+}
+
+func zenoCheckedSub(a int, b int) int {
+	r := a - b
+	if (b > 0 && r > a) || (b < 0 && r < a) {
+		zenoOverflow(a, "-", b)
+	}
+	return r
+}
+
+func zenoCheckedMul(a int, b int) int {
+	r := a * b
+	// The smallest int times -1 wraps around to itself, which the division
+	// does not show
+	if a != 0 && (r/a != b || (a == -1 && b < 0 && r < 0)) {
+		zenoOverflow(a, "*", b)
+	}
+	return r
+}
+
+func zenoCheckedDiv(a int, b int) int {
+	if b == 0 {
+		panic(fmt.Sprintf("division by zero: %d / 0", a))
+	}
+	if b == -1 && a < 0 && -a < 0 {
+		zenoOverflow(a, "/", b)
+	}
+	return a / b
+}
+
+func zenoCheckedMod(a int, b int) int {
+	if b == 0 {
+		panic(fmt.Sprintf("division by zero: %d %% 0", a))
+	}
+	return a % b
+}
+
+func zenoCheckedNeg(a int) int {
+	if a < 0 && -a < 0 {
+		panic(fmt.Sprintf("integer overflow: -(%d) does not fit in an int", a))
+	}
+	return -a
+}
+
+func zenoOverflow(a int, op string, b int) {
+	panic(fmt.Sprintf("integer overflow: %d %s %d does not fit in an int", a, op, b))
+}
+
+func zenoBuiltinArgs() []string {
+	return append([]string{}, os.Args[1:]...)
+}
+
+var zenoSpawnGroup sync.WaitGroup
+
+func zenoSpawn(task func()) {
+	zenoSpawnGroup.Add(1)
+	go func() {
+		defer zenoSpawnGroup.Done()
+		defer zenoRecoverPanic()
+		task()
+	}()
+}
+
+func zenoBuiltinWait() {
+	zenoSpawnGroup.Wait()
+}
+
+func zenoBuiltinSend[T any](ch chan T, value T) {
+	ch <- value
+}
+
+func zenoBuiltinRecv[T any](ch chan T) zenoOption[T] {
+	value, ok := <-ch
+	return zenoOption[T]{Some: ok, Value: value}
+}
+
+func zenoBuiltinClose[T any](ch chan T) {
+	close(ch)
+}
+
+func zenoBuiltinLen(value interface{}) int {
+	if s, ok := value.(string); ok {
+		return len([]rune(s))
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
+		return rv.Len()
+	}
+	if set, ok := value.(*zenoSet[any]); ok {
+		return len(set.items)
+	}
+	if collection, ok := value.(interface{ length() int }); ok {
+		return collection.length()
+	}
+	panic(fmt.Sprintf("len: %s has no length", zenoBuiltinTypeOf(value)))
+}
+
+func zenoBuiltinStr(value interface{}) string {
+	if value == nil {
+		return "nil"
+	}
+	return fmt.Sprint(value)
+}
+
+func zenoBuiltinInt(value interface{}) zenoResult[int, string] {
+	switch v := value.(type) {
+	case int:
+		return zenoResult[int, string]{Ok: true, Value: v}
+	case int32, int64, uint8, uint32, uint64:
+		return zenoBuiltinSized[int](v, "int")
+	case float64:
+		return zenoResult[int, string]{Ok: true, Value: int(v)}
+	case string:
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return zenoResult[int, string]{Error: fmt.Sprintf("cannot convert %q to int", v)}
+		}
+		return zenoResult[int, string]{Ok: true, Value: n}
+	}
+	return zenoResult[int, string]{Error: fmt.Sprintf("cannot convert %s to int", zenoBuiltinTypeOf(value))}
+}
+
+func zenoBuiltinFloat(value interface{}) zenoResult[float64, string] {
+	switch v := value.(type) {
+	case int:
+		return zenoResult[float64, string]{Ok: true, Value: float64(v)}
+	case int32, int64, uint8, uint32, uint64:
+		rv := reflect.ValueOf(v)
+		if rv.CanInt() {
+			return zenoResult[float64, string]{Ok: true, Value: float64(rv.Int())}
+		}
+		return zenoResult[float64, string]{Ok: true, Value: float64(rv.Uint())}
+	case float64:
+		return zenoResult[float64, string]{Ok: true, Value: v}
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return zenoResult[float64, string]{Error: fmt.Sprintf("cannot convert %q to float", v)}
+		}
+		return zenoResult[float64, string]{Ok: true, Value: f}
+	}
+	return zenoResult[float64, string]{Error: fmt.Sprintf("cannot convert %s to float", zenoBuiltinTypeOf(value))}
+}
+
+// zenoBuiltinSized converts an integer, a float or a string to the integer
+// type T, called name in Zeno, failing when the value does not fit in it
+func zenoBuiltinSized[T zenoInteger](value interface{}, name string) zenoResult[T, string] {
+	var n T
+	fits := false
+	switch v := value.(type) {
+	case int:
+		n, fits = zenoFromSigned[T](int64(v))
+	case int32:
+		n, fits = zenoFromSigned[T](int64(v))
+	case int64:
+		n, fits = zenoFromSigned[T](v)
+	case uint8:
+		n, fits = zenoFromUnsigned[T](uint64(v))
+	case uint32:
+		n, fits = zenoFromUnsigned[T](uint64(v))
+	case uint64:
+		n, fits = zenoFromUnsigned[T](v)
+	case float64:
+		// Go leaves the conversion of a float out of the range of the
+		// integer type undefined
+		if v >= -(1<<63) && v < 1<<63 {
+			n, fits = zenoFromSigned[T](int64(v))
+		} else if v >= 0 && v < 1<<64 {
+			n, fits = zenoFromUnsigned[T](uint64(v))
+		}
+	case string:
+		s := strings.TrimSpace(v)
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			n, fits = zenoFromSigned[T](i)
+		} else if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+			n, fits = zenoFromUnsigned[T](u)
+		} else {
+			return zenoResult[T, string]{Error: fmt.Sprintf("cannot convert %q to %s", v, name)}
+		}
+	default:
+		return zenoResult[T, string]{Error: fmt.Sprintf("cannot convert %s to %s", zenoBuiltinTypeOf(value), name)}
+	}
+	if !fits {
+		return zenoResult[T, string]{Error: fmt.Sprintf("%v is out of range for %s", value, name)}
+	}
+	return zenoResult[T, string]{Ok: true, Value: n}
+}
+
+// zenoFromSigned converts v to T and reports whether it keeps its value
+func zenoFromSigned[T zenoInteger](v int64) (T, bool) {
+	n := T(v)
+	return n, int64(n) == v && (n < 0) == (v < 0)
+}
+
+// zenoFromUnsigned converts v to T and reports whether it keeps its value
+func zenoFromUnsigned[T zenoInteger](v uint64) (T, bool) {
+	n := T(v)
+	return n, uint64(n) == v && n >= 0
+}
+
+// zenoChar is a char passed where any is expected, which prints as the
+// character rather than its code point
+type zenoChar rune
+
+func (c zenoChar) String() string {
+	return string(c)
+}
+
+// zenoBuiltinChar converts a code point, or a string of one character, to a
+// char
+func zenoBuiltinChar(value interface{}) zenoResult[rune, string] {
+	switch v := value.(type) {
+	case zenoChar:
+		return zenoResult[rune, string]{Ok: true, Value: rune(v)}
+	case string:
+		if r, size := utf8.DecodeRuneInString(v); size > 0 && size == len(v) && (r != utf8.RuneError || size > 1) {
+			return zenoResult[rune, string]{Ok: true, Value: r}
+		}
+		return zenoResult[rune, string]{Error: fmt.Sprintf("cannot convert %q to char, which holds one character", v)}
+	case int, int32, int64, uint8, uint32, uint64:
+		if n := zenoBuiltinSized[int32](v, "char"); n.Ok && utf8.ValidRune(n.Value) {
+			return zenoResult[rune, string]{Ok: true, Value: n.Value}
+		}
+		return zenoResult[rune, string]{Error: fmt.Sprintf("%v is not a valid character", v)}
+	}
+	return zenoResult[rune, string]{Error: fmt.Sprintf("cannot convert %s to char", zenoBuiltinTypeOf(value))}
+}
+
+func zenoBuiltinParseBool(value interface{}) zenoResult[bool, string] {
+	switch v := value.(type) {
+	case bool:
+		return zenoResult[bool, string]{Ok: true, Value: v}
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return zenoResult[bool, string]{Error: fmt.Sprintf("cannot convert %q to bool", v)}
+		}
+		return zenoResult[bool, string]{Ok: true, Value: b}
+	}
+	return zenoResult[bool, string]{Error: fmt.Sprintf("cannot convert %s to bool", zenoBuiltinTypeOf(value))}
+}
+
+func zenoBuiltinTypeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "nil"
+	case int:
+		return "int"
+	case zenoChar:
+		return "char"
+	case int32:
+		return "i32"
+	case int64:
+		return "i64"
+	case uint32:
+		return "u32"
+	case uint64:
+		return "u64"
+	case uint8:
+		return "byte"
+	case float64:
+		return "float"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case interface{ isResult() }:
+		return "Result"
+	case interface{ isOption() }:
+		return "Option"
+	}
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map:
+		return "map"
+	case reflect.Func:
+		return "function"
+	case reflect.Chan:
+		return "Channel"
+	}
+	return reflect.TypeOf(value).String()
+}
+
+`
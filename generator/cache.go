@@ -0,0 +1,176 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/deps"
+	"github.com/linkalls/zeno-lang/lexer"
+	"github.com/linkalls/zeno-lang/parser"
+	"github.com/linkalls/zeno-lang/stdlib"
+)
+
+// cacheFormat is part of every cache key, with compilerID, so that entries
+// written by a generator producing different code are not reused
+const cacheFormat = "zeno-package-1"
+
+// compilerID identifies the running compiler by the hash of its executable,
+// which changes with every build of zeno, so that an upgrade does not reuse
+// the packages an older version generated. Without a readable executable,
+// the module version and commit it was built from are used.
+var compilerID = sync.OnceValue(func() string {
+	if path, err := os.Executable(); err == nil {
+		if file, err := os.Open(path); err == nil {
+			defer file.Close()
+			hash := sha256.New()
+			if _, err := io.Copy(hash, file); err == nil {
+				return hex.EncodeToString(hash.Sum(nil))
+			}
+		}
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	id := info.Main.Version
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" || setting.Key == "vcs.modified" {
+			id += " " + setting.Value
+		}
+	}
+	return id
+})
+
+// PackageCache stores the Go packages generated for user modules by key, so
+// that modules whose sources did not change are not generated again. The
+// key covers the module, the modules it imports, directly or not, the build
+// constants and the options.
+type PackageCache interface {
+	Load(key string) (*CachedPackage, bool)
+	Store(key string, pkg *CachedPackage)
+}
+
+// CachedPackage is a generated package as stored in a PackageCache
+type CachedPackage struct {
+	Code      string
+	Lines     map[int]SourceLocation // the source map
+	GoModules []string
+	// Imports lists the Zeno files of the user modules the package
+	// imports, which are added to the workspace with it
+	Imports []string
+}
+
+// SetCache sets the cache of the packages generated for user modules
+func (g *Generator) SetCache(cache PackageCache) {
+	g.cache = cache
+}
+
+// packageKey returns the cache key of the package generated for the user
+// module in zenoFile, whose imports are read recursively
+func (w *workspaceState) packageKey(g *Generator, zenoFile string) string {
+	if key, ok := w.keys[zenoFile]; ok {
+		return key
+	}
+	// An import cycle hashes the files already on the path once
+	w.keys[zenoFile] = zenoFile
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%s\n%s\n%s\n%d\n%t\n", cacheFormat, compilerID(), w.rootDir, zenoFile, g.options.Strictness, g.options.CheckOverflow)
+	names := make([]string, 0, len(g.buildConstants))
+	for name := range g.buildConstants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(hash, "%s=%s\n", name, g.buildConstants[name].String())
+	}
+	content, err := os.ReadFile(zenoFile)
+	if err == nil {
+		hash.Write(content)
+		userModules, stdModules := moduleImports(zenoFile, string(content))
+		for _, imported := range userModules {
+			fmt.Fprintf(hash, "\n%s", w.packageKey(g, imported))
+		}
+		// The standard library may be read from --std-path, and edited
+		for _, module := range stdModules {
+			if std, _, err := stdlib.Source(module); err == nil {
+				hash.Write(std)
+			}
+		}
+	}
+	key := hex.EncodeToString(hash.Sum(nil))
+	w.keys[zenoFile] = key
+	return key
+}
+
+// moduleImports returns the absolute paths of the user modules imported by
+// the Zeno source of file, and the standard library modules it imports
+func moduleImports(file, content string) (userModules, stdModules []string) {
+	program := parser.New(lexer.New(content)).ParseProgram()
+	for _, stmt := range program.Statements {
+		imp, ok := stmt.(*ast.ImportStatement)
+		if !ok {
+			continue
+		}
+		if strings.HasPrefix(imp.Module, "std/") {
+			stdModules = append(stdModules, imp.Module)
+			continue
+		}
+		if deps.IsRemote(imp.Module) {
+			if path, err := deps.Resolve(filepath.Dir(file), imp.Module); err == nil {
+				userModules = append(userModules, path)
+			}
+			continue
+		}
+		if !strings.HasPrefix(imp.Module, "./") && !strings.HasPrefix(imp.Module, "../") {
+			continue
+		}
+		module := imp.Module
+		if !strings.HasSuffix(module, ".zeno") {
+			module += ".zeno"
+		}
+		if path, err := filepath.Abs(filepath.Join(filepath.Dir(file), module)); err == nil {
+			userModules = append(userModules, path)
+		}
+	}
+	return userModules, stdModules
+}
+
+// cachedPackage fills pkg from the cache and adds the packages it imports
+// to the workspace. It reports false if the package is not in the cache.
+func (g *Generator) cachedPackage(key string, pkg *Package) (bool, error) {
+	cached, ok := g.workspace.cache.Load(key)
+	if !ok {
+		return false, nil
+	}
+	pkg.Code = cached.Code
+	pkg.SourceMap = &SourceMap{lines: cached.Lines}
+	if pkg.SourceMap.lines == nil {
+		pkg.SourceMap.lines = make(map[int]SourceLocation)
+	}
+	pkg.goModules = cached.GoModules
+	for _, imported := range cached.Imports {
+		content, err := os.ReadFile(imported)
+		if err != nil {
+			return false, nil
+		}
+		p := parser.New(lexer.New(string(content)))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			return false, nil
+		}
+		if _, err := g.userPackage(imported, program); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
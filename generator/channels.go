@@ -0,0 +1,208 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/types"
+)
+
+// isChannelType reports whether a type annotation names a channel, e.g.
+// Channel<int>
+func isChannelType(name string) bool {
+	return strings.HasPrefix(name, "Channel<") && strings.HasSuffix(name, ">")
+}
+
+// channelType returns the Channel type written name
+func (g *Generator) channelType(name string) *types.ChannelType {
+	_, arg, _ := strings.Cut(strings.TrimSuffix(name, ">"), "<")
+	return &types.ChannelType{ElementType: g.mapASTTypeToType(arg)}
+}
+
+// goChannelType returns the Go type of the values of a channel type
+func (g *Generator) goChannelType(t *types.ChannelType) string {
+	return "chan " + g.goValueType(t.ElementType)
+}
+
+// channelConstructorType returns the channel type created by chan: the one
+// of its type argument, or else the one expected by the statement, or else
+// a channel of any values
+func (g *Generator) channelConstructorType(call *ast.FunctionCall) *types.ChannelType {
+	if len(call.TypeArguments) == 1 {
+		return &types.ChannelType{ElementType: g.mapASTTypeToType(call.TypeArguments[0])}
+	}
+	if expected, ok := g.expected.(*types.ChannelType); ok {
+		return expected
+	}
+	return &types.ChannelType{ElementType: types.AnyType}
+}
+
+// generateChan writes a call to chan as a make of a Go channel, buffered
+// when a capacity is given
+func (g *Generator) generateChan(call *ast.FunctionCall, builder *strings.Builder) error {
+	builder.WriteString("make(" + g.goChannelType(g.channelConstructorType(call)))
+	if len(call.Arguments) == 1 {
+		builder.WriteString(", ")
+		defer g.expect(nil)()
+		if err := g.generateExpression(call.Arguments[0], builder); err != nil {
+			return err
+		}
+	}
+	builder.WriteString(")")
+	return nil
+}
+
+// channelElement returns the type of the values of the channel expr, nil if
+// it is not known to be a channel
+func (g *Generator) channelElement(expr ast.Expression) types.Type {
+	if channel, ok := g.inferType(expr).(*types.ChannelType); ok {
+		return channel.ElementType
+	}
+	return nil
+}
+
+// generateSend writes a call to send. The type argument of the helper is
+// written out when it is known, so that the value converts to the type of
+// the channel instead of Go inferring a different one from it.
+func (g *Generator) generateSend(b builtinFunction, call *ast.FunctionCall, builder *strings.Builder) error {
+	element := g.channelElement(call.Arguments[0])
+	builder.WriteString(b.helper)
+	if element != nil {
+		builder.WriteString("[" + g.goValueType(element) + "]")
+	}
+	builder.WriteString("(")
+	if err := g.generateExpression(call.Arguments[0], builder); err != nil {
+		return err
+	}
+	builder.WriteString(", ")
+	defer g.expect(element)()
+	if err := g.generateExpression(call.Arguments[1], builder); err != nil {
+		return err
+	}
+	builder.WriteString(")")
+	return nil
+}
+
+// generateWhen writes a when as a Go select
+func (g *Generator) generateWhen(s *ast.WhenStatement, builder *strings.Builder, indentLevel int) error {
+	var blocks []*ast.Block
+	for i := range s.Arms {
+		blocks = append(blocks, s.Arms[i].Block)
+	}
+	return g.generateArms("zenoWhenBreak", armsBreak(blocks), s, builder, indentLevel, func(level int) error {
+		builder.WriteString(indent(level) + "select {\n")
+		for i := range s.Arms {
+			if err := g.generateWhenArm(&s.Arms[i], builder, level); err != nil {
+				return err
+			}
+		}
+		builder.WriteString(indent(level) + "}\n")
+		return nil
+	})
+}
+
+// generateArms writes the Go select or switch of the arms of a when or a
+// match with generate, at the indentation level it is given. A break in an
+// arm would only leave the select or switch, so when the arms break out of
+// the loop around them, they set a flag, named from prefix, that is checked
+// after it instead.
+func (g *Generator) generateArms(prefix string, breaks bool, node ast.Node, builder *strings.Builder, indentLevel int, generate func(level int) error) error {
+	outerBreak := g.breakFlag
+	defer func() { g.breakFlag = outerBreak }()
+	g.breakFlag = ""
+	level := indentLevel
+	if breaks {
+		g.breakFlags++
+		g.breakFlag = fmt.Sprintf("%s%d", prefix, g.breakFlags)
+		builder.WriteString(indent(level) + "{\n")
+		level++
+		builder.WriteString(indent(level) + g.breakFlag + " := false\n")
+	}
+	if err := generate(level); err != nil {
+		return err
+	}
+	if g.breakFlag != "" {
+		builder.WriteString(indent(level) + "if " + g.breakFlag + " {\n")
+		// The break leaves the loop, or sets the flag of outer arms
+		flag := g.breakFlag
+		g.breakFlag = outerBreak
+		if err := g.generateStatement(&ast.BreakStatement{Position: node.Pos()}, builder, level+1); err != nil {
+			return err
+		}
+		g.breakFlag = flag
+		builder.WriteString(indent(level) + "}\n")
+		builder.WriteString(indent(indentLevel) + "}\n")
+	}
+	return nil
+}
+
+// generateWhenArm writes the case clause of an arm of a when and its body
+func (g *Generator) generateWhenArm(arm *ast.WhenArm, builder *strings.Builder, indentLevel int) error {
+	defer g.enterScope()()
+	statements := []ast.Statement{&ast.ExpressionStatement{Position: arm.Position, Expression: arm.Value}}
+	if arm.Block != nil {
+		statements = arm.Block.Statements
+	}
+	builder.WriteString(indent(indentLevel))
+	switch {
+	case arm.IsDefault():
+		builder.WriteString("default:\n")
+	case arm.Sent != nil:
+		builder.WriteString("case ")
+		if err := g.generateExpression(arm.Channel, builder); err != nil {
+			return err
+		}
+		builder.WriteString(" <- ")
+		restore := g.expect(g.channelElement(arm.Channel))
+		err := g.generateExpression(arm.Sent, builder)
+		restore()
+		if err != nil {
+			return err
+		}
+		builder.WriteString(":\n")
+	default:
+		builder.WriteString("case ")
+		if arm.Name != "" && g.armReads(arm, statements) {
+			builder.WriteString(arm.Name + " := ")
+		}
+		builder.WriteString("<-")
+		if err := g.generateExpression(arm.Channel, builder); err != nil {
+			return err
+		}
+		builder.WriteString(":\n")
+	}
+	for _, stmt := range statements {
+		if err := g.generateStatement(stmt, builder, indentLevel+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// armReads registers the variable an arm receives into and reports whether
+// its statements read it, as Go rejects a variable that is never read
+func (g *Generator) armReads(arm *ast.WhenArm, statements []ast.Statement) bool {
+	element := g.channelElement(arm.Channel)
+	if element == nil {
+		element = types.AnyType
+	}
+	g.registerVariableWithType(arm.Name, element)
+	for _, name := range g.spawnCaptures(statements) {
+		if name == arm.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// armsBreak reports whether a break in the blocks of the arms of a when or
+// a match leaves the loop around it: one that is not in a loop of the arms
+func armsBreak(arms []*ast.Block) bool {
+	for _, block := range arms {
+		if block != nil && ast.Breaks(block.Statements) {
+			return true
+		}
+	}
+	return false
+}
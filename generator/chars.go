@@ -0,0 +1,36 @@
+package generator
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/types"
+)
+
+// boxingHelpers are the builtin helpers receiving the chars passed to them
+// boxed, see generateAny
+var boxingHelpers = map[string]bool{
+	"zenoBuiltinStr":    true,
+	"zenoBuiltinTypeOf": true,
+}
+
+// generateCharLiteral writes a char literal as a Go rune literal
+func generateCharLiteral(e *ast.CharLiteral, builder *strings.Builder) {
+	builder.WriteString(strconv.QuoteRune(e.Value))
+}
+
+// generateAny writes a value passed where any is expected. A char, a rune
+// in Go, is boxed in a zenoChar, which keeps it a char at run time: it
+// prints as the character rather than its code point, and typeOf names it.
+func (g *Generator) generateAny(expr ast.Expression, builder *strings.Builder) error {
+	if g.inferType(expr) != types.CharType {
+		return g.generateExpression(expr, builder)
+	}
+	builder.WriteString("zenoChar(")
+	if err := g.generateExpression(expr, builder); err != nil {
+		return err
+	}
+	builder.WriteString(")")
+	return nil
+}
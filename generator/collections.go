@@ -0,0 +1,357 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/types"
+)
+
+// collectionGoTypes are the Go types of the std/collections types, which
+// are generic over the Go types of their type arguments
+var collectionGoTypes = map[string]string{
+	"Set":       "zenoHashSet",
+	"Queue":     "zenoQueue",
+	"Stack":     "zenoStack",
+	"SortedMap": "zenoSortedMap",
+}
+
+// collectionType returns the type named by a type annotation such as
+// Set<int>, and false if it does not name a collection
+func (g *Generator) collectionType(name string) (*types.CollectionType, bool) {
+	base, args, generic := strings.Cut(strings.TrimSuffix(name, ">"), "<")
+	if !generic || !strings.HasSuffix(name, ">") {
+		return nil, false
+	}
+	elements := types.SplitTypeList(args)
+	if count, ok := types.Collections[base]; !ok || count != len(elements) {
+		return nil, false
+	}
+	collection := &types.CollectionType{Name: base}
+	for _, element := range elements {
+		collection.Elements = append(collection.Elements, g.mapASTTypeToType(element))
+	}
+	return collection, true
+}
+
+// goCollectionType returns the Go type of the values of a collection type
+func (g *Generator) goCollectionType(t *types.CollectionType) string {
+	elements := make([]string, len(t.Elements))
+	for i, element := range t.Elements {
+		elements[i] = g.goValueType(element)
+	}
+	return "*" + collectionGoTypes[t.Name] + "[" + strings.Join(elements, ", ") + "]"
+}
+
+// collectionMethod returns a method of a collection, called as the Go
+// method of the same name
+func collectionMethod(t *types.CollectionType, name string) (builtinMethod, bool) {
+	element := t.Elements[0]
+	var m builtinMethod
+	switch name {
+	case "length":
+		m = builtinMethod{params: 0, returnType: types.IntType}
+	case "values":
+		m = builtinMethod{params: 0, returnType: &types.ArrayType{ElementType: t.Elements[len(t.Elements)-1]}}
+	}
+	switch t.Name + "." + name {
+	case "Set.add", "Set.remove", "Set.contains", "SortedMap.remove", "SortedMap.contains":
+		m = builtinMethod{params: 1, returnType: types.BoolType}
+	case "Queue.enqueue", "Stack.push":
+		m = builtinMethod{params: 1}
+	case "Queue.dequeue", "Queue.peek", "Stack.pop", "Stack.peek":
+		m = builtinMethod{params: 0, returnType: &types.OptionType{ValueType: element}}
+	case "Queue.isEmpty", "Stack.isEmpty":
+		m = builtinMethod{params: 0, returnType: types.BoolType}
+	case "SortedMap.set":
+		m = builtinMethod{params: 2}
+	case "SortedMap.get":
+		m = builtinMethod{params: 1, returnType: &types.OptionType{ValueType: t.Elements[1]}}
+	case "SortedMap.keys":
+		m = builtinMethod{params: 0, returnType: &types.ArrayType{ElementType: element}}
+	}
+	if m.returnType == nil && m.params == 0 {
+		return builtinMethod{}, false
+	}
+	args := make([]string, m.params)
+	for i := range args {
+		args[i] = fmt.Sprintf("%%[%d]s", i+2)
+	}
+	m.format = "%[1]s." + name + "(" + strings.Join(args, ", ") + ")"
+	return m, true
+}
+
+// collectionLoopSource returns the method whose result a for-in loop over a
+// collection ranges over: the keys of a SortedMap, the values otherwise
+func collectionLoopSource(t *types.CollectionType) string {
+	if t.Name == "SortedMap" {
+		return ".keys()"
+	}
+	return ".values()"
+}
+
+// nativeCollectionHelpers implements the std/collections types. Sets and
+// sorted maps index their values as interface{} keys, so that they accept
+// the element types of Zeno, which Go generics only know as any; sorted maps
+// order their keys with zenoCollectionCompare.
+const nativeCollectionHelpers = `type zenoHashSet[T any] struct {
+	index map[interface{}]int
+	items []T
+}
+
+func zenoNativeNewSet[T any]() *zenoHashSet[T] {
+	return &zenoHashSet[T]{index: make(map[interface{}]int)}
+}
+
+func (s *zenoHashSet[T]) add(value T) bool {
+	if _, ok := s.index[value]; ok {
+		return false
+	}
+	s.index[value] = len(s.items)
+	s.items = append(s.items, value)
+	return true
+}
+
+func (s *zenoHashSet[T]) remove(value T) bool {
+	i, ok := s.index[value]
+	if !ok {
+		return false
+	}
+	delete(s.index, value)
+	s.items = append(s.items[:i], s.items[i+1:]...)
+	for j := i; j < len(s.items); j++ {
+		s.index[s.items[j]] = j
+	}
+	return true
+}
+
+func (s *zenoHashSet[T]) contains(value T) bool {
+	_, ok := s.index[value]
+	return ok
+}
+
+func (s *zenoHashSet[T]) length() int {
+	return len(s.items)
+}
+
+func (s *zenoHashSet[T]) values() []T {
+	return append([]T{}, s.items...)
+}
+
+func (s *zenoHashSet[T]) String() string {
+	return "Set" + zenoCollectionString(s.items)
+}
+
+type zenoQueue[T any] struct {
+	items []T
+}
+
+func zenoNativeNewQueue[T any]() *zenoQueue[T] {
+	return &zenoQueue[T]{}
+}
+
+func (q *zenoQueue[T]) enqueue(value T) {
+	q.items = append(q.items, value)
+}
+
+func (q *zenoQueue[T]) dequeue() zenoOption[T] {
+	if len(q.items) == 0 {
+		return zenoOption[T]{}
+	}
+	value := q.items[0]
+	q.items = q.items[1:]
+	return zenoOption[T]{Some: true, Value: value}
+}
+
+func (q *zenoQueue[T]) peek() zenoOption[T] {
+	if len(q.items) == 0 {
+		return zenoOption[T]{}
+	}
+	return zenoOption[T]{Some: true, Value: q.items[0]}
+}
+
+func (q *zenoQueue[T]) isEmpty() bool {
+	return len(q.items) == 0
+}
+
+func (q *zenoQueue[T]) length() int {
+	return len(q.items)
+}
+
+func (q *zenoQueue[T]) values() []T {
+	return append([]T{}, q.items...)
+}
+
+func (q *zenoQueue[T]) String() string {
+	return "Queue" + zenoCollectionString(q.items)
+}
+
+type zenoStack[T any] struct {
+	items []T
+}
+
+func zenoNativeNewStack[T any]() *zenoStack[T] {
+	return &zenoStack[T]{}
+}
+
+func (s *zenoStack[T]) push(value T) {
+	s.items = append(s.items, value)
+}
+
+func (s *zenoStack[T]) pop() zenoOption[T] {
+	if len(s.items) == 0 {
+		return zenoOption[T]{}
+	}
+	value := s.items[len(s.items)-1]
+	s.items = s.items[:len(s.items)-1]
+	return zenoOption[T]{Some: true, Value: value}
+}
+
+func (s *zenoStack[T]) peek() zenoOption[T] {
+	if len(s.items) == 0 {
+		return zenoOption[T]{}
+	}
+	return zenoOption[T]{Some: true, Value: s.items[len(s.items)-1]}
+}
+
+func (s *zenoStack[T]) isEmpty() bool {
+	return len(s.items) == 0
+}
+
+func (s *zenoStack[T]) length() int {
+	return len(s.items)
+}
+
+// values returns the elements from the top of the stack down
+func (s *zenoStack[T]) values() []T {
+	result := make([]T, len(s.items))
+	for i, item := range s.items {
+		result[len(s.items)-1-i] = item
+	}
+	return result
+}
+
+func (s *zenoStack[T]) String() string {
+	return "Stack" + zenoCollectionString(s.values())
+}
+
+type zenoSortedMap[K any, V any] struct {
+	sortedKeys []K
+	entries    map[interface{}]V
+}
+
+func zenoNativeNewSortedMap[K any, V any]() *zenoSortedMap[K, V] {
+	return &zenoSortedMap[K, V]{entries: make(map[interface{}]V)}
+}
+
+// search returns the position of key in the sorted keys, or where it would
+// be inserted
+func (m *zenoSortedMap[K, V]) search(key K) int {
+	return sort.Search(len(m.sortedKeys), func(i int) bool {
+		return zenoCollectionCompare(m.sortedKeys[i], key) >= 0
+	})
+}
+
+func (m *zenoSortedMap[K, V]) set(key K, value V) {
+	if _, ok := m.entries[key]; !ok {
+		i := m.search(key)
+		m.sortedKeys = append(m.sortedKeys, key)
+		copy(m.sortedKeys[i+1:], m.sortedKeys[i:])
+		m.sortedKeys[i] = key
+	}
+	m.entries[key] = value
+}
+
+func (m *zenoSortedMap[K, V]) get(key K) zenoOption[V] {
+	value, ok := m.entries[key]
+	return zenoOption[V]{Some: ok, Value: value}
+}
+
+func (m *zenoSortedMap[K, V]) remove(key K) bool {
+	if _, ok := m.entries[key]; !ok {
+		return false
+	}
+	delete(m.entries, key)
+	i := m.search(key)
+	m.sortedKeys = append(m.sortedKeys[:i], m.sortedKeys[i+1:]...)
+	return true
+}
+
+func (m *zenoSortedMap[K, V]) contains(key K) bool {
+	_, ok := m.entries[key]
+	return ok
+}
+
+func (m *zenoSortedMap[K, V]) length() int {
+	return len(m.sortedKeys)
+}
+
+func (m *zenoSortedMap[K, V]) keys() []K {
+	return append([]K{}, m.sortedKeys...)
+}
+
+func (m *zenoSortedMap[K, V]) values() []V {
+	result := make([]V, len(m.sortedKeys))
+	for i, key := range m.sortedKeys {
+		result[i] = m.entries[key]
+	}
+	return result
+}
+
+func (m *zenoSortedMap[K, V]) String() string {
+	parts := make([]string, len(m.sortedKeys))
+	for i, key := range m.sortedKeys {
+		parts[i] = fmt.Sprintf("%v: %v", key, m.entries[key])
+	}
+	return "SortedMap{" + strings.Join(parts, ", ") + "}"
+}
+
+func zenoCollectionString[T any](items []T) string {
+	parts := make([]string, len(items))
+	for i, item := range items {
+		parts[i] = fmt.Sprint(item)
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// zenoCollectionCompare orders numbers by value, strings and bools by their
+// natural order, and other values by their text
+func zenoCollectionCompare(a interface{}, b interface{}) int {
+	switch x := a.(type) {
+	case int:
+		if y, ok := b.(int); ok {
+			return zenoCollectionCompareOrdered(x, y)
+		}
+	case float64:
+		if y, ok := b.(float64); ok {
+			return zenoCollectionCompareOrdered(x, y)
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return zenoCollectionCompareOrdered(x, y)
+		}
+	case bool:
+		if y, ok := b.(bool); ok && x != y {
+			if y {
+				return -1
+			}
+			return 1
+		}
+		if _, ok := b.(bool); ok {
+			return 0
+		}
+	}
+	return zenoCollectionCompareOrdered(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func zenoCollectionCompareOrdered[T int | float64 | string](a T, b T) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+`
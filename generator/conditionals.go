@@ -0,0 +1,92 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+	"github.com/linkalls/zeno-lang/types"
+)
+
+// ifType returns the type of an if used as a value: the type of its
+// branches when they all have the same known type, and any otherwise.
+func (g *Generator) ifType(e *ast.IfExpression) types.Type {
+	var result types.Type
+	for _, block := range e.Blocks() {
+		endScope := g.enterScope()
+		g.registerBlockVariables(block)
+		var ok bool
+		result, ok = g.joinValueType(result, blockValue(block))
+		endScope()
+		if !ok {
+			return types.AnyType
+		}
+	}
+	return result
+}
+
+// generateIfExpression writes an if used as a value as a function literal
+// that is called at once and returns the value of the branch taken.
+func (g *Generator) generateIfExpression(e *ast.IfExpression, builder *strings.Builder) error {
+	level := g.indentLevel
+	builder.WriteString("func() ")
+	builder.WriteString(g.goValueType(g.ifType(e)))
+	builder.WriteString(" {\n")
+	outerValueClosure := g.inValueClosure
+	g.inValueClosure = true
+	defer func() { g.inValueClosure = outerValueClosure }()
+
+	builder.WriteString(indent(level + 1))
+	builder.WriteString("if ")
+	if err := g.generateCondition(e.Condition, builder); err != nil {
+		return err
+	}
+	builder.WriteString(" {\n")
+	if err := g.generateBranchValue(e.ThenBlock, builder, level+2); err != nil {
+		return err
+	}
+	for _, elseIf := range e.ElseIfClauses {
+		builder.WriteString(indent(level + 1))
+		builder.WriteString("} else if ")
+		if err := g.generateCondition(elseIf.Condition, builder); err != nil {
+			return err
+		}
+		builder.WriteString(" {\n")
+		if err := g.generateBranchValue(elseIf.Block, builder, level+2); err != nil {
+			return err
+		}
+	}
+	builder.WriteString(indent(level + 1))
+	builder.WriteString("} else {\n")
+	if err := g.generateBranchValue(e.ElseBlock, builder, level+2); err != nil {
+		return err
+	}
+	builder.WriteString(indent(level + 1))
+	builder.WriteString("}\n")
+	builder.WriteString(indent(level))
+	builder.WriteString("}()")
+	return nil
+}
+
+// generateBranchValue writes the statements of a branch of an if used as a
+// value and returns the value of its last one
+func (g *Generator) generateBranchValue(block *ast.Block, builder *strings.Builder, indentLevel int) error {
+	value := blockValue(block)
+	if value == nil {
+		return newGenerationErrorAt(block, i18n.GenIfBranchWithoutValue)
+	}
+	defer g.enterScope()()
+	statements := block.Statements
+	for _, stmt := range statements[:len(statements)-1] {
+		if err := g.generateStatement(stmt, builder, indentLevel); err != nil {
+			return err
+		}
+	}
+	builder.WriteString(indent(indentLevel))
+	builder.WriteString("return ")
+	if err := g.generateExpression(value, builder); err != nil {
+		return err
+	}
+	builder.WriteString("\n")
+	return nil
+}
@@ -0,0 +1,81 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+	"github.com/linkalls/zeno-lang/lexer"
+	"github.com/linkalls/zeno-lang/token"
+)
+
+// buildNamespace is the identifier through which build constants are read,
+// e.g. build.VERSION
+const buildNamespace = "build"
+
+// ParseBuildConstants parses NAME=VALUE definitions given on the command line
+// (-D flags). Values are typed like Zeno literals: true and false are bools,
+// numbers are ints or floats and anything else is a string. Quoting a value
+// ("VERSION=\"1\"") forces a string.
+func ParseBuildConstants(definitions []string) (map[string]ast.Expression, error) {
+	constants := make(map[string]ast.Expression)
+	for _, definition := range definitions {
+		name, value, found := strings.Cut(definition, "=")
+		if !found || !isIdentifier(name) {
+			return nil, fmt.Errorf("invalid build constant %q: expected NAME=VALUE", definition)
+		}
+		constants[name] = buildConstantLiteral(value)
+	}
+	return constants, nil
+}
+
+// isIdentifier reports whether name is a valid Zeno identifier
+func isIdentifier(name string) bool {
+	tok := lexer.New(name).NextToken()
+	return tok.Type == token.IDENT && tok.Literal == name
+}
+
+// buildConstantLiteral converts the value of a build constant to a literal
+func buildConstantLiteral(value string) ast.Expression {
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		return &ast.StringLiteral{Value: value[1 : len(value)-1]}
+	}
+	switch value {
+	case "true":
+		return &ast.BooleanLiteral{Value: true}
+	case "false":
+		return &ast.BooleanLiteral{Value: false}
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		return &ast.IntegerLiteral{Value: n}
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return &ast.FloatLiteral{Value: f}
+	}
+	return &ast.StringLiteral{Value: value}
+}
+
+// SetBuildConstants makes constants available to the program as build.NAME
+func (g *Generator) SetBuildConstants(constants map[string]ast.Expression) {
+	g.buildConstants = constants
+}
+
+// buildConstant resolves build.NAME. ok is false when expr is not a build
+// constant reference, for example when a variable named build shadows the
+// namespace.
+func (g *Generator) buildConstant(expr *ast.MemberExpression) (value ast.Expression, ok bool, err error) {
+	object, isIdent := expr.Object.(*ast.Identifier)
+	if !isIdent || object.Value != buildNamespace {
+		return nil, false, nil
+	}
+	if _, isVar := g.symbolTable.Resolve(buildNamespace); isVar {
+		return nil, false, nil
+	}
+	value, defined := g.buildConstants[expr.Property]
+	if !defined {
+		return nil, true, newGenerationErrorAt(expr, i18n.GenUnknownBuildConstant, expr.Property, expr.Property)
+	}
+	return value, true, nil
+}
@@ -0,0 +1,128 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/types"
+)
+
+// destructuredElements returns the expressions reading the names of
+// let {...} = value and let [...] = value: value.name or value[i]. The
+// expression of _ is nil.
+func destructuredElements(s *ast.LetDeclaration, value ast.Expression) []ast.Expression {
+	elements := make([]ast.Expression, len(s.Names))
+	for i, name := range s.Names {
+		switch {
+		case name == "_":
+		case s.Destructure == ast.DestructureFields:
+			elements[i] = &ast.MemberExpression{Position: s.Position, Object: value, Property: name}
+		default:
+			index := &ast.IntegerLiteral{Position: s.Position, Value: i}
+			elements[i] = &ast.IndexExpression{Position: s.Position, Left: value, Index: index}
+		}
+	}
+	return elements
+}
+
+// destructuredTypes returns the types of the names of a let that takes a
+// value apart, any when they are not known
+func (g *Generator) destructuredTypes(s *ast.LetDeclaration) []types.Type {
+	elements := make([]types.Type, len(s.Names))
+	if s.Destructure == ast.DestructureTuple {
+		tuple, _ := g.inferType(s.ValueExpression).(*types.TupleType)
+		for i := range elements {
+			elements[i] = types.AnyType
+			if tuple != nil && len(tuple.Elements) == len(elements) {
+				elements[i] = tuple.Elements[i]
+			}
+		}
+		return elements
+	}
+	for i, element := range destructuredElements(s, s.ValueExpression) {
+		elements[i] = types.AnyType
+		if element != nil {
+			elements[i] = g.inferType(element)
+		}
+	}
+	return elements
+}
+
+// generateDestructuring writes a let that takes a value apart as a Go
+// declaration of several variables. A tuple is written var a, b = value,
+// and let {name, age} = value as var name, age = value.name, value.age,
+// with value held in a variable unless it is one.
+func (g *Generator) generateDestructuring(s *ast.LetDeclaration, builder *strings.Builder, indentLevel int) error {
+	elementTypes := g.destructuredTypes(s)
+	if err := g.generateTryChecks(s.ValueExpression, builder, indentLevel); err != nil {
+		return err
+	}
+	if s.Destructure == ast.DestructureTuple {
+		g.registerDestructuredNames(s, elementTypes)
+		defer g.expect(&types.TupleType{Elements: elementTypes})()
+		builder.WriteString(indent(indentLevel))
+		builder.WriteString("var ")
+		builder.WriteString(strings.Join(s.Names, ", "))
+		builder.WriteString(" = ")
+		if err := g.generateExpression(s.ValueExpression, builder); err != nil {
+			return err
+		}
+		builder.WriteString("\n")
+		return nil
+	}
+
+	defer g.expect(nil)()
+	value := s.ValueExpression
+	if _, isVariable := value.(*ast.Identifier); !isVariable {
+		g.valueCount++
+		name := fmt.Sprintf("zenoValue%d", g.valueCount)
+		builder.WriteString(indent(indentLevel))
+		if indentLevel == 0 {
+			// A module-level let is a package-level variable
+			builder.WriteString("var " + name + " = ")
+		} else {
+			builder.WriteString(name + " := ")
+		}
+		if err := g.generateExpression(value, builder); err != nil {
+			return err
+		}
+		builder.WriteString("\n")
+		g.registerVariableWithType(name, g.inferType(value))
+		value = &ast.Identifier{Position: s.Position, Value: name}
+	}
+	var names, values []string
+	for i, element := range destructuredElements(s, value) {
+		if element == nil {
+			continue
+		}
+		var code strings.Builder
+		if err := g.generateExpression(element, &code); err != nil {
+			return err
+		}
+		names = append(names, s.Names[i])
+		values = append(values, code.String())
+	}
+	g.registerDestructuredNames(s, elementTypes)
+	builder.WriteString(indent(indentLevel))
+	if len(names) == 0 {
+		// Every name is _
+		if indentLevel == 0 {
+			builder.WriteString("var ")
+		}
+		builder.WriteString("_ = " + value.String() + "\n")
+		return nil
+	}
+	builder.WriteString(fmt.Sprintf("var %s = %s\n", strings.Join(names, ", "), strings.Join(values, ", ")))
+	return nil
+}
+
+// registerDestructuredNames defines the names of a let that takes a value
+// apart, except _
+func (g *Generator) registerDestructuredNames(s *ast.LetDeclaration, elementTypes []types.Type) {
+	for i, name := range s.Names {
+		if name != "_" {
+			g.registerVariableWithType(name, elementTypes[i])
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package generator
+
+import (
+	"fmt"
+	goparser "go/parser"
+	"go/scanner"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LineDirectives returns code, the Go file generated with sm, with
+// //line file.zeno:N directives on the line above the statements coming
+// from Zeno sources, so that Go compiler errors and the stack traces of
+// panics refer to them. Declarations that do not, such as the helpers and
+// the functions of the standard library, get a directive naming goFile, the
+// name of the Go file, to restore Go lines. Relative Zeno paths are taken
+// relative to the working directory and made absolute, since Go resolves
+// them from the directory of goFile. The directives start their lines, where
+// gofmt leaves them, so the result stays formatted.
+func (sm *SourceMap) LineDirectives(code, goFile string) string {
+	if sm == nil || len(sm.lines) == 0 {
+		return code
+	}
+	fset := token.NewFileSet()
+	file := fset.AddFile(goFile, fset.Base(), len(code))
+	var s scanner.Scanner
+	s.Init(file, []byte(code), nil, 0)
+	// firstTokens holds the offset of the first token of each line, which
+	// cannot be inside a string or a comment
+	firstTokens := make(map[int]int)
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			continue
+		}
+		if line := file.Line(pos); firstTokens[line] == 0 {
+			firstTokens[line] = file.Offset(pos) + 1
+		}
+	}
+	declLines := make(map[int]bool)
+	declSet := token.NewFileSet()
+	if parsed, err := goparser.ParseFile(declSet, goFile, code, goparser.SkipObjectResolution); err == nil {
+		for _, decl := range parsed.Decls {
+			declLines[declSet.Position(decl.Pos()).Line] = true
+		}
+	}
+
+	// A directive is needed where the line Go counts to from the previous
+	// one is not the right one
+	inserts := make(map[int]string)
+	added := 0 // directive lines inserted so far
+	fromZeno := false
+	var zenoFile string
+	var zenoOffset int // Zeno line minus Go line since the last directive
+	for line := 1; line <= file.LineCount(); line++ {
+		if firstTokens[line] == 0 {
+			continue
+		}
+		// The directive goes on its own line, which cannot be inserted
+		// where the line starts in the middle of a raw string or a comment
+		offset := file.Offset(file.LineStart(line))
+		if strings.TrimSpace(code[offset:firstTokens[line]-1]) != "" {
+			continue
+		}
+		loc, mapped := sm.lines[line]
+		if mapped && loc.Line > 0 && loc.File != "" && !strings.HasPrefix(loc.File, "std/") {
+			if fromZeno && loc.File == zenoFile && loc.Line-line == zenoOffset {
+				continue
+			}
+			path := loc.File
+			if abs, err := filepath.Abs(path); err == nil {
+				path = abs
+			}
+			inserts[offset] = fmt.Sprintf("//line %s:%d\n", filepath.ToSlash(path), loc.Line)
+			fromZeno, zenoFile, zenoOffset = true, loc.File, loc.Line-line
+			added++
+		} else if fromZeno && (mapped || declLines[line]) {
+			// The line ends up below the directives inserted above it
+			added++
+			inserts[offset] = fmt.Sprintf("//line %s:%d\n", filepath.Base(goFile), line+added)
+			fromZeno = false
+		}
+	}
+	offsets := make([]int, 0, len(inserts))
+	for offset := range inserts {
+		offsets = append(offsets, offset)
+	}
+	sort.Ints(offsets)
+	var builder strings.Builder
+	last := 0
+	for _, offset := range offsets {
+		builder.WriteString(code[last:offset])
+		builder.WriteString(inserts[offset])
+		last = offset
+	}
+	builder.WriteString(code[last:])
+	return builder.String()
+}
@@ -0,0 +1,255 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+	"github.com/linkalls/zeno-lang/types"
+)
+
+// variantTypeName returns the name of the Go struct of an enum variant,
+// e.g. ShapeCircle for Circle in enum Shape
+func variantTypeName(decl *ast.EnumDeclaration, variant *ast.EnumVariant) string {
+	return decl.Name + variant.Name
+}
+
+// variantField returns the name of the i-th field of a variant struct
+func variantField(i int) string {
+	return "F" + strconv.Itoa(i)
+}
+
+// generateEnumDeclaration writes an enum as a Go interface with a struct per
+// variant. The unexported marker method keeps other types out of the
+// interface, and String prints a variant like the call that creates it.
+func (g *Generator) generateEnumDeclaration(decl *ast.EnumDeclaration, builder *strings.Builder) {
+	marker := "is" + decl.Name
+	builder.WriteString(fmt.Sprintf("type %s interface {\n\t%s()\n}\n\n", decl.Name, marker))
+	for i := range decl.Variants {
+		variant := &decl.Variants[i]
+		name := variantTypeName(decl, variant)
+		if len(variant.Fields) == 0 {
+			builder.WriteString(fmt.Sprintf("type %s struct{}\n\n", name))
+		} else {
+			builder.WriteString(fmt.Sprintf("type %s struct {\n", name))
+			for j, field := range variant.Fields {
+				builder.WriteString(fmt.Sprintf("\t%s %s\n", variantField(j), mapType(field)))
+			}
+			builder.WriteString("}\n\n")
+		}
+		builder.WriteString(fmt.Sprintf("func (%s) %s() {}\n\n", name, marker))
+
+		builder.WriteString(fmt.Sprintf("func (v %s) String() string {\n", name))
+		if len(variant.Fields) == 0 {
+			builder.WriteString(fmt.Sprintf("\treturn %s\n", strconv.Quote(variant.Name)))
+		} else {
+			var verbs, args []string
+			for j, field := range variant.Fields {
+				verb := "%v"
+				if field == "string" {
+					verb = "%q"
+				}
+				verbs = append(verbs, verb)
+				args = append(args, ", v."+variantField(j))
+			}
+			format := variant.Name + "(" + strings.Join(verbs, ", ") + ")"
+			builder.WriteString(fmt.Sprintf("\treturn fmt.Sprintf(%s%s)\n", strconv.Quote(format), strings.Join(args, "")))
+		}
+		builder.WriteString("}\n\n")
+	}
+}
+
+// generateImportedEnum writes an enum imported from a module: a copy for
+// standard library modules, aliases of the interface and its variants for
+// user modules
+func (g *Generator) generateImportedEnum(modulePath string, decl *ast.EnumDeclaration, builder *strings.Builder) {
+	alias, isUserModule := g.modulePackages[modulePath]
+	if !isUserModule {
+		g.generateEnumDeclaration(decl, builder)
+		return
+	}
+	g.usedPackages[alias] = true
+	builder.WriteString(fmt.Sprintf("type %s = %s.%s\n\n", decl.Name, alias, decl.Name))
+	for i := range decl.Variants {
+		name := variantTypeName(decl, &decl.Variants[i])
+		builder.WriteString(fmt.Sprintf("type %s = %s.%s\n\n", name, alias, name))
+	}
+}
+
+// findEnumDeclaration looks up an enum declared in the program or imported
+// from a module
+func (g *Generator) findEnumDeclaration(name string) *ast.EnumDeclaration {
+	for _, decl := range g.enumDeclarations() {
+		if decl.Name == name {
+			return decl
+		}
+	}
+	return nil
+}
+
+// enumDeclarations returns the enums declared in the program and the ones
+// it imports
+func (g *Generator) enumDeclarations() []*ast.EnumDeclaration {
+	var result []*ast.EnumDeclaration
+	if g.program != nil {
+		for _, stmt := range g.program.Statements {
+			if decl, ok := stmt.(*ast.EnumDeclaration); ok {
+				result = append(result, decl)
+			}
+		}
+	}
+	for modulePath, typeNames := range g.importTypes {
+		moduleAST, exists := g.moduleASTs[modulePath]
+		if !exists {
+			continue
+		}
+		for _, stmt := range moduleAST.Statements {
+			if decl, ok := stmt.(*ast.EnumDeclaration); ok && containsString(typeNames, decl.Name) {
+				result = append(result, decl)
+			}
+		}
+	}
+	return result
+}
+
+// enumVariant resolves a name used as a value or called like a function to
+// an enum variant. Variables and functions of the same name take precedence.
+func (g *Generator) enumVariant(name string) (*ast.EnumDeclaration, *ast.EnumVariant, bool) {
+	if _, isVar := g.symbolTable.Resolve(name); isVar {
+		return nil, nil, false
+	}
+	if _, isFn := g.goFunctionName(name); isFn {
+		return nil, nil, false
+	}
+	for _, decl := range g.enumDeclarations() {
+		if variant, ok := decl.Variant(name); ok {
+			return decl, variant, true
+		}
+	}
+	return nil, nil, false
+}
+
+// generateVariant writes a value of an enum variant, converted to the enum
+// so that the variable holding it can take any variant
+func (g *Generator) generateVariant(node ast.Node, decl *ast.EnumDeclaration, variant *ast.EnumVariant, args []ast.Expression, builder *strings.Builder) error {
+	if len(args) != len(variant.Fields) {
+		return newGenerationErrorAt(node, i18n.GenArgumentCount, variant.Name, len(variant.Fields), len(args), node.String())
+	}
+	builder.WriteString(decl.Name + "(" + variantTypeName(decl, variant) + "{")
+	for i, arg := range args {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		if err := g.generateExpression(arg, builder); err != nil {
+			return err
+		}
+	}
+	builder.WriteString("})")
+	return nil
+}
+
+// variantPattern resolves the pattern of a match arm to the enum variant it
+// matches: Empty, or Circle(r) which also binds the fields
+func (g *Generator) variantPattern(pattern ast.Expression) (*ast.EnumDeclaration, *ast.EnumVariant, []ast.Expression, bool) {
+	switch p := pattern.(type) {
+	case *ast.Identifier:
+		decl, variant, ok := g.enumVariant(p.Value)
+		return decl, variant, nil, ok
+	case *ast.FunctionCall:
+		decl, variant, ok := g.enumVariant(p.Name)
+		return decl, variant, p.Arguments, ok
+	}
+	return nil, nil, nil, false
+}
+
+// isEnumMatch reports whether the arms of e match enum variants, which is
+// done with a type switch
+func (g *Generator) isEnumMatch(e *ast.MatchExpression) bool {
+	for _, arm := range e.Arms {
+		if _, _, _, ok := g.variantPattern(arm.Pattern); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// variantBindings returns the names a variant pattern binds, by field
+// index. Fields matched with _ are left out.
+func variantBindings(bindings []ast.Expression) map[int]string {
+	result := make(map[int]string)
+	for i, binding := range bindings {
+		if ident, ok := binding.(*ast.Identifier); ok && ident.Value != "_" {
+			result[i] = ident.Value
+		}
+	}
+	return result
+}
+
+// registerArmBindings declares the names bound by the variant or Option
+// pattern of an arm of e with the types of their fields, in the scope of
+// the arm
+func (g *Generator) registerArmBindings(e *ast.MatchExpression, arm *ast.MatchArm) {
+	if g.isOptionMatch(e) {
+		g.registerOptionBindings(e, arm)
+		return
+	}
+	_, variant, bindings, ok := g.variantPattern(arm.Pattern)
+	if !ok {
+		return
+	}
+	for i, name := range variantBindings(bindings) {
+		if i < len(variant.Fields) {
+			g.registerVariableWithType(name, g.mapASTTypeToType(variant.Fields[i]))
+		}
+	}
+}
+
+// generateMatchSwitch writes the switch statement header of a match on
+// subject, the Go code of its value. Matches on enum variants switch on the
+// type, binding the variant to zenoValue when an arm reads its fields, and
+// matches on Options bind the Option to zenoValue to test whether it is set.
+func (g *Generator) generateMatchSwitch(e *ast.MatchExpression, subject string, builder *strings.Builder) {
+	if g.isOptionMatch(e) {
+		builder.WriteString("switch zenoValue := " + subject + "; {\n")
+		return
+	}
+	if !g.isEnumMatch(e) {
+		builder.WriteString("switch " + subject + " {\n")
+		return
+	}
+	for _, arm := range e.Arms {
+		if _, _, bindings, ok := g.variantPattern(arm.Pattern); ok && len(variantBindings(bindings)) > 0 {
+			builder.WriteString("switch zenoValue := " + subject + ".(type) {\n")
+			return
+		}
+	}
+	builder.WriteString("switch " + subject + ".(type) {\n")
+}
+
+// generateVariantCase writes the case clause of an arm matching an enum
+// variant and declares the names it binds
+func (g *Generator) generateVariantCase(pattern ast.Expression, builder *strings.Builder, indentLevel int) error {
+	decl, variant, bindings, _ := g.variantPattern(pattern)
+	if len(bindings) > 0 && len(bindings) != len(variant.Fields) {
+		return newGenerationErrorAt(pattern, i18n.TypeVariantArity, variant.Name, len(variant.Fields), pattern, len(bindings))
+	}
+	builder.WriteString(indent(indentLevel) + "case " + variantTypeName(decl, variant) + ":\n")
+	names := variantBindings(bindings)
+	for i := range bindings {
+		name, ok := names[i]
+		if !ok {
+			continue
+		}
+		// The arm may not read every field it binds
+		builder.WriteString(fmt.Sprintf("%s%s := zenoValue.%s\n", indent(indentLevel+1), name, variantField(i)))
+		builder.WriteString(fmt.Sprintf("%s_ = %s\n", indent(indentLevel+1), name))
+	}
+	return nil
+}
+
+// enumType returns the type of a variant value
+func enumType(decl *ast.EnumDeclaration) types.Type {
+	return &types.EnumType{Name: decl.Name}
+}
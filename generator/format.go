@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"fmt"
+	"go/format"
+	"go/scanner"
+	"go/token"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/i18n"
+)
+
+// formatCode formats the generated code with gofmt and moves the lines of
+// sm to the lines their code ends up on. Code that gofmt cannot parse is a
+// bug of the generator, reported along with the code.
+func formatCode(code string, sm *SourceMap) (string, error) {
+	formatted, err := format.Source([]byte(code))
+	if err != nil {
+		return "", newGenerationError(i18n.GenFormatFailed, err, numberLines(code))
+	}
+	sm.remap(code, string(formatted))
+	return string(formatted), nil
+}
+
+// remap moves the mapped lines of before, the code the map was recorded
+// for, to the lines of the same code in after, its formatted version.
+// Tokens are matched in order; gofmt only moves them, apart from the
+// parentheses and semicolons it drops.
+func (sm *SourceMap) remap(before, after string) {
+	oldLines, newLines := tokenLines(before), tokenLines(after)
+	if len(oldLines) != len(newLines) {
+		return
+	}
+	moved := make(map[int]int)
+	for i, line := range oldLines {
+		if _, ok := moved[line]; !ok {
+			moved[line] = newLines[i]
+		}
+	}
+	lines := make(map[int]SourceLocation, len(sm.lines))
+	last := strings.Count(before, "\n") + 1
+	for line, loc := range sm.lines {
+		// A mapped line without tokens goes with the next line having some
+		for l := line; l <= last; l++ {
+			if to, ok := moved[l]; ok {
+				line = to
+				break
+			}
+		}
+		lines[line] = loc
+	}
+	sm.lines = lines
+}
+
+// tokenLines returns the line of each token of code, leaving out comments,
+// parentheses and semicolons
+func tokenLines(code string) []int {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(code))
+	var s scanner.Scanner
+	s.Init(file, []byte(code), nil, 0)
+	var lines []int
+	for {
+		pos, tok, _ := s.Scan()
+		switch tok {
+		case token.EOF:
+			return lines
+		case token.LPAREN, token.RPAREN, token.SEMICOLON:
+			continue
+		}
+		lines = append(lines, file.Line(pos))
+	}
+}
+
+// numberLines prefixes the lines of code with their numbers, which the
+// errors about it refer to
+func numberLines(code string) string {
+	var builder strings.Builder
+	for i, line := range strings.Split(strings.TrimSuffix(code, "\n"), "\n") {
+		fmt.Fprintf(&builder, "%4d | %s\n", i+1, line)
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
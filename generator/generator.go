@@ -1,15 +1,24 @@
 package generator
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/deps"
+	"github.com/linkalls/zeno-lang/diagnostics"
+	"github.com/linkalls/zeno-lang/i18n"
 	"github.com/linkalls/zeno-lang/lexer"
 	"github.com/linkalls/zeno-lang/parser"
+	"github.com/linkalls/zeno-lang/stdlib"
 	"github.com/linkalls/zeno-lang/types"
 )
 
@@ -24,21 +33,245 @@ func snakeToCamel(s string) string {
 	return strings.Join(parts, "")
 }
 
+// exportedName returns the Go name exporting name, with its first letter in
+// upper case. Go only exports the names starting with an upper case letter,
+// so a name starting with a letter without case, as in Japanese, or with _
+// is prefixed with X.
+func exportedName(name string) string {
+	first, size := utf8.DecodeRuneInString(name)
+	if size == 0 {
+		return name
+	}
+	if upper := unicode.ToUpper(first); unicode.IsUpper(upper) {
+		return string(upper) + name[size:]
+	}
+	return "X" + name
+}
+
+// unexportedName returns name with its first letter in lower case
+func unexportedName(name string) string {
+	first, size := utf8.DecodeRuneInString(name)
+	if size == 0 {
+		return name
+	}
+	return string(unicode.ToLower(first)) + name[size:]
+}
+
 // GenerationError represents errors during code generation
 type GenerationError struct {
-	Message string
+	Code       string // stable diagnostic code, e.g. Z0104
+	Message    string
+	Suggestion string       // optional fix shown as help, e.g. a missing import line
+	Pos        ast.Position // location in the Zeno source, zero if unknown
+	File       string       // module the error is in, empty for the generated file
 }
 
 func (e GenerationError) Error() string {
-	return "Generation Error: " + e.Message
+	message := e.Message
+	if e.Code != "" {
+		message = "[" + e.Code + "] " + message
+	}
+	message = i18n.T(i18n.LabelGenerationError, message)
+	if e.Pos.IsValid() {
+		message += "\n  --> " + i18n.T(i18n.LabelLocation, e.Pos.Line, e.Pos.Column)
+	}
+	if e.Suggestion != "" {
+		message += "\n" + i18n.T(i18n.LabelHelp) + ": " + e.Suggestion
+	}
+	return message
+}
+
+// Diagnostic converts the error to the representation shared by all
+// phases. file is the generated file, used unless the error is in a module.
+func (e GenerationError) Diagnostic(file string) diagnostics.Diagnostic {
+	if e.File != "" {
+		file = e.File
+	}
+	return diagnostics.Diagnostic{
+		Severity:   diagnostics.Error,
+		Code:       e.Code,
+		Source:     "generator",
+		File:       file,
+		Pos:        e.Pos,
+		Message:    e.Message,
+		Suggestion: e.Suggestion,
+	}
+}
+
+// GenerationErrors are errors reported together, such as the unused
+// variables and functions of a program
+type GenerationErrors []GenerationError
+
+func (e GenerationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Errors returns the GenerationErrors err holds, or the GenerationError it
+// is, and nil for any other error
+func Errors(err error) []GenerationError {
+	var list GenerationErrors
+	if errors.As(err, &list) {
+		return list
+	}
+	var single GenerationError
+	if errors.As(err, &single) {
+		return []GenerationError{single}
+	}
+	return nil
+}
+
+// newGenerationError builds a GenerationError from the message catalog
+func newGenerationError(id i18n.MessageID, args ...interface{}) GenerationError {
+	return GenerationError{Code: i18n.Code(id), Message: i18n.T(id, args...)}
+}
+
+// newGenerationErrorAt builds a GenerationError located at node
+func newGenerationErrorAt(node ast.Node, id i18n.MessageID, args ...interface{}) GenerationError {
+	err := newGenerationError(id, args...)
+	if node != nil {
+		err.Pos = node.Pos()
+	}
+	return err
+}
+
+// locate attaches the position of node to err if it is a GenerationError
+// without a location of its own
+func locate(err error, node ast.Node) error {
+	genErr, ok := err.(GenerationError)
+	if !ok || genErr.Pos.IsValid() {
+		return err
+	}
+	genErr.Pos = node.Pos()
+	return genErr
+}
+
+// Warning represents a non-fatal diagnostic produced during code generation
+type Warning struct {
+	Code    string
+	Message string
+	Pos     ast.Position // location in the Zeno source, zero if unknown
+}
+
+func (w Warning) String() string {
+	label := i18n.T(i18n.LabelWarning)
+	if w.Code != "" {
+		label += "[" + w.Code + "]"
+	}
+	message := label + ": " + w.Message
+	if w.Pos.IsValid() {
+		message += "\n  --> " + i18n.T(i18n.LabelLocation, w.Pos.Line, w.Pos.Column)
+	}
+	return message
+}
+
+// Diagnostic converts the warning to the representation shared by all
+// phases
+func (w Warning) Diagnostic(file string) diagnostics.Diagnostic {
+	return diagnostics.Diagnostic{
+		Severity: diagnostics.Warning,
+		Code:     w.Code,
+		Source:   "generator",
+		File:     file,
+		Pos:      w.Pos,
+		Message:  w.Message,
+	}
+}
+
+// Strictness says how the generator treats unused variables and functions
+type Strictness int
+
+const (
+	// StrictnessError fails the generation, which is the default
+	StrictnessError Strictness = iota
+	// StrictnessWarn reports a warning for each of them
+	StrictnessWarn
+	// StrictnessOff accepts them silently
+	StrictnessOff
+)
+
+// GeneratorOptions configure a Generator, see SetOptions
+type GeneratorOptions struct {
+	// Strictness says how unused variables and functions are treated.
+	// Names starting with _ are never reported.
+	Strictness Strictness
+	// Test generates a test program, whose main function runs the test
+	// functions of the file (see TestFunctions) instead of its own
+	Test bool
+	// Debug leaves panics to the Go runtime, which prints the whole stack
+	// trace, instead of ending the program with a Zeno message
+	Debug bool
+	// CheckOverflow makes +, -, *, / and % and negation panic when their
+	// result does not fit in an int, instead of wrapping around, and gives
+	// the operands in the message of a division by zero
+	CheckOverflow bool
+}
+
+// SourceLocation describes the Zeno construct a generated Go line came from
+type SourceLocation struct {
+	File      string // Zeno source file or module path
+	Function  string // Enclosing Zeno function, empty at top level
+	Statement string // Zeno source text of the statement
+	Line      int    // Zeno line number, 0 if unknown
+}
+
+// SourceMap maps line numbers of the generated Go code back to Zeno sources
+type SourceMap struct {
+	lines map[int]SourceLocation
+}
+
+// shift moves all mapped lines down by n, for code inserted above them
+func (sm *SourceMap) shift(n int) {
+	lines := make(map[int]SourceLocation, len(sm.lines))
+	for line, loc := range sm.lines {
+		lines[line+n] = loc
+	}
+	sm.lines = lines
+}
+
+// Lookup returns the Zeno location for a generated Go line. Lines without an
+// exact entry resolve to the closest preceding mapped line.
+func (sm *SourceMap) Lookup(goLine int) (SourceLocation, bool) {
+	if sm == nil {
+		return SourceLocation{}, false
+	}
+	for line := goLine; line > 0; line-- {
+		if loc, ok := sm.lines[line]; ok {
+			return loc, true
+		}
+	}
+	return SourceLocation{}, false
 }
 
 // Generator manages code generation with scope and import tracking
 type Generator struct {
-	imports      map[string][]string
-	declaredVars map[string]bool
-	usedVars     map[string]bool
-	declaredFns  map[string]string
+	imports map[string][]string
+	// declaredVars are the variables declared with let, in the order of
+	// the declarations, and usedVars the ones read afterwards in their
+	// scope. Both are recorded by collectImportsAndDeclarations.
+	declaredVars []variableDeclaration
+	usedVars     map[*types.Symbol]bool
+	// discarded maps let declarations to their unused names, which are
+	// assigned to _ since Go rejects unused variables
+	discarded map[*ast.LetDeclaration][]string
+	// packageLets are the package-level lets in the order they are
+	// collected, which puts the ones a value refers to before it. A let is
+	// collected into packageScope when a declaration before it refers to
+	// one of its names in laterLets.
+	packageLets   []*ast.LetDeclaration
+	laterLets     map[string]*ast.LetDeclaration
+	collectedLets map[*ast.LetDeclaration]bool
+	packageScope  *types.SymbolTable
+	options       GeneratorOptions
+	declaredFns   map[string]string
+	// aliases are the functions imported with as, by the name they are
+	// imported as
+	aliases map[string]importedFunction
+	// namespaces are the modules imported with import * as, by name
+	namespaces   map[string]string
 	usedFns      map[string]bool
 	importTypes  map[string][]string // 型インポートの追跡
 	userModules  map[string]map[string]string
@@ -47,24 +280,93 @@ type Generator struct {
 	currentDir   string
 	symbolTable  *types.SymbolTable
 	program      *ast.Program
+	warnings     []Warning
+	sourceMap    *SourceMap
+	// currentFile and currentFunction describe the Zeno code being generated,
+	// for the source map
+	currentFile     string
+	currentFunction string
+	// buildConstants holds the values of build.NAME set with -D
+	buildConstants map[string]ast.Expression
+	// workspace is shared with the generators of imported user modules
+	workspace *workspaceState
+	// cache stores the packages generated for user modules, nil if none
+	cache PackageCache
+	// userImports are the Zeno files of the user modules imported by the
+	// file being generated
+	userImports []string
+	// packageName is the Go package generated, "main" unless this is a module
+	packageName string
+	// packageImports maps the aliases of imported user packages to their
+	// import paths; usedPackages records the ones the generated code refers to
+	packageImports map[string]string
+	usedPackages   map[string]bool
+	// modulePackages maps imported user modules to their package aliases
+	modulePackages map[string]string
+	// indentLevel is the indentation of the statement being generated, for
+	// expressions that span several lines
+	indentLevel int
+	// returnType is the return type of the function being generated, nil
+	// if it has none
+	returnType types.Type
+	// expected is the type the expression being generated must have, which
+	// ok, err, some and none create values of
+	expected types.Type
+	// tryValues holds the Go expressions giving the values of the ?
+	// operators checked before the statement being generated; tryCount
+	// numbers the variables holding them
+	tryValues map[*ast.TryExpression]string
+	tryCount  int
+	// valueCount numbers the variables holding the values taken apart by
+	// let {...} and let [...]
+	valueCount int
+	// inValueClosure is set while generating the arms of a match or the
+	// branches of an if used as a value, which run in a function literal
+	inValueClosure bool
+	// inConstant is set while generating the value of a const, which Go
+	// computes at compile time
+	inConstant bool
+	// breakFlag is the flag that a break sets in the arms of a when or a
+	// match, outside the loops in them, "" elsewhere; breakFlags numbers the
+	// flags
+	breakFlag  string
+	breakFlags int
+	// constraints holds the type parameters of the generic function being
+	// generated, with the index in typeConstraints of the constraint the
+	// body needs of each, nil outside generic functions
+	constraints map[string]int
 }
 
 func NewGenerator() *Generator {
 	g := &Generator{
-		imports:      make(map[string][]string),
-		declaredVars: make(map[string]bool),
-		usedVars:     make(map[string]bool),
-		declaredFns:  make(map[string]string),
-		usedFns:      make(map[string]bool),
-		userModules:  make(map[string]map[string]string),
-		moduleASTs:   make(map[string]*ast.Program),
-		standardLibs: make(map[string]map[string]string),
-		symbolTable:  types.NewSymbolTable(nil),
-		importTypes:  make(map[string][]string),
+		imports:        make(map[string][]string),
+		usedVars:       make(map[*types.Symbol]bool),
+		discarded:      make(map[*ast.LetDeclaration][]string),
+		declaredFns:    make(map[string]string),
+		aliases:        make(map[string]importedFunction),
+		namespaces:     make(map[string]string),
+		usedFns:        make(map[string]bool),
+		userModules:    make(map[string]map[string]string),
+		moduleASTs:     make(map[string]*ast.Program),
+		standardLibs:   make(map[string]map[string]string),
+		symbolTable:    types.NewSymbolTable(nil),
+		importTypes:    make(map[string][]string),
+		sourceMap:      &SourceMap{lines: make(map[int]SourceLocation)},
+		packageName:    "main",
+		packageImports: make(map[string]string),
+		usedPackages:   make(map[string]bool),
+		modulePackages: make(map[string]string),
+		tryValues:      make(map[*ast.TryExpression]string),
 	}
 	return g
 }
 
+// SetOptions configures the generation, e.g. how unused variables are
+// treated
+func (g *Generator) SetOptions(options GeneratorOptions) {
+	g.options = options
+}
+
 func Generate(program *ast.Program) (string, error) {
 	return GenerateWithOptions(program)
 }
@@ -74,129 +376,320 @@ func GenerateWithOptions(program *ast.Program) (string, error) {
 }
 
 func GenerateWithFile(program *ast.Program, sourceFile string) (string, error) {
+	code, _, err := GenerateWithWarnings(program, sourceFile)
+	return code, err
+}
+
+// GenerateWithWarnings generates Go code like GenerateWithFile and additionally
+// returns the non-fatal warnings collected along the way.
+func GenerateWithWarnings(program *ast.Program, sourceFile string) (string, []Warning, error) {
 	g := NewGenerator()
+	code, err := g.GenerateFile(program, sourceFile)
+	return code, g.warnings, err
+}
+
+// GenerateFile generates Go code for a program read from sourceFile. After it
+// returns, Warnings and SourceMap describe the generated output.
+func (g *Generator) GenerateFile(program *ast.Program, sourceFile string) (string, error) {
 	g.currentDir = sourceFile
+	g.currentFile = sourceFile
 	g.program = program
 	return g.generateProgram(program)
 }
 
+// goModules lists the third-party Go modules required by std modules that
+// are not implemented with the Go standard library alone.
+var goModules = map[string]string{
+	"std/db": "modernc.org/sqlite v1.29.0",
+}
+
+// usesModule reports whether the program imports the given module
+func (g *Generator) usesModule(module string) bool {
+	_, ok := g.imports[module]
+	return ok
+}
+
+// driverImports returns the packages imported only for their side effects,
+// such as database drivers registering themselves.
+func (g *Generator) driverImports() []string {
+	if g.usesModule("std/db") {
+		return []string{"modernc.org/sqlite"}
+	}
+	return nil
+}
+
+// GoModules returns the third-party Go modules ("path version") the generated
+// code depends on. Building it requires a go.mod listing them.
+func (g *Generator) GoModules() []string {
+	var result []string
+	for module, requirement := range goModules {
+		if g.usesModule(module) {
+			result = append(result, requirement)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Warnings returns the warnings collected so far
+func (g *Generator) Warnings() []Warning { return g.warnings }
+
+// SourceMap returns the mapping from generated Go lines to Zeno sources
+func (g *Generator) SourceMap() *SourceMap { return g.sourceMap }
+
+// recordSourceLocation maps the Go line about to be written to stmt.
+func (g *Generator) recordSourceLocation(builder *strings.Builder, stmt ast.Statement) {
+	goLine := strings.Count(builder.String(), "\n") + 1
+	g.sourceMap.lines[goLine] = SourceLocation{
+		File:      g.currentFile,
+		Function:  g.currentFunction,
+		Statement: firstLine(stmt.String()),
+		Line:      stmt.Pos().Line,
+	}
+}
+
+func firstLine(s string) string {
+	if i := strings.Index(s, "\n"); i >= 0 {
+		return strings.TrimSpace(s[:i])
+	}
+	return s
+}
+
+func (g *Generator) addWarning(node ast.Node, id i18n.MessageID, args ...interface{}) {
+	g.warnings = append(g.warnings, Warning{Code: i18n.Code(id), Message: i18n.T(id, args...), Pos: node.Pos()})
+}
+
 func (g *Generator) generateProgram(program *ast.Program) (string, error) {
 	var builder strings.Builder
 	if err := g.validateFunctionTypes(program); err != nil {
 		return "", err
 	}
+	moduleLevel := g.packageName != "main" || g.options.Test || program.ModuleLevel()
+	// The variables are collected in a scope of their own, so that the
+	// generation starts without them. The package-level ones come first,
+	// since the functions declared before them may use them.
+	endScope := g.enterScope()
+	g.packageScope, g.packageLets = g.symbolTable, nil
+	g.laterLets = make(map[string]*ast.LetDeclaration)
+	g.collectedLets = make(map[*ast.LetDeclaration]bool)
 	for _, stmt := range program.Statements {
-		if err := g.collectImportsAndDeclarations(stmt); err != nil {
-			return "", err
+		if packageLevel(stmt, moduleLevel) {
+			let := stmt.(*ast.LetDeclaration)
+			for _, name := range let.BoundNames() {
+				if name != "_" {
+					g.laterLets[name] = let
+				}
+			}
 		}
 	}
-	builder.WriteString("package main\n\n")
-	builder.WriteString("import (\n")
-	requiredImports := make(map[string]bool)
-	for module := range g.imports {
-		if strings.HasPrefix(module, "std/") {
-			if module == "std/fmt" {
-				requiredImports["fmt"] = true
-			} else if module == "std/io" {
-				requiredImports["os"] = true
+	for _, first := range []bool{true, false} {
+		for _, stmt := range program.Statements {
+			if packageLevel(stmt, moduleLevel) != first {
+				continue
+			}
+			var err error
+			if first {
+				err = g.collectPackageLet(stmt.(*ast.LetDeclaration))
+			} else {
+				err = g.collectImportsAndDeclarations(stmt)
+			}
+			if err != nil {
+				return "", err
 			}
 		}
 	}
+	endScope()
+	unusedVars := g.unusedVariables()
+	for _, v := range unusedVars {
+		g.discarded[v.let] = append(g.discarded[v.let], v.symbol.Name)
+	}
+	// The imports are written last, once the packages the code refers to
+	// are known
+	requiredImports := make(map[string]bool)
 	requiredImports["fmt"] = true
 	requiredImports["os"] = true
 	requiredImports["encoding/json"] = true
-	for imp := range requiredImports {
-		builder.WriteString(fmt.Sprintf("\t\"%s\"\n", imp))
+	requiredImports["strings"] = true
+	requiredImports["reflect"] = true
+	requiredImports["sync"] = true
+	requiredImports["strconv"] = true
+	requiredImports["runtime"] = true
+	requiredImports["unicode/utf8"] = true
+	if g.usesModule("std/db") {
+		requiredImports["database/sql"] = true
 	}
-	builder.WriteString(")\n\n")
-	// Generate Go generic type alias for Zeno 'Result<T>'
-	for _, stmt := range program.Statements {
-		if tdecl, ok := stmt.(*ast.TypeDeclaration); ok && tdecl.Name == "Result" && len(tdecl.Generics) == 1 {
-			gen := tdecl.Generics[0]
-			builder.WriteString(fmt.Sprintf("type Result[%s any] struct {\n", gen))
-			builder.WriteString("\tOk bool\n")
-			builder.WriteString(fmt.Sprintf("\tValue %s\n", gen))
-			builder.WriteString("\tError string\n")
-			builder.WriteString("}\n\n")
-			break
+	if g.usesModule("std/datetime") {
+		requiredImports["time"] = true
+	}
+	if g.usesModule("std/semver") || g.usesModule("std/collections") {
+		requiredImports["sort"] = true
+	}
+	if g.usesModule("std/ansi") || g.usesModule("std/regex") {
+		requiredImports["regexp"] = true
+	}
+	if g.usesModule("std/sync") {
+		requiredImports["sync/atomic"] = true
+	}
+	if g.usesModule("std/prompt") {
+		requiredImports["bufio"] = true
+		requiredImports["os/exec"] = true
+	}
+	if g.usesModule("std/io") {
+		requiredImports["bufio"] = true
+		requiredImports["io"] = true
+	}
+	if g.usesModule("std/os") {
+		requiredImports["os/exec"] = true
+	}
+	if g.usesModule("std/http") {
+		for _, pkg := range []string{"io", "net/http", "time"} {
+			requiredImports[pkg] = true
 		}
 	}
-
-	// Generate type definitions for imported types
-	for modulePath, typeNames := range g.importTypes {
-		if moduleAST, exists := g.moduleASTs[modulePath]; exists {
-			for _, stmt := range moduleAST.Statements {
-				if typeDecl, ok := stmt.(*ast.TypeDeclaration); ok {
-					for _, typeName := range typeNames {
-						if typeDecl.Name == typeName {
-							// Generate a simple type alias for now
-							builder.WriteString(fmt.Sprintf("type %s map[string]interface{}\n\n", typeName))
-						}
-					}
-				}
-			}
+	if g.usesModule("std/archive") {
+		for _, pkg := range []string{"archive/tar", "archive/zip", "compress/gzip", "io", "path/filepath"} {
+			requiredImports[pkg] = true
 		}
 	}
-	g.generateNativeFunctionHelpers(&builder)
+	g.generateTypeDeclarations(program, &builder)
 	var functionDefs []*ast.FunctionDefinition
+	var impls []*ast.ImplDeclaration
 	var otherStmts []ast.Statement
 	var mainFunc *ast.FunctionDefinition
+	for _, let := range g.packageLets {
+		if err := g.generateStatement(let, &builder, 0); err != nil {
+			return "", err
+		}
+	}
 	for _, stmt := range program.Statements {
+		if packageLevel(stmt, moduleLevel) {
+			continue
+		}
 		if funcDef, ok := stmt.(*ast.FunctionDefinition); ok {
-			if funcDef.Name == "main" {
+			if funcDef.Name == "main" && g.packageName == "main" {
 				mainFunc = funcDef
 			} else {
 				functionDefs = append(functionDefs, funcDef)
 			}
+		} else if impl, ok := stmt.(*ast.ImplDeclaration); ok {
+			impls = append(impls, impl)
 		} else if _, ok := stmt.(*ast.ImportStatement); !ok {
 			otherStmts = append(otherStmts, stmt)
 		}
 	}
+	if len(g.packageLets) > 0 {
+		builder.WriteString("\n")
+	}
+	// Standard library functions are copied into the package; user modules
+	// are packages of their own
 	for modulePath, moduleAST := range g.moduleASTs {
-		if importedFuncs, exists := g.imports[modulePath]; exists {
+		if !strings.HasPrefix(modulePath, "std/") {
+			continue
+		}
+		if _, exists := g.imports[modulePath]; exists {
+			g.currentFile = modulePath
 			for _, stmt := range moduleAST.Statements {
-				if funcDef, ok := stmt.(*ast.FunctionDefinition); ok && funcDef.IsPublic {
-					for _, importedFunc := range importedFuncs {
-						if importedFunc == funcDef.Name {
-							if err := g.generateStatement(funcDef, &builder, 0); err != nil {
-								return "", err
-							}
-							builder.WriteString("\n")
-							break
-						}
+				if funcDef, ok := stmt.(*ast.FunctionDefinition); ok && funcDef.IsPublic && g.importsFunction(modulePath, funcDef.Name) {
+					if err := g.generateStatement(funcDef, &builder, 0); err != nil {
+						return "", err
 					}
+					builder.WriteString("\n")
 				}
 			}
 		}
 	}
+	g.currentFile = g.currentDir
 	for _, funcDef := range functionDefs {
 		if err := g.generateStatement(funcDef, &builder, 0); err != nil {
 			return "", err
 		}
 		builder.WriteString("\n")
 	}
-	builder.WriteString("func main() {\n")
-	if mainFunc != nil {
-		for _, bodyStmt := range mainFunc.Body {
-			if err := g.generateStatement(bodyStmt, &builder, 1); err != nil {
-				return "", err
-			}
+	for _, impl := range impls {
+		if err := g.generateStatement(impl, &builder, 0); err != nil {
+			return "", err
 		}
-	} else if len(otherStmts) > 0 {
-		for _, stmt := range otherStmts {
-			if err := g.generateStatement(stmt, &builder, 1); err != nil {
-				return "", err
+	}
+	// Top-level statements of a module are not run; only its functions are
+	// part of the package
+	if g.packageName == "main" && g.options.Test {
+		g.generateTestMain(&builder)
+	} else if g.packageName == "main" {
+		builder.WriteString("func main() {\n")
+		if !g.options.Debug {
+			builder.WriteString("\tdefer zenoRecoverPanic()\n")
+		}
+		g.currentFunction = "main"
+		if mainFunc != nil {
+			for _, bodyStmt := range mainFunc.Body {
+				if err := g.generateStatement(bodyStmt, &builder, 1); err != nil {
+					return "", err
+				}
+			}
+		} else if len(otherStmts) > 0 {
+			for _, stmt := range otherStmts {
+				if err := g.generateStatement(stmt, &builder, 1); err != nil {
+					return "", err
+				}
 			}
 		}
+		builder.WriteString("}\n")
 	}
-	builder.WriteString("}\n")
-	if err := g.checkUnusedVariables(); err != nil {
-		return "", err
+	if unused := append(g.checkUnusedVariables(unusedVars), g.checkUnusedFunctions()...); len(unused) > 0 {
+		return "", unused
 	}
-	if err := g.checkUnusedFunctions(); err != nil {
-		return "", err
+
+	// Only the helpers and packages the code refers to are kept. The helpers
+	// come last, so that removing some leaves the source map unchanged.
+	var helpers strings.Builder
+	g.generateNativeFunctionHelpers(&helpers)
+	helperSource := helpers.String()
+	if g.options.Debug {
+		// Like main, the goroutines leave panics to Go
+		helperSource = strings.ReplaceAll(helperSource, "\tdefer zenoRecoverPanic()\n", "")
+	}
+	helperCode, referenced, ok := neededHelpers(builder.String(), helperSource)
+	if !ok {
+		helperCode = helperSource
+	}
+	builder.WriteString("\n")
+	builder.WriteString(helperCode)
+
+	var header strings.Builder
+	header.WriteString("package " + g.packageName + "\n\n")
+	header.WriteString("import (\n")
+	imports := make([]string, 0, len(requiredImports))
+	for imp := range requiredImports {
+		if !ok || referenced[path.Base(imp)] {
+			imports = append(imports, imp)
+		}
 	}
-	return builder.String(), nil
+	sort.Strings(imports)
+	for _, imp := range imports {
+		header.WriteString(fmt.Sprintf("\t\"%s\"\n", imp))
+	}
+	for _, pkg := range g.driverImports() {
+		header.WriteString(fmt.Sprintf("\t_ \"%s\"\n", pkg))
+	}
+	aliases := make([]string, 0, len(g.usedPackages))
+	for alias := range g.usedPackages {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		header.WriteString(fmt.Sprintf("\t%s \"%s\"\n", alias, g.packageImports[alias]))
+	}
+	header.WriteString(")\n\n")
+	g.sourceMap.shift(strings.Count(header.String(), "\n"))
+	return formatCode(header.String()+builder.String(), g.sourceMap)
+}
+
+// packageLevel reports whether stmt is a top-level let compiled to a Go
+// package-level variable or constant: any when the variables of the top
+// level are module-level, and consts otherwise
+func packageLevel(stmt ast.Statement, moduleLevel bool) bool {
+	let, ok := stmt.(*ast.LetDeclaration)
+	return ok && (moduleLevel || let.Constant)
 }
 
 func indent(level int) string { return strings.Repeat("\t", level) }
@@ -212,7 +705,12 @@ func getGoTypeForZenoPrimitiveType(zenoType types.Type) string {
 		return "string"
 	case types.BoolType:
 		return "bool"
+	case types.CharType:
+		return "rune"
 	default:
+		if types.IsSized(zenoType) {
+			return sizedGoTypes[zenoType.String()]
+		}
 		return "interface{}" // Default for non-primitive or unknown types
 	}
 }
@@ -227,45 +725,138 @@ func mapType(zenoType string) string {
 		return "bool"
 	case "string":
 		return "string"
+	case "char":
+		return "rune"
 	case "any":
 		return "interface{}"
+	case "Iterator":
+		return "*zenoIterator"
+	case "Regex":
+		return "*regexp.Regexp"
+	case "Mutex":
+		return "*sync.Mutex"
+	case "AtomicInt":
+		return "*atomic.Int64"
 	case "void":
 		return ""
 	default:
+		if goType, ok := sizedGoTypes[zenoType]; ok {
+			return goType
+		}
+		if element, isArray := strings.CutPrefix(zenoType, "[]"); isArray {
+			return "[]" + mapType(element)
+		}
+		// A tuple is returned as Go multiple values, (int, string)
+		if elements, isTuple := strings.CutPrefix(zenoType, "("); isTuple && strings.HasSuffix(elements, ")") {
+			var goElements []string
+			for _, element := range types.SplitTypeList(strings.TrimSuffix(elements, ")")) {
+				goElements = append(goElements, mapType(element))
+			}
+			return "(" + strings.Join(goElements, ", ") + ")"
+		}
+		// Type arguments are written Box<int> in Zeno and Box[int] in Go
+		if name, args, generic := strings.Cut(zenoType, "<"); generic && strings.HasSuffix(args, ">") {
+			var goArgs []string
+			for _, arg := range types.SplitTypeList(strings.TrimSuffix(args, ">")) {
+				goArgs = append(goArgs, mapType(arg))
+			}
+			if name == "Result" {
+				// Result<T> is short for Result<T, string>
+				if len(goArgs) == 1 {
+					goArgs = append(goArgs, "string")
+				}
+				name = "zenoResult"
+			}
+			if name == "Option" {
+				name = "zenoOption"
+			}
+			if name == "Channel" && len(goArgs) == 1 {
+				return "chan " + goArgs[0]
+			}
+			if goName, ok := collectionGoTypes[name]; ok {
+				name = "*" + goName
+			}
+			return name + "[" + strings.Join(goArgs, ", ") + "]"
+		}
 		return zenoType
 	}
 }
 
-func (g *Generator) generateStatement(stmt ast.Statement, builder *strings.Builder, indentLevel int) error {
+func (g *Generator) generateStatement(stmt ast.Statement, builder *strings.Builder, indentLevel int) (err error) {
+	defer func() { err = locate(err, stmt) }()
+	outerIndent := g.indentLevel
+	g.indentLevel = indentLevel
+	defer func() { g.indentLevel = outerIndent }()
+	switch stmt.(type) {
+	case *ast.TypeDeclaration, *ast.EnumDeclaration, *ast.ImportStatement:
+	default:
+		g.recordSourceLocation(builder, stmt)
+	}
+	switch stmt.(type) {
+	case *ast.WhileStatement, *ast.LoopStatement, *ast.ForStatement, *ast.SpawnStatement:
+		// A break in the body leaves the body's own loop
+		outerBreak := g.breakFlag
+		g.breakFlag = ""
+		defer func() { g.breakFlag = outerBreak }()
+	}
 	switch s := stmt.(type) {
-	case *ast.TypeDeclaration:
+	case *ast.TypeDeclaration, *ast.EnumDeclaration:
 		// skip type declarations
 		return nil
 	case *ast.ImportStatement:
 		return nil
 	case *ast.LetDeclaration:
+		// Go lets package-level variables go unused
+		if indentLevel > 0 {
+			defer g.discardUnused(s, builder, indentLevel)
+		}
+		if s.Names != nil {
+			return g.generateDestructuring(s, builder, indentLevel)
+		}
 		var varType types.Type
 		if s.TypeAnn != nil {
 			varType = g.mapASTTypeToType(*s.TypeAnn)
+			typeName := strings.SplitN(*s.TypeAnn, "<", 2)[0]
+			if decl := g.findTypeDeclaration(typeName); decl != nil {
+				g.warnIfDeprecated(s, i18n.GenWarnDeprecatedType, typeName, decl.Deprecated)
+			}
 		} else {
 			varType = g.inferType(s.ValueExpression)
 		}
 		g.registerVariableWithType(s.Name, varType)
+		if err := g.generateTryChecks(s.ValueExpression, builder, indentLevel); err != nil {
+			return err
+		}
+		defer g.expect(varType)()
 		builder.WriteString(indent(indentLevel))
-		builder.WriteString("var ")
+		if s.Constant {
+			builder.WriteString("const ")
+		} else {
+			builder.WriteString("var ")
+		}
 		builder.WriteString(s.Name)
 		if s.TypeAnn != nil {
 			builder.WriteString(" ")
 			builder.WriteString(mapType(*s.TypeAnn))
 		}
 		builder.WriteString(" = ")
-		if err := g.generateExpression(s.ValueExpression, builder); err != nil {
+		g.inConstant = s.Constant
+		err := g.generateExpression(s.ValueExpression, builder)
+		g.inConstant = false
+		if err != nil {
 			return err
 		}
 		builder.WriteString("\n")
 	case *ast.AssignmentStatement:
-		g.usedVars[s.Name] = true
-		g.markVariableUsage(s.Value)
+		if _, declared := g.symbolTable.Resolve(s.Name); !declared {
+			err := newGenerationErrorAt(s, i18n.TypeAssignUndeclared, s.Name)
+			err.Suggestion = i18n.T(i18n.TypeHintDeclare, s.Name, s.Value)
+			return err
+		}
+		if err := g.generateTryChecks(s.Value, builder, indentLevel); err != nil {
+			return err
+		}
+		defer g.expect(g.getVariableType(s.Name))()
 		builder.WriteString(indent(indentLevel))
 		builder.WriteString(s.Name)
 		builder.WriteString(" = ")
@@ -278,27 +869,14 @@ func (g *Generator) generateStatement(stmt ast.Statement, builder *strings.Build
 		builder.WriteString("func ")
 		functionName := s.Name
 		if s.IsPublic {
-			if len(functionName) > 0 {
-				functionName = strings.ToUpper(string(functionName[0])) + functionName[1:]
-			}
-		} else {
-			if functionName != "main" && len(functionName) > 0 {
-				functionName = strings.ToLower(string(functionName[0])) + functionName[1:]
-			}
+			functionName = exportedName(functionName)
+		} else if functionName != "main" {
+			functionName = unexportedName(functionName)
 		}
 		builder.WriteString(functionName)
-		// Generic type parameters
-		if len(s.Generics) > 0 {
-			builder.WriteString("[")
-			for i, gen := range s.Generics {
-				if i > 0 {
-					builder.WriteString(", ")
-				}
-				builder.WriteString(gen)
-				builder.WriteString(" any")
-			}
-			builder.WriteString("]")
-		}
+		// The type parameters are inserted here after the body, whose
+		// operators decide their constraints
+		typeParamsAt := builder.Len()
 		builder.WriteString("(")
 		for i, param := range s.Parameters {
 			if i > 0 {
@@ -316,23 +894,14 @@ func (g *Generator) generateStatement(stmt ast.Statement, builder *strings.Build
 			builder.WriteString(" ")
 			builder.WriteString(mapType(*s.ReturnType))
 		}
-		builder.WriteString(" {\n")
-		originalSymbolTable := g.symbolTable
-		g.symbolTable = types.NewSymbolTable(originalSymbolTable)
-		for _, param := range s.Parameters {
-			paramType := g.mapASTTypeToType(param.Type)
-			g.symbolTable.Define(param.Name, paramType)
-		}
-		for _, bodyStmt := range s.Body {
-			if err := g.generateStatement(bodyStmt, builder, indentLevel+1); err != nil {
-				g.symbolTable = originalSymbolTable
-				return err
-			}
-		}
-		g.symbolTable = originalSymbolTable
-		builder.WriteString(indent(indentLevel))
-		builder.WriteString("}\n")
+		return g.generateFunctionBody(s, builder, indentLevel, typeParamsAt)
+	case *ast.ImplDeclaration:
+		return g.generateImpl(s, builder, indentLevel)
 	case *ast.ReturnStatement:
+		if err := g.generateTryChecks(s.Value, builder, indentLevel); err != nil {
+			return err
+		}
+		defer g.expect(g.returnType)()
 		builder.WriteString(indent(indentLevel))
 		builder.WriteString("return")
 		if s.Value != nil {
@@ -343,12 +912,32 @@ func (g *Generator) generateStatement(stmt ast.Statement, builder *strings.Build
 		}
 		builder.WriteString("\n")
 	case *ast.ExpressionStatement:
+		if err := g.generateTryChecks(s.Expression, builder, indentLevel); err != nil {
+			return err
+		}
+		switch e := s.Expression.(type) {
+		case *ast.MatchExpression:
+			return g.generateMatchStatement(e, builder, indentLevel)
+		case *ast.TryExpression:
+			// Only the error check is needed
+			return nil
+		case *ast.MethodCallExpression:
+			builder.WriteString(indent(indentLevel))
+			if err := g.generateMethodCall(e, builder, true); err != nil {
+				return err
+			}
+			builder.WriteString("\n")
+			return nil
+		}
 		builder.WriteString(indent(indentLevel))
 		if err := g.generateExpression(s.Expression, builder); err != nil {
 			return err
 		}
 		builder.WriteString("\n")
 	case *ast.IfStatement:
+		if err := g.generateTryChecks(s.Condition, builder, indentLevel); err != nil {
+			return err
+		}
 		builder.WriteString(indent(indentLevel))
 		builder.WriteString("if ")
 		if err := g.generateCondition(s.Condition, builder); err != nil {
@@ -386,34 +975,170 @@ func (g *Generator) generateStatement(stmt ast.Statement, builder *strings.Build
 			return err
 		}
 		builder.WriteString("\n")
+	case *ast.LoopStatement:
+		builder.WriteString(indent(indentLevel))
+		builder.WriteString("for ")
+		if err := g.generateBlock(s.Body, builder, indentLevel); err != nil {
+			return err
+		}
+		builder.WriteString("\n")
+	case *ast.SpawnStatement:
+		return g.generateSpawn(s, builder, indentLevel)
+	case *ast.WhenStatement:
+		return g.generateWhen(s, builder, indentLevel)
+	case *ast.TryStatement:
+		return g.generateTry(s, builder, indentLevel)
+	case *ast.BreakStatement:
+		if g.breakFlag != "" {
+			builder.WriteString(indent(indentLevel) + g.breakFlag + " = true\n")
+		}
+		builder.WriteString(indent(indentLevel))
+		builder.WriteString("break\n")
+	case *ast.ContinueStatement:
+		builder.WriteString(indent(indentLevel))
+		builder.WriteString("continue\n")
 	case *ast.ForStatement:
+		if err := g.generateTryChecks(s.Iterable, builder, indentLevel); err != nil {
+			return err
+		}
+		// The loop variables are in a scope around the body, like in Go
+		defer g.enterScope()()
+		iterableType := g.inferType(s.Iterable)
+		if iterableType == types.IteratorType {
+			if s.IndexName != "" {
+				return newGenerationErrorAt(s.Iterable, i18n.TypeIndexedIteration, iterableType)
+			}
+			return g.generateIteratorLoop(s, builder, indentLevel)
+		}
+		collection, isCollection := iterableType.(*types.CollectionType)
+		if isCollection && s.IndexName != "" {
+			return newGenerationErrorAt(s.Iterable, i18n.TypeIndexedIteration, iterableType)
+		}
+		if call, ok := g.rangeCall(s.Iterable); ok && s.IndexName == "" {
+			return g.generateRangeLoop(s, call, builder, indentLevel)
+		}
+		if channel, ok := iterableType.(*types.ChannelType); ok {
+			if s.IndexName != "" {
+				return newGenerationErrorAt(s.Iterable, i18n.TypeIndexedIteration, iterableType)
+			}
+			// The loop ends when the channel is closed
+			builder.WriteString(indent(indentLevel) + rangeClause("", s.VarName))
+			if err := g.generateExpression(s.Iterable, builder); err != nil {
+				return err
+			}
+			g.registerVariableWithType(s.VarName, channel.ElementType)
+			builder.WriteString(" ")
+			if err := g.generateBlock(s.Body, builder, indentLevel); err != nil {
+				return err
+			}
+			builder.WriteString("\n")
+			return nil
+		}
 		// s は *ast.ForStatement 型としてバインドされるので、そのまま利用
 		builder.WriteString(indent(indentLevel))
 		// Zeno の for-in を Go の range ループに変換
-		builder.WriteString("for _, " + s.VarName + " := range ")
+		index := "_"
+		if s.IndexName != "" {
+			index = s.IndexName
+		}
+		builder.WriteString(rangeClause(index, s.VarName))
 		if err := g.generateExpression(s.Iterable, builder); err != nil {
 			return err
 		}
+		if isCollection {
+			builder.WriteString(collectionLoopSource(collection))
+			g.registerVariableWithType(s.VarName, collection.Element())
+		}
+		if array, ok := iterableType.(*types.ArrayType); ok && array.ElementType != nil {
+			if s.IndexName != "" {
+				g.registerVariableWithType(s.IndexName, types.IntType)
+			}
+			g.registerVariableWithType(s.VarName, array.ElementType)
+		}
+		if iterableType == types.StringType {
+			// Ranging over a Go string gives its runes
+			g.registerVariableWithType(s.VarName, types.CharType)
+		}
 		builder.WriteString(" ")
 		if err := g.generateBlock(s.Body, builder, indentLevel); err != nil {
 			return err
 		}
 		builder.WriteString("\n")
 	default:
-		return GenerationError{Message: fmt.Sprintf("Unsupported statement type: %T", stmt)}
+		return newGenerationErrorAt(stmt, i18n.GenUnsupportedStatement, stmt)
+	}
+	return nil
+}
+
+// rangeClause returns the start of a Go for loop ranging over a value, with
+// the variables index, if not empty, and value. Go does not declare _ with
+// :=, so a loop using neither ranges without variables.
+func rangeClause(index, value string) string {
+	switch {
+	case (index == "" || index == "_") && value == "_":
+		return "for range "
+	case index == "":
+		return "for " + value + " := range "
+	}
+	return "for " + index + ", " + value + " := range "
+}
+
+// generateIteratorLoop generates a for-in loop over a std/iter iterator. The
+// iterator is advanced lazily, one element per iteration.
+func (g *Generator) generateIteratorLoop(s *ast.ForStatement, builder *strings.Builder, indentLevel int) error {
+	builder.WriteString(indent(indentLevel))
+	builder.WriteString("for zenoIt := ")
+	if err := g.generateExpression(s.Iterable, builder); err != nil {
+		return err
+	}
+	builder.WriteString("; zenoIt.hasNext(); {\n")
+	builder.WriteString(indent(indentLevel + 1))
+	if s.VarName == "_" {
+		builder.WriteString("zenoIt.next()\n")
+	} else {
+		builder.WriteString(s.VarName + " := zenoIt.next()\n")
+	}
+	if s.Body != nil {
+		for _, stmt := range s.Body.Statements {
+			if err := g.generateStatement(stmt, builder, indentLevel+1); err != nil {
+				return err
+			}
+		}
+	}
+	builder.WriteString(indent(indentLevel))
+	builder.WriteString("}\n")
+	return nil
+}
+
+// generatePromoted generates expr, converted to float64 if promote is set
+func (g *Generator) generatePromoted(expr ast.Expression, promote bool, builder *strings.Builder) error {
+	if !promote {
+		return g.generateExpression(expr, builder)
 	}
+	builder.WriteString("float64(")
+	if err := g.generateExpression(expr, builder); err != nil {
+		return err
+	}
+	builder.WriteString(")")
 	return nil
 }
 
 func (g *Generator) generateExpression(expr ast.Expression, builder *strings.Builder) error {
 	switch e := expr.(type) {
 	case *ast.IntegerLiteral:
-		builder.WriteString(strconv.Itoa(e.Value))
+		generateIntegerLiteral(e, builder)
 	case *ast.StringLiteral:
 		escaped := strconv.Quote(e.Value)
 		builder.WriteString(escaped)
+	case *ast.CharLiteral:
+		generateCharLiteral(e, builder)
 	case *ast.FloatLiteral:
-		builder.WriteString(strconv.FormatFloat(e.Value, 'f', -1, 64))
+		literal := strconv.FormatFloat(e.Value, 'f', -1, 64)
+		// 2.0 must stay a float in Go, where 2 is an int
+		if !strings.Contains(literal, ".") {
+			literal += ".0"
+		}
+		builder.WriteString(literal)
 	case *ast.BooleanLiteral:
 		if e.Value {
 			builder.WriteString("true")
@@ -421,19 +1146,66 @@ func (g *Generator) generateExpression(expr ast.Expression, builder *strings.Bui
 			builder.WriteString("false")
 		}
 	case *ast.Identifier:
-		builder.WriteString(e.Value)
-
-	case *ast.MemberExpression:
-		// Generate map or struct field access as index into map
-		if err := g.generateExpression(e.Object, builder); err != nil {
-			return err
+		if decl, variant, ok := g.enumVariant(e.Value); ok {
+			return g.generateVariant(e, decl, variant, nil, builder)
 		}
-		builder.WriteString("[")
-		builder.WriteString(strconv.Quote(e.Property))
-		builder.WriteString("]")
+		if g.isNone(e.Value) {
+			builder.WriteString(g.goOptionType(g.optionConstructorType(e)) + "{}")
+			break
+		}
+		// Functions referenced as values use their Go name
+		if _, isVar := g.symbolTable.Resolve(e.Value); !isVar {
+			if goName, isFn := g.goFunctionName(e.Value); isFn {
+				builder.WriteString(goName)
+				break
+			}
+		}
+		builder.WriteString(e.Value)
+
+	case *ast.MemberExpression:
+		if value, ok, err := g.buildConstant(e); ok {
+			if err != nil {
+				return err
+			}
+			return g.generateExpression(value, builder)
+		}
+		return g.generateMemberAccess(e, builder)
 
+	case *ast.TupleLiteral:
+		// A tuple is written as the Go multiple values it is returned or
+		// destructured as: return a, b
+		expected, _ := g.expected.(*types.TupleType)
+		for i, el := range e.Elements {
+			if i > 0 {
+				builder.WriteString(", ")
+			}
+			var elementType types.Type
+			if expected != nil && i < len(expected.Elements) {
+				elementType = expected.Elements[i]
+			}
+			restore := g.expect(elementType)
+			err := g.generateExpression(el, builder)
+			restore()
+			if err != nil {
+				return err
+			}
+		}
 	case *ast.ArrayLiteral:
-		if len(e.Elements) == 0 {
+		// An array stored where a []T is expected, e.g. by let xs: []float,
+		// takes that type so that its elements convert
+		if expected, ok := g.expected.(*types.ArrayType); ok && expected.ElementType != nil {
+			builder.WriteString("[]" + g.goValueType(expected.ElementType) + "{")
+			defer g.expect(expected.ElementType)()
+			for i, elem := range e.Elements {
+				if i > 0 {
+					builder.WriteString(", ")
+				}
+				if err := g.generateExpression(elem, builder); err != nil {
+					return err
+				}
+			}
+			builder.WriteString("}")
+		} else if len(e.Elements) == 0 {
 			builder.WriteString("[]interface{}{}") // Default for empty array
 		} else {
 			// Determine element type based on the first element, as parser ensures homogeneity for primitives.
@@ -474,7 +1246,7 @@ func (g *Generator) generateExpression(expr ast.Expression, builder *strings.Bui
 				keyString = k.Value
 			default:
 				// Should not happen if parser validation is correct
-				return GenerationError{Message: fmt.Sprintf("unsupported map key type: %T", k)}
+				return newGenerationErrorAt(k, i18n.GenUnsupportedMapKey, k)
 			}
 			builder.WriteString(fmt.Sprintf("\"%s\": ", keyString))
 
@@ -486,6 +1258,12 @@ func (g *Generator) generateExpression(expr ast.Expression, builder *strings.Bui
 		}
 		builder.WriteString("}")
 	case *ast.UnaryExpression:
+		if e.Operator == ast.UnaryOpMinus {
+			g.constrain(operatorConstraint(ast.BinaryOpMinus), e.Right)
+			if g.checksOverflow() && g.inferType(e.Right) == types.IntType && !literalInt(e.Right) {
+				return g.generateChecked("zenoCheckedNeg", builder, e.Right)
+			}
+		}
 		builder.WriteString("(")
 		builder.WriteString(e.Operator.String())
 		if err := g.generateExpression(e.Right, builder); err != nil {
@@ -493,21 +1271,43 @@ func (g *Generator) generateExpression(expr ast.Expression, builder *strings.Bui
 		}
 		builder.WriteString(")")
 	case *ast.BinaryExpression:
+		if method, ok := g.operatorMethod(e); ok {
+			return g.generateOperatorCall(e, method, builder)
+		}
+		if e.Operator.IntegerOnly() {
+			// Go has no %, bitwise operators or shifts on floats
+			left, right := g.inferType(e.Left), g.inferType(e.Right)
+			if left == types.FloatType || right == types.FloatType {
+				return newGenerationErrorAt(e, i18n.TypeInvalidOperands, e.Operator, left, right)
+			}
+		}
+		g.constrain(operatorConstraint(e.Operator), e.Left, e.Right)
+		if helper, ok := g.checkedOperator(e); ok {
+			return g.generateChecked(helper, builder, e.Left, e.Right)
+		}
+		// An int mixed with a float is promoted to float, which Go only
+		// does for constants
+		left, right := g.inferType(e.Left), g.inferType(e.Right)
+		promoteLeft := left == types.IntType && right == types.FloatType
+		promoteRight := left == types.FloatType && right == types.IntType
 		builder.WriteString("(")
-		if err := g.generateExpression(e.Left, builder); err != nil {
+		if err := g.generatePromoted(e.Left, promoteLeft, builder); err != nil {
 			return err
 		}
 		builder.WriteString(" ")
 		builder.WriteString(e.Operator.String())
 		builder.WriteString(" ")
-		if err := g.generateExpression(e.Right, builder); err != nil {
+		if err := g.generatePromoted(e.Right, promoteRight, builder); err != nil {
 			return err
 		}
 		builder.WriteString(")")
 	case *ast.FunctionCall:
+		if decl, variant, ok := g.enumVariant(e.Name); ok {
+			return g.generateVariant(e, decl, variant, e.Arguments, builder)
+		}
 		// Check if function is imported first, before special-casing
 		var functionName string
-		if goName, exists := g.declaredFns[e.Name]; exists {
+		if goName, exists := g.goFunctionName(e.Name); exists {
 			functionName = goName
 		} else {
 			// Special-case Zeno print and println only if not imported
@@ -517,7 +1317,7 @@ func (g *Generator) generateExpression(expr ast.Expression, builder *strings.Bui
 					if i > 0 {
 						builder.WriteString(", ")
 					}
-					if err := g.generateExpression(arg, builder); err != nil {
+					if err := g.generateAny(arg, builder); err != nil {
 						return err
 					}
 				}
@@ -530,51 +1330,175 @@ func (g *Generator) generateExpression(expr ast.Expression, builder *strings.Bui
 					if i > 0 {
 						builder.WriteString(", ")
 					}
-					if err := g.generateExpression(arg, builder); err != nil {
+					if err := g.generateAny(arg, builder); err != nil {
 						return err
 					}
 				}
 				builder.WriteString(")")
 				return nil
 			}
+			if b, ok := g.lookupBuiltin(e.Name); ok {
+				return g.generateBuiltinCall(b, e, builder)
+			}
 			functionName = e.Name
 		}
 		if err := g.validateImports(e.Name); err != nil {
+			return locate(err, e)
+		}
+		if err := g.validateCallArguments(e); err != nil {
 			return err
 		}
+		if def := g.findFunctionDefinition(e.Name); def != nil {
+			g.warnIfDeprecated(e, i18n.GenWarnDeprecatedFunction, e.Name, def.Deprecated)
+		}
 		builder.WriteString(functionName)
+		if len(e.TypeArguments) > 0 {
+			typeArgs := make([]string, len(e.TypeArguments))
+			for i, typeArg := range e.TypeArguments {
+				typeArgs[i] = mapType(typeArg)
+			}
+			builder.WriteString("[" + strings.Join(typeArgs, ", ") + "]")
+		}
 		builder.WriteString("(")
 		// generate arguments
+		def := g.findFunctionDefinition(e.Name)
+		var typeArgs []string
+		if def != nil {
+			typeArgs = g.callTypeArguments(def, e)
+		}
 		for i, arg := range e.Arguments {
 			if i > 0 {
 				builder.WriteString(", ")
 			}
-			if err := g.generateExpression(arg, builder); err != nil {
+			if err := g.generateArgument(def, typeArgs, i, arg, builder); err != nil {
 				return err
 			}
 		}
 		builder.WriteString(")")
 	case *ast.StructLiteral:
-		// Generate struct literal as map[string]interface{}
-		builder.WriteString("map[string]interface{}{")
-		count := 0
-		for fieldName, valueExpr := range e.Fields {
-			if count > 0 {
-				builder.WriteString(", ")
-			}
-			builder.WriteString(fmt.Sprintf("\"%s\": ", fieldName))
-			if err := g.generateExpression(valueExpr, builder); err != nil {
-				return err
-			}
-			count++
+		return g.generateStructLiteral(e, builder)
+	case *ast.MatchExpression:
+		return g.generateMatchExpression(e, builder)
+	case *ast.IfExpression:
+		return g.generateIfExpression(e, builder)
+	case *ast.TryExpression:
+		value, checked := g.tryValues[e]
+		if !checked {
+			return newGenerationErrorAt(e, i18n.GenTryPosition)
 		}
-		builder.WriteString("}")
+		builder.WriteString(value)
+	case *ast.IndexExpression:
+		return g.generateIndexExpression(e, builder)
+	case *ast.MethodCallExpression:
+		return g.generateMethodCall(e, builder, false)
+	case *ast.SliceExpression:
+		return g.generateSliceExpression(e, builder)
 	default:
-		return GenerationError{Message: fmt.Sprintf("Unsupported expression type: %T", expr)}
+		return newGenerationErrorAt(expr, i18n.GenUnsupportedExpression, expr)
+	}
+	return nil
+}
+
+// collectFunctionBody collects the declarations of the body of a function,
+// with its parameters in scope
+func (g *Generator) collectFunctionBody(s *ast.FunctionDefinition) error {
+	endScope := g.enterScope()
+	for _, param := range s.Parameters {
+		g.registerVariableWithType(param.Name, g.mapASTTypeToType(param.Type))
+	}
+	for _, bodyStmt := range s.Body {
+		if err := g.collectImportsAndDeclarations(bodyStmt); err != nil {
+			return err
+		}
+	}
+	endScope()
+	return nil
+}
+
+// generateFunctionBody writes the opening brace of a function whose
+// signature was written, its body with its parameters in scope and the
+// closing brace. The type parameters of a generic function are inserted at
+// offset typeParamsAt of builder.
+func (g *Generator) generateFunctionBody(s *ast.FunctionDefinition, builder *strings.Builder, indentLevel int, typeParamsAt int) error {
+	builder.WriteString(" {\n")
+	originalSymbolTable := g.symbolTable
+	originalFunction := g.currentFunction
+	originalReturnType := g.returnType
+	originalConstraints := g.constraints
+	g.symbolTable = types.NewSymbolTable(originalSymbolTable)
+	g.currentFunction = s.Name
+	g.returnType = nil
+	g.constraints = nil
+	if len(s.Generics) > 0 {
+		g.constraints = make(map[string]int)
+		for _, param := range s.Generics {
+			g.constraints[param] = 0
+		}
 	}
+	if s.ReturnType != nil {
+		g.returnType = g.mapASTTypeToType(*s.ReturnType)
+	}
+	for _, param := range s.Parameters {
+		paramType := g.mapASTTypeToType(param.Type)
+		g.symbolTable.Define(param.Name, paramType)
+	}
+	var err error
+	for _, bodyStmt := range s.Body {
+		if err = g.generateStatement(bodyStmt, builder, indentLevel+1); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		g.insertTypeParameters(s, builder, typeParamsAt)
+	}
+	g.symbolTable = originalSymbolTable
+	g.currentFunction = originalFunction
+	g.returnType = originalReturnType
+	g.constraints = originalConstraints
+	if err != nil {
+		return err
+	}
+	builder.WriteString(indent(indentLevel))
+	builder.WriteString("}\n")
 	return nil
 }
 
+// generateArgument writes the i-th argument of a call to def, which
+// creates a value of the type of its parameter when it calls ok, err, some
+// or none. The type parameters of a generic def are instantiated with
+// typeArgs, and left to Go when those are not known.
+func (g *Generator) generateArgument(def *ast.FunctionDefinition, typeArgs []string, i int, arg ast.Expression, builder *strings.Builder) error {
+	var expected types.Type
+	if def != nil && len(def.Parameters) > 0 {
+		// Variadic parameters are written with the type of their elements
+		param := def.Parameters[min(i, len(def.Parameters)-1)]
+		if len(typeArgs) > 0 || !usesTypeParameter(def, param.Type) {
+			expected = g.mapASTTypeToType(def.Instantiate(param.Type, typeArgs))
+		}
+	}
+	defer g.expect(expected)()
+	if expected == types.AnyType {
+		return g.generateAny(arg, builder)
+	}
+	return g.generateExpression(arg, builder)
+}
+
+// usesTypeParameter reports whether typeName refers to a type parameter of
+// def, whose type is only known from the arguments of a call
+func usesTypeParameter(def *ast.FunctionDefinition, typeName string) bool {
+	words := strings.FieldsFunc(typeName, func(r rune) bool {
+		return r == '[' || r == ']' || r == '<' || r == '>' || r == ','
+	})
+	for _, word := range words {
+		for _, param := range def.Generics {
+			if word == param {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (g *Generator) generateCondition(expr ast.Expression, builder *strings.Builder) error {
 	// ... (content remains the same as fetched in Turn 61) ...
 	switch e := expr.(type) {
@@ -583,6 +1507,7 @@ func (g *Generator) generateCondition(expr ast.Expression, builder *strings.Buil
 	case *ast.BinaryExpression:
 		return g.generateExpression(expr, builder)
 	case *ast.IntegerLiteral:
+		g.addWarning(e, i18n.GenWarnImplicitBoolConversion, types.IntType, e.String())
 		builder.WriteString("(")
 		if err := g.generateExpression(expr, builder); err != nil {
 			return err
@@ -592,6 +1517,12 @@ func (g *Generator) generateCondition(expr ast.Expression, builder *strings.Buil
 	case *ast.Identifier:
 		varType := g.getVariableType(e.Value)
 		// fmt.Printf("DEBUG: Variable %s has type %v\n", e.Value, varType)
+		if types.IsInteger(varType) || varType == types.StringType || varType == types.FloatType {
+			g.addWarning(e, i18n.GenWarnImplicitBoolConversion, varType, e.Value)
+		}
+		if types.IsSized(varType) {
+			varType = types.IntType
+		}
 		switch varType {
 		case types.BoolType:
 			return g.generateExpression(expr, builder)
@@ -630,6 +1561,7 @@ func (g *Generator) generateBlock(block *ast.Block, builder *strings.Builder, in
 		builder.WriteString("{}\n")
 		return nil
 	}
+	defer g.enterScope()()
 	builder.WriteString("{\n")
 	for _, stmt := range block.Statements {
 		if err := g.generateStatement(stmt, builder, indentLevel+1); err != nil {
@@ -641,140 +1573,492 @@ func (g *Generator) generateBlock(block *ast.Block, builder *strings.Builder, in
 	return nil
 }
 
+// importItems imports items from module. The functions of a namespace
+// import are known by their alias only.
+func (g *Generator) importItems(module string, items []ast.ImportItem, namespace bool) error {
+	// 関数インポートと型インポートを分離
+	var names []string
+	var typeNames []string
+	// The functions imported under their own name; the others are
+	// only known by their alias
+	var plainNames []string
+	previous := make(map[string]string)
+	for _, imp := range items {
+		if imp.IsType {
+			typeNames = append(typeNames, imp.Name)
+			continue
+		}
+		names = append(names, imp.Name)
+		if imp.Alias == "" {
+			plainNames = append(plainNames, imp.Name)
+		} else if goName, declared := g.declaredFns[imp.Name]; declared {
+			previous[imp.Name] = goName
+		}
+	}
+	// Several import statements may name the same module
+	g.imports[module] = append(g.imports[module], plainNames...)
+	if len(typeNames) > 0 {
+		g.importTypes[module] = append(g.importTypes[module], typeNames...)
+	}
+	if strings.HasPrefix(module, "std/") {
+		if err := g.processStdModule(module, names, typeNames); err != nil {
+			return err
+		}
+	} else if strings.HasPrefix(module, "./") || strings.HasPrefix(module, "../") || deps.IsRemote(module) {
+		if err := g.processUserModule(module, names, typeNames); err != nil {
+			return err
+		}
+	}
+	for _, imp := range items {
+		if imp.IsType || imp.Alias == "" {
+			continue
+		}
+		g.declaredFns[imp.Alias] = g.declaredFns[imp.Name]
+		g.aliases[imp.Alias] = importedFunction{module: module, name: imp.Name, namespace: namespace}
+		if containsString(plainNames, imp.Name) {
+			continue
+		}
+		if goName, declared := previous[imp.Name]; declared {
+			g.declaredFns[imp.Name] = goName
+		} else {
+			delete(g.declaredFns, imp.Name)
+		}
+	}
+	return nil
+}
+
+// resolveImport follows the pub imports re-exporting name from the user
+// module at path to the module declaring it, and returns that module with
+// the name declared there. Modules that cannot be read are reported when
+// they are processed.
+func (g *Generator) resolveImport(path, name string, isType bool) (string, string) {
+	seen := map[string]bool{path: true}
+	for !strings.HasPrefix(path, "std/") {
+		program, _, err := g.readUserModule(path)
+		if err != nil {
+			break
+		}
+		reexport, item, ok := program.Reexport(name, isType)
+		if !ok {
+			break
+		}
+		next := reexport.ModulePath(path)
+		if seen[next] {
+			break
+		}
+		seen[next] = true
+		path, name = next, item.Name
+	}
+	return path, name
+}
+
+// importedFunction is a public function of an imported module
+type importedFunction struct {
+	module string
+	name   string
+	// namespace is set for the functions of import * as
+	namespace bool
+}
+
 func (g *Generator) collectImportsAndDeclarations(stmt ast.Statement) error {
 	// ... (content remains the same as fetched in Turn 61) ...
 	switch s := stmt.(type) {
 	case *ast.ImportStatement:
-		// 関数インポートと型インポートを分離
-		var names []string
-		var typeNames []string
-		for _, imp := range s.Imports {
-			if imp.IsType {
-				typeNames = append(typeNames, imp.Name)
-			} else {
-				names = append(names, imp.Name)
+		items := s.Imports
+		if s.Namespace != "" {
+			// The functions of import * as io are imported as io.readFile
+			exports, err := g.moduleExports(s.Module)
+			if err != nil {
+				return err
+			}
+			items = nil
+			for _, name := range exports {
+				items = append(items, ast.ImportItem{Name: name, Alias: s.Namespace + "." + name})
 			}
+			g.namespaces[s.Namespace] = s.Module
 		}
-		g.imports[s.Module] = names
-		if len(typeNames) > 0 {
-			g.importTypes[s.Module] = typeNames
+		// Re-exported items are imported from the module declaring them
+		modules := []string{s.Module}
+		moduleItems := make(map[string][]ast.ImportItem)
+		for _, item := range items {
+			module, name := g.resolveImport(s.Module, item.Name, item.IsType)
+			if name != item.Name {
+				item.Alias, item.Name = item.LocalName(), name
+				if item.Alias == name {
+					item.Alias = ""
+				}
+			}
+			if _, ok := moduleItems[module]; !ok && module != s.Module {
+				modules = append(modules, module)
+			}
+			moduleItems[module] = append(moduleItems[module], item)
 		}
-		if strings.HasPrefix(s.Module, "std/") {
-			if err := g.processStdModule(s.Module, names, typeNames); err != nil {
-				return err
+		for _, module := range modules {
+			if err := g.importItems(module, moduleItems[module], s.Namespace != ""); err != nil {
+				return locate(err, s)
 			}
-		} else if strings.HasPrefix(s.Module, "./") || strings.HasPrefix(s.Module, "../") {
-			if err := g.processUserModule(s.Module, names); err != nil {
+		}
+	case *ast.LetDeclaration:
+		// The value is read before the names are declared: in
+		// let x = x + 1, x is the variable of an outer scope
+		if s.ValueExpression != nil {
+			if err := g.markVariableUsage(s.ValueExpression); err != nil {
 				return err
 			}
 		}
-	case *ast.LetDeclaration:
-		g.declaredVars[s.Name] = true
-		var varType types.Type
-		if s.TypeAnn != nil {
-			varType = g.mapASTTypeToType(*s.TypeAnn)
+		if s.Names != nil {
+			for i, varType := range g.destructuredTypes(s) {
+				if s.Names[i] != "_" {
+					g.declareVariable(s, s.Names[i], varType)
+				}
+			}
 		} else {
-			varType = g.inferType(s.ValueExpression)
-		}
-		g.registerVariableWithType(s.Name, varType)
-		if s.ValueExpression != nil {
-			g.markVariableUsage(s.ValueExpression)
+			var varType types.Type
+			if s.TypeAnn != nil {
+				varType = g.mapASTTypeToType(*s.TypeAnn)
+			} else {
+				varType = g.inferType(s.ValueExpression)
+			}
+			g.declareVariable(s, s.Name, varType)
 		}
 	case *ast.AssignmentStatement:
-		g.usedVars[s.Name] = true
-		g.markVariableUsage(s.Value)
+		if err := g.useVariable(s.Name); err != nil {
+			return err
+		}
+		if err := g.markVariableUsage(s.Value); err != nil {
+			return err
+		}
 	case *ast.FunctionDefinition:
 		goFuncName := s.Name
 		if s.IsPublic {
-			if len(goFuncName) > 0 {
-				goFuncName = strings.ToUpper(s.Name[:1]) + s.Name[1:]
-			}
+			goFuncName = exportedName(goFuncName)
 		}
 		g.declaredFns[s.Name] = goFuncName
-		for _, bodyStmt := range s.Body {
-			g.collectImportsAndDeclarations(bodyStmt)
+		if err := g.collectFunctionBody(s); err != nil {
+			return err
+		}
+	case *ast.ImplDeclaration:
+		for _, method := range s.Methods {
+			if err := g.collectFunctionBody(method); err != nil {
+				return err
+			}
 		}
 	case *ast.ReturnStatement:
 		if s.Value != nil {
-			g.markVariableUsage(s.Value)
+			if err := g.markVariableUsage(s.Value); err != nil {
+				return err
+			}
 		}
 	case *ast.ExpressionStatement:
-		g.markVariableUsage(s.Expression)
+		if err := g.markVariableUsage(s.Expression); err != nil {
+			return err
+		}
 	case *ast.IfStatement:
-		g.markVariableUsage(s.Condition)
+		if err := g.markVariableUsage(s.Condition); err != nil {
+			return err
+		}
 		if s.ThenBlock != nil {
-			g.markBlockUsage(s.ThenBlock)
+			if err := g.markBlockUsage(s.ThenBlock); err != nil {
+				return err
+			}
 		}
 		for _, elseIf := range s.ElseIfClauses {
-			g.markVariableUsage(elseIf.Condition)
+			if err := g.markVariableUsage(elseIf.Condition); err != nil {
+				return err
+			}
 			if elseIf.Block != nil {
-				g.markBlockUsage(elseIf.Block)
+				if err := g.markBlockUsage(elseIf.Block); err != nil {
+					return err
+				}
 			}
 		}
 		if s.ElseBlock != nil {
-			g.markBlockUsage(s.ElseBlock)
+			if err := g.markBlockUsage(s.ElseBlock); err != nil {
+				return err
+			}
 		}
 	case *ast.WhileStatement:
-		g.markVariableUsage(s.Condition)
+		if err := g.markVariableUsage(s.Condition); err != nil {
+			return err
+		}
 		if s.Block != nil {
-			g.markBlockUsage(s.Block)
+			if err := g.markBlockUsage(s.Block); err != nil {
+				return err
+			}
+		}
+	case *ast.ForStatement:
+		if err := g.markVariableUsage(s.Iterable); err != nil {
+			return err
+		}
+		endScope := g.enterScope()
+		// Loop variables are not reported when unused
+		if s.IndexName != "" {
+			g.registerVariableWithType(s.IndexName, types.IntType)
+		}
+		var element types.Type = types.AnyType
+		switch t := g.inferType(s.Iterable).(type) {
+		case *types.ArrayType:
+			if t.ElementType != nil {
+				element = t.ElementType
+			}
+		case *types.ChannelType:
+			element = t.ElementType
+		}
+		g.registerVariableWithType(s.VarName, element)
+		if err := g.markBlockUsage(s.Body); err != nil {
+			return err
+		}
+		endScope()
+	case *ast.LoopStatement:
+		if err := g.markBlockUsage(s.Body); err != nil {
+			return err
+		}
+	case *ast.SpawnStatement:
+		if err := g.markBlockUsage(&ast.Block{Statements: s.Statements()}); err != nil {
+			return err
+		}
+	case *ast.TryStatement:
+		if err := g.markBlockUsage(s.Body); err != nil {
+			return err
+		}
+		endScope := g.enterScope()
+		if s.CatchName != "" {
+			g.registerVariableWithType(s.CatchName, types.StringType)
+		}
+		if err := g.markBlockUsage(s.Catch); err != nil {
+			return err
+		}
+		endScope()
+	case *ast.WhenStatement:
+		for i := range s.Arms {
+			arm := &s.Arms[i]
+			endScope := g.enterScope()
+			if !arm.IsDefault() {
+				if err := g.markVariableUsage(arm.Channel); err != nil {
+					return err
+				}
+				if arm.Sent != nil {
+					if err := g.markVariableUsage(arm.Sent); err != nil {
+						return err
+					}
+				}
+			}
+			if arm.Name != "" {
+				var element types.Type = types.AnyType
+				if channel, ok := g.inferType(arm.Channel).(*types.ChannelType); ok {
+					element = channel.ElementType
+				}
+				g.registerVariableWithType(arm.Name, element)
+			}
+			if arm.Block != nil {
+				if err := g.markBlockUsage(arm.Block); err != nil {
+					return err
+				}
+			} else {
+				if err := g.markVariableUsage(arm.Value); err != nil {
+					return err
+				}
+			}
+			endScope()
 		}
 	}
 	return nil
 }
 
-func (g *Generator) markVariableUsage(expr ast.Expression) {
+func (g *Generator) markVariableUsage(expr ast.Expression) error {
 	// ... (content remains the same as fetched in Turn 61) ...
 	switch e := expr.(type) {
 	case *ast.Identifier:
-		g.usedVars[e.Value] = true
+		if err := g.useVariable(e.Value); err != nil {
+			return err
+		}
+		// A function passed as a value, e.g. map(it, double)
+		g.usedFns[e.Value] = true
 	case *ast.BooleanLiteral, *ast.IntegerLiteral, *ast.StringLiteral:
 		// No action needed
 	case *ast.BinaryExpression:
-		g.markVariableUsage(e.Left)
-		g.markVariableUsage(e.Right)
+		if err := g.markVariableUsage(e.Left); err != nil {
+			return err
+		}
+		if err := g.markVariableUsage(e.Right); err != nil {
+			return err
+		}
 	case *ast.UnaryExpression:
-		g.markVariableUsage(e.Right)
+		if err := g.markVariableUsage(e.Right); err != nil {
+			return err
+		}
+	case *ast.TryExpression:
+		if err := g.markVariableUsage(e.Value); err != nil {
+			return err
+		}
+	case *ast.TupleLiteral:
+		for _, el := range e.Elements {
+			if err := g.markVariableUsage(el); err != nil {
+				return err
+			}
+		}
+	case *ast.ArrayLiteral:
+		for _, el := range e.Elements {
+			if err := g.markVariableUsage(el); err != nil {
+				return err
+			}
+		}
+	case *ast.MapLiteral:
+		for _, key := range e.Keys {
+			if err := g.markVariableUsage(key); err != nil {
+				return err
+			}
+			if err := g.markVariableUsage(e.Pairs[key]); err != nil {
+				return err
+			}
+		}
+	case *ast.StructLiteral:
+		for _, name := range e.FieldNames {
+			if err := g.markVariableUsage(e.Fields[name]); err != nil {
+				return err
+			}
+		}
+	case *ast.IndexExpression:
+		if err := g.markVariableUsage(e.Left); err != nil {
+			return err
+		}
+		if err := g.markVariableUsage(e.Index); err != nil {
+			return err
+		}
+	case *ast.SliceExpression:
+		if err := g.markVariableUsage(e.Left); err != nil {
+			return err
+		}
+		if e.Low != nil {
+			if err := g.markVariableUsage(e.Low); err != nil {
+				return err
+			}
+		}
+		if e.High != nil {
+			if err := g.markVariableUsage(e.High); err != nil {
+				return err
+			}
+		}
 	case *ast.FunctionCall:
 		g.usedFns[e.Name] = true
 		for _, arg := range e.Arguments {
-			g.markVariableUsage(arg)
+			if err := g.markVariableUsage(arg); err != nil {
+				return err
+			}
+		}
+	case *ast.MethodCallExpression:
+		if call, ok := g.namespaceCall(e); ok {
+			if err := g.markVariableUsage(call); err != nil {
+				return err
+			}
+			break
+		}
+		if err := g.markVariableUsage(e.Object); err != nil {
+			return err
+		}
+		for _, arg := range e.Arguments {
+			if err := g.markVariableUsage(arg); err != nil {
+				return err
+			}
 		}
 	case *ast.MemberExpression:
 		// Mark the object variable as used
-		g.markVariableUsage(e.Object)
-	}
-}
-
-func (g *Generator) markBlockUsage(block *ast.Block) {
-	// ... (content remains the same as fetched in Turn 61) ...
-	if block == nil {
-		return
+		if err := g.markVariableUsage(e.Object); err != nil {
+			return err
+		}
+	case *ast.MatchExpression:
+		if err := g.markVariableUsage(e.Subject); err != nil {
+			return err
+		}
+		for i := range e.Arms {
+			arm := &e.Arms[i]
+			endScope := g.enterScope()
+			g.registerArmBindings(e, arm)
+			if !arm.IsWildcard() {
+				if err := g.markVariableUsage(arm.Pattern); err != nil {
+					return err
+				}
+			}
+			if arm.Block != nil {
+				if err := g.markBlockUsage(arm.Block); err != nil {
+					return err
+				}
+			} else {
+				if err := g.markVariableUsage(arm.Value); err != nil {
+					return err
+				}
+			}
+			endScope()
+		}
+	case *ast.IfExpression:
+		if err := g.markVariableUsage(e.Condition); err != nil {
+			return err
+		}
+		for _, elseIf := range e.ElseIfClauses {
+			if err := g.markVariableUsage(elseIf.Condition); err != nil {
+				return err
+			}
+		}
+		for _, block := range e.Blocks() {
+			if err := g.markBlockUsage(block); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *Generator) markBlockUsage(block *ast.Block) error {
+	// ... (content remains the same as fetched in Turn 61) ...
+	if block == nil {
+		return nil
 	}
+	defer g.enterScope()()
 	for _, stmt := range block.Statements {
-		g.collectImportsAndDeclarations(stmt)
+		if err := g.collectImportsAndDeclarations(stmt); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func (g *Generator) checkUnusedVariables() error {
-	// ... (content remains the same as fetched in Turn 61) ...
-	var unusedVars []string
-	for varName := range g.declaredVars {
-		if !g.usedVars[varName] {
-			unusedVars = append(unusedVars, varName)
+// unusedVariables returns the variables declared with let that are never
+// read
+func (g *Generator) unusedVariables() []variableDeclaration {
+	var unused []variableDeclaration
+	for _, v := range g.declaredVars {
+		if !g.usedVars[v.symbol] {
+			unused = append(unused, v)
 		}
 	}
-	if len(unusedVars) > 0 {
-		return GenerationError{Message: fmt.Sprintf("Unused variables found: %s", strings.Join(unusedVars, ", "))}
+	return unused
+}
+
+// checkUnusedVariables reports the unused variables whose names do not
+// start with _ as errors or warnings, as the strictness says
+func (g *Generator) checkUnusedVariables(unused []variableDeclaration) GenerationErrors {
+	var errs GenerationErrors
+	for _, v := range unused {
+		name := v.symbol.Name
+		if strings.HasPrefix(name, "_") {
+			continue
+		}
+		switch g.options.Strictness {
+		case StrictnessError:
+			errs = append(errs, newGenerationErrorAt(v.let, i18n.GenUnusedVariable, name))
+		case StrictnessWarn:
+			g.addWarning(v.let, i18n.GenUnusedVariable, name)
+		}
 	}
-	return nil
+	return errs
 }
 
-func (g *Generator) checkUnusedFunctions() error {
-	// ... (content remains the same as fetched in Turn 61) ...
+// checkUnusedFunctions reports the unused private functions whose names do
+// not start with _ as errors or warnings, as the strictness says
+func (g *Generator) checkUnusedFunctions() GenerationErrors {
 	var unusedFns []string
 	for fnName := range g.declaredFns {
-		if fnName == "main" {
+		if fnName == "main" || strings.HasPrefix(fnName, "_") {
 			continue
 		}
 		if g.isPublicFunction(fnName) {
@@ -784,27 +2068,55 @@ func (g *Generator) checkUnusedFunctions() error {
 			unusedFns = append(unusedFns, fnName)
 		}
 	}
-	if len(unusedFns) > 0 {
-		return GenerationError{Message: fmt.Sprintf("Unused functions found: %s", strings.Join(unusedFns, ", "))}
+	sort.Strings(unusedFns)
+	var errs GenerationErrors
+	for _, fnName := range unusedFns {
+		fn := g.findFunctionDefinition(fnName)
+		switch g.options.Strictness {
+		case StrictnessError:
+			err := newGenerationError(i18n.GenUnusedFunction, fnName)
+			if fn != nil {
+				err.Pos = fn.Pos()
+			}
+			errs = append(errs, err)
+		case StrictnessWarn:
+			if fn != nil {
+				g.addWarning(fn, i18n.GenUnusedFunction, fnName)
+			}
+		}
 	}
-	return nil
+	return errs
+}
+
+// goFunctionName returns the Go name of a declared or imported function and
+// records the use of the user package it belongs to
+func (g *Generator) goFunctionName(name string) (string, bool) {
+	goName, ok := g.declaredFns[name]
+	if alias, _, qualified := strings.Cut(goName, "."); ok && qualified {
+		g.usedPackages[alias] = true
+	}
+	return goName, ok
 }
 
 func (g *Generator) isPublicFunction(fnName string) bool {
 	// ... (content remains the same as fetched in Turn 61) ...
 	if goFuncName, exists := g.declaredFns[fnName]; exists {
-		if len(goFuncName) == 0 {
-			return false
-		}
-		return goFuncName[0] >= 'A' && goFuncName[0] <= 'Z'
+		// Functions of user packages are qualified, e.g. zeno_utils.Add
+		goFuncName = goFuncName[strings.LastIndex(goFuncName, ".")+1:]
+		first, _ := utf8.DecodeRuneInString(goFuncName)
+		return unicode.IsUpper(first)
 	}
 	return false
 }
 
 func (g *Generator) validateImports(functionName string) error {
 	// ... (content remains the same as fetched in Turn 61) ...
-	builtinFunctions := map[string]bool{}
-	if builtinFunctions[functionName] {
+	if _, ok := g.lookupBuiltin(functionName); ok {
+		return nil
+	}
+	// Functions of the program and imported ones, under their own name or
+	// another one, shadow the functions of the modules
+	if _, declared := g.declaredFns[functionName]; declared {
 		return nil
 	}
 	for module, functions := range g.standardLibs {
@@ -816,7 +2128,7 @@ func (g *Generator) validateImports(functionName string) error {
 					}
 				}
 			}
-			return GenerationError{Message: fmt.Sprintf("Function '%s' is not imported from '%s'", functionName, module)}
+			return g.withImportSuggestion(newGenerationError(i18n.GenNotImported, functionName, module), functionName, module)
 		}
 	}
 	for module, functions := range g.userModules {
@@ -828,14 +2140,37 @@ func (g *Generator) validateImports(functionName string) error {
 					}
 				}
 			}
-			return GenerationError{Message: fmt.Sprintf("Function '%s' is not imported from '%s'", functionName, module)}
+			return g.withImportSuggestion(newGenerationError(i18n.GenNotImported, functionName, module), functionName, module)
+		}
+	}
+	// The function is defined nowhere; suggest a standard library module that exports it
+	if g.findFunctionDefinition(functionName) == nil {
+		if modules := stdlib.ModulesExporting(functionName); len(modules) > 0 {
+			return g.withImportSuggestion(newGenerationError(i18n.GenMissingImport, functionName), functionName, modules[0])
 		}
 	}
 	return nil
 }
 
-func (g *Generator) processUserModule(modulePath string, importedFunctions []string) error {
-	// ... (content remains the same as fetched in Turn 61) ...
+// withImportSuggestion attaches the import statement that makes functionName
+// available from module. Names already imported from the module are kept in
+// the suggested line.
+func (g *Generator) withImportSuggestion(err GenerationError, functionName, module string) GenerationError {
+	names := append([]string{}, g.imports[module]...)
+	for alias, imported := range g.aliases {
+		if imported.module == module && !imported.namespace {
+			names = append(names, imported.name+" as "+alias)
+		}
+	}
+	sort.Strings(names[len(g.imports[module]):])
+	names = append(names, functionName)
+	err.Suggestion = i18n.T(i18n.GenHintAddImport, stdlib.ImportLine(module, names...))
+	return err
+}
+
+// readUserModule parses the user module imported from modulePath and returns
+// it with the path of its file
+func (g *Generator) readUserModule(modulePath string) (*ast.Program, string, error) {
 	var zenoFilePath string
 	if strings.HasSuffix(modulePath, ".zeno") {
 		zenoFilePath = modulePath
@@ -845,99 +2180,220 @@ func (g *Generator) processUserModule(modulePath string, importedFunctions []str
 	if (strings.HasPrefix(zenoFilePath, "./") || strings.HasPrefix(zenoFilePath, "../")) && g.currentDir != "" {
 		baseDir := filepath.Dir(g.currentDir)
 		zenoFilePath = filepath.Join(baseDir, zenoFilePath)
+	} else if deps.IsRemote(modulePath) {
+		// Remote modules are read from the module cache, at the version
+		// pinned by the lock file of the importing file
+		file, err := deps.Resolve(filepath.Dir(g.currentDir), modulePath)
+		var notFetched *deps.NotFetchedError
+		if errors.As(err, &notFetched) {
+			return nil, modulePath, newGenerationError(i18n.GenModuleNotFetched, modulePath, notFetched.Command())
+		}
+		if err != nil {
+			return nil, modulePath, newGenerationError(i18n.GenModuleReadFailed, modulePath, err)
+		}
+		zenoFilePath = file
 	}
 	content, err := os.ReadFile(zenoFilePath)
 	if err != nil {
-		return GenerationError{Message: fmt.Sprintf("Failed to read module file '%s': %v", zenoFilePath, err)}
+		return nil, zenoFilePath, newGenerationError(i18n.GenModuleReadFailed, zenoFilePath, err)
 	}
 	l := lexer.New(string(content))
 	p := parser.New(l)
 	program := p.ParseProgram()
 	if len(p.Errors()) > 0 {
-		return GenerationError{Message: fmt.Sprintf("Parse errors in module '%s': %v", zenoFilePath, p.Errors())}
+		return nil, zenoFilePath, newGenerationError(i18n.GenModuleParseErrors, zenoFilePath, p.Errors())
+	}
+	return program, zenoFilePath, nil
+}
+
+// moduleExports returns the public functions of a standard library or user
+// module
+func (g *Generator) moduleExports(modulePath string) ([]string, error) {
+	if strings.HasPrefix(modulePath, "std/") {
+		// processStdModule reports a module that cannot be read
+		return stdlib.Exports(modulePath), nil
+	}
+	program, _, err := g.readUserModule(modulePath)
+	if err != nil {
+		return nil, err
+	}
+	return program.Exports(), nil
+}
+
+func (g *Generator) processUserModule(modulePath string, importedFunctions []string, importedTypes []string) error {
+	// ... (content remains the same as fetched in Turn 61) ...
+	program, zenoFilePath, err := g.readUserModule(modulePath)
+	if err != nil {
+		return err
 	}
 	publicFunctions := make(map[string]string)
+	definitions := make(map[string]*ast.FunctionDefinition)
 	for _, stmt := range program.Statements {
 		if funcDef, ok := stmt.(*ast.FunctionDefinition); ok && funcDef.IsPublic {
-			goFuncName := funcDef.Name
-			if len(goFuncName) > 0 {
-				goFuncName = strings.ToUpper(string(goFuncName[0])) + goFuncName[1:]
-			}
-			publicFunctions[funcDef.Name] = goFuncName
+			publicFunctions[funcDef.Name] = exportedName(funcDef.Name)
+			definitions[funcDef.Name] = funcDef
 		}
 	}
 	for _, importedFunc := range importedFunctions {
 		if _, exists := publicFunctions[importedFunc]; !exists {
-			return GenerationError{Message: fmt.Sprintf("Function '%s' is not exported from module '%s'", importedFunc, modulePath)}
+			return newGenerationError(i18n.GenFunctionNotExported, importedFunc, modulePath)
+		}
+	}
+	for _, importedType := range importedTypes {
+		declared := false
+		for _, stmt := range program.Statements {
+			switch decl := stmt.(type) {
+			case *ast.TypeDeclaration:
+				declared = declared || decl.Name == importedType
+			case *ast.EnumDeclaration:
+				declared = declared || decl.Name == importedType
+			}
+		}
+		if !declared {
+			return newGenerationError(i18n.GenTypeNotExported, importedType, modulePath)
+		}
+	}
+	if len(importedFunctions) > 0 || len(importedTypes) > 0 {
+		pkg, err := g.userPackage(zenoFilePath, program)
+		if err != nil {
+			return err
+		}
+		alias := packageAlias(pkg)
+		g.packageImports[alias] = GoModulePath + "/" + pkg.Dir
+		g.modulePackages[modulePath] = alias
+		for _, importedFunc := range importedFunctions {
+			// Add imported function to declaredFns for proper name resolution
+			g.declaredFns[importedFunc] = alias + "." + publicFunctions[importedFunc]
 		}
-		// Add imported function to declaredFns for proper name resolution
-		g.declaredFns[importedFunc] = publicFunctions[importedFunc]
 	}
 	g.userModules[modulePath] = publicFunctions
 	g.moduleASTs[modulePath] = program
+	for _, importedFunc := range importedFunctions {
+		if err := g.importSignatureTypes(modulePath, program, definitions[importedFunc]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importSignatureTypes imports the struct and enum types in the signature
+// of a function imported from the user module at modulePath, so that the
+// values it returns can be used without importing their types, as in
+// origin().x for origin(): Point. A type the module imports is imported
+// from the module declaring it; a name the file declares is left to it.
+func (g *Generator) importSignatureTypes(modulePath string, program *ast.Program, fn *ast.FunctionDefinition) error {
+	signature := make([]string, 0, len(fn.Parameters)+1)
+	for _, param := range fn.Parameters {
+		signature = append(signature, param.Type)
+	}
+	if fn.ReturnType != nil {
+		signature = append(signature, *fn.ReturnType)
+	}
+	for _, typeName := range signature {
+		words := strings.FieldsFunc(typeName, func(r rune) bool {
+			return r == '[' || r == ']' || r == '<' || r == '>' || r == ',' || r == ' ' || r == '(' || r == ')'
+		})
+		for _, name := range words {
+			if containsString(fn.Generics, name) || g.findTypeDeclaration(name) != nil || g.findEnumDeclaration(name) != nil {
+				continue
+			}
+			if module, ok := g.declaringModule(modulePath, program, name); ok {
+				if err := g.importItems(module, []ast.ImportItem{{Name: name, IsType: true}}, false); err != nil {
+					return err
+				}
+			}
+		}
+	}
 	return nil
 }
 
+// declaringModule follows the type imports of the user module at
+// modulePath to the user module declaring the type name
+func (g *Generator) declaringModule(modulePath string, program *ast.Program, name string) (string, bool) {
+	seen := make(map[string]bool)
+	for !seen[modulePath] {
+		seen[modulePath] = true
+		next := ""
+		for _, stmt := range program.Statements {
+			switch s := stmt.(type) {
+			case *ast.TypeDeclaration:
+				if s.Name == name {
+					return modulePath, true
+				}
+			case *ast.EnumDeclaration:
+				if s.Name == name {
+					return modulePath, true
+				}
+			case *ast.ImportStatement:
+				for _, item := range s.Imports {
+					if item.IsType && item.Name == name {
+						next = s.ModulePath(modulePath)
+					}
+				}
+			}
+		}
+		// The types of standard library modules are copied into each
+		// package using them, and cannot be shared
+		if next == "" || strings.HasPrefix(next, "std/") {
+			return "", false
+		}
+		var err error
+		if program, _, err = g.readUserModule(next); err != nil {
+			return "", false
+		}
+		modulePath = next
+	}
+	return "", false
+}
+
 func (g *Generator) processStdModule(modulePath string, importedFunctions []string, importedTypes []string) error {
 	// ... (content remains the same as fetched in Turn 61) ...
-	moduleShortName := strings.TrimPrefix(modulePath, "std/")
-	zenoFilePath := filepath.Join("std", moduleShortName+".zeno")
-	content, err := os.ReadFile(zenoFilePath)
+	content, zenoFilePath, err := stdlib.Source(modulePath)
 	if err != nil {
-		return GenerationError{Message: fmt.Sprintf("Failed to read module file '%s': %v", zenoFilePath, err)}
+		return newGenerationError(i18n.GenModuleReadFailed, zenoFilePath, err)
 	}
 	l := lexer.New(string(content))
 	p := parser.New(l)
 	program := p.ParseProgram()
 	if len(p.Errors()) > 0 {
-		return GenerationError{Message: fmt.Sprintf("Parse errors in module '%s': %v", zenoFilePath, p.Errors())}
+		return newGenerationError(i18n.GenModuleParseErrors, zenoFilePath, p.Errors())
 	}
 	publicFunctions := make(map[string]string)
 	publicTypes := make(map[string]string)
 
 	for _, stmt := range program.Statements {
 		if funcDef, ok := stmt.(*ast.FunctionDefinition); ok && funcDef.IsPublic {
-			goFuncName := funcDef.Name
-			if len(goFuncName) > 0 {
-				goFuncName = strings.ToUpper(string(goFuncName[0])) + goFuncName[1:]
-			}
-			publicFunctions[funcDef.Name] = goFuncName
+			publicFunctions[funcDef.Name] = exportedName(funcDef.Name)
 		} else if typeDef, ok := stmt.(*ast.TypeDeclaration); ok {
 			// Handle type declarations - assume all types in std modules are public
 			publicTypes[typeDef.Name] = typeDef.Name
+		} else if enumDef, ok := stmt.(*ast.EnumDeclaration); ok {
+			publicTypes[enumDef.Name] = enumDef.Name
 		}
 	}
 
 	for _, importedFunc := range importedFunctions {
 		if _, exists := publicFunctions[importedFunc]; !exists {
-			return GenerationError{Message: fmt.Sprintf("Function '%s' is not exported from module '%s'", importedFunc, modulePath)}
+			return newGenerationError(i18n.GenFunctionNotExported, importedFunc, modulePath)
 		}
 		// Add imported function to declaredFns for proper name resolution
 		g.declaredFns[importedFunc] = publicFunctions[importedFunc]
 	}
 
-	// Auto-import dependent types for std/result functions
-	if modulePath == "std/result" && len(importedFunctions) > 0 {
-		// If importing functions from std/result, auto-import Result type
-		resultTypeAlreadyImported := false
-		for _, typeName := range importedTypes {
-			if typeName == "Result" {
-				resultTypeAlreadyImported = true
-				break
-			}
-		}
-		if !resultTypeAlreadyImported {
-			// Auto-add Result type to importTypes
-			if g.importTypes[modulePath] == nil {
-				g.importTypes[modulePath] = []string{}
+	// The functions copied from the module and its native helpers may use
+	// the types it declares, e.g. the Result of std/result, so these are
+	// copied with them
+	if len(importedFunctions) > 0 || len(importedTypes) > 0 {
+		for _, stmt := range program.Statements {
+			if typeDef, ok := stmt.(*ast.TypeDeclaration); ok && !containsString(g.importTypes[modulePath], typeDef.Name) {
+				g.importTypes[modulePath] = append(g.importTypes[modulePath], typeDef.Name)
 			}
-			g.importTypes[modulePath] = append(g.importTypes[modulePath], "Result")
 		}
 	}
 
 	for _, importedType := range importedTypes {
 		if _, exists := publicTypes[importedType]; !exists {
-			return GenerationError{Message: fmt.Sprintf("Type '%s' is not exported from module '%s'", importedType, modulePath)}
+			return newGenerationError(i18n.GenTypeNotExported, importedType, modulePath)
 		}
 	}
 	g.standardLibs[modulePath] = publicFunctions
@@ -964,150 +2420,1945 @@ func (g *Generator) generateNativeFunctionHelpers(builder *strings.Builder) {
 	builder.WriteString("func zenoNativeGetCurrentDirectory() string {\n\tpwd, err := os.Getwd()\n\tif err != nil {\n\t\tfmt.Fprintf(os.Stderr, \"Error getting current directory: %v\\n\", err)\n\t\treturn \"\"\n\t}\n\treturn pwd\n}\n\n")
 	builder.WriteString("func zenoNativeJsonParse(jsonString string) interface{} {\n\tvar result interface{}\n\terr := json.Unmarshal([]byte(jsonString), &result)\n\tif err != nil {\n\t\tfmt.Fprintf(os.Stderr, \"Error parsing JSON string '%s': %v\\n\", jsonString, err)\n\t\treturn nil\n\t}\n\treturn result\n}\n\n")
 	builder.WriteString("func zenoNativeJsonStringify(value interface{}) string {\n\tjsonBytes, err := json.Marshal(value)\n\tif err != nil {\n\t\tfmt.Fprintf(os.Stderr, \"Error stringifying to JSON for value '%v': %v\\n\", value, err)\n\t\treturn \"\"\n\t}\n\treturn string(jsonBytes)\n}\n\n")
+
+	builder.WriteString(nativeSetHelpers)
+	builder.WriteString(nativeIterHelpers)
+	builder.WriteString(nativeBuilderHelpers)
+	builder.WriteString(nativeParallelHelpers)
+	builder.WriteString(nativeBuiltinHelpers)
+	builder.WriteString(nativeGenericHelpers)
+	builder.WriteString(nativeMethodHelpers)
+	if g.usesModule("std/db") {
+		builder.WriteString(nativeDBHelpers)
+	}
+	if g.usesModule("std/archive") {
+		builder.WriteString(nativeArchiveHelpers)
+	}
+	if g.usesModule("std/datetime") {
+		builder.WriteString(nativeDatetimeHelpers)
+	}
+	if g.usesModule("std/semver") {
+		builder.WriteString(nativeSemverHelpers)
+	}
+	if g.usesModule("std/ansi") {
+		builder.WriteString(nativeAnsiHelpers)
+	}
+	if g.usesModule("std/prompt") {
+		builder.WriteString(nativePromptHelpers)
+	}
+	if g.usesModule("std/collections") {
+		builder.WriteString(nativeCollectionHelpers)
+	}
+	if g.usesModule("std/regex") {
+		builder.WriteString(nativeRegexHelpers)
+	}
+	if g.usesModule("std/sync") {
+		builder.WriteString(nativeSyncHelpers)
+	}
+	if g.usesModule("std/json") {
+		builder.WriteString(nativeJsonHelpers)
+	}
+	if g.usesModule("std/io") {
+		builder.WriteString(nativeIoHelpers)
+	}
+	if g.usesModule("std/os") {
+		builder.WriteString(nativeOsHelpers)
+	}
+	if g.usesModule("std/http") {
+		builder.WriteString(nativeHttpHelpers)
+	}
 }
 
-func (g *Generator) inferType(expr ast.Expression) types.Type {
-	switch e := expr.(type) {
-	case *ast.BooleanLiteral:
-		return types.BoolType
-	case *ast.IntegerLiteral:
-		return types.IntType
-	case *ast.StringLiteral:
-		return types.StringType
-	case *ast.FloatLiteral:
-		return types.FloatType
-	case *ast.ArrayLiteral: // Added
-		return types.AnyType // Placeholder for now
-	case *ast.Identifier:
-		if symbol, ok := g.symbolTable.Resolve(e.Value); ok {
-			return symbol.Type
-		}
-		return types.IntType
-	case *ast.BinaryExpression:
-		switch e.Operator {
-		case ast.BinaryOpEq, ast.BinaryOpNotEq, ast.BinaryOpLt, ast.BinaryOpLte, ast.BinaryOpGt, ast.BinaryOpGte:
-			return types.BoolType
-		case ast.BinaryOpPlus, ast.BinaryOpMinus, ast.BinaryOpMultiply, ast.BinaryOpDivide, ast.BinaryOpModulo:
-			leftType := g.inferType(e.Left)
-			rightType := g.inferType(e.Right)
-			if leftType == types.FloatType || rightType == types.FloatType {
-				return types.FloatType
-			}
-			return types.IntType
-		case ast.BinaryOpAnd, ast.BinaryOpOr:
-			return types.BoolType
-		}
-	case *ast.FunctionCall:
-		var funcDef *ast.FunctionDefinition
-		if g.program != nil {
-			for _, stmt := range g.program.Statements {
-				if def, ok := stmt.(*ast.FunctionDefinition); ok && def.Name == e.Name {
-					funcDef = def
-					break
-				}
-			}
-		}
-		if funcDef == nil {
-			for modulePath, moduleAST := range g.moduleASTs {
-				isImportedFromThisModule := false
-				if importedFuncs, exists := g.imports[modulePath]; exists {
-					for _, importedFnName := range importedFuncs {
-						if importedFnName == e.Name {
-							isImportedFromThisModule = true
-							break
-						}
-					}
-				}
-				if isImportedFromThisModule {
-					for _, stmt := range moduleAST.Statements {
-						if def, ok := stmt.(*ast.FunctionDefinition); ok && def.Name == e.Name {
-							if def.IsPublic {
-								funcDef = def
-								break
-							}
-						}
-					}
-				}
-				if funcDef != nil {
-					break
-				}
-			}
-		}
-		if funcDef != nil && funcDef.ReturnType != nil {
-			return g.mapASTTypeToType(*funcDef.ReturnType)
-		}
-		// fmt.Printf("WARN: Could not accurately determine return type for function call '%s'. Defaulting to IntType.\n", e.Name)
-		return types.IntType
-	case *ast.UnaryExpression:
-		switch e.Operator {
-		case ast.UnaryOpBang:
-			return types.BoolType
-		case ast.UnaryOpMinus:
-			return g.inferType(e.Right)
-		default:
-			// warning: suppressed inference fallback log
-			return types.IntType
-		}
+// nativeSetHelpers implements std/set. zenoSet is generic over the element
+// type; Zeno values reach it as interface{}, so the helpers use zenoSet[any].
+const nativeSetHelpers = `type zenoSet[T comparable] struct {
+	index map[T]int
+	items []T
+}
+
+func newZenoSet[T comparable](items ...T) *zenoSet[T] {
+	s := &zenoSet[T]{index: make(map[T]int)}
+	for _, item := range items {
+		s.add(item)
 	}
-	return types.IntType
+	return s
 }
 
-func (g *Generator) registerVariableWithType(name string, varType types.Type) {
-	g.symbolTable.Define(name, varType)
+func (s *zenoSet[T]) add(item T) bool {
+	if _, ok := s.index[item]; ok {
+		return false
+	}
+	s.index[item] = len(s.items)
+	s.items = append(s.items, item)
+	return true
 }
 
-func (g *Generator) getVariableType(name string) types.Type {
-	if symbol, ok := g.symbolTable.Resolve(name); ok {
-		// debug: suppress output
-		return symbol.Type
+func (s *zenoSet[T]) remove(item T) bool {
+	i, ok := s.index[item]
+	if !ok {
+		return false
 	}
-	// debug: suppress output
-	return types.IntType
+	delete(s.index, item)
+	s.items = append(s.items[:i], s.items[i+1:]...)
+	for j := i; j < len(s.items); j++ {
+		s.index[s.items[j]] = j
+	}
+	return true
 }
 
-func (g *Generator) mapASTTypeToType(astType string) types.Type {
-	switch astType {
-	case "bool":
-		return types.BoolType
-	case "int":
-		return types.IntType
-	case "string":
-		return types.StringType
-	case "float":
-		return types.FloatType
-	default:
-		return types.IntType
+func (s *zenoSet[T]) has(item T) bool {
+	_, ok := s.index[item]
+	return ok
+}
+
+func (s *zenoSet[T]) String() string {
+	parts := make([]string, len(s.items))
+	for i, item := range s.items {
+		parts[i] = fmt.Sprint(item)
 	}
+	return "{" + strings.Join(parts, ", ") + "}"
 }
 
-func (g *Generator) validateFunctionTypes(program *ast.Program) error {
-	// ... (content remains the same as fetched in Turn 61) ...
-	for _, stmt := range program.Statements {
-		if funcDef, ok := stmt.(*ast.FunctionDefinition); ok {
-			if funcDef.Name == "main" {
-				continue
-			}
-			for _, param := range funcDef.Parameters {
-				if param.Type == "" {
-					return GenerationError{Message: fmt.Sprintf("Function '%s': parameter '%s' must have an explicit type", funcDef.Name, param.Name)}
-				}
-			}
-			if g.hasValueReturnStatement(funcDef.Body) && funcDef.ReturnType == nil {
-				return GenerationError{Message: fmt.Sprintf("Function '%s' contains return statements with values but has no explicit return type", funcDef.Name)}
-			}
+func zenoNativeAsSet(value interface{}) *zenoSet[any] {
+	s, ok := value.(*zenoSet[any])
+	if !ok {
+		panic(fmt.Sprintf("std/set: expected a set, got %T", value))
+	}
+	return s
+}
+
+func zenoNativeSetNew(items []interface{}) interface{} {
+	return newZenoSet(items...)
+}
+
+func zenoNativeSetAdd(set interface{}, item interface{}) bool {
+	return zenoNativeAsSet(set).add(item)
+}
+
+func zenoNativeSetRemove(set interface{}, item interface{}) bool {
+	return zenoNativeAsSet(set).remove(item)
+}
+
+func zenoNativeSetHas(set interface{}, item interface{}) bool {
+	return zenoNativeAsSet(set).has(item)
+}
+
+func zenoNativeSetUnion(a interface{}, b interface{}) interface{} {
+	result := newZenoSet(zenoNativeAsSet(a).items...)
+	for _, item := range zenoNativeAsSet(b).items {
+		result.add(item)
+	}
+	return result
+}
+
+func zenoNativeSetIntersect(a interface{}, b interface{}) interface{} {
+	other := zenoNativeAsSet(b)
+	result := newZenoSet[any]()
+	for _, item := range zenoNativeAsSet(a).items {
+		if other.has(item) {
+			result.add(item)
 		}
 	}
-	return nil
+	return result
 }
 
-func (g *Generator) hasValueReturnStatement(statements []ast.Statement) bool {
-	// ... (content remains the same as fetched in Turn 61) ...
-	for _, stmt := range statements {
-		if rs, ok := stmt.(*ast.ReturnStatement); ok && rs.Value != nil {
-			return true
+func zenoNativeSetSize(set interface{}) int {
+	return len(zenoNativeAsSet(set).items)
+}
+
+func zenoNativeSetToArray(set interface{}) []interface{} {
+	return append([]interface{}{}, zenoNativeAsSet(set).items...)
+}
+
+`
+
+// nativeIterHelpers implements std/iter. Adapters wrap their source and only
+// pull elements from it when asked, so no intermediate arrays are built.
+// Zeno functions passed to map and filter are called through reflection so
+// that functions with concrete parameter types can be used.
+const nativeIterHelpers = `type zenoIterator struct {
+	hasNext func() bool
+	next    func() interface{}
+}
+
+func zenoNativeCall(fn interface{}, args ...interface{}) interface{} {
+	f := reflect.ValueOf(fn)
+	if f.Kind() != reflect.Func {
+		panic(fmt.Sprintf("std/iter: expected a function, got %T", fn))
+	}
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		paramType := f.Type().In(i)
+		if arg == nil {
+			in[i] = reflect.Zero(paramType)
+			continue
 		}
-		if ifStmt, ok := stmt.(*ast.IfStatement); ok {
-			if ifStmt.ThenBlock != nil && g.hasValueReturnStatement(ifStmt.ThenBlock.Statements) {
+		v := reflect.ValueOf(arg)
+		if v.Type() != paramType && v.Type().ConvertibleTo(paramType) {
+			v = v.Convert(paramType)
+		}
+		in[i] = v
+	}
+	out := f.Call(in)
+	if len(out) == 0 {
+		return nil
+	}
+	return out[0].Interface()
+}
+
+func zenoNativeIterFrom(values interface{}) *zenoIterator {
+	switch v := values.(type) {
+	case *zenoIterator:
+		return v
+	case *zenoSet[any]:
+		values = v.items
+	}
+	rv := reflect.ValueOf(values)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		panic(fmt.Sprintf("std/iter: cannot iterate over %T", values))
+	}
+	i := 0
+	return &zenoIterator{
+		hasNext: func() bool { return i < rv.Len() },
+		next: func() interface{} {
+			v := rv.Index(i).Interface()
+			i++
+			return v
+		},
+	}
+}
+
+func zenoNativeIterCount(start int) *zenoIterator {
+	n := start
+	return &zenoIterator{
+		hasNext: func() bool { return true },
+		next: func() interface{} {
+			v := n
+			n++
+			return v
+		},
+	}
+}
+
+func zenoNativeIterHasNext(it *zenoIterator) bool {
+	return it.hasNext()
+}
+
+func zenoNativeIterNext(it *zenoIterator) interface{} {
+	if !it.hasNext() {
+		panic("std/iter: next called on an exhausted iterator")
+	}
+	return it.next()
+}
+
+func zenoNativeIterMap(it *zenoIterator, fn interface{}) *zenoIterator {
+	return &zenoIterator{
+		hasNext: it.hasNext,
+		next:    func() interface{} { return zenoNativeCall(fn, it.next()) },
+	}
+}
+
+func zenoNativeIterFilter(it *zenoIterator, fn interface{}) *zenoIterator {
+	var pending interface{}
+	ready := false
+	advance := func() bool {
+		for !ready && it.hasNext() {
+			v := it.next()
+			if keep, _ := zenoNativeCall(fn, v).(bool); keep {
+				pending, ready = v, true
+			}
+		}
+		return ready
+	}
+	return &zenoIterator{
+		hasNext: advance,
+		next: func() interface{} {
+			advance()
+			ready = false
+			return pending
+		},
+	}
+}
+
+func zenoNativeIterTake(it *zenoIterator, n int) *zenoIterator {
+	taken := 0
+	return &zenoIterator{
+		hasNext: func() bool { return taken < n && it.hasNext() },
+		next: func() interface{} {
+			taken++
+			return it.next()
+		},
+	}
+}
+
+func zenoNativeIterZip(a *zenoIterator, b *zenoIterator) *zenoIterator {
+	return &zenoIterator{
+		hasNext: func() bool { return a.hasNext() && b.hasNext() },
+		next:    func() interface{} { return []interface{}{a.next(), b.next()} },
+	}
+}
+
+func zenoNativeIterCollect(it *zenoIterator) []interface{} {
+	result := []interface{}{}
+	for it.hasNext() {
+		result = append(result, it.next())
+	}
+	return result
+}
+
+`
+
+// nativeBuilderHelpers implements std/strbuilder on top of strings.Builder.
+const nativeBuilderHelpers = `func zenoNativeAsBuilder(builder interface{}) *strings.Builder {
+	b, ok := builder.(*strings.Builder)
+	if !ok {
+		panic(fmt.Sprintf("std/strbuilder: expected a builder, got %T", builder))
+	}
+	return b
+}
+
+func zenoNativeBuilderNew() interface{} {
+	return &strings.Builder{}
+}
+
+func zenoNativeBuilderAppend(builder interface{}, text string) {
+	zenoNativeAsBuilder(builder).WriteString(text)
+}
+
+func zenoNativeBuilderString(builder interface{}) string {
+	return zenoNativeAsBuilder(builder).String()
+}
+
+func zenoNativeBuilderReset(builder interface{}) {
+	zenoNativeAsBuilder(builder).Reset()
+}
+`
+
+// nativeParallelHelpers implements std/parallel. Results keep the order of
+// the input regardless of which goroutine finishes first.
+const nativeParallelHelpers = `func zenoNativeParallelMap(values interface{}, fn interface{}, workers int) []interface{} {
+	rv := reflect.ValueOf(values)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		panic(fmt.Sprintf("std/parallel: cannot map over %T", values))
+	}
+	n := rv.Len()
+	if workers <= 0 || workers > n {
+		workers = n
+	}
+	results := make([]interface{}, n)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer zenoRecoverPanic()
+			for i := range jobs {
+				results[i] = zenoNativeCall(fn, rv.Index(i).Interface())
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+func zenoNativeWaitAll(tasks []interface{}) {
+	var wg sync.WaitGroup
+	for _, task := range tasks {
+		wg.Add(1)
+		go func(task interface{}) {
+			defer wg.Done()
+			defer zenoRecoverPanic()
+			zenoNativeCall(task)
+		}(task)
+	}
+	wg.Wait()
+}
+`
+
+// nativeDBHelpers implements std/db with database/sql and the pure-Go
+// SQLite driver. They are only emitted when std/db is imported, because the
+// driver is a third-party module. Query results are arrays of maps from
+// column name to value, with integers as int and text as string.
+const nativeDBHelpers = `func zenoNativeDBOpen(path string) interface{} {
+	db, err := sql.Open("sqlite", path)
+	if err == nil {
+		err = db.Ping()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening database %s: %v\n", path, err)
+		return nil
+	}
+	return db
+}
+
+func zenoNativeDBClose(handle interface{}) bool {
+	var err error
+	switch h := handle.(type) {
+	case *sql.DB:
+		err = h.Close()
+	case *sql.Stmt:
+		err = h.Close()
+	default:
+		err = fmt.Errorf("expected a database or statement, got %T", handle)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+		return false
+	}
+	return true
+}
+
+func zenoNativeAsDB(handle interface{}) *sql.DB {
+	db, ok := handle.(*sql.DB)
+	if !ok {
+		panic(fmt.Sprintf("std/db: expected a database, got %T", handle))
+	}
+	return db
+}
+
+func zenoNativeAsStmt(handle interface{}) *sql.Stmt {
+	stmt, ok := handle.(*sql.Stmt)
+	if !ok {
+		panic(fmt.Sprintf("std/db: expected a prepared statement, got %T", handle))
+	}
+	return stmt
+}
+
+func zenoNativeDBResult(result sql.Result, err error) int {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing statement: %v\n", err)
+		return -1
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0
+	}
+	return int(affected)
+}
+
+func zenoNativeDBRows(rows *sql.Rows, err error) []interface{} {
+	result := []interface{}{}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing query: %v\n", err)
+		return result
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading columns: %v\n", err)
+		return result
+	}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading row: %v\n", err)
+			return result
+		}
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			switch v := values[i].(type) {
+			case int64:
+				row[column] = int(v)
+			case []byte:
+				row[column] = string(v)
+			default:
+				row[column] = v
+			}
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading rows: %v\n", err)
+	}
+	return result
+}
+
+func zenoNativeDBExec(handle interface{}, query string, args []interface{}) int {
+	return zenoNativeDBResult(zenoNativeAsDB(handle).Exec(query, args...))
+}
+
+func zenoNativeDBQuery(handle interface{}, query string, args []interface{}) []interface{} {
+	return zenoNativeDBRows(zenoNativeAsDB(handle).Query(query, args...))
+}
+
+func zenoNativeDBPrepare(handle interface{}, query string) interface{} {
+	stmt, err := zenoNativeAsDB(handle).Prepare(query)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error preparing statement: %v\n", err)
+		return nil
+	}
+	return stmt
+}
+
+func zenoNativeDBExecStmt(handle interface{}, args []interface{}) int {
+	return zenoNativeDBResult(zenoNativeAsStmt(handle).Exec(args...))
+}
+
+func zenoNativeDBQueryStmt(handle interface{}, args []interface{}) []interface{} {
+	return zenoNativeDBRows(zenoNativeAsStmt(handle).Query(args...))
+}
+`
+
+// nativeArchiveHelpers implements std/archive. The format is chosen from the
+// archive name: ".zip" for zip files, ".tar.gz" or ".tgz" for gzipped
+// tarballs. Extraction refuses entries that would escape the destination.
+const nativeArchiveHelpers = `func zenoNativeArchiveFormat(archive string) string {
+	switch {
+	case strings.HasSuffix(archive, ".zip"):
+		return "zip"
+	case strings.HasSuffix(archive, ".tar.gz"), strings.HasSuffix(archive, ".tgz"):
+		return "tar.gz"
+	}
+	return ""
+}
+
+func zenoNativeArchiveFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, root := range paths {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.Mode().IsRegular() {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func zenoNativeArchiveCreate(archive string, paths []string) bool {
+	err := func() error {
+		format := zenoNativeArchiveFormat(archive)
+		if format == "" {
+			return fmt.Errorf("unsupported archive format")
+		}
+		files, err := zenoNativeArchiveFiles(paths)
+		if err != nil {
+			return err
+		}
+		out, err := os.Create(archive)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		if format == "zip" {
+			zw := zip.NewWriter(out)
+			for _, file := range files {
+				w, err := zw.Create(filepath.ToSlash(file))
+				if err != nil {
+					return err
+				}
+				if err := zenoNativeArchiveCopyFrom(w, file); err != nil {
+					return err
+				}
+			}
+			return zw.Close()
+		}
+		gw := gzip.NewWriter(out)
+		tw := tar.NewWriter(gw)
+		for _, file := range files {
+			info, err := os.Stat(file)
+			if err != nil {
+				return err
+			}
+			header, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return err
+			}
+			header.Name = filepath.ToSlash(file)
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			if err := zenoNativeArchiveCopyFrom(tw, file); err != nil {
+				return err
+			}
+		}
+		if err := tw.Close(); err != nil {
+			return err
+		}
+		return gw.Close()
+	}()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating archive %s: %v\n", archive, err)
+		return false
+	}
+	return true
+}
+
+func zenoNativeArchiveCopyFrom(w io.Writer, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func zenoNativeArchiveWriteFile(dest string, name string, r io.Reader) error {
+	target := filepath.Join(dest, name)
+	rel, err := filepath.Rel(dest, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("entry %s escapes the destination directory", name)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, r)
+	return err
+}
+
+func zenoNativeArchiveWalk(archive string, visit func(name string, r io.Reader) error) error {
+	switch zenoNativeArchiveFormat(archive) {
+	case "zip":
+		zr, err := zip.OpenReader(archive)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		for _, entry := range zr.File {
+			if entry.FileInfo().IsDir() {
+				continue
+			}
+			rc, err := entry.Open()
+			if err != nil {
+				return err
+			}
+			err = visit(entry.Name, rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case "tar.gz":
+		f, err := os.Open(archive)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gr.Close()
+		tr := tar.NewReader(gr)
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if header.Typeflag != tar.TypeReg {
+				continue
+			}
+			if err := visit(header.Name, tr); err != nil {
+				return err
+			}
+		}
+	}
+	return fmt.Errorf("unsupported archive format")
+}
+
+func zenoNativeArchiveExtract(archive string, dest string) bool {
+	err := zenoNativeArchiveWalk(archive, func(name string, r io.Reader) error {
+		return zenoNativeArchiveWriteFile(dest, name, r)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error extracting archive %s: %v\n", archive, err)
+		return false
+	}
+	return true
+}
+
+func zenoNativeArchiveList(archive string) []interface{} {
+	names := []interface{}{}
+	err := zenoNativeArchiveWalk(archive, func(name string, r io.Reader) error {
+		names = append(names, name)
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading archive %s: %v\n", archive, err)
+	}
+	return names
+}
+`
+
+// nativeDatetimeHelpers implements std/datetime on top of time.Time. Layouts
+// use YYYY, MM, DD, HH, mm, ss, SSS and Z placeholders, which are translated
+// to Go reference layouts; "iso" selects RFC 3339.
+const nativeDatetimeHelpers = `var zenoDatetimeLayout = strings.NewReplacer(
+	"YYYY", "2006", "MM", "01", "DD", "02",
+	"HH", "15", "mm", "04", "ss", "05", "SSS", "000", "Z", "Z07:00",
+)
+
+func zenoNativeGoLayout(layout string) string {
+	if layout == "" || layout == "iso" {
+		return time.RFC3339
+	}
+	return zenoDatetimeLayout.Replace(layout)
+}
+
+func zenoNativeAsTime(date interface{}) time.Time {
+	t, ok := date.(time.Time)
+	if !ok {
+		panic(fmt.Sprintf("std/datetime: expected a date, got %T", date))
+	}
+	return t
+}
+
+func zenoNativeDatetimeNow() interface{} {
+	return time.Now()
+}
+
+func zenoNativeDatetimeParse(layout string, text string) interface{} {
+	t, err := time.Parse(zenoNativeGoLayout(layout), text)
+	if err != nil && (layout == "" || layout == "iso") {
+		t, err = time.Parse("2006-01-02", text)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing date '%s': %v\n", text, err)
+		return nil
+	}
+	return t
+}
+
+func zenoNativeDatetimeFormat(date interface{}, layout string) string {
+	return zenoNativeAsTime(date).Format(zenoNativeGoLayout(layout))
+}
+
+func zenoNativeDatetimeAdd(date interface{}, duration string, sign time.Duration) interface{} {
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing duration '%s': %v\n", duration, err)
+		return date
+	}
+	return zenoNativeAsTime(date).Add(sign * d)
+}
+
+func zenoNativeDatetimeDiff(a interface{}, b interface{}) int {
+	return int(zenoNativeAsTime(a).Sub(zenoNativeAsTime(b)) / time.Second)
+}
+
+func zenoNativeDatetimeComponent(date interface{}, component string) int {
+	t := zenoNativeAsTime(date)
+	switch component {
+	case "year":
+		return t.Year()
+	case "month":
+		return int(t.Month())
+	case "day":
+		return t.Day()
+	case "hour":
+		return t.Hour()
+	case "minute":
+		return t.Minute()
+	}
+	return t.Second()
+}
+`
+
+// nativeSemverHelpers implements std/semver. It follows the rules of the
+// semver package, which the compiler itself uses; keep the two in sync.
+const nativeSemverHelpers = `type zenoSemver struct {
+	numbers [3]int
+	pre     []string
+}
+
+func zenoNativeSemverParse(s string) (zenoSemver, bool) {
+	var v zenoSemver
+	text := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexByte(text, '+'); i >= 0 {
+		if i == len(text)-1 {
+			return v, false
+		}
+		text = text[:i]
+	}
+	if i := strings.IndexByte(text, '-'); i >= 0 {
+		v.pre = strings.Split(text[i+1:], ".")
+		text = text[:i]
+		for _, id := range v.pre {
+			if id == "" {
+				return v, false
+			}
+		}
+	}
+	parts := strings.Split(text, ".")
+	if len(parts) != 3 {
+		return v, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || (len(part) > 1 && part[0] == '0') {
+			return v, false
+		}
+		v.numbers[i] = n
+	}
+	return v, true
+}
+
+func zenoNativeSemverMust(s string) zenoSemver {
+	v, ok := zenoNativeSemverParse(s)
+	if !ok {
+		panic(fmt.Sprintf("std/semver: invalid version %q", s))
+	}
+	return v
+}
+
+func zenoNativeSemverCmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func zenoNativeSemverCmp(a, b zenoSemver) int {
+	for i := range a.numbers {
+		if c := zenoNativeSemverCmpInt(a.numbers[i], b.numbers[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(a.pre) == 0 && len(b.pre) == 0:
+		return 0
+	case len(a.pre) == 0:
+		return 1
+	case len(b.pre) == 0:
+		return -1
+	}
+	for i := 0; i < len(a.pre) && i < len(b.pre); i++ {
+		an, aErr := strconv.Atoi(a.pre[i])
+		bn, bErr := strconv.Atoi(b.pre[i])
+		c := 0
+		switch {
+		case aErr == nil && bErr == nil:
+			c = zenoNativeSemverCmpInt(an, bn)
+		case aErr == nil:
+			c = -1
+		case bErr == nil:
+			c = 1
+		default:
+			c = strings.Compare(a.pre[i], b.pre[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return zenoNativeSemverCmpInt(len(a.pre), len(b.pre))
+}
+
+func zenoNativeSemverValid(version string) bool {
+	_, ok := zenoNativeSemverParse(version)
+	return ok
+}
+
+func zenoNativeSemverCompare(a string, b string) int {
+	return zenoNativeSemverCmp(zenoNativeSemverMust(a), zenoNativeSemverMust(b))
+}
+
+func zenoNativeSemverPart(version string, index int) int {
+	return zenoNativeSemverMust(version).numbers[index]
+}
+
+func zenoNativeSemverMatches(v zenoSemver, term string) bool {
+	if term == "*" {
+		return true
+	}
+	op := ""
+	for _, prefix := range []string{">=", "<=", "!=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(term, prefix) {
+			op = prefix
+			break
+		}
+	}
+	base := zenoNativeSemverMust(term[len(op):])
+	c := zenoNativeSemverCmp(v, base)
+	switch op {
+	case "", "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case "^":
+		upper := zenoSemver{numbers: [3]int{base.numbers[0] + 1, 0, 0}}
+		if base.numbers[0] == 0 && base.numbers[1] > 0 {
+			upper = zenoSemver{numbers: [3]int{0, base.numbers[1] + 1, 0}}
+		} else if base.numbers[0] == 0 {
+			upper = zenoSemver{numbers: [3]int{0, 0, base.numbers[2] + 1}}
+		}
+		return c >= 0 && zenoNativeSemverCmp(v, upper) < 0
+	}
+	upper := zenoSemver{numbers: [3]int{base.numbers[0], base.numbers[1] + 1, 0}}
+	return c >= 0 && zenoNativeSemverCmp(v, upper) < 0
+}
+
+func zenoNativeSemverSatisfies(version string, constraint string) bool {
+	v := zenoNativeSemverMust(version)
+	for _, alternative := range strings.Split(constraint, "||") {
+		terms := strings.Fields(alternative)
+		if len(terms) == 0 {
+			panic(fmt.Sprintf("std/semver: invalid constraint %q", constraint))
+		}
+		// Prereleases only match ranges naming a prerelease of the same release
+		ok := len(v.pre) == 0
+		for _, term := range terms {
+			base, valid := zenoNativeSemverParse(strings.TrimLeft(term, "<>=!^~"))
+			if valid && len(base.pre) > 0 && base.numbers == v.numbers {
+				ok = true
+			}
+		}
+		for _, term := range terms {
+			if ok && !zenoNativeSemverMatches(v, term) {
+				ok = false
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func zenoNativeSemverStrings(versions interface{}) []string {
+	var result []string
+	switch vs := versions.(type) {
+	case []string:
+		result = append(result, vs...)
+	case []interface{}:
+		for _, v := range vs {
+			result = append(result, fmt.Sprint(v))
+		}
+	default:
+		panic(fmt.Sprintf("std/semver: expected an array of versions, got %T", versions))
+	}
+	return result
+}
+
+func zenoNativeSemverSort(versions interface{}) []string {
+	result := zenoNativeSemverStrings(versions)
+	sort.SliceStable(result, func(i, j int) bool {
+		return zenoNativeSemverCompare(result[i], result[j]) < 0
+	})
+	return result
+}
+
+func zenoNativeSemverMaxSatisfying(versions interface{}, constraint string) string {
+	best := ""
+	for _, v := range zenoNativeSemverStrings(versions) {
+		if zenoNativeSemverSatisfies(v, constraint) && (best == "" || zenoNativeSemverCompare(v, best) > 0) {
+			best = v
+		}
+	}
+	return best
+}
+`
+
+// nativeAnsiHelpers implements std/ansi. Styling is applied only when
+// standard output is a terminal, unless NO_COLOR or FORCE_COLOR say
+// otherwise (see https://no-color.org).
+const nativeAnsiHelpers = `var zenoAnsiEnabled = zenoNativeAnsiDetect()
+
+var zenoAnsiPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func zenoNativeAnsiDetect() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if os.Getenv("FORCE_COLOR") != "" {
+		return true
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0 && os.Getenv("TERM") != "dumb"
+}
+
+func zenoNativeAnsiStyle(text string, code string) string {
+	if !zenoAnsiEnabled {
+		return text
+	}
+	return "\x1b[" + code + "m" + text + "\x1b[0m"
+}
+
+func zenoNativeAnsiSetEnabled(enabled bool) {
+	zenoAnsiEnabled = enabled
+}
+
+func zenoNativeAnsiEnabled() bool {
+	return zenoAnsiEnabled
+}
+
+func zenoNativeAnsiStrip(text string) string {
+	return zenoAnsiPattern.ReplaceAllString(text, "")
+}
+`
+
+// nativePromptHelpers implements std/prompt. All reads share one buffered
+// reader so that input typed ahead is not lost between prompts. Echo is
+// turned off for passwords with stty; where that is unavailable the input
+// stays visible rather than failing.
+const nativePromptHelpers = `var zenoPromptReader = bufio.NewReader(os.Stdin)
+
+func zenoNativePromptLine(question string) string {
+	fmt.Print(question)
+	line, err := zenoPromptReader.ReadString('\n')
+	if err != nil && line == "" {
+		return ""
+	}
+	return strings.TrimRight(line, "\r\n")
+}
+
+func zenoNativePrompt(question string, fallback string) string {
+	answer := strings.TrimSpace(zenoNativePromptLine(question))
+	if answer == "" {
+		return fallback
+	}
+	return answer
+}
+
+func zenoNativeConfirm(question string, fallback bool) bool {
+	hint := " [y/N] "
+	if fallback {
+		hint = " [Y/n] "
+	}
+	for {
+		switch strings.ToLower(strings.TrimSpace(zenoNativePromptLine(question + hint))) {
+		case "":
+			return fallback
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		}
+	}
+}
+
+func zenoNativeStty(args ...string) bool {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	return cmd.Run() == nil
+}
+
+func zenoNativePassword(question string) string {
+	hidden := zenoNativeStty("-echo")
+	answer := zenoNativePromptLine(question)
+	if hidden {
+		zenoNativeStty("echo")
+		fmt.Println()
+	}
+	return answer
+}
+`
+
+// nativeRegexHelpers implements std/regex. Regex values are *regexp.Regexp,
+// which the Zeno Regex type maps to.
+const nativeRegexHelpers = `func zenoNativeRegexCompile(pattern string) zenoResult[*regexp.Regexp, string] {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return zenoResult[*regexp.Regexp, string]{Error: err.Error()}
+	}
+	return zenoResult[*regexp.Regexp, string]{Ok: true, Value: re}
+}
+
+func zenoNativeRegexMatches(re *regexp.Regexp, text string) bool {
+	return re.MatchString(text)
+}
+
+func zenoNativeRegexFind(re *regexp.Regexp, text string) zenoOption[string] {
+	match := re.FindStringIndex(text)
+	if match == nil {
+		return zenoOption[string]{}
+	}
+	return zenoOption[string]{Some: true, Value: text[match[0]:match[1]]}
+}
+
+func zenoNativeRegexFindAll(re *regexp.Regexp, text string) []string {
+	return append([]string{}, re.FindAllString(text, -1)...)
+}
+
+func zenoNativeRegexGroups(re *regexp.Regexp, text string) []string {
+	return append([]string{}, re.FindStringSubmatch(text)...)
+}
+
+func zenoNativeRegexReplace(re *regexp.Regexp, text string, replacement string) string {
+	return re.ReplaceAllString(text, replacement)
+}
+
+func zenoNativeRegexSplit(re *regexp.Regexp, text string) []string {
+	return re.Split(text, -1)
+}
+
+func zenoNativeRegexEscape(text string) string {
+	return regexp.QuoteMeta(text)
+}
+`
+
+// nativeSyncHelpers implements std/sync. Mutex values are *sync.Mutex and
+// AtomicInt values *atomic.Int64, which the Zeno types map to.
+const nativeSyncHelpers = `func zenoNativeSyncNewMutex() *sync.Mutex {
+	return &sync.Mutex{}
+}
+
+func zenoNativeSyncLock(m *sync.Mutex) {
+	m.Lock()
+}
+
+func zenoNativeSyncUnlock(m *sync.Mutex) {
+	m.Unlock()
+}
+
+func zenoNativeSyncWithLock(m *sync.Mutex, action interface{}) interface{} {
+	m.Lock()
+	defer m.Unlock()
+	return zenoNativeCall(action)
+}
+
+func zenoNativeSyncNewAtomicInt(value int) *atomic.Int64 {
+	a := &atomic.Int64{}
+	a.Store(int64(value))
+	return a
+}
+
+func zenoNativeSyncAtomicLoad(a *atomic.Int64) int {
+	return int(a.Load())
+}
+
+func zenoNativeSyncAtomicStore(a *atomic.Int64, value int) {
+	a.Store(int64(value))
+}
+
+func zenoNativeSyncAtomicAdd(a *atomic.Int64, delta int) int {
+	return int(a.Add(int64(delta)))
+}
+
+func zenoNativeSyncAtomicCompareAndSwap(a *atomic.Int64, old int, new int) bool {
+	return a.CompareAndSwap(int64(old), int64(new))
+}
+`
+
+// nativeJsonHelpers implements the std/json functions returning a Result.
+// decode is generic, so that encoding/json fills the struct generated for
+// the Zeno type, whose json tags hold the declared field names.
+const nativeJsonHelpers = `func zenoNativeJsonDecode[T any](jsonString string) zenoResult[T, string] {
+	var value T
+	if err := json.Unmarshal([]byte(jsonString), &value); err != nil {
+		return zenoResult[T, string]{Error: err.Error()}
+	}
+	return zenoResult[T, string]{Ok: true, Value: value}
+}
+
+func zenoNativeJsonEncode(value interface{}) zenoResult[string, string] {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return zenoResult[string, string]{Error: err.Error()}
+	}
+	return zenoResult[string, string]{Ok: true, Value: string(data)}
+}
+`
+
+// nativeIoHelpers implements the std/io functions returning a Result.
+// Streams are *zenoIoStream values, which hold the file to close along with
+// its buffered reader or writer.
+const nativeIoHelpers = `type zenoIoStream struct {
+	file   *os.File
+	reader *bufio.Reader
+	writer *bufio.Writer
+}
+
+func zenoNativeAsIoStream(stream interface{}) *zenoIoStream {
+	s, ok := stream.(*zenoIoStream)
+	if !ok {
+		panic(fmt.Sprintf("std/io: expected a stream, got %T", stream))
+	}
+	return s
+}
+
+func zenoNativeIoDone(err error) zenoResult[bool, string] {
+	if err != nil {
+		return zenoResult[bool, string]{Error: err.Error()}
+	}
+	return zenoResult[bool, string]{Ok: true, Value: true}
+}
+
+func zenoNativeIoReadDir(path string) zenoResult[[]string, string] {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return zenoResult[[]string, string]{Error: err.Error()}
+	}
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+	return zenoResult[[]string, string]{Ok: true, Value: names}
+}
+
+func zenoNativeIoMkdir(path string, parents bool) zenoResult[bool, string] {
+	if parents {
+		return zenoNativeIoDone(os.MkdirAll(path, 0755))
+	}
+	return zenoNativeIoDone(os.Mkdir(path, 0755))
+}
+
+func zenoNativeIoExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func zenoNativeIoCopyFile(src string, dst string) zenoResult[bool, string] {
+	in, err := os.Open(src)
+	if err != nil {
+		return zenoNativeIoDone(err)
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return zenoNativeIoDone(err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return zenoNativeIoDone(err)
+	}
+	return zenoNativeIoDone(out.Close())
+}
+
+func zenoNativeIoAppendFile(path string, content string) zenoResult[bool, string] {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return zenoNativeIoDone(err)
+	}
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return zenoNativeIoDone(err)
+	}
+	return zenoNativeIoDone(file.Close())
+}
+
+func zenoNativeIoReadLines(path string) zenoResult[[]string, string] {
+	file, err := os.Open(path)
+	if err != nil {
+		return zenoResult[[]string, string]{Error: err.Error()}
+	}
+	defer file.Close()
+	lines := []string{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimSuffix(scanner.Text(), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return zenoResult[[]string, string]{Error: err.Error()}
+	}
+	return zenoResult[[]string, string]{Ok: true, Value: lines}
+}
+
+func zenoNativeIoOpenReader(path string) zenoResult[interface{}, string] {
+	file, err := os.Open(path)
+	if err != nil {
+		return zenoResult[interface{}, string]{Error: err.Error()}
+	}
+	return zenoResult[interface{}, string]{Ok: true, Value: &zenoIoStream{file: file, reader: bufio.NewReader(file)}}
+}
+
+func zenoNativeIoReadLine(reader interface{}) zenoResult[string, string] {
+	s := zenoNativeAsIoStream(reader)
+	if s.reader == nil {
+		return zenoResult[string, string]{Error: "stream is not a reader"}
+	}
+	line, err := s.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return zenoResult[string, string]{Error: err.Error()}
+	}
+	return zenoResult[string, string]{Ok: true, Value: strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")}
+}
+
+func zenoNativeIoOpenWriter(path string) zenoResult[interface{}, string] {
+	file, err := os.Create(path)
+	if err != nil {
+		return zenoResult[interface{}, string]{Error: err.Error()}
+	}
+	return zenoResult[interface{}, string]{Ok: true, Value: &zenoIoStream{file: file, writer: bufio.NewWriter(file)}}
+}
+
+func zenoNativeIoWrite(writer interface{}, text string) zenoResult[bool, string] {
+	s := zenoNativeAsIoStream(writer)
+	if s.writer == nil {
+		return zenoResult[bool, string]{Error: "stream is not a writer"}
+	}
+	_, err := s.writer.WriteString(text)
+	return zenoNativeIoDone(err)
+}
+
+func zenoNativeIoClose(stream interface{}) zenoResult[bool, string] {
+	s := zenoNativeAsIoStream(stream)
+	if s.writer != nil {
+		if err := s.writer.Flush(); err != nil {
+			s.file.Close()
+			return zenoNativeIoDone(err)
+		}
+	}
+	return zenoNativeIoDone(s.file.Close())
+}
+`
+
+// nativeOsHelpers implements std/os. The commands run by exec share the
+// terminal of the program, except for their output, which is returned.
+const nativeOsHelpers = `func zenoNativeOsArgs() []string {
+	return append([]string{}, os.Args[1:]...)
+}
+
+func zenoNativeOsEnv(name string) string {
+	return os.Getenv(name)
+}
+
+func zenoNativeOsSetEnv(name string, value string) bool {
+	return os.Setenv(name, value) == nil
+}
+
+func zenoNativeOsExit(code int) {
+	os.Exit(code)
+}
+
+func zenoNativeOsExec(command string, args []string) zenoResult[string, string] {
+	cmd := exec.Command(command, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return zenoResult[string, string]{Error: fmt.Sprintf("%s: %v", command, err)}
+	}
+	return zenoResult[string, string]{Ok: true, Value: string(output)}
+}
+`
+
+// nativeHttpHelpers implements std/http with one client, whose timeout
+// setTimeout changes, and net/http servers calling the handler through
+// zenoNativeCall. The Request and Response types are copied from std/http.
+const nativeHttpHelpers = `var zenoHttpClient = &http.Client{Timeout: 30 * time.Second}
+
+func zenoNativeHttpSetTimeout(seconds int) {
+	zenoHttpClient.Timeout = time.Duration(seconds) * time.Second
+}
+
+func zenoNativeHttpRequest(method string, url string, body string, headers interface{}) zenoResult[Response, string] {
+	request, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		return zenoResult[Response, string]{Error: err.Error()}
+	}
+	if fields, ok := headers.(map[string]interface{}); ok {
+		for name, value := range fields {
+			request.Header.Set(name, fmt.Sprint(value))
+		}
+	}
+	response, err := zenoHttpClient.Do(request)
+	if err != nil {
+		return zenoResult[Response, string]{Error: err.Error()}
+	}
+	defer response.Body.Close()
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return zenoResult[Response, string]{Error: err.Error()}
+	}
+	received := make(map[string]interface{}, len(response.Header))
+	for name := range response.Header {
+		received[name] = response.Header.Get(name)
+	}
+	return zenoResult[Response, string]{Ok: true, Value: Response{Status: response.StatusCode, Body: string(content), Headers: received}}
+}
+
+func zenoNativeHttpServe(addr string, handler interface{}) string {
+	err := http.ListenAndServe(addr, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		query := make(map[string]interface{})
+		for name := range r.URL.Query() {
+			query[name] = r.URL.Query().Get(name)
+		}
+		headers := make(map[string]interface{}, len(r.Header))
+		for name := range r.Header {
+			headers[name] = r.Header.Get(name)
+		}
+		request := Request{Method: r.Method, Path: r.URL.Path, Query: query, Headers: headers, Body: string(body)}
+		response, ok := zenoNativeCall(handler, request).(Response)
+		if !ok {
+			http.Error(w, "std/http: the handler did not return a Response", http.StatusInternalServerError)
+			return
+		}
+		if fields, ok := response.Headers.(map[string]interface{}); ok {
+			for name, value := range fields {
+				w.Header().Set(name, fmt.Sprint(value))
+			}
+		}
+		if response.Status == 0 {
+			response.Status = http.StatusOK
+		}
+		w.WriteHeader(response.Status)
+		io.WriteString(w, response.Body)
+	}))
+	return err.Error()
+}
+
+func zenoNativeHttpHeader(headers interface{}, name string) string {
+	return zenoNativeHttpField(headers, http.CanonicalHeaderKey(name))
+}
+
+func zenoNativeHttpField(fields interface{}, name string) string {
+	values, _ := fields.(map[string]interface{})
+	if value, ok := values[name]; ok {
+		return fmt.Sprint(value)
+	}
+	return ""
+}
+`
+
+func (g *Generator) inferType(expr ast.Expression) types.Type {
+	switch e := expr.(type) {
+	case *ast.BooleanLiteral:
+		return types.BoolType
+	case *ast.IntegerLiteral:
+		return integerLiteralType(e)
+	case *ast.StringLiteral:
+		return types.StringType
+	case *ast.CharLiteral:
+		return types.CharType
+	case *ast.FloatLiteral:
+		return types.FloatType
+	case *ast.ArrayLiteral:
+		if len(e.Elements) == 0 {
+			return &types.ArrayType{ElementType: types.AnyType}
+		}
+		return &types.ArrayType{ElementType: g.inferType(e.Elements[0])}
+	case *ast.TupleLiteral:
+		tuple := &types.TupleType{}
+		for _, el := range e.Elements {
+			tuple.Elements = append(tuple.Elements, g.inferType(el))
+		}
+		return tuple
+	case *ast.Identifier:
+		if symbol, ok := g.symbolTable.Resolve(e.Value); ok {
+			return symbol.Type
+		}
+		if decl, _, ok := g.enumVariant(e.Value); ok {
+			return enumType(decl)
+		}
+		if g.isNone(e.Value) {
+			return g.optionConstructorType(e)
+		}
+		return types.IntType
+	case *ast.BinaryExpression:
+		switch e.Operator {
+		case ast.BinaryOpEq, ast.BinaryOpNotEq, ast.BinaryOpLt, ast.BinaryOpLte, ast.BinaryOpGt, ast.BinaryOpGte:
+			return types.BoolType
+		case ast.BinaryOpPlus, ast.BinaryOpMinus, ast.BinaryOpMultiply, ast.BinaryOpDivide, ast.BinaryOpModulo:
+			if _, ok := g.operatorMethod(e); ok {
+				return g.inferType(e.Left)
+			}
+			leftType := g.inferType(e.Left)
+			rightType := g.inferType(e.Right)
+			if sized, ok := sizedOperandType(leftType, rightType); ok {
+				return sized
+			}
+			if leftType == types.FloatType || rightType == types.FloatType {
+				return types.FloatType
+			}
+			return types.IntType
+		case ast.BinaryOpAnd, ast.BinaryOpOr:
+			return types.BoolType
+		case ast.BinaryOpShiftLeft, ast.BinaryOpShiftRight:
+			// A shift has the type of the shifted value
+			if left := g.inferType(e.Left); types.IsSized(left) {
+				return left
+			}
+			return types.IntType
+		case ast.BinaryOpBitAnd, ast.BinaryOpBitOr, ast.BinaryOpBitXor:
+			if sized, ok := sizedOperandType(g.inferType(e.Left), g.inferType(e.Right)); ok {
+				return sized
+			}
+			return types.IntType
+		}
+	case *ast.FunctionCall:
+		if decl, _, ok := g.enumVariant(e.Name); ok {
+			return enumType(decl)
+		}
+		funcDef := g.findFunctionDefinition(e.Name)
+		if funcDef != nil && funcDef.ReturnType != nil {
+			return g.mapASTTypeToType(funcDef.Instantiate(*funcDef.ReturnType, g.callTypeArguments(funcDef, e)))
+		}
+		if b, ok := g.lookupBuiltin(e.Name); ok {
+			return g.builtinCallType(b, e)
+		}
+		// fmt.Printf("WARN: Could not accurately determine return type for function call '%s'. Defaulting to IntType.\n", e.Name)
+		return types.IntType
+	case *ast.MemberExpression:
+		if value, ok, err := g.buildConstant(e); ok && err == nil {
+			return g.inferType(value)
+		}
+		if fieldType, ok := g.memberType(e); ok {
+			return fieldType
+		}
+	case *ast.StructLiteral:
+		if decl := g.structDeclaration(e.TypeName); decl != nil {
+			return &types.StructType{Name: decl.Name}
+		}
+	case *ast.MatchExpression:
+		return g.matchType(e)
+	case *ast.IfExpression:
+		return g.ifType(e)
+	case *ast.TryExpression:
+		if result, ok := g.inferType(e.Value).(*types.ResultType); ok {
+			return result.ValueType
+		}
+	case *ast.IndexExpression:
+		return g.indexType(g.inferType(e.Left))
+	case *ast.SliceExpression:
+		return g.inferType(e.Left)
+	case *ast.MethodCallExpression:
+		return g.methodCallType(e)
+	case *ast.UnaryExpression:
+		switch e.Operator {
+		case ast.UnaryOpBang:
+			return types.BoolType
+		case ast.UnaryOpMinus:
+			return g.inferType(e.Right)
+		default:
+			// warning: suppressed inference fallback log
+			return types.IntType
+		}
+	}
+	return types.IntType
+}
+
+// importsFunction reports whether the function name of module is imported,
+// under its own name or another one. Of the functions of a module imported
+// with import * as, only those called are.
+func (g *Generator) importsFunction(module, name string) bool {
+	if containsString(g.imports[module], name) {
+		return true
+	}
+	for alias, imported := range g.aliases {
+		if imported.module == module && imported.name == name && (!imported.namespace || g.usedFns[alias]) {
+			return true
+		}
+	}
+	return false
+}
+
+// findFunctionDefinition looks up the definition of a function called by name,
+// first in the current program and then in the public functions of imported modules.
+func (g *Generator) findFunctionDefinition(name string) *ast.FunctionDefinition {
+	if g.program != nil {
+		for _, stmt := range g.program.Statements {
+			if def, ok := stmt.(*ast.FunctionDefinition); ok && def.Name == name {
+				return def
+			}
+		}
+	}
+	if imported, ok := g.aliases[name]; ok {
+		if moduleAST := g.moduleASTs[imported.module]; moduleAST != nil {
+			for _, stmt := range moduleAST.Statements {
+				if def, ok := stmt.(*ast.FunctionDefinition); ok && def.Name == imported.name && def.IsPublic {
+					return def
+				}
+			}
+		}
+		return nil
+	}
+	for modulePath, moduleAST := range g.moduleASTs {
+		isImportedFromThisModule := false
+		if importedFuncs, exists := g.imports[modulePath]; exists {
+			for _, importedFnName := range importedFuncs {
+				if importedFnName == name {
+					isImportedFromThisModule = true
+					break
+				}
+			}
+		}
+		if !isImportedFromThisModule {
+			continue
+		}
+		for _, stmt := range moduleAST.Statements {
+			if def, ok := stmt.(*ast.FunctionDefinition); ok && def.Name == name && def.IsPublic {
+				return def
+			}
+		}
+	}
+	return nil
+}
+
+// findTypeDeclaration looks up a type declared in the program or imported
+// from a module.
+func (g *Generator) findTypeDeclaration(name string) *ast.TypeDeclaration {
+	if g.program != nil {
+		for _, stmt := range g.program.Statements {
+			if decl, ok := stmt.(*ast.TypeDeclaration); ok && decl.Name == name {
+				return decl
+			}
+		}
+	}
+	for modulePath, typeNames := range g.importTypes {
+		moduleAST, exists := g.moduleASTs[modulePath]
+		if !exists {
+			continue
+		}
+		for _, typeName := range typeNames {
+			if typeName != name {
+				continue
+			}
+			for _, stmt := range moduleAST.Statements {
+				if decl, ok := stmt.(*ast.TypeDeclaration); ok && decl.Name == name {
+					return decl
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// warnIfDeprecated reports a use of a declaration marked @deprecated. Uses
+// inside imported modules are left to the module author.
+func (g *Generator) warnIfDeprecated(node ast.Node, id i18n.MessageID, name string, deprecation *ast.Deprecation) {
+	if deprecation == nil || g.currentFile != g.currentDir {
+		return
+	}
+	warning := Warning{Code: i18n.Code(id), Message: i18n.T(id, name), Pos: node.Pos()}
+	if deprecation.Message != "" {
+		warning.Message += ": " + deprecation.Message
+	}
+	g.warnings = append(g.warnings, warning)
+}
+
+// validateCallArguments checks a call against the declared signature of the
+// called function: the number of arguments and, where both sides are known,
+// the type of each argument.
+func (g *Generator) validateCallArguments(call *ast.FunctionCall) error {
+	funcDef := g.findFunctionDefinition(call.Name)
+	if funcDef == nil {
+		return nil
+	}
+
+	required := len(funcDef.Parameters)
+	variadic := required > 0 && funcDef.Parameters[required-1].Variadic
+	if variadic {
+		required--
+	}
+	if len(call.Arguments) < required || (!variadic && len(call.Arguments) > required) {
+		if variadic {
+			return newGenerationErrorAt(call, i18n.GenArgumentCountAtLeast, call.Name, required, len(call.Arguments), call.String())
+		}
+		return newGenerationErrorAt(call, i18n.GenArgumentCount, call.Name, required, len(call.Arguments), call.String())
+	}
+
+	for i, arg := range call.Arguments {
+		param := funcDef.Parameters[min(i, len(funcDef.Parameters)-1)]
+		if isGenericParameter(funcDef, param.Type) {
+			continue
+		}
+		paramType, ok := basicTypeFromName(param.Type)
+		if !ok {
+			continue
+		}
+		argType := g.knownExpressionType(arg)
+		if argType == nil || argType == paramType {
+			continue
+		}
+		// Integer literals are untyped constants in Go and convert to float
+		// and the sized integer types implicitly.
+		if lit, isIntLit := arg.(*ast.IntegerLiteral); isIntLit && lit.Suffix == "" && (paramType == types.FloatType || types.IsSized(paramType)) {
+			continue
+		}
+		return newGenerationErrorAt(call, i18n.GenArgumentType, i+1, call.Name, param.Name, paramType, argType, call.String())
+	}
+	return nil
+}
+
+func isGenericParameter(funcDef *ast.FunctionDefinition, typeName string) bool {
+	for _, gen := range funcDef.Generics {
+		if gen == typeName {
+			return true
+		}
+	}
+	return false
+}
+
+// basicTypeFromName maps the name of a Zeno primitive type to its types.Type.
+// Unlike mapASTTypeToType it reports whether the name was a primitive at all.
+func basicTypeFromName(name string) (types.Type, bool) {
+	switch name {
+	case "int":
+		return types.IntType, true
+	case "float":
+		return types.FloatType, true
+	case "string":
+		return types.StringType, true
+	case "bool":
+		return types.BoolType, true
+	case "char":
+		return types.CharType, true
+	}
+	if sized, ok := types.SizedInts[name]; ok {
+		return sized, true
+	}
+	return nil, false
+}
+
+// knownExpressionType returns the type of expr only when it can be determined
+// with certainty, and nil otherwise. inferType falls back to int for unknown
+// expressions, which is too loose for reporting type errors.
+func (g *Generator) knownExpressionType(expr ast.Expression) types.Type {
+	switch e := expr.(type) {
+	case *ast.BooleanLiteral:
+		return types.BoolType
+	case *ast.IntegerLiteral:
+		return integerLiteralType(e)
+	case *ast.StringLiteral:
+		return types.StringType
+	case *ast.CharLiteral:
+		return types.CharType
+	case *ast.FloatLiteral:
+		return types.FloatType
+	case *ast.Identifier:
+		if symbol, ok := g.symbolTable.Resolve(e.Value); ok {
+			if _, basic := symbol.Type.(*types.BasicType); basic && symbol.Type != types.AnyType {
+				return symbol.Type
+			}
+		}
+	case *ast.MemberExpression:
+		if value, ok, err := g.buildConstant(e); ok && err == nil {
+			return g.knownExpressionType(value)
+		}
+	case *ast.UnaryExpression:
+		if e.Operator == ast.UnaryOpBang {
+			return types.BoolType
+		}
+		return g.knownExpressionType(e.Right)
+	case *ast.BinaryExpression:
+		switch e.Operator {
+		case ast.BinaryOpEq, ast.BinaryOpNotEq, ast.BinaryOpLt, ast.BinaryOpLte, ast.BinaryOpGt, ast.BinaryOpGte, ast.BinaryOpAnd, ast.BinaryOpOr:
+			return types.BoolType
+		}
+		left := g.knownExpressionType(e.Left)
+		right := g.knownExpressionType(e.Right)
+		if left == nil || right == nil {
+			return nil
+		}
+		if sized, ok := sizedOperandType(left, right); ok {
+			return sized
+		}
+		if left == types.FloatType || right == types.FloatType {
+			return types.FloatType
+		}
+		return left
+	case *ast.FunctionCall:
+		if funcDef := g.findFunctionDefinition(e.Name); funcDef != nil && funcDef.ReturnType != nil {
+			if t, ok := basicTypeFromName(funcDef.Instantiate(*funcDef.ReturnType, e.TypeArguments)); ok {
+				return t
+			}
+		}
+		if b, ok := g.lookupBuiltin(e.Name); ok {
+			if _, basic := b.returnType.(*types.BasicType); basic {
+				return b.returnType
+			}
+		}
+	}
+	return nil
+}
+
+func (g *Generator) registerVariableWithType(name string, varType types.Type) {
+	g.symbolTable.Define(name, varType)
+}
+
+// enterScope starts the scope of the variables of a block and returns the
+// function ending it. Variables declared in a scope shadow the ones of the
+// same name outside it.
+func (g *Generator) enterScope() func() {
+	outer := g.symbolTable
+	g.symbolTable = types.NewSymbolTable(outer)
+	return func() { g.symbolTable = outer }
+}
+
+// variableDeclaration is a variable declared by a let
+type variableDeclaration struct {
+	symbol *types.Symbol
+	let    *ast.LetDeclaration
+}
+
+// declareVariable defines a variable declared by let, which
+// checkUnusedVariables reports unless useVariable is called for it
+func (g *Generator) declareVariable(let *ast.LetDeclaration, name string, varType types.Type) {
+	symbol := g.symbolTable.Define(name, varType)
+	if name != "_" {
+		g.declaredVars = append(g.declaredVars, variableDeclaration{symbol: symbol, let: let})
+	}
+}
+
+// discardUnused assigns the unused variables declared by let to _, which
+// keeps Go from rejecting them when they are allowed
+func (g *Generator) discardUnused(let *ast.LetDeclaration, builder *strings.Builder, indentLevel int) {
+	for _, name := range g.discarded[let] {
+		builder.WriteString(indent(indentLevel) + "_ = " + name + "\n")
+	}
+}
+
+// useVariable records that the variable name refers to in the current
+// scope is used
+func (g *Generator) useVariable(name string) error {
+	symbol, ok := g.symbolTable.Resolve(name)
+	if let, later := g.laterLets[name]; !ok && later && !g.collectedLets[let] {
+		if err := g.collectPackageLet(let); err != nil {
+			return err
+		}
+		symbol, ok = g.symbolTable.Resolve(name)
+	}
+	if ok {
+		g.usedVars[symbol] = true
+	}
+	return nil
+}
+
+// collectPackageLet collects a package-level let, unless a declaration
+// before it referred to it, which collected it already
+func (g *Generator) collectPackageLet(let *ast.LetDeclaration) error {
+	if g.collectedLets[let] {
+		return nil
+	}
+	g.collectedLets[let] = true
+	outer := g.symbolTable
+	g.symbolTable = g.packageScope
+	defer func() { g.symbolTable = outer }()
+	err := g.collectImportsAndDeclarations(let)
+	g.packageLets = append(g.packageLets, let)
+	return err
+}
+
+func (g *Generator) getVariableType(name string) types.Type {
+	if symbol, ok := g.symbolTable.Resolve(name); ok {
+		// debug: suppress output
+		return symbol.Type
+	}
+	// debug: suppress output
+	return types.IntType
+}
+
+func (g *Generator) mapASTTypeToType(astType string) types.Type {
+	switch astType {
+	case "bool":
+		return types.BoolType
+	case "int":
+		return types.IntType
+	case "string":
+		return types.StringType
+	case "float":
+		return types.FloatType
+	case "char":
+		return types.CharType
+	case "Iterator":
+		return types.IteratorType
+	case "Regex":
+		return types.RegexType
+	case "Mutex":
+		return types.MutexType
+	case "AtomicInt":
+		return types.AtomicIntType
+	case "any":
+		return types.AnyType
+	default:
+		if sized, ok := types.SizedInts[astType]; ok {
+			return sized
+		}
+		if element, isArray := strings.CutPrefix(astType, "[]"); isArray {
+			return &types.ArrayType{ElementType: g.mapASTTypeToType(element)}
+		}
+		if elements, isTuple := strings.CutPrefix(astType, "("); isTuple && strings.HasSuffix(elements, ")") {
+			tuple := &types.TupleType{}
+			for _, element := range types.SplitTypeList(strings.TrimSuffix(elements, ")")) {
+				tuple.Elements = append(tuple.Elements, g.mapASTTypeToType(element))
+			}
+			return tuple
+		}
+		if isResultType(astType) {
+			return g.resultType(astType)
+		}
+		if isOptionType(astType) {
+			return g.optionType(astType)
+		}
+		if isChannelType(astType) {
+			return g.channelType(astType)
+		}
+		if collection, ok := g.collectionType(astType); ok {
+			return collection
+		}
+		if decl := g.structDeclaration(astType); decl != nil {
+			return &types.StructType{Name: decl.Name}
+		}
+		if decl := g.findEnumDeclaration(astType); decl != nil {
+			return enumType(decl)
+		}
+		if param, ok := g.typeParameter(astType); ok {
+			return param
+		}
+		return types.IntType
+	}
+}
+
+func (g *Generator) validateFunctionTypes(program *ast.Program) error {
+	// ... (content remains the same as fetched in Turn 61) ...
+	for _, stmt := range program.Statements {
+		if funcDef, ok := stmt.(*ast.FunctionDefinition); ok {
+			if funcDef.Name == "main" && g.packageName == "main" {
+				continue
+			}
+			for _, param := range funcDef.Parameters {
+				if param.Type == "" {
+					return newGenerationErrorAt(funcDef, i18n.GenParamNeedsType, funcDef.Name, param.Name)
+				}
+			}
+			if g.hasValueReturnStatement(funcDef.Body) && funcDef.ReturnType == nil {
+				return newGenerationErrorAt(funcDef, i18n.GenMissingReturnType, funcDef.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func (g *Generator) hasValueReturnStatement(statements []ast.Statement) bool {
+	// ... (content remains the same as fetched in Turn 61) ...
+	for _, stmt := range statements {
+		if rs, ok := stmt.(*ast.ReturnStatement); ok && rs.Value != nil {
+			return true
+		}
+		if ifStmt, ok := stmt.(*ast.IfStatement); ok {
+			if ifStmt.ThenBlock != nil && g.hasValueReturnStatement(ifStmt.ThenBlock.Statements) {
 				return true
 			}
 			for _, elseIfClause := range ifStmt.ElseIfClauses {
@@ -1124,6 +4375,11 @@ func (g *Generator) hasValueReturnStatement(statements []ast.Statement) bool {
 				return true
 			}
 		}
+		if loopStmt, ok := stmt.(*ast.LoopStatement); ok {
+			if g.hasValueReturnStatement(loopStmt.Body.Statements) {
+				return true
+			}
+		}
 	}
 	return false
 }
@@ -1,12 +1,19 @@
 package generator
 
 import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/deps"
 	"github.com/linkalls/zeno-lang/lexer"
 	"github.com/linkalls/zeno-lang/parser"
+	"github.com/linkalls/zeno-lang/stdlib"
 )
 
 // Helper function to run generator tests
@@ -48,6 +55,30 @@ func runGeneratorTest(t *testing.T, inputZeno string, expectedGoSubstrings []str
 	return goCode
 }
 
+// runProgram generates the Go code of a Zeno program, runs it with go run
+// and returns what it prints. The test is skipped without a Go toolchain.
+func runProgram(t *testing.T, inputZeno string) string {
+	t.Helper()
+	goPath, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not installed")
+	}
+	program := parser.New(lexer.New(inputZeno)).ParseProgram()
+	goCode, err := Generate(program)
+	if err != nil {
+		t.Fatalf("generation failed: %v", err)
+	}
+	file := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(file, []byte(goCode), 0644); err != nil {
+		t.Fatal(err)
+	}
+	output, err := exec.Command(goPath, "run", file).CombinedOutput()
+	if err != nil {
+		t.Fatalf("go run failed: %v\n%s\n%s", err, output, goCode)
+	}
+	return string(output)
+}
+
 func TestGenerateLetDeclarations(t *testing.T) {
 	runGeneratorTest(t, "let x = 10", []string{
 		"package main",
@@ -263,3 +294,2325 @@ func TestGenerateStdIoImportValidation(t *testing.T) {
 		t.Errorf("Expected import validation error for writeFile, got: %v", err)
 	}
 }
+
+func TestGenerateCallArgumentValidation(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{`fn add(a: int, b: int): int {
+    return a + b
+}
+fn main() {
+    add(1)
+}`, "Function 'add' expects 2 argument(s), got 1 in call add(1)"},
+		{`fn add(a: int, b: int): int {
+    return a + b
+}
+fn main() {
+    add(1, 2, 3)
+}`, "Function 'add' expects 2 argument(s), got 3"},
+		{`fn greet(name: string) {
+    return
+}
+fn main() {
+    greet(42)
+}`, "Argument 1 of 'greet' (parameter 'name') expects string, got int"},
+		{`fn sum(first: int, ...rest: int): int {
+    return first
+}
+fn main() {
+    sum()
+}`, "Function 'sum' expects at least 1 argument(s), got 0"},
+		{`fn main() {
+    println(len("a", "b"))
+}`, "Function 'len' expects 1 argument(s), got 2"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("Parser errors: %v", p.Errors())
+		}
+
+		_, err := Generate(program)
+		if err == nil {
+			t.Errorf("Expected error %q for input:\n%s\nbut got none", tt.expectedError, tt.input)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.expectedError) {
+			t.Errorf("Expected error containing %q, got: %v", tt.expectedError, err)
+		}
+	}
+}
+
+func TestGenerateMissingImportSuggestion(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	tests := []struct {
+		input      string
+		message    string
+		suggestion string
+	}{
+		{`fn main() {
+    let text = readFile("notes.txt")
+    println(text)
+}`, "Function 'readFile' is not defined or imported", `import { readFile } from "std/io"`},
+		{`import { readFile } from "std/io"
+fn main() {
+    let text = readFile("notes.txt")
+    writeFile("copy.txt", text)
+}`, "Function 'writeFile' is not imported from 'std/io'", `import { readFile, writeFile } from "std/io"`},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("Parser errors: %v", p.Errors())
+		}
+
+		_, err := Generate(program)
+		genErr, ok := err.(GenerationError)
+		if !ok {
+			t.Errorf("Expected GenerationError for input:\n%s\ngot: %v", tt.input, err)
+			continue
+		}
+		if genErr.Code != "Z0106" || !strings.Contains(genErr.Message, tt.message) {
+			t.Errorf("Expected Z0106 %q, got %s %q", tt.message, genErr.Code, genErr.Message)
+		}
+		if !strings.Contains(genErr.Suggestion, tt.suggestion) {
+			t.Errorf("Expected suggestion containing %q, got %q", tt.suggestion, genErr.Suggestion)
+		}
+	}
+}
+
+func TestGenerateAssignUndeclared(t *testing.T) {
+	program := parser.New(lexer.New("fn main() {\n    let total = 0\n    totl = total + 1\n}")).ParseProgram()
+	_, err := Generate(program)
+	genErr, ok := err.(GenerationError)
+	if !ok {
+		t.Fatalf("Expected GenerationError, got: %v", err)
+	}
+	if genErr.Code != "Z0139" || genErr.Pos.Line != 3 {
+		t.Errorf("Expected Z0139 on line 3, got %s on line %d", genErr.Code, genErr.Pos.Line)
+	}
+	if genErr.Suggestion != "did you mean `let totl = (total + 1)`?" {
+		t.Errorf("Expected a suggestion to declare totl, got %q", genErr.Suggestion)
+	}
+}
+
+func TestGenerateCallArgumentValidationAccepts(t *testing.T) {
+	runGeneratorTest(t, `fn scale(x: float, ...rest: int): float {
+    return x
+}
+fn main() {
+    scale(2)
+    scale(2.5, 1, 2, 3)
+}`, []string{
+		"scale(2)",
+		"scale(2.5, 1, 2, 3)",
+	})
+}
+
+func TestGenerateBuiltins(t *testing.T) {
+	runGeneratorTest(t, `fn main() {
+    let items = [1, 2, 3]
+    let n = len(items)
+    let parsed = int("42")
+    println(str(n) + typeOf(items), parsed.value, float("1.5").ok)
+}`, []string{
+		"var n = zenoBuiltinLen(items)",
+		"var parsed = zenoBuiltinInt(\"42\")",
+		"(zenoBuiltinStr(n) + zenoBuiltinTypeOf(items))",
+		"zenoBuiltinFloat(\"1.5\").Ok",
+		"func zenoBuiltinLen(value interface{}) int {",
+	})
+
+	runGeneratorTest(t, `fn main() {
+    let n = toInt("4")
+    let flag = parseBool("true")
+    println(toString(n.value + 1), toFloat("2.5").value, flag.ok)
+}`, []string{
+		"var n = zenoBuiltinInt(\"4\")",
+		"var flag = zenoBuiltinParseBool(\"true\")",
+		"zenoBuiltinStr((n.Value + 1))",
+		"zenoBuiltinFloat(\"2.5\").Value",
+		"strconv.ParseBool(strings.TrimSpace(v))",
+	})
+
+	runGeneratorTest(t, `fn main() {
+    for arg in args() {
+        println(arg.toUpper())
+    }
+}`, []string{
+		"for _, arg := range zenoBuiltinArgs() {",
+		"strings.ToUpper(arg)",
+		"return append([]string{}, os.Args[1:]...)",
+	})
+
+	// A user-defined function shadows the builtin of the same name
+	runGeneratorTest(t, `fn len(s: string): int {
+    return 0
+}
+fn main() {
+    println(len("abc"))
+}`, []string{
+		"fmt.Println(len(\"abc\"))",
+	})
+}
+
+func TestGenerateLoopStatement(t *testing.T) {
+	runGeneratorTest(t, `fn main() {
+    let n = 0
+    loop {
+        n = n + 1
+        if n < 3 {
+            continue
+        }
+        break
+    }
+}`, []string{
+		"\tfor {\n",
+		"\t\t\tcontinue\n",
+		"\t\tbreak\n",
+	})
+}
+
+func TestGenerateSpawn(t *testing.T) {
+	runGeneratorTest(t, `fn work(n: int) {
+    println(n)
+}
+
+fn main() {
+    for i in [1, 2] {
+        spawn {
+            let doubled = i * 2
+            work(doubled)
+        }
+    }
+    spawn work(3)
+    wait()
+}`, []string{
+		"\t\t{\n\t\t\ti := i\n\t\t\tzenoSpawn(func() {\n",
+		"\t\t\t\tvar doubled = (i * 2)\n",
+		"\t\t\t})\n\t\t}\n",
+		"\tzenoSpawn(func() {\n\t\twork(3)\n\t})\n",
+		"zenoBuiltinWait()",
+		"var zenoSpawnGroup sync.WaitGroup",
+	})
+
+	// Variables declared in the block are not copies
+	runGeneratorTest(t, `fn main() {
+    let label = "a"
+    spawn {
+        let label = "b"
+        println(label)
+    }
+    println(label)
+}`, []string{
+		"\tzenoSpawn(func() {\n\t\tvar label = \"b\"\n",
+	})
+}
+
+func TestGenerateGenerics(t *testing.T) {
+	runGeneratorTest(t, `fn largest<T>(items: []T): T {
+    let mut best = items[0]
+    for item in items {
+        if item > best {
+            best = item
+        }
+    }
+    return best
+}
+
+fn contains<T>(items: []T, wanted: T): bool {
+    for item in items {
+        if item == wanted {
+            return true
+        }
+    }
+    return false
+}
+
+fn empty<T>(): []T {
+    let items: []T = []
+    return items
+}
+
+fn orElse<T>(value: Option<T>, fallback: T): T {
+    return unwrapOr(value, fallback)
+}
+
+fn main() {
+    let n = largest([3, 9]) * 2
+    println(n, contains(["a"], "b"), empty<string>())
+    println(orElse(none, 4) + 1)
+}`, []string{
+		"func largest[T zenoOrdered](items []T) T {",
+		"func contains[T comparable](items []T, wanted T) bool {",
+		"func empty[T any]() []T {\n\tvar items []T = []T{}\n",
+		"var n = (largest([]int{3, 9}) * 2)",
+		"empty[string]()",
+		"orElse(zenoOption[int]{}, 4)",
+		"type zenoOrdered interface {",
+	})
+}
+
+func TestGenerateTraits(t *testing.T) {
+	runGeneratorTest(t, `type Money = {
+    cents: int
+}
+
+impl Add for Money {
+    fn add(a: Money, b: Money): Money {
+        return Money{cents: a.cents + b.cents}
+    }
+}
+
+impl Eq for Money {
+    fn eq(a: Money, b: Money): bool {
+        return a.cents == b.cents
+    }
+}
+
+impl Ord for Money {
+    fn compare(a: Money, b: Money): int {
+        return a.cents - b.cents
+    }
+}
+
+fn main() {
+    let price = Money{cents: 250}
+    let tax = Money{cents: 50}
+    let total = price + tax
+    println(total.cents, total != price)
+    if price < total {
+        println("cheaper")
+    }
+}`, []string{
+		"func (a Money) ZenoAdd(b Money) Money {",
+		"func (a Money) ZenoCompare(b Money) int {",
+		"var total = price.ZenoAdd(tax)",
+		"(!total.ZenoEq(price))",
+		"if price.ZenoCompare(total) < 0 {",
+	})
+}
+
+func TestGenerateModuleLevelOrder(t *testing.T) {
+	// The variables a value refers to are declared before it, whatever
+	// their order in the source
+	output := runProgram(t, `let total = base * 2
+const limit = max + 1
+const max = 4
+let base = 21
+
+fn main() {
+    println(total, limit)
+}`)
+	if output != "42 5\n" {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+}
+
+func TestGenerateModuleLevelVariables(t *testing.T) {
+	runGeneratorTest(t, `fn describe(): string {
+    return greeting + str(limit)
+}
+
+const limit = 3
+let greeting = "hello"
+let mut calls = 0
+let [first, _] = [10, 20]
+
+fn bump(): int {
+    calls = calls + 1
+    return calls * first
+}
+
+fn main() {
+    println(describe(), bump())
+}`, []string{
+		"const limit = 3\n\nvar greeting = \"hello\"\nvar calls = 0\nvar zenoValue1 = []int{10, 20}\nvar first = zenoValue1[0]\n",
+		"var first = zenoValue1[0]\n\nfunc describe() string {\n\treturn (greeting + zenoBuiltinStr(limit))",
+		"\tcalls = (calls + 1)\n",
+	})
+	// In a script only the consts are package-level
+	code := runGeneratorTest(t, `const name = "script"
+
+fn show() {
+    println(name)
+}
+
+let n = 2
+show()
+println(n)`, []string{"const name = \"script\"\n", "func main() {\n\tdefer zenoRecoverPanic()\n\tvar n = 2\n"})
+	if strings.Contains(code, "\tconst name") {
+		t.Errorf("the const of a script should not be declared in main:\n%s", code)
+	}
+}
+
+func TestGenerateImportAlias(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	code := runGeneratorTest(t, `import { compile as pattern, matches } from "std/regex"
+
+fn compile(s: string): string {
+    return s + "+"
+}
+
+fn main() {
+    let re = pattern(compile("a"))
+    if re.ok {
+        println(matches(re.value, "aa"))
+    }
+}`, []string{
+		"func Compile(pattern string) zenoResult[*regexp.Regexp, string] {",
+		"func compile(s string) string {",
+		"var re = Compile(compile(\"a\"))",
+	})
+	if strings.Contains(code, "pattern(") {
+		t.Errorf("the alias should be replaced by the Go name of the function:\n%s", code)
+	}
+}
+
+func TestGenerateNamespaceImport(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	code := runGeneratorTest(t, `import * as regex from "std/regex"
+
+fn main() {
+    let re = regex.compile("a+")
+    if re.ok {
+        println(regex.matches(re.value, "aa"))
+    }
+    let io = "x"
+    println(io.length())
+}`, []string{
+		"var re = Compile(\"a+\")",
+		"fmt.Println(Matches(re.Value, \"aa\"))",
+		"func Matches(re *regexp.Regexp, text string) bool {",
+	})
+	// Only the functions called are copied
+	if strings.Contains(code, "func FindAll(") {
+		t.Errorf("uncalled functions of the module should not be generated:\n%s", code)
+	}
+}
+
+func TestGenerateTry(t *testing.T) {
+	runGeneratorTest(t, `fn safe(items: []int, i: int): int {
+    let mut value = 0
+    try {
+        value = items[i]
+    } catch e {
+        println("caught", e)
+        return -1
+    }
+    return value
+}
+
+fn main() {
+    for k in [1, 2] {
+        try {
+            panic(k)
+        } catch e {
+            break
+        }
+    }
+    println(safe([1], 3))
+}`, []string{
+		"\tif e, zenoCaught := zenoCatch(func() {\n\t\tvalue = items[i]\n\t}); zenoCaught {\n\t\tfmt.Println(\"caught\", e)\n\t\treturn (-1)\n\t}\n",
+		"\t\tif _, zenoCaught := zenoCatch(func() {\n\t\t\tzenoBuiltinPanic(k)\n\t\t}); zenoCaught {\n\t\t\tbreak\n",
+		"func zenoCatch(body func()) (message string, caught bool) {",
+	})
+}
+
+func TestGenerateChannels(t *testing.T) {
+	runGeneratorTest(t, `fn main() {
+    let jobs: Channel<int> = chan(4)
+    let names = chan<string>()
+    send(jobs, 1)
+    close(jobs)
+    for j in jobs {
+        println(j)
+    }
+    println(recv(names))
+}`, []string{
+		"var jobs chan int = make(chan int, 4)",
+		"var names = make(chan string)",
+		"zenoBuiltinSend[int](jobs, 1)",
+		"zenoBuiltinClose(jobs)",
+		"\tfor j := range jobs {\n",
+		"Println(zenoBuiltinRecv(names))",
+	})
+
+	// A break in an arm leaves the loop, not only the select
+	runGeneratorTest(t, `fn main() {
+    let ticks = chan<int>(1)
+    let done = chan<bool>(1)
+    loop {
+        when {
+            t from ticks => println(t),
+            send(ticks, 2) => println("sent"),
+            done => {
+                break
+            }
+            unused from ticks => println("dropped"),
+        }
+    }
+}`, []string{
+		"\t\t\tcase <-ticks:\n\t\t\t\tfmt.Println(\"dropped\")\n",
+		"\t\t{\n\t\t\tzenoWhenBreak1 := false\n\t\t\tselect {\n",
+		"\t\t\tcase t := <-ticks:\n\t\t\t\tfmt.Println(t)\n",
+		"\t\t\tcase ticks <- 2:\n",
+		"\t\t\tcase <-done:\n\t\t\t\tzenoWhenBreak1 = true\n\t\t\t\tbreak\n",
+		"\t\t\tif zenoWhenBreak1 {\n\t\t\t\tbreak\n\t\t\t}\n",
+	})
+}
+
+func TestGenerateBuildConstants(t *testing.T) {
+	constants, err := ParseBuildConstants([]string{"VERSION=1.2.3", "DEBUG=false", "LEVEL=2", `NAME="42"`})
+	if err != nil {
+		t.Fatalf("ParseBuildConstants failed: %v", err)
+	}
+	generate := func(input string) (string, error) {
+		p := parser.New(lexer.New(input))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("Parser errors: %v", p.Errors())
+		}
+		g := NewGenerator()
+		g.SetBuildConstants(constants)
+		return g.GenerateFile(program, "")
+	}
+
+	output, err := generate(`fn main() {
+    let level: int = build.LEVEL + 1
+    println(build.VERSION, build.NAME, level)
+    if build.DEBUG {
+        println("debug")
+    }
+}`)
+	if err != nil {
+		t.Fatalf("generation failed: %v", err)
+	}
+	for _, expected := range []string{
+		"var level int = (2 + 1)",
+		"fmt.Println(\"1.2.3\", \"42\", level)",
+		"if false {",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+
+	_, err = generate(`fn main() {
+    println(build.COMMIT)
+}`)
+	if genErr, ok := err.(GenerationError); !ok || genErr.Code != "Z0115" {
+		t.Errorf("expected Z0115 for an undefined build constant, got %v", err)
+	}
+
+	if _, err := ParseBuildConstants([]string{"1X=2"}); err == nil {
+		t.Errorf("expected an error for an invalid constant name")
+	}
+}
+
+func TestGenerateDeprecationWarnings(t *testing.T) {
+	input := `@deprecated("use sum instead")
+fn add(a: int, b: int): int {
+    return a + b
+}
+fn main() {
+    let x = add(1, 2)
+    let y = add(x, 3)
+    println(y)
+}`
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	_, warnings, err := GenerateWithWarnings(program, "")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(warnings) != 2 {
+		t.Fatalf("expected a warning at each of the 2 call sites, got %v", warnings)
+	}
+	for _, w := range warnings {
+		if w.Code != "Z0204" || w.Message != "function 'add' is deprecated: use sum instead" {
+			t.Errorf("unexpected warning: %s", w)
+		}
+	}
+}
+
+func TestGenerateSourceMap(t *testing.T) {
+	input := `fn double(n: int): int {
+    return n * 2
+}
+fn main() {
+    let x = double(4)
+    x = x + 1
+}`
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	g := NewGenerator()
+	code, err := g.GenerateFile(program, "example.zeno")
+	if err != nil {
+		t.Fatalf("Generator error: %v", err)
+	}
+
+	lines := strings.Split(code, "\n")
+	found := map[string]int{}
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "return (n * 2)" && trimmed != "x = (x + 1)" {
+			continue
+		}
+		loc, ok := g.SourceMap().Lookup(i + 1)
+		if !ok {
+			t.Fatalf("no source location for Go line %d: %q", i+1, line)
+		}
+		if loc.File != "example.zeno" {
+			t.Errorf("expected file example.zeno, got %q", loc.File)
+		}
+		found[loc.Function+": "+loc.Statement] = loc.Line
+	}
+	for want, wantLine := range map[string]int{"double: return (n * 2)": 2, "main: x = (x + 1)": 6} {
+		if found[want] != wantLine {
+			t.Errorf("expected source map entry %q at line %d, got %v", want, wantLine, found)
+		}
+	}
+}
+
+func TestSourceMapAfterFormatting(t *testing.T) {
+	// gofmt separates the const from the var with a blank line, moving the
+	// lines below
+	input := `const limit = 3
+let greeting = "hi"
+fn main() {
+    let mut y = limit
+    y = y + 1
+    println(greeting, y)
+}`
+	program := parser.New(lexer.New(input)).ParseProgram()
+	g := NewGenerator()
+	code, err := g.GenerateFile(program, "example.zeno")
+	if err != nil {
+		t.Fatalf("Generator error: %v", err)
+	}
+	for i, line := range strings.Split(code, "\n") {
+		if strings.TrimSpace(line) != "y = (y + 1)" {
+			continue
+		}
+		loc, ok := g.SourceMap().Lookup(i + 1)
+		if !ok || loc.Line != 5 || loc.Statement != "y = (y + 1)" {
+			t.Errorf("expected Go line %d to map to line 5, got %+v", i+1, loc)
+		}
+		return
+	}
+	t.Fatalf("assignment not found in:\n%s", code)
+}
+
+func TestLineDirectives(t *testing.T) {
+	input := `fn pick(items: []int, i: int): int {
+    return items[i]
+}
+fn main() {
+    let items = [1, 2]
+    println(len(items))
+    println(pick(items, 5))
+}`
+	program := parser.New(lexer.New(input)).ParseProgram()
+	g := NewGenerator()
+	code, err := g.GenerateFile(program, "app.zeno")
+	if err != nil {
+		t.Fatalf("Generator error: %v", err)
+	}
+	abs, err := filepath.Abs("app.zeno")
+	if err != nil {
+		t.Fatal(err)
+	}
+	annotated := g.SourceMap().LineDirectives(code, "app.go")
+	for _, sub := range []string{
+		"//line " + filepath.ToSlash(abs) + ":1\nfunc pick(",
+		"//line " + filepath.ToSlash(abs) + ":5\n\tvar items = ",
+		// Go counts the following lines on its own
+		"\n\tfmt.Println(pick(items, 5))",
+		"//line app.go:",
+	} {
+		if !strings.Contains(annotated, sub) {
+			t.Errorf("expected %q in:\n%s", sub, annotated)
+		}
+	}
+
+	// The directives must leave the file formatted
+	gofmtPath, err := exec.LookPath("gofmt")
+	if err != nil {
+		t.Skip("gofmt is not installed")
+	}
+	file := filepath.Join(t.TempDir(), "app.go")
+	if err := os.WriteFile(file, []byte(annotated), 0644); err != nil {
+		t.Fatal(err)
+	}
+	output, err := exec.Command(gofmtPath, "-l", file).CombinedOutput()
+	if err != nil || len(output) > 0 {
+		t.Errorf("gofmt -l reported %s (%v) for:\n%s", output, err, annotated)
+	}
+}
+
+func TestFormatCodeInvalid(t *testing.T) {
+	_, err := formatCode("package main\n\nfunc main() {\n\tx := \n}\n", &SourceMap{lines: map[int]SourceLocation{}})
+	genErr, ok := err.(GenerationError)
+	if !ok || genErr.Code != "Z0154" {
+		t.Fatalf("expected a Z0154 error, got %v", err)
+	}
+	if !strings.Contains(genErr.Message, "   4 | \tx := ") {
+		t.Errorf("expected the numbered code in the message, got:\n%s", genErr.Message)
+	}
+}
+
+func TestGenerateErrorPosition(t *testing.T) {
+	input := `fn add(a: int, b: int): int {
+    return a + b
+}
+fn main() {
+    let x = 1
+    println(add(x))
+}`
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	_, err := Generate(program)
+	genErr, ok := err.(GenerationError)
+	if !ok {
+		t.Fatalf("expected GenerationError, got %v", err)
+	}
+	if genErr.Pos.Line != 6 || genErr.Pos.Column != 13 {
+		t.Errorf("expected error at 6:13, got %s", genErr.Pos)
+	}
+	if !strings.Contains(genErr.Error(), "line 6, column 13") {
+		t.Errorf("expected location in error message, got: %v", genErr)
+	}
+}
+
+func TestGenerateStdSet(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	runGeneratorTest(t, `import { newSet, add, has, size } from "std/set"
+fn main() {
+    let s = newSet(1, 2)
+    add(s, 3)
+    let n = size(s)
+    if has(s, n) {
+        add(s, n)
+    }
+}`, []string{
+		"func NewSet(items ...interface{}) interface{} {",
+		"return zenoNativeSetNew(items)",
+		"func Add(set interface{}, item interface{}) bool {",
+		"type zenoSet[T comparable] struct {",
+		"var s = NewSet(1, 2)",
+		"Add(s, 3)",
+	})
+}
+
+func TestGenerateStdIter(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	runGeneratorTest(t, `import { count, filter, take } from "std/iter"
+fn isLarge(x: int): bool {
+    return x > 2
+}
+fn main() {
+    for n in take(filter(count(1), isLarge), 3) {
+        println(n)
+    }
+}`, []string{
+		"func Filter(it *zenoIterator, predicate interface{}) *zenoIterator {",
+		"return zenoNativeIterFilter(it, predicate)",
+		"type zenoIterator struct {",
+		"for zenoIt := Take(Filter(Count(1), isLarge), 3); zenoIt.hasNext(); {",
+		"n := zenoIt.next()",
+	})
+}
+
+func TestGenerateStdStrBuilder(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	runGeneratorTest(t, `import { newBuilder, append, appendLine, toString } from "std/strbuilder"
+fn main() {
+    let b = newBuilder()
+    append(b, "a")
+    appendLine(b, "b")
+    println(toString(b))
+}`, []string{
+		"func Append(builder interface{}, text string) {",
+		"zenoNativeBuilderAppend(builder, text)",
+		"func zenoNativeBuilderNew() interface{} {",
+		"var b = NewBuilder()",
+		"AppendLine(b, \"b\")",
+	})
+}
+
+func TestGenerateStdParallel(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	runGeneratorTest(t, `import { parallelMap, waitAll } from "std/parallel"
+fn square(x: int): int {
+    return x * x
+}
+fn work() {
+    println("done")
+}
+fn main() {
+    println(parallelMap([1, 2, 3], square))
+    waitAll(work, work)
+}`, []string{
+		"func ParallelMap(values interface{}, transform interface{}) interface{} {",
+		"return zenoNativeParallelMap(values, transform, 0)",
+		"func zenoNativeWaitAll(tasks []interface{}) {",
+		"\"sync\"",
+		"WaitAll(work, work)",
+	})
+}
+
+func TestGenerateStdDB(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	input := `import { open, exec, query } from "std/db"
+fn main() {
+    let db = open(":memory:")
+    exec(db, "CREATE TABLE t (n INTEGER)")
+    println(query(db, "SELECT n FROM t WHERE n > ?", 1))
+}`
+	runGeneratorTest(t, input, []string{
+		"_ \"modernc.org/sqlite\"",
+		"\"database/sql\"",
+		"func Query(db interface{}, query string, args ...interface{}) interface{} {",
+		"func zenoNativeDBRows(rows *sql.Rows, err error) []interface{} {",
+		"var db = Open(\":memory:\")",
+	})
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	g := NewGenerator()
+	if _, err := g.GenerateFile(program, ""); err != nil {
+		t.Fatalf("generation failed: %v", err)
+	}
+	if modules := g.GoModules(); len(modules) != 1 || !strings.HasPrefix(modules[0], "modernc.org/sqlite ") {
+		t.Errorf("expected the SQLite driver module, got %v", modules)
+	}
+
+	output, err := Generate(parser.New(lexer.New("fn main() {\n    println(1)\n}")).ParseProgram())
+	if err != nil {
+		t.Fatalf("generation failed: %v", err)
+	}
+	if strings.Contains(output, "sqlite") || strings.Contains(output, "database/sql") {
+		t.Errorf("programs without std/db must not depend on the SQLite driver:\n%s", output)
+	}
+}
+
+func TestGenerateUsedImportsOnly(t *testing.T) {
+	output, err := Generate(parser.New(lexer.New("fn main() {\n    let x = 1 + 2\n    print(x)\n}")).ParseProgram())
+	if err != nil {
+		t.Fatalf("generation failed: %v", err)
+	}
+	// os, runtime and strings come with the panic handler of main
+	if !strings.Contains(output, "import (\n\t\"fmt\"\n\t\"os\"\n\t\"runtime\"\n\t\"strings\"\n)\n") {
+		t.Errorf("expected only the packages in use to be imported:\n%s", output)
+	}
+	if strings.Contains(output, "zenoNativeReadFile") {
+		t.Errorf("expected helpers the program does not use to be left out:\n%s", output)
+	}
+}
+
+func TestGeneratePanicHandler(t *testing.T) {
+	program := parser.New(lexer.New("fn main() {\n    panic(\"stop\")\n}")).ParseProgram()
+	output, err := Generate(program)
+	if err != nil {
+		t.Fatalf("generation failed: %v", err)
+	}
+	for _, sub := range []string{"func main() {\n\tdefer zenoRecoverPanic()\n", "func zenoRecoverPanic() {", "return \"division by zero\""} {
+		if !strings.Contains(output, sub) {
+			t.Errorf("expected %q in:\n%s", sub, output)
+		}
+	}
+
+	g := NewGenerator()
+	g.SetOptions(GeneratorOptions{Debug: true})
+	output, err = g.GenerateFile(program, "")
+	if err != nil {
+		t.Fatalf("generation failed: %v", err)
+	}
+	if strings.Contains(output, "zenoRecoverPanic") {
+		t.Errorf("expected panics to be left to Go with Debug:\n%s", output)
+	}
+}
+
+func TestGenerateSpawnPanic(t *testing.T) {
+	goPath, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not installed")
+	}
+	program := parser.New(lexer.New("fn main() {\n    let xs = [1]\n    spawn {\n        println(xs[3])\n    }\n    wait()\n}")).ParseProgram()
+	code, err := Generate(program)
+	if err != nil {
+		t.Fatalf("generation failed: %v", err)
+	}
+	file := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(file, []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+	output, err := exec.Command(goPath, "run", file).CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected the program to fail:\n%s", output)
+	}
+	if !strings.HasPrefix(string(output), "panic: index 3 is out of range for length 1\n") || strings.Contains(string(output), "goroutine") {
+		t.Errorf("expected the panic of the spawn block in Zeno terms, got:\n%s", output)
+	}
+
+	// With Debug, the goroutines leave panics to Go like main
+	g := NewGenerator()
+	g.SetOptions(GeneratorOptions{Debug: true})
+	code, err = g.GenerateFile(program, "")
+	if err != nil {
+		t.Fatalf("generation failed: %v", err)
+	}
+	if strings.Contains(code, "zenoRecoverPanic") {
+		t.Errorf("expected panics to be left to Go with Debug:\n%s", code)
+	}
+}
+
+func TestGenerateStdArchive(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	runGeneratorTest(t, `import { create, list } from "std/archive"
+fn main() {
+    create("out.zip", "a.txt", "b.txt")
+    println(list("out.zip"))
+}`, []string{
+		"func Create(archive string, paths ...string) bool {",
+		"return zenoNativeArchiveCreate(archive, paths)",
+		"\"archive/zip\"",
+		"func zenoNativeArchiveList(archive string) []interface{} {",
+		"Create(\"out.zip\", \"a.txt\", \"b.txt\")",
+	})
+}
+
+func TestGenerateStdDatetime(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	runGeneratorTest(t, `import { parse, format, add, year } from "std/datetime"
+fn main() {
+    let d = add(parse("iso", "2024-05-01"), "24h")
+    println(format(d, "YYYY-MM-DD"), year(d))
+}`, []string{
+		"func Parse(layout string, text string) interface{} {",
+		"return zenoNativeDatetimeAdd(date, duration, 1)",
+		"return zenoNativeDatetimeComponent(date, \"year\")",
+		"\"time\"",
+		"var d = Add(Parse(\"iso\", \"2024-05-01\"), \"24h\")",
+	})
+}
+
+func TestGenerateStdSemver(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	runGeneratorTest(t, `import { satisfies, sort } from "std/semver"
+fn main() {
+    println(satisfies("1.4.0", "^1.2.0"))
+    println(sort(["1.10.0", "1.2.0"]))
+}`, []string{
+		"func Satisfies(version string, constraint string) bool {",
+		"return zenoNativeSemverSort(versions)",
+		"type zenoSemver struct {",
+		"\"strconv\"",
+		"Satisfies(\"1.4.0\", \"^1.2.0\")",
+	})
+}
+
+func TestGenerateStdAnsi(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	runGeneratorTest(t, `import { bold, red } from "std/ansi"
+fn main() {
+    println(bold(red("error")))
+}`, []string{
+		"func Red(text string) string {",
+		"return zenoNativeAnsiStyle(text, \"31\")",
+		"var zenoAnsiEnabled = zenoNativeAnsiDetect()",
+		"os.Getenv(\"NO_COLOR\")",
+		"Bold(Red(\"error\"))",
+	})
+}
+
+func TestGenerateStdCollections(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	runGeneratorTest(t, `import { newSet, newSortedMap } from "std/collections"
+fn main() {
+    let seen = newSet<string>()
+    seen.add("a")
+    let counts = newSortedMap<string, int>()
+    counts.set("a", unwrapOr(counts.get("a"), 0) + 1)
+    for key in counts {
+        println(key, seen.contains(key))
+    }
+}`, []string{
+		"func NewSet[T any]() *zenoHashSet[T] {",
+		"return zenoNativeNewSet[T]()",
+		"var seen = NewSet[string]()",
+		"seen.add(\"a\")",
+		"counts.set(\"a\", (zenoBuiltinUnwrapOr(counts.get(\"a\"), 0) + 1))",
+		"for _, key := range counts.keys() {",
+		"type zenoSortedMap[K any, V any] struct {",
+	})
+}
+
+func TestGenerateStdRegex(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	// Backslashes of the pattern reach Go escaped
+	runGeneratorTest(t, `import { compile, replace } from "std/regex"
+fn main() {
+    let re = compile("\\d+")
+    if re.ok {
+        println(replace(re.value, "a1b2", "#"))
+    }
+}`, []string{
+		"func Compile(pattern string) zenoResult[*regexp.Regexp, string] {",
+		"func Replace(re *regexp.Regexp, text string, replacement string) string {",
+		"var re = Compile(\"\\\\d+\")",
+		"\t\"regexp\"",
+	})
+}
+
+func TestGenerateStdSync(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	runGeneratorTest(t, `import { newMutex, lock, unlock, newAtomicInt, atomicAdd } from "std/sync"
+fn main() {
+    let m = newMutex()
+    let hits = newAtomicInt(0)
+    spawn {
+        lock(m)
+        atomicAdd(hits, 1)
+        unlock(m)
+    }
+    wait()
+}`, []string{
+		"func NewMutex() *sync.Mutex {",
+		"func AtomicAdd(a *atomic.Int64, delta int) int {",
+		"\t\tm, hits := m, hits\n",
+		"\t\"sync/atomic\"",
+	})
+}
+
+func TestGenerateStdJson(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	runGeneratorTest(t, `import { decode, encode } from "std/json"
+type User = {
+    name: string
+}
+fn main() {
+    let user = decode<User>("{\"name\": \"Ann\"}")
+    if user.ok {
+        println(user.value.name, encode(user.value).value)
+    }
+}`, []string{
+		"Name string `json:\"name\"`",
+		"func Decode[T any](jsonString string) zenoResult[T, string] {",
+		"return zenoNativeJsonDecode[T](jsonString)",
+		"func zenoNativeJsonDecode[T any](jsonString string) zenoResult[T, string] {",
+		"var user = Decode[User](",
+		"user.Value.Name",
+	})
+}
+
+func TestGenerateStdIo(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	runGeneratorTest(t, `import { readFile, readDir, openReader, readLine } from "std/io"
+fn main() {
+    let entries = readDir(".")
+    let reader = openReader("notes.txt")
+    if entries.ok && reader.ok {
+        println(entries.value, readLine(reader.value).value, readFile("notes.txt"))
+    }
+}`, []string{
+		"func ReadFile(path string) string {",
+		"return zenoNativeReadFile(path)",
+		"func ReadDir(path string) zenoResult[[]string, string] {",
+		"func OpenReader(path string) zenoResult[interface{}, string] {",
+		"type zenoIoStream struct {",
+		"\t\"bufio\"",
+	})
+}
+
+func TestGenerateStdOs(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	runGeneratorTest(t, `import { env, exec, exit } from "std/os"
+fn main() {
+    let result = exec("git", ["status"])
+    if result.ok {
+        println(env("HOME"), result.value)
+    } else {
+        exit(1)
+    }
+}`, []string{
+		"func Exec(command string, args []string) zenoResult[string, string] {",
+		"return zenoNativeOsExec(command, args)",
+		"\t\"os/exec\"",
+		"zenoNativeOsExit(code)",
+	})
+}
+
+func TestGenerateStdHttp(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	// The Response type comes with the functions that return it
+	runGeneratorTest(t, `import { get, header } from "std/http"
+fn main() {
+    let r = get("https://example.com")
+    if r.ok {
+        println(r.value.status, header(r.value, "Content-Type"))
+    }
+}`, []string{
+		"type Response struct {",
+		"func Get(url string) zenoResult[Response, string] {",
+		"var zenoHttpClient = &http.Client{Timeout: 30 * time.Second}",
+		"\t\"net/http\"",
+		"r.Value.Status",
+	})
+
+	runGeneratorTest(t, `import { serve, respond } from "std/http"
+fn handle(req: Request): Response {
+    return respond(200, req.path)
+}
+fn main() {
+    println(serve(":8080", handle))
+}`, []string{
+		"type Request struct {",
+		"func handle(req Request) Response {",
+		"zenoNativeCall(handler, request).(Response)",
+		"fmt.Println(Serve(\":8080\", handle))",
+	})
+}
+
+func TestGenerateStdPrompt(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	runGeneratorTest(t, `import { prompt, confirm } from "std/prompt"
+fn main() {
+    let name = prompt("Name: ")
+    if confirm("Continue?") {
+        println(name)
+    }
+}`, []string{
+		"func Confirm(question string) bool {",
+		"return zenoNativeConfirm(question, false)",
+		"var zenoPromptReader = bufio.NewReader(os.Stdin)",
+		"var name = Prompt(\"Name: \")",
+	})
+}
+
+func TestGenerateWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"lib/text.zeno": `import { shout } from "./loud"
+fn format(name: string): string {
+    return "hello " + name
+}
+pub fn greet(name: string): string {
+    return shout(format(name))
+}`,
+		"lib/loud.zeno": `pub fn shout(s: string): string {
+    return s + "!"
+}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	input := `import { greet } from "./lib/text"
+fn format(n: int): string {
+    return str(n)
+}
+fn main() {
+    let s = greet(format(1))
+    s = s + "."
+}`
+	program := parser.New(lexer.New(input)).ParseProgram()
+	workspace, err := NewGenerator().GenerateWorkspace(program, filepath.Join(dir, "app.zeno"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var dirs []string
+	for _, pkg := range workspace.Packages {
+		dirs = append(dirs, pkg.Dir)
+	}
+	if strings.Join(dirs, ",") != ",lib/loud,lib/text" {
+		t.Fatalf("unexpected packages %q", dirs)
+	}
+	expected := map[string][]string{
+		"main.go": {
+			"package main",
+			`zeno_lib_text "zenoprogram/lib/text"`,
+			"var s = zeno_lib_text.Greet(format(1))",
+			"func format(n int) string {",
+		},
+		"lib/text/text.go": {
+			"package text",
+			`zeno_lib_loud "zenoprogram/lib/loud"`,
+			"func format(name string) string {",
+			"func Greet(name string) string {",
+			"return zeno_lib_loud.Shout(format(name))",
+		},
+		"lib/loud/loud.go": {
+			"package loud",
+			"func Shout(s string) string {",
+		},
+	}
+	for _, pkg := range workspace.Packages {
+		for _, sub := range expected[pkg.File()] {
+			if !strings.Contains(pkg.Code, sub) {
+				t.Errorf("%s does not contain %q:\n%s", pkg.File(), sub, pkg.Code)
+			}
+		}
+	}
+	if strings.Contains(workspace.Packages[1].Code, "func main()") {
+		t.Errorf("module package has a main function")
+	}
+	// Source map lines account for the imports written above the code
+	lines := strings.Split(workspace.Main().Code, "\n")
+	for i, line := range lines {
+		if strings.Contains(line, "zeno_lib_text.Greet") {
+			loc, ok := workspace.Main().SourceMap.Lookup(i + 1)
+			if !ok || loc.Line != 6 {
+				t.Errorf("expected Go line %d to map to Zeno line 6, got %+v", i+1, loc)
+			}
+		}
+	}
+}
+
+func TestGenerateReexports(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+	dir := t.TempDir()
+	files := map[string]string{
+		"lib/api.zeno": `pub import { shout, whisper as quiet } from "./internal/text"
+pub import { println as say } from "std/fmt"
+pub fn version(): string {
+    return "1"
+}`,
+		"lib/internal/text.zeno": `pub fn shout(s: string): string {
+    return s + "!"
+}
+pub fn whisper(s: string): string {
+    return s + "..."
+}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	input := `import { shout, quiet, say, version } from "./lib/api"
+fn main() {
+    say(shout(version()), quiet("a"))
+}`
+	program := parser.New(lexer.New(input)).ParseProgram()
+	workspace, err := NewGenerator().GenerateWorkspace(program, filepath.Join(dir, "app.zeno"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The functions are called in the module declaring them
+	for _, sub := range []string{
+		`zeno_lib_internal__text "zenoprogram/lib/internal_/text"`,
+		"Println(zeno_lib_internal__text.Shout(zeno_lib_api.Version()), zeno_lib_internal__text.Whisper(\"a\"))",
+		"func Println(",
+	} {
+		if !strings.Contains(workspace.Main().Code, sub) {
+			t.Errorf("main.go does not contain %q:\n%s", sub, workspace.Main().Code)
+		}
+	}
+}
+
+func TestGenerateWorkspaceHelpers(t *testing.T) {
+	dir := t.TempDir()
+	module := `import { now, format } from "std/datetime"
+pub fn stamp(): string {
+    return format(now(), "YYYY")
+}`
+	if err := os.WriteFile(filepath.Join(dir, "stamp.zeno"), []byte(module), 0644); err != nil {
+		t.Fatal(err)
+	}
+	input := `import { stamp } from "./stamp"
+fn main() {
+    println(stamp())
+}`
+	program := parser.New(lexer.New(input)).ParseProgram()
+	workspace, err := NewGenerator().GenerateWorkspace(program, filepath.Join(dir, "app.zeno"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The helpers of std/datetime belong to the package importing it
+	if main := workspace.Main().Code; strings.Contains(main, "zenoNativeDatetime") || strings.Contains(main, "\"time\"") {
+		t.Errorf("expected the main package to leave the helpers of its modules out:\n%s", main)
+	}
+	lib := workspace.Packages[1].Code
+	if n := strings.Count(lib, "func zenoNativeDatetimeFormat("); n != 1 {
+		t.Errorf("expected the module to declare zenoNativeDatetimeFormat once, got %d:\n%s", n, lib)
+	}
+}
+
+func TestGenerateTransitiveTypes(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"lib/shapes.zeno": "type Point = {\n    x: int\n    y: int\n}",
+		"lib/geo.zeno": `import { type Point } from "./shapes"
+pub fn origin(): Point {
+    return Point { x: 0, y: 0 }
+}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	input := `import { origin } from "./lib/geo"
+fn originX(): int {
+    let p = origin()
+    return p.x
+}
+fn main() {
+    originX()
+}`
+	program := parser.New(lexer.New(input)).ParseProgram()
+	workspace, err := NewGenerator().GenerateWorkspace(program, filepath.Join(dir, "app.zeno"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Point comes with origin, from the module declaring it
+	for _, sub := range []string{
+		"type Point = zeno_lib_shapes.Point",
+		"var p = zeno_lib_geo.Origin()",
+		"return p.X",
+	} {
+		if !strings.Contains(workspace.Main().Code, sub) {
+			t.Errorf("main.go does not contain %q:\n%s", sub, workspace.Main().Code)
+		}
+	}
+}
+
+func TestGenerateImportCycle(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"lib/a.zeno": "import { b } from \"./b\"\npub fn a(): int {\n    return 1\n}",
+		"lib/b.zeno": "import { c } from \"./c\"\npub fn b(): int {\n    return c()\n}",
+		"lib/c.zeno": "import { a } from \"./a\"\npub fn c(): int {\n    return a()\n}",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	program := parser.New(lexer.New("import { a } from \"./lib/a\"\nfn main() {\n    a()\n}")).ParseProgram()
+	_, err := NewGenerator().GenerateWorkspace(program, filepath.Join(dir, "app.zeno"))
+	genErr, ok := err.(GenerationError)
+	if !ok {
+		t.Fatalf("expected a GenerationError, got %v", err)
+	}
+	if genErr.Code != "Z0153" || genErr.Message != "Import cycle: lib/a.zeno → lib/b.zeno → lib/c.zeno → lib/a.zeno" {
+		t.Errorf("unexpected error %q", genErr.Message)
+	}
+	// The cycle is reported at the import closing it
+	if !strings.HasSuffix(genErr.File, "c.zeno") || genErr.Pos.Line != 1 {
+		t.Errorf("expected the error at c.zeno:1, got %s:%d", genErr.File, genErr.Pos.Line)
+	}
+}
+
+func TestGenerateRemoteModule(t *testing.T) {
+	defer func(dir string) { deps.CacheDir = dir }(deps.CacheDir)
+	deps.CacheDir = t.TempDir()
+	dir := t.TempDir()
+	input := `import { shout } from "example.com/user/pkg/text"
+fn main() {
+    shout("hi")
+}`
+	program := parser.New(lexer.New(input)).ParseProgram()
+	_, err := NewGenerator().GenerateWorkspace(program, filepath.Join(dir, "app.zeno"))
+	if err == nil || !strings.Contains(err.Error(), "[Z0152]") || !strings.Contains(err.Error(), "zeno get example.com/user/pkg") {
+		t.Fatalf("expected a module not fetched, got %v", err)
+	}
+
+	files := map[string]string{
+		filepath.Join(dir, deps.LockFile):                                      "example.com/user/pkg v1.0.0 0123456789abcdef0123456789abcdef01234567\n",
+		filepath.Join(deps.CacheDir, "example.com/user/pkg@v1.0.0/text.zeno"):  "import { mark } from \"./marks\"\npub fn shout(s: string): string {\n    return s + mark()\n}",
+		filepath.Join(deps.CacheDir, "example.com/user/pkg@v1.0.0/marks.zeno"): "pub fn mark(): string {\n    return \"!\"\n}",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	workspace, err := NewGenerator().GenerateWorkspace(program, filepath.Join(dir, "app.zeno"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var dirs []string
+	for _, pkg := range workspace.Packages[1:] {
+		dirs = append(dirs, pkg.Dir)
+	}
+	expected := []string{"deps/example_com/user/pkg_v1_0_0/marks", "deps/example_com/user/pkg_v1_0_0/text"}
+	if !reflect.DeepEqual(dirs, expected) {
+		t.Errorf("expected packages %v, got %v", expected, dirs)
+	}
+	if !strings.Contains(workspace.Main().Code, "zeno_deps_example_com_user_pkg_v1_0_0_text.Shout(\"hi\")") {
+		t.Errorf("expected a call to the remote package:\n%s", workspace.Main().Code)
+	}
+}
+
+func TestGenerateStdPath(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	// Modules missing from the search path are built in
+	stdlib.Dir = t.TempDir()
+	loud := "pub fn shout(s: string): string {\n    return s + \"!\"\n}\n"
+	if err := os.WriteFile(filepath.Join(stdlib.Dir, "loud.zeno"), []byte(loud), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGeneratorTest(t, `import { println } from "std/fmt"
+import { shout } from "std/loud"
+fn main() {
+    println(shout("hi"))
+}`, []string{"func Shout(s string) string", "func Println("})
+}
+
+// mapCache is a PackageCache in memory that counts its hits
+type mapCache struct {
+	entries map[string]*CachedPackage
+	hits    int
+}
+
+func (c *mapCache) Load(key string) (*CachedPackage, bool) {
+	pkg, ok := c.entries[key]
+	if ok {
+		c.hits++
+	}
+	return pkg, ok
+}
+
+func (c *mapCache) Store(key string, pkg *CachedPackage) {
+	c.entries[key] = pkg
+}
+
+func TestGenerateWorkspaceCache(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("text.zeno", `import { shout } from "./loud"
+pub fn greet(name: string): string {
+    return shout("hello " + name)
+}`)
+	write("loud.zeno", `pub fn shout(s: string): string {
+    return s + "!"
+}`)
+	cache := &mapCache{entries: make(map[string]*CachedPackage)}
+	generate := func() *Workspace {
+		program := parser.New(lexer.New(`import { greet } from "./text"
+fn main() {
+    println(greet("zeno"))
+}`)).ParseProgram()
+		gen := NewGenerator()
+		gen.SetCache(cache)
+		workspace, err := gen.GenerateWorkspace(program, filepath.Join(dir, "app.zeno"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return workspace
+	}
+
+	first := generate()
+	if len(cache.entries) != 2 || cache.hits != 0 {
+		t.Fatalf("expected 2 entries and no hits, got %d and %d", len(cache.entries), cache.hits)
+	}
+	second := generate()
+	if cache.hits != 2 {
+		t.Fatalf("expected both modules to be loaded from the cache, got %d hits", cache.hits)
+	}
+	if len(second.Packages) != len(first.Packages) {
+		t.Fatalf("expected %d packages, got %d", len(first.Packages), len(second.Packages))
+	}
+	for i, pkg := range second.Packages[1:] {
+		if pkg.Code != first.Packages[i+1].Code {
+			t.Errorf("%s differs when loaded from the cache:\n%s", pkg.File(), pkg.Code)
+		}
+	}
+
+	// Changing a module changes the keys of the modules importing it
+	write("loud.zeno", `pub fn shout(s: string): string {
+    return s + "!!"
+}`)
+	generate()
+	if len(cache.entries) != 4 || cache.hits != 2 {
+		t.Errorf("expected both modules to be generated again, got %d entries and %d hits", len(cache.entries), cache.hits)
+	}
+
+	// Another build of the compiler does not reuse them either
+	defer func(id func() string) { compilerID = id }(compilerID)
+	compilerID = func() string { return "another build" }
+	generate()
+	if len(cache.entries) != 6 || cache.hits != 2 {
+		t.Errorf("expected another compiler to generate both modules again, got %d entries and %d hits", len(cache.entries), cache.hits)
+	}
+}
+
+func TestPackageDir(t *testing.T) {
+	tests := []struct {
+		file     string
+		expected string
+	}{
+		{"/src/utils.zeno", "utils"},
+		{"/src/lib/math-utils.zeno", "lib/math_utils"},
+		{"/shared/2d.zeno", "parent/shared/m2d"},
+		{"/src/lib/internal/text.zeno", "lib/internal_/text"},
+	}
+	for _, tt := range tests {
+		if got := packageDir("/src", tt.file); got != tt.expected {
+			t.Errorf("packageDir(%q) = %q, want %q", tt.file, got, tt.expected)
+		}
+	}
+}
+
+func TestGenerateStructTypes(t *testing.T) {
+	runGeneratorTest(t, `type Point = {
+    x: int
+    y: int
+    name: string
+}
+type Box<T> = {
+    value: T
+}
+fn shift(p: Point): Point {
+    return Point{x: p.x + 1, y: p.y, name: p.name}
+}
+fn main() {
+    let p = shift(Point{x: 1, y: 2, name: "a"})
+    let b = Box{value: 1.5}
+    let r = int("3")
+    println(p.x, b.value, r.value)
+}`, []string{
+		"type Point struct {\n\tX    int    `json:\"x\"`\n\tY    int    `json:\"y\"`\n\tName string `json:\"name\"`\n}",
+		"func (v Point) String() string {\n\treturn fmt.Sprintf(\"Point{x: %v, y: %v, name: %q}\", v.X, v.Y, v.Name)\n}",
+		"type Box[T any] struct {\n\tValue T `json:\"value\"`\n}",
+		"func (v Box[T]) String() string {",
+		"func shift(p Point) Point {",
+		"return Point{X: (p.X + 1), Y: p.Y, Name: p.Name}",
+		"var b = Box[float64]{Value: 1.5}",
+		`fmt.Println(p.X, b.Value, r.Value)`,
+	})
+}
+
+func TestGenerateUnknownField(t *testing.T) {
+	tests := []string{
+		"type Point = {\n    x: int\n}\nfn main() {\n    let p = Point{x: 1, z: 2}\n    println(p)\n}",
+		"type Point = {\n    x: int\n}\nfn main() {\n    let p = Point{x: 1}\n    println(p.z)\n}",
+	}
+	for _, input := range tests {
+		program := parser.New(lexer.New(input)).ParseProgram()
+		_, err := Generate(program)
+		if err == nil || !strings.Contains(err.Error(), "[Z0116] Type 'Point' has no field 'z'") {
+			t.Errorf("expected unknown field error for input:\n%s\ngot: %v", input, err)
+		}
+	}
+}
+
+func TestGenerateUnknownType(t *testing.T) {
+	input := "type Point = {\n    x: int\n}\nfn main() {\n    let p = Pont{x: 1}\n    println(p)\n}"
+	program := parser.New(lexer.New(input)).ParseProgram()
+	_, err := Generate(program)
+	if err == nil || !strings.Contains(err.Error(), "[Z0158] Unknown type Pont") {
+		t.Errorf("expected an unknown type error, got: %v", err)
+	}
+}
+
+func TestGenerateMatch(t *testing.T) {
+	runGeneratorTest(t, `fn main() {
+    let n = 2
+    let name = match n {
+        1 => "one",
+        _ => {
+            let s = str(n)
+            s
+        },
+    }
+    let half = match n {
+        1 => 1,
+        2 => 2.5,
+    }
+    match n {
+        0 => println("zero"),
+        _ => println(name, half),
+    }
+}`, []string{
+		"var name = func() string {\n\t\tswitch n {\n\t\tcase 1:\n\t\t\treturn \"one\"\n\t\tdefault:\n",
+		"var half = func() float64 {\n\t\tzenoMatch := n\n\t\tswitch zenoMatch {",
+		"panic(fmt.Sprintf(\"no match arm for %v\", zenoMatch))",
+		"switch n {\n\tcase 0:\n\t\tfmt.Println(\"zero\")\n\tdefault:\n",
+	})
+
+	program := parser.New(lexer.New("fn main() {\n    let x = match 1 {\n        _ => {\n            let y = 1\n        },\n    }\n    println(x)\n}")).ParseProgram()
+	_, err := Generate(program)
+	if err == nil || !strings.Contains(err.Error(), "[Z0123]") {
+		t.Errorf("expected an error for a match arm without a value, got: %v", err)
+	}
+}
+
+func TestGenerateEnum(t *testing.T) {
+	runGeneratorTest(t, `enum Shape {
+    Circle(float),
+    Named(string),
+    Empty,
+}
+fn area(s: Shape): float {
+    return match s {
+        Circle(r) => 3.0 * r * r,
+        _ => 0.0,
+    }
+}
+fn main() {
+    let s = Circle(2.0)
+    match s {
+        Named(_) => println("named"),
+        Empty => println("empty"),
+    }
+    println(area(s), Named("x"), Empty)
+}`, []string{
+		"type Shape interface {\n\tisShape()\n}",
+		"type ShapeCircle struct {\n\tF0 float64\n}",
+		"func (ShapeCircle) isShape() {}",
+		"func (v ShapeNamed) String() string {\n\treturn fmt.Sprintf(\"Named(%q)\", v.F0)\n}",
+		"type ShapeEmpty struct{}",
+		"switch zenoValue := s.(type) {\n\t\tcase ShapeCircle:\n\t\t\tr := zenoValue.F0\n",
+		"var s = Shape(ShapeCircle{2.0})",
+		"switch s.(type) {\n\tcase ShapeNamed:",
+		"fmt.Println(area(s), Shape(ShapeNamed{\"x\"}), Shape(ShapeEmpty{}))",
+	})
+}
+
+func TestGenerateResult(t *testing.T) {
+	runGeneratorTest(t, `fn half(n: int): Result<int> {
+    if n < 0 {
+        return err("negative")
+    }
+    return ok(n / 2)
+}
+fn quarter(s: string): Result<int, string> {
+    let n = int(s)?
+    return ok(half(half(n)?)?)
+}
+fn main() {
+    let q = quarter("8")
+    if q.ok {
+        println(q.value)
+    } else {
+        println(q.error, ok(1))
+    }
+}`, []string{
+		"type zenoResult[T any, E any] struct {",
+		"func half(n int) zenoResult[int, string] {",
+		"return zenoResult[int, string]{Error: \"negative\"}",
+		"return zenoResult[int, string]{Ok: true, Value: (n / 2)}",
+		"\tzenoTry1 := zenoBuiltinInt(s)\n\tif !zenoTry1.Ok {\n\t\treturn zenoResult[int, string]{Error: zenoTry1.Error}\n\t}\n\tvar n = zenoTry1.Value\n",
+		"\tzenoTry2 := half(n)\n",
+		"\tzenoTry3 := half(zenoTry2.Value)\n",
+		"return zenoResult[int, string]{Ok: true, Value: zenoTry3.Value}",
+		"if q.Ok {\n\t\tfmt.Println(q.Value)",
+		"fmt.Println(q.Error, zenoResult[int, string]{Ok: true, Value: 1})",
+	})
+
+	program := parser.New(lexer.New("fn parse(s: string): Result<int> {\n    let n = match s {\n        \"\" => 0,\n        _ => int(s)?,\n    }\n    return ok(n)\n}")).ParseProgram()
+	_, err := Generate(program)
+	if err == nil || !strings.Contains(err.Error(), "[Z0129]") {
+		t.Errorf("expected an error for ? in a match arm, got: %v", err)
+	}
+}
+
+func TestGenerateOption(t *testing.T) {
+	runGeneratorTest(t, `fn find(n: int): Option<int> {
+    if n > 0 {
+        return some(n)
+    }
+    return none
+}
+fn show(o: Option<float>): float {
+    return match o {
+        some(x) => x,
+        none => 0.0,
+    }
+}
+fn main() {
+    let found = find(2)
+    match found {
+        some(n) => println(n),
+        none => println("none"),
+    }
+    println(unwrapOr(found, 0), show(some(1)), show(none))
+}`, []string{
+		"type zenoOption[T any] struct {",
+		"func find(n int) zenoOption[int] {",
+		"return zenoOption[int]{Some: true, Value: n}",
+		"return zenoOption[int]{}",
+		"switch zenoValue := found; {\n\tcase zenoValue.Some:\n\t\tn := zenoValue.Value\n\t\t_ = n\n\t\tfmt.Println(n)\n\tcase !zenoValue.Some:\n",
+		"return func() float64 {",
+		"fmt.Println(zenoBuiltinUnwrapOr(found, 0), show(zenoOption[float64]{Some: true, Value: 1}), show(zenoOption[float64]{}))",
+	})
+}
+
+func TestGenerateIndexExpression(t *testing.T) {
+	runGeneratorTest(t, `fn main() {
+    let items = [10, 20, 30]
+    let name = "zeno"
+    let config = {debug: true}
+    println(items[0], items[1:], items[:len(items) - 1], name[0], name[1:3], config["debug"])
+}`, []string{
+		"items[0]",
+		"items[1:]",
+		"items[:(zenoBuiltinLen(items)-1)]",
+		"string([]rune(name)[0])",
+		"string([]rune(name)[1:3])",
+		"config[\"debug\"]",
+	})
+}
+
+func TestGenerateArrayTypes(t *testing.T) {
+	runGeneratorTest(t, `fn sum(values: []int): int {
+    let total = 0
+    for v in values {
+        total = total + v
+    }
+    return total
+}
+fn names(): []string {
+    return []
+}
+fn main() {
+    let fs: []float = [1, 2]
+    let grid: [][]int = []
+    println(sum([1, 2]), fs, names(), grid)
+}`, []string{
+		"func sum(values []int) int {",
+		"func names() []string {\n\treturn []string{}\n}",
+		"var fs []float64 = []float64{1, 2}",
+		"var grid [][]int = [][]int{}",
+		"fmt.Println(sum([]int{1, 2}), fs, names(), grid)",
+	})
+}
+
+func TestGenerateMethodCalls(t *testing.T) {
+	runGeneratorTest(t, `fn double(n: int): int {
+    return n * 2
+}
+fn main() {
+    let words = " a,b ".trim().split(",")
+    for w in words {
+        println(w.toUpper(), w.length())
+    }
+    let items = [1, 2]
+    items.push(3)
+    let doubled = items.map(double)
+    println(words.join("-"), doubled.contains(4), "abc".indexOf("c"))
+}`, []string{
+		`var words = strings.Split(strings.TrimSpace(" a,b "), ",")`,
+		"fmt.Println(strings.ToUpper(w), zenoBuiltinLen(w))",
+		"items = append(items, 3)",
+		"var doubled = zenoMethodMap(items, double)",
+		`fmt.Println(zenoMethodJoin(words, "-"), zenoMethodContains(doubled, 4), zenoMethodIndexOf("abc", "c"))`,
+	})
+}
+
+func TestGenerateRange(t *testing.T) {
+	runGeneratorTest(t, `fn main() {
+    let n = 3
+    for i in range(n) {
+        println(i)
+    }
+    for i in range(1, n * 2, 2) {
+        println(i)
+    }
+    for i in range(n, 0, -1) {
+        println(i)
+    }
+    let evens = range(0, 10, 2)
+    println(evens)
+}`, []string{
+		"for i, zenoEnd := 0, n; i < zenoEnd; i++ {",
+		"for i, zenoEnd := 1, (n * 2); i < zenoEnd; i += 2 {",
+		"for i := n; i > 0; i-- {",
+		"var evens = zenoBuiltinRange(0, 10, 2)",
+	})
+}
+
+func TestGenerateRangeEndSnapshot(t *testing.T) {
+	// The end is evaluated once, so growing n in the body does not make the
+	// loop run longer
+	output := runProgram(t, `fn main() {
+    let mut n = 3
+    for i in range(n) {
+        n = n + 1
+        println(i)
+    }
+    println(n)
+}`)
+	if output != "0\n1\n2\n6\n" {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+}
+
+func TestGenerateRangeBlank(t *testing.T) {
+	output := runProgram(t, `fn main() {
+    let mut count = 0
+    for _ in range(3) {
+        count = count + 1
+    }
+    for _ in range(1, 10, 4) {
+        count = count + 10
+    }
+    println(count)
+}`)
+	if output != "33\n" {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+}
+
+func TestGenerateForBlank(t *testing.T) {
+	output := runProgram(t, `fn main() {
+    let xs = [1, 2, 3]
+    let mut count = 0
+    for _ in xs {
+        count = count + 1
+    }
+    for _, _ in xs {
+        count = count + 10
+    }
+    for _ in "ab" {
+        count = count + 100
+    }
+    let ch = chan<int>(2)
+    send(ch, 1)
+    send(ch, 2)
+    close(ch)
+    for _ in ch {
+        count = count + 1000
+    }
+    println(count)
+}`)
+	if output != "2233\n" {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+}
+
+func TestGenerateForWithIndex(t *testing.T) {
+	runGeneratorTest(t, `fn main() {
+    let names = ["a", "b"]
+    for i, name in names {
+        println(i + 1, name.toUpper())
+    }
+    for _, name in names {
+        println(name)
+    }
+}`, []string{
+		"for i, name := range names {",
+		"fmt.Println((i + 1), strings.ToUpper(name))",
+		"for _, name := range names {",
+	})
+}
+
+func TestGenerateModulo(t *testing.T) {
+	runGeneratorTest(t, `fn main() {
+    let n = 7
+    println(n % 3 + 1, n - n % 2 * 2)
+}`, []string{
+		"fmt.Println(((n % 3) + 1), (n - ((n % 2) * 2)))",
+	})
+
+	program := parser.New(lexer.New("fn main() {\n    println(7.5 % 2)\n}")).ParseProgram()
+	_, err := Generate(program)
+	if err == nil || !strings.Contains(err.Error(), "[Z0119] Operator % cannot be applied to float and int") {
+		t.Errorf("expected an error for float modulo, got: %v", err)
+	}
+}
+
+func TestGenerateBitwiseOperators(t *testing.T) {
+	runGeneratorTest(t, `fn main() {
+    let flags = 1 << 3 | 1
+    println(flags & 8 == 8, flags ^ 1, flags >> 1)
+}`, []string{
+		"var flags = ((1 << 3) | 1)",
+		"fmt.Println(((flags & 8) == 8), (flags ^ 1), (flags >> 1))",
+	})
+}
+
+func TestGenerateNumericPromotion(t *testing.T) {
+	runGeneratorTest(t, `fn main() {
+    let n = 3
+    let f = 2.0
+    println(n * f, f < n, n + 1)
+}`, []string{
+		"var f = 2.0",
+		"fmt.Println((float64(n) * f), (f < float64(n)), (n + 1))",
+	})
+}
+
+func TestGenerateOverflowChecks(t *testing.T) {
+	program := parser.New(lexer.New(`const LIMIT = 1 << 62
+fn main() {
+    let n = 3
+    let f = 2.0
+    println(n * 2 + 1, -n, n / 2 % 2, f * 2.0, LIMIT)
+}`)).ParseProgram()
+	g := NewGenerator()
+	g.SetOptions(GeneratorOptions{CheckOverflow: true})
+	output, err := g.GenerateFile(program, "")
+	if err != nil {
+		t.Fatalf("generation failed: %v", err)
+	}
+	for _, sub := range []string{
+		"const LIMIT = (1 << 62)",
+		"fmt.Println(zenoCheckedAdd(zenoCheckedMul(n, 2), 1), zenoCheckedNeg(n), zenoCheckedMod(zenoCheckedDiv(n, 2), 2), (f * 2.0), LIMIT)",
+		"func zenoCheckedMul(a int, b int) int {",
+		"func zenoOverflow(a int, op string, b int) {",
+	} {
+		if !strings.Contains(output, sub) {
+			t.Errorf("expected %q in:\n%s", sub, output)
+		}
+	}
+
+	output, err = Generate(program)
+	if err != nil {
+		t.Fatalf("generation failed: %v", err)
+	}
+	if strings.Contains(output, "zenoChecked") {
+		t.Errorf("expected unchecked operators without CheckOverflow:\n%s", output)
+	}
+}
+
+func TestGenerateSizedIntegers(t *testing.T) {
+	program := parser.New(lexer.New(`fn widen(n: i32, data: []byte): u64 {
+    return 0u64
+}
+
+fn main() {
+    let big = 18446744073709551615u64
+    let small: byte = 200
+    println(big, small, i32("7"), widen(3i32, [1, 2]))
+}`)).ParseProgram()
+	output, err := Generate(program)
+	if err != nil {
+		t.Fatalf("generation failed: %v", err)
+	}
+	for _, sub := range []string{
+		"func widen(n int32, data []byte) uint64 {",
+		"return uint64(0)",
+		"var big = uint64(18446744073709551615)",
+		"var small byte = 200",
+		`zenoBuiltinSized[int32]("7", "i32")`,
+		"widen(int32(3), []byte{1, 2})",
+		"func zenoBuiltinSized[T zenoInteger](value interface{}, name string) zenoResult[T, string] {",
+	} {
+		if !strings.Contains(output, sub) {
+			t.Errorf("expected %q in:\n%s", sub, output)
+		}
+	}
+}
+
+func TestGenerateChars(t *testing.T) {
+	program := parser.New(lexer.New(`fn isVowel(c: char): bool {
+    return c == 'a' || c == 'e'
+}
+
+fn main() {
+    let quote = '\''
+    for c in "hello" {
+        println(c, isVowel(c), str(c), typeOf(c), int(c))
+    }
+    println(quote, char(233))
+}`)).ParseProgram()
+	output, err := Generate(program)
+	if err != nil {
+		t.Fatalf("generation failed: %v", err)
+	}
+	for _, sub := range []string{
+		"func isVowel(c rune) bool {",
+		"return ((c == 'a') || (c == 'e'))",
+		`var quote = '\''`,
+		`for _, c := range "hello" {`,
+		"fmt.Println(zenoChar(c), isVowel(c), zenoBuiltinStr(zenoChar(c)), zenoBuiltinTypeOf(zenoChar(c)), zenoBuiltinInt(c))",
+		"fmt.Println(zenoChar(quote), zenoBuiltinChar(233))",
+		"type zenoChar rune",
+	} {
+		if !strings.Contains(output, sub) {
+			t.Errorf("expected %q in:\n%s", sub, output)
+		}
+	}
+}
+
+func TestGenerateUnicodeNames(t *testing.T) {
+	program := parser.New(lexer.New(`type 点 = {
+    横: int
+    éclat: int
+}
+
+pub fn 挨拶(名前: string): string {
+    return "こんにちは、" + 名前
+}
+
+fn main() {
+    let p = 点{ 横: 1, éclat: 2 }
+    println(挨拶("世界"), p.横 + p.éclat)
+}`)).ParseProgram()
+	output, err := Generate(program)
+	if err != nil {
+		t.Fatalf("generation failed: %v", err)
+	}
+	for _, sub := range []string{
+		"var p = 点{X横: 1, Éclat: 2}",
+		"func X挨拶(名前 string) string {",
+		`X挨拶("世界")`,
+		"(p.X横 + p.Éclat)",
+	} {
+		if !strings.Contains(output, sub) {
+			t.Errorf("expected %q in:\n%s", sub, output)
+		}
+	}
+}
+
+func TestGenerateMatchBreak(t *testing.T) {
+	// A break in an arm leaves the loop, not only the switch
+	output := runProgram(t, `fn main() {
+    let mut i = 0
+    loop {
+        i = i + 1
+        match i {
+            3 => {
+                break
+            }
+            _ => {
+                if i > 6 {
+                    return
+                }
+                println(i)
+            }
+        }
+    }
+    let mut n = 0
+    while n < 10 {
+        n = n + 1
+        match n % 2 {
+            0 => {
+                continue
+            }
+            _ => {
+                if n > 6 {
+                    break
+                }
+            }
+        }
+        println(n)
+    }
+    println("done", i, n)
+}`)
+	if expected := "1\n2\n1\n3\n5\ndone 3 7\n"; output != expected {
+		t.Errorf("expected output %q, got %q", expected, output)
+	}
+}
+
+func TestGenerateIfExpression(t *testing.T) {
+	runGeneratorTest(t, `fn main() {
+    let n = 3
+    let name = if n == 1 {
+        "one"
+    } else {
+        let s = str(n)
+        s
+    }
+    println(name)
+}`, []string{
+		"var name = func() string {",
+		"if n == 1 {\n\t\t\treturn \"one\"\n\t\t} else {",
+		"return s\n\t\t}\n\t}()",
+	})
+
+	program := parser.New(lexer.New("fn main() {\n    let x = if true {\n        1\n    } else {\n        let y = 2\n    }\n    println(x)\n}")).ParseProgram()
+	_, err := Generate(program)
+	if err == nil || !strings.Contains(err.Error(), "[Z0123] A branch of an if used as a value must end with an expression") {
+		t.Errorf("expected an error for a branch without a value, got: %v", err)
+	}
+}
+
+func TestGenerateTuples(t *testing.T) {
+	runGeneratorTest(t, `fn divmod(a: int, b: int): (int, int) {
+    return (a / b, a % b)
+}
+
+fn scale(): (float, string) {
+    return (1, "x")
+}
+
+fn main() {
+    let (q, r) = divmod(7, 2)
+    let (f, _) = scale()
+    println(q, r, f)
+}`, []string{
+		"func divmod(a int, b int) (int, int) {",
+		"return (a / b), (a % b)",
+		"func scale() (float64, string) {\n\treturn 1, \"x\"",
+		"var q, r = divmod(7, 2)",
+		"var f, _ = scale()",
+	})
+}
+
+func TestGenerateDestructuring(t *testing.T) {
+	runGeneratorTest(t, `type Person = {
+    name: string
+    age: int
+}
+
+fn main() {
+    let person = Person{name: "a", age: 2}
+    let {name, age} = person
+    let [first, _, third] = [1, 2, 3]
+    let {debug} = {debug: true}
+    println(name, age, first, third, debug)
+}`, []string{
+		"var name, age = person.Name, person.Age",
+		"zenoValue1 := []int{1, 2, 3}\n\tvar first, third = zenoValue1[0], zenoValue1[2]",
+		"zenoValue2 := map[string]interface{}{\"debug\": true}\n\tvar debug = zenoValue2[\"debug\"]",
+	})
+}
+
+func TestGenerateScopes(t *testing.T) {
+	runGeneratorTest(t, `fn main() {
+    let label = "outer"
+    if true {
+        let label = 42
+        println(label + 1)
+    }
+    for i in [1, 2] {
+        let label = i * 2
+        println(label)
+    }
+    println(label + "!")
+}`, []string{
+		"var label = \"outer\"",
+		"var label = 42",
+		"var label = (i * 2)",
+		"fmt.Println((label + \"!\"))",
+	})
+
+	// x is used in first, but not in second
+	program := parser.New(lexer.New(`fn first(): int {
+    let x = 1
+    return x
+}
+
+fn second() {
+    let x = 2
+}
+
+fn main() {
+    println(first())
+    second()
+}`)).ParseProgram()
+	_, err := Generate(program)
+	if errs := Errors(err); len(errs) != 1 || errs[0].Message != "variable 'x' is declared but not used" || errs[0].Pos.Line != 7 {
+		t.Errorf("expected x of second to be reported as unused, got: %v", err)
+	}
+}
+
+func TestGenerateUnusedStrictness(t *testing.T) {
+	input := `fn helper() {
+}
+
+fn main() {
+    let x = 1
+    let _tmp = 2
+    let (a, b) = (1, "s")
+    println(a)
+}`
+	generate := func(strictness Strictness) (string, []Warning, error) {
+		program := parser.New(lexer.New(input)).ParseProgram()
+		g := NewGenerator()
+		g.SetOptions(GeneratorOptions{Strictness: strictness})
+		code, err := g.GenerateFile(program, "")
+		return code, g.Warnings(), err
+	}
+
+	// Each unused variable and function is an error at its declaration,
+	// like the warnings
+	_, _, err := generate(StrictnessError)
+	var errs []string
+	for _, genErr := range Errors(err) {
+		errs = append(errs, fmt.Sprintf("%d:%d %s %s", genErr.Pos.Line, genErr.Pos.Column, genErr.Code, genErr.Message))
+	}
+	wantErrs := []string{
+		"5:5 Z0104 variable 'x' is declared but not used",
+		"7:5 Z0104 variable 'b' is declared but not used",
+		"1:1 Z0105 function 'helper' is defined but not used",
+	}
+	if strings.Join(errs, "\n") != strings.Join(wantErrs, "\n") {
+		t.Errorf("expected x, b and helper but not _tmp to be reported, got: %v", err)
+	}
+
+	code, warnings, err := generate(StrictnessWarn)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	var got []string
+	for _, w := range warnings {
+		got = append(got, w.Code+" "+w.Message)
+	}
+	want := []string{
+		"Z0104 variable 'x' is declared but not used",
+		"Z0104 variable 'b' is declared but not used",
+		"Z0105 function 'helper' is defined but not used",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected warnings:\n%s", strings.Join(got, "\n"))
+	}
+	for _, discard := range []string{"_ = x", "_ = _tmp", "_ = b"} {
+		if !strings.Contains(code, discard) {
+			t.Errorf("expected %q in the generated code:\n%s", discard, code)
+		}
+	}
+
+	if _, warnings, err := generate(StrictnessOff); err != nil || len(warnings) != 0 {
+		t.Errorf("expected no error nor warning, got %v, %v", err, warnings)
+	}
+}
+
+func TestGenerateVariablesUsedInLiterals(t *testing.T) {
+	program := parser.New(lexer.New(`type Box = {
+    label: string
+}
+
+fn main() {
+    let name = "x"
+    let count = 2
+    println(Box{label: name}, {"count": count})
+}`)).ParseProgram()
+	if _, err := NewGenerator().GenerateFile(program, ""); err != nil {
+		t.Errorf("variables used in struct and map literals reported unused: %v", err)
+	}
+}
+
+func TestGenerateTestProgram(t *testing.T) {
+	input := `fn double(n: int): int {
+    return n * 2
+}
+
+fn test_double() {
+    assertEq(double(2), 4)
+}
+
+pub fn test_positive() {
+    assertTrue(double(1) > 0)
+}
+
+fn test_helper(n: int) {
+    println(n)
+}
+
+fn main() {
+    test_helper(1)
+}`
+	program := parser.New(lexer.New(input)).ParseProgram()
+	var names []string
+	for _, fn := range TestFunctions(program) {
+		names = append(names, fn.Name)
+	}
+	if strings.Join(names, ",") != "test_double,test_positive" {
+		t.Errorf("unexpected tests %q", names)
+	}
+
+	g := NewGenerator()
+	g.SetOptions(GeneratorOptions{Test: true})
+	code, err := g.GenerateFile(program, "math_test.zeno")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	for _, expected := range []string{
+		`zenoBuiltinAssertEq(double(2), 4, "math_test.zeno:6")`,
+		`zenoBuiltinAssertTrue((double(1) > 0), "math_test.zeno:10")`,
+		`{"test_double", test_double},`,
+		`{"test_positive", Test_positive},`,
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("expected %q in the generated code:\n%s", expected, code)
+		}
+	}
+	if strings.Contains(code, "test_helper(1)") {
+		t.Errorf("the main function of the file is generated in a test program:\n%s", code)
+	}
+}
@@ -0,0 +1,108 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/types"
+)
+
+// typeConstraints are the Go constraints a type parameter is declared with,
+// from the loosest to the strictest. A type parameter gets the strictest one
+// the operators applied to its values need.
+var typeConstraints = []string{"any", "comparable", "zenoOrdered", "zenoNumber", "zenoInteger"}
+
+// nativeGenericHelpers declares the constraints of typeConstraints that Go
+// does not predeclare. The integers include the sized integer types.
+const nativeGenericHelpers = `type zenoOrdered interface {
+	zenoNumber | ~string
+}
+
+type zenoNumber interface {
+	zenoInteger | ~float64
+}
+
+type zenoInteger interface {
+	~int | ~int32 | ~int64 | ~uint32 | ~uint64 | ~uint8
+}
+`
+
+// operatorConstraint returns the index in typeConstraints of the constraint
+// that the operands of op need
+func operatorConstraint(op ast.BinaryOperator) int {
+	switch {
+	case op.IntegerOnly():
+		return 4
+	case op == ast.BinaryOpMinus || op == ast.BinaryOpMultiply || op == ast.BinaryOpDivide:
+		return 3
+	case op == ast.BinaryOpPlus || op == ast.BinaryOpLt || op == ast.BinaryOpLte || op == ast.BinaryOpGt || op == ast.BinaryOpGte:
+		return 2
+	case op == ast.BinaryOpEq || op == ast.BinaryOpNotEq:
+		return 1
+	}
+	return 0
+}
+
+// constrain records that the type parameters the operands of an operator in
+// the body of a generic function are typed with need the constraint of
+// typeConstraints at index constraint
+func (g *Generator) constrain(constraint int, operands ...ast.Expression) {
+	if g.constraints == nil {
+		return
+	}
+	for _, operand := range operands {
+		param, ok := g.inferType(operand).(*types.TypeParameter)
+		if ok && constraint > g.constraints[param.Name] {
+			g.constraints[param.Name] = constraint
+		}
+	}
+}
+
+// typeParameter returns the type parameter of the generic function being
+// generated that name refers to
+func (g *Generator) typeParameter(name string) (*types.TypeParameter, bool) {
+	if _, ok := g.constraints[name]; ok {
+		return &types.TypeParameter{Name: name}, true
+	}
+	return nil, false
+}
+
+// insertTypeParameters writes the type parameters of a generic function at
+// offset at of builder, where its signature starts, with the constraints
+// recorded while its body was generated. They take no line of their own, so
+// the lines of the source map stay in place.
+func (g *Generator) insertTypeParameters(def *ast.FunctionDefinition, builder *strings.Builder, at int) {
+	if len(def.Generics) == 0 {
+		return
+	}
+	params := make([]string, len(def.Generics))
+	for i, param := range def.Generics {
+		params[i] = param + " " + typeConstraints[g.constraints[param]]
+	}
+	code := builder.String()
+	builder.Reset()
+	builder.WriteString(code[:at] + "[" + strings.Join(params, ", ") + "]" + code[at:])
+}
+
+// callTypeArguments returns the type arguments of a call to the generic
+// function def: the ones written out, or else the ones inferred from the
+// types of its arguments, nil if some cannot be inferred
+func (g *Generator) callTypeArguments(def *ast.FunctionDefinition, call *ast.FunctionCall) []string {
+	if len(call.TypeArguments) > 0 || len(def.Generics) == 0 || len(def.Parameters) == 0 {
+		return call.TypeArguments
+	}
+	bindings := make(map[string]types.Type)
+	for i, arg := range call.Arguments {
+		param := def.Parameters[min(i, len(def.Parameters)-1)]
+		types.Infer(param.Type, def.Generics, g.inferType(arg), bindings)
+	}
+	typeArgs := make([]string, len(def.Generics))
+	for i, param := range def.Generics {
+		bound, ok := bindings[param]
+		if !ok {
+			return nil
+		}
+		typeArgs[i] = bound.String()
+	}
+	return typeArgs
+}
@@ -0,0 +1,167 @@
+package generator
+
+import (
+	goast "go/ast"
+	goparser "go/parser"
+	"go/token"
+	"strings"
+	"sync"
+)
+
+// helperDecl is one top-level declaration of the native helpers
+type helperDecl struct {
+	names []string // the names it declares, or the type of a method
+	refs  []string // the identifiers it uses
+	code  string
+}
+
+// helperIndexes caches the declarations of the helpers by source, which
+// most packages of a workspace share, so that each source is parsed once
+var helperIndexes sync.Map
+
+// indexHelpers returns the declarations of helpers, the Go code written by
+// generateNativeFunctionHelpers, in their order
+func indexHelpers(helpers string) ([]helperDecl, bool) {
+	if decls, ok := helperIndexes.Load(helpers); ok {
+		return decls.([]helperDecl), true
+	}
+	fset := token.NewFileSet()
+	source := "package p\n" + helpers
+	file, err := goparser.ParseFile(fset, "", source, goparser.ParseComments|goparser.SkipObjectResolution)
+	if err != nil {
+		return nil, false
+	}
+	base := fset.File(file.Pos()).Base()
+	decls := make([]helperDecl, 0, len(file.Decls))
+	for _, decl := range file.Decls {
+		start := decl.Pos()
+		if doc := declDoc(decl); doc != nil {
+			start = doc.Pos()
+		}
+		refs := make(map[string]bool)
+		collectIdents(decl, refs)
+		d := helperDecl{names: declaredNames(decl), code: source[int(start)-base : int(decl.End())-base]}
+		for name := range refs {
+			d.refs = append(d.refs, name)
+		}
+		decls = append(decls, d)
+	}
+	helperIndexes.Store(helpers, decls)
+	return decls, true
+}
+
+// neededHelpers returns the declarations of helpers that code refers to
+// directly or through other helpers, in their order. It also returns the
+// names of the packages code and these declarations refer to, such as
+// "fmt" or "json". It reports false if either does not parse, which leaves
+// the errors to the Go compiler.
+func neededHelpers(code, helpers string) (string, map[string]bool, bool) {
+	codeFile, err := goparser.ParseFile(token.NewFileSet(), "", "package p\n"+code, goparser.SkipObjectResolution)
+	if err != nil {
+		return "", nil, false
+	}
+	decls, ok := indexHelpers(helpers)
+	if !ok {
+		return "", nil, false
+	}
+	referenced := make(map[string]bool)
+	collectIdents(codeFile, referenced)
+
+	// A declaration is needed once one of the names it declares is
+	// referenced; methods are needed with their type
+	kept := make([]bool, len(decls))
+	for changed := true; changed; {
+		changed = false
+		for i, decl := range decls {
+			if kept[i] || !decl.declaresAny(referenced) {
+				continue
+			}
+			kept[i] = true
+			changed = true
+			for _, name := range decl.refs {
+				referenced[name] = true
+			}
+		}
+	}
+	var builder strings.Builder
+	for i, decl := range decls {
+		if kept[i] {
+			builder.WriteString(decl.code)
+			builder.WriteString("\n\n")
+		}
+	}
+	return builder.String(), referenced, true
+}
+
+func (d helperDecl) declaresAny(names map[string]bool) bool {
+	for _, name := range d.names {
+		if names[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// collectIdents adds the identifiers used in node to names
+func collectIdents(node goast.Node, names map[string]bool) {
+	goast.Inspect(node, func(n goast.Node) bool {
+		if ident, ok := n.(*goast.Ident); ok {
+			names[ident.Name] = true
+		}
+		return true
+	})
+}
+
+// declaredNames returns the names decl declares, or the type of the
+// receiver of a method
+func declaredNames(decl goast.Decl) []string {
+	var names []string
+	switch d := decl.(type) {
+	case *goast.FuncDecl:
+		if d.Recv != nil && len(d.Recv.List) > 0 {
+			return []string{receiverType(d.Recv.List[0].Type)}
+		}
+		return []string{d.Name.Name}
+	case *goast.GenDecl:
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *goast.TypeSpec:
+				names = append(names, s.Name.Name)
+			case *goast.ValueSpec:
+				for _, name := range s.Names {
+					names = append(names, name.Name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// receiverType returns the name of the type of a method receiver, such as
+// zenoSet for *zenoSet[T]
+func receiverType(expr goast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *goast.StarExpr:
+			expr = e.X
+		case *goast.IndexExpr:
+			expr = e.X
+		case *goast.IndexListExpr:
+			expr = e.X
+		case *goast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+func declDoc(decl goast.Decl) *goast.CommentGroup {
+	switch d := decl.(type) {
+	case *goast.FuncDecl:
+		return d.Doc
+	case *goast.GenDecl:
+		return d.Doc
+	}
+	return nil
+}
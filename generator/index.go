@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/types"
+)
+
+// generateIndexExpression writes items[i]. Strings are indexed by
+// character, like len counts them, so they are converted to runes first.
+func (g *Generator) generateIndexExpression(e *ast.IndexExpression, builder *strings.Builder) error {
+	isString := g.inferType(e.Left) == types.StringType
+	if isString {
+		builder.WriteString("string([]rune(")
+	}
+	if err := g.generateExpression(e.Left, builder); err != nil {
+		return err
+	}
+	if isString {
+		builder.WriteString(")")
+	}
+	builder.WriteString("[")
+	if err := g.generateExpression(e.Index, builder); err != nil {
+		return err
+	}
+	builder.WriteString("]")
+	if isString {
+		builder.WriteString(")")
+	}
+	return nil
+}
+
+// generateSliceExpression writes items[low:high], by character for strings
+func (g *Generator) generateSliceExpression(e *ast.SliceExpression, builder *strings.Builder) error {
+	isString := g.inferType(e.Left) == types.StringType
+	if isString {
+		builder.WriteString("string([]rune(")
+	}
+	if err := g.generateExpression(e.Left, builder); err != nil {
+		return err
+	}
+	if isString {
+		builder.WriteString(")")
+	}
+	builder.WriteString("[")
+	if e.Low != nil {
+		if err := g.generateExpression(e.Low, builder); err != nil {
+			return err
+		}
+	}
+	builder.WriteString(":")
+	if e.High != nil {
+		if err := g.generateExpression(e.High, builder); err != nil {
+			return err
+		}
+	}
+	builder.WriteString("]")
+	if isString {
+		builder.WriteString(")")
+	}
+	return nil
+}
+
+// indexType returns the type of the element items[i] reads: a string for
+// strings, the element type for arrays, and any for maps
+func (g *Generator) indexType(left types.Type) types.Type {
+	if left == types.StringType {
+		return types.StringType
+	}
+	if array, ok := left.(*types.ArrayType); ok && array.ElementType != nil {
+		return array.ElementType
+	}
+	return types.AnyType
+}
@@ -0,0 +1,69 @@
+package generator
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/types"
+)
+
+// sizedGoTypes are the Go types of the sized integer types
+var sizedGoTypes = map[string]string{
+	"i32":  "int32",
+	"i64":  "int64",
+	"u32":  "uint32",
+	"u64":  "uint64",
+	"byte": "byte",
+}
+
+// integerLiteralType returns the type of an integer literal: int, or the
+// sized integer type of its suffix
+func integerLiteralType(e *ast.IntegerLiteral) types.Type {
+	if e.Suffix != "" {
+		return types.SizedInts[e.Suffix]
+	}
+	return types.IntType
+}
+
+// generateIntegerLiteral writes an integer literal, converted to the Go type
+// of its suffix, as in uint64(10) for 10u64
+func generateIntegerLiteral(e *ast.IntegerLiteral, builder *strings.Builder) {
+	if e.Suffix == "" {
+		builder.WriteString(strconv.Itoa(e.Value))
+		return
+	}
+	builder.WriteString(sizedGoTypes[e.Suffix])
+	builder.WriteString("(")
+	if e.Suffix == "u64" {
+		builder.WriteString(strconv.FormatUint(uint64(e.Value), 10))
+	} else {
+		builder.WriteString(strconv.Itoa(e.Value))
+	}
+	builder.WriteString(")")
+}
+
+// sizedOperandType returns the sized integer type of one of the operands of
+// an operator, which the other, an int literal, converts to like a Go
+// constant
+func sizedOperandType(left, right types.Type) (types.Type, bool) {
+	switch {
+	case types.IsSized(left):
+		return left, true
+	case types.IsSized(right):
+		return right, true
+	}
+	return nil, false
+}
+
+// generateSizedConversion writes a call to i32, i64, u32, u64 or byte, which
+// return a Result that fails when the value does not fit in the type
+func (g *Generator) generateSizedConversion(call *ast.FunctionCall, builder *strings.Builder) error {
+	builder.WriteString("zenoBuiltinSized[" + sizedGoTypes[call.Name] + "](")
+	defer g.expect(nil)()
+	if err := g.generateExpression(call.Arguments[0], builder); err != nil {
+		return err
+	}
+	builder.WriteString(", " + strconv.Quote(call.Name) + ")")
+	return nil
+}
@@ -0,0 +1,259 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+	"github.com/linkalls/zeno-lang/types"
+)
+
+// armValue returns the expression giving the value of a match arm: its
+// expression, or the last statement of its block. It is nil for blocks that
+// do not end with an expression.
+func armValue(arm *ast.MatchArm) ast.Expression {
+	if arm.Block == nil {
+		return arm.Value
+	}
+	return blockValue(arm.Block)
+}
+
+// blockValue returns the last statement of a block used as a value if it
+// is an expression, and nil otherwise
+func blockValue(block *ast.Block) ast.Expression {
+	n := len(block.Statements)
+	if n == 0 {
+		return nil
+	}
+	if stmt, ok := block.Statements[n-1].(*ast.ExpressionStatement); ok {
+		return stmt.Expression
+	}
+	return nil
+}
+
+// matchType returns the type of a match used as a value: the type of its
+// arms when they all have the same known type, and any otherwise.
+func (g *Generator) matchType(e *ast.MatchExpression) types.Type {
+	var result types.Type
+	for i := range e.Arms {
+		arm := &e.Arms[i]
+		endScope := g.enterScope()
+		g.registerArmBindings(e, arm)
+		if arm.Block != nil {
+			g.registerBlockVariables(arm.Block)
+		}
+		var ok bool
+		result, ok = g.joinValueType(result, armValue(arm))
+		endScope()
+		if !ok {
+			return types.AnyType
+		}
+	}
+	if result == nil {
+		return types.AnyType
+	}
+	return result
+}
+
+// registerBlockVariables registers the variables declared in a block used
+// as a value, to which its value may refer
+func (g *Generator) registerBlockVariables(block *ast.Block) {
+	for _, stmt := range block.Statements {
+		if let, ok := stmt.(*ast.LetDeclaration); ok && let.TypeAnn == nil {
+			g.registerVariableWithType(let.Name, g.inferType(let.ValueExpression))
+		}
+	}
+}
+
+// joinValueType joins result, the type of the values of the match arms or
+// if branches so far, with the type of value. It reports false when value
+// is nil or its type is not known or differs.
+func (g *Generator) joinValueType(result types.Type, value ast.Expression) (types.Type, bool) {
+	if value == nil {
+		return nil, false
+	}
+	valueType := g.knownExpressionType(value)
+	if valueType == nil {
+		switch t := g.inferType(value).(type) {
+		case *types.StructType, *types.EnumType, *types.ResultType, *types.OptionType:
+			valueType = t
+		default:
+			return nil, false
+		}
+	}
+	switch {
+	case result == nil || result.String() == valueType.String():
+		return valueType, true
+	case (result == types.IntType || result == types.FloatType) && (valueType == types.IntType || valueType == types.FloatType):
+		return types.FloatType, true
+	}
+	return nil, false
+}
+
+// goValueType returns the Go type for values of t
+func (g *Generator) goValueType(t types.Type) string {
+	if enum, ok := t.(*types.EnumType); ok {
+		return enum.Name
+	}
+	if result, ok := t.(*types.ResultType); ok {
+		return g.goResultType(result)
+	}
+	if option, ok := t.(*types.OptionType); ok {
+		return g.goOptionType(option)
+	}
+	if collection, ok := t.(*types.CollectionType); ok {
+		return g.goCollectionType(collection)
+	}
+	if channel, ok := t.(*types.ChannelType); ok {
+		return g.goChannelType(channel)
+	}
+	if array, ok := t.(*types.ArrayType); ok && array.ElementType != nil {
+		return "[]" + g.goValueType(array.ElementType)
+	}
+	if param, ok := t.(*types.TypeParameter); ok {
+		return param.Name
+	}
+	if structType, ok := t.(*types.StructType); ok {
+		if decl := g.structDeclaration(structType.Name); decl != nil && len(decl.Generics) == 0 {
+			return decl.Name
+		}
+	}
+	return getGoTypeForZenoPrimitiveType(t)
+}
+
+// generateMatchCase writes the case clause of an arm
+func (g *Generator) generateMatchCase(arm *ast.MatchArm, builder *strings.Builder, indentLevel int) error {
+	if _, _, _, ok := g.variantPattern(arm.Pattern); ok {
+		return g.generateVariantCase(arm.Pattern, builder, indentLevel)
+	}
+	if g.optionPattern(arm.Pattern) {
+		return g.generateOptionCase(arm.Pattern, builder, indentLevel)
+	}
+	builder.WriteString(indent(indentLevel))
+	if arm.IsWildcard() {
+		builder.WriteString("default:\n")
+		return nil
+	}
+	builder.WriteString("case ")
+	if err := g.generateExpression(arm.Pattern, builder); err != nil {
+		return err
+	}
+	builder.WriteString(":\n")
+	return nil
+}
+
+// generateMatchStatement writes a match whose value is not used as a Go
+// switch. Arms after the wildcard can never be taken and are left out. A
+// break in an arm leaves the loop around the match, see generateArms.
+func (g *Generator) generateMatchStatement(e *ast.MatchExpression, builder *strings.Builder, indentLevel int) error {
+	var subject strings.Builder
+	if err := g.generateExpression(e.Subject, &subject); err != nil {
+		return err
+	}
+	var blocks []*ast.Block
+	for i := range e.Arms {
+		blocks = append(blocks, e.Arms[i].Block)
+	}
+	return g.generateArms("zenoMatchBreak", armsBreak(blocks), e, builder, indentLevel, func(level int) error {
+		builder.WriteString(indent(level))
+		g.generateMatchSwitch(e, subject.String(), builder)
+		for i := range e.Arms {
+			arm := &e.Arms[i]
+			if err := g.generateMatchCase(arm, builder, level); err != nil {
+				return err
+			}
+			endScope := g.enterScope()
+			g.registerArmBindings(e, arm)
+			if arm.Block != nil {
+				for _, stmt := range arm.Block.Statements {
+					if err := g.generateStatement(stmt, builder, level+1); err != nil {
+						return err
+					}
+				}
+			} else {
+				stmt := &ast.ExpressionStatement{Position: arm.Value.Pos(), Expression: arm.Value}
+				if err := g.generateStatement(stmt, builder, level+1); err != nil {
+					return err
+				}
+			}
+			endScope()
+			if arm.IsWildcard() {
+				break
+			}
+		}
+		builder.WriteString(indent(level))
+		builder.WriteString("}\n")
+		return nil
+	})
+}
+
+// generateMatchExpression writes a match used as a value as a function
+// literal that is called at once and returns the value of the matching arm.
+// Without a wildcard arm, a value that no arm matches panics.
+func (g *Generator) generateMatchExpression(e *ast.MatchExpression, builder *strings.Builder) error {
+	level := g.indentLevel
+	exhaustive := false
+	for _, arm := range e.Arms {
+		if arm.IsWildcard() {
+			exhaustive = true
+		}
+	}
+
+	var subject strings.Builder
+	if err := g.generateExpression(e.Subject, &subject); err != nil {
+		return err
+	}
+	builder.WriteString("func() ")
+	builder.WriteString(g.goValueType(g.matchType(e)))
+	builder.WriteString(" {\n")
+	outerValueClosure := g.inValueClosure
+	g.inValueClosure = true
+	defer func() { g.inValueClosure = outerValueClosure }()
+	builder.WriteString(indent(level + 1))
+	if exhaustive {
+		g.generateMatchSwitch(e, subject.String(), builder)
+	} else {
+		builder.WriteString("zenoMatch := " + subject.String() + "\n")
+		builder.WriteString(indent(level + 1))
+		g.generateMatchSwitch(e, "zenoMatch", builder)
+	}
+	for i := range e.Arms {
+		arm := &e.Arms[i]
+		if err := g.generateMatchCase(arm, builder, level+1); err != nil {
+			return err
+		}
+		value := armValue(arm)
+		if value == nil {
+			return newGenerationErrorAt(arm, i18n.GenMatchArmWithoutValue)
+		}
+		endScope := g.enterScope()
+		g.registerArmBindings(e, arm)
+		if arm.Block != nil {
+			statements := arm.Block.Statements
+			for _, stmt := range statements[:len(statements)-1] {
+				if err := g.generateStatement(stmt, builder, level+2); err != nil {
+					return err
+				}
+			}
+		}
+		builder.WriteString(indent(level + 2))
+		builder.WriteString("return ")
+		if err := g.generateExpression(value, builder); err != nil {
+			return err
+		}
+		builder.WriteString("\n")
+		endScope()
+		if arm.IsWildcard() {
+			break
+		}
+	}
+	builder.WriteString(indent(level + 1))
+	builder.WriteString("}\n")
+	if !exhaustive {
+		builder.WriteString(indent(level + 1))
+		builder.WriteString("panic(fmt.Sprintf(\"no match arm for %v\", zenoMatch))\n")
+	}
+	builder.WriteString(indent(level))
+	builder.WriteString("}()")
+	return nil
+}
@@ -0,0 +1,214 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+	"github.com/linkalls/zeno-lang/types"
+)
+
+// builtinMethod describes a method of strings, arrays or collections
+type builtinMethod struct {
+	// params is the number of arguments the method takes
+	params int
+	// format writes the call: %[1]s is the receiver and %[2]s, %[3]s the
+	// arguments
+	format string
+	// returnType is the Zeno type of the call, nil for the methods whose
+	// type depends on the call, see methodCallType
+	returnType types.Type
+}
+
+var stringMethods = map[string]builtinMethod{
+	"length":     {params: 0, format: "zenoBuiltinLen(%[1]s)", returnType: types.IntType},
+	"toUpper":    {params: 0, format: "strings.ToUpper(%[1]s)", returnType: types.StringType},
+	"toLower":    {params: 0, format: "strings.ToLower(%[1]s)", returnType: types.StringType},
+	"trim":       {params: 0, format: "strings.TrimSpace(%[1]s)", returnType: types.StringType},
+	"contains":   {params: 1, format: "strings.Contains(%[1]s, %[2]s)", returnType: types.BoolType},
+	"startsWith": {params: 1, format: "strings.HasPrefix(%[1]s, %[2]s)", returnType: types.BoolType},
+	"endsWith":   {params: 1, format: "strings.HasSuffix(%[1]s, %[2]s)", returnType: types.BoolType},
+	"split":      {params: 1, format: "strings.Split(%[1]s, %[2]s)", returnType: &types.ArrayType{ElementType: types.StringType}},
+	"replace":    {params: 2, format: "strings.ReplaceAll(%[1]s, %[2]s, %[3]s)", returnType: types.StringType},
+	"indexOf":    {params: 1, format: "zenoMethodIndexOf(%[1]s, %[2]s)", returnType: types.IntType},
+}
+
+var arrayMethods = map[string]builtinMethod{
+	"length":   {params: 0, format: "len(%[1]s)", returnType: types.IntType},
+	"push":     {params: 1, format: "%[1]s = append(%[1]s, %[2]s)"},
+	"contains": {params: 1, format: "zenoMethodContains(%[1]s, %[2]s)", returnType: types.BoolType},
+	"join":     {params: 1, format: "zenoMethodJoin(%[1]s, %[2]s)", returnType: types.StringType},
+	"map":      {params: 1, format: "zenoMethodMap(%[1]s, %[2]s)"},
+	"filter":   {params: 1, format: "zenoMethodFilter(%[1]s, %[2]s)"},
+}
+
+// lookupMethod returns the method called on a value of type receiver.
+// Values of type any are asserted to the type that has the method, strings
+// first.
+func (g *Generator) lookupMethod(receiver types.Type, name string) (builtinMethod, string, bool) {
+	if _, isArray := receiver.(*types.ArrayType); isArray {
+		m, ok := arrayMethods[name]
+		return m, "", ok
+	}
+	if collection, isCollection := receiver.(*types.CollectionType); isCollection {
+		m, ok := collectionMethod(collection, name)
+		return m, "", ok
+	}
+	switch receiver {
+	case types.StringType:
+		m, ok := stringMethods[name]
+		return m, "", ok
+	case types.AnyType:
+		if m, ok := stringMethods[name]; ok {
+			if name == "length" {
+				// zenoBuiltinLen takes any value
+				return m, "", true
+			}
+			return m, ".(string)", true
+		}
+		m, ok := arrayMethods[name]
+		return m, ".([]interface{})", ok
+	}
+	return builtinMethod{}, "", false
+}
+
+// namespaceCall resolves io.readFile(path), where io is a module imported
+// with import * as io, to a call of the function imported as io.readFile.
+// ok is false for other calls, for example when a variable named io shadows
+// the module.
+func (g *Generator) namespaceCall(e *ast.MethodCallExpression) (*ast.FunctionCall, bool) {
+	name, call, ok := e.QualifiedCall()
+	if !ok {
+		return nil, false
+	}
+	if _, isNamespace := g.namespaces[name]; !isNamespace {
+		return nil, false
+	}
+	if _, isVar := g.symbolTable.Resolve(name); isVar {
+		return nil, false
+	}
+	return call, true
+}
+
+// generateMethodCall writes a call to a method of a string or an array.
+// push is written as an assignment, so it is only valid as a statement.
+func (g *Generator) generateMethodCall(e *ast.MethodCallExpression, builder *strings.Builder, isStatement bool) error {
+	if call, ok := g.namespaceCall(e); ok {
+		if _, imported := g.declaredFns[call.Name]; !imported {
+			return newGenerationErrorAt(e, i18n.GenFunctionNotExported, e.Method, g.namespaces[e.Object.String()])
+		}
+		return g.generateExpression(call, builder)
+	}
+	receiverType := g.inferType(e.Object)
+	m, assertion, ok := g.lookupMethod(receiverType, e.Method)
+	if !ok {
+		return newGenerationErrorAt(e, i18n.TypeUnknownMethod, receiverType, e.Method)
+	}
+	if len(e.Arguments) != m.params {
+		return newGenerationErrorAt(e, i18n.GenArgumentCount, e.Method, m.params, len(e.Arguments), e.String())
+	}
+	if _, isArray := receiverType.(*types.ArrayType); isArray && e.Method == "push" {
+		if _, isVariable := e.Object.(*ast.Identifier); !isVariable || !isStatement {
+			return newGenerationErrorAt(e, i18n.TypePushTarget)
+		}
+	}
+
+	restore := g.expect(nil)
+	defer restore()
+	var receiver strings.Builder
+	if err := g.generateExpression(e.Object, &receiver); err != nil {
+		return err
+	}
+	args := []interface{}{receiver.String() + assertion}
+	if array, ok := receiverType.(*types.ArrayType); ok && (e.Method == "push" || e.Method == "contains") {
+		// The value converts to the element type, e.g. 1 pushed to a []float
+		g.expect(array.ElementType)
+	}
+	for _, arg := range e.Arguments {
+		var argument strings.Builder
+		if err := g.generateExpression(arg, &argument); err != nil {
+			return err
+		}
+		args = append(args, argument.String())
+	}
+	builder.WriteString(fmt.Sprintf(m.format, args...))
+	return nil
+}
+
+// methodCallType returns the type of a call to a method
+func (g *Generator) methodCallType(e *ast.MethodCallExpression) types.Type {
+	if call, ok := g.namespaceCall(e); ok {
+		return g.inferType(call)
+	}
+	receiverType := g.inferType(e.Object)
+	m, _, ok := g.lookupMethod(receiverType, e.Method)
+	if !ok {
+		return types.AnyType
+	}
+	switch e.Method {
+	case "map":
+		// The elements take the return type of the function
+		if len(e.Arguments) == 1 {
+			if fn, isFunction := e.Arguments[0].(*ast.Identifier); isFunction {
+				if def := g.findFunctionDefinition(fn.Value); def != nil && def.ReturnType != nil {
+					return &types.ArrayType{ElementType: g.mapASTTypeToType(*def.ReturnType)}
+				}
+			}
+		}
+		return &types.ArrayType{ElementType: types.AnyType}
+	case "filter":
+		return receiverType
+	}
+	if m.returnType == nil {
+		return types.AnyType
+	}
+	return m.returnType
+}
+
+// nativeMethodHelpers implements the methods of strings and arrays that
+// have no direct Go equivalent
+const nativeMethodHelpers = `func zenoMethodIndexOf(s string, substr string) int {
+	i := strings.Index(s, substr)
+	if i < 0 {
+		return -1
+	}
+	return len([]rune(s[:i]))
+}
+
+func zenoMethodContains[T comparable](items []T, value T) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func zenoMethodJoin[T any](items []T, sep string) string {
+	parts := make([]string, len(items))
+	for i, item := range items {
+		parts[i] = fmt.Sprint(item)
+	}
+	return strings.Join(parts, sep)
+}
+
+func zenoMethodMap[T any, U any](items []T, f func(T) U) []U {
+	result := make([]U, len(items))
+	for i, item := range items {
+		result[i] = f(item)
+	}
+	return result
+}
+
+func zenoMethodFilter[T any](items []T, keep func(T) bool) []T {
+	result := []T{}
+	for _, item := range items {
+		if keep(item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+`
@@ -0,0 +1,132 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+	"github.com/linkalls/zeno-lang/types"
+)
+
+// optionType returns the Option type written name, e.g. Option<int>
+func (g *Generator) optionType(name string) *types.OptionType {
+	_, arg, _ := strings.Cut(strings.TrimSuffix(name, ">"), "<")
+	return &types.OptionType{ValueType: g.mapASTTypeToType(arg)}
+}
+
+// isOptionType reports whether a type annotation names the builtin Option
+// type
+func isOptionType(name string) bool {
+	return strings.HasPrefix(name, "Option<") && strings.HasSuffix(name, ">")
+}
+
+// goOptionType returns the Go type of the values of an Option type
+func (g *Generator) goOptionType(t *types.OptionType) string {
+	return "zenoOption[" + g.goValueType(t.ValueType) + "]"
+}
+
+// isNone reports whether name, used as a value, is the empty Option.
+// Variables, functions and variants called none take precedence.
+func (g *Generator) isNone(name string) bool {
+	if name != "none" {
+		return false
+	}
+	if _, isVar := g.symbolTable.Resolve(name); isVar {
+		return false
+	}
+	if _, isFn := g.goFunctionName(name); isFn {
+		return false
+	}
+	_, _, isVariant := g.enumVariant(name)
+	return !isVariant
+}
+
+// optionConstructorType returns the Option type created by some(x) or
+// none: the one expected by the statement, or else the type of x, which is
+// any for none
+func (g *Generator) optionConstructorType(expr ast.Expression) *types.OptionType {
+	if expected, ok := g.expected.(*types.OptionType); ok {
+		return expected
+	}
+	var value types.Type = types.AnyType
+	if call, ok := expr.(*ast.FunctionCall); ok && len(call.Arguments) == 1 {
+		if t := g.knownExpressionType(call.Arguments[0]); t != nil {
+			value = t
+		}
+	}
+	return &types.OptionType{ValueType: value}
+}
+
+// generateSome writes a call to some as a zenoOption literal
+func (g *Generator) generateSome(call *ast.FunctionCall, builder *strings.Builder) error {
+	option := g.optionConstructorType(call)
+	builder.WriteString(g.goOptionType(option) + "{Some: true, Value: ")
+	defer g.expect(option.ValueType)()
+	if err := g.generateExpression(call.Arguments[0], builder); err != nil {
+		return err
+	}
+	builder.WriteString("}")
+	return nil
+}
+
+// optionPattern reports whether the pattern of a match arm matches an
+// Option: some(x), which also binds its value, or none
+func (g *Generator) optionPattern(pattern ast.Expression) bool {
+	switch p := pattern.(type) {
+	case *ast.Identifier:
+		return g.isNone(p.Value)
+	case *ast.FunctionCall:
+		if p.Name != "some" {
+			return false
+		}
+		_, isBuiltin := g.lookupBuiltin(p.Name)
+		return isBuiltin
+	}
+	return false
+}
+
+// isOptionMatch reports whether the arms of e match Options, which is done
+// with a switch on whether the value is set
+func (g *Generator) isOptionMatch(e *ast.MatchExpression) bool {
+	for _, arm := range e.Arms {
+		if g.optionPattern(arm.Pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// registerOptionBindings declares the name bound by the some(x) pattern of
+// an arm of e with the value type of the subject
+func (g *Generator) registerOptionBindings(e *ast.MatchExpression, arm *ast.MatchArm) {
+	var value types.Type = types.AnyType
+	if option, ok := g.inferType(e.Subject).(*types.OptionType); ok {
+		value = option.ValueType
+	}
+	if call, ok := arm.Pattern.(*ast.FunctionCall); ok && g.optionPattern(call) {
+		for _, name := range variantBindings(call.Arguments) {
+			g.registerVariableWithType(name, value)
+		}
+	}
+}
+
+// generateOptionCase writes the case clause of an arm matching some(x) or
+// none and declares the name it binds
+func (g *Generator) generateOptionCase(pattern ast.Expression, builder *strings.Builder, indentLevel int) error {
+	call, isSome := pattern.(*ast.FunctionCall)
+	if !isSome {
+		builder.WriteString(indent(indentLevel) + "case !zenoValue.Some:\n")
+		return nil
+	}
+	if len(call.Arguments) != 1 {
+		return newGenerationErrorAt(pattern, i18n.TypeVariantArity, call.Name, 1, pattern, len(call.Arguments))
+	}
+	builder.WriteString(indent(indentLevel) + "case zenoValue.Some:\n")
+	if name, ok := variantBindings(call.Arguments)[0]; ok {
+		// The arm may not read the value it binds
+		builder.WriteString(fmt.Sprintf("%s%s := zenoValue.Value\n", indent(indentLevel+1), name))
+		builder.WriteString(fmt.Sprintf("%s_ = %s\n", indent(indentLevel+1), name))
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/types"
+)
+
+// checkedOperators are the helpers computing the operators of ints when
+// GeneratorOptions.CheckOverflow is set. They panic where Go wraps around
+// or reports a bare division by zero.
+var checkedOperators = map[ast.BinaryOperator]string{
+	ast.BinaryOpPlus:     "zenoCheckedAdd",
+	ast.BinaryOpMinus:    "zenoCheckedSub",
+	ast.BinaryOpMultiply: "zenoCheckedMul",
+	ast.BinaryOpDivide:   "zenoCheckedDiv",
+	ast.BinaryOpModulo:   "zenoCheckedMod",
+}
+
+// checksOverflow reports whether the operators applied to ints are checked
+// in the expression being generated. The values of consts are left to Go,
+// which computes them at compile time and rejects an overflow.
+func (g *Generator) checksOverflow() bool {
+	return g.options.CheckOverflow && !g.inConstant
+}
+
+// checkedOperator returns the helper computing e, if it applies an operator
+// to two ints that is checked. Literals are left to Go, which keeps them
+// constants that convert to the sized integer types; the type checker
+// reports their overflow.
+func (g *Generator) checkedOperator(e *ast.BinaryExpression) (string, bool) {
+	helper, ok := checkedOperators[e.Operator]
+	if !ok || !g.checksOverflow() || literalInt(e) {
+		return "", false
+	}
+	if g.inferType(e.Left) != types.IntType || g.inferType(e.Right) != types.IntType {
+		return "", false
+	}
+	return helper, true
+}
+
+// literalInt reports whether expr is made of int literals and operators
+func literalInt(expr ast.Expression) bool {
+	switch e := expr.(type) {
+	case *ast.IntegerLiteral:
+		return e.Suffix == ""
+	case *ast.UnaryExpression:
+		return e.Operator == ast.UnaryOpMinus && literalInt(e.Right)
+	case *ast.BinaryExpression:
+		return literalInt(e.Left) && literalInt(e.Right)
+	}
+	return false
+}
+
+// generateChecked writes a call to helper with the operands
+func (g *Generator) generateChecked(helper string, builder *strings.Builder, operands ...ast.Expression) error {
+	builder.WriteString(helper)
+	builder.WriteString("(")
+	for i, operand := range operands {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		if err := g.generateExpression(operand, builder); err != nil {
+			return err
+		}
+	}
+	builder.WriteString(")")
+	return nil
+}
@@ -0,0 +1,141 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+	"github.com/linkalls/zeno-lang/types"
+)
+
+// rangeArguments returns the start, end and step of a call to range, which
+// default to 0 and 1: range(end), range(start, end), range(start, end, step)
+func rangeArguments(call *ast.FunctionCall) (start, end, step ast.Expression) {
+	start = &ast.IntegerLiteral{Position: call.Position, Value: 0}
+	step = &ast.IntegerLiteral{Position: call.Position, Value: 1}
+	switch len(call.Arguments) {
+	case 1:
+		end = call.Arguments[0]
+	case 2:
+		start, end = call.Arguments[0], call.Arguments[1]
+	default:
+		start, end, step = call.Arguments[0], call.Arguments[1], call.Arguments[2]
+	}
+	return start, end, step
+}
+
+// integerConstant returns the value of an integer literal, possibly negated
+func integerConstant(expr ast.Expression) (int, bool) {
+	switch e := expr.(type) {
+	case *ast.IntegerLiteral:
+		return e.Value, true
+	case *ast.UnaryExpression:
+		if value, ok := integerConstant(e.Right); ok && e.Operator == ast.UnaryOpMinus {
+			return -value, true
+		}
+	}
+	return 0, false
+}
+
+// rangeCall returns the call when a for loop iterates over the builtin
+// range, which is then written as a counting loop
+func (g *Generator) rangeCall(iterable ast.Expression) (*ast.FunctionCall, bool) {
+	call, ok := iterable.(*ast.FunctionCall)
+	if !ok || call.Name != "range" || len(call.Arguments) < 1 || len(call.Arguments) > 3 {
+		return nil, false
+	}
+	_, isBuiltin := g.lookupBuiltin(call.Name)
+	return call, isBuiltin
+}
+
+// generateRange writes a call to range used as a value, which builds the
+// array of the numbers
+func (g *Generator) generateRange(call *ast.FunctionCall, builder *strings.Builder) error {
+	start, end, step := rangeArguments(call)
+	if value, ok := integerConstant(step); ok && value == 0 {
+		return newGenerationErrorAt(step, i18n.TypeRangeStep)
+	}
+	defer g.expect(nil)()
+	builder.WriteString("zenoBuiltinRange(")
+	for i, arg := range []ast.Expression{start, end, step} {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		if err := g.generateExpression(arg, builder); err != nil {
+			return err
+		}
+	}
+	builder.WriteString(")")
+	return nil
+}
+
+// generateRangeLoop writes `for i in range(...)` as a Go for loop counting
+// from start to end without building the array. The end and a step that is
+// not constant are evaluated once, before the first iteration. A loop
+// variable written _ is counted in a hidden variable, as Go cannot.
+func (g *Generator) generateRangeLoop(s *ast.ForStatement, call *ast.FunctionCall, builder *strings.Builder, indentLevel int) error {
+	start, end, step := rangeArguments(call)
+	defer g.expect(nil)()
+	generate := func(expr ast.Expression) (string, error) {
+		var code strings.Builder
+		err := g.generateExpression(expr, &code)
+		return code.String(), err
+	}
+	startCode, err := generate(start)
+	if err != nil {
+		return err
+	}
+	endCode, err := generate(end)
+	if err != nil {
+		return err
+	}
+	counter := s.VarName
+	if counter == "_" {
+		counter = "zenoI"
+	}
+	names := []string{counter}
+	values := []string{startCode}
+	// Only a constant end is left in the condition, since the body may
+	// assign the variables the end is computed from
+	if _, ok := integerConstant(end); !ok {
+		names, values = append(names, "zenoEnd"), append(values, endCode)
+		endCode = "zenoEnd"
+	}
+
+	var condition, post string
+	if value, ok := integerConstant(step); ok {
+		switch {
+		case value == 0:
+			return newGenerationErrorAt(step, i18n.TypeRangeStep)
+		case value == 1:
+			condition, post = counter+" < "+endCode, counter+"++"
+		case value == -1:
+			condition, post = counter+" > "+endCode, counter+"--"
+		case value > 0:
+			condition, post = counter+" < "+endCode, counter+" += "+strconv.Itoa(value)
+		default:
+			condition, post = counter+" > "+endCode, counter+" -= "+strconv.Itoa(-value)
+		}
+	} else {
+		stepCode, err := generate(step)
+		if err != nil {
+			return err
+		}
+		names, values = append(names, "zenoStep"), append(values, stepCode)
+		condition = fmt.Sprintf("(zenoStep > 0 && %[1]s < %[2]s) || (zenoStep < 0 && %[1]s > %[2]s)", counter, endCode)
+		post = counter + " += zenoStep"
+	}
+
+	builder.WriteString(indent(indentLevel))
+	builder.WriteString(fmt.Sprintf("for %s := %s; %s; %s ", strings.Join(names, ", "), strings.Join(values, ", "), condition, post))
+	if counter == s.VarName {
+		g.registerVariableWithType(s.VarName, types.IntType)
+	}
+	if err := g.generateBlock(s.Body, builder, indentLevel); err != nil {
+		return err
+	}
+	builder.WriteString("\n")
+	return nil
+}
@@ -0,0 +1,182 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+	"github.com/linkalls/zeno-lang/types"
+)
+
+// resultType returns the Result type written name, e.g. Result<int> which
+// is short for Result<int, string>
+func (g *Generator) resultType(name string) *types.ResultType {
+	result := &types.ResultType{ValueType: types.AnyType, ErrorType: types.StringType}
+	_, args, _ := strings.Cut(strings.TrimSuffix(name, ">"), "<")
+	for i, arg := range types.SplitTypeList(args) {
+		switch i {
+		case 0:
+			result.ValueType = g.mapASTTypeToType(arg)
+		case 1:
+			result.ErrorType = g.mapASTTypeToType(arg)
+		}
+	}
+	return result
+}
+
+// isResultType reports whether a type annotation names the builtin Result
+// type. Result without type arguments is left to struct types declared with
+// that name, like the one of std/result.
+func isResultType(name string) bool {
+	return strings.HasPrefix(name, "Result<") && strings.HasSuffix(name, ">")
+}
+
+// goResultType returns the Go type of the values of a Result type
+func (g *Generator) goResultType(t *types.ResultType) string {
+	return "zenoResult[" + g.goValueType(t.ValueType) + ", " + g.goValueType(t.ErrorType) + "]"
+}
+
+// resultConstructorType returns the Result type created by a call to ok or
+// err: the one expected by the statement, or else a Result whose other type
+// is any, with string errors
+func (g *Generator) resultConstructorType(call *ast.FunctionCall) *types.ResultType {
+	if expected, ok := g.expected.(*types.ResultType); ok {
+		return expected
+	}
+	var arg types.Type = types.AnyType
+	if len(call.Arguments) == 1 {
+		if t := g.knownExpressionType(call.Arguments[0]); t != nil {
+			arg = t
+		}
+	}
+	if call.Name == "ok" {
+		return &types.ResultType{ValueType: arg, ErrorType: types.StringType}
+	}
+	return &types.ResultType{ValueType: types.AnyType, ErrorType: arg}
+}
+
+// expect makes ok, err, some and none create values of t, when it is a
+// Result or an Option, until the returned function is called
+func (g *Generator) expect(t types.Type) func() {
+	outer := g.expected
+	g.expected = t
+	return func() { g.expected = outer }
+}
+
+// generateResultConstructor writes a call to ok or err as a zenoResult
+// literal
+func (g *Generator) generateResultConstructor(call *ast.FunctionCall, builder *strings.Builder) error {
+	builder.WriteString(g.goResultType(g.resultConstructorType(call)))
+	if call.Name == "ok" {
+		builder.WriteString("{Ok: true, Value: ")
+	} else {
+		builder.WriteString("{Error: ")
+	}
+	// The argument has the value or error type of the Result
+	result := g.resultConstructorType(call)
+	argType := result.ValueType
+	if call.Name == "err" {
+		argType = result.ErrorType
+	}
+	defer g.expect(argType)()
+	if err := g.generateExpression(call.Arguments[0], builder); err != nil {
+		return err
+	}
+	builder.WriteString("}")
+	return nil
+}
+
+// generateTryChecks writes the checks of the ? operators in expr before the
+// statement using their values. Each Result is held in a variable, and an
+// error is returned at once from the enclosing function.
+func (g *Generator) generateTryChecks(expr ast.Expression, builder *strings.Builder, indentLevel int) error {
+	for _, try := range tryExpressions(expr) {
+		if g.inValueClosure {
+			// The arm runs in a function literal, see generateMatchExpression
+			return newGenerationErrorAt(try, i18n.GenTryPosition)
+		}
+		result, ok := g.returnType.(*types.ResultType)
+		if !ok {
+			return newGenerationErrorAt(try, i18n.TypeTryOutsideResult)
+		}
+		var value strings.Builder
+		if err := g.generateExpression(try.Value, &value); err != nil {
+			return err
+		}
+		g.tryCount++
+		name := fmt.Sprintf("zenoTry%d", g.tryCount)
+		builder.WriteString(fmt.Sprintf("%s%s := %s\n", indent(indentLevel), name, value.String()))
+		builder.WriteString(fmt.Sprintf("%sif !%s.Ok {\n", indent(indentLevel), name))
+		builder.WriteString(fmt.Sprintf("%sreturn %s{Error: %s.Error}\n", indent(indentLevel+1), g.goResultType(result), name))
+		builder.WriteString(indent(indentLevel) + "}\n")
+		g.tryValues[try] = name + ".Value"
+	}
+	return nil
+}
+
+// tryExpressions returns the ? operators in expr, inner ones first. The
+// ones that are not always evaluated, in match arms, if branches and on the
+// right of && and ||, are left out; they are reported when generated.
+func tryExpressions(expr ast.Expression) []*ast.TryExpression {
+	var result []*ast.TryExpression
+	var walk func(expr ast.Expression)
+	walk = func(expr ast.Expression) {
+		switch e := expr.(type) {
+		case *ast.TryExpression:
+			walk(e.Value)
+			result = append(result, e)
+		case *ast.BinaryExpression:
+			walk(e.Left)
+			if e.Operator != ast.BinaryOpAnd && e.Operator != ast.BinaryOpOr {
+				walk(e.Right)
+			}
+		case *ast.UnaryExpression:
+			walk(e.Right)
+		case *ast.FunctionCall:
+			for _, arg := range e.Arguments {
+				walk(arg)
+			}
+		case *ast.MemberExpression:
+			walk(e.Object)
+		case *ast.MethodCallExpression:
+			walk(e.Object)
+			for _, arg := range e.Arguments {
+				walk(arg)
+			}
+		case *ast.IndexExpression:
+			walk(e.Left)
+			walk(e.Index)
+		case *ast.SliceExpression:
+			walk(e.Left)
+			if e.Low != nil {
+				walk(e.Low)
+			}
+			if e.High != nil {
+				walk(e.High)
+			}
+		case *ast.ArrayLiteral:
+			for _, element := range e.Elements {
+				walk(element)
+			}
+		case *ast.TupleLiteral:
+			for _, element := range e.Elements {
+				walk(element)
+			}
+		case *ast.MapLiteral:
+			for _, key := range e.OrderedKeys() {
+				walk(e.Pairs[key])
+			}
+		case *ast.StructLiteral:
+			for _, name := range e.OrderedFieldNames() {
+				walk(e.Fields[name])
+			}
+		case *ast.MatchExpression:
+			walk(e.Subject)
+		case *ast.IfExpression:
+			walk(e.Condition)
+		}
+	}
+	walk(expr)
+	return result
+}
@@ -0,0 +1,201 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+)
+
+// generateSpawn writes a spawn as a call of zenoSpawn, which runs a closure
+// on a goroutine. The variables of the code around the spawn that the
+// closure reads are copied first, so that it sees their values at the spawn
+// like the arguments of a go statement, whatever the code after it does.
+func (g *Generator) generateSpawn(s *ast.SpawnStatement, builder *strings.Builder, indentLevel int) error {
+	captures := g.spawnCaptures(s.Statements())
+	if len(captures) > 0 {
+		builder.WriteString(indent(indentLevel) + "{\n")
+		indentLevel++
+		list := strings.Join(captures, ", ")
+		builder.WriteString(indent(indentLevel) + list + " := " + list + "\n")
+	}
+	builder.WriteString(indent(indentLevel) + "zenoSpawn(func() {\n")
+	endScope := g.enterScope()
+	for _, stmt := range s.Statements() {
+		if err := g.generateStatement(stmt, builder, indentLevel+1); err != nil {
+			endScope()
+			return err
+		}
+	}
+	endScope()
+	builder.WriteString(indent(indentLevel) + "})\n")
+	if len(captures) > 0 {
+		builder.WriteString(indent(indentLevel-1) + "}\n")
+	}
+	return nil
+}
+
+// spawnCaptures returns the variables of the scope around a spawn that its
+// statements read, in the order of their first reads. A name is left out
+// once the statements declare a variable of their own with it, so that no
+// copy is made that the closure does not use, which Go rejects.
+func (g *Generator) spawnCaptures(statements []ast.Statement) []string {
+	declared := make(map[string]bool)
+	seen := make(map[string]bool)
+	var captures []string
+
+	var walkStatements func(statements []ast.Statement)
+	var walk func(expr ast.Expression)
+	// bind declares the names a match pattern binds, with every identifier
+	// in it, which errs on the side of copying less
+	var bind func(pattern ast.Expression)
+	bind = func(pattern ast.Expression) {
+		switch p := pattern.(type) {
+		case *ast.Identifier:
+			declared[p.Value] = true
+		case *ast.FunctionCall:
+			for _, arg := range p.Arguments {
+				bind(arg)
+			}
+		case *ast.TupleLiteral:
+			for _, element := range p.Elements {
+				bind(element)
+			}
+		}
+	}
+	walkBlock := func(block *ast.Block) {
+		if block != nil {
+			walkStatements(block.Statements)
+		}
+	}
+	walk = func(expr ast.Expression) {
+		switch e := expr.(type) {
+		case *ast.Identifier:
+			if declared[e.Value] || seen[e.Value] {
+				return
+			}
+			if _, isVar := g.symbolTable.Resolve(e.Value); isVar {
+				seen[e.Value] = true
+				captures = append(captures, e.Value)
+			}
+		case *ast.BinaryExpression:
+			walk(e.Left)
+			walk(e.Right)
+		case *ast.UnaryExpression:
+			walk(e.Right)
+		case *ast.TryExpression:
+			walk(e.Value)
+		case *ast.ResultLiteral:
+			walk(e.Value)
+		case *ast.ArrayLiteral:
+			for _, element := range e.Elements {
+				walk(element)
+			}
+		case *ast.TupleLiteral:
+			for _, element := range e.Elements {
+				walk(element)
+			}
+		case *ast.MapLiteral:
+			// The keys are names, not variables
+			for _, key := range e.OrderedKeys() {
+				walk(e.Pairs[key])
+			}
+		case *ast.StructLiteral:
+			for _, name := range e.OrderedFieldNames() {
+				walk(e.Fields[name])
+			}
+		case *ast.IndexExpression:
+			walk(e.Left)
+			walk(e.Index)
+		case *ast.SliceExpression:
+			walk(e.Left)
+			walk(e.Low)
+			walk(e.High)
+		case *ast.FunctionCall:
+			for _, arg := range e.Arguments {
+				walk(arg)
+			}
+		case *ast.MethodCallExpression:
+			walk(e.Object)
+			for _, arg := range e.Arguments {
+				walk(arg)
+			}
+		case *ast.MemberExpression:
+			walk(e.Object)
+		case *ast.MemberAccessExpression:
+			walk(e.Expression)
+		case *ast.MatchExpression:
+			walk(e.Subject)
+			for i := range e.Arms {
+				arm := &e.Arms[i]
+				bind(arm.Pattern)
+				walk(arm.Value)
+				walkBlock(arm.Block)
+			}
+		case *ast.IfExpression:
+			walk(e.Condition)
+			for _, clause := range e.ElseIfClauses {
+				walk(clause.Condition)
+			}
+			for _, block := range e.Blocks() {
+				walkBlock(block)
+			}
+		}
+	}
+	walkStatements = func(statements []ast.Statement) {
+		for _, stmt := range statements {
+			switch s := stmt.(type) {
+			case *ast.LetDeclaration:
+				walk(s.ValueExpression)
+				declared[s.Name] = true
+				for _, name := range s.Names {
+					declared[name] = true
+				}
+			case *ast.AssignmentStatement:
+				walk(s.Value)
+			case *ast.ExpressionStatement:
+				walk(s.Expression)
+			case *ast.ReturnStatement:
+				walk(s.Value)
+			case *ast.IfStatement:
+				walk(s.Condition)
+				walkBlock(s.ThenBlock)
+				for _, clause := range s.ElseIfClauses {
+					walk(clause.Condition)
+					walkBlock(clause.Block)
+				}
+				walkBlock(s.ElseBlock)
+			case *ast.WhileStatement:
+				walk(s.Condition)
+				walkBlock(s.Block)
+			case *ast.LoopStatement:
+				walkBlock(s.Body)
+			case *ast.ForStatement:
+				walk(s.Iterable)
+				declared[s.IndexName] = true
+				declared[s.VarName] = true
+				walkBlock(s.Body)
+			case *ast.SpawnStatement:
+				walkStatements(s.Statements())
+			case *ast.TryStatement:
+				walkBlock(s.Body)
+				if s.CatchName != "" {
+					declared[s.CatchName] = true
+				}
+				walkBlock(s.Catch)
+			case *ast.WhenStatement:
+				for i := range s.Arms {
+					arm := &s.Arms[i]
+					walk(arm.Channel)
+					walk(arm.Sent)
+					if arm.Name != "" {
+						declared[arm.Name] = true
+					}
+					walk(arm.Value)
+					walkBlock(arm.Block)
+				}
+			}
+		}
+	}
+	walkStatements(statements)
+	return captures
+}
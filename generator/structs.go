@@ -0,0 +1,237 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+	"github.com/linkalls/zeno-lang/types"
+)
+
+// goFieldName returns the Go name of a struct field. Fields are exported so
+// that std/json and fmt can see them.
+func goFieldName(name string) string {
+	return exportedName(name)
+}
+
+// generateTypeDeclaration writes a type declaration as a Go struct with a
+// String method. The json tags keep the Zeno field names when a value is
+// stringified.
+func (g *Generator) generateTypeDeclaration(decl *ast.TypeDeclaration, builder *strings.Builder) {
+	builder.WriteString("type " + decl.Name)
+	if len(decl.Generics) > 0 {
+		builder.WriteString("[" + strings.Join(decl.Generics, ", ") + " any]")
+	}
+	builder.WriteString(" struct {\n")
+	for _, field := range decl.Fields {
+		builder.WriteString(fmt.Sprintf("\t%s %s `json:%s`\n", goFieldName(field.Name), mapType(field.TypeAnn), strconv.Quote(field.Name)))
+	}
+	builder.WriteString("}\n\n")
+
+	// Values print like the Zeno literal that creates them
+	receiver := decl.Name
+	if len(decl.Generics) > 0 {
+		receiver += "[" + strings.Join(decl.Generics, ", ") + "]"
+	}
+	var format, args []string
+	for _, field := range decl.Fields {
+		verb := "%v"
+		if field.TypeAnn == "string" {
+			verb = "%q"
+		}
+		format = append(format, field.Name+": "+verb)
+		args = append(args, ", v."+goFieldName(field.Name))
+	}
+	builder.WriteString(fmt.Sprintf("func (v %s) String() string {\n", receiver))
+	builder.WriteString(fmt.Sprintf("\treturn fmt.Sprintf(%s%s)\n", strconv.Quote(decl.Name+"{"+strings.Join(format, ", ")+"}"), strings.Join(args, "")))
+	builder.WriteString("}\n\n")
+}
+
+// generateTypeDeclarations writes the types declared in the program and the
+// ones it imports. Standard library types are copied like their functions;
+// types of user modules are aliases of the type in the module's package.
+func (g *Generator) generateTypeDeclarations(program *ast.Program, builder *strings.Builder) {
+	for _, stmt := range program.Statements {
+		switch decl := stmt.(type) {
+		case *ast.TypeDeclaration:
+			g.generateTypeDeclaration(decl, builder)
+		case *ast.EnumDeclaration:
+			g.generateEnumDeclaration(decl, builder)
+		}
+	}
+	for modulePath, typeNames := range g.importTypes {
+		moduleAST, exists := g.moduleASTs[modulePath]
+		if !exists {
+			continue
+		}
+		for _, stmt := range moduleAST.Statements {
+			if enum, ok := stmt.(*ast.EnumDeclaration); ok && containsString(typeNames, enum.Name) {
+				g.generateImportedEnum(modulePath, enum, builder)
+				continue
+			}
+			decl, ok := stmt.(*ast.TypeDeclaration)
+			if !ok || !containsString(typeNames, decl.Name) {
+				continue
+			}
+			alias, isUserModule := g.modulePackages[modulePath]
+			if !isUserModule {
+				g.generateTypeDeclaration(decl, builder)
+				continue
+			}
+			g.usedPackages[alias] = true
+			if len(decl.Generics) > 0 {
+				params := strings.Join(decl.Generics, ", ")
+				builder.WriteString(fmt.Sprintf("type %s[%s any] = %s.%s[%s]\n\n", decl.Name, params, alias, decl.Name, params))
+			} else {
+				builder.WriteString(fmt.Sprintf("type %s = %s.%s\n\n", decl.Name, alias, decl.Name))
+			}
+		}
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// structDeclaration returns the declaration of a struct type, given its name
+// with or without type arguments ("Box" or "Box<int>")
+func (g *Generator) structDeclaration(typeName string) *ast.TypeDeclaration {
+	return g.findTypeDeclaration(strings.SplitN(typeName, "<", 2)[0])
+}
+
+// structField looks up a field of a struct type
+func structField(decl *ast.TypeDeclaration, name string) (ast.TypeField, bool) {
+	for _, field := range decl.Fields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return ast.TypeField{}, false
+}
+
+// generateStructLiteral writes a literal of a declared type as a Go
+// composite literal
+func (g *Generator) generateStructLiteral(e *ast.StructLiteral, builder *strings.Builder) error {
+	decl := g.structDeclaration(e.TypeName)
+	if decl == nil {
+		name := strings.SplitN(e.TypeName, "<", 2)[0]
+		return newGenerationErrorAt(e, i18n.TypeUnknownType, name, name)
+	}
+
+	g.warnIfDeprecated(e, i18n.GenWarnDeprecatedType, e.TypeName, decl.Deprecated)
+	for _, fieldName := range e.OrderedFieldNames() {
+		if _, ok := structField(decl, fieldName); !ok {
+			return newGenerationErrorAt(e, i18n.GenUnknownField, decl.Name, fieldName)
+		}
+	}
+	builder.WriteString(decl.Name)
+	builder.WriteString(g.typeArguments(decl, e))
+	builder.WriteString("{")
+	for i, fieldName := range e.OrderedFieldNames() {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString(goFieldName(fieldName) + ": ")
+		if err := g.generateExpression(e.Fields[fieldName], builder); err != nil {
+			return err
+		}
+	}
+	builder.WriteString("}")
+	return nil
+}
+
+// typeArguments returns the Go type arguments of a literal of a generic
+// type, e.g. "[int]" for Box{value: 1}. Go cannot infer them for composite
+// literals, so each parameter is taken from the first field declared with
+// it; parameters without such a field are interface{}.
+func (g *Generator) typeArguments(decl *ast.TypeDeclaration, e *ast.StructLiteral) string {
+	if len(decl.Generics) == 0 {
+		return ""
+	}
+	args := make([]string, len(decl.Generics))
+	for i, param := range decl.Generics {
+		args[i] = "interface{}"
+		for _, field := range decl.Fields {
+			value, ok := e.Fields[field.Name]
+			if field.TypeAnn != param || !ok {
+				continue
+			}
+			if goType := getGoTypeForZenoPrimitiveType(g.inferType(value)); goType != "interface{}" {
+				args[i] = goType
+			}
+			break
+		}
+	}
+	return "[" + strings.Join(args, ", ") + "]"
+}
+
+// generateMemberAccess writes obj.field. Values of declared struct types and
+// Results use Go field access; other values, such as maps from std/json, are
+// indexed.
+func (g *Generator) generateMemberAccess(e *ast.MemberExpression, builder *strings.Builder) error {
+	if err := g.generateExpression(e.Object, builder); err != nil {
+		return err
+	}
+	if result, ok := g.inferType(e.Object).(*types.ResultType); ok {
+		if _, ok := resultField(result, e.Property); !ok {
+			return newGenerationErrorAt(e, i18n.GenUnknownField, "Result", e.Property)
+		}
+		builder.WriteString("." + goFieldName(e.Property))
+		return nil
+	}
+	if structType, ok := g.inferType(e.Object).(*types.StructType); ok {
+		if decl := g.structDeclaration(structType.Name); decl != nil {
+			if _, ok := structField(decl, e.Property); !ok {
+				return newGenerationErrorAt(e, i18n.GenUnknownField, decl.Name, e.Property)
+			}
+			builder.WriteString("." + goFieldName(e.Property))
+			return nil
+		}
+	}
+	builder.WriteString("[")
+	builder.WriteString(strconv.Quote(e.Property))
+	builder.WriteString("]")
+	return nil
+}
+
+// memberType returns the type of obj.field when obj is a declared struct or
+// a Result
+func (g *Generator) memberType(e *ast.MemberExpression) (types.Type, bool) {
+	object := g.inferType(e.Object)
+	if result, ok := object.(*types.ResultType); ok {
+		return resultField(result, e.Property)
+	}
+	structType, ok := object.(*types.StructType)
+	if !ok {
+		return nil, false
+	}
+	decl := g.structDeclaration(structType.Name)
+	if decl == nil {
+		return nil, false
+	}
+	field, ok := structField(decl, e.Property)
+	if !ok {
+		return nil, false
+	}
+	return g.mapASTTypeToType(field.TypeAnn), true
+}
+
+// resultField returns the type of a field of values of a Result type
+func resultField(result *types.ResultType, name string) (types.Type, bool) {
+	switch name {
+	case "ok":
+		return types.BoolType, true
+	case "value":
+		return result.ValueType, true
+	case "error":
+		return result.ErrorType, true
+	}
+	return nil, false
+}
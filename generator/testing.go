@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+)
+
+// TestPrefix starts the names of the test functions run by zeno test
+const TestPrefix = "test_"
+
+// TestFunctions returns the test functions of a program, in the order they
+// are declared: the functions without parameters or type parameters whose
+// names start with TestPrefix
+func TestFunctions(program *ast.Program) []*ast.FunctionDefinition {
+	var tests []*ast.FunctionDefinition
+	for _, stmt := range program.Statements {
+		fn, ok := stmt.(*ast.FunctionDefinition)
+		if ok && strings.HasPrefix(fn.Name, TestPrefix) && len(fn.Parameters) == 0 && len(fn.Generics) == 0 {
+			tests = append(tests, fn)
+		}
+	}
+	return tests
+}
+
+// generateTestMain writes the main function of a test program, which runs
+// the test functions and reports their results. The tests count as used.
+func (g *Generator) generateTestMain(builder *strings.Builder) {
+	builder.WriteString(nativeTestRunner)
+	builder.WriteString("func main() {\n")
+	builder.WriteString("\tzenoRunTests([]zenoTest{\n")
+	for _, test := range TestFunctions(g.program) {
+		g.usedFns[test.Name] = true
+		goName, ok := g.declaredFns[test.Name]
+		if !ok {
+			goName = test.Name
+		}
+		builder.WriteString(fmt.Sprintf("\t\t{%q, %s},\n", test.Name, goName))
+	}
+	builder.WriteString("\t})\n")
+	builder.WriteString("}\n")
+}
+
+// nativeTestRunner runs the tests of a test program. A test fails when it
+// panics, which failed assertions do; the program exits with status 1 if
+// any test failed.
+const nativeTestRunner = `type zenoTest struct {
+	name string
+	run  func()
+}
+
+func zenoRunTests(tests []zenoTest) {
+	failed := 0
+	for _, test := range tests {
+		if message := zenoRunTest(test); message != "" {
+			failed++
+			fmt.Printf("FAIL %s\n    %s\n", test.name, message)
+		} else {
+			fmt.Printf("ok   %s\n", test.name)
+		}
+	}
+	fmt.Printf("%d passed, %d failed\n", len(tests)-failed, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func zenoRunTest(test zenoTest) (message string) {
+	defer func() {
+		if r := recover(); r != nil {
+			if failure, ok := r.(zenoAssertionFailed); ok {
+				message = failure.Error()
+			} else {
+				message = fmt.Sprintf("panic: %v", r)
+			}
+		}
+	}()
+	test.run()
+	return ""
+}
+
+`
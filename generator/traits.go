@@ -0,0 +1,104 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/types"
+)
+
+// traitMethodName returns the name of the Go method an impl function is
+// compiled to. It is exported, so that the operators work on the types of
+// user modules, and prefixed so that it cannot clash with a field.
+func traitMethodName(method string) string {
+	return "Zeno" + exportedName(method)
+}
+
+// generateImpl writes the function of an impl as a Go method of the struct
+// type, whose receiver is the first parameter
+func (g *Generator) generateImpl(s *ast.ImplDeclaration, builder *strings.Builder, indentLevel int) error {
+	for _, method := range s.Methods {
+		if len(method.Parameters) != 2 {
+			continue
+		}
+		receiver, other := method.Parameters[0], method.Parameters[1]
+		builder.WriteString(indent(indentLevel) + "func (" + receiver.Name + " " + mapType(receiver.Type) + ") ")
+		builder.WriteString(traitMethodName(method.Name) + "(" + other.Name + " " + mapType(other.Type) + ")")
+		if method.ReturnType != nil {
+			builder.WriteString(" " + mapType(*method.ReturnType))
+		}
+		if err := g.generateFunctionBody(method, builder, indentLevel, builder.Len()); err != nil {
+			return err
+		}
+		builder.WriteString("\n")
+	}
+	return nil
+}
+
+// findImpl looks up the function that implements trait for a struct type
+// declared in the program or imported from a module, whose impls come
+// with it
+func (g *Generator) findImpl(typeName, trait string) (*ast.FunctionDefinition, bool) {
+	programs := []*ast.Program{g.program}
+	for modulePath, typeNames := range g.importTypes {
+		if containsString(typeNames, typeName) {
+			programs = append(programs, g.moduleASTs[modulePath])
+		}
+	}
+	for _, program := range programs {
+		if program == nil {
+			continue
+		}
+		for _, stmt := range program.Statements {
+			if impl, ok := stmt.(*ast.ImplDeclaration); ok && impl.TypeName == typeName && impl.Trait == trait {
+				return impl.Method(ast.Traits[trait].Method)
+			}
+		}
+	}
+	return nil, false
+}
+
+// operatorMethod returns the impl function that the operator of e calls,
+// when its operands are values of a struct type implementing its trait
+func (g *Generator) operatorMethod(e *ast.BinaryExpression) (*ast.FunctionDefinition, bool) {
+	trait := ast.OperatorTrait(e.Operator)
+	if trait == "" {
+		return nil, false
+	}
+	left, ok := g.inferType(e.Left).(*types.StructType)
+	if !ok || g.inferType(e.Right).String() != left.Name {
+		return nil, false
+	}
+	return g.findImpl(left.Name, trait)
+}
+
+// generateOperatorCall writes an operator as a call of the method it is
+// given by: a + b as a.ZenoAdd(b), a != b as !a.ZenoEq(b) and a < b as
+// a.ZenoCompare(b) < 0
+func (g *Generator) generateOperatorCall(e *ast.BinaryExpression, method *ast.FunctionDefinition, builder *strings.Builder) error {
+	var call strings.Builder
+	_, literal := e.Left.(*ast.StructLiteral)
+	if literal {
+		call.WriteString("(")
+	}
+	if err := g.generateExpression(e.Left, &call); err != nil {
+		return err
+	}
+	if literal {
+		call.WriteString(")")
+	}
+	call.WriteString("." + traitMethodName(method.Name) + "(")
+	if err := g.generateExpression(e.Right, &call); err != nil {
+		return err
+	}
+	call.WriteString(")")
+	switch e.Operator {
+	case ast.BinaryOpPlus, ast.BinaryOpEq:
+		builder.WriteString(call.String())
+	case ast.BinaryOpNotEq:
+		builder.WriteString("(!" + call.String() + ")")
+	default:
+		builder.WriteString("(" + call.String() + " " + e.Operator.String() + " 0)")
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/types"
+)
+
+// generateTry writes a try as a call of zenoCatch, which runs the try block
+// in a function literal and recovers a panic in it, followed by the catch
+// block, which runs as part of the function when there was a panic.
+func (g *Generator) generateTry(s *ast.TryStatement, builder *strings.Builder, indentLevel int) error {
+	message := "_"
+	if s.CatchName != "" && g.catchReads(s) {
+		message = s.CatchName
+	}
+	builder.WriteString(indent(indentLevel) + "if " + message + ", zenoCaught := zenoCatch(func() {\n")
+	if err := g.generateTryBlock(s.Body, builder, indentLevel+1); err != nil {
+		return err
+	}
+	builder.WriteString(indent(indentLevel) + "}); zenoCaught {\n")
+	defer g.enterScope()()
+	if s.CatchName != "" {
+		g.registerVariableWithType(s.CatchName, types.StringType)
+	}
+	for _, stmt := range s.Catch.Statements {
+		if err := g.generateStatement(stmt, builder, indentLevel+1); err != nil {
+			return err
+		}
+	}
+	builder.WriteString(indent(indentLevel) + "}\n")
+	return nil
+}
+
+// generateTryBlock writes the statements of a try block, which cannot break
+// out of the loops around the try
+func (g *Generator) generateTryBlock(block *ast.Block, builder *strings.Builder, indentLevel int) error {
+	defer g.enterScope()()
+	for _, stmt := range block.Statements {
+		if err := g.generateStatement(stmt, builder, indentLevel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// catchReads reports whether the catch block of a try reads the message of
+// the panic, as Go rejects a variable that is never read
+func (g *Generator) catchReads(s *ast.TryStatement) bool {
+	defer g.enterScope()()
+	g.registerVariableWithType(s.CatchName, types.StringType)
+	for _, name := range g.spawnCaptures(s.Catch.Statements) {
+		if name == s.CatchName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,261 @@
+package generator
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/deps"
+	"github.com/linkalls/zeno-lang/i18n"
+)
+
+// GoModulePath is the module path of generated Go workspaces. User module
+// packages are imported as GoModulePath + "/" + Package.Dir.
+const GoModulePath = "zenoprogram"
+
+// Package is one generated Go package
+type Package struct {
+	Dir        string // directory relative to the workspace root, "" for main
+	Name       string // Go package name
+	SourceFile string // Zeno file the package was generated from
+	Code       string // Go source code
+	SourceMap  *SourceMap
+	// goModules lists the third-party Go modules the package depends on
+	goModules []string
+}
+
+// File returns the path of the package's Go file relative to the workspace
+// root
+func (p *Package) File() string {
+	if p.Dir == "" {
+		return "main.go"
+	}
+	return filepath.ToSlash(filepath.Join(p.Dir, p.Name+".go"))
+}
+
+// Workspace is the Go module generated from a Zeno program: the main package
+// and one package per imported user module, so that modules keep their own
+// namespaces.
+type Workspace struct {
+	Packages []*Package // the main package first, then modules sorted by Dir
+}
+
+// Main returns the main package
+func (w *Workspace) Main() *Package { return w.Packages[0] }
+
+// GoModules returns the third-party Go modules ("path version") required by
+// any package of the workspace
+func (w *Workspace) GoModules() []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, pkg := range w.Packages {
+		for _, module := range pkg.goModules {
+			if !seen[module] {
+				seen[module] = true
+				result = append(result, module)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// workspaceState is shared by the generators of all packages of a workspace
+type workspaceState struct {
+	rootDir  string              // directory of the main Zeno file
+	packages map[string]*Package // by absolute Zeno file path
+	cache    PackageCache        // nil if packages are not cached
+	keys     map[string]string   // cache keys by absolute Zeno file path
+	loading  []string            // absolute Zeno file paths being generated, importers first
+}
+
+// newWorkspaceState returns the state of a workspace whose main Zeno file
+// is in rootDir
+func newWorkspaceState(rootDir string, cache PackageCache) *workspaceState {
+	return &workspaceState{
+		rootDir:  rootDir,
+		packages: make(map[string]*Package),
+		cache:    cache,
+		keys:     make(map[string]string),
+	}
+}
+
+// GenerateWorkspace generates the Go packages for a program read from
+// sourceFile and the user modules it imports, directly or through other
+// modules. Warnings and SourceMap describe the main package.
+func (g *Generator) GenerateWorkspace(program *ast.Program, sourceFile string) (*Workspace, error) {
+	rootDir, err := filepath.Abs(filepath.Dir(sourceFile))
+	if err != nil {
+		return nil, err
+	}
+	g.workspace = newWorkspaceState(rootDir, g.cache)
+	if absFile, err := filepath.Abs(sourceFile); err == nil {
+		g.workspace.loading = []string{absFile}
+	}
+	code, err := g.GenerateFile(program, sourceFile)
+	if err != nil {
+		return nil, err
+	}
+	mainPackage := &Package{Name: "main", SourceFile: sourceFile, Code: code, SourceMap: g.sourceMap, goModules: g.GoModules()}
+	workspace := &Workspace{Packages: []*Package{mainPackage}}
+	var modules []*Package
+	for _, pkg := range g.workspace.packages {
+		modules = append(modules, pkg)
+	}
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Dir < modules[j].Dir })
+	workspace.Packages = append(workspace.Packages, modules...)
+	return workspace, nil
+}
+
+// userPackage returns the Go package generated for the user module in
+// zenoFile, generating it on first use. Warnings in modules are left to
+// their authors.
+func (g *Generator) userPackage(zenoFile string, program *ast.Program) (*Package, error) {
+	if g.workspace == nil {
+		rootDir, err := filepath.Abs(filepath.Dir(g.currentDir))
+		if err != nil {
+			return nil, err
+		}
+		g.workspace = newWorkspaceState(rootDir, g.cache)
+	}
+	absFile, err := filepath.Abs(zenoFile)
+	if err != nil {
+		return nil, err
+	}
+	g.userImports = append(g.userImports, absFile)
+	if cycle := g.workspace.importCycle(absFile); cycle != "" {
+		return nil, newGenerationError(i18n.GenImportCycle, cycle)
+	}
+	if pkg, ok := g.workspace.packages[absFile]; ok {
+		return pkg, nil
+	}
+	dir := packageDir(g.workspace.rootDir, absFile)
+	pkg := &Package{Dir: dir, Name: filepath.Base(dir), SourceFile: zenoFile}
+	g.workspace.packages[absFile] = pkg
+	g.workspace.loading = append(g.workspace.loading, absFile)
+	defer func() { g.workspace.loading = g.workspace.loading[:len(g.workspace.loading)-1] }()
+
+	var key string
+	if g.workspace.cache != nil {
+		key = g.workspace.packageKey(g, absFile)
+		if ok, err := g.cachedPackage(key, pkg); ok || err != nil {
+			return pkg, err
+		}
+	}
+
+	sub := NewGenerator()
+	sub.workspace = g.workspace
+	sub.packageName = pkg.Name
+	sub.buildConstants = g.buildConstants
+	sub.options = g.options
+	code, err := sub.GenerateFile(program, zenoFile)
+	if genErr, ok := err.(GenerationError); ok && genErr.File == "" {
+		genErr.File = zenoFile
+		return nil, genErr
+	}
+	if genErrs, ok := err.(GenerationErrors); ok {
+		for i := range genErrs {
+			if genErrs[i].File == "" {
+				genErrs[i].File = zenoFile
+			}
+		}
+		return nil, genErrs
+	}
+	if err != nil {
+		return nil, err
+	}
+	pkg.Code = code
+	pkg.SourceMap = sub.sourceMap
+	pkg.goModules = sub.GoModules()
+	if g.workspace.cache != nil {
+		g.workspace.cache.Store(key, &CachedPackage{
+			Code:      pkg.Code,
+			Lines:     pkg.SourceMap.lines,
+			GoModules: pkg.goModules,
+			Imports:   sub.userImports,
+		})
+	}
+	return pkg, nil
+}
+
+// importCycle returns the import path from absFile back to itself, as in
+// "a.zeno → b.zeno → a.zeno", if the file is being generated and so
+// imports itself through the files importing it. Go packages cannot import
+// each other.
+func (w *workspaceState) importCycle(absFile string) string {
+	for i, loading := range w.loading {
+		if loading != absFile {
+			continue
+		}
+		var files []string
+		for _, file := range w.loading[i:] {
+			if rel, err := filepath.Rel(w.rootDir, file); err == nil {
+				file = filepath.ToSlash(rel)
+			}
+			files = append(files, file)
+		}
+		return strings.Join(append(files, files[0]), " → ")
+	}
+	return ""
+}
+
+// packageDir maps a module file to its package directory below the
+// workspace root: "lib/math_utils.zeno" becomes "lib/math_utils". Modules
+// outside the root are placed under "parent" directories, remote modules
+// under "deps", and every path element is turned into a valid Go identifier.
+func packageDir(rootDir, absFile string) string {
+	rel, err := filepath.Rel(rootDir, absFile)
+	if err != nil {
+		rel = filepath.Base(absFile)
+	}
+	if cached, err := filepath.Rel(deps.CacheDir, absFile); err == nil && !strings.HasPrefix(cached, "..") {
+		rel = filepath.Join("deps", cached)
+	}
+	rel = strings.TrimSuffix(rel, filepath.Ext(rel))
+	elements := strings.Split(filepath.ToSlash(rel), "/")
+	for i, element := range elements {
+		if element == ".." {
+			elements[i] = "parent"
+			continue
+		}
+		if element == "internal" {
+			// Go only lets the packages next to an internal directory
+			// import the ones below it, and any module may import another
+			elements[i] = "internal_"
+			continue
+		}
+		elements[i] = goIdentifier(element)
+	}
+	return strings.Join(elements, "/")
+}
+
+// goIdentifier replaces the characters of name that are not allowed in Go
+// identifiers with underscores
+func goIdentifier(name string) string {
+	var builder strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_' || unicode.IsLetter(r):
+			builder.WriteRune(r)
+		case unicode.IsDigit(r):
+			if i == 0 {
+				builder.WriteString("m")
+			}
+			builder.WriteRune(r)
+		default:
+			builder.WriteRune('_')
+		}
+	}
+	if builder.Len() == 0 {
+		return "m"
+	}
+	return builder.String()
+}
+
+// packageAlias is the name under which a user package is imported. The
+// prefix keeps it apart from Go packages and Zeno variables.
+func packageAlias(pkg *Package) string {
+	return "zeno_" + strings.ReplaceAll(pkg.Dir, "/", "_")
+}
@@ -0,0 +1,178 @@
+package i18n
+
+var english = map[MessageID]string{
+	LabelError:           "error",
+	LabelWarning:         "warning",
+	LabelInfo:            "info",
+	LabelNote:            "note",
+	LabelHelp:            "help",
+	LabelLocation:        "line %d, column %d",
+	LabelExpectedGot:     "expected %s, but got %s",
+	LabelGenerationError: "Generation Error: %s",
+	LabelTypeError:       "Type Error: %s",
+
+	ParserExpectedNextToken:        "expected next token to be %s, got %s instead",
+	ParserHintCloseParen:           "add missing ')' to close function call or expression",
+	ParserHintCloseBlock:           "add missing '}' to close block",
+	ParserInvalidInteger:           "could not parse %q as integer",
+	ParserInvalidFloat:             "could not parse %q as float",
+	ParserNoPrefixParseFn:          "no prefix parse function for %s found",
+	ParserHintUnexpectedRParen:     "unexpected ')' - check for empty function call like 'println()'",
+	ParserHintUnexpectedEOF:        "unexpected end of file - check for incomplete expression",
+	ParserImportSeparator:          "expected ',' or '}' in import statement, got %s",
+	ParserPubWithoutFn:             "pub can only be used with function definitions and imports",
+	ParserExpectedParamName:        "expected parameter name",
+	ParserVariadicNotLast:          "variadic parameter must be the last parameter",
+	ParserHintVariadicNotLast:      "move variadic parameter to the end",
+	ParserArrayFirstNotPrimitive:   "array element type is not a primitive type (int, float, string, bool), got %s for first element",
+	ParserArrayElementNotPrimitive: "array element type is not a primitive type (int, float, string, bool), got %s at index %d (expected %s)",
+	ParserArrayMismatchedTypes:     "mismatched types in array literal: expected %s, got %s at index %d",
+	ParserInvalidMapKey:            "invalid map key type: expected IDENTIFIER or STRING, got %T",
+	ParserMapSeparatorEOF:          "expected ',' or '}' after map value, got EOF",
+	ParserMapSeparator:             "expected ',' or '}' after map value, got %s instead",
+	ParserExpectedCloseBlock:       "expected '}' to close block",
+	ParserStructNeedsTypeName:      "struct literal requires a type name",
+	ParserHintStructTypeName:       "use a type name like Result{...}",
+	ParserStructFieldName:          "struct field name must be identifier, got %s",
+	ParserStructSeparatorEOF:       "expected ',' or '}' after struct field value, got EOF",
+	ParserStructSeparator:          "expected ',' or '}' after struct field value, got %s instead",
+	ParserExpectedCloseStruct:      "expected '}' to close struct literal",
+	ParserHintCloseStruct:          "add missing '}' to close struct literal",
+	ParserCallOnNonIdentifier:      "function call on non-identifier expression not supported",
+	ParserHintCallOnNonIdentifier:  "use a function name instead of expression",
+	ParserCallNeedsArgument:        "function '%s' requires at least one argument",
+	ParserHintCallNeedsArgument:    "add an argument like println(\"Hello\")",
+	ParserElseSyntax:               "expected 'if' or '{' after 'else', got %s",
+	ParserExpectedPropertyName:     "expected property name after '.'",
+	ParserHintExpectedPropertyName: "ensure valid identifier follows '.'",
+	ParserUnknownAttribute:         "unknown attribute '@%s'",
+	ParserAttributeTarget:          "'@%s' must be followed by a function or type declaration",
+	ParserOutsideLoop:              "'%s' outside of a loop",
+	ParserEnumVariantName:          "enum variant name must be identifier, got %s",
+	ParserIfWithoutElse:            "an if used as a value needs an else block",
+	ParserSpawnTarget:              "'spawn' must be followed by a block or a function call, got %s",
+	ParserImplMember:               "an impl block can only declare functions, got %s",
+	ParserImportTypeAlias:          "the type %s cannot be imported under another name; only functions can be renamed with as",
+	ParserPubNamespaceImport:       "pub import * as %s cannot re-export a whole module; list the items to re-export",
+	ParserIntegerOutOfRange:        "integer literal %s is out of range for %s, which holds %d to %d",
+	ParserInvalidChar:              "character literal '%s' must hold exactly one character; use double quotes for a string",
+	ParserWarnEmptyIfBlock:         "empty block in 'if' statement",
+	ParserHintEmptyIfBlock:         "remove the statement or add a body",
+	ParserWarnEmptyWhileBody:       "empty body in 'while' loop",
+	ParserHintEmptyWhileBody:       "a loop without a body either never runs or never terminates",
+
+	GenUnsupportedStatement:       "Unsupported statement type: %T",
+	GenUnsupportedExpression:      "Unsupported expression type: %T",
+	GenUnsupportedMapKey:          "unsupported map key type: %T",
+	GenUnusedVariable:             "variable '%s' is declared but not used",
+	GenUnusedFunction:             "function '%s' is defined but not used",
+	GenNotImported:                "Function '%s' is not imported from '%s'",
+	GenMissingImport:              "Function '%s' is not defined or imported",
+	GenHintAddImport:              "add `%s`",
+	GenModuleReadFailed:           "Failed to read module file '%s': %v",
+	GenModuleParseErrors:          "Parse errors in module '%s': %v",
+	GenModuleNotFetched:           "Module '%s' has not been fetched; run `%s`",
+	GenImportCycle:                "Import cycle: %s",
+	GenFormatFailed:               "Generated Go code is invalid, which is a bug in the compiler: %s\n%s",
+	GenFunctionNotExported:        "Function '%s' is not exported from module '%s'",
+	GenTypeNotExported:            "Type '%s' is not exported from module '%s'",
+	GenParamNeedsType:             "Function '%s': parameter '%s' must have an explicit type",
+	GenMissingReturnType:          "Function '%s' contains return statements with values but has no explicit return type",
+	GenArgumentCount:              "Function '%s' expects %d argument(s), got %d in call %s",
+	GenArgumentCountAtLeast:       "Function '%s' expects at least %d argument(s), got %d in call %s",
+	GenArgumentType:               "Argument %d of '%s' (parameter '%s') expects %s, got %s in call %s",
+	GenUnknownBuildConstant:       "Build constant '%s' is not defined; pass it with -D %s=VALUE",
+	GenUnknownField:               "Type '%s' has no field '%s'",
+	GenMatchArmWithoutValue:       "A match arm used as a value must end with an expression",
+	GenIfBranchWithoutValue:       "A branch of an if used as a value must end with an expression",
+	GenTryPosition:                "The ? operator cannot be used here; assign the value to a variable first",
+	GenWarnImplicitBoolConversion: "implicit conversion of %s to bool in condition '%s'",
+	GenWarnDeprecatedFunction:     "function '%s' is deprecated",
+	GenWarnDeprecatedType:         "type '%s' is deprecated",
+
+	TypeLetMismatch:        "Variable '%s' is declared as %s but initialized with %s",
+	TypeAssignMismatch:     "Cannot assign %s to variable '%s' of type %s",
+	TypeFieldMismatch:      "Field '%s' of '%s' expects %s, got %s",
+	TypeVariantField:       "Field %d of variant '%s' expects %s, got %s",
+	TypeReturnMismatch:     "Function '%s' returns %s, but the return value is %s",
+	TypeMissingReturnValue: "Function '%s' must return a value of type %s",
+	TypeInvalidOperands:    "Operator %s cannot be applied to %s and %s",
+	TypeInvalidOperand:     "Operator %s cannot be applied to %s",
+	TypeUndefinedVariable:  "Undefined variable '%s'",
+	TypeInvalidCondition:   "Condition must be a bool, got %s",
+	TypeNotIterable:        "Cannot iterate over %s",
+	TypeMatchArmMismatch:   "Match arm has type %s, but the previous arms have type %s",
+	TypeIfBranchMismatch:   "If branch has type %s, but the previous branches have type %s",
+	TypeTupleValue:         "A tuple of type %s cannot be stored in a variable; take it apart with let (a, b) = ...",
+	TypeTupleArity:         "Tuple of type %s has %d elements, but %d names are given",
+	TypeNotTuple:           "Cannot take apart %s with let (...); it is not a tuple",
+	TypeNotStructOrMap:     "Cannot take apart %s with let {...}; it is not a struct or map",
+	TypeNotArray:           "Cannot take apart %s with let [...]; it is not an array",
+	TypeMatchPattern:       "Pattern %s of type %s cannot match a value of type %s",
+	TypeDuplicatePattern:   "Pattern %s is already matched by an earlier arm",
+	TypeVariantBinding:     "Pattern %s must bind each field of variant %s to a name",
+	TypeVariantArity:       "Variant %s has %d field(s), but pattern %s binds %d",
+	TypeTryOperand:         "The ? operator needs a Result, got %s",
+	TypeTryOutsideResult:   "The ? operator can only be used in a function that returns a Result",
+	TypeTryErrorMismatch:   "Function '%s' returns errors of type %s, but ? passes on errors of type %s",
+	TypeOptionNotUnwrapped: "%s may be none; unwrap it with match or unwrapOr before using it",
+	TypeNotIndexable:       "Cannot index a value of type %s",
+	TypeIndexType:          "Index of %s must be %s, got %s",
+	TypeNegativeIndex:      "Index %d is negative",
+	TypeIndexOutOfRange:    "Index %d is out of range for length %d",
+	TypeInvertedSlice:      "Slice bounds %d:%d are inverted",
+	TypeUnknownMethod:      "Type %s has no method '%s'",
+	TypePushTarget:         "push must be called on a variable as a statement, as in items.push(x)",
+	TypeRangeStep:          "The step of range must not be 0",
+	TypeIndexedIteration:   "Cannot iterate over %s with an index",
+	TypeAssignImmutable:    "Cannot change immutable variable '%s'",
+	TypeHintMutable:        "declare it with `let mut %s` to allow changing it",
+	TypeAssignUndeclared:   "Cannot assign to undeclared variable '%s'",
+	TypeTypeArgumentCount:  "Function %s takes %d type argument(s), but %d were given in %s",
+	TypeSpawnExit:          "'%s' cannot be used in a spawn block, which does not run as part of its function",
+	TypeSpawnCapture:       "Cannot change '%s' in a spawn block, which has its own copy of the variable",
+	TypeNotChannel:         "Expected a channel in a when arm, got %s",
+	TypeTryExit:            "'%s' cannot be used in a try block, which runs as a function literal",
+	TypeCannotInfer:        "Cannot infer the type parameter %s of %s from the arguments of %s",
+	TypeHintTypeArguments:  "Write the type arguments out after the name: %s",
+	TypeUnknownTrait:       "Unknown trait %s; the traits are %s",
+	TypeImplTarget:         "Traits can only be implemented for struct types without type parameters declared in the same file, not %s",
+	TypeImplMethod:         "impl %s for %s must declare exactly fn %s",
+	TypeHintImplTrait:      "Implement the trait %s for %s to give it %s: impl %[1]s for %[2]s { ... }",
+	TypeConstValue:         "The value of const %s must be a constant: a literal, another const or operators applied to them",
+	TypeInitCycle:          "The initialization of %s depends on itself: %s",
+	TypeNamespaceValue:     "%s names the module %s and can only be used to call its functions, as in %[1]s.name()",
+	TypeHintDeclare:        "did you mean `let %s = %s`?",
+	TypeMissingReturn:      "Function '%s' can end without returning a value of type %s",
+	TypeHintReturnElse:     "add an `else` branch that returns a value",
+	TypeHintReturnLoop:     "the loop can end; add a `return` after it",
+	TypeHintReturnWildcard: "add a `_` arm that returns a value",
+	TypeHintReturnEnd:      "add a `return` before this closing brace",
+	TypeDivisionByZero:     "Division by zero in %s: the divisor is always 0",
+	TypeConstantOverflow:   "The constant expression %s overflows int, which holds %d to %d",
+	TypeHintConvertSized:   "convert %[2]s with %[1]s(%[2]s), which returns a Result that fails if the value does not fit",
+	TypeUnknownType:        "Unknown type %s; declare it with type %s = { ... } or import it",
+
+	LintPublicFunctionName:  "Public function '%s' should be in UpperCamelCase (e.g., MyFunction).",
+	LintPrivateFunctionName: "Private function '%s' should be in lowerCamelCase (e.g., myFunction).",
+	LintVariableName:        "Variable '%s' should be in lowerCamelCase (e.g., myVariable).",
+	LintUnusedVariable:      "Variable '%s' is declared but not used.",
+	LintUnusedImport:        "Imported symbol '%s' from module '%s' is not used.",
+	LintUnusedFunction:      "Function '%s' is defined but not used.",
+	LintMissingImport:       "Function '%s' is not imported; it is exported by '%s'.",
+	LintDeprecatedUsage:     "Function '%s' is deprecated.",
+	LintDeprecatedUsageHint: "Function '%s' is deprecated: %s",
+	LintMatchNotExhaustive:  "match is not exhaustive; add a `_` arm for the other values",
+	LintMatchMissingCases:   "match is not exhaustive; missing %s",
+	LintUnreachableCode:     "Unreachable code: the `%s` at line %d always leaves the block.",
+	LintMissingReturn:       "Function '%s' can end without returning a value.",
+	LintConstantCondition:   "This condition is always %t.",
+	LintInfiniteWhile:       "This while loop never ends: its condition is always true and it has no break. Use `loop` for a loop meant to run forever.",
+	LintSelfComparison:      "'%s' is compared with itself, which is always %t.",
+	LintFunctionComplexity:  "Function '%s' has a cyclomatic complexity of %d, more than %d.",
+	LintFunctionLength:      "Function '%s' is %d lines long, more than %d.",
+	LintFunctionParameters:  "Function '%s' takes %d parameters, more than %d.",
+	LintFixRemoveImport:     "remove `%s` from the import",
+	LintFixRename:           "rename `%s` to `%s`",
+	LintFixMarkUnused:       "rename it to `%s` to mark it as unused",
+}
@@ -0,0 +1,178 @@
+package i18n
+
+var japanese = map[MessageID]string{
+	LabelError:           "エラー",
+	LabelWarning:         "警告",
+	LabelInfo:            "情報",
+	LabelNote:            "注記",
+	LabelHelp:            "ヒント",
+	LabelLocation:        "%d 行目, %d 列目",
+	LabelExpectedGot:     "%s が必要ですが、%s が見つかりました",
+	LabelGenerationError: "生成エラー: %s",
+	LabelTypeError:       "型エラー: %s",
+
+	ParserExpectedNextToken:        "次のトークンは %s であるべきですが、%s が見つかりました",
+	ParserHintCloseParen:           "関数呼び出しまたは式を閉じる ')' を追加してください",
+	ParserHintCloseBlock:           "ブロックを閉じる '}' を追加してください",
+	ParserInvalidInteger:           "%q を整数として解析できません",
+	ParserInvalidFloat:             "%q を浮動小数点数として解析できません",
+	ParserNoPrefixParseFn:          "%s から始まる式は解析できません",
+	ParserHintUnexpectedRParen:     "予期しない ')' です - 'println()' のような空の関数呼び出しを確認してください",
+	ParserHintUnexpectedEOF:        "予期しないファイル終端です - 式が不完全でないか確認してください",
+	ParserImportSeparator:          "import 文では ',' または '}' が必要ですが、%s が見つかりました",
+	ParserPubWithoutFn:             "pub は関数定義とインポートにのみ使用できます",
+	ParserExpectedParamName:        "パラメータ名が必要です",
+	ParserVariadicNotLast:          "可変長パラメータは最後のパラメータでなければなりません",
+	ParserHintVariadicNotLast:      "可変長パラメータを末尾に移動してください",
+	ParserArrayFirstNotPrimitive:   "配列の要素型がプリミティブ型 (int, float, string, bool) ではありません。最初の要素は %s です",
+	ParserArrayElementNotPrimitive: "配列の要素型がプリミティブ型 (int, float, string, bool) ではありません。インデックス %[2]d の要素は %[1]s です (期待される型: %[3]s)",
+	ParserArrayMismatchedTypes:     "配列リテラルの型が一致しません: %[1]s が必要ですが、インデックス %[3]d に %[2]s があります",
+	ParserInvalidMapKey:            "無効なマップキーの型です: IDENTIFIER または STRING が必要ですが、%T が見つかりました",
+	ParserMapSeparatorEOF:          "マップの値の後には ',' または '}' が必要ですが、ファイルが終了しました",
+	ParserMapSeparator:             "マップの値の後には ',' または '}' が必要ですが、%s が見つかりました",
+	ParserExpectedCloseBlock:       "ブロックを閉じる '}' が必要です",
+	ParserStructNeedsTypeName:      "構造体リテラルには型名が必要です",
+	ParserHintStructTypeName:       "Result{...} のように型名を指定してください",
+	ParserStructFieldName:          "構造体のフィールド名は識別子でなければなりませんが、%s が見つかりました",
+	ParserStructSeparatorEOF:       "構造体フィールドの値の後には ',' または '}' が必要ですが、ファイルが終了しました",
+	ParserStructSeparator:          "構造体フィールドの値の後には ',' または '}' が必要ですが、%s が見つかりました",
+	ParserExpectedCloseStruct:      "構造体リテラルを閉じる '}' が必要です",
+	ParserHintCloseStruct:          "構造体リテラルを閉じる '}' を追加してください",
+	ParserCallOnNonIdentifier:      "識別子以外の式に対する関数呼び出しはサポートされていません",
+	ParserHintCallOnNonIdentifier:  "式の代わりに関数名を使用してください",
+	ParserCallNeedsArgument:        "関数 '%s' には少なくとも1つの引数が必要です",
+	ParserHintCallNeedsArgument:    "println(\"Hello\") のように引数を追加してください",
+	ParserElseSyntax:               "'else' の後には 'if' または '{' が必要ですが、%s が見つかりました",
+	ParserExpectedPropertyName:     "'.' の後にはプロパティ名が必要です",
+	ParserHintExpectedPropertyName: "'.' の後に有効な識別子を続けてください",
+	ParserUnknownAttribute:         "不明な属性 '@%s' です",
+	ParserAttributeTarget:          "'@%s' の後には関数または型の宣言が必要です",
+	ParserOutsideLoop:              "'%s' はループの外では使用できません",
+	ParserEnumVariantName:          "列挙型のバリアント名は識別子でなければなりませんが、%s が見つかりました",
+	ParserIfWithoutElse:            "値として使われる if には else ブロックが必要です",
+	ParserSpawnTarget:              "'spawn' の後にはブロックまたは関数呼び出しが必要ですが、%s が見つかりました",
+	ParserImplMember:               "impl ブロックには関数しか宣言できませんが、%s が見つかりました",
+	ParserImportTypeAlias:          "型 %s は別名でインポートできません。as で名前を変えられるのは関数だけです",
+	ParserPubNamespaceImport:       "pub import * as %s ではモジュール全体を再エクスポートできません。再エクスポートする項目を列挙してください",
+	ParserIntegerOutOfRange:        "整数リテラル %s は %s の範囲 (%d から %d) を超えています",
+	ParserInvalidChar:              "文字リテラル '%s' はちょうど 1 文字でなければなりません。文字列には二重引用符を使ってください",
+	ParserWarnEmptyIfBlock:         "'if' 文のブロックが空です",
+	ParserHintEmptyIfBlock:         "文を削除するか、本体を追加してください",
+	ParserWarnEmptyWhileBody:       "'while' ループの本体が空です",
+	ParserHintEmptyWhileBody:       "本体のないループは一度も実行されないか、終了しません",
+
+	GenUnsupportedStatement:       "サポートされていない文の種類です: %T",
+	GenUnsupportedExpression:      "サポートされていない式の種類です: %T",
+	GenUnsupportedMapKey:          "サポートされていないマップキーの型です: %T",
+	GenUnusedVariable:             "変数 '%s' は宣言されていますが使用されていません",
+	GenUnusedFunction:             "関数 '%s' は定義されていますが使用されていません",
+	GenNotImported:                "関数 '%s' は '%s' からインポートされていません",
+	GenMissingImport:              "関数 '%s' は定義もインポートもされていません",
+	GenHintAddImport:              "`%s` を追加してください",
+	GenModuleReadFailed:           "モジュールファイル '%s' を読み込めません: %v",
+	GenModuleParseErrors:          "モジュール '%s' に構文エラーがあります: %v",
+	GenModuleNotFetched:           "モジュール '%s' が取得されていません。`%s` を実行してください",
+	GenImportCycle:                "インポートが循環しています: %s",
+	GenFormatFailed:               "生成された Go コードが不正です (コンパイラのバグです): %s\n%s",
+	GenFunctionNotExported:        "関数 '%s' はモジュール '%s' からエクスポートされていません",
+	GenTypeNotExported:            "型 '%s' はモジュール '%s' からエクスポートされていません",
+	GenParamNeedsType:             "関数 '%s': パラメータ '%s' には明示的な型が必要です",
+	GenMissingReturnType:          "関数 '%s' は値を返す return 文を含みますが、戻り値の型が指定されていません",
+	GenArgumentCount:              "関数 '%s' は %d 個の引数を受け取りますが、呼び出し %[4]s では %[3]d 個が渡されています",
+	GenArgumentCountAtLeast:       "関数 '%s' は少なくとも %d 個の引数を受け取りますが、呼び出し %[4]s では %[3]d 個が渡されています",
+	GenArgumentType:               "'%[2]s' の第%[1]d引数 (パラメータ '%[3]s') は %[4]s 型ですが、呼び出し %[6]s では %[5]s が渡されています",
+	GenUnknownBuildConstant:       "ビルド定数 '%s' は定義されていません。-D %s=VALUE で指定してください",
+	GenUnknownField:               "型 '%s' にフィールド '%s' はありません",
+	GenMatchArmWithoutValue:       "値として使われる match のアームは式で終わる必要があります",
+	GenIfBranchWithoutValue:       "値として使われる if の分岐は式で終わる必要があります",
+	GenTryPosition:                "? 演算子はここでは使えません。先に値を変数に代入してください",
+	GenWarnImplicitBoolConversion: "条件 '%[2]s' で %[1]s から bool への暗黙の変換が行われています",
+	GenWarnDeprecatedFunction:     "関数 '%s' は非推奨です",
+	GenWarnDeprecatedType:         "型 '%s' は非推奨です",
+
+	TypeLetMismatch:        "変数 '%s' は %s として宣言されていますが、%s で初期化されています",
+	TypeAssignMismatch:     "%[1]s を %[3]s 型の変数 '%[2]s' に代入できません",
+	TypeFieldMismatch:      "'%[2]s' のフィールド '%[1]s' は %[3]s を期待していますが、%[4]s が渡されました",
+	TypeVariantField:       "バリアント '%[2]s' のフィールド %[1]d は %[3]s を期待していますが、%[4]s が渡されました",
+	TypeReturnMismatch:     "関数 '%s' の戻り値の型は %s ですが、%s が返されています",
+	TypeMissingReturnValue: "関数 '%s' は %s 型の値を返す必要があります",
+	TypeInvalidOperands:    "演算子 %s は %s と %s に適用できません",
+	TypeInvalidOperand:     "演算子 %s は %s に適用できません",
+	TypeUndefinedVariable:  "未定義の変数 '%s'",
+	TypeInvalidCondition:   "条件は bool でなければなりません（%s が指定されました）",
+	TypeNotIterable:        "%s は反復処理できません",
+	TypeMatchArmMismatch:   "match のアームの型は %s ですが、それまでのアームの型は %s です",
+	TypeIfBranchMismatch:   "if の分岐の型は %s ですが、それまでの分岐の型は %s です",
+	TypeTupleValue:         "型 %s のタプルは変数に格納できません。let (a, b) = ... で分解してください",
+	TypeTupleArity:         "型 %s のタプルの要素は %d 個ですが、名前が %d 個指定されています",
+	TypeNotTuple:           "%s はタプルではないため let (...) で分解できません",
+	TypeNotStructOrMap:     "%s は構造体でもマップでもないため let {...} で分解できません",
+	TypeNotArray:           "%s は配列ではないため let [...] で分解できません",
+	TypeMatchPattern:       "%[2]s 型のパターン %[1]s は %[3]s 型の値にマッチできません",
+	TypeDuplicatePattern:   "パターン %s は前のアームですでにマッチしています",
+	TypeVariantBinding:     "パターン %s はバリアント %s の各フィールドを名前に束縛しなければなりません",
+	TypeVariantArity:       "バリアント %s のフィールドは %d 個ですが、パターン %s は %d 個を束縛しています",
+	TypeTryOperand:         "? 演算子には Result が必要ですが、%s が渡されました",
+	TypeTryOutsideResult:   "? 演算子は Result を返す関数の中でのみ使えます",
+	TypeTryErrorMismatch:   "関数 '%s' のエラーの型は %s ですが、? は %s のエラーを返そうとしています",
+	TypeOptionNotUnwrapped: "%s は none の可能性があります。match か unwrapOr で値を取り出してから使ってください",
+	TypeNotIndexable:       "%s 型の値にはインデックスを付けられません",
+	TypeIndexType:          "%[1]s のインデックスは %[2]s である必要がありますが、%[3]s が渡されました",
+	TypeNegativeIndex:      "インデックス %d が負の値です",
+	TypeIndexOutOfRange:    "インデックス %d は長さ %d の範囲外です",
+	TypeInvertedSlice:      "スライスの範囲 %d:%d の開始が終了より後ろにあります",
+	TypeUnknownMethod:      "型 %s にメソッド '%s' はありません",
+	TypePushTarget:         "push は items.push(x) のように変数に対して文として呼び出す必要があります",
+	TypeRangeStep:          "range の増分に 0 は指定できません",
+	TypeIndexedIteration:   "%s はインデックス付きで反復処理できません",
+	TypeAssignImmutable:    "イミュータブルな変数 '%s' は変更できません",
+	TypeHintMutable:        "変更できるようにするには `let mut %s` で宣言してください",
+	TypeAssignUndeclared:   "宣言されていない変数 '%s' には代入できません",
+	TypeTypeArgumentCount:  "関数 %s の型引数は %d 個ですが、%d 個が %s で渡されています",
+	TypeSpawnExit:          "spawn ブロックは関数の一部として実行されないため、'%s' は使用できません",
+	TypeSpawnCapture:       "spawn ブロックは変数のコピーを持つため、'%s' は変更できません",
+	TypeNotChannel:         "when の腕にはチャネルが必要ですが、%s が見つかりました",
+	TypeTryExit:            "try ブロックは関数リテラルとして実行されるため、'%s' は使用できません",
+	TypeCannotInfer:        "%[2]s の型パラメータ %[1]s を %[3]s の引数から推論できません",
+	TypeHintTypeArguments:  "関数名の後に型引数を明示してください: %s",
+	TypeUnknownTrait:       "不明なトレイト %s です。トレイトは %s です",
+	TypeImplTarget:         "トレイトを実装できるのは同じファイルで宣言された型パラメータのない構造体型だけですが、%s が指定されました",
+	TypeImplMethod:         "impl %s for %s では fn %s だけを宣言する必要があります",
+	TypeHintImplTrait:      "%[2]s に %[3]s を使えるようにするにはトレイト %[1]s を実装してください: impl %[1]s for %[2]s { ... }",
+	TypeConstValue:         "const %s の値は定数である必要があります: リテラル、他の const、またはそれらに演算子を適用したもの",
+	TypeInitCycle:          "%s の初期化が自身に依存しています: %s",
+	TypeNamespaceValue:     "%s はモジュール %s を表す名前で、%[1]s.name() のように関数の呼び出しにしか使えません",
+	TypeHintDeclare:        "`let %s = %s` のつもりですか?",
+	TypeMissingReturn:      "関数 '%s' は %s 型の値を返さずに終了する可能性があります",
+	TypeHintReturnElse:     "値を返す `else` 分岐を追加してください",
+	TypeHintReturnLoop:     "ループは終了する可能性があります。ループの後に `return` を追加してください",
+	TypeHintReturnWildcard: "値を返す `_` アームを追加してください",
+	TypeHintReturnEnd:      "この閉じ括弧の前に `return` を追加してください",
+	TypeDivisionByZero:     "%s はゼロ除算です: 除数は常に 0 です",
+	TypeConstantOverflow:   "定数式 %s は int の範囲 (%d から %d) を超えています",
+	TypeHintConvertSized:   "%[2]s を %[1]s(%[2]s) で変換してください。値が収まらない場合に失敗する Result を返します",
+	TypeUnknownType:        "不明な型 %s です。type %s = { ... } で宣言するか、インポートしてください",
+
+	LintPublicFunctionName:  "公開関数 '%s' は UpperCamelCase (例: MyFunction) で命名してください。",
+	LintPrivateFunctionName: "非公開関数 '%s' は lowerCamelCase (例: myFunction) で命名してください。",
+	LintVariableName:        "変数 '%s' は lowerCamelCase (例: myVariable) で命名してください。",
+	LintUnusedVariable:      "変数 '%s' は宣言されていますが使用されていません。",
+	LintUnusedImport:        "モジュール '%[2]s' からインポートされたシンボル '%[1]s' は使用されていません。",
+	LintUnusedFunction:      "関数 '%s' は定義されていますが使用されていません。",
+	LintMissingImport:       "関数 '%s' はインポートされていません。'%s' からエクスポートされています。",
+	LintDeprecatedUsage:     "関数 '%s' は非推奨です。",
+	LintDeprecatedUsageHint: "関数 '%s' は非推奨です: %s",
+	LintMatchNotExhaustive:  "match が網羅的ではありません。その他の値のために `_` アームを追加してください",
+	LintMatchMissingCases:   "match が網羅的ではありません。%s がありません",
+	LintUnreachableCode:     "到達不能なコードです: %[2]d 行目の `%[1]s` は常にブロックを抜けます。",
+	LintMissingReturn:       "関数 '%s' は値を返さずに終了する可能性があります。",
+	LintConstantCondition:   "この条件は常に %t です。",
+	LintInfiniteWhile:       "この while ループは終了しません: 条件は常に true で、break もありません。永久に続けるループには `loop` を使ってください。",
+	LintSelfComparison:      "'%s' をそれ自身と比較しています。結果は常に %t です。",
+	LintFunctionComplexity:  "関数 '%s' の循環的複雑度は %d で、上限の %d を超えています。",
+	LintFunctionLength:      "関数 '%s' は %d 行あり、上限の %d 行を超えています。",
+	LintFunctionParameters:  "関数 '%s' は %d 個の引数を取り、上限の %d 個を超えています。",
+	LintFixRemoveImport:     "インポートから `%s` を削除してください",
+	LintFixRename:           "`%s` を `%s` に名前変更してください",
+	LintFixMarkUnused:       "未使用であることを示すため `%s` に名前変更してください",
+}
@@ -0,0 +1,173 @@
+package i18n
+
+import "sort"
+
+// Diagnostic codes are stable identifiers attached to every error and
+// warning so they can be searched for, suppressed and matched in CI
+// independently of the message language. Codes are grouped by range:
+//
+//	Z0001-Z0099  syntax errors (parser)
+//	Z0101-Z0199  semantic errors (generator and checks)
+//	Z0201-Z0299  warnings
+//	Z0301-Z0399  lint issues
+//
+// A code must never be reused for a different problem once released.
+var codes = map[MessageID]string{
+	ParserExpectedNextToken:        "Z0001",
+	ParserNoPrefixParseFn:          "Z0002",
+	ParserInvalidInteger:           "Z0003",
+	ParserInvalidFloat:             "Z0004",
+	ParserImportSeparator:          "Z0005",
+	ParserPubWithoutFn:             "Z0006",
+	ParserExpectedParamName:        "Z0007",
+	ParserVariadicNotLast:          "Z0008",
+	ParserArrayFirstNotPrimitive:   "Z0009",
+	ParserArrayElementNotPrimitive: "Z0010",
+	ParserArrayMismatchedTypes:     "Z0011",
+	ParserInvalidMapKey:            "Z0012",
+	ParserMapSeparatorEOF:          "Z0013",
+	ParserMapSeparator:             "Z0013",
+	ParserExpectedCloseBlock:       "Z0014",
+	ParserStructNeedsTypeName:      "Z0015",
+	ParserStructFieldName:          "Z0016",
+	ParserStructSeparatorEOF:       "Z0017",
+	ParserStructSeparator:          "Z0017",
+	ParserExpectedCloseStruct:      "Z0018",
+	ParserCallOnNonIdentifier:      "Z0019",
+	ParserCallNeedsArgument:        "Z0020",
+	ParserElseSyntax:               "Z0021",
+	ParserExpectedPropertyName:     "Z0022",
+	ParserUnknownAttribute:         "Z0023",
+	ParserAttributeTarget:          "Z0024",
+	ParserOutsideLoop:              "Z0025",
+	ParserEnumVariantName:          "Z0026",
+	ParserIfWithoutElse:            "Z0027",
+	ParserSpawnTarget:              "Z0028",
+	ParserImplMember:               "Z0029",
+	ParserImportTypeAlias:          "Z0030",
+	ParserPubNamespaceImport:       "Z0031",
+	ParserIntegerOutOfRange:        "Z0032",
+	ParserInvalidChar:              "Z0033",
+
+	GenUnsupportedStatement:  "Z0101",
+	GenUnsupportedExpression: "Z0102",
+	GenUnsupportedMapKey:     "Z0103",
+	GenUnusedVariable:        "Z0104",
+	GenUnusedFunction:        "Z0105",
+	GenNotImported:           "Z0106",
+	GenMissingImport:         "Z0106",
+	GenModuleReadFailed:      "Z0107",
+	GenModuleParseErrors:     "Z0108",
+	GenFunctionNotExported:   "Z0109",
+	GenTypeNotExported:       "Z0110",
+	GenParamNeedsType:        "Z0111",
+	GenMissingReturnType:     "Z0112",
+	GenArgumentCount:         "Z0113",
+	GenArgumentCountAtLeast:  "Z0113",
+	GenArgumentType:          "Z0114",
+	GenUnknownBuildConstant:  "Z0115",
+	GenUnknownField:          "Z0116",
+	TypeLetMismatch:          "Z0117",
+	TypeAssignMismatch:       "Z0117",
+	TypeFieldMismatch:        "Z0117",
+	TypeVariantField:         "Z0117",
+	TypeReturnMismatch:       "Z0118",
+	TypeMissingReturnValue:   "Z0118",
+	TypeInvalidOperands:      "Z0119",
+	TypeInvalidOperand:       "Z0119",
+	TypeUndefinedVariable:    "Z0120",
+	TypeInvalidCondition:     "Z0121",
+	TypeNotIterable:          "Z0122",
+	GenMatchArmWithoutValue:  "Z0123",
+	GenIfBranchWithoutValue:  "Z0123",
+	TypeMatchArmMismatch:     "Z0117",
+	TypeIfBranchMismatch:     "Z0117",
+	TypeTupleValue:           "Z0138",
+	TypeTupleArity:           "Z0138",
+	TypeNotTuple:             "Z0138",
+	TypeNotStructOrMap:       "Z0138",
+	TypeNotArray:             "Z0138",
+	TypeMatchPattern:         "Z0124",
+	TypeDuplicatePattern:     "Z0125",
+	TypeVariantBinding:       "Z0126",
+	TypeVariantArity:         "Z0126",
+	TypeTryOperand:           "Z0127",
+	TypeTryOutsideResult:     "Z0128",
+	TypeTryErrorMismatch:     "Z0128",
+	GenTryPosition:           "Z0129",
+	TypeOptionNotUnwrapped:   "Z0130",
+	TypeNotIndexable:         "Z0131",
+	TypeIndexType:            "Z0132",
+	TypeNegativeIndex:        "Z0133",
+	TypeIndexOutOfRange:      "Z0133",
+	TypeInvertedSlice:        "Z0133",
+	TypeUnknownMethod:        "Z0134",
+	TypePushTarget:           "Z0135",
+	TypeRangeStep:            "Z0136",
+	TypeIndexedIteration:     "Z0122",
+	TypeAssignImmutable:      "Z0137",
+	TypeAssignUndeclared:     "Z0139",
+	TypeTypeArgumentCount:    "Z0140",
+	TypeSpawnExit:            "Z0141",
+	TypeSpawnCapture:         "Z0142",
+	TypeNotChannel:           "Z0143",
+	TypeTryExit:              "Z0144",
+	TypeCannotInfer:          "Z0145",
+	TypeUnknownTrait:         "Z0146",
+	TypeImplTarget:           "Z0147",
+	TypeImplMethod:           "Z0148",
+	TypeConstValue:           "Z0149",
+	TypeInitCycle:            "Z0150",
+	TypeNamespaceValue:       "Z0151",
+	GenModuleNotFetched:      "Z0152",
+	GenImportCycle:           "Z0153",
+	GenFormatFailed:          "Z0154",
+	TypeMissingReturn:        "Z0155",
+	TypeDivisionByZero:       "Z0156",
+	TypeConstantOverflow:     "Z0157",
+	TypeUnknownType:          "Z0158",
+
+	ParserWarnEmptyIfBlock:        "Z0201",
+	ParserWarnEmptyWhileBody:      "Z0202",
+	GenWarnImplicitBoolConversion: "Z0203",
+	GenWarnDeprecatedFunction:     "Z0204",
+	GenWarnDeprecatedType:         "Z0204",
+
+	LintPublicFunctionName:  "Z0301",
+	LintPrivateFunctionName: "Z0302",
+	LintVariableName:        "Z0303",
+	LintUnusedVariable:      "Z0304",
+	LintUnusedImport:        "Z0305",
+	LintUnusedFunction:      "Z0306",
+	LintMissingImport:       "Z0307",
+	LintDeprecatedUsage:     "Z0308",
+	LintDeprecatedUsageHint: "Z0308",
+	LintMatchNotExhaustive:  "Z0309",
+	LintMatchMissingCases:   "Z0309",
+	LintUnreachableCode:     "Z0310",
+	LintMissingReturn:       "Z0311",
+	LintConstantCondition:   "Z0312",
+	LintInfiniteWhile:       "Z0313",
+	LintSelfComparison:      "Z0314",
+	LintFunctionComplexity:  "Z0315",
+	LintFunctionLength:      "Z0316",
+	LintFunctionParameters:  "Z0317",
+}
+
+// Code returns the diagnostic code for a message, or "" for messages that
+// are not diagnostics on their own (labels and hints).
+func Code(id MessageID) string { return codes[id] }
+
+// Codes returns every assigned diagnostic code in sorted order
+func Codes() []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, code := range codes {
+		if !seen[code] {
+			seen[code] = true
+			result = append(result, code)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
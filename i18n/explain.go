@@ -0,0 +1,691 @@
+package i18n
+
+import "strings"
+
+// Explanation is the long-form documentation for a diagnostic code,
+// printed by `zeno explain`.
+type Explanation struct {
+	Code        string
+	Title       string
+	Description string
+	Example     string // code that triggers the diagnostic
+	Fix         string // the corrected code or how to resolve it
+}
+
+// Explain returns the explanation for a diagnostic code. Codes are matched
+// case-insensitively, so "z0203" and "Z0203" are equivalent.
+func Explain(code string) (Explanation, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	e, ok := explanations[code]
+	e.Code = code
+	return e, ok
+}
+
+var explanations = map[string]Explanation{
+	"Z0001": {
+		Title:       "unexpected token",
+		Description: "The parser expected a specific token (such as ')' or '}') but found something else. This usually means a delimiter is missing or an expression is incomplete.",
+		Example:     "fn main() {\n    println(\"hi\"\n}",
+		Fix:         "fn main() {\n    println(\"hi\")\n}",
+	},
+	"Z0002": {
+		Title:       "token cannot start an expression",
+		Description: "An expression was expected, but the token found cannot begin one. Common causes are a stray ')' or an expression cut off at the end of the file.",
+		Example:     "let x = )",
+		Fix:         "let x = 1",
+	},
+	"Z0003": {
+		Title:       "invalid integer literal",
+		Description: "The integer literal could not be parsed, usually because it does not fit in a 64-bit integer.",
+		Example:     "let n = 99999999999999999999",
+		Fix:         "let n = 9999999999",
+	},
+	"Z0004": {
+		Title:       "invalid float literal",
+		Description: "The floating point literal could not be parsed.",
+		Example:     "let f = 1.2.3",
+		Fix:         "let f = 1.23",
+	},
+	"Z0005": {
+		Title:       "malformed import list",
+		Description: "Names in an import list must be separated by ',' and the list closed with '}'.",
+		Example:     "import { println print } from \"std/fmt\"",
+		Fix:         "import { println, print } from \"std/fmt\"",
+	},
+	"Z0006": {
+		Title:       "'pub' without a function",
+		Description: "The 'pub' modifier exports a declaration from a module and may only precede a function definition, or an import whose items the module re-exports.",
+		Example:     "pub let x = 1",
+		Fix:         "pub fn value(): int {\n    return 1\n}",
+	},
+	"Z0007": {
+		Title:       "missing parameter name",
+		Description: "A function parameter list contains an entry without a name.",
+		Example:     "fn add(: int, b: int): int {\n    return b\n}",
+		Fix:         "fn add(a: int, b: int): int {\n    return a + b\n}",
+	},
+	"Z0008": {
+		Title:       "variadic parameter is not last",
+		Description: "A variadic parameter collects all remaining arguments, so no parameter may follow it.",
+		Example:     "fn log(...parts: string, level: int) {\n}",
+		Fix:         "fn log(level: int, ...parts: string) {\n}",
+	},
+	"Z0009": {
+		Title:       "non-primitive array element",
+		Description: "Array literals may only contain primitive values (int, float, string, bool).",
+		Example:     "let xs = [[1], [2]]",
+		Fix:         "let xs = [1, 2]",
+	},
+	"Z0010": {
+		Title:       "non-primitive array element",
+		Description: "An element after the first in an array literal is not a primitive value (int, float, string, bool).",
+		Example:     "let xs = [1, [2]]",
+		Fix:         "let xs = [1, 2]",
+	},
+	"Z0011": {
+		Title:       "mixed element types in array literal",
+		Description: "All elements of an array literal must have the same type as the first element.",
+		Example:     "let xs = [1, \"two\", 3]",
+		Fix:         "let xs = [1, 2, 3]",
+	},
+	"Z0012": {
+		Title:       "invalid map key",
+		Description: "Map literal keys must be identifiers or string literals.",
+		Example:     "let m = {1: \"one\"}",
+		Fix:         "let m = {\"one\": 1}",
+	},
+	"Z0013": {
+		Title:       "malformed map literal",
+		Description: "Entries in a map literal must be separated by ',' and the literal closed with '}'.",
+		Example:     "let m = {\"a\": 1 \"b\": 2}",
+		Fix:         "let m = {\"a\": 1, \"b\": 2}",
+	},
+	"Z0014": {
+		Title:       "unclosed block",
+		Description: "A block opened with '{' reached the end of the file or an unexpected token before its closing '}'.",
+		Example:     "fn main() {\n    println(\"hi\")",
+		Fix:         "fn main() {\n    println(\"hi\")\n}",
+	},
+	"Z0015": {
+		Title:       "struct literal without a type name",
+		Description: "A struct literal must name the type it constructs.",
+		Example:     "let p = getPoint(){x: 1}",
+		Fix:         "let p = Point{x: 1, y: 2}",
+	},
+	"Z0016": {
+		Title:       "invalid struct field name",
+		Description: "Field names in a struct literal must be identifiers.",
+		Example:     "let p = Point{\"x\": 1}",
+		Fix:         "let p = Point{x: 1}",
+	},
+	"Z0017": {
+		Title:       "malformed struct literal",
+		Description: "Fields in a struct literal must be separated by ',' and the literal closed with '}'.",
+		Example:     "let p = Point{x: 1 y: 2}",
+		Fix:         "let p = Point{x: 1, y: 2}",
+	},
+	"Z0018": {
+		Title:       "unclosed struct literal",
+		Description: "A struct literal is missing its closing '}'.",
+		Example:     "let p = Point{x: 1, y: 2",
+		Fix:         "let p = Point{x: 1, y: 2}",
+	},
+	"Z0019": {
+		Title:       "call on a non-identifier",
+		Description: "Only named functions can be called. Calling the result of an arbitrary expression is not supported.",
+		Example:     "(getHandler())(1)",
+		Fix:         "let handler = getHandler()\nhandler(1)",
+	},
+	"Z0020": {
+		Title:       "call requires an argument",
+		Description: "Some built-in functions, such as println, need at least one argument.",
+		Example:     "println()",
+		Fix:         "println(\"\")",
+	},
+	"Z0021": {
+		Title:       "malformed else branch",
+		Description: "'else' must be followed by a block or by another 'if'.",
+		Example:     "if x > 0 {\n    println(\"pos\")\n} else println(\"neg\")",
+		Fix:         "if x > 0 {\n    println(\"pos\")\n} else {\n    println(\"neg\")\n}",
+	},
+	"Z0022": {
+		Title:       "missing property name",
+		Description: "A '.' must be followed by the name of a field or member.",
+		Example:     "let n = point.",
+		Fix:         "let n = point.x",
+	},
+	"Z0023": {
+		Title:       "unknown attribute",
+		Description: "Only the @deprecated attribute is supported.",
+		Example:     "@inline\nfn add(a: int, b: int): int {\n    return a + b\n}",
+		Fix:         "fn add(a: int, b: int): int {\n    return a + b\n}",
+	},
+	"Z0024": {
+		Title:       "attribute without a declaration",
+		Description: "An attribute applies to the function or type declaration that follows it.",
+		Example:     "@deprecated\nlet x = 1",
+		Fix:         "@deprecated\nfn oldValue(): int {\n    return 1\n}",
+	},
+	"Z0025": {
+		Title:       "break or continue outside of a loop",
+		Description: "'break' and 'continue' can only be used inside the body of a loop, while or for statement.",
+		Example:     "fn main() {\n    break\n}",
+		Fix:         "fn main() {\n    loop {\n        break\n    }\n}",
+	},
+	"Z0026": {
+		Title:       "invalid enum variant",
+		Description: "The variants of an enum are names, optionally followed by the types of their fields in parentheses.",
+		Example:     "enum Shape {\n    \"circle\"\n}",
+		Fix:         "enum Shape {\n    Circle(float),\n    Empty,\n}",
+	},
+	"Z0027": {
+		Title:       "if value without else",
+		Description: "An if used as a value, e.g. on the right of let, must have an else block so that it gives a value whichever branch is taken.",
+		Example:     "let sign = if n < 0 {\n    -1\n}",
+		Fix:         "let sign = if n < 0 {\n    -1\n} else {\n    1\n}",
+	},
+	"Z0028": {
+		Title:       "invalid spawn",
+		Description: "spawn runs a block, or a single function call, concurrently with the code after it. It must be followed by one of them.",
+		Example:     "spawn 1 + 2",
+		Fix:         "spawn work(1)\nspawn {\n    work(2)\n}\nwait()",
+	},
+	"Z0029": {
+		Title:       "invalid impl block",
+		Description: "An impl block implements a trait for a type with functions, and can only contain function declarations.",
+		Example:     "impl Add for Money {\n    let zero = 0\n}",
+		Fix:         "impl Add for Money {\n    fn add(a: Money, b: Money): Money {\n        return Money{cents: a.cents + b.cents}\n    }\n}",
+	},
+	"Z0030": {
+		Title:       "renamed type import",
+		Description: "as gives an imported function another name, so that functions of the same name from different modules can both be imported. Types keep the name they are declared with, since values of them are shared with the module.",
+		Example:     "import { type Point as Coord } from \"./geometry\"",
+		Fix:         "import { type Point } from \"./geometry\"",
+	},
+	"Z0031": {
+		Title:       "re-exported namespace import",
+		Description: "pub import re-exports the items it lists, so that the modules importing this one can import them from it. A module imported with import * as cannot be re-exported: the items must be listed.",
+		Example:     "pub import * as text from \"./internal/text\"",
+		Fix:         "pub import { shout, whisper } from \"./internal/text\"",
+	},
+	"Z0032": {
+		Title:       "integer literal out of range",
+		Description: "int is a 64-bit integer and holds the values from -9223372036854775808 to 9223372036854775807. A literal outside of this range cannot be stored in it; use a u64 literal such as 10000000000000000000u64 for larger positive values, or a float for approximate ones. A literal with a suffix, like 300byte, must fit in the type of its suffix, and a literal without one in the sized type it takes where it is used, as in let b: byte = 300.",
+		Example:     "let big = 10000000000000000000",
+		Fix:         "let big = 10000000000000000000u64",
+	},
+	"Z0033": {
+		Title:       "invalid character literal",
+		Description: "A character literal, written in single quotes, holds exactly one Unicode character of type char, possibly written with an escape sequence such as '\\n' or '\\u00e9'. Text of any other length is a string, written in double quotes.",
+		Example:     "let greeting = 'hi'",
+		Fix:         "let greeting = \"hi\"",
+	},
+
+	"Z0101": {
+		Title:       "unsupported statement",
+		Description: "The code generator does not know how to translate this kind of statement to Go. This is a compiler limitation; please report it with the offending code.",
+	},
+	"Z0102": {
+		Title:       "unsupported expression",
+		Description: "The code generator does not know how to translate this kind of expression to Go. This is a compiler limitation; please report it with the offending code.",
+	},
+	"Z0103": {
+		Title:       "unsupported map key",
+		Description: "A map literal contains a key the code generator cannot translate. Use string or identifier keys.",
+		Example:     "let m = {1.5: \"x\"}",
+		Fix:         "let m = {\"1.5\": \"x\"}",
+	},
+	"Z0104": {
+		Title:       "unused variable",
+		Description: "A variable is declared but never read. Go rejects unused local variables, so Zeno reports them before generating code. With --allow-unused they are reported as warnings instead, and names starting with _ are never reported.",
+		Example:     "fn main() {\n    let x = 1\n}",
+		Fix:         "fn main() {\n    let x = 1\n    println(x)\n}",
+	},
+	"Z0105": {
+		Title:       "unused function",
+		Description: "A private function is defined but never called. Remove it, call it, or export it with 'pub'. With --allow-unused it is reported as a warning instead, and names starting with _ are never reported.",
+		Example:     "fn helper() {\n}\n\nfn main() {\n}",
+		Fix:         "fn helper() {\n}\n\nfn main() {\n    helper()\n}",
+	},
+	"Z0106": {
+		Title:       "function not imported",
+		Description: "A function from a standard library or user module is called without being imported. The diagnostic suggests the import statement to add; `zeno lint --fix` can insert it for you.",
+		Example:     "fn main() {\n    let text = readFile(\"notes.txt\")\n}",
+		Fix:         "import { readFile } from \"std/io\"\n\nfn main() {\n    let text = readFile(\"notes.txt\")\n}",
+	},
+	"Z0107": {
+		Title:       "module not found",
+		Description: "The file for an imported module could not be read. Check the path in the import statement; relative paths are resolved against the importing file.",
+		Example:     "import { add } from \"./mth\"",
+		Fix:         "import { add } from \"./math\"",
+	},
+	"Z0108": {
+		Title:       "syntax errors in imported module",
+		Description: "An imported module failed to parse. Fix the errors reported for that module first.",
+	},
+	"Z0109": {
+		Title:       "function not exported",
+		Description: "An imported function exists in the module but is not marked 'pub'.",
+		Example:     "// math.zeno\nfn add(a: int, b: int): int {\n    return a + b\n}",
+		Fix:         "// math.zeno\npub fn add(a: int, b: int): int {\n    return a + b\n}",
+	},
+	"Z0110": {
+		Title:       "type not exported",
+		Description: "An imported type is not declared by the module. Check the spelling of the type name and the module path.",
+		Example:     "import { Reslt } from \"std/result\"",
+		Fix:         "import { Result } from \"std/result\"",
+	},
+	"Z0111": {
+		Title:       "parameter without a type",
+		Description: "Every function parameter needs an explicit type annotation.",
+		Example:     "fn double(x) : int {\n    return x * 2\n}",
+		Fix:         "fn double(x: int): int {\n    return x * 2\n}",
+	},
+	"Z0112": {
+		Title:       "missing return type",
+		Description: "A function returns a value but does not declare a return type.",
+		Example:     "fn answer() {\n    return 42\n}",
+		Fix:         "fn answer(): int {\n    return 42\n}",
+	},
+	"Z0113": {
+		Title:       "wrong number of arguments",
+		Description: "A call passes a different number of arguments than the function declares. Variadic functions require at least the fixed parameters.",
+		Example:     "fn add(a: int, b: int): int {\n    return a + b\n}\n\nlet x = add(1)",
+		Fix:         "let x = add(1, 2)",
+	},
+	"Z0114": {
+		Title:       "argument type mismatch",
+		Description: "An argument's type does not match the declared parameter type. Integer literals are accepted where a float is expected.",
+		Example:     "fn greet(name: string) {\n}\n\ngreet(42)",
+		Fix:         "greet(\"42\")",
+	},
+	"Z0115": {
+		Title:       "undefined build constant",
+		Description: "build.NAME refers to a constant given on the command line with -D NAME=VALUE, and this one was not given.",
+		Example:     "// zeno build app.zeno\nprintln(build.VERSION)",
+		Fix:         "// zeno build -D VERSION=1.2.3 app.zeno\nprintln(build.VERSION)",
+	},
+	"Z0116": {
+		Title:       "unknown struct field",
+		Description: "A struct literal or field access names a field that the type declaration does not have.",
+		Example:     "type Point = {\n    x: int\n    y: int\n}\nlet p = Point{x: 1, z: 2}",
+		Fix:         "type Point = {\n    x: int\n    y: int\n}\nlet p = Point{x: 1, y: 2}",
+	},
+	"Z0117": {
+		Title:       "type mismatch",
+		Description: "A value is stored where a different type is expected: a let with a type annotation, an assignment to an existing variable, or a struct field. Integer literals are accepted where a float is expected.",
+		Example:     "let count: int = \"three\"",
+		Fix:         "let count: int = 3",
+	},
+	"Z0118": {
+		Title:       "return type mismatch",
+		Description: "A return statement gives a value of a different type than the function declares, or no value at all in a function with a return type.",
+		Example:     "fn name(): string {\n    return 42\n}",
+		Fix:         "fn name(): string {\n    return \"42\"\n}",
+	},
+	"Z0119": {
+		Title:       "invalid operands",
+		Description: "An operator is applied to values it does not support. Arithmetic needs numbers (+ also joins two strings), % needs ints, && and || need bools, and comparisons need values of the same type.",
+		Example:     "let total = \"items: \" + 3",
+		Fix:         "let total = \"items: \" + str(3)",
+	},
+	"Z0120": {
+		Title:       "undefined variable",
+		Description: "An identifier does not name a variable, parameter or function in scope. Variables declared at the top level are not visible inside functions.",
+		Example:     "fn show() {\n    println(message)\n}",
+		Fix:         "fn show(message: string) {\n    println(message)\n}",
+	},
+	"Z0121": {
+		Title:       "invalid condition",
+		Description: "The condition of an if or while has a type that cannot be used as a truth value, such as an array or a struct.",
+		Example:     "if [1, 2] {\n}",
+		Fix:         "if len([1, 2]) > 0 {\n}",
+	},
+	"Z0122": {
+		Title:       "value is not iterable",
+		Description: "A for-in loop iterates over a value that is not an array or an iterator. A loop with an index, for i, v in values, needs an array.",
+		Example:     "for c in 42 {\n}",
+		Fix:         "for c in [4, 2] {\n}",
+	},
+	"Z0123": {
+		Title:       "match arm or if branch without a value",
+		Description: "When a match or an if is used as a value, each block gives the value of its last statement, which must therefore be an expression.",
+		Example:     "let name = match n {\n    1 => \"one\",\n    _ => {\n        let s = str(n)\n    }\n}",
+		Fix:         "let name = match n {\n    1 => \"one\",\n    _ => {\n        let s = str(n)\n        s\n    }\n}",
+	},
+	"Z0124": {
+		Title:       "pattern type mismatch",
+		Description: "A match pattern has a type that the matched value can never have.",
+		Example:     "let n = 1\nmatch n {\n    \"one\" => println(1),\n    _ => println(0),\n}",
+		Fix:         "let n = 1\nmatch n {\n    1 => println(1),\n    _ => println(0),\n}",
+	},
+	"Z0125": {
+		Title:       "duplicate pattern",
+		Description: "Two arms of a match have the same pattern. Arms are tried in order, so the later one is never taken.",
+		Example:     "match n {\n    1 => println(\"one\"),\n    1 => println(\"uno\"),\n}",
+		Fix:         "match n {\n    1 => println(\"one\"),\n    2 => println(\"two\"),\n}",
+	},
+	"Z0126": {
+		Title:       "invalid variant pattern",
+		Description: "A pattern for an enum variant with fields binds each field, in order, to a name that can be used in the arm. `_` ignores a field.",
+		Example:     "match shape {\n    Rect(w) => w,\n    _ => 0.0,\n}",
+		Fix:         "match shape {\n    Rect(w, _) => w,\n    _ => 0.0,\n}",
+	},
+	"Z0127": {
+		Title:       "? applied to a value that is not a Result",
+		Description: "The ? operator unwraps a Result: it gives the value of an ok result and returns an error result from the enclosing function. Other values cannot be unwrapped.",
+		Example:     "fn next(n: int): Result<int> {\n    return ok(n? + 1)\n}",
+		Fix:         "fn next(n: int): Result<int> {\n    return ok(n + 1)\n}",
+	},
+	"Z0128": {
+		Title:       "? outside a function returning a Result",
+		Description: "The ? operator returns the error of a Result from the enclosing function, so that function must return a Result with the same error type.",
+		Example:     "fn main() {\n    let n = int(\"42\")?\n}",
+		Fix:         "fn parse(s: string): Result<int> {\n    let n = int(s)?\n    return ok(n * 2)\n}",
+	},
+	"Z0129": {
+		Title:       "? in an unsupported position",
+		Description: "The error check of a ? operator is written before the statement that uses its value. This is not possible inside match arms, after && and ||, or in the conditions of else-if and while, which are not always evaluated.",
+		Example:     "while next()? {\n}",
+		Fix:         "let more = next()?\nwhile more {\n    more = next()?\n}",
+	},
+	"Z0130": {
+		Title:       "Option used without unwrapping",
+		Description: "An Option<T> holds either some value or none, so it cannot be used as a T. Take the value out with a match on some(x) and none, or with unwrapOr(option, default).",
+		Example:     "let port: Option<int> = some(8080)\nprintln(port + 1)",
+		Fix:         "let port: Option<int> = some(8080)\nprintln(unwrapOr(port, 80) + 1)",
+	},
+	"Z0131": {
+		Title:       "value cannot be indexed",
+		Description: "Only arrays, strings and maps can be indexed with [] or sliced with [low:high].",
+		Example:     "let n = 42\nprintln(n[0])",
+		Fix:         "let digits = str(42)\nprintln(digits[0])",
+	},
+	"Z0132": {
+		Title:       "index of the wrong type",
+		Description: "Arrays and strings are indexed and sliced with ints, maps with string keys.",
+		Example:     "let items = [1, 2, 3]\nprintln(items[\"0\"])",
+		Fix:         "let items = [1, 2, 3]\nprintln(items[0])",
+	},
+	"Z0133": {
+		Title:       "index out of range",
+		Description: "A constant index is negative or past the end of an array or string literal, or the bounds of a slice are inverted. Indexes start at 0 and slices take the elements from low up to, but not including, high.",
+		Example:     "let items = [1, 2, 3]\nprintln(items[-1], items[2:1])",
+		Fix:         "let items = [1, 2, 3]\nprintln(items[len(items) - 1], items[1:2])",
+	},
+	"Z0134": {
+		Title:       "unknown method",
+		Description: "The method is not defined for the type of the value it is called on. Strings have length, toUpper, toLower, trim, contains, startsWith, endsWith, split, replace and indexOf; arrays have length, push, contains, join, map and filter.",
+		Example:     "let name = \"zeno\"\nprintln(name.upper())",
+		Fix:         "let name = \"zeno\"\nprintln(name.toUpper())",
+	},
+	"Z0135": {
+		Title:       "invalid push",
+		Description: "push appends to the array stored in a variable, so it must be called on a variable and cannot be used as a value.",
+		Example:     "let items = [1, 2]\nlet more = items.push(3)",
+		Fix:         "let items = [1, 2]\nitems.push(3)",
+	},
+	"Z0136": {
+		Title:       "range step of zero",
+		Description: "range(start, end, step) counts from start by step until it reaches end. A step of 0 never reaches the end; use a negative step to count down.",
+		Example:     "for i in range(10, 0, 0) {\n    println(i)\n}",
+		Fix:         "for i in range(10, 0, -1) {\n    println(i)\n}",
+	},
+	"Z0137": {
+		Title:       "immutable variable changed",
+		Description: "Variables declared with let cannot be reassigned or pushed to. Declare the variable with let mut to allow changing it. Function parameters and loop variables are immutable.",
+		Example:     "let count = 0\ncount = count + 1",
+		Fix:         "let mut count = 0\ncount = count + 1",
+	},
+	"Z0138": {
+		Title:       "invalid destructuring or use of a tuple",
+		Description: "Tuples are returned from functions as several values and must be taken apart with let (a, b) = ..., with one name per element. They cannot be stored in a single variable. Likewise let {name, age} = ... takes apart only a struct or map, and let [first, second] = ... only an array.",
+		Example:     "fn divide(a: int, b: int): (int, int) {\n    return (a / b, a % b)\n}\nlet result = divide(7, 2)",
+		Fix:         "fn divide(a: int, b: int): (int, int) {\n    return (a / b, a % b)\n}\nlet (quotient, remainder) = divide(7, 2)",
+	},
+	"Z0139": {
+		Title:       "assignment to an undeclared variable",
+		Description: "An assignment changes a variable that already exists. A new variable is declared with let, or let mut if it is changed later.",
+		Example:     "total = 0",
+		Fix:         "let mut total = 0",
+	},
+	"Z0140": {
+		Title:       "wrong number of type arguments",
+		Description: "Type arguments written after the name of a function, as in decode<User>(text), fix its type parameters in order. They must be as many as the parameters declared with the function, and functions without type parameters take none.",
+		Example:     "fn first<T>(items: []T): T {\n    return items[0]\n}\nlet x = first<int, string>([1, 2])",
+		Fix:         "fn first<T>(items: []T): T {\n    return items[0]\n}\nlet x = first<int>([1, 2])",
+	},
+	"Z0141": {
+		Title:       "return or ? in a spawn block",
+		Description: "A spawn block runs on its own, after the function that spawned it may have returned, so it cannot return from that function: neither return nor the ? operator can be used in it. Handle errors inside the block instead.",
+		Example:     "fn load(path: string): Result<string> {\n    spawn {\n        let text = readText(path)?\n    }\n    return ok(\"\")\n}",
+		Fix:         "fn load(path: string): Result<string> {\n    spawn {\n        match readText(path) {\n            ok(text) => println(text),\n            err(e) => println(e),\n        }\n    }\n    return ok(\"\")\n}",
+	},
+	"Z0142": {
+		Title:       "change of a variable captured by spawn",
+		Description: "A spawn block gets a copy of the variables of the code around it, taken when the spawn runs, so changes it made to them would be lost. Declare the variable inside the block, or send the result through a value shared by reference such as a map.",
+		Example:     "let mut total = 0\nspawn {\n    total = total + 1\n}",
+		Fix:         "let total = 0\nspawn {\n    let next = total + 1\n    println(next)\n}",
+	},
+	"Z0143": {
+		Title:       "when arm without a channel",
+		Description: "Each arm of a when receives from a channel, as in `msg from inbox` or `inbox`, or sends to one, as in `send(outbox, value)`, except the arm _ that runs when no other can. The value the arm names must be a Channel created with chan.",
+		Example:     "let count = 1\nwhen {\n    n from count => println(n),\n}",
+		Fix:         "let numbers = chan<int>(1)\nsend(numbers, 1)\nwhen {\n    n from numbers => println(n),\n}",
+	},
+	"Z0144": {
+		Title:       "return or ? in a try block",
+		Description: "A try block runs as a function literal, so that a panic in it can be recovered, which means it cannot return from the function around it: neither return nor the ? operator can be used in it. Assign the result to a variable declared before the try and return after it, or return from the catch block, which runs as part of the function.",
+		Example:     "fn parse(s: string): int {\n    try {\n        return toInt(s).value\n    } catch {\n        return 0\n    }\n}",
+		Fix:         "fn parse(s: string): int {\n    let mut n = 0\n    try {\n        n = toInt(s).value\n    } catch {\n        return 0\n    }\n    return n\n}",
+	},
+	"Z0145": {
+		Title:       "type parameter cannot be inferred",
+		Description: "When a generic function is called without type arguments, its type parameters are inferred from the types of the arguments, e.g. T is int in first([1, 2]) for fn first<T>(items: []T): T. A type parameter that none of the arguments stands for, such as one only the return type is written with, cannot be inferred: write the type arguments out after the name of the function.",
+		Example:     "fn empty<T>(): []T {\n    let items: []T = []\n    return items\n}\nlet names = empty()",
+		Fix:         "fn empty<T>(): []T {\n    let items: []T = []\n    return items\n}\nlet names = empty<string>()",
+	},
+	"Z0146": {
+		Title:       "unknown trait",
+		Description: "impl implements one of the traits that give a type operators: Add for +, Eq for == and !=, and Ord for <, <=, > and >=. Other traits cannot be declared.",
+		Example:     "impl Sub for Money {\n    fn sub(a: Money, b: Money): Money {\n        return Money{cents: a.cents - b.cents}\n    }\n}",
+		Fix:         "impl Add for Money {\n    fn add(a: Money, b: Money): Money {\n        return Money{cents: a.cents + b.cents}\n    }\n}",
+	},
+	"Z0147": {
+		Title:       "trait implemented for a type that cannot have one",
+		Description: "The functions of an impl are compiled to Go methods of the struct the type is compiled to, so traits can only be implemented for struct types declared in the same file, and without type parameters.",
+		Example:     "impl Add for int {\n    fn add(a: int, b: int): int {\n        return a - b\n    }\n}",
+		Fix:         "type Money = {\n    cents: int\n}\n\nimpl Add for Money {\n    fn add(a: Money, b: Money): Money {\n        return Money{cents: a.cents + b.cents}\n    }\n}",
+	},
+	"Z0148": {
+		Title:       "wrong functions in an impl",
+		Description: "An impl declares exactly the function its trait requires, which takes two values of the type: add returns their sum as a value of the type, eq whether they are equal, and compare an int that is negative, zero or positive as the first is less than, equal to or greater than the second.",
+		Example:     "impl Ord for Money {\n    fn less(a: Money, b: Money): bool {\n        return a.cents < b.cents\n    }\n}",
+		Fix:         "impl Ord for Money {\n    fn compare(a: Money, b: Money): int {\n        return a.cents - b.cents\n    }\n}",
+	},
+	"Z0149": {
+		Title:       "const with a value that is not a constant",
+		Description: "A const is compiled to a Go constant, whose value is known when the program is compiled: an int, float, string or bool literal, another const, or operators applied to them. Declare a value that is computed when the program runs with let.",
+		Example:     "const size = len(\"hello\")",
+		Fix:         "let size = len(\"hello\")",
+	},
+	"Z0150": {
+		Title:       "initialization cycle",
+		Description: "Module-level variables are initialized before main runs, each after the variables and functions its value refers to. A variable whose value refers, directly or through the functions it calls, to the variable itself cannot be initialized. Compute the value in a function, or pass what the function needs as an argument.",
+		Example:     "let total = sum()\n\nfn sum(): int {\n    return total + 1\n}\n\nfn main() {\n    println(total)\n}",
+		Fix:         "let base = 1\nlet total = sum(base)\n\nfn sum(n: int): int {\n    return n + 1\n}\n\nfn main() {\n    println(total)\n}",
+	},
+	"Z0151": {
+		Title:       "module used as a value",
+		Description: "import * as io from \"std/io\" gives the name io to the module, so that its functions are called as io.readFile(path). The name is not a value: it cannot be assigned, passed or printed.",
+		Example:     "import * as io from \"std/io\"\n\nlet files = io",
+		Fix:         "import * as io from \"std/io\"\n\nlet text = io.readFile(\"notes.txt\")",
+	},
+	"Z0152": {
+		Title:       "remote module not fetched",
+		Description: "A module imported from a repository, such as github.com/user/pkg/text, is read from the module cache at the version pinned in zeno.lock. zeno get <repository> fetches the highest version of a repository and pins it; zeno get without arguments fetches the versions already pinned, for example after cloning a project.",
+		Example:     "import { shout } from \"github.com/user/pkg/text\"",
+		Fix:         "// after running: zeno get github.com/user/pkg\nimport { shout } from \"github.com/user/pkg/text\"",
+	},
+	"Z0153": {
+		Title:       "import cycle",
+		Description: "Each module is compiled to a Go package, and Go packages cannot import each other: a module cannot import, directly or through other modules, the module importing it. Move what both modules need into a third module that both import.",
+		Example:     "// a.zeno\nimport { b } from \"./b\"\n\n// b.zeno\nimport { a } from \"./a\"",
+		Fix:         "// a.zeno\nimport { shared } from \"./common\"\n\n// b.zeno\nimport { shared } from \"./common\"",
+	},
+	"Z0154": {
+		Title:       "invalid generated code",
+		Description: "The generated Go code is formatted with gofmt before it is written, which fails only if the code is not valid Go. This is a bug in the compiler, not in the program: the message ends with the generated code, with line numbers, to include in a bug report. Rewriting the statement the Go error points to usually works around it.",
+	},
+	"Z0155": {
+		Title:       "missing return",
+		Description: "A function with a return type must return a value on every path through its body, but the end of the function can be reached: through an if without an else, a while or for loop, which can end, a loop with a break, a match without a `_` arm, or a branch that does not end with a return. The error points to where the path leaves; return a value there, or after the loop.",
+		Example:     "fn sign(n: int): int {\n    if n > 0 {\n        return 1\n    } else if n < 0 {\n        return -1\n    }\n}",
+		Fix:         "fn sign(n: int): int {\n    if n > 0 {\n        return 1\n    } else if n < 0 {\n        return -1\n    }\n    return 0\n}",
+	},
+	"Z0156": {
+		Title:       "division by zero",
+		Description: "The divisor of / or % is a constant that is 0, so the division would panic, or give an infinity for floats, every time it runs. Go rejects it at compile time too. Check the divisor, or divide by a variable when the value is only known at run time.",
+		Example:     "let half = total / 0",
+		Fix:         "let half = total / 2",
+	},
+	"Z0157": {
+		Title:       "constant overflow",
+		Description: "An expression made only of integer literals is computed at compile time, and its value does not fit in an int, which holds the values from -9223372036854775808 to 9223372036854775807. At run time, the overflow of an int wraps around silently, unless the program is built with --check-overflow, which makes it panic.",
+		Example:     "let limit = 9223372036854775807 + 1",
+		Fix:         "let limit = 9223372036854775807",
+	},
+	"Z0158": {
+		Title:       "unknown type",
+		Description: "A struct literal such as Point{x: 1, y: 2} names a type that is neither declared with type in the file nor imported, often because of a misspelling or a missing import { type Point }. Maps are written without a type name: {x: 1, y: 2}.",
+		Example:     "type Point = {\n    x: int\n    y: int\n}\n\nlet p = Pont{x: 1, y: 2}",
+		Fix:         "type Point = {\n    x: int\n    y: int\n}\n\nlet p = Point{x: 1, y: 2}",
+	},
+
+	"Z0201": {
+		Title:       "empty if block",
+		Description: "The body of an 'if' statement is empty, so the condition has no effect.",
+		Example:     "if ready {\n}",
+		Fix:         "if ready {\n    start()\n}",
+	},
+	"Z0202": {
+		Title:       "empty while body",
+		Description: "A 'while' loop without a body either never runs or spins forever.",
+		Example:     "while running {\n}",
+		Fix:         "while running {\n    running = step()\n}",
+	},
+	"Z0203": {
+		Title:       "implicit conversion to bool",
+		Description: "A non-boolean value is used as a condition. Zeno converts it implicitly, which hides mistakes such as testing a count instead of comparing it.",
+		Example:     "let count = 3\nif count {\n    println(\"some\")\n}",
+		Fix:         "let count = 3\nif count > 0 {\n    println(\"some\")\n}",
+	},
+
+	"Z0204": {
+		Title:       "use of a deprecated declaration",
+		Description: "A function or type marked with @deprecated is used. The declaration still works but is scheduled for removal; the text given to @deprecated usually names the replacement.",
+		Example:     "@deprecated(\"use readText instead\")\npub fn readFile(path: string): string {\n    return readText(path)\n}\n\nlet s = readFile(\"a.txt\")",
+		Fix:         "let s = readText(\"a.txt\")",
+	},
+
+	"Z0301": {
+		Title:       "public function naming",
+		Description: "Public functions are named in UpperCamelCase.",
+		Example:     "pub fn parse_config() {\n}",
+		Fix:         "pub fn ParseConfig() {\n}",
+	},
+	"Z0302": {
+		Title:       "private function naming",
+		Description: "Private functions are named in lowerCamelCase.",
+		Example:     "fn Parse_config() {\n}",
+		Fix:         "fn parseConfig() {\n}",
+	},
+	"Z0303": {
+		Title:       "variable naming",
+		Description: "Variables are named in lowerCamelCase.",
+		Example:     "let user_name = \"zeno\"",
+		Fix:         "let userName = \"zeno\"",
+	},
+	"Z0304": {
+		Title:       "unused variable",
+		Description: "A variable is declared but never read.",
+		Example:     "let total = 0",
+		Fix:         "Remove the declaration or use the variable.",
+	},
+	"Z0305": {
+		Title:       "unused import",
+		Description: "A symbol is imported but never used.",
+		Example:     "import { println, print } from \"std/fmt\"\n\nfn main() {\n    println(\"hi\")\n}",
+		Fix:         "import { println } from \"std/fmt\"\n\nfn main() {\n    println(\"hi\")\n}",
+	},
+	"Z0306": {
+		Title:       "unused function",
+		Description: "A function is defined but never called.",
+		Example:     "fn helper() {\n}",
+		Fix:         "Remove the function or call it.",
+	},
+	"Z0307": {
+		Title:       "missing import",
+		Description: "A function exported by a standard library module is called without being imported. `zeno lint --fix` inserts the suggested import.",
+		Example:     "fn main() {\n    writeFile(\"out.txt\", \"done\")\n}",
+		Fix:         "import { writeFile } from \"std/io\"\n\nfn main() {\n    writeFile(\"out.txt\", \"done\")\n}",
+	},
+	"Z0308": {
+		Title:       "deprecated function call",
+		Description: "The no-deprecated lint rule forbids calls to functions marked @deprecated, so that code can be migrated before the function is removed.",
+		Example:     "@deprecated(\"use sum instead\")\nfn add(a: int, b: int): int {\n    return a + b\n}\n\nlet x = add(1, 2)",
+		Fix:         "let x = sum(1, 2)",
+	},
+	"Z0309": {
+		Title:       "non-exhaustive match",
+		Description: "The match-exhaustive lint rule reports matches that do not handle every value: a match on a bool without both true and false, or any other match without a `_` arm. A match used as a value panics when no arm matches.",
+		Example:     "let label = match done {\n    true => \"done\",\n}",
+		Fix:         "let label = match done {\n    true => \"done\",\n    false => \"pending\",\n}",
+	},
+	"Z0310": {
+		Title:       "unreachable code",
+		Description: "The unreachable-code lint rule reports the first statement of a block that can never run: it follows a return, break or continue, or an if whose branches, including an else, all end with one. The statements after it are unreachable too; remove them or move them before the statement leaving the block.",
+		Example:     "fn total(n: int): int {\n    return n * 2\n    println(\"done\")\n}",
+		Fix:         "fn total(n: int): int {\n    println(\"done\")\n    return n * 2\n}",
+	},
+	"Z0311": {
+		Title:       "missing return",
+		Description: "The missing-return lint rule reports the functions with a return type that can end without returning a value, like the compiler does with Z0155, for zeno lint, which does not type check.",
+		Example:     "fn sign(n: int): int {\n    if n > 0 {\n        return 1\n    }\n}",
+		Fix:         "fn sign(n: int): int {\n    if n > 0 {\n        return 1\n    }\n    return 0\n}",
+	},
+	"Z0312": {
+		Title:       "constant condition",
+		Description: "The condition of an if, else if or while is made of literals only, like `1 == 1` or `false && ready`, so it is always true or always false and one of the branches never runs. This is usually left over from debugging. Constants declared with const are not reported, since turning code on and off with them is intended.",
+		Example:     "if 1 == 1 {\n    println(\"always\")\n}",
+		Fix:         "println(\"always\")",
+	},
+	"Z0313": {
+		Title:       "endless while loop",
+		Description: "The condition of a while loop is always true and its body has no break, so the loop only ends with a return or when the program stops. `loop` says that a loop runs forever; if the loop should end, add a break or a condition that changes.",
+		Example:     "while true {\n    serve()\n}",
+		Fix:         "loop {\n    serve()\n}",
+	},
+	"Z0314": {
+		Title:       "comparison with itself",
+		Description: "A variable is compared with itself, which is always true for ==, <= and >=, and always false for !=, < and >. The comparison was most likely meant to use another variable on one side.",
+		Example:     "if count == count {\n    println(\"equal\")\n}",
+		Fix:         "if count == limit {\n    println(\"equal\")\n}",
+	},
+	"Z0315": {
+		Title:       "function too complex",
+		Description: "The cyclomatic complexity of a function, the number of paths through it, is over the limit of the function-complexity rule, 15 by default. It is one plus a count of each if, else if, loop, match or when arm other than _, catch, ? and the operators && and ||. Functions with many paths are hard to follow and to test; move parts of them to functions of their own. The limit is set with max in the [rules.function-complexity] table of .zenolint.toml.",
+		Example:     "[rules.function-complexity]\nmax = 10",
+	},
+	"Z0316": {
+		Title:       "function too long",
+		Description: "The body of a function takes more lines than the limit of the function-length rule, 80 by default, counting blank lines and comments. Move parts of it to functions of their own. The limit is set with max in the [rules.function-length] table of .zenolint.toml.",
+		Example:     "[rules.function-length]\nmax = 50",
+	},
+	"Z0317": {
+		Title:       "too many parameters",
+		Description: "A function takes more parameters than the limit of the function-parameters rule, 5 by default. Calls to it are hard to read and easy to get wrong, since the arguments are not named; pass a struct holding the values instead. The limit is set with max in the [rules.function-parameters] table of .zenolint.toml.",
+		Example:     "fn draw(x: int, y: int, width: int, height: int, color: string, filled: bool) {\n}",
+		Fix:         "type Rect = {\n    x: int\n    y: int\n    width: int\n    height: int\n}\n\nfn draw(rect: Rect, color: string, filled: bool) {\n}",
+	},
+}
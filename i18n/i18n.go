@@ -0,0 +1,94 @@
+// Package i18n provides the message catalog used for compiler and linter
+// diagnostics. Every user-facing message has a MessageID; the text for the
+// active language is looked up with T, falling back to English when a
+// translation is missing.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// MessageID identifies a translatable message
+type MessageID string
+
+// DefaultLanguage is used when no language is selected or a translation is missing
+const DefaultLanguage = "en"
+
+// catalogs maps a language code to its message templates. Templates use
+// fmt verbs and receive the arguments passed to T.
+var catalogs = map[string]map[MessageID]string{
+	"en": english,
+	"ja": japanese,
+}
+
+var current = DefaultLanguage
+
+// SetLanguage selects the language used by T. Region suffixes and encodings
+// are ignored, so "ja_JP.UTF-8" selects "ja".
+func SetLanguage(lang string) error {
+	code := normalize(lang)
+	if _, ok := catalogs[code]; !ok {
+		return fmt.Errorf("unsupported language %q (available: %s)", lang, strings.Join(Languages(), ", "))
+	}
+	current = code
+	return nil
+}
+
+// Language returns the active language code
+func Language() string { return current }
+
+// Languages returns the available language codes in sorted order
+func Languages() []string {
+	var langs []string
+	for code := range catalogs {
+		langs = append(langs, code)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// DetectLanguage returns the language requested by the environment:
+// ZENO_LANG first, then the system locale (LC_ALL, LC_MESSAGES, LANG).
+// Locales without a catalog resolve to DefaultLanguage.
+func DetectLanguage() string {
+	for _, env := range []string{"ZENO_LANG", "LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(env)
+		if value == "" {
+			continue
+		}
+		if code := normalize(value); catalogs[code] != nil {
+			return code
+		}
+		if env == "ZENO_LANG" {
+			// An explicit but unknown choice should not silently fall through to the locale.
+			return DefaultLanguage
+		}
+	}
+	return DefaultLanguage
+}
+
+// T returns the message for id in the active language, formatted with args.
+func T(id MessageID, args ...interface{}) string {
+	template, ok := catalogs[current][id]
+	if !ok {
+		template, ok = english[id]
+	}
+	if !ok {
+		return string(id)
+	}
+	if len(args) == 0 {
+		return template
+	}
+	return fmt.Sprintf(template, args...)
+}
+
+func normalize(lang string) string {
+	code := strings.ToLower(lang)
+	if i := strings.IndexAny(code, "_-.@"); i >= 0 {
+		code = code[:i]
+	}
+	return code
+}
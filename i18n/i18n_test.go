@@ -0,0 +1,110 @@
+package i18n
+
+import (
+	"regexp"
+	"testing"
+)
+
+var verbPattern = regexp.MustCompile(`%(\[\d+\])?[a-zA-Z]`)
+
+func TestCatalogsAreComplete(t *testing.T) {
+	for lang, catalog := range catalogs {
+		for id, template := range english {
+			translated, ok := catalog[id]
+			if !ok {
+				t.Errorf("%s: missing translation for %s", lang, id)
+				continue
+			}
+			if got, want := len(verbPattern.FindAllString(translated, -1)), len(verbPattern.FindAllString(template, -1)); got != want {
+				t.Errorf("%s: %s uses %d format verbs, English uses %d", lang, id, got, want)
+			}
+		}
+		for id := range catalog {
+			if _, ok := english[id]; !ok {
+				t.Errorf("%s: %s has no English message", lang, id)
+			}
+		}
+	}
+}
+
+func TestSetLanguage(t *testing.T) {
+	defer SetLanguage(DefaultLanguage)
+
+	if err := SetLanguage("ja_JP.UTF-8"); err != nil {
+		t.Fatalf("SetLanguage(ja_JP.UTF-8) failed: %v", err)
+	}
+	if Language() != "ja" {
+		t.Errorf("expected language ja, got %s", Language())
+	}
+	if got := T(GenUnusedVariable, "x"); got != "変数 'x' は宣言されていますが使用されていません" {
+		t.Errorf("unexpected Japanese message: %q", got)
+	}
+
+	if err := SetLanguage("fr"); err == nil {
+		t.Error("expected error for unsupported language")
+	}
+	if Language() != "ja" {
+		t.Errorf("failed SetLanguage should keep the previous language, got %s", Language())
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	t.Setenv("ZENO_LANG", "")
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "ja_JP.UTF-8")
+	if got := DetectLanguage(); got != "ja" {
+		t.Errorf("expected ja from LANG, got %s", got)
+	}
+
+	t.Setenv("ZENO_LANG", "en")
+	if got := DetectLanguage(); got != "en" {
+		t.Errorf("ZENO_LANG should take precedence over LANG, got %s", got)
+	}
+}
+
+func TestMissingMessageFallsBackToID(t *testing.T) {
+	if got := T(MessageID("no.such.message")); got != "no.such.message" {
+		t.Errorf("expected message ID as fallback, got %q", got)
+	}
+}
+
+func TestEveryCodeIsExplained(t *testing.T) {
+	pattern := regexp.MustCompile(`^Z\d{4}$`)
+	for id, code := range codes {
+		if !pattern.MatchString(code) {
+			t.Errorf("message %s has malformed code %q", id, code)
+		}
+		if _, ok := english[id]; !ok {
+			t.Errorf("code %s is assigned to unknown message %s", code, id)
+		}
+	}
+	for _, code := range Codes() {
+		e, ok := Explain(code)
+		if !ok || e.Title == "" || e.Description == "" {
+			t.Errorf("code %s has no explanation", code)
+		}
+	}
+	for code := range explanations {
+		found := false
+		for _, c := range codes {
+			found = found || c == code
+		}
+		if !found {
+			t.Errorf("explanation for %s does not match any diagnostic", code)
+		}
+	}
+}
+
+func TestExplainIsCaseInsensitive(t *testing.T) {
+	e, ok := Explain("z0203")
+	if !ok {
+		t.Fatal("expected explanation for z0203")
+	}
+	if e.Code != "Z0203" {
+		t.Errorf("expected normalized code Z0203, got %s", e.Code)
+	}
+	if _, ok := Explain("Z9999"); ok {
+		t.Error("expected no explanation for unknown code")
+	}
+}
@@ -0,0 +1,191 @@
+package i18n
+
+// Diagnostic labels
+const (
+	LabelError           MessageID = "label.error"
+	LabelWarning         MessageID = "label.warning"
+	LabelInfo            MessageID = "label.info"
+	LabelNote            MessageID = "label.note"
+	LabelHelp            MessageID = "label.help"
+	LabelLocation        MessageID = "label.location"
+	LabelExpectedGot     MessageID = "label.expected_got"
+	LabelGenerationError MessageID = "label.generation_error"
+	LabelTypeError       MessageID = "label.type_error"
+)
+
+// Parser messages
+const (
+	ParserExpectedNextToken        MessageID = "parser.expected_next_token"
+	ParserHintCloseParen           MessageID = "parser.hint.close_paren"
+	ParserHintCloseBlock           MessageID = "parser.hint.close_block"
+	ParserInvalidInteger           MessageID = "parser.invalid_integer"
+	ParserInvalidFloat             MessageID = "parser.invalid_float"
+	ParserNoPrefixParseFn          MessageID = "parser.no_prefix_parse_fn"
+	ParserHintUnexpectedRParen     MessageID = "parser.hint.unexpected_rparen"
+	ParserHintUnexpectedEOF        MessageID = "parser.hint.unexpected_eof"
+	ParserImportSeparator          MessageID = "parser.import_separator"
+	ParserPubWithoutFn             MessageID = "parser.pub_without_fn"
+	ParserExpectedParamName        MessageID = "parser.expected_param_name"
+	ParserVariadicNotLast          MessageID = "parser.variadic_not_last"
+	ParserHintVariadicNotLast      MessageID = "parser.hint.variadic_not_last"
+	ParserArrayFirstNotPrimitive   MessageID = "parser.array_first_not_primitive"
+	ParserArrayElementNotPrimitive MessageID = "parser.array_element_not_primitive"
+	ParserArrayMismatchedTypes     MessageID = "parser.array_mismatched_types"
+	ParserInvalidMapKey            MessageID = "parser.invalid_map_key"
+	ParserMapSeparatorEOF          MessageID = "parser.map_separator_eof"
+	ParserMapSeparator             MessageID = "parser.map_separator"
+	ParserExpectedCloseBlock       MessageID = "parser.expected_close_block"
+	ParserStructNeedsTypeName      MessageID = "parser.struct_needs_type_name"
+	ParserHintStructTypeName       MessageID = "parser.hint.struct_type_name"
+	ParserStructFieldName          MessageID = "parser.struct_field_name"
+	ParserStructSeparatorEOF       MessageID = "parser.struct_separator_eof"
+	ParserStructSeparator          MessageID = "parser.struct_separator"
+	ParserExpectedCloseStruct      MessageID = "parser.expected_close_struct"
+	ParserHintCloseStruct          MessageID = "parser.hint.close_struct"
+	ParserCallOnNonIdentifier      MessageID = "parser.call_on_non_identifier"
+	ParserHintCallOnNonIdentifier  MessageID = "parser.hint.call_on_non_identifier"
+	ParserCallNeedsArgument        MessageID = "parser.call_needs_argument"
+	ParserHintCallNeedsArgument    MessageID = "parser.hint.call_needs_argument"
+	ParserElseSyntax               MessageID = "parser.else_syntax"
+	ParserExpectedPropertyName     MessageID = "parser.expected_property_name"
+	ParserHintExpectedPropertyName MessageID = "parser.hint.expected_property_name"
+	ParserUnknownAttribute         MessageID = "parser.unknown_attribute"
+	ParserAttributeTarget          MessageID = "parser.attribute_target"
+	ParserOutsideLoop              MessageID = "parser.outside_loop"
+	ParserEnumVariantName          MessageID = "parser.enum_variant_name"
+	ParserIfWithoutElse            MessageID = "parser.if_without_else"
+	ParserSpawnTarget              MessageID = "parser.spawn_target"
+	ParserImplMember               MessageID = "parser.impl_member"
+	ParserImportTypeAlias          MessageID = "parser.import_type_alias"
+	ParserPubNamespaceImport       MessageID = "parser.pub_namespace_import"
+	ParserIntegerOutOfRange        MessageID = "parser.integer_out_of_range"
+	ParserInvalidChar              MessageID = "parser.invalid_char"
+	ParserWarnEmptyIfBlock         MessageID = "parser.warn.empty_if_block"
+	ParserHintEmptyIfBlock         MessageID = "parser.hint.empty_if_block"
+	ParserWarnEmptyWhileBody       MessageID = "parser.warn.empty_while_body"
+	ParserHintEmptyWhileBody       MessageID = "parser.hint.empty_while_body"
+)
+
+// Generator and checker messages
+const (
+	GenUnsupportedStatement       MessageID = "gen.unsupported_statement"
+	GenUnsupportedExpression      MessageID = "gen.unsupported_expression"
+	GenUnsupportedMapKey          MessageID = "gen.unsupported_map_key"
+	GenUnusedVariable             MessageID = "gen.unused_variable"
+	GenUnusedFunction             MessageID = "gen.unused_function"
+	GenNotImported                MessageID = "gen.not_imported"
+	GenMissingImport              MessageID = "gen.missing_import"
+	GenHintAddImport              MessageID = "gen.hint.add_import"
+	GenModuleReadFailed           MessageID = "gen.module_read_failed"
+	GenModuleParseErrors          MessageID = "gen.module_parse_errors"
+	GenModuleNotFetched           MessageID = "gen.module_not_fetched"
+	GenImportCycle                MessageID = "gen.import_cycle"
+	GenFormatFailed               MessageID = "gen.format_failed"
+	GenFunctionNotExported        MessageID = "gen.function_not_exported"
+	GenTypeNotExported            MessageID = "gen.type_not_exported"
+	GenParamNeedsType             MessageID = "gen.param_needs_type"
+	GenMissingReturnType          MessageID = "gen.missing_return_type"
+	GenArgumentCount              MessageID = "gen.argument_count"
+	GenArgumentCountAtLeast       MessageID = "gen.argument_count_at_least"
+	GenArgumentType               MessageID = "gen.argument_type"
+	GenUnknownBuildConstant       MessageID = "gen.unknown_build_constant"
+	GenUnknownField               MessageID = "gen.unknown_field"
+	GenMatchArmWithoutValue       MessageID = "gen.match_arm_without_value"
+	GenIfBranchWithoutValue       MessageID = "gen.if_branch_without_value"
+	GenTryPosition                MessageID = "gen.try_position"
+	GenWarnImplicitBoolConversion MessageID = "gen.warn.implicit_bool_conversion"
+	GenWarnDeprecatedFunction     MessageID = "gen.warn.deprecated_function"
+	GenWarnDeprecatedType         MessageID = "gen.warn.deprecated_type"
+)
+
+// Type checker messages
+const (
+	TypeLetMismatch        MessageID = "type.let_mismatch"
+	TypeAssignMismatch     MessageID = "type.assign_mismatch"
+	TypeFieldMismatch      MessageID = "type.field_mismatch"
+	TypeVariantField       MessageID = "type.variant_field"
+	TypeReturnMismatch     MessageID = "type.return_mismatch"
+	TypeMissingReturnValue MessageID = "type.missing_return_value"
+	TypeInvalidOperands    MessageID = "type.invalid_operands"
+	TypeInvalidOperand     MessageID = "type.invalid_operand"
+	TypeUndefinedVariable  MessageID = "type.undefined_variable"
+	TypeInvalidCondition   MessageID = "type.invalid_condition"
+	TypeNotIterable        MessageID = "type.not_iterable"
+	TypeMatchArmMismatch   MessageID = "type.match_arm_mismatch"
+	TypeIfBranchMismatch   MessageID = "type.if_branch_mismatch"
+	TypeTupleValue         MessageID = "type.tuple_value"
+	TypeTupleArity         MessageID = "type.tuple_arity"
+	TypeNotTuple           MessageID = "type.not_tuple"
+	TypeNotStructOrMap     MessageID = "type.not_struct_or_map"
+	TypeNotArray           MessageID = "type.not_array"
+	TypeMatchPattern       MessageID = "type.match_pattern"
+	TypeDuplicatePattern   MessageID = "type.duplicate_pattern"
+	TypeVariantBinding     MessageID = "type.variant_binding"
+	TypeVariantArity       MessageID = "type.variant_arity"
+	TypeTryOperand         MessageID = "type.try_operand"
+	TypeTryOutsideResult   MessageID = "type.try_outside_result"
+	TypeTryErrorMismatch   MessageID = "type.try_error_mismatch"
+	TypeOptionNotUnwrapped MessageID = "type.option_not_unwrapped"
+	TypeNotIndexable       MessageID = "type.not_indexable"
+	TypeIndexType          MessageID = "type.index_type"
+	TypeNegativeIndex      MessageID = "type.negative_index"
+	TypeIndexOutOfRange    MessageID = "type.index_out_of_range"
+	TypeInvertedSlice      MessageID = "type.inverted_slice"
+	TypeUnknownMethod      MessageID = "type.unknown_method"
+	TypePushTarget         MessageID = "type.push_target"
+	TypeRangeStep          MessageID = "type.range_step"
+	TypeIndexedIteration   MessageID = "type.indexed_iteration"
+	TypeAssignImmutable    MessageID = "type.assign_immutable"
+	TypeHintMutable        MessageID = "type.hint_mutable"
+	TypeAssignUndeclared   MessageID = "type.assign_undeclared"
+	TypeTypeArgumentCount  MessageID = "type.type_argument_count"
+	TypeSpawnExit          MessageID = "type.spawn_exit"
+	TypeSpawnCapture       MessageID = "type.spawn_capture"
+	TypeNotChannel         MessageID = "type.not_channel"
+	TypeTryExit            MessageID = "type.try_exit"
+	TypeCannotInfer        MessageID = "type.cannot_infer"
+	TypeHintTypeArguments  MessageID = "type.hint_type_arguments"
+	TypeUnknownTrait       MessageID = "type.unknown_trait"
+	TypeImplTarget         MessageID = "type.impl_target"
+	TypeImplMethod         MessageID = "type.impl_method"
+	TypeHintImplTrait      MessageID = "type.hint_impl_trait"
+	TypeConstValue         MessageID = "type.const_value"
+	TypeInitCycle          MessageID = "type.init_cycle"
+	TypeNamespaceValue     MessageID = "type.namespace_value"
+	TypeHintDeclare        MessageID = "type.hint_declare"
+	TypeMissingReturn      MessageID = "type.missing_return"
+	TypeHintReturnElse     MessageID = "type.hint_return_else"
+	TypeHintReturnLoop     MessageID = "type.hint_return_loop"
+	TypeHintReturnWildcard MessageID = "type.hint_return_wildcard"
+	TypeHintReturnEnd      MessageID = "type.hint_return_end"
+	TypeDivisionByZero     MessageID = "type.division_by_zero"
+	TypeConstantOverflow   MessageID = "type.constant_overflow"
+	TypeHintConvertSized   MessageID = "type.hint_convert_sized"
+	TypeUnknownType        MessageID = "type.unknown_type"
+)
+
+// Linter messages
+const (
+	LintPublicFunctionName  MessageID = "lint.public_function_name"
+	LintPrivateFunctionName MessageID = "lint.private_function_name"
+	LintVariableName        MessageID = "lint.variable_name"
+	LintUnusedVariable      MessageID = "lint.unused_variable"
+	LintUnusedImport        MessageID = "lint.unused_import"
+	LintUnusedFunction      MessageID = "lint.unused_function"
+	LintMissingImport       MessageID = "lint.missing_import"
+	LintDeprecatedUsage     MessageID = "lint.deprecated_usage"
+	LintDeprecatedUsageHint MessageID = "lint.deprecated_usage_hint"
+	LintMatchNotExhaustive  MessageID = "lint.match_not_exhaustive"
+	LintMatchMissingCases   MessageID = "lint.match_missing_cases"
+	LintUnreachableCode     MessageID = "lint.unreachable_code"
+	LintMissingReturn       MessageID = "lint.missing_return"
+	LintConstantCondition   MessageID = "lint.constant_condition"
+	LintInfiniteWhile       MessageID = "lint.infinite_while"
+	LintSelfComparison      MessageID = "lint.self_comparison"
+	LintFunctionComplexity  MessageID = "lint.function_complexity"
+	LintFunctionLength      MessageID = "lint.function_length"
+	LintFunctionParameters  MessageID = "lint.function_parameters"
+	LintFixRemoveImport     MessageID = "lint.fix.remove_import"
+	LintFixRename           MessageID = "lint.fix.rename"
+	LintFixMarkUnused       MessageID = "lint.fix.mark_unused"
+)
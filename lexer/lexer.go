@@ -4,51 +4,104 @@ import (
 	"strconv" // Added: for strconv.ParseInt
 	"strings" // Added: for strings.Builder and strings.Contains (though Contains might not be used anymore)
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/linkalls/zeno-lang/token"
 )
 
-// Lexer represents the lexical analyzer
+// Lexer represents the lexical analyzer. It reads its input, UTF-8 text,
+// a character at a time, so identifiers may be written in any script.
 type Lexer struct {
 	input        string
 	position     int  // current position in input (points to current char)
 	readPosition int  // current reading position in input (after current char)
-	ch           byte // current char under examination
+	ch           rune // current char under examination
+	line         int  // line of ch, starting at 1
+	column       int  // column of ch in characters, starting at 1
+	keepComments bool // return comments as COMMENT tokens instead of skipping them
+	// endsStatement is set after a token that can end a statement, for the
+	// newline following it to end the statement
+	endsStatement bool
+	// brackets are the (, [ and { open at the current character, the
+	// innermost last
+	brackets []rune
 }
 
 // New creates a new instance of Lexer
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{input: input, line: 1}
 	l.readChar()
 	return l
 }
 
-// readChar gives us the next character and advances our position in the input string
+// NewWithComments creates a Lexer that returns comments as COMMENT tokens,
+// for tools such as the formatter that need to reproduce them
+func NewWithComments(input string) *Lexer {
+	l := New(input)
+	l.keepComments = true
+	return l
+}
+
+// readChar gives us the next character and advances our position in the
+// input string. A byte that is not valid UTF-8 is read as utf8.RuneError.
 func (l *Lexer) readChar() {
+	if l.ch == '\n' && l.readPosition > 0 {
+		l.line++
+		l.column = 0
+	}
+	l.column++
+	size := 0
 	if l.readPosition >= len(l.input) {
 		l.ch = 0 // ASCII NUL character signifies "EOF"
 	} else {
-		l.ch = l.input[l.readPosition]
+		l.ch, size = utf8.DecodeRuneInString(l.input[l.readPosition:])
 	}
 	l.position = l.readPosition
-	l.readPosition++
+	l.readPosition += max(size, 1)
 }
 
 // peekChar returns the next character without advancing our position
-func (l *Lexer) peekChar() byte {
+func (l *Lexer) peekChar() rune {
 	if l.readPosition >= len(l.input) {
 		return 0
 	}
-	return l.input[l.readPosition]
+	ch, _ := utf8.DecodeRuneInString(l.input[l.readPosition:])
+	return ch
 }
 
-// skipWhitespace skips whitespace characters
+// skipWhitespace skips whitespace characters, stopping at a newline that
+// ends a statement
 func (l *Lexer) skipWhitespace() {
-	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
+	for l.ch == ' ' || l.ch == '\t' || l.ch == '\r' || (l.ch == '\n' && !l.semicolonDue()) {
 		l.readChar()
 	}
 }
 
+// statementEnds are the tokens that can end a statement
+var statementEnds = map[token.TokenType]bool{
+	token.IDENT:    true,
+	token.INT:      true,
+	token.FLOAT:    true,
+	token.STRING:   true,
+	token.CHAR:     true,
+	token.TRUE:     true,
+	token.FALSE:    true,
+	token.RETURN:   true,
+	token.BREAK:    true,
+	token.CONTINUE: true,
+	token.RPAREN:   true,
+	token.RBRACKET: true,
+	token.RBRACE:   true,
+	token.QUESTION: true,
+}
+
+// semicolonDue reports whether a newline or the end of the input at the
+// current character ends a statement: it follows a token that can end one,
+// outside of parentheses and brackets, where expressions may span lines.
+func (l *Lexer) semicolonDue() bool {
+	return l.endsStatement && (len(l.brackets) == 0 || l.brackets[len(l.brackets)-1] == '{')
+}
+
 // skipComment skips single-line and multi-line comments
 func (l *Lexer) skipComment() bool {
 	if l.ch == '/' && l.peekChar() == '/' {
@@ -106,15 +159,29 @@ func (l *Lexer) readNumber() (token.TokenType, string) {
 		}
 	}
 
+	// The name of a sized integer type may follow an integer, as in 10u64
+	if tokenType == token.INT && isLetter(l.ch) {
+		suffix := l.input[l.position:]
+		if end := strings.IndexFunc(suffix, func(r rune) bool { return !isLetter(r) && !isDigit(r) }); end >= 0 {
+			suffix = suffix[:end]
+		}
+		if token.IntegerSuffixes[suffix] {
+			for range suffix {
+				l.readChar()
+			}
+		}
+	}
+
 	return tokenType, l.input[position:l.position]
 }
 
-// readString reads a string literal
-func (l *Lexer) readString() (string, bool) {
+// readString reads a string literal, or a char literal when quote is ',
+// which ends at the end of the line if it is not closed
+func (l *Lexer) readString(quote rune) (string, bool) {
 	position := l.position + 1 // skip opening quote
 	for {
 		l.readChar()
-		if l.ch == '"' || l.ch == 0 {
+		if l.ch == quote || l.ch == 0 || (quote == '\'' && l.ch == '\n') {
 			break
 		}
 		// Handle escape sequences
@@ -126,7 +193,7 @@ func (l *Lexer) readString() (string, bool) {
 		}
 	}
 
-	if l.ch == 0 {
+	if l.ch != quote {
 		// Unterminated string
 		return "", false
 	}
@@ -135,23 +202,68 @@ func (l *Lexer) readString() (string, bool) {
 	return str, true
 }
 
-// NextToken returns the next token in the input
+// NextToken returns the next token in the input. As in Go, a SEMICOLON is
+// inserted where a line ends after a token that can end a statement: an
+// identifier, a literal, return, break, continue, ?, or a closing ), ] or }.
+// Inside parentheses and brackets lines do not end statements, and a line
+// ending with an operator or a comma continues on the next one. The end of
+// the input ends a statement the same way. Inserted semicolons have an empty
+// literal.
 func (l *Lexer) NextToken() token.Token {
-	var tok token.Token
+	tok := l.next()
+	switch tok.Type {
+	case token.LPAREN, token.LBRACKET, token.LBRACE:
+		l.brackets = append(l.brackets, rune(tok.Literal[0]))
+	case token.RPAREN, token.RBRACKET, token.RBRACE:
+		if len(l.brackets) > 0 {
+			l.brackets = l.brackets[:len(l.brackets)-1]
+		}
+	}
+	if tok.Type != token.COMMENT {
+		l.endsStatement = statementEnds[tok.Type]
+	}
+	return tok
+}
 
+// next returns the next token, without keeping track of the brackets and
+// of the end of statements
+func (l *Lexer) next() (tok token.Token) {
 	l.skipWhitespace()
 
 	// Skip comments
-	for l.skipComment() {
+	for {
+		start, line, column := l.position, l.line, l.column
+		if !l.skipComment() {
+			break
+		}
+		if l.keepComments {
+			text := strings.TrimRight(l.input[start:l.position], "\r")
+			return token.Token{Type: token.COMMENT, Literal: text, Line: line, Column: column}
+		}
 		l.skipWhitespace()
 	}
 
+	if (l.ch == '\n' || l.ch == 0) && l.semicolonDue() {
+		return token.Token{Type: token.SEMICOLON, Line: l.line, Column: l.column}
+	}
+
+	line, column := l.line, l.column
+	defer func() {
+		if tok.Line == 0 {
+			tok.Line, tok.Column = line, column
+		}
+	}()
+
 	switch l.ch {
 	case '=':
 		if l.peekChar() == '=' {
 			ch := l.ch
 			l.readChar()
 			tok = token.Token{Type: token.EQ, Literal: string(ch) + string(l.ch)}
+		} else if l.peekChar() == '>' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.ARROW, Literal: string(ch) + string(l.ch)}
 		} else {
 			tok = newToken(token.ASSIGN, l.ch)
 		}
@@ -171,7 +283,7 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.MULTIPLY, l.ch)
 	case '/':
 		if l.skipComment() {
-			return l.NextToken()
+			return l.next()
 		} else {
 			tok = newToken(token.DIVIDE, l.ch)
 		}
@@ -182,6 +294,10 @@ func (l *Lexer) NextToken() token.Token {
 			ch := l.ch
 			l.readChar()
 			tok = token.Token{Type: token.LTE, Literal: string(ch) + string(l.ch)}
+		} else if l.peekChar() == '<' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.SHL, Literal: string(ch) + string(l.ch)}
 		} else {
 			tok = newToken(token.LT, l.ch)
 		}
@@ -190,6 +306,11 @@ func (l *Lexer) NextToken() token.Token {
 			ch := l.ch
 			l.readChar()
 			tok = token.Token{Type: token.GTE, Literal: string(ch) + string(l.ch)}
+		} else if l.peekChar() == '>' {
+			// The parser splits >> again where it closes two type arguments
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.SHR, Literal: string(ch) + string(l.ch)}
 		} else {
 			tok = newToken(token.GT, l.ch)
 		}
@@ -199,7 +320,7 @@ func (l *Lexer) NextToken() token.Token {
 			l.readChar()
 			tok = token.Token{Type: token.AND, Literal: string(ch) + string(l.ch)}
 		} else {
-			tok = newToken(token.ILLEGAL, l.ch)
+			tok = newToken(token.BIT_AND, l.ch)
 		}
 	case '|':
 		if l.peekChar() == '|' {
@@ -207,10 +328,14 @@ func (l *Lexer) NextToken() token.Token {
 			l.readChar()
 			tok = token.Token{Type: token.OR, Literal: string(ch) + string(l.ch)}
 		} else {
-			tok = newToken(token.ILLEGAL, l.ch)
+			tok = newToken(token.BIT_OR, l.ch)
 		}
+	case '^':
+		tok = newToken(token.BIT_XOR, l.ch)
 	case ',':
 		tok = newToken(token.COMMA, l.ch)
+	case ';':
+		tok = newToken(token.SEMICOLON, l.ch)
 	case ':':
 		tok = newToken(token.COLON, l.ch)
 	case '.':
@@ -242,14 +367,24 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.RBRACKET, l.ch)
 	case '?':
 		tok = newToken(token.QUESTION, l.ch)
+	case '@':
+		tok = newToken(token.AT, l.ch)
 	case '"':
-		str, ok := l.readString()
+		str, ok := l.readString('"')
 		if !ok {
 			tok = newToken(token.ILLEGAL, l.ch)
 		} else {
 			tok.Type = token.STRING
 			tok.Literal = str
 		}
+	case '\'':
+		str, ok := l.readString('\'')
+		if !ok {
+			tok = newToken(token.ILLEGAL, l.ch)
+		} else {
+			tok.Type = token.CHAR
+			tok.Literal = str
+		}
 	case 0:
 		tok.Literal = ""
 		tok.Type = token.EOF
@@ -272,15 +407,18 @@ func (l *Lexer) NextToken() token.Token {
 	return tok
 }
 
-func newToken(tokenType token.TokenType, ch byte) token.Token {
+func newToken(tokenType token.TokenType, ch rune) token.Token {
 	return token.Token{Type: tokenType, Literal: string(ch)}
 }
 
-func isLetter(ch byte) bool {
-	return unicode.IsLetter(rune(ch)) || ch == '_'
+// isLetter reports whether ch may start an identifier: a letter of any
+// script, or _
+func isLetter(ch rune) bool {
+	return unicode.IsLetter(ch) || ch == '_'
 }
 
-func isDigit(ch byte) bool {
+// isDigit reports whether ch is an ASCII digit, the only digits of numbers
+func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
 
@@ -301,6 +439,8 @@ func processEscapeSequence(str string) string {
 				result.WriteByte('\\')
 			case '"':
 				result.WriteByte('"')
+			case '\'':
+				result.WriteByte('\'')
 			case 'u':
 				if i+4 < len(str) {
 					val, err := strconv.ParseInt(str[i+1:i+5], 16, 32)
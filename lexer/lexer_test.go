@@ -112,6 +112,7 @@ loop {
 		{token.FALSE, "false"},
 		{token.SEMICOLON, ""},
 		{token.RBRACE, "}"},
+		{token.SEMICOLON, ""},
 		{token.INT, "10"},
 		{token.EQ, "=="},
 		{token.INT, "10"},
@@ -119,12 +120,16 @@ loop {
 		{token.INT, "10"},
 		{token.NOT_EQ, "!="},
 		{token.INT, "9"},
+		{token.SEMICOLON, ""},
 		{token.STRING, "foobar"},
+		{token.SEMICOLON, ""},
 		{token.STRING, "foo bar"},
+		{token.SEMICOLON, ""},
 		{token.LET, "let"},
 		{token.IDENT, "x"},
 		{token.ASSIGN, "="},
 		{token.INT, "5"},
+		{token.SEMICOLON, ""},
 		{token.WHILE, "while"},
 		{token.LPAREN, "("},
 		{token.IDENT, "x"},
@@ -132,6 +137,7 @@ loop {
 		{token.INT, "0"},
 		{token.RPAREN, ")"},
 		{token.LBRACE, "{"},
+		{token.IDENT, "print"},
 		{token.LPAREN, "("},
 		{token.STRING, "Hello"},
 		{token.RPAREN, ")"},
@@ -143,17 +149,16 @@ loop {
 		{token.INT, "1"},
 		{token.SEMICOLON, ""},
 		{token.RBRACE, "}"},
+		{token.SEMICOLON, ""},
 		{token.FOR, "for"},
 		{token.LPAREN, "("},
 		{token.LET, "let"},
 		{token.IDENT, "i"},
 		{token.ASSIGN, "="},
 		{token.INT, "0"},
-		{token.SEMICOLON, ""},
 		{token.IDENT, "i"},
 		{token.LT, "<"},
 		{token.INT, "10"},
-		{token.SEMICOLON, ""},
 		{token.IDENT, "i"},
 		{token.ASSIGN, "="},
 		{token.IDENT, "i"},
@@ -161,16 +166,19 @@ loop {
 		{token.INT, "1"},
 		{token.RPAREN, ")"},
 		{token.LBRACE, "{"},
+		{token.IDENT, "println"},
 		{token.LPAREN, "("},
 		{token.STRING, "World"},
 		{token.RPAREN, ")"},
 		{token.SEMICOLON, ""},
 		{token.RBRACE, "}"},
+		{token.SEMICOLON, ""},
 		{token.LOOP, "loop"},
 		{token.LBRACE, "{"},
 		{token.BREAK, "break"},
 		{token.SEMICOLON, ""},
 		{token.RBRACE, "}"},
+		{token.SEMICOLON, ""},
 		{token.EOF, ""},
 	}
 
@@ -323,6 +331,81 @@ func TestFloatNumbers(t *testing.T) {
 	}
 }
 
+func TestIntegerSuffixes(t *testing.T) {
+	input := `10u64 255byte 7i32 3ab`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "10u64"},
+		{token.INT, "255byte"},
+		{token.INT, "7i32"},
+		{token.INT, "3"},
+		{token.IDENT, "ab"},
+		{token.SEMICOLON, ""},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestUnicodeIdentifiers(t *testing.T) {
+	input := `let 名前 = "ゼノ"
+grüße(名前, _x1)`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{token.LET, "let", 1, 1},
+		{token.IDENT, "名前", 1, 5},
+		{token.ASSIGN, "=", 1, 8},
+		{token.STRING, "ゼノ", 1, 10},
+		{token.IDENT, "grüße", 2, 1},
+		{token.LPAREN, "(", 2, 6},
+		{token.IDENT, "名前", 2, 7},
+		{token.COMMA, ",", 2, 9},
+		{token.IDENT, "_x1", 2, 11},
+		{token.RPAREN, ")", 2, 14},
+		{token.EOF, "", 2, 15},
+	}
+
+	l := New(input)
+	i := 0
+	for tok := l.NextToken(); i < len(tests); tok = l.NextToken() {
+		if tok.Type == token.SEMICOLON {
+			continue
+		}
+		tt := tests[i]
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - token wrong. expected=%s %q, got=%s %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+		if tok.Line != tt.expectedLine || tok.Column != tt.expectedColumn {
+			t.Errorf("tests[%d] - %q position wrong. expected=%d:%d, got=%d:%d",
+				i, tok.Literal, tt.expectedLine, tt.expectedColumn, tok.Line, tok.Column)
+		}
+		i++
+	}
+}
+
 func TestComparisonOperators(t *testing.T) {
 	input := `5 <= 10
 10 >= 5
@@ -369,6 +452,48 @@ func TestComparisonOperators(t *testing.T) {
 	}
 }
 
+func TestBitwiseOperators(t *testing.T) {
+	input := `a & b | c ^ d
+1 << 4 >> 2`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.BIT_AND, "&"},
+		{token.IDENT, "b"},
+		{token.BIT_OR, "|"},
+		{token.IDENT, "c"},
+		{token.BIT_XOR, "^"},
+		{token.IDENT, "d"},
+		{token.SEMICOLON, ""},
+		{token.INT, "1"},
+		{token.SHL, "<<"},
+		{token.INT, "4"},
+		{token.SHR, ">>"},
+		{token.INT, "2"},
+		{token.SEMICOLON, ""},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
 func TestProcessStringLiteral(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -390,3 +515,123 @@ func TestProcessStringLiteral(t *testing.T) {
 		}
 	}
 }
+
+func TestTokenPositions(t *testing.T) {
+	input := `let x = 5
+/* comment
+   spanning lines */ let s = "héllo" + x
+  println(s)`
+
+	tests := []struct {
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{"let", 1, 1},
+		{"x", 1, 5},
+		{"=", 1, 7},
+		{"5", 1, 9},
+		{"let", 3, 22},
+		{"s", 3, 26},
+		{"=", 3, 28},
+		{"héllo", 3, 30},
+		{"+", 3, 38},
+		{"x", 3, 40},
+		{"println", 4, 3},
+		{"(", 4, 10},
+		{"s", 4, 11},
+		{")", 4, 12},
+	}
+
+	l := New(input)
+	i := 0
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		if tok.Type == token.SEMICOLON {
+			continue
+		}
+		if i >= len(tests) {
+			t.Fatalf("unexpected token %q", tok.Literal)
+		}
+		tt := tests[i]
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Line != tt.expectedLine || tok.Column != tt.expectedColumn {
+			t.Errorf("tests[%d] - %q position wrong. expected=%d:%d, got=%d:%d",
+				i, tok.Literal, tt.expectedLine, tt.expectedColumn, tok.Line, tok.Column)
+		}
+		i++
+	}
+}
+
+func TestCommentTokens(t *testing.T) {
+	input := `// leading
+let x = 5 // trailing
+/* block
+   comment */ let y = 10`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedLine    int
+	}{
+		{token.COMMENT, "// leading", 1},
+		{token.LET, "let", 2},
+		{token.IDENT, "x", 2},
+		{token.ASSIGN, "=", 2},
+		{token.INT, "5", 2},
+		{token.COMMENT, "// trailing", 2},
+		{token.SEMICOLON, "", 2},
+		{token.COMMENT, "/* block\n   comment */", 3},
+		{token.LET, "let", 4},
+		{token.IDENT, "y", 4},
+		{token.ASSIGN, "=", 4},
+		{token.INT, "10", 4},
+		{token.SEMICOLON, "", 4},
+		{token.EOF, "", 4},
+	}
+
+	l := NewWithComments(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral || tok.Line != tt.expectedLine {
+			t.Fatalf("tests[%d] - expected %s %q on line %d, got %s %q on line %d",
+				i, tt.expectedType, tt.expectedLiteral, tt.expectedLine, tok.Type, tok.Literal, tok.Line)
+		}
+	}
+}
+
+func TestCharLiterals(t *testing.T) {
+	input := `'a' '\'' '\n' 'é' 'ab
+x`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.CHAR, "a"},
+		{token.CHAR, `\'`},
+		{token.CHAR, `\n`},
+		{token.CHAR, "é"},
+		{token.ILLEGAL, "\n"},
+		{token.IDENT, "x"},
+		{token.SEMICOLON, ""},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
@@ -0,0 +1,385 @@
+package linter
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/diagnostics"
+)
+
+// ConfigFile is the name of the file configuring the linter for the files
+// in its directory and below
+const ConfigFile = ".zenolint.toml"
+
+// DefaultRules returns the rules run by the lint and check commands, which
+// a configuration can turn off
+func DefaultRules() []Rule {
+	return []Rule{
+		&UnusedVariableRule{},
+		&UnusedFunctionRule{},
+		&FunctionNameRule{},
+		&VariableNameRule{},
+		&UnusedImportRule{},
+		&MissingImportRule{},
+		&DeprecatedUsageRule{},
+		&MatchExhaustiveRule{},
+		&UnreachableCodeRule{},
+		&MissingReturnRule{},
+		&ConstantConditionRule{},
+		&FunctionComplexityRule{},
+		&FunctionLengthRule{},
+		&FunctionParametersRule{},
+	}
+}
+
+// RuleConfig configures one rule
+type RuleConfig struct {
+	Off      bool
+	Severity diagnostics.Severity // of the issues of the rule, Warning by default
+	Exclude  []string             // paths the rule is not run on
+	Max      int                  // the limit of a Limiter, 0 for its default
+}
+
+// Config is the content of a configuration file:
+//
+//	# Paths are relative to the directory of the file; ** matches any
+//	# number of directories, and a directory excludes the files below it
+//	exclude = ["gen", "vendor/**/*.zeno"]
+//
+//	[rules]
+//	variable-naming-convention = "off"
+//	unused-import = "error"        # or "warning", "info"
+//
+//	[rules.unused-function]
+//	severity = "info"
+//	exclude = ["scripts"]
+//
+//	[rules.function-length]
+//	max = 50                       # the limit of the rules implementing Limiter
+//
+// The zero Config runs every rule on every file, with warnings.
+type Config struct {
+	Path    string // the file read, empty for the defaults
+	Exclude []string
+	Rules   map[string]RuleConfig // by rule name
+}
+
+// LoadConfig reads the configuration of the files in dir, found in dir or
+// the nearest parent directory holding one. Without a configuration file,
+// it returns the defaults.
+func LoadConfig(dir string) (*Config, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		file := filepath.Join(dir, ConfigFile)
+		if data, err := os.ReadFile(file); err == nil {
+			return ParseConfig(file, string(data))
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return &Config{}, nil
+		}
+		dir = parent
+	}
+}
+
+// ParseConfig parses the configuration read from file. It supports the
+// part of TOML shown in Config: strings, integers, booleans, arrays of
+// strings and tables.
+func ParseConfig(file, content string) (*Config, error) {
+	config := &Config{Path: file, Rules: make(map[string]RuleConfig)}
+	known := make(map[string]Rule)
+	for _, rule := range DefaultRules() {
+		known[rule.Name()] = rule
+	}
+	table := ""
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(stripComment(lines[i]))
+		if line == "" {
+			continue
+		}
+		fail := func(format string, args ...interface{}) (*Config, error) {
+			return nil, fmt.Errorf("%s:%d: %s", file, i+1, fmt.Sprintf(format, args...))
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return fail("expected ] at the end of the table header")
+			}
+			table = strings.TrimSpace(line[1 : len(line)-1])
+			if name, ok := strings.CutPrefix(table, "rules."); ok && known[name] == nil {
+				return fail("unknown rule %q", name)
+			} else if !ok && table != "rules" {
+				return fail("unknown table [%s]", table)
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return fail("expected key = value")
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		// Arrays may span lines
+		for strings.HasPrefix(value, "[") && !strings.HasSuffix(value, "]") && i+1 < len(lines) {
+			i++
+			value += " " + strings.TrimSpace(stripComment(lines[i]))
+		}
+		parsed, err := parseValue(value)
+		if err != nil {
+			return fail("%v", err)
+		}
+		switch {
+		case table == "" && key == "exclude":
+			list, ok := parsed.([]string)
+			if !ok {
+				return fail("exclude must be an array of strings")
+			}
+			config.Exclude = list
+		case table == "rules":
+			if known[key] == nil {
+				return fail("unknown rule %q", key)
+			}
+			rule := config.rule(key)
+			if err := rule.setSeverity(parsed); err != nil {
+				return fail("%v", err)
+			}
+			config.Rules[key] = rule
+		case strings.HasPrefix(table, "rules."):
+			name := strings.TrimPrefix(table, "rules.")
+			rule := config.rule(name)
+			switch key {
+			case "severity":
+				if err := rule.setSeverity(parsed); err != nil {
+					return fail("%v", err)
+				}
+			case "exclude":
+				list, ok := parsed.([]string)
+				if !ok {
+					return fail("exclude must be an array of strings")
+				}
+				rule.Exclude = list
+			case "max":
+				if _, ok := known[name].(Limiter); !ok {
+					return fail("rule %s has no limit to set with max", name)
+				}
+				max, ok := parsed.(int)
+				if !ok || max <= 0 {
+					return fail("max must be a positive integer")
+				}
+				rule.Max = max
+			default:
+				return fail("unknown key %q, expected severity, exclude or max", key)
+			}
+			config.Rules[name] = rule
+		default:
+			return fail("unknown key %q", key)
+		}
+	}
+	return config, nil
+}
+
+// setSeverity sets the severity of the rule from "off", "info", "warning",
+// "error" or a boolean turning it on or off
+func (r *RuleConfig) setSeverity(value interface{}) error {
+	switch v := value.(type) {
+	case bool:
+		r.Off = !v
+		return nil
+	case string:
+		r.Off = false
+		switch v {
+		case "off":
+			r.Off = true
+		case "info":
+			r.Severity = diagnostics.Info
+		case "warning":
+			r.Severity = diagnostics.Warning
+		case "error":
+			r.Severity = diagnostics.Error
+		default:
+			return fmt.Errorf("unknown severity %q, expected off, info, warning or error", v)
+		}
+		return nil
+	}
+	return fmt.Errorf("expected a severity such as \"warning\", or a boolean")
+}
+
+// stripComment removes a # comment outside of strings from line
+func stripComment(line string) string {
+	inString := false
+	for i, r := range line {
+		switch {
+		case r == '"' && (i == 0 || line[i-1] != '\\'):
+			inString = !inString
+		case r == '#' && !inString:
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// parseValue parses a string, an integer, a boolean or an array of strings
+func parseValue(value string) (interface{}, error) {
+	switch {
+	case value == "true" || value == "false":
+		return value == "true", nil
+	case strings.HasPrefix(value, "\""):
+		return strconv.Unquote(value)
+	case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+		list := []string{}
+		for _, item := range splitArray(value[1 : len(value)-1]) {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			s, err := strconv.Unquote(item)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string %s in array", item)
+			}
+			list = append(list, s)
+		}
+		return list, nil
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		return n, nil
+	}
+	return nil, fmt.Errorf("invalid value %s, expected a string, an integer, a boolean or an array of strings", value)
+}
+
+// splitArray splits the items of an array at the commas outside of
+// strings, so that "a,b.zeno" stays one item
+func splitArray(items string) []string {
+	var parts []string
+	inString, start := false, 0
+	for i := 0; i < len(items); i++ {
+		switch c := items[i]; {
+		case c == '\\' && inString:
+			i++ // the escaped character
+		case c == '"':
+			inString = !inString
+		case c == ',' && !inString:
+			parts = append(parts, items[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, items[start:])
+}
+
+// Excluded reports whether file is not linted at all
+func (c *Config) Excluded(file string) bool {
+	return c.matches(c.Exclude, file)
+}
+
+// enabled returns the rules run on file, with the limits configured
+func (c *Config) enabled(rules []Rule, file string) []Rule {
+	var result []Rule
+	for _, rule := range rules {
+		config, ok := c.Rules[rule.Name()]
+		if ok && (config.Off || c.matches(config.Exclude, file)) {
+			continue
+		}
+		if limiter, isLimiter := rule.(Limiter); isLimiter && config.Max > 0 {
+			rule = limiter.WithMax(config.Max)
+		}
+		result = append(result, rule)
+	}
+	return result
+}
+
+// severity returns the severity of the issues of rule
+func (c *Config) severity(rule string) diagnostics.Severity {
+	if config, ok := c.Rules[rule]; ok {
+		return config.Severity
+	}
+	return diagnostics.Warning
+}
+
+// rule returns the configuration of the rule named name, with the default
+// severity if it has none yet
+func (c *Config) rule(name string) RuleConfig {
+	if config, ok := c.Rules[name]; ok {
+		return config
+	}
+	return RuleConfig{Severity: diagnostics.Warning}
+}
+
+// matches reports whether file matches one of patterns, which are relative
+// to the directory of the configuration file
+func (c *Config) matches(patterns []string, file string) bool {
+	if len(patterns) == 0 || c.Path == "" {
+		return false
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(filepath.Dir(c.Path), abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	for _, pattern := range patterns {
+		if matchPath(strings.Split(path.Clean(pattern), "/"), strings.Split(filepath.ToSlash(rel), "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPath matches the elements of a path against the ones of a pattern,
+// in which ** matches any number of elements. A pattern matching a
+// directory matches the files below it.
+func matchPath(pattern, elements []string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(elements); i++ {
+			if matchPath(pattern[1:], elements[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(elements) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], elements[0]); !ok {
+		return false
+	}
+	return matchPath(pattern[1:], elements[1:])
+}
+
+// InitConfig returns the content of a new configuration file listing the
+// rules with their defaults
+func InitConfig() string {
+	var builder strings.Builder
+	builder.WriteString("# Configuration of zeno lint and zeno check for this directory and below.\n")
+	builder.WriteString("# Paths are relative to this file; ** matches any number of directories,\n")
+	builder.WriteString("# and a directory excludes the files below it.\n")
+	builder.WriteString("exclude = []\n\n")
+	builder.WriteString("# The severity of each rule: \"off\", \"info\", \"warning\" or \"error\". A rule\n")
+	builder.WriteString("# can also have a table of its own, [rules.<name>], with a severity and an\n")
+	builder.WriteString("# exclude list; the rules with a limit have one, with the max allowed.\n")
+	builder.WriteString("[rules]\n")
+	rules := DefaultRules()
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Name() < rules[j].Name() })
+	var limiters []Limiter
+	for _, rule := range rules {
+		if limiter, ok := rule.(Limiter); ok {
+			limiters = append(limiters, limiter)
+			continue
+		}
+		fmt.Fprintf(&builder, "\n# %s\n%s = \"warning\"\n", rule.Description(), rule.Name())
+	}
+	for _, limiter := range limiters {
+		fmt.Fprintf(&builder, "\n# %s\n[rules.%s]\nseverity = \"warning\"\nmax = %d\n", limiter.Description(), limiter.Name(), limiter.Max())
+	}
+	return builder.String()
+}
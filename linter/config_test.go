@@ -0,0 +1,56 @@
+package linter
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseConfigArrays(t *testing.T) {
+	content := `exclude = ["a,b.zeno", "gen"]
+
+[rules.unused-function]
+exclude = [
+    "x,y",      # a comma in a name
+    "scripts",
+]
+`
+	config, err := ParseConfig(".zenolint.toml", content)
+	if err != nil {
+		t.Fatalf("ParseConfig: %v", err)
+	}
+	if want := []string{"a,b.zeno", "gen"}; !reflect.DeepEqual(config.Exclude, want) {
+		t.Errorf("exclude = %q, want %q", config.Exclude, want)
+	}
+	if got, want := config.Rules["unused-function"].Exclude, []string{"x,y", "scripts"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unused-function exclude = %q, want %q", got, want)
+	}
+
+	if _, err := ParseConfig(".zenolint.toml", `exclude = ["a", b]`); err == nil {
+		t.Errorf("expected an error for an unquoted array item")
+	}
+}
+
+func TestConfigExcluded(t *testing.T) {
+	dir := t.TempDir()
+	config, err := ParseConfig(filepath.Join(dir, ConfigFile), `exclude = ["a,b.zeno", "gen", "vendor/**/*.zeno"]`)
+	if err != nil {
+		t.Fatalf("ParseConfig: %v", err)
+	}
+	tests := []struct {
+		file     string
+		excluded bool
+	}{
+		{"a,b.zeno", true},
+		{"a.zeno", false},
+		{"b.zeno", false},
+		{"gen/main.zeno", true},
+		{"vendor/x/y/lib.zeno", true},
+		{"src/main.zeno", false},
+	}
+	for _, tt := range tests {
+		if excluded := config.Excluded(filepath.Join(dir, tt.file)); excluded != tt.excluded {
+			t.Errorf("Excluded(%q) = %v, want %v", tt.file, excluded, tt.excluded)
+		}
+	}
+}
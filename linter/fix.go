@@ -0,0 +1,243 @@
+package linter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/lexer"
+	"github.com/linkalls/zeno-lang/token"
+)
+
+// Fix describes an automatic correction for an issue, as edits of the
+// source that are applied together.
+type Fix struct {
+	Description string // A short, human-readable summary of the change.
+	Edits       []Edit
+}
+
+// Edit replaces the text from Start up to End, excluded, with Text. Lines
+// and columns start at 1 and count characters, like the ones of tokens; an
+// insertion has the same Start and End.
+type Edit struct {
+	Start, End ast.Position
+	Text       string
+}
+
+// insertLine returns the edit inserting text as a new line before line
+func insertLine(line int, text string) Edit {
+	pos := ast.Position{Line: line, Column: 1}
+	return Edit{Start: pos, End: pos, Text: text + "\n"}
+}
+
+// Source is a file being fixed: its text and its tokens, comments and the
+// semicolons ending lines excluded
+type Source struct {
+	Text   string
+	Tokens []token.Token
+}
+
+// NewSource returns the source of a file, for the fixes of its issues
+func NewSource(text string) *Source {
+	s := &Source{Text: text}
+	l := lexer.New(text)
+	for {
+		tok := l.NextToken()
+		if tok.Type == token.EOF {
+			break
+		}
+		if tok.Type == token.SEMICOLON && tok.Literal == "" {
+			continue
+		}
+		s.Tokens = append(s.Tokens, tok)
+	}
+	return s
+}
+
+// end returns the position following tok. Tokens do not span lines, but
+// the literal of a string token is its value, without quotes or escapes, so
+// the end of the string is found in the text.
+func (s *Source) end(tok token.Token) ast.Position {
+	if tok.Type != token.STRING {
+		return ast.Position{Line: tok.Line, Column: tok.Column + utf8.RuneCountInString(tok.Literal)}
+	}
+	offset := s.offset(start(tok))
+	if offset < 0 {
+		return start(tok)
+	}
+	column, escaped := tok.Column+1, false
+	for _, r := range s.Text[offset+1:] {
+		column++
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"' || r == '\n':
+			return ast.Position{Line: tok.Line, Column: column}
+		}
+	}
+	return ast.Position{Line: tok.Line, Column: column}
+}
+
+// start returns the position of tok
+func start(tok token.Token) ast.Position {
+	return ast.Position{Line: tok.Line, Column: tok.Column}
+}
+
+// at returns the index of the first token at pos or after it, or -1
+func (s *Source) at(pos ast.Position) int {
+	for i, tok := range s.Tokens {
+		if tok.Line > pos.Line || (tok.Line == pos.Line && tok.Column >= pos.Column) {
+			return i
+		}
+	}
+	return -1
+}
+
+// identifiers returns the indexes of the identifier tokens named name
+func (s *Source) identifiers(name string) []int {
+	var indexes []int
+	for i, tok := range s.Tokens {
+		if tok.Type == token.IDENT && tok.Literal == name {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// offset returns the byte offset of pos in the text, or -1 if it is
+// outside of it
+func (s *Source) offset(pos ast.Position) int {
+	line, column := 1, 1
+	for i, r := range s.Text {
+		if line == pos.Line && column == pos.Column {
+			return i
+		}
+		if r == '\n' {
+			if line == pos.Line {
+				return -1
+			}
+			line, column = line+1, 1
+		} else {
+			column++
+		}
+	}
+	if line == pos.Line && column == pos.Column {
+		return len(s.Text)
+	}
+	return -1
+}
+
+// removeLines returns the edit removing the tokens from first to last, and
+// the lines holding them if they hold nothing else
+func (s *Source) removeLines(first, last int) Edit {
+	from, to := start(s.Tokens[first]), s.end(s.Tokens[last])
+	before := s.Text[:s.offset(from)]
+	after := s.Text[s.offset(to):]
+	lineStart := before[strings.LastIndex(before, "\n")+1:]
+	lineEnd, _, found := strings.Cut(after, "\n")
+	if strings.TrimSpace(lineStart) == "" && strings.TrimSpace(lineEnd) == "" && found {
+		from = ast.Position{Line: from.Line, Column: 1}
+		to = ast.Position{Line: to.Line + 1, Column: 1}
+	}
+	return Edit{Start: from, End: to}
+}
+
+// AddFixes attaches to the issues of the rules implementing Fixer the
+// corrections they offer for source, the text the linted program was parsed
+// from.
+func (l *Linter) AddFixes(issues []Issue, source string) {
+	fixers := make(map[string]Fixer)
+	for _, rule := range l.rules {
+		if fixer, ok := rule.(Fixer); ok {
+			fixers[rule.Name()] = fixer
+		}
+	}
+	var src *Source
+	for i := range issues {
+		fixer, ok := fixers[issues[i].RuleName]
+		if !ok || issues[i].Fix != nil {
+			continue
+		}
+		if src == nil {
+			src = NewSource(source)
+		}
+		issues[i].Fix = fixer.Fix(issues[i], src)
+	}
+}
+
+// span is an edit of the text between two byte offsets
+type span struct {
+	start, end int
+	text       string
+}
+
+// overlaps reports whether an edit from start to end changes the text s
+// changes. An insertion at the start of a replaced text overlaps it, while
+// insertions at the same place do not overlap and keep their order.
+func (s span) overlaps(start, end int) bool {
+	switch {
+	case start == end && s.start == s.end:
+		return false
+	case start == end:
+		return s.start <= start && start < s.end
+	case s.start == s.end:
+		return start <= s.start && s.start < end
+	}
+	return start < s.end && s.start < end
+}
+
+// ApplyFixes applies the fixes attached to issues to source and returns the
+// new source along with the number of fixes applied. Identical fixes (for
+// example the same import suggested for several calls) are applied once, and
+// a fix editing text that another one edits is left for a later run.
+func ApplyFixes(source string, issues []Issue) (string, int) {
+	src := &Source{Text: source}
+	var spans []span
+	seen := make(map[string]bool)
+	applied := 0
+	for _, issue := range issues {
+		if issue.Fix == nil || len(issue.Fix.Edits) == 0 || seen[fmt.Sprint(issue.Fix.Edits)] {
+			continue
+		}
+		seen[fmt.Sprint(issue.Fix.Edits)] = true
+		var edits []span
+		valid := true
+		for _, edit := range issue.Fix.Edits {
+			start, end := src.offset(edit.Start), src.offset(edit.End)
+			if start < 0 || end < start {
+				valid = false
+				break
+			}
+			for _, other := range spans {
+				valid = valid && !other.overlaps(start, end)
+			}
+			for _, other := range edits {
+				valid = valid && !other.overlaps(start, end)
+			}
+			edits = append(edits, span{start, end, edit.Text})
+		}
+		if valid {
+			spans = append(spans, edits...)
+			applied++
+		}
+	}
+	if applied == 0 {
+		return source, 0
+	}
+
+	// Insertions at the same place keep the order of their fixes
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var builder strings.Builder
+	last := 0
+	for _, s := range spans {
+		builder.WriteString(source[last:s.start])
+		builder.WriteString(s.text)
+		last = s.end
+	}
+	builder.WriteString(source[last:])
+	return builder.String(), applied
+}
@@ -0,0 +1,23 @@
+package linter
+
+import "testing"
+
+func TestApplyFixes(t *testing.T) {
+	tests := []struct {
+		rule     Rule
+		source   string
+		expected string
+	}{
+		{&UnusedImportRule{}, "import {println, trim} from \"std/fmt\"\nprintln(1)\n", "import {println} from \"std/fmt\"\nprintln(1)\n"},
+		{&UnusedImportRule{}, "import {trim} from \"std/fmt\"\nprintln(1)\n", "println(1)\n"},
+		{&VariableNameRule{}, "let user_name = 1\nprintln(user_name)\n", "let userName = 1\nprintln(userName)\n"},
+	}
+	for _, tt := range tests {
+		issues := lint(t, tt.rule, tt.source)
+		NewLinter([]Rule{tt.rule}).AddFixes(issues, tt.source)
+		fixed, count := ApplyFixes(tt.source, issues)
+		if fixed != tt.expected || count != 1 {
+			t.Errorf("%q: fixed %d to %q, want %q", tt.source, count, fixed, tt.expected)
+		}
+	}
+}
@@ -1,11 +1,36 @@
 package linter
 
+import (
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/diagnostics"
+)
+
 // Issue represents a single linting issue found.
 type Issue struct {
-	Filepath string // The path to the file where the issue was found.
-	Line     int    // The line number of the issue.
-	Column   int    // The column number of the issue (can be 0 if not applicable).
-	RuleName string // The name of the rule that was violated.
-	Message  string // A descriptive message for the issue.
-	// Severity string // e.g., "error", "warning", "info" (optional for now, can default to warning)
+	Filepath string               // The path to the file where the issue was found.
+	Line     int                  // The line number of the issue.
+	Column   int                  // The column number of the issue (can be 0 if not applicable).
+	RuleName string               // The name of the rule that was violated.
+	Code     string               // The stable diagnostic code, e.g. Z0304.
+	Message  string               // A descriptive message for the issue.
+	Symbol   string               // The name the issue is about, for fixes, if any.
+	Fix      *Fix                 // An automatic correction, if the rule can offer one.
+	Severity diagnostics.Severity // Set by Lint from the configuration, Warning by default.
+}
+
+// Diagnostic converts the issue to the representation shared by all phases.
+// The description of its fix, if any, becomes the suggestion.
+func (i Issue) Diagnostic() diagnostics.Diagnostic {
+	d := diagnostics.Diagnostic{
+		Severity: i.Severity,
+		Code:     i.Code,
+		Source:   i.RuleName,
+		File:     i.Filepath,
+		Pos:      ast.Position{Line: i.Line, Column: i.Column},
+		Message:  i.Message,
+	}
+	if i.Fix != nil {
+		d.Suggestion = i.Fix.Description
+	}
+	return d
 }
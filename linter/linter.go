@@ -3,6 +3,7 @@ package linter
 import (
 	"fmt"     // For potential error formatting
 	"strings" // Added for strings.HasPrefix
+	"unicode"
 
 	"github.com/linkalls/zeno-lang/ast"
 )
@@ -11,16 +12,28 @@ import (
 type Linter struct {
 	rules  []Rule
 	issues []Issue
-	// Optionally, configuration for rules can be added here later.
+	config *Config
+	active []Rule // the rules run on the file being linted
 }
 
 func NewLinter(rules []Rule) *Linter {
-	return &Linter{rules: rules}
+	return &Linter{rules: rules, config: &Config{}}
+}
+
+// SetConfig makes the linter skip the files and rules config excludes and
+// give the issues the severities it sets.
+func (l *Linter) SetConfig(config *Config) {
+	l.config = config
 }
 
 // Lint analyzes the given AST program and returns a list of issues.
 func (l *Linter) Lint(program *ast.Program, filepath string) ([]Issue, error) {
 	l.issues = []Issue{} // Reset issues for this run
+	if l.config.Excluded(filepath) {
+		return l.issues, nil
+	}
+	l.active = l.config.enabled(l.rules, filepath)
+	defer l.setSeverities()
 
 	visitor := &linterVisitor{
 		linter:              l,
@@ -34,13 +47,26 @@ func (l *Linter) Lint(program *ast.Program, filepath string) ([]Issue, error) {
 		usedImportedSymbols: make(map[string]bool),
 	}
 
+	// Module-level variables may be used by the functions declared before
+	// them
+	moduleLevel := program.ModuleLevel()
+	for _, stmt := range program.Statements {
+		if let, ok := stmt.(*ast.LetDeclaration); ok && (moduleLevel || let.Constant) {
+			for _, name := range let.BoundNames() {
+				if name != "_" {
+					visitor.declaredVars[name] = let
+				}
+			}
+		}
+	}
+
 	if err := Walk(program, visitor); err != nil {
 		// If Walk itself returns an error (e.g. from a visitor method), propagate it.
 		return l.issues, fmt.Errorf("error during AST walk for file %s: %w", filepath, err)
 	}
 
 	// Post-traversal checks for rules that require them
-	for _, rule := range l.rules {
+	for _, rule := range l.active {
 		// Check for UnusedVariableRule
 		if uvRule, ok := rule.(*UnusedVariableRule); ok {
 			postIssues := uvRule.PostCheck(visitor.declaredVars, visitor.usedVars, filepath)
@@ -64,9 +90,18 @@ func (l *Linter) Lint(program *ast.Program, filepath string) ([]Issue, error) {
 		// }
 	}
 
+	l.issues = parseSuppressions(program.Comments).filter(l.issues)
 	return l.issues, nil
 }
 
+// setSeverities sets the severity of the issues found to the one configured
+// for their rule
+func (l *Linter) setSeverities() {
+	for i := range l.issues {
+		l.issues[i].Severity = l.config.severity(l.issues[i].RuleName)
+	}
+}
+
 // RegisterRule adds a rule to the linter.
 func (l *Linter) RegisterRule(rule Rule) {
 	l.rules = append(l.rules, rule)
@@ -87,15 +122,16 @@ type linterVisitor struct {
 }
 
 func (v *linterVisitor) applyRules(node ast.Node) error {
-	for _, rule := range v.linter.rules {
+	for _, rule := range v.linter.active {
 		issues := rule.Check(node, v.program)
 		for i := range issues {
 			if issues[i].Filepath == "" {
 				issues[i].Filepath = v.filepath
 			}
-			// TODO: Populate Line/Column from AST node if available and not set by rule.
-			// This requires AST nodes to have position information.
-			// For now, rules are responsible or this needs to be added when AST supports it.
+			if issues[i].Line == 0 {
+				pos := node.Pos()
+				issues[i].Line, issues[i].Column = pos.Line, pos.Column
+			}
 		}
 		v.linter.issues = append(v.linter.issues, issues...)
 	}
@@ -103,14 +139,61 @@ func (v *linterVisitor) applyRules(node ast.Node) error {
 }
 
 func (v *linterVisitor) VisitProgram(node *ast.Program) error {
+	// Walk does not enter type declarations, so their field types are
+	// marked here
+	for _, stmt := range node.Statements {
+		switch decl := stmt.(type) {
+		case *ast.TypeDeclaration:
+			for _, field := range decl.Fields {
+				v.useTypes(field.TypeAnn)
+			}
+		case *ast.EnumDeclaration:
+			for _, variant := range decl.Variants {
+				v.useTypes(variant.Fields...)
+			}
+		}
+	}
 	return v.applyRules(node)
 }
 
+// useTypes marks the names in type annotations as used, such as Point in
+// []Point, Result<Point> or geo.Point. Every name is marked, because type
+// declarations are seen before the imports they use are visited
+func (v *linterVisitor) useTypes(annotations ...string) {
+	if v.usedImportedSymbols == nil {
+		return
+	}
+	for _, annotation := range annotations {
+		words := strings.FieldsFunc(annotation, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+		})
+		for _, name := range words {
+			v.usedImportedSymbols[name] = true
+		}
+	}
+}
+
+// useSignature marks the imported types in a function's parameters and
+// return type as used
+func (v *linterVisitor) useSignature(fn *ast.FunctionDefinition) {
+	for _, param := range fn.Parameters {
+		v.useTypes(param.Type)
+	}
+	if fn.ReturnType != nil {
+		v.useTypes(*fn.ReturnType)
+	}
+}
+
 func (v *linterVisitor) VisitImportStatement(node *ast.ImportStatement) error {
-	if v.importedSymbols != nil {
+	// pub import で再エクスポートした項目は使われなくてもよい
+	if v.importedSymbols != nil && !node.IsPublic {
 		for _, imp := range node.Imports {
-			// ImportItem.Nameにインポートされた識別子名が入る
-			v.importedSymbols[imp.Name] = node
+			// as で別名を付けた場合は別名で使われる
+			v.importedSymbols[imp.LocalName()] = node
+		}
+		// import * as io は io.readFile() の io で使われる
+		if node.Namespace != "" {
+			v.importedSymbols[node.Namespace] = node
 		}
 	}
 	return v.applyRules(node)
@@ -119,7 +202,14 @@ func (v *linterVisitor) VisitImportStatement(node *ast.ImportStatement) error {
 func (v *linterVisitor) VisitLetDeclaration(node *ast.LetDeclaration) error {
 	// Store variable declaration
 	if v.declaredVars != nil {
-		v.declaredVars[node.Name] = node // Store the node itself for position info later
+		for _, name := range node.BoundNames() {
+			if name != "_" {
+				v.declaredVars[name] = node // Store the node itself for position info later
+			}
+		}
+	}
+	if node.TypeAnn != nil {
+		v.useTypes(*node.TypeAnn)
 	}
 	// Also apply other rules to this node
 	return v.applyRules(node)
@@ -137,6 +227,15 @@ func (v *linterVisitor) VisitFunctionDefinition(node *ast.FunctionDefinition) er
 	if v.declaredFns != nil && node.Name != "main" && !node.IsPublic {
 		v.declaredFns[node.Name] = node
 	}
+	v.useSignature(node)
+	return v.applyRules(node)
+}
+
+func (v *linterVisitor) VisitImplDeclaration(node *ast.ImplDeclaration) error {
+	v.useTypes(node.Trait, node.TypeName)
+	for _, method := range node.Methods {
+		v.useSignature(method)
+	}
 	return v.applyRules(node)
 }
 
@@ -152,6 +251,34 @@ func (v *linterVisitor) VisitWhileStatement(node *ast.WhileStatement) error {
 	return v.applyRules(node)
 }
 
+func (v *linterVisitor) VisitLoopStatement(node *ast.LoopStatement) error {
+	return v.applyRules(node)
+}
+
+func (v *linterVisitor) VisitSpawnStatement(node *ast.SpawnStatement) error {
+	return v.applyRules(node)
+}
+
+func (v *linterVisitor) VisitWhenStatement(node *ast.WhenStatement) error {
+	return v.applyRules(node)
+}
+
+func (v *linterVisitor) VisitTryStatement(node *ast.TryStatement) error {
+	return v.applyRules(node)
+}
+
+// VisitForStatement declares the loop variables, which are reported like
+// variables declared with let when the body does not use them
+func (v *linterVisitor) VisitForStatement(node *ast.ForStatement) error {
+	if v.declaredVars != nil {
+		if node.IndexName != "" {
+			v.declaredVars[node.IndexName] = node
+		}
+		v.declaredVars[node.VarName] = node
+	}
+	return v.applyRules(node)
+}
+
 func (v *linterVisitor) VisitBlock(node *ast.Block) error {
 	return v.applyRules(node)
 }
@@ -167,6 +294,10 @@ func (v *linterVisitor) VisitIdentifier(node *ast.Identifier) error {
 			v.usedVars[node.Value] = true
 		}
 	}
+	// A function passed as a value, e.g. to withLock, is used
+	if v.calledFns != nil {
+		v.calledFns[node.Value] = true
+	}
 	// Check if the identifier is an imported symbol
 	if v.usedImportedSymbols != nil {
 		if _, isImported := v.importedSymbols[node.Value]; isImported {
@@ -201,6 +332,7 @@ func (v *linterVisitor) VisitFunctionCall(node *ast.FunctionCall) error {
 			v.usedImportedSymbols[node.Name] = true
 		}
 	}
+	v.useTypes(node.TypeArguments...)
 	return v.applyRules(node)
 }
 
@@ -221,7 +353,17 @@ func (v *linterVisitor) VisitArrayLiteral(node *ast.ArrayLiteral) error {
 	return v.applyRules(node)
 }
 
+func (v *linterVisitor) VisitTupleLiteral(node *ast.TupleLiteral) error {
+	for _, elem := range node.Elements {
+		if err := Walk(elem, v); err != nil {
+			return err
+		}
+	}
+	return v.applyRules(node)
+}
+
 func (v *linterVisitor) VisitStructLiteral(node *ast.StructLiteral) error {
+	v.useTypes(node.TypeName)
 	for _, valueExpr := range node.Fields {
 		if err := Walk(valueExpr, v); err != nil {
 			return err
@@ -241,3 +383,27 @@ func (v *linterVisitor) VisitMapLiteral(node *ast.MapLiteral) error {
 	}
 	return v.applyRules(node)
 }
+
+func (v *linterVisitor) VisitMatchExpression(node *ast.MatchExpression) error {
+	return v.applyRules(node)
+}
+
+func (v *linterVisitor) VisitIfExpression(node *ast.IfExpression) error {
+	return v.applyRules(node)
+}
+
+func (v *linterVisitor) VisitTryExpression(node *ast.TryExpression) error {
+	return v.applyRules(node)
+}
+
+func (v *linterVisitor) VisitIndexExpression(node *ast.IndexExpression) error {
+	return v.applyRules(node)
+}
+
+func (v *linterVisitor) VisitSliceExpression(node *ast.SliceExpression) error {
+	return v.applyRules(node)
+}
+
+func (v *linterVisitor) VisitMethodCallExpression(node *ast.MethodCallExpression) error {
+	return v.applyRules(node)
+}
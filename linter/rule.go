@@ -9,3 +9,10 @@ type Rule interface {
 	Check(node ast.Node, program *ast.Program) []Issue // Performs the check on the given AST node.
 	                                                  // `program` provides context of the whole program if needed.
 }
+
+// Fixer is implemented by the rules that can correct their issues. Fix
+// returns the correction of an issue the rule reported in src, or nil when
+// it cannot be made safely.
+type Fixer interface {
+	Fix(issue Issue, src *Source) *Fix
+}
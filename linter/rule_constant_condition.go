@@ -0,0 +1,232 @@
+package linter
+
+import (
+	"cmp"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+)
+
+// ConstantConditionRule (L11)
+// Reports the conditions of if and while that are always true or always
+// false, found by folding the literals they are made of, the while loops
+// whose condition is always true and that have no break, and the
+// comparisons of a variable with itself. Constants declared with const are
+// not folded, since switching code on and off with them is intended.
+type ConstantConditionRule struct{}
+
+func (r *ConstantConditionRule) Name() string {
+	return "constant-condition"
+}
+
+func (r *ConstantConditionRule) Description() string {
+	return "Detects conditions that are always true or false, endless while loops and comparisons of a variable with itself."
+}
+
+func (r *ConstantConditionRule) Check(node ast.Node, program *ast.Program) []Issue {
+	var conditions []ast.Expression
+	switch n := node.(type) {
+	case *ast.IfStatement:
+		conditions = append(conditions, n.Condition)
+		for _, clause := range n.ElseIfClauses {
+			conditions = append(conditions, clause.Condition)
+		}
+	case *ast.IfExpression:
+		conditions = append(conditions, n.Condition)
+		for _, clause := range n.ElseIfClauses {
+			conditions = append(conditions, clause.Condition)
+		}
+	case *ast.WhileStatement:
+		value, ok := foldConstant(n.Condition).(bool)
+		if ok && value && !ast.Breaks(n.Block.Statements) {
+			return []Issue{r.issue(n.Pos(), i18n.LintInfiniteWhile)}
+		}
+		if ok && !value {
+			return []Issue{r.issue(n.Condition.Pos(), i18n.LintConstantCondition, value)}
+		}
+	case *ast.BinaryExpression:
+		if !comparison(n.Operator) {
+			return nil
+		}
+		left, leftOk := n.Left.(*ast.Identifier)
+		right, rightOk := n.Right.(*ast.Identifier)
+		if leftOk && rightOk && left.Value == right.Value {
+			always := n.Operator == ast.BinaryOpEq || n.Operator == ast.BinaryOpLte || n.Operator == ast.BinaryOpGte
+			return []Issue{r.issue(n.Pos(), i18n.LintSelfComparison, left.Value, always)}
+		}
+	}
+	var issues []Issue
+	for _, condition := range conditions {
+		if value, ok := foldConstant(condition).(bool); ok {
+			issues = append(issues, r.issue(condition.Pos(), i18n.LintConstantCondition, value))
+		}
+	}
+	return issues
+}
+
+func (r *ConstantConditionRule) issue(pos ast.Position, id i18n.MessageID, args ...interface{}) Issue {
+	return Issue{
+		Line:     pos.Line,
+		Column:   pos.Column,
+		RuleName: r.Name(),
+		Code:     i18n.Code(id),
+		Message:  i18n.T(id, args...),
+	}
+}
+
+// comparison reports whether op compares its operands
+func comparison(op ast.BinaryOperator) bool {
+	switch op {
+	case ast.BinaryOpEq, ast.BinaryOpNotEq, ast.BinaryOpLt, ast.BinaryOpLte, ast.BinaryOpGt, ast.BinaryOpGte:
+		return true
+	}
+	return false
+}
+
+// foldConstant returns the value of e, an int, float64, string or bool, if
+// it is made of literals only, or nil. && and || are folded when their left
+// operand decides the result, like false && ready.
+func foldConstant(e ast.Expression) interface{} {
+	switch e := e.(type) {
+	case *ast.IntegerLiteral:
+		return e.Value
+	case *ast.FloatLiteral:
+		return e.Value
+	case *ast.StringLiteral:
+		return e.Value
+	case *ast.BooleanLiteral:
+		return e.Value
+	case *ast.UnaryExpression:
+		switch right := foldConstant(e.Right).(type) {
+		case bool:
+			if e.Operator == ast.UnaryOpBang {
+				return !right
+			}
+		case int:
+			if e.Operator == ast.UnaryOpMinus {
+				return -right
+			}
+		case float64:
+			if e.Operator == ast.UnaryOpMinus {
+				return -right
+			}
+		}
+	case *ast.BinaryExpression:
+		left := foldConstant(e.Left)
+		if value, ok := left.(bool); ok {
+			if (e.Operator == ast.BinaryOpAnd && !value) || (e.Operator == ast.BinaryOpOr && value) {
+				return value
+			}
+		}
+		right := foldConstant(e.Right)
+		if left == nil || right == nil {
+			return nil
+		}
+		return foldBinary(e.Operator, left, right)
+	}
+	return nil
+}
+
+// foldBinary returns the value of left op right, or nil if op does not
+// apply to them. An int operand is converted to float64 when the other is
+// one, as integer literals are where a float is expected.
+func foldBinary(op ast.BinaryOperator, left, right interface{}) interface{} {
+	if l, ok := left.(int); ok {
+		if _, ok := right.(float64); ok {
+			left = float64(l)
+		}
+	}
+	if r, ok := right.(int); ok {
+		if _, ok := left.(float64); ok {
+			right = float64(r)
+		}
+	}
+	switch l := left.(type) {
+	case int:
+		r, ok := right.(int)
+		if !ok {
+			return nil
+		}
+		switch op {
+		case ast.BinaryOpPlus:
+			return l + r
+		case ast.BinaryOpMinus:
+			return l - r
+		case ast.BinaryOpMultiply:
+			return l * r
+		case ast.BinaryOpDivide:
+			if r != 0 {
+				return l / r
+			}
+		case ast.BinaryOpModulo:
+			if r != 0 {
+				return l % r
+			}
+		}
+		return compareResult(op, cmp.Compare(l, r))
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			return nil
+		}
+		switch op {
+		case ast.BinaryOpPlus:
+			return l + r
+		case ast.BinaryOpMinus:
+			return l - r
+		case ast.BinaryOpMultiply:
+			return l * r
+		case ast.BinaryOpDivide:
+			if r != 0 {
+				return l / r
+			}
+		}
+		return compareResult(op, cmp.Compare(l, r))
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return nil
+		}
+		if op == ast.BinaryOpPlus {
+			return l + r
+		}
+		return compareResult(op, cmp.Compare(l, r))
+	case bool:
+		r, ok := right.(bool)
+		if !ok {
+			return nil
+		}
+		switch op {
+		case ast.BinaryOpAnd:
+			return l && r
+		case ast.BinaryOpOr:
+			return l || r
+		case ast.BinaryOpEq:
+			return l == r
+		case ast.BinaryOpNotEq:
+			return l != r
+		}
+	}
+	return nil
+}
+
+// compareResult returns the value of a comparison op of two values, from
+// their order c, as cmp.Compare returns it, or nil if op is not a
+// comparison
+func compareResult(op ast.BinaryOperator, c int) interface{} {
+	switch op {
+	case ast.BinaryOpEq:
+		return c == 0
+	case ast.BinaryOpNotEq:
+		return c != 0
+	case ast.BinaryOpLt:
+		return c < 0
+	case ast.BinaryOpLte:
+		return c <= 0
+	case ast.BinaryOpGt:
+		return c > 0
+	case ast.BinaryOpGte:
+		return c >= 0
+	}
+	return nil
+}
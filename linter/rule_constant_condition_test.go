@@ -0,0 +1,42 @@
+package linter
+
+import (
+	"testing"
+
+	"github.com/linkalls/zeno-lang/lexer"
+	"github.com/linkalls/zeno-lang/parser"
+)
+
+// lint parses source and returns the issues rule reports on it
+func lint(t *testing.T, rule Rule, source string) []Issue {
+	t.Helper()
+	p := parser.New(lexer.NewWithComments(source))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("parser errors for %q: %v", source, p.Errors())
+	}
+	issues, err := NewLinter([]Rule{rule}).Lint(program, "test.zeno")
+	if err != nil {
+		t.Fatalf("lint error for %q: %v", source, err)
+	}
+	return issues
+}
+
+func TestConstantConditionInfiniteWhile(t *testing.T) {
+	tests := []struct {
+		source   string
+		reported bool
+	}{
+		{"fn f() {\n    while true {\n        println(1)\n    }\n}", true},
+		{"fn f() {\n    while true {\n        break\n    }\n}", false},
+		{"fn f() {\n    while true {\n        loop {\n            break\n        }\n    }\n}", true},
+		{"fn f(ch: Channel<int>) {\n    while true {\n        when {\n            v from ch => {\n                break\n            },\n        }\n    }\n}", false},
+		{"fn f(x: int) {\n    while true {\n        match x {\n            1 => {\n                break\n            },\n            _ => {},\n        }\n    }\n}", false},
+	}
+	for _, tt := range tests {
+		issues := lint(t, &ConstantConditionRule{}, tt.source)
+		if reported := len(issues) > 0; reported != tt.reported {
+			t.Errorf("%q: reported %v, want %v (%v)", tt.source, reported, tt.reported, issues)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package linter
+
+import (
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+	"github.com/linkalls/zeno-lang/stdlib"
+)
+
+// DeprecatedUsageRule (L7)
+// Forbids calls to functions marked @deprecated, whether defined in the
+// same file or imported from the standard library.
+type DeprecatedUsageRule struct{}
+
+func (r *DeprecatedUsageRule) Name() string {
+	return "no-deprecated"
+}
+
+func (r *DeprecatedUsageRule) Description() string {
+	return "Forbids calls to functions marked @deprecated."
+}
+
+func (r *DeprecatedUsageRule) Check(node ast.Node, program *ast.Program) []Issue {
+	if program == nil {
+		return nil
+	}
+	call, ok := node.(*ast.FunctionCall)
+	if method, isMethod := node.(*ast.MethodCallExpression); isMethod {
+		// io.readFile() calls a function of the module imported as io
+		_, call, ok = method.QualifiedCall()
+	}
+	if !ok {
+		return nil
+	}
+	def := findCalledFunction(call.Name, program)
+	if def == nil || def.Deprecated == nil {
+		return nil
+	}
+	message := i18n.T(i18n.LintDeprecatedUsage, call.Name)
+	if def.Deprecated.Message != "" {
+		message = i18n.T(i18n.LintDeprecatedUsageHint, call.Name, def.Deprecated.Message)
+	}
+	return []Issue{{
+		Line:     call.Line,
+		Column:   call.Column,
+		RuleName: r.Name(),
+		Code:     i18n.Code(i18n.LintDeprecatedUsage),
+		Message:  message,
+	}}
+}
+
+// findCalledFunction resolves a call to a function defined in program or
+// imported into it from a standard library module, which may be called as
+// io.readFile when the module is imported as io.
+func findCalledFunction(name string, program *ast.Program) *ast.FunctionDefinition {
+	for _, stmt := range program.Statements {
+		switch s := stmt.(type) {
+		case *ast.FunctionDefinition:
+			if s.Name == name {
+				return s
+			}
+		case *ast.ImportStatement:
+			if !strings.HasPrefix(s.Module, "std/") {
+				continue
+			}
+			if function, ok := strings.CutPrefix(name, s.Namespace+"."); ok && s.Namespace != "" {
+				return stdlib.Function(s.Module, function)
+			}
+			for _, imp := range s.Imports {
+				if imp.LocalName() == name {
+					return stdlib.Function(s.Module, imp.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package linter
+
+import (
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+)
+
+// Limiter is implemented by the rules reporting the functions that go over
+// a limit, which the max key of their table in the configuration sets:
+//
+//	[rules.function-complexity]
+//	max = 10
+type Limiter interface {
+	Rule
+	// Max returns the limit of the rule, the default one unless WithMax set
+	// it
+	Max() int
+	// WithMax returns the rule with the limit max
+	WithMax(max int) Rule
+}
+
+// The default limits of the rules of this file
+const (
+	defaultMaxComplexity = 15
+	defaultMaxLength     = 80
+	defaultMaxParameters = 5
+)
+
+// FunctionComplexityRule (L12)
+// Reports the functions whose cyclomatic complexity, the number of paths
+// through them, is over the limit. It is one plus a count of each if, else
+// if, loop, match or when arm other than _, catch, ? and the operators &&
+// and ||.
+type FunctionComplexityRule struct {
+	limit int
+}
+
+func (r *FunctionComplexityRule) Name() string {
+	return "function-complexity"
+}
+
+func (r *FunctionComplexityRule) Description() string {
+	return "Detects functions whose cyclomatic complexity is over the limit."
+}
+
+func (r *FunctionComplexityRule) Max() int {
+	if r.limit > 0 {
+		return r.limit
+	}
+	return defaultMaxComplexity
+}
+
+func (r *FunctionComplexityRule) WithMax(max int) Rule {
+	return &FunctionComplexityRule{limit: max}
+}
+
+func (r *FunctionComplexityRule) Check(node ast.Node, program *ast.Program) []Issue {
+	var issues []Issue
+	for _, fn := range functions(node) {
+		if complexity := complexity(fn); complexity > r.Max() {
+			issues = append(issues, limitIssue(r, fn, i18n.LintFunctionComplexity, complexity))
+		}
+	}
+	return issues
+}
+
+// complexity returns the cyclomatic complexity of fn
+func complexity(fn *ast.FunctionDefinition) int {
+	complexity := 1
+	for _, stmt := range fn.Body {
+		Inspect(stmt, func(node ast.Node) {
+			switch n := node.(type) {
+			case *ast.IfStatement:
+				complexity += 1 + len(n.ElseIfClauses)
+			case *ast.IfExpression:
+				complexity += 1 + len(n.ElseIfClauses)
+			case *ast.WhileStatement, *ast.LoopStatement, *ast.ForStatement, *ast.TryStatement, *ast.TryExpression:
+				complexity++
+			case *ast.MatchExpression:
+				for i := range n.Arms {
+					if !n.Arms[i].IsWildcard() {
+						complexity++
+					}
+				}
+			case *ast.WhenStatement:
+				for i := range n.Arms {
+					if !n.Arms[i].IsDefault() {
+						complexity++
+					}
+				}
+			case *ast.BinaryExpression:
+				if n.Operator == ast.BinaryOpAnd || n.Operator == ast.BinaryOpOr {
+					complexity++
+				}
+			}
+		})
+	}
+	return complexity
+}
+
+// FunctionLengthRule (L13)
+// Reports the functions whose body, between its braces, takes more lines
+// than the limit. Blank lines and comments count.
+type FunctionLengthRule struct {
+	limit int
+}
+
+func (r *FunctionLengthRule) Name() string {
+	return "function-length"
+}
+
+func (r *FunctionLengthRule) Description() string {
+	return "Detects functions whose body has more lines than the limit."
+}
+
+func (r *FunctionLengthRule) Max() int {
+	if r.limit > 0 {
+		return r.limit
+	}
+	return defaultMaxLength
+}
+
+func (r *FunctionLengthRule) WithMax(max int) Rule {
+	return &FunctionLengthRule{limit: max}
+}
+
+func (r *FunctionLengthRule) Check(node ast.Node, program *ast.Program) []Issue {
+	var issues []Issue
+	for _, fn := range functions(node) {
+		if length := fn.Rbrace.Line - fn.Lbrace.Line - 1; length > r.Max() {
+			issues = append(issues, limitIssue(r, fn, i18n.LintFunctionLength, length))
+		}
+	}
+	return issues
+}
+
+// FunctionParametersRule (L14)
+// Reports the functions taking more parameters than the limit, which are
+// hard to call correctly; passing a struct names the values instead.
+type FunctionParametersRule struct {
+	limit int
+}
+
+func (r *FunctionParametersRule) Name() string {
+	return "function-parameters"
+}
+
+func (r *FunctionParametersRule) Description() string {
+	return "Detects functions taking more parameters than the limit."
+}
+
+func (r *FunctionParametersRule) Max() int {
+	if r.limit > 0 {
+		return r.limit
+	}
+	return defaultMaxParameters
+}
+
+func (r *FunctionParametersRule) WithMax(max int) Rule {
+	return &FunctionParametersRule{limit: max}
+}
+
+func (r *FunctionParametersRule) Check(node ast.Node, program *ast.Program) []Issue {
+	var issues []Issue
+	for _, fn := range functions(node) {
+		if count := len(fn.Parameters); count > r.Max() {
+			issues = append(issues, limitIssue(r, fn, i18n.LintFunctionParameters, count))
+		}
+	}
+	return issues
+}
+
+// functions returns the function node defines: itself for a function, and
+// its methods for an impl, which Walk does not visit as functions
+func functions(node ast.Node) []*ast.FunctionDefinition {
+	switch n := node.(type) {
+	case *ast.FunctionDefinition:
+		return []*ast.FunctionDefinition{n}
+	case *ast.ImplDeclaration:
+		return n.Methods
+	}
+	return nil
+}
+
+// limitIssue returns the issue of rule for fn, whose measure is value
+func limitIssue(rule Limiter, fn *ast.FunctionDefinition, id i18n.MessageID, value int) Issue {
+	return Issue{
+		Line:     fn.Line,
+		Column:   fn.Column,
+		RuleName: rule.Name(),
+		Code:     i18n.Code(id),
+		Message:  i18n.T(id, fn.Name, value, rule.Max()),
+	}
+}
@@ -0,0 +1,40 @@
+package linter
+
+import (
+	"testing"
+
+	"github.com/linkalls/zeno-lang/lexer"
+	"github.com/linkalls/zeno-lang/parser"
+)
+
+func TestFunctionLimitMax(t *testing.T) {
+	source := "fn f(a: int, b: int, c: int): int {\n    return a + b + c\n}"
+	program := parser.New(lexer.New(source)).ParseProgram()
+	tests := []struct {
+		config   string
+		reported bool
+	}{
+		{"", false},
+		{"[rules.function-parameters]\nmax = 2", true},
+		{"[rules.function-parameters]\nmax = 3", false},
+	}
+	for _, tt := range tests {
+		config, err := ParseConfig(ConfigFile, tt.config)
+		if err != nil {
+			t.Fatalf("ParseConfig(%q): %v", tt.config, err)
+		}
+		l := NewLinter([]Rule{&FunctionParametersRule{}})
+		l.SetConfig(config)
+		issues, err := l.Lint(program, "test.zeno")
+		if err != nil {
+			t.Fatalf("Lint: %v", err)
+		}
+		if reported := len(issues) > 0; reported != tt.reported {
+			t.Errorf("max from %q: reported %v, want %v (%v)", tt.config, reported, tt.reported, issues)
+		}
+	}
+
+	if _, err := ParseConfig(ConfigFile, "[rules.unused-import]\nmax = 2"); err == nil {
+		t.Errorf("expected an error for max on a rule without a limit")
+	}
+}
@@ -0,0 +1,118 @@
+package linter
+
+import (
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+)
+
+// MatchExhaustiveRule (L8)
+// Warns about match expressions that do not cover every value of their
+// subject. Booleans are covered by a true and a false arm, Options by a
+// some and a none arm, enums declared in the file by an arm for each
+// variant; other values need a `_` arm.
+type MatchExhaustiveRule struct{}
+
+func (r *MatchExhaustiveRule) Name() string {
+	return "match-exhaustive"
+}
+
+func (r *MatchExhaustiveRule) Description() string {
+	return "Warns about match expressions that do not handle every value."
+}
+
+func (r *MatchExhaustiveRule) Check(node ast.Node, program *ast.Program) []Issue {
+	match, ok := node.(*ast.MatchExpression)
+	if !ok || len(match.Arms) == 0 {
+		return nil
+	}
+	for _, arm := range match.Arms {
+		if arm.IsWildcard() {
+			return nil
+		}
+	}
+
+	issue := Issue{
+		Line:     match.Line,
+		Column:   match.Column,
+		RuleName: r.Name(),
+		Code:     i18n.Code(i18n.LintMatchNotExhaustive),
+		Message:  i18n.T(i18n.LintMatchNotExhaustive),
+	}
+	if cases, ok := matchCases(match, program); ok {
+		var missing []string
+		for _, c := range cases {
+			if !matchesCase(match, c) {
+				missing = append(missing, c)
+			}
+		}
+		if len(missing) == 0 {
+			return nil
+		}
+		issue.Message = i18n.T(i18n.LintMatchMissingCases, strings.Join(missing, ", "))
+	}
+	return []Issue{issue}
+}
+
+// matchCases returns every case of the subject of match when they can be
+// listed: true and false when the first pattern is a boolean, the variants
+// of the enum when it names one, some and none when it matches an Option
+func matchCases(match *ast.MatchExpression, program *ast.Program) ([]string, bool) {
+	first := match.Arms[0].Pattern
+	if _, ok := first.(*ast.BooleanLiteral); ok {
+		return []string{"true", "false"}, true
+	}
+	name := patternName(first)
+	if decl := enumOfVariant(name, program); decl != nil {
+		cases := make([]string, len(decl.Variants))
+		for i, variant := range decl.Variants {
+			cases[i] = variant.Name
+		}
+		return cases, true
+	}
+	if name == "some" || name == "none" {
+		return []string{"some", "none"}, true
+	}
+	return nil, false
+}
+
+// matchesCase reports whether an arm of match handles the case named c
+func matchesCase(match *ast.MatchExpression, c string) bool {
+	for _, arm := range match.Arms {
+		if b, ok := arm.Pattern.(*ast.BooleanLiteral); ok && b.String() == c {
+			return true
+		}
+		if patternName(arm.Pattern) == c {
+			return true
+		}
+	}
+	return false
+}
+
+// patternName returns the variant a pattern names: Empty or Circle(r)
+func patternName(pattern ast.Expression) string {
+	switch p := pattern.(type) {
+	case *ast.Identifier:
+		return p.Value
+	case *ast.FunctionCall:
+		return p.Name
+	}
+	return ""
+}
+
+// enumOfVariant returns the enum declared in program that has a variant
+// called name
+func enumOfVariant(name string, program *ast.Program) *ast.EnumDeclaration {
+	if name == "" || program == nil {
+		return nil
+	}
+	for _, stmt := range program.Statements {
+		if decl, ok := stmt.(*ast.EnumDeclaration); ok {
+			if _, ok := decl.Variant(name); ok {
+				return decl
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package linter
+
+import (
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+	"github.com/linkalls/zeno-lang/stdlib"
+)
+
+// MissingImportRule (L6)
+// Detects calls to standard library functions that are not imported and
+// offers the import statement as a fix.
+type MissingImportRule struct{}
+
+func (r *MissingImportRule) Name() string {
+	return "missing-import"
+}
+
+func (r *MissingImportRule) Description() string {
+	return "Detects calls to standard library functions that are not imported."
+}
+
+func (r *MissingImportRule) Check(node ast.Node, program *ast.Program) []Issue {
+	call, ok := node.(*ast.FunctionCall)
+	if !ok || program == nil {
+		return nil
+	}
+	// print, println, the Result constructors, and close and panic, which
+	// are builtins unless the functions of std/db and std/fmt are imported,
+	// are accepted by the compiler without an import
+	switch call.Name {
+	case "print", "println", "ok", "err", "close", "panic":
+		return nil
+	}
+	for _, stmt := range program.Statements {
+		switch s := stmt.(type) {
+		case *ast.FunctionDefinition:
+			if s.Name == call.Name {
+				return nil
+			}
+		case *ast.EnumDeclaration:
+			if _, ok := s.Variant(call.Name); ok {
+				return nil
+			}
+		case *ast.ImportStatement:
+			for _, imp := range s.Imports {
+				if imp.LocalName() == call.Name {
+					return nil
+				}
+			}
+		}
+	}
+
+	modules := stdlib.ModulesExporting(call.Name)
+	if len(modules) == 0 {
+		return nil
+	}
+	importLine := stdlib.ImportLine(modules[0], call.Name)
+	return []Issue{{
+		Line:     call.Line,
+		Column:   call.Column,
+		RuleName: r.Name(),
+		Code:     i18n.Code(i18n.LintMissingImport),
+		Message:  i18n.T(i18n.LintMissingImport, call.Name, modules[0]),
+		Fix: &Fix{
+			Description: i18n.T(i18n.GenHintAddImport, importLine),
+			Edits:       []Edit{insertLine(1, importLine)},
+		},
+	}}
+}
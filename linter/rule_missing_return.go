@@ -0,0 +1,38 @@
+package linter
+
+import (
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+)
+
+// MissingReturnRule (L10)
+// Reports the functions with a return type that can end without returning
+// a value, at the statement or the closing brace the path leaves by, as the
+// type checker does when compiling.
+type MissingReturnRule struct{}
+
+func (r *MissingReturnRule) Name() string {
+	return "missing-return"
+}
+
+func (r *MissingReturnRule) Description() string {
+	return "Detects functions with a return type that can end without returning a value."
+}
+
+func (r *MissingReturnRule) Check(node ast.Node, program *ast.Program) []Issue {
+	fn, ok := node.(*ast.FunctionDefinition)
+	if !ok || fn.ReturnType == nil || *fn.ReturnType == "void" {
+		return nil
+	}
+	fallThrough, ok := ast.FindFallThrough(fn.Body, fn.Rbrace)
+	if !ok {
+		return nil
+	}
+	return []Issue{{
+		Line:     fallThrough.Line,
+		Column:   fallThrough.Column,
+		RuleName: r.Name(),
+		Code:     i18n.Code(i18n.LintMissingReturn),
+		Message:  i18n.T(i18n.LintMissingReturn, fn.Name),
+	}}
+}
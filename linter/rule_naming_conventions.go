@@ -1,24 +1,27 @@
 package linter
 
 import (
-	"fmt"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+	"github.com/linkalls/zeno-lang/token"
 )
 
 // --- Helper Functions for Case Checking ---
 
 // isLowerCamelCase checks if s is valid lowerCamelCase.
-// e.g., myVariable, anotherOne.
+// e.g., myVariable, anotherOne, or 名前, as letters without case are
+// neither lower nor upper case.
 // Simple initial check: starts with lowercase, no underscores/hyphens.
 func isLowerCamelCase(s string) bool {
 	if len(s) == 0 {
 		return false // Empty string is not valid lowerCamelCase
 	}
-	firstChar := rune(s[0])
-	if !(unicode.IsLower(firstChar) && unicode.IsLetter(firstChar)) {
+	firstChar, _ := utf8.DecodeRuneInString(s)
+	if !(unicode.IsLetter(firstChar) && !unicode.IsUpper(firstChar)) {
 		return false
 	}
 	// Allow digits after the first character, but no underscores or hyphens.
@@ -27,14 +30,14 @@ func isLowerCamelCase(s string) bool {
 }
 
 // isUpperCamelCase checks if s is valid UpperCamelCase.
-// e.g., MyFunction, AnotherOne.
+// e.g., MyFunction, AnotherOne, or 挨拶.
 // Simple initial check: starts with uppercase, no underscores/hyphens.
 func isUpperCamelCase(s string) bool {
 	if len(s) == 0 {
 		return false // Empty string is not valid UpperCamelCase
 	}
-	firstChar := rune(s[0])
-	if !(unicode.IsUpper(firstChar) && unicode.IsLetter(firstChar)) {
+	firstChar, _ := utf8.DecodeRuneInString(s)
+	if !(unicode.IsLetter(firstChar) && !unicode.IsLower(firstChar)) {
 		return false
 	}
 	// Allow digits after the first character, but no underscores or hyphens.
@@ -62,8 +65,9 @@ func (r *FunctionNameRule) Check(node ast.Node, program *ast.Program) []Issue {
 		return issues // Not a function definition, skip
 	}
 
-	// Skip "main" function from this rule, as it's a special case.
-	if fnDef.Name == "main" {
+	// Skip "main" function from this rule, as it's a special case, and the
+	// tests run by zeno test, whose names start with test_.
+	if fnDef.Name == "main" || strings.HasPrefix(fnDef.Name, "test_") {
 		return issues
 	}
 
@@ -71,25 +75,39 @@ func (r *FunctionNameRule) Check(node ast.Node, program *ast.Program) []Issue {
 		if !isUpperCamelCase(fnDef.Name) {
 			issues = append(issues, Issue{
 				// Filepath will be set by the Linter's visitor
-				Line:     0, // Placeholder - AST nodes need line/col info
-				Column:   0, // Placeholder
+				Line:     fnDef.Line,
+				Column:   fnDef.Column,
 				RuleName: r.Name(),
-				Message:  fmt.Sprintf("Public function '%s' should be in UpperCamelCase (e.g., MyFunction).", fnDef.Name),
+				Code:     i18n.Code(i18n.LintPublicFunctionName),
+				Message:  i18n.T(i18n.LintPublicFunctionName, fnDef.Name),
+				Symbol:   fnDef.Name,
 			})
 		}
 	} else { // Private function
-		if !isLowerCamelCase(fnDef.Name) {
+		// A leading underscore marks a function as intentionally unused
+		if !isLowerCamelCase(strings.TrimPrefix(fnDef.Name, "_")) {
 			issues = append(issues, Issue{
-				Line:     0, // Placeholder
-				Column:   0, // Placeholder
+				Line:     fnDef.Line,
+				Column:   fnDef.Column,
 				RuleName: r.Name(),
-				Message:  fmt.Sprintf("Private function '%s' should be in lowerCamelCase (e.g., myFunction).", fnDef.Name),
+				Code:     i18n.Code(i18n.LintPrivateFunctionName),
+				Message:  i18n.T(i18n.LintPrivateFunctionName, fnDef.Name),
+				Symbol:   fnDef.Name,
 			})
 		}
 	}
 	return issues
 }
 
+// Fix renames a private function to its name in lowerCamelCase. Public
+// functions are not renamed, since other modules import them by name.
+func (r *FunctionNameRule) Fix(issue Issue, src *Source) *Fix {
+	if issue.Code != i18n.Code(i18n.LintPrivateFunctionName) {
+		return nil
+	}
+	return renameFix(src, issue.Symbol, camelCase(issue.Symbol, false))
+}
+
 // VariableNameRule (L4)
 // Ensures 'let' declared variables are lowerCamelCase.
 type VariableNameRule struct{}
@@ -109,17 +127,81 @@ func (r *VariableNameRule) Check(node ast.Node, program *ast.Program) []Issue {
 		return issues // Not a let declaration, skip
 	}
 
-	if letDecl.Name == "_" { // Conventionally ignored variables
-		return issues
+	for _, name := range letDecl.BoundNames() {
+		if name == "_" { // Conventionally ignored variables
+			continue
+		}
+		// A leading underscore marks a variable as intentionally unused
+		if !isLowerCamelCase(strings.TrimPrefix(name, "_")) {
+			issues = append(issues, Issue{
+				Line:     letDecl.Line,
+				Column:   letDecl.Column,
+				RuleName: r.Name(),
+				Code:     i18n.Code(i18n.LintVariableName),
+				Message:  i18n.T(i18n.LintVariableName, name),
+				Symbol:   name,
+			})
+		}
 	}
+	return issues
+}
+
+// Fix renames the variable to its name in lowerCamelCase.
+func (r *VariableNameRule) Fix(issue Issue, src *Source) *Fix {
+	return renameFix(src, issue.Symbol, camelCase(issue.Symbol, false))
+}
 
-	if !isLowerCamelCase(letDecl.Name) {
-		issues = append(issues, Issue{
-			Line:     0, // Placeholder
-			Column:   0, // Placeholder
-			RuleName: r.Name(),
-			Message:  fmt.Sprintf("Variable '%s' should be in lowerCamelCase (e.g., myVariable).", letDecl.Name),
-		})
+// camelCase returns name, whose words are separated by _ or -, in
+// lowerCamelCase, or UpperCamelCase if upper, keeping a leading _
+func camelCase(name string, upper bool) string {
+	prefix := ""
+	if strings.HasPrefix(name, "_") {
+		prefix, name = "_", strings.TrimLeft(name, "_")
 	}
-	return issues
+	var builder strings.Builder
+	for i, word := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' }) {
+		r, size := utf8.DecodeRuneInString(word)
+		if i == 0 && !upper {
+			r = unicode.ToLower(r)
+		} else {
+			r = unicode.ToUpper(r)
+		}
+		builder.WriteRune(r)
+		builder.WriteString(word[size:])
+	}
+	return prefix + builder.String()
+}
+
+// renameFix returns the fix renaming the identifier name to newName in the
+// whole file, or nil if that is not safe: when newName is not in
+// lowerCamelCase or is already used, or when name is also the name of a
+// field or of an imported item, which the rename would change
+func renameFix(src *Source, name, newName string) *Fix {
+	if !isLowerCamelCase(strings.TrimPrefix(newName, "_")) || len(src.identifiers(newName)) > 0 {
+		return nil
+	}
+	fix := &Fix{Description: i18n.T(i18n.LintFixRename, name, newName)}
+	importLine := 0
+	for i, tok := range src.Tokens {
+		if tok.Type == token.IMPORT {
+			importLine = tok.Line
+		}
+		if tok.Type != token.IDENT || tok.Literal != name {
+			continue
+		}
+		var previous, next token.TokenType
+		if i > 0 {
+			previous = src.Tokens[i-1].Type
+		}
+		if i+1 < len(src.Tokens) {
+			next = src.Tokens[i+1].Type
+		}
+		field := previous == token.DOT ||
+			(next == token.COLON && (previous == token.LBRACE || previous == token.COMMA || previous == token.LPAREN))
+		if field || tok.Line == importLine {
+			return nil
+		}
+		fix.Edits = append(fix.Edits, Edit{Start: start(tok), End: src.end(tok), Text: newName})
+	}
+	return fix
 }
@@ -0,0 +1,85 @@
+package linter
+
+import (
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+)
+
+// UnreachableCodeRule (L9)
+// Reports the first statement of a block, or of the body of a function,
+// that follows a return, break or continue, or an if whose branches, else
+// included, all end with one.
+type UnreachableCodeRule struct{}
+
+func (r *UnreachableCodeRule) Name() string {
+	return "unreachable-code"
+}
+
+func (r *UnreachableCodeRule) Description() string {
+	return "Detects statements that can never run because the ones before them always return, break or continue."
+}
+
+func (r *UnreachableCodeRule) Check(node ast.Node, program *ast.Program) []Issue {
+	var statements []ast.Statement
+	switch n := node.(type) {
+	case *ast.Block:
+		statements = n.Statements
+	case *ast.FunctionDefinition:
+		statements = n.Body
+	default:
+		return nil
+	}
+	for i := 0; i+1 < len(statements); i++ {
+		keyword, ok := leavesBlock(statements[i])
+		if !ok {
+			continue
+		}
+		next := statements[i+1].Pos()
+		return []Issue{{
+			Line:     next.Line,
+			Column:   next.Column,
+			RuleName: r.Name(),
+			Code:     i18n.Code(i18n.LintUnreachableCode),
+			Message:  i18n.T(i18n.LintUnreachableCode, keyword, statements[i].Pos().Line),
+		}}
+	}
+	return nil
+}
+
+// leavesBlock reports whether stmt always leaves the block holding it, and
+// returns its keyword
+func leavesBlock(stmt ast.Statement) (string, bool) {
+	switch s := stmt.(type) {
+	case *ast.ReturnStatement:
+		return "return", true
+	case *ast.BreakStatement:
+		return "break", true
+	case *ast.ContinueStatement:
+		return "continue", true
+	case *ast.IfStatement:
+		if s.ElseBlock == nil || !blockLeaves(s.ThenBlock) || !blockLeaves(s.ElseBlock) {
+			return "", false
+		}
+		for _, clause := range s.ElseIfClauses {
+			if !blockLeaves(clause.Block) {
+				return "", false
+			}
+		}
+		return "if", true
+	}
+	return "", false
+}
+
+// blockLeaves reports whether one of the statements of block always leaves
+// it
+func blockLeaves(block *ast.Block) bool {
+	if block == nil {
+		return false
+	}
+	for _, stmt := range block.Statements {
+		if _, ok := leavesBlock(stmt); ok {
+			return true
+		}
+	}
+	return false
+}
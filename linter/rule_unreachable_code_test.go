@@ -0,0 +1,21 @@
+package linter
+
+import "testing"
+
+func TestUnreachableCode(t *testing.T) {
+	tests := []struct {
+		source   string
+		reported bool
+	}{
+		{"fn f(): int {\n    return 1\n    println(2)\n}", true},
+		{"fn f() {\n    while true {\n        break\n        println(2)\n    }\n}", true},
+		{"fn f(x: bool): int {\n    if x {\n        return 1\n    }\n    return 2\n}", false},
+		{"fn f(x: bool): int {\n    if x {\n        return 1\n    } else {\n        return 2\n    }\n    println(3)\n}", true},
+	}
+	for _, tt := range tests {
+		issues := lint(t, &UnreachableCodeRule{}, tt.source)
+		if reported := len(issues) > 0; reported != tt.reported {
+			t.Errorf("%q: reported %v, want %v (%v)", tt.source, reported, tt.reported, issues)
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package linter
 
 import (
-	"fmt"
+	"strings"
+
 	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/i18n"
+	"github.com/linkalls/zeno-lang/token"
 )
 
 // UnusedVariableRule (L1)
@@ -33,37 +36,56 @@ func (r *UnusedVariableRule) PostCheck(
 	issues := []Issue{}
 
 	for varName, declNode := range declaredVars {
-		if varName == "_" { // The underscore variable is conventionally ignored for unused checks
+		if strings.HasPrefix(varName, "_") { // Names starting with an underscore are intentionally unused
 			continue
 		}
 		if !usedVars[varName] {
-			// TODO: Extract Line/Column from declNode once AST nodes support position info.
-			// For now, using placeholder 0.
-			line := 0
-			column := 0
-			// TODO: Extract Line/Column from declNode once AST nodes support position info.
-            // The following is to "use" declNode to prevent compiler errors about unused variables
-            // until proper position extraction from AST nodes is implemented.
-            if declNode != nil {
-                _ = declNode // Explicitly mark as used for now
-            }
-			// if declNodeWithPos, ok := declNode.(ast.PositionalNode); ok { // Hypothetical interface
-			// 	line = declNodeWithPos.Line()
-			// 	column = declNodeWithPos.Column()
-			// }
+			var pos ast.Position
+			if declNode != nil {
+				pos = declNode.Pos()
+			}
 
 			issues = append(issues, Issue{
 				Filepath: filepath,
-				Line:     line,   // Placeholder
-				Column:   column, // Placeholder
+				Line:     pos.Line,
+				Column:   pos.Column,
 				RuleName: r.Name(),
-				Message:  fmt.Sprintf("Variable '%s' is declared but not used.", varName),
+				Code:     i18n.Code(i18n.LintUnusedVariable),
+				Message:  i18n.T(i18n.LintUnusedVariable, varName),
+				Symbol:   varName,
 			})
 		}
 	}
 	return issues
 }
 
+// Fix prefixes the name of the variable with _, which marks it as unused,
+// at its declaration. It is only offered when the declaration is the only
+// place the name appears, apart from the fields of the same name.
+func (r *UnusedVariableRule) Fix(issue Issue, src *Source) *Fix {
+	newName := "_" + issue.Symbol
+	if len(src.identifiers(newName)) > 0 {
+		return nil
+	}
+	var declaration *token.Token
+	for _, i := range src.identifiers(issue.Symbol) {
+		if i > 0 && src.Tokens[i-1].Type == token.DOT {
+			continue
+		}
+		if declaration != nil {
+			return nil
+		}
+		declaration = &src.Tokens[i]
+	}
+	if declaration == nil {
+		return nil
+	}
+	return &Fix{
+		Description: i18n.T(i18n.LintFixMarkUnused, newName),
+		Edits:       []Edit{{Start: start(*declaration), End: src.end(*declaration), Text: newName}},
+	}
+}
+
 // --- UnusedImportRule (L5) ---
 
 // UnusedImportRule detects symbols imported from modules that are not used.
@@ -92,27 +114,74 @@ func (r *UnusedImportRule) PostCheck(
 
 	for symbolName, importStmtNode := range importedSymbols {
 		if !usedImportedSymbols[symbolName] {
-			line := 0
-			column := 0
-			// TODO: Extract Line/Column from importStmtNode or even more precisely
-			// from the specific symbol within the import list if AST supports it.
-			// if importStmtNodeWithPos, ok := importStmtNode.(ast.PositionalNode); ok {
-			// 	line = importStmtNodeWithPos.Line()
-			// 	column = importStmtNodeWithPos.Column()
-			// }
-
 			issues = append(issues, Issue{
 				Filepath: filepath,
-				Line:     line,   // Placeholder: Line of the import statement
-				Column:   column, // Placeholder
+				Line:     importStmtNode.Line,
+				Column:   importStmtNode.Column,
 				RuleName: r.Name(),
-				Message:  fmt.Sprintf("Imported symbol '%s' from module '%s' is not used.", symbolName, importStmtNode.Module),
+				Code:     i18n.Code(i18n.LintUnusedImport),
+				Message:  i18n.T(i18n.LintUnusedImport, symbolName, importStmtNode.Module),
+				Symbol:   symbolName,
 			})
 		}
 	}
 	return issues
 }
 
+// Fix removes the symbol from the list of the import statement, or the
+// statement if the symbol is the only one it imports.
+func (r *UnusedImportRule) Fix(issue Issue, src *Source) *Fix {
+	first := src.at(ast.Position{Line: issue.Line, Column: issue.Column})
+	if first < 0 || src.Tokens[first].Type != token.IMPORT {
+		return nil
+	}
+	last := first + 1
+	for last < len(src.Tokens) && !(src.Tokens[last].Type == token.STRING && src.Tokens[last-1].Type == token.FROM) {
+		last++
+	}
+	if last == len(src.Tokens) {
+		return nil
+	}
+	fix := &Fix{Description: i18n.T(i18n.LintFixRemoveImport, issue.Symbol)}
+
+	// The items are the tokens between the braces, separated by commas
+	var items [][2]int
+	for i := first + 1; i < last && src.Tokens[i].Type != token.RBRACE; i++ {
+		switch src.Tokens[i].Type {
+		case token.LBRACE, token.COMMA:
+			items = append(items, [2]int{i + 1, i})
+		default:
+			if len(items) > 0 {
+				items[len(items)-1][1] = i
+			}
+		}
+	}
+	// A trailing comma leaves an empty item
+	if len(items) > 0 && items[len(items)-1][1] < items[len(items)-1][0] {
+		items = items[:len(items)-1]
+	}
+	for j, item := range items {
+		if src.Tokens[item[1]].Literal != issue.Symbol {
+			continue
+		}
+		switch {
+		case len(items) == 1:
+			fix.Edits = []Edit{src.removeLines(first, last)}
+		case j < len(items)-1:
+			fix.Edits = []Edit{{Start: start(src.Tokens[item[0]]), End: start(src.Tokens[items[j+1][0]])}}
+		default:
+			fix.Edits = []Edit{{Start: src.end(src.Tokens[items[j-1][1]]), End: src.end(src.Tokens[item[1]])}}
+		}
+		return fix
+	}
+	// import * as name from "module"
+	if len(items) == 0 && src.Tokens[last-2].Type == token.IDENT && src.Tokens[last-2].Literal == issue.Symbol {
+		fix.Edits = []Edit{src.removeLines(first, last)}
+		return fix
+	}
+	return nil
+}
+
 // --- UnusedFunctionRule (L2) ---
 
 // UnusedFunctionRule detects non-public functions that are defined but not used.
@@ -142,26 +211,20 @@ func (r *UnusedFunctionRule) PostCheck(
 		// The logic to only add non-public, non-main functions to declaredFns
 		// will be in the visitor's VisitFunctionDefinition.
 		// Here we assume declaredFns contains only the functions we care about (non-public, non-main).
-		if !calledFns[fnName] {
-			line := 0
-			column := 0
-			// TODO: Extract Line/Column from fnDefNode once AST nodes support position info.
-            // The following is to "use" fnDefNode to prevent compiler errors about unused variables
-            // until proper position extraction from AST nodes is implemented.
-            if fnDefNode != nil {
-                _ = fnDefNode // Explicitly mark as used for now
-            }
-			// if fnDefNodeWithPos, ok := fnDefNode.(ast.PositionalNode); ok { // Hypothetical interface fully removed
-			// 	 line = fnDefNodeWithPos.Line()
-			// 	 column = fnDefNodeWithPos.Column()
-			// }
+		// Tests, whose names start with test_, are called by zeno test
+		if !calledFns[fnName] && !strings.HasPrefix(fnName, "_") && !strings.HasPrefix(fnName, "test_") {
+			var pos ast.Position
+			if fnDefNode != nil {
+				pos = fnDefNode.Pos()
+			}
 
 			issues = append(issues, Issue{
 				Filepath: filepath,
-				Line:     line,   // Placeholder
-				Column:   column, // Placeholder
+				Line:     pos.Line,
+				Column:   pos.Column,
 				RuleName: r.Name(),
-				Message:  fmt.Sprintf("Function '%s' is defined but not used.", fnName),
+				Code:     i18n.Code(i18n.LintUnusedFunction),
+				Message:  i18n.T(i18n.LintUnusedFunction, fnName),
 			})
 		}
 	}
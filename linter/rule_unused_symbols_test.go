@@ -0,0 +1,23 @@
+package linter
+
+import "testing"
+
+func TestUnusedImportTypes(t *testing.T) {
+	tests := []struct {
+		source   string
+		reported bool
+	}{
+		{"import {type Point} from \"./geo\"\nlet p = Point{x: 1, y: 2}", false},
+		{"import {type Point} from \"./geo\"\nlet ps: []Point = []", false},
+		{"import {type Point} from \"./geo\"\nfn origin(): Point {\n    return Point{x: 0, y: 0}\n}", false},
+		{"import {type Point} from \"./geo\"\nfn norm(p: Point): int {\n    return 0\n}", false},
+		{"import {type Point} from \"./geo\"\ntype Line = {\n    start: Point\n    end: Point\n}", false},
+		{"import {type Point} from \"./geo\"\nlet n = 1", true},
+	}
+	for _, tt := range tests {
+		issues := lint(t, &UnusedImportRule{}, tt.source)
+		if reported := len(issues) > 0; reported != tt.reported {
+			t.Errorf("%q: reported %v, want %v (%v)", tt.source, reported, tt.reported, issues)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package linter
+
+import (
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+)
+
+// Comments starting with suppressionPrefix turn rules off where their
+// issues are accepted:
+//
+//	// zeno-lint:disable-next-line variable-naming-convention
+//	let legacy_name = 1
+//
+//	// zeno-lint:disable unused-function, function-naming-convention
+//
+// disable-next-line applies to the line following the comment and disable
+// to the whole file. Without rule names, they turn all the rules off. The
+// rules are listed on the line of the directive, so the next lines of a
+// /* */ comment can explain why. The comments are read from
+// Program.Comments, so the program must be parsed with a lexer created by
+// lexer.NewWithComments.
+const suppressionPrefix = "zeno-lint:"
+
+// suppressions holds the rules turned off by comments, by name; the empty
+// name stands for all the rules
+type suppressions struct {
+	file  map[string]bool
+	lines map[int]map[string]bool
+}
+
+// parseSuppressions reads the suppression comments among comments
+func parseSuppressions(comments []*ast.Comment) suppressions {
+	s := suppressions{file: make(map[string]bool), lines: make(map[int]map[string]bool)}
+	for _, comment := range comments {
+		text := strings.TrimPrefix(comment.Text, "//")
+		if strings.HasPrefix(text, "/*") {
+			text = strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+		}
+		directive, ok := strings.CutPrefix(strings.TrimSpace(text), suppressionPrefix)
+		if !ok {
+			continue
+		}
+		// The rules are listed on the line of the directive; the lines after
+		// it can give the reason
+		directive, _, _ = strings.Cut(directive, "\n")
+		command, list, _ := strings.Cut(strings.TrimSpace(directive), " ")
+		rules := s.file
+		switch command {
+		case "disable":
+		case "disable-next-line":
+			// The line after the end of the comment
+			line := comment.Line + strings.Count(comment.Text, "\n") + 1
+			if s.lines[line] == nil {
+				s.lines[line] = make(map[string]bool)
+			}
+			rules = s.lines[line]
+		default:
+			continue
+		}
+		names := strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+		if len(names) == 0 {
+			names = []string{""}
+		}
+		for _, name := range names {
+			rules[name] = true
+		}
+	}
+	return s
+}
+
+// suppressed reports whether a comment turns the rule of issue off at its
+// line
+func (s suppressions) suppressed(issue Issue) bool {
+	line := s.lines[issue.Line]
+	return s.file[""] || s.file[issue.RuleName] || line[""] || line[issue.RuleName]
+}
+
+// filter returns the issues that are not suppressed
+func (s suppressions) filter(issues []Issue) []Issue {
+	if len(s.file) == 0 && len(s.lines) == 0 {
+		return issues
+	}
+	result := []Issue{}
+	for _, issue := range issues {
+		if !s.suppressed(issue) {
+			result = append(result, issue)
+		}
+	}
+	return result
+}
@@ -0,0 +1,22 @@
+package linter
+
+import "testing"
+
+func TestSuppressionComments(t *testing.T) {
+	tests := []struct {
+		source   string
+		reported bool
+	}{
+		{"let legacy_name = 1\nprintln(legacy_name)", true},
+		{"// zeno-lint:disable-next-line variable-naming-convention\nlet legacy_name = 1\nprintln(legacy_name)", false},
+		{"// zeno-lint:disable-next-line unused-variable\nlet legacy_name = 1\nprintln(legacy_name)", true},
+		{"// zeno-lint:disable\nlet legacy_name = 1\nprintln(legacy_name)", false},
+		{"/* zeno-lint:disable-next-line\n   kept for the old API */\nlet legacy_name = 1\nprintln(legacy_name)", false},
+	}
+	for _, tt := range tests {
+		issues := lint(t, &VariableNameRule{}, tt.source)
+		if reported := len(issues) > 0; reported != tt.reported {
+			t.Errorf("%q: reported %v, want %v (%v)", tt.source, reported, tt.reported, issues)
+		}
+	}
+}
@@ -14,9 +14,15 @@ type Visitor interface {
 	VisitAssignmentStatement(node *ast.AssignmentStatement) error
 	VisitExpressionStatement(node *ast.ExpressionStatement) error
 	VisitFunctionDefinition(node *ast.FunctionDefinition) error
+	VisitImplDeclaration(node *ast.ImplDeclaration) error
 	VisitReturnStatement(node *ast.ReturnStatement) error
 	VisitIfStatement(node *ast.IfStatement) error
 	VisitWhileStatement(node *ast.WhileStatement) error
+	VisitLoopStatement(node *ast.LoopStatement) error
+	VisitSpawnStatement(node *ast.SpawnStatement) error
+	VisitWhenStatement(node *ast.WhenStatement) error
+	VisitTryStatement(node *ast.TryStatement) error
+	VisitForStatement(node *ast.ForStatement) error
 	VisitBlock(node *ast.Block) error
 
 	// Expressions
@@ -30,6 +36,13 @@ type Visitor interface {
 	VisitArrayLiteral(node *ast.ArrayLiteral) error   // Added
 	VisitMapLiteral(node *ast.MapLiteral) error       // Added
 	VisitStructLiteral(node *ast.StructLiteral) error // Added
+	VisitTupleLiteral(node *ast.TupleLiteral) error
+	VisitMatchExpression(node *ast.MatchExpression) error
+	VisitIfExpression(node *ast.IfExpression) error
+	VisitTryExpression(node *ast.TryExpression) error
+	VisitIndexExpression(node *ast.IndexExpression) error
+	VisitSliceExpression(node *ast.SliceExpression) error
+	VisitMethodCallExpression(node *ast.MethodCallExpression) error
 	// Note: ast.Parameter is not typically visited standalone by this kind of walker,
 	// it's part of FunctionDefinition. Similarly for ElseIfClause.
 }
@@ -91,6 +104,19 @@ func Walk(node ast.Node, visitor Visitor) error {
 				return fmt.Errorf("in function body: %w", err)
 			}
 		}
+	case *ast.ImplDeclaration:
+		if err = visitor.VisitImplDeclaration(n); err != nil {
+			return err
+		}
+		// The methods are walked without visiting them as functions, since
+		// the operators they implement call them rather than their names
+		for _, method := range n.Methods {
+			for _, stmt := range method.Body {
+				if err = Walk(stmt, visitor); err != nil {
+					return fmt.Errorf("in impl method body: %w", err)
+				}
+			}
+		}
 	case *ast.ReturnStatement:
 		if err = visitor.VisitReturnStatement(n); err != nil {
 			return err
@@ -141,6 +167,68 @@ func Walk(node ast.Node, visitor Visitor) error {
 				return fmt.Errorf("in while block: %w", err)
 			}
 		}
+	case *ast.LoopStatement:
+		if err = visitor.VisitLoopStatement(n); err != nil {
+			return err
+		}
+		if err = Walk(n.Body, visitor); err != nil {
+			return fmt.Errorf("in loop body: %w", err)
+		}
+	case *ast.SpawnStatement:
+		if err = visitor.VisitSpawnStatement(n); err != nil {
+			return err
+		}
+		if n.Body != nil {
+			if err = Walk(n.Body, visitor); err != nil {
+				return fmt.Errorf("in spawn block: %w", err)
+			}
+		} else if err = Walk(n.Call, visitor); err != nil {
+			return fmt.Errorf("in spawn call: %w", err)
+		}
+	case *ast.TryStatement:
+		if err = visitor.VisitTryStatement(n); err != nil {
+			return err
+		}
+		if err = Walk(n.Body, visitor); err != nil {
+			return fmt.Errorf("in try block: %w", err)
+		}
+		if err = Walk(n.Catch, visitor); err != nil {
+			return fmt.Errorf("in catch block: %w", err)
+		}
+	case *ast.WhenStatement:
+		if err = visitor.VisitWhenStatement(n); err != nil {
+			return err
+		}
+		for _, arm := range n.Arms {
+			if !arm.IsDefault() {
+				if err = Walk(arm.Channel, visitor); err != nil {
+					return fmt.Errorf("in when channel: %w", err)
+				}
+				if arm.Sent != nil {
+					if err = Walk(arm.Sent, visitor); err != nil {
+						return fmt.Errorf("in when send: %w", err)
+					}
+				}
+			}
+			if arm.Block != nil {
+				err = Walk(arm.Block, visitor)
+			} else {
+				err = Walk(arm.Value, visitor)
+			}
+			if err != nil {
+				return fmt.Errorf("in when arm: %w", err)
+			}
+		}
+	case *ast.ForStatement:
+		if err = visitor.VisitForStatement(n); err != nil {
+			return err
+		}
+		if err = Walk(n.Iterable, visitor); err != nil {
+			return fmt.Errorf("in for iterable: %w", err)
+		}
+		if err = Walk(n.Body, visitor); err != nil {
+			return fmt.Errorf("in for body: %w", err)
+		}
 	case *ast.Block:
 		if err = visitor.VisitBlock(n); err != nil {
 			return err
@@ -190,6 +278,9 @@ func Walk(node ast.Node, visitor Visitor) error {
 		// The visitor's VisitArrayLiteral method is responsible for walking children (elements)
 		// and applying rules.
 		err = visitor.VisitArrayLiteral(n)
+	case *ast.TupleLiteral:
+		// Like VisitArrayLiteral, VisitTupleLiteral walks the elements
+		err = visitor.VisitTupleLiteral(n)
 	case *ast.MapLiteral:
 		// The visitor's VisitMapLiteral method is responsible for walking children (keys/values)
 		// and applying rules.
@@ -204,6 +295,89 @@ func Walk(node ast.Node, visitor Visitor) error {
 				return fmt.Errorf("in struct literal field value: %w", err)
 			}
 		}
+	case *ast.MatchExpression:
+		if err = visitor.VisitMatchExpression(n); err != nil {
+			return err
+		}
+		if err = Walk(n.Subject, visitor); err != nil {
+			return fmt.Errorf("in match subject: %w", err)
+		}
+		for _, arm := range n.Arms {
+			if arm.Pattern != nil {
+				if err = Walk(arm.Pattern, visitor); err != nil {
+					return fmt.Errorf("in match pattern: %w", err)
+				}
+			}
+			if arm.Block != nil {
+				err = Walk(arm.Block, visitor)
+			} else {
+				err = Walk(arm.Value, visitor)
+			}
+			if err != nil {
+				return fmt.Errorf("in match arm: %w", err)
+			}
+		}
+	case *ast.IfExpression:
+		if err = visitor.VisitIfExpression(n); err != nil {
+			return err
+		}
+		if err = Walk(n.Condition, visitor); err != nil {
+			return fmt.Errorf("in if condition: %w", err)
+		}
+		for _, clause := range n.ElseIfClauses {
+			if err = Walk(clause.Condition, visitor); err != nil {
+				return fmt.Errorf("in else if clause condition: %w", err)
+			}
+		}
+		for _, block := range n.Blocks() {
+			if err = Walk(block, visitor); err != nil {
+				return fmt.Errorf("in if branch: %w", err)
+			}
+		}
+	case *ast.TryExpression:
+		if err = visitor.VisitTryExpression(n); err != nil {
+			return err
+		}
+		if err = Walk(n.Value, visitor); err != nil {
+			return fmt.Errorf("in try expression: %w", err)
+		}
+	case *ast.IndexExpression:
+		if err = visitor.VisitIndexExpression(n); err != nil {
+			return err
+		}
+		if err = Walk(n.Left, visitor); err != nil {
+			return fmt.Errorf("in indexed value: %w", err)
+		}
+		if err = Walk(n.Index, visitor); err != nil {
+			return fmt.Errorf("in index: %w", err)
+		}
+	case *ast.SliceExpression:
+		if err = visitor.VisitSliceExpression(n); err != nil {
+			return err
+		}
+		if err = Walk(n.Left, visitor); err != nil {
+			return fmt.Errorf("in sliced value: %w", err)
+		}
+		for _, bound := range []ast.Expression{n.Low, n.High} {
+			if bound == nil {
+				continue
+			}
+			if err = Walk(bound, visitor); err != nil {
+				return fmt.Errorf("in slice bound: %w", err)
+			}
+		}
+	case *ast.MethodCallExpression:
+		if err = visitor.VisitMethodCallExpression(n); err != nil {
+			return err
+		}
+		if err = Walk(n.Object, visitor); err != nil {
+			return fmt.Errorf("in method receiver: %w", err)
+		}
+		for _, arg := range n.Arguments {
+			if err = Walk(arg, visitor); err != nil {
+				return fmt.Errorf("in method call argument: %w", err)
+			}
+		}
 	default:
 		// This case should ideally not be hit if all ast.Node types are covered.
 		// It implies a new AST node was added but not handled in Walk.
@@ -213,3 +387,92 @@ func Walk(node ast.Node, visitor Visitor) error {
 	}
 	return err
 }
+
+// Inspect calls f on node and on every node below it that Walk reaches, in
+// the order Walk visits them
+func Inspect(node ast.Node, f func(ast.Node)) {
+	_ = Walk(node, inspector(f))
+}
+
+// inspector is the Visitor of Inspect. Like linterVisitor, it walks the
+// elements of the literals Walk leaves to their visit method.
+type inspector func(ast.Node)
+
+func (f inspector) visit(node ast.Node) error {
+	f(node)
+	return nil
+}
+
+func (f inspector) walk(node ast.Node, children ...ast.Expression) error {
+	f(node)
+	for _, child := range children {
+		if err := Walk(child, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f inspector) VisitProgram(node *ast.Program) error { return f.visit(node) }
+func (f inspector) VisitImportStatement(node *ast.ImportStatement) error {
+	return f.visit(node)
+}
+func (f inspector) VisitLetDeclaration(node *ast.LetDeclaration) error { return f.visit(node) }
+func (f inspector) VisitAssignmentStatement(node *ast.AssignmentStatement) error {
+	return f.visit(node)
+}
+func (f inspector) VisitExpressionStatement(node *ast.ExpressionStatement) error {
+	return f.visit(node)
+}
+func (f inspector) VisitFunctionDefinition(node *ast.FunctionDefinition) error {
+	return f.visit(node)
+}
+func (f inspector) VisitImplDeclaration(node *ast.ImplDeclaration) error { return f.visit(node) }
+func (f inspector) VisitReturnStatement(node *ast.ReturnStatement) error { return f.visit(node) }
+func (f inspector) VisitIfStatement(node *ast.IfStatement) error         { return f.visit(node) }
+func (f inspector) VisitWhileStatement(node *ast.WhileStatement) error   { return f.visit(node) }
+func (f inspector) VisitLoopStatement(node *ast.LoopStatement) error     { return f.visit(node) }
+func (f inspector) VisitSpawnStatement(node *ast.SpawnStatement) error   { return f.visit(node) }
+func (f inspector) VisitWhenStatement(node *ast.WhenStatement) error     { return f.visit(node) }
+func (f inspector) VisitTryStatement(node *ast.TryStatement) error       { return f.visit(node) }
+func (f inspector) VisitForStatement(node *ast.ForStatement) error       { return f.visit(node) }
+func (f inspector) VisitBlock(node *ast.Block) error                     { return f.visit(node) }
+func (f inspector) VisitIdentifier(node *ast.Identifier) error           { return f.visit(node) }
+func (f inspector) VisitIntegerLiteral(node *ast.IntegerLiteral) error   { return f.visit(node) }
+func (f inspector) VisitStringLiteral(node *ast.StringLiteral) error     { return f.visit(node) }
+func (f inspector) VisitBooleanLiteral(node *ast.BooleanLiteral) error   { return f.visit(node) }
+func (f inspector) VisitFunctionCall(node *ast.FunctionCall) error       { return f.visit(node) }
+func (f inspector) VisitBinaryExpression(node *ast.BinaryExpression) error {
+	return f.visit(node)
+}
+func (f inspector) VisitUnaryExpression(node *ast.UnaryExpression) error {
+	return f.visit(node)
+}
+func (f inspector) VisitArrayLiteral(node *ast.ArrayLiteral) error {
+	return f.walk(node, node.Elements...)
+}
+func (f inspector) VisitTupleLiteral(node *ast.TupleLiteral) error {
+	return f.walk(node, node.Elements...)
+}
+func (f inspector) VisitMapLiteral(node *ast.MapLiteral) error {
+	var children []ast.Expression
+	for _, key := range node.OrderedKeys() {
+		children = append(children, key, node.Pairs[key])
+	}
+	return f.walk(node, children...)
+}
+func (f inspector) VisitStructLiteral(node *ast.StructLiteral) error { return f.visit(node) }
+func (f inspector) VisitMatchExpression(node *ast.MatchExpression) error {
+	return f.visit(node)
+}
+func (f inspector) VisitIfExpression(node *ast.IfExpression) error   { return f.visit(node) }
+func (f inspector) VisitTryExpression(node *ast.TryExpression) error { return f.visit(node) }
+func (f inspector) VisitIndexExpression(node *ast.IndexExpression) error {
+	return f.visit(node)
+}
+func (f inspector) VisitSliceExpression(node *ast.SliceExpression) error {
+	return f.visit(node)
+}
+func (f inspector) VisitMethodCallExpression(node *ast.MethodCallExpression) error {
+	return f.visit(node)
+}
@@ -5,14 +5,18 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/diagnostics"
+	"github.com/linkalls/zeno-lang/i18n"
 	"github.com/linkalls/zeno-lang/lexer"
 	"github.com/linkalls/zeno-lang/token"
 )
 
 // ParseError represents a detailed parser error with position information
 type ParseError struct {
+	Code       string // stable diagnostic code, e.g. Z0001
 	Message    string
 	Line       int
 	Column     int
@@ -21,16 +25,24 @@ type ParseError struct {
 	Got        string
 	Context    string
 	Suggestion string
+	Warning    bool // true for non-fatal diagnostics
 }
 
 func (e ParseError) String() string {
 	var builder strings.Builder
 
 	// Error header with position
-	builder.WriteString(fmt.Sprintf("error: %s\n", e.Message))
+	severity := i18n.T(i18n.LabelError)
+	if e.Warning {
+		severity = i18n.T(i18n.LabelWarning)
+	}
+	if e.Code != "" {
+		severity += "[" + e.Code + "]"
+	}
+	builder.WriteString(fmt.Sprintf("%s: %s\n", severity, e.Message))
 
 	if e.Line > 0 {
-		builder.WriteString(fmt.Sprintf("  --> line %d, column %d\n", e.Line, e.Column))
+		builder.WriteString(fmt.Sprintf("  --> %s\n", i18n.T(i18n.LabelLocation, e.Line, e.Column)))
 	}
 
 	// Context information
@@ -40,17 +52,42 @@ func (e ParseError) String() string {
 
 	// Expected vs Got
 	if e.Expected != "" && e.Got != "" {
-		builder.WriteString(fmt.Sprintf("   = expected %s, but got %s\n", e.Expected, e.Got))
+		builder.WriteString(fmt.Sprintf("   = %s\n", i18n.T(i18n.LabelExpectedGot, e.Expected, e.Got)))
 	}
 
 	// Suggestion
 	if e.Suggestion != "" {
-		builder.WriteString(fmt.Sprintf("help: %s\n", e.Suggestion))
+		builder.WriteString(fmt.Sprintf("%s: %s\n", i18n.T(i18n.LabelHelp), e.Suggestion))
 	}
 
 	return builder.String()
 }
 
+// Diagnostic converts the error to the representation shared by all phases
+func (e ParseError) Diagnostic(file string) diagnostics.Diagnostic {
+	d := diagnostics.Diagnostic{
+		Severity:   diagnostics.Error,
+		Code:       e.Code,
+		Source:     "parser",
+		File:       file,
+		Pos:        ast.Position{Line: e.Line, Column: e.Column},
+		Length:     utf8.RuneCountInString(e.Token.Literal),
+		Message:    e.Message,
+		Context:    e.Context,
+		Suggestion: e.Suggestion,
+	}
+	if e.Token.Type == token.STRING || e.Token.Type == token.CHAR {
+		d.Length += 2 // the quotes
+	}
+	if e.Warning {
+		d.Severity = diagnostics.Warning
+	}
+	if e.Expected != "" && e.Got != "" {
+		d.Notes = append(d.Notes, i18n.T(i18n.LabelExpectedGot, e.Expected, e.Got))
+	}
+	return d
+}
+
 // Precedence levels for operator precedence parsing
 const (
 	_ int = iota
@@ -59,8 +96,8 @@ const (
 	LOGICAL_AND // &&
 	EQUALS      // ==, !=
 	COMPARISON  // <, >, <=, >=
-	SUM         // +, -
-	PRODUCT     // *, /
+	SUM         // +, -, |, ^
+	PRODUCT     // *, /, %, &, <<, >>
 	PREFIX      // -X or !X
 	CALL        // myFunction(X)
 )
@@ -79,10 +116,18 @@ var precedences = map[token.TokenType]int{
 	token.MINUS:    SUM,
 	token.DIVIDE:   PRODUCT,
 	token.MULTIPLY: PRODUCT,
+	token.MODULO:   PRODUCT,
+	token.BIT_OR:   SUM,
+	token.BIT_XOR:  SUM,
+	token.BIT_AND:  PRODUCT,
+	token.SHL:      PRODUCT,
+	token.SHR:      PRODUCT,
 	token.LPAREN:   CALL,
 	token.LBRACE:   CALL, // For struct literals
 	// Add dot operator for property access with call-level precedence
-	token.DOT: CALL,
+	token.DOT:      CALL,
+	token.QUESTION: CALL,
+	token.LBRACKET: CALL,
 }
 
 // Parser holds the state for parsing tokens into an AST
@@ -94,6 +139,7 @@ type Parser struct {
 
 	errors         []string
 	detailedErrors []ParseError
+	warnings       []ParseError
 	filename       string
 	input          string
 
@@ -101,6 +147,15 @@ type Parser struct {
 	infixParseFns  map[token.TokenType]infixParseFn
 
 	currentUntil token.TokenType
+	// loopDepth counts the enclosing loop bodies, for break and continue
+	loopDepth int
+	// arrayElementType is the primitive element type of the array type a
+	// let annotates, e.g. FLOAT for let xs: []float, which the array
+	// literal it is set to is checked against
+	arrayElementType string
+	// comments collects COMMENT tokens from lexers created with
+	// lexer.NewWithComments
+	comments []*ast.Comment
 }
 
 type (
@@ -120,6 +175,16 @@ func tokenToBinaryOperator(literal string) ast.BinaryOperator {
 		return ast.BinaryOpDivide
 	case "%":
 		return ast.BinaryOpModulo
+	case "&":
+		return ast.BinaryOpBitAnd
+	case "|":
+		return ast.BinaryOpBitOr
+	case "^":
+		return ast.BinaryOpBitXor
+	case "<<":
+		return ast.BinaryOpShiftLeft
+	case ">>":
+		return ast.BinaryOpShiftRight
 	case "==":
 		return ast.BinaryOpEq
 	case "!=":
@@ -166,6 +231,7 @@ func New(l *lexer.Lexer) *Parser {
 		token.IDENT:    p.parseIdentifier,
 		token.INT:      p.parseIntegerLiteral,
 		token.STRING:   p.parseStringLiteral,
+		token.CHAR:     p.parseCharLiteral,
 		token.TRUE:     p.parseBooleanLiteral,
 		token.FALSE:    p.parseBooleanLiteral,
 		token.BANG:     p.parsePrefixExpression,
@@ -173,12 +239,21 @@ func New(l *lexer.Lexer) *Parser {
 		token.FLOAT:    p.parseFloatLiteral,
 		token.LBRACKET: p.parseArrayLiteral, // Added for array literals
 		token.LBRACE:   p.parseMapLiteral,   // Added for map literals
+		token.MATCH:    p.parseMatchExpression,
+		token.IF:       p.parseIfExpression,
+		token.LPAREN:   p.parseGroupedExpression,
 	}
 	p.infixParseFns = map[token.TokenType]infixParseFn{
 		token.PLUS:     p.parseInfixExpression,
 		token.MINUS:    p.parseInfixExpression,
 		token.MULTIPLY: p.parseInfixExpression,
 		token.DIVIDE:   p.parseInfixExpression,
+		token.MODULO:   p.parseInfixExpression,
+		token.BIT_AND:  p.parseInfixExpression,
+		token.BIT_OR:   p.parseInfixExpression,
+		token.BIT_XOR:  p.parseInfixExpression,
+		token.SHL:      p.parseInfixExpression,
+		token.SHR:      p.parseInfixExpression,
 		token.EQ:       p.parseInfixExpression,
 		token.NOT_EQ:   p.parseInfixExpression,
 		token.LT:       p.parseInfixExpression,
@@ -190,7 +265,9 @@ func New(l *lexer.Lexer) *Parser {
 		token.LPAREN:   p.parseFunctionCall,
 		token.LBRACE:   p.parseStructLiteral, // Added for struct literals
 		// Add member access operator
-		token.DOT: p.parseMemberExpression,
+		token.DOT:      p.parseMemberExpression,
+		token.QUESTION: p.parseTryExpression,
+		token.LBRACKET: p.parseIndexExpression,
 	}
 	p.nextToken()
 	p.nextToken()
@@ -208,22 +285,43 @@ func NewWithInput(l *lexer.Lexer, filename, input string) *Parser {
 func (p *Parser) nextToken() {
 	p.currentToken = p.peekToken
 	p.peekToken = p.l.NextToken()
+	for p.peekToken.Type == token.COMMENT {
+		p.comments = append(p.comments, &ast.Comment{
+			Position: ast.Position{Line: p.peekToken.Line, Column: p.peekToken.Column},
+			Text:     p.peekToken.Literal,
+		})
+		p.peekToken = p.l.NextToken()
+	}
 }
 
 func (p *Parser) Errors() []string { return p.errors }
 
+// Warnings returns non-fatal diagnostics collected while parsing
+func (p *Parser) Warnings() []ParseError { return p.warnings }
+
 // DetailedErrors returns the list of detailed ParseError structs
 func (p *Parser) DetailedErrors() []ParseError { return p.detailedErrors }
 
-// addDetailedError adds a detailed error with position information
-func (p *Parser) addDetailedError(message, expected, got, context, suggestion string) {
-	// Calculate line and column from current token position
-	line, column := p.getTokenPosition()
+// Diagnostics returns the errors and then the warnings collected while
+// parsing, located in the file the parser was created with
+func (p *Parser) Diagnostics() []diagnostics.Diagnostic {
+	var result []diagnostics.Diagnostic
+	for _, e := range p.detailedErrors {
+		result = append(result, e.Diagnostic(p.filename))
+	}
+	for _, w := range p.warnings {
+		result = append(result, w.Diagnostic(p.filename))
+	}
+	return result
+}
 
+// addDetailedError adds a detailed error with position information
+func (p *Parser) addDetailedError(id i18n.MessageID, message, expected, got, context, suggestion string) {
 	detailedErr := ParseError{
+		Code:       i18n.Code(id),
 		Message:    message,
-		Line:       line,
-		Column:     column,
+		Line:       p.currentToken.Line,
+		Column:     p.currentToken.Column,
 		Token:      p.currentToken,
 		Expected:   expected,
 		Got:        got,
@@ -236,30 +334,34 @@ func (p *Parser) addDetailedError(message, expected, got, context, suggestion st
 	p.errors = append(p.errors, message)
 }
 
-// getTokenPosition calculates line and column from token position
-func (p *Parser) getTokenPosition() (int, int) {
-	if p.input == "" {
-		return 0, 0
-	}
+// addError records an error from the message catalog at the current token position
+func (p *Parser) addError(id i18n.MessageID, args ...interface{}) {
+	p.addDetailedError(id, i18n.T(id, args...), "", "", "", "")
+}
 
-	// Simple line/column calculation - can be improved with lexer position tracking
-	lines := strings.Split(p.input[:min(len(p.input), len(p.currentToken.Literal))], "\n")
-	line := len(lines)
-	column := len(lines[len(lines)-1]) + 1
-	return line, column
+// addWarning records a non-fatal diagnostic at the current token position
+func (p *Parser) addWarning(id i18n.MessageID, context, suggestion string) {
+	p.warnings = append(p.warnings, ParseError{
+		Code:       i18n.Code(id),
+		Message:    i18n.T(id),
+		Line:       p.currentToken.Line,
+		Column:     p.currentToken.Column,
+		Token:      p.currentToken,
+		Context:    context,
+		Suggestion: suggestion,
+		Warning:    true,
+	})
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
+// pos returns the position of the current token
+func (p *Parser) pos() ast.Position {
+	return ast.Position{Line: p.currentToken.Line, Column: p.currentToken.Column}
 }
 
 func (p *Parser) peekError(t token.TokenType) {
 	expected := string(t)
 	got := string(p.peekToken.Type)
-	message := "expected next token to be " + expected + ", got " + got + " instead"
+	message := i18n.T(i18n.ParserExpectedNextToken, expected, got)
 
 	context := ""
 	if p.peekToken.Literal != "" {
@@ -268,12 +370,12 @@ func (p *Parser) peekError(t token.TokenType) {
 
 	suggestion := ""
 	if t == token.RPAREN && p.peekToken.Type == token.EOF {
-		suggestion = "add missing ')' to close function call or expression"
+		suggestion = i18n.T(i18n.ParserHintCloseParen)
 	} else if t == token.RBRACE && p.peekToken.Type == token.EOF {
-		suggestion = "add missing '}' to close block"
+		suggestion = i18n.T(i18n.ParserHintCloseBlock)
 	}
 
-	p.addDetailedError(message, expected, got, context, suggestion)
+	p.addDetailedError(i18n.ParserExpectedNextToken, message, expected, got, context, suggestion)
 }
 
 func (p *Parser) expectPeek(t token.TokenType) bool {
@@ -285,8 +387,16 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 	return false
 }
 
+// skipSemicolons moves past the SEMICOLON tokens following the current
+// token, such as the one ending the line before the } closing a literal
+func (p *Parser) skipSemicolons() {
+	for p.peekToken.Type == token.SEMICOLON {
+		p.nextToken()
+	}
+}
+
 func (p *Parser) ParseProgram() *ast.Program {
-	program := &ast.Program{Statements: []ast.Statement{}}
+	program := &ast.Program{Position: p.pos(), Statements: []ast.Statement{}}
 	for p.currentToken.Type != token.EOF {
 		stmt := p.parseStatement()
 		if stmt != nil {
@@ -294,6 +404,7 @@ func (p *Parser) ParseProgram() *ast.Program {
 		}
 		p.nextToken()
 	}
+	program.Comments = p.comments
 	return program
 }
 
@@ -304,20 +415,39 @@ func (p *Parser) parseStatement() ast.Statement {
 		stmt = p.parseForStatement()
 	case token.TYPE:
 		return p.parseTypeDeclaration()
+	case token.ENUM:
+		return p.parseEnumDeclaration()
+	case token.IMPL:
+		return p.parseImplDeclaration()
 	case token.IMPORT:
 		stmt = p.parseImportStatement()
-	case token.LET:
+	case token.LET, token.CONST:
 		stmt = p.parseLetStatement()
 	case token.IF:
 		stmt = p.parseIfStatement()
 	case token.PUB:
 		stmt = p.parsePublicDeclaration()
+	case token.AT:
+		stmt = p.parseAttributedDeclaration()
 	case token.FN:
 		stmt = p.parseFunctionDefinition()
 	case token.RETURN:
 		stmt = p.parseReturnStatement()
 	case token.WHILE:
 		stmt = p.parseWhileStatement()
+	case token.LOOP:
+		stmt = p.parseLoopStatement()
+	case token.BREAK, token.CONTINUE:
+		stmt = p.parseLoopControlStatement()
+	case token.SPAWN:
+		stmt = p.parseSpawnStatement()
+	case token.TRY:
+		stmt = p.parseTryStatement()
+	case token.WHEN:
+		stmt = p.parseWhenStatement()
+	case token.SEMICOLON:
+		// An empty statement, or the end of the line of the one before
+		return nil
 	case token.IDENT:
 		if p.peekToken.Type == token.ASSIGN {
 			stmt = p.parseAssignmentStatement()
@@ -330,7 +460,48 @@ func (p *Parser) parseStatement() ast.Statement {
 	return stmt
 }
 
+// destructurePatterns maps the token opening the pattern of a let that
+// takes a value apart to its kind and closing token
+var destructurePatterns = map[token.TokenType]struct {
+	kind  ast.Destructure
+	close token.TokenType
+}{
+	token.LPAREN:   {ast.DestructureTuple, token.RPAREN},
+	token.LBRACE:   {ast.DestructureFields, token.RBRACE},
+	token.LBRACKET: {ast.DestructureArray, token.RBRACKET},
+}
+
+// parseLetStatement parses a let, or a const, which declares a single name
+// that cannot be changed
 func (p *Parser) parseLetStatement() *ast.LetDeclaration {
+	pos := p.pos()
+	constant := p.currentToken.Type == token.CONST
+	mutable := !constant && p.peekToken.Type == token.MUT
+	if mutable {
+		p.nextToken()
+	}
+	if pattern, ok := destructurePatterns[p.peekToken.Type]; ok && !constant {
+		// let (a, b), let {name, age} and let [first, second] take the
+		// value apart
+		p.nextToken()
+		var names []string
+		for {
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			names = append(names, p.currentToken.Literal)
+			if p.peekToken.Type != token.COMMA {
+				break
+			}
+			p.nextToken()
+		}
+		if !p.expectPeek(pattern.close) || !p.expectPeek(token.ASSIGN) {
+			return nil
+		}
+		p.nextToken()
+		value := p.parseExpression(LOWEST)
+		return &ast.LetDeclaration{Position: pos, Names: names, Destructure: pattern.kind, Mutable: mutable, ValueExpression: value}
+	}
 	if !p.expectPeek(token.IDENT) {
 		return nil
 	}
@@ -338,45 +509,95 @@ func (p *Parser) parseLetStatement() *ast.LetDeclaration {
 	var typeAnn *string
 	if p.peekToken.Type == token.COLON {
 		p.nextToken()
-		if !p.expectPeek(token.IDENT) {
+		if !p.expectPeekType() {
 			return nil
 		}
-		// parse basic and generic type annotations (e.g., Result<int>)
-		typeStr := p.currentToken.Literal
-		if p.peekToken.Type == token.LT {
-			for p.peekToken.Type != token.GT {
-				p.nextToken()
-				typeStr += p.currentToken.Literal
-			}
-			// consume '>'
-			p.nextToken()
-			typeStr += p.currentToken.Literal
-		}
-		annotation := typeStr
+		annotation := p.parseTypeAnnotation()
 		typeAnn = &annotation
 	}
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
 	}
 	p.nextToken()
+	if typeAnn != nil && p.currentToken.Type == token.LBRACKET {
+		p.arrayElementType = annotatedPrimitiveTypes[strings.TrimPrefix(*typeAnn, "[]")]
+	}
 	value := p.parseExpression(LOWEST)
-	return &ast.LetDeclaration{Name: name, TypeAnn: typeAnn, ValueExpression: value}
+	p.arrayElementType = ""
+	return &ast.LetDeclaration{Position: pos, Name: name, Mutable: mutable, Constant: constant, TypeAnn: typeAnn, ValueExpression: value}
+}
+
+// expectPeekType advances to the start of a type annotation: a type name,
+// the '[' of an array type or the '(' of a tuple type
+func (p *Parser) expectPeekType() bool {
+	if p.peekToken.Type == token.LBRACKET || p.peekToken.Type == token.LPAREN {
+		p.nextToken()
+		return true
+	}
+	return p.expectPeek(token.IDENT)
+}
+
+// parseTypeAnnotation parses basic, array, tuple and generic type
+// annotations (e.g., []int, (int, string), Result<[]string>). currentToken
+// is the type name or the '[' or '(' starting the type.
+func (p *Parser) parseTypeAnnotation() string {
+	if p.currentToken.Type == token.LBRACKET {
+		if !p.expectPeek(token.RBRACKET) || !p.expectPeekType() {
+			return "[]"
+		}
+		return "[]" + p.parseTypeAnnotation()
+	}
+	if p.currentToken.Type == token.LPAREN {
+		var elements []string
+		for p.expectPeekType() {
+			elements = append(elements, p.parseTypeAnnotation())
+			if p.peekToken.Type != token.COMMA {
+				break
+			}
+			p.nextToken()
+		}
+		p.expectPeek(token.RPAREN)
+		return "(" + strings.Join(elements, ", ") + ")"
+	}
+	typeStr := p.currentToken.Literal
+	if p.peekToken.Type == token.LT {
+		// Parse everything until the matching '>'
+		depth := 0
+		for p.peekToken.Type != token.EOF {
+			p.nextToken()
+			typeStr += p.currentToken.Literal
+			if p.currentToken.Type == token.LT {
+				depth++
+			} else if p.currentToken.Type == token.GT {
+				if depth--; depth == 0 {
+					break
+				}
+			} else if p.currentToken.Type == token.SHR {
+				// Closes two type arguments, e.g. Result<Option<int>>
+				if depth -= 2; depth <= 0 {
+					break
+				}
+			}
+		}
+	}
+	return typeStr
 }
 
 func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
-	stmt := &ast.ExpressionStatement{}
+	stmt := &ast.ExpressionStatement{Position: p.pos()}
 	stmt.Expression = p.parseExpression(LOWEST)
 	return stmt
 }
 
 func (p *Parser) parseAssignmentStatement() *ast.AssignmentStatement {
+	pos := p.pos()
 	name := p.currentToken.Literal
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
 	}
 	p.nextToken()
 	value := p.parseExpression(LOWEST)
-	return &ast.AssignmentStatement{Name: name, Value: value}
+	return &ast.AssignmentStatement{Position: pos, Name: name, Value: value}
 }
 
 func (p *Parser) parseExpression(precedence int) ast.Expression {
@@ -405,24 +626,139 @@ func (p *Parser) parseExpressionUntil(precedence int, until token.TokenType) ast
 }
 
 func (p *Parser) parseIdentifier() ast.Expression {
-	return &ast.Identifier{Value: p.currentToken.Literal}
+	ident := &ast.Identifier{Position: p.pos(), Value: p.currentToken.Literal}
+	if p.peekToken.Type == token.LT {
+		if call := p.parseGenericCall(ident); call != nil {
+			return call
+		}
+	}
+	return ident
+}
+
+// parseGenericCall parses a call with type arguments, decode<User>(text),
+// which starts like a comparison. It is only one when the type arguments are
+// followed by '('; otherwise the parser is put back on the identifier and
+// nil is returned.
+func (p *Parser) parseGenericCall(ident *ast.Identifier) *ast.FunctionCall {
+	lexer, current, peek := *p.l, p.currentToken, p.peekToken
+	errors, detailedErrors, warnings, comments := len(p.errors), len(p.detailedErrors), len(p.warnings), len(p.comments)
+	p.nextToken()
+	var typeArgs []string
+	closed := false
+	for !closed && (p.peekToken.Type == token.IDENT || p.peekToken.Type == token.LBRACKET || p.peekToken.Type == token.LPAREN) {
+		p.nextToken()
+		typeArg := p.parseTypeAnnotation()
+		if strings.Count(typeArg, ">") > strings.Count(typeArg, "<") {
+			// The '>>' ending a generic type argument also closes the list
+			typeArg, closed = typeArg[:len(typeArg)-1], true
+		}
+		typeArgs = append(typeArgs, typeArg)
+		if p.peekToken.Type != token.COMMA {
+			break
+		}
+		p.nextToken()
+	}
+	if !closed && len(typeArgs) > 0 && p.peekToken.Type == token.GT {
+		p.nextToken()
+		closed = true
+	}
+	if !closed || p.peekToken.Type != token.LPAREN {
+		*p.l, p.currentToken, p.peekToken = lexer, current, peek
+		p.errors, p.detailedErrors, p.warnings, p.comments = p.errors[:errors], p.detailedErrors[:detailedErrors], p.warnings[:warnings], p.comments[:comments]
+		return nil
+	}
+	p.nextToken()
+	call, ok := p.parseFunctionCall(ident).(*ast.FunctionCall)
+	if !ok {
+		return nil
+	}
+	call.TypeArguments = typeArgs
+	return call
+}
+
+// integerSizes are the bits and the signedness of the integer types, by
+// the suffix of their literals
+var integerSizes = map[string]struct {
+	bits     int
+	unsigned bool
+}{
+	"":     {64, false},
+	"i32":  {32, false},
+	"i64":  {64, false},
+	"u32":  {32, true},
+	"u64":  {64, true},
+	"byte": {8, true},
+}
+
+// splitIntegerLiteral splits an integer literal into its digits and the
+// sized integer type it ends with, e.g. 10 and u64 for 10u64
+func splitIntegerLiteral(literal string) (digits, suffix string) {
+	if i := strings.IndexFunc(literal, func(r rune) bool { return r < '0' || r > '9' }); i >= 0 {
+		return literal[:i], literal[i:]
+	}
+	return literal, ""
 }
 
 func (p *Parser) parseIntegerLiteral() ast.Expression {
-	value, err := strconv.Atoi(p.currentToken.Literal)
+	literal := p.currentToken.Literal
+	digits, suffix := splitIntegerLiteral(literal)
+	size := integerSizes[suffix]
+	var value int
+	var err error
+	if size.unsigned {
+		var n uint64
+		n, err = strconv.ParseUint(digits, 10, size.bits)
+		value = int(n)
+	} else {
+		var n int64
+		n, err = strconv.ParseInt(digits, 10, size.bits)
+		value = int(n)
+	}
+	if errors.Is(err, strconv.ErrRange) {
+		typeName := suffix
+		if typeName == "" {
+			typeName = "int"
+		}
+		if size.unsigned {
+			p.addError(i18n.ParserIntegerOutOfRange, literal, typeName, 0, ^uint64(0)>>(64-size.bits))
+		} else {
+			min := int64(-1) << (size.bits - 1)
+			p.addError(i18n.ParserIntegerOutOfRange, literal, typeName, min, ^min)
+		}
+		return nil
+	}
+	if err != nil {
+		p.addError(i18n.ParserInvalidInteger, literal)
+		return nil
+	}
+	return &ast.IntegerLiteral{Position: p.pos(), Value: value, Suffix: suffix}
+}
+
+// parseNegatedInteger reads the current integer literal with the minus
+// before it as one value, for the smallest value of a signed type, such as
+// -9223372036854775808, whose digits alone are out of range. It returns nil
+// for any literal whose digits are in range, which stays a negation.
+func (p *Parser) parseNegatedInteger() *ast.IntegerLiteral {
+	digits, suffix := splitIntegerLiteral(p.currentToken.Literal)
+	size, known := integerSizes[suffix]
+	if !known || size.unsigned {
+		return nil
+	}
+	if _, err := strconv.ParseInt(digits, 10, size.bits); !errors.Is(err, strconv.ErrRange) {
+		return nil
+	}
+	n, err := strconv.ParseInt("-"+digits, 10, size.bits)
 	if err != nil {
-		p.errors = append(p.errors, fmt.Sprintf("could not parse %q as integer", p.currentToken.Literal))
 		return nil
 	}
-	return &ast.IntegerLiteral{Value: value}
+	return &ast.IntegerLiteral{Value: int(n), Suffix: suffix}
 }
 
 func (p *Parser) parseFloatLiteral() ast.Expression {
-	lit := &ast.FloatLiteral{}
+	lit := &ast.FloatLiteral{Position: p.pos()}
 	value, err := strconv.ParseFloat(p.currentToken.Literal, 64)
 	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as float", p.currentToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.addError(i18n.ParserInvalidFloat, p.currentToken.Literal)
 		return nil
 	}
 	lit.Value = value
@@ -430,15 +766,41 @@ func (p *Parser) parseFloatLiteral() ast.Expression {
 }
 
 func (p *Parser) parseStringLiteral() ast.Expression {
-	return &ast.StringLiteral{Value: lexer.ProcessStringLiteral(p.currentToken.Literal)}
+	return &ast.StringLiteral{Position: p.pos(), Value: lexer.ProcessStringLiteral(p.currentToken.Literal), Raw: p.currentToken.Literal}
+}
+
+// parseCharLiteral parses a literal in single quotes, which must hold one
+// character once its escape sequences are resolved. \xff is the character
+// U+00FF, as in Go, rather than a byte of UTF-8.
+func (p *Parser) parseCharLiteral() ast.Expression {
+	raw := p.currentToken.Literal
+	value := lexer.ProcessStringLiteral(raw)
+	r, size := utf8.DecodeRuneInString(value)
+	if len(value) == 1 {
+		r, size = rune(value[0]), 1
+	}
+	if value == "" || size != len(value) {
+		p.addError(i18n.ParserInvalidChar, raw)
+		return nil
+	}
+	return &ast.CharLiteral{Position: p.pos(), Value: r, Raw: raw}
 }
+
 func (p *Parser) parseBooleanLiteral() ast.Expression {
-	return &ast.BooleanLiteral{Value: p.currentToken.Type == token.TRUE}
+	return &ast.BooleanLiteral{Position: p.pos(), Value: p.currentToken.Type == token.TRUE}
 }
 
 func (p *Parser) parsePrefixExpression() ast.Expression {
-	expr := &ast.UnaryExpression{Operator: tokenToUnaryOperator(p.currentToken.Type)}
+	expr := &ast.UnaryExpression{Position: p.pos(), Operator: tokenToUnaryOperator(p.currentToken.Type)}
 	p.nextToken()
+	// Nothing binding tighter than the minus may follow the literal, as in
+	// -9223372036854775808.abs()
+	if expr.Operator == ast.UnaryOpMinus && p.currentToken.Type == token.INT && p.peekPrecedence() <= PREFIX {
+		if literal := p.parseNegatedInteger(); literal != nil {
+			literal.Position = expr.Position
+			return literal
+		}
+	}
 	expr.Right = p.parseExpression(PREFIX)
 	return expr
 }
@@ -454,7 +816,7 @@ func tokenToUnaryOperator(tok token.TokenType) ast.UnaryOperator {
 }
 
 func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
-	expr := &ast.BinaryExpression{Left: left, Operator: tokenToBinaryOperator(p.currentToken.Literal)}
+	expr := &ast.BinaryExpression{Position: left.Pos(), Left: left, Operator: tokenToBinaryOperator(p.currentToken.Literal)}
 	prec := p.curPrecedence()
 	p.nextToken()
 	expr.Right = p.parseExpressionUntil(prec, p.currentUntil)
@@ -462,7 +824,7 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 }
 
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
-	message := "no prefix parse function for " + string(t) + " found"
+	message := i18n.T(i18n.ParserNoPrefixParseFn, t)
 	expected := "expression"
 	got := string(t)
 
@@ -473,12 +835,12 @@ func (p *Parser) noPrefixParseFnError(t token.TokenType) {
 
 	suggestion := ""
 	if t == token.RPAREN {
-		suggestion = "unexpected ')' - check for empty function call like 'println()'"
+		suggestion = i18n.T(i18n.ParserHintUnexpectedRParen)
 	} else if t == token.EOF {
-		suggestion = "unexpected end of file - check for incomplete expression"
+		suggestion = i18n.T(i18n.ParserHintUnexpectedEOF)
 	}
 
-	p.addDetailedError(message, expected, got, context, suggestion)
+	p.addDetailedError(i18n.ParserNoPrefixParseFn, message, expected, got, context, suggestion)
 }
 
 func ParseExpression(input string) (ast.Expression, error) {
@@ -492,6 +854,20 @@ func ParseExpression(input string) (ast.Expression, error) {
 }
 
 func (p *Parser) parseImportStatement() *ast.ImportStatement {
+	pos := p.pos()
+	if p.peekToken.Type == token.MULTIPLY {
+		// import * as io from "std/io" imports the whole module
+		p.nextToken()
+		if !p.expectPeek(token.AS) || !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		namespace := p.currentToken.Literal
+		module, ok := p.parseImportSource()
+		if !ok {
+			return nil
+		}
+		return &ast.ImportStatement{Position: pos, Namespace: namespace, Module: module}
+	}
 	if !p.expectPeek(token.LBRACE) {
 		return nil
 	}
@@ -502,17 +878,11 @@ func (p *Parser) parseImportStatement() *ast.ImportStatement {
 	p.nextToken()
 	if p.currentToken.Type == token.RBRACE {
 		// 空のインポート
-		if !p.expectPeek(token.FROM) {
-			return nil
-		}
-		if !p.expectPeek(token.STRING) {
+		module, ok := p.parseImportSource()
+		if !ok {
 			return nil
 		}
-		module := p.currentToken.Literal
-		if len(module) >= 2 && module[0] == '"' && module[len(module)-1] == '"' {
-			module = module[1 : len(module)-1]
-		}
-		return &ast.ImportStatement{Imports: items, Module: module}
+		return &ast.ImportStatement{Position: pos, Imports: items, Module: module}
 	}
 
 	for {
@@ -528,16 +898,30 @@ func (p *Parser) parseImportStatement() *ast.ImportStatement {
 			return nil
 		}
 
-		items = append(items, ast.ImportItem{Name: p.currentToken.Literal, IsType: isType})
+		item := ast.ImportItem{Name: p.currentToken.Literal, IsType: isType}
+		if p.peekToken.Type == token.AS {
+			// import { parse as parseJson } renames a function
+			p.nextToken()
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			if isType {
+				p.addError(i18n.ParserImportTypeAlias, item.Name)
+			} else {
+				item.Alias = p.currentToken.Literal
+			}
+		}
+		items = append(items, item)
 
 		// 次のトークンをチェック
+		p.skipSemicolons()
 		if p.peekToken.Type == token.COMMA {
 			p.nextToken() // COMMAを消費
 			p.nextToken() // 次の項目へ移動
 		} else if p.peekToken.Type == token.RBRACE {
 			break
 		} else {
-			p.errors = append(p.errors, fmt.Sprintf("expected ',' or '}' in import statement, got %s", p.peekToken.Type))
+			p.addError(i18n.ParserImportSeparator, p.peekToken.Type)
 			return nil
 		}
 	}
@@ -545,18 +929,23 @@ func (p *Parser) parseImportStatement() *ast.ImportStatement {
 	if !p.expectPeek(token.RBRACE) {
 		return nil
 	}
-	if !p.expectPeek(token.FROM) {
-		return nil
-	}
-	if !p.expectPeek(token.STRING) {
+	module, ok := p.parseImportSource()
+	if !ok {
 		return nil
 	}
+	return &ast.ImportStatement{Position: pos, Imports: items, Module: module}
+}
 
+// parseImportSource parses the from "module" ending an import statement
+func (p *Parser) parseImportSource() (string, bool) {
+	if !p.expectPeek(token.FROM) || !p.expectPeek(token.STRING) {
+		return "", false
+	}
 	module := p.currentToken.Literal
 	if len(module) >= 2 && module[0] == '"' && module[len(module)-1] == '"' {
 		module = module[1 : len(module)-1]
 	}
-	return &ast.ImportStatement{Imports: items, Module: module}
+	return module, true
 }
 
 func (p *Parser) isValidImportIdentifier() bool { return p.currentToken.Type == token.IDENT }
@@ -566,15 +955,105 @@ func (p *Parser) parseFunctionDefinition() *ast.FunctionDefinition {
 }
 
 func (p *Parser) parsePublicDeclaration() ast.Statement {
+	pos := p.pos()
+	if p.peekToken.Type == token.IMPORT {
+		// pub import { helper } from "./internal" re-exports helper
+		p.nextToken()
+		stmt := p.parseImportStatement()
+		if stmt == nil {
+			return nil
+		}
+		if stmt.Namespace != "" {
+			p.addError(i18n.ParserPubNamespaceImport, stmt.Namespace)
+			return nil
+		}
+		stmt.Position = pos
+		stmt.IsPublic = true
+		return stmt
+	}
 	if p.peekToken.Type != token.FN {
-		p.errors = append(p.errors, "pub can only be used with function definitions")
+		p.addError(i18n.ParserPubWithoutFn)
+		return nil
+	}
+	p.nextToken()
+	def := p.parseFunctionDefinitionWithVisibility(true)
+	if def == nil {
+		return nil
+	}
+	def.Position = pos
+	return def
+}
+
+// parseAttributedDeclaration parses an attribute such as @deprecated("hint")
+// and the function or type declaration it applies to.
+func (p *Parser) parseAttributedDeclaration() ast.Statement {
+	// currentToken is AT
+	pos := p.pos()
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	attribute := p.currentToken.Literal
+	if attribute != "deprecated" {
+		p.addError(i18n.ParserUnknownAttribute, attribute)
 		return nil
 	}
+	deprecation := &ast.Deprecation{}
+	if p.peekToken.Type == token.LPAREN {
+		p.nextToken()
+		if !p.expectPeek(token.STRING) {
+			return nil
+		}
+		deprecation.Message = p.currentToken.Literal
+		if !p.expectPeek(token.RPAREN) {
+			return nil
+		}
+	}
+	p.skipSemicolons()
 	p.nextToken()
-	return p.parseFunctionDefinitionWithVisibility(true)
+
+	switch p.currentToken.Type {
+	case token.PUB, token.FN:
+		var def *ast.FunctionDefinition
+		if p.currentToken.Type == token.PUB {
+			if p.peekToken.Type != token.FN {
+				p.addError(i18n.ParserPubWithoutFn)
+				return nil
+			}
+			p.nextToken()
+			def = p.parseFunctionDefinitionWithVisibility(true)
+		} else {
+			def = p.parseFunctionDefinition()
+		}
+		if def == nil {
+			return nil
+		}
+		def.Position = pos
+		def.Deprecated = deprecation
+		return def
+	case token.TYPE:
+		decl := p.parseTypeDeclaration()
+		if decl == nil {
+			return nil
+		}
+		decl.Position = pos
+		decl.Deprecated = deprecation
+		return decl
+	case token.ENUM:
+		decl := p.parseEnumDeclaration()
+		if decl == nil {
+			return nil
+		}
+		decl.Position = pos
+		decl.Deprecated = deprecation
+		return decl
+	default:
+		p.addError(i18n.ParserAttributeTarget, attribute)
+		return nil
+	}
 }
 
 func (p *Parser) parseFunctionDefinitionWithVisibility(isPublic bool) *ast.FunctionDefinition {
+	pos := p.pos()
 	if !p.expectPeek(token.IDENT) {
 		return nil
 	}
@@ -615,7 +1094,7 @@ func (p *Parser) parseFunctionDefinitionWithVisibility(isPublic bool) *ast.Funct
 					return nil
 				}
 			} else if p.currentToken.Type != token.IDENT {
-				p.errors = append(p.errors, "expected parameter name")
+				p.addError(i18n.ParserExpectedParamName)
 				return nil
 			}
 
@@ -623,37 +1102,19 @@ func (p *Parser) parseFunctionDefinitionWithVisibility(isPublic bool) *ast.Funct
 			if !p.expectPeek(token.COLON) {
 				return nil
 			}
-			if !p.expectPeek(token.IDENT) {
+			if !p.expectPeekType() {
 				return nil
 			}
 			// Parse parameter type (may include generics like Result<T>)
-			paramType := p.currentToken.Literal
-
-			// Check if this is a generic type
-			if p.peekToken.Type == token.LT {
-				p.nextToken() // consume '<'
-				paramType += p.currentToken.Literal
-
-				// Parse everything until we find the matching '>'
-				depth := 1
-				for depth > 0 && p.peekToken.Type != token.EOF {
-					p.nextToken()
-					paramType += p.currentToken.Literal
-					if p.currentToken.Type == token.LT {
-						depth++
-					} else if p.currentToken.Type == token.GT {
-						depth--
-					}
-				}
-			}
+			paramType := p.parseTypeAnnotation()
 
 			parameters = append(parameters, ast.Parameter{Name: paramName, Type: paramType, Variadic: variadic})
 
 			// Variadic parameter must be the last one
 			if variadic {
 				if p.peekToken.Type == token.COMMA {
-					message := "variadic parameter must be the last parameter"
-					p.addDetailedError(message, "no more parameters", "comma", "after variadic parameter", "move variadic parameter to the end")
+					message := i18n.T(i18n.ParserVariadicNotLast)
+					p.addDetailedError(i18n.ParserVariadicNotLast, message, "no more parameters", "comma", "after variadic parameter", i18n.T(i18n.ParserHintVariadicNotLast))
 					return nil
 				}
 				break
@@ -673,31 +1134,10 @@ func (p *Parser) parseFunctionDefinitionWithVisibility(isPublic bool) *ast.Funct
 	var returnType *string
 	if p.peekToken.Type == token.COLON {
 		p.nextToken()
-		if !p.expectPeek(token.IDENT) {
+		if !p.expectPeekType() {
 			return nil
 		}
-		// Start with the identifier (e.g., "Result" or "int")
-		typeStr := p.currentToken.Literal
-
-		// Check if this is a generic type (e.g., Result<T>)
-		if p.peekToken.Type == token.LT {
-			p.nextToken() // consume '<'
-			typeStr += p.currentToken.Literal
-
-			// Parse everything until we find the matching '>'
-			depth := 1
-			for depth > 0 && p.peekToken.Type != token.EOF {
-				p.nextToken()
-				typeStr += p.currentToken.Literal
-				if p.currentToken.Type == token.LT {
-					depth++
-				} else if p.currentToken.Type == token.GT {
-					depth--
-				}
-			}
-		}
-
-		retType := typeStr
+		retType := p.parseTypeAnnotation()
 		returnType = &retType
 	}
 	if !p.expectPeek(token.LBRACE) {
@@ -707,10 +1147,11 @@ func (p *Parser) parseFunctionDefinitionWithVisibility(isPublic bool) *ast.Funct
 	if bodyBlock == nil {
 		return nil
 	}
-	return &ast.FunctionDefinition{Name: name, Generics: generics, Parameters: parameters, ReturnType: returnType, Body: bodyBlock.Statements, IsPublic: isPublic}
+	return &ast.FunctionDefinition{Position: pos, Name: name, Generics: generics, Parameters: parameters, ReturnType: returnType, Body: bodyBlock.Statements, Lbrace: bodyBlock.Position, Rbrace: bodyBlock.Rbrace, IsPublic: isPublic}
 }
 
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
+	pos := p.pos()
 	var value ast.Expression
 	if p.peekToken.Type != token.SEMICOLON && p.peekToken.Type != token.EOF && p.peekToken.Type != token.RBRACE {
 		p.nextToken()
@@ -719,7 +1160,7 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	if p.peekToken.Type == token.SEMICOLON {
 		p.nextToken()
 	}
-	return &ast.ReturnStatement{Value: value}
+	return &ast.ReturnStatement{Position: pos, Value: value}
 }
 
 // parseCommaSeparatedExpressions parses a list of comma-separated expressions until an endToken.
@@ -776,8 +1217,20 @@ func getExpressionPrimitiveType(exp ast.Expression) (string, bool) {
 	}
 }
 
+// annotatedPrimitiveTypes are the primitive types of
+// getExpressionPrimitiveType by the names they are annotated with
+var annotatedPrimitiveTypes = map[string]string{
+	"int":    "INT",
+	"float":  "FLOAT",
+	"string": "STRING",
+	"bool":   "BOOL",
+}
+
 func (p *Parser) parseArrayLiteral() ast.Expression {
-	array := &ast.ArrayLiteral{}
+	array := &ast.ArrayLiteral{Position: p.pos()}
+	// Only the outermost literal takes the annotated element type
+	annotated := p.arrayElementType
+	p.arrayElementType = ""
 	// currentToken is token.LBRACKET when this prefixParseFn is called.
 	// parseCommaSeparatedExpressions handles the parsing of elements between LBRACKET and RBRACKET.
 	array.Elements = p.parseCommaSeparatedExpressions(token.RBRACKET)
@@ -791,12 +1244,19 @@ func (p *Parser) parseArrayLiteral() ast.Expression {
 	if len(array.Elements) > 0 {
 		firstElementType, isFirstPrimitive := getExpressionPrimitiveType(array.Elements[0])
 		if !isFirstPrimitive {
-			msg := fmt.Sprintf("array element type is not a primitive type (int, float, string, bool), got %s for first element", firstElementType)
-			p.errors = append(p.errors, msg)
+			p.addError(i18n.ParserArrayFirstNotPrimitive, firstElementType)
 			// Return the array to allow collecting more syntax errors; type errors are semantic.
 			// The generator/type-checker will ultimately decide if this partially valid AST is usable.
 		}
 
+		// The annotated element type is expected of the elements when the
+		// first one has it, as ints do for float. A first element of
+		// another type is left for the type checker to report against the
+		// annotation.
+		if isFirstPrimitive && (annotated == firstElementType || annotated == "FLOAT" && firstElementType == "INT") {
+			firstElementType = annotated
+		}
+
 		// We proceed with type checking against the first element's type only if it was primitive.
 		if isFirstPrimitive {
 			for i := 1; i < len(array.Elements); i++ {
@@ -804,14 +1264,12 @@ func (p *Parser) parseArrayLiteral() ast.Expression {
 				elementType, isPrimitive := getExpressionPrimitiveType(element)
 
 				if !isPrimitive {
-					msg := fmt.Sprintf("array element type is not a primitive type (int, float, string, bool), got %s at index %d (expected %s)", elementType, i, firstElementType)
-					p.errors = append(p.errors, msg)
+					p.addError(i18n.ParserArrayElementNotPrimitive, elementType, i, firstElementType)
 					continue // Continue to find all non-primitive elements
 				}
 
-				if elementType != firstElementType {
-					msg := fmt.Sprintf("mismatched types in array literal: expected %s, got %s at index %d", firstElementType, elementType, i)
-					p.errors = append(p.errors, msg)
+				if elementType != firstElementType && !(elementType == "INT" && firstElementType == "FLOAT" && annotated == "FLOAT") {
+					p.addError(i18n.ParserArrayMismatchedTypes, firstElementType, elementType, i)
 					// Continue to find all mismatches against the first primitive type
 				}
 			}
@@ -827,7 +1285,7 @@ func (p *Parser) parseArrayLiteral() ast.Expression {
 
 func (p *Parser) parseMapLiteral() ast.Expression {
 	// currentToken is token.LBRACE when this prefixParseFn is called.
-	mapLiteral := &ast.MapLiteral{Pairs: make(map[ast.Expression]ast.Expression)}
+	mapLiteral := &ast.MapLiteral{Position: p.pos(), Pairs: make(map[ast.Expression]ast.Expression)}
 
 	// Handle empty map {}
 	if p.peekToken.Type == token.RBRACE {
@@ -851,8 +1309,7 @@ func (p *Parser) parseMapLiteral() ast.Expression {
 		case *ast.Identifier, *ast.StringLiteral:
 			// Valid key type
 		default:
-			msg := fmt.Sprintf("invalid map key type: expected IDENTIFIER or STRING, got %T", key)
-			p.errors = append(p.errors, msg)
+			p.addError(i18n.ParserInvalidMapKey, key)
 			return nil
 		}
 
@@ -869,6 +1326,8 @@ func (p *Parser) parseMapLiteral() ast.Expression {
 			return nil
 		}
 		mapLiteral.Pairs[key] = value
+		mapLiteral.Keys = append(mapLiteral.Keys, key)
+		p.skipSemicolons()
 
 		// After parsing a value, p.currentToken is the last token of the value expression.
 		// p.peekToken is what comes AFTER the value expression (e.g., COMMA or RBRACE).
@@ -886,12 +1345,10 @@ func (p *Parser) parseMapLiteral() ast.Expression {
 			p.nextToken() // Advances p.currentToken to be the RBRACE.
 			break         // Exit loop, RBRACE is currentToken.
 		} else if p.peekToken.Type == token.EOF { // Premature EOF
-			msg := "expected ',' or '}' after map value, got EOF"
-			p.errors = append(p.errors, msg)
+			p.addError(i18n.ParserMapSeparatorEOF)
 			return nil
 		} else { // Unexpected token
-			msg := fmt.Sprintf("expected ',' or '}' after map value, got %s instead", p.peekToken.Type)
-			p.errors = append(p.errors, msg)
+			p.addError(i18n.ParserMapSeparator, p.peekToken.Type)
 			return nil
 		}
 	} // End of for loop
@@ -899,15 +1356,15 @@ func (p *Parser) parseMapLiteral() ast.Expression {
 	if p.currentToken.Type != token.RBRACE {
 		// Only report block close error if no prior errors
 		if len(p.detailedErrors) == 0 {
-			message := "expected '}' to close block"
+			message := i18n.T(i18n.ParserExpectedCloseBlock)
 			expected := "}"
 			got := string(p.currentToken.Type)
 			context := ""
 			if p.currentToken.Literal != "" {
 				context = "found '" + p.currentToken.Literal + "'"
 			}
-			suggestion := "add missing '}' to close block"
-			p.addDetailedError(message, expected, got, context, suggestion)
+			suggestion := i18n.T(i18n.ParserHintCloseBlock)
+			p.addDetailedError(i18n.ParserExpectedCloseBlock, message, expected, got, context, suggestion)
 		}
 		return nil
 	}
@@ -922,12 +1379,13 @@ func (p *Parser) parseStructLiteral(typeExpr ast.Expression) ast.Expression {
 	if ident, ok := typeExpr.(*ast.Identifier); ok {
 		typeName = ident.Value
 	} else {
-		message := "struct literal requires a type name"
-		p.addDetailedError(message, "identifier", "expression", "", "use a type name like Result{...}")
+		message := i18n.T(i18n.ParserStructNeedsTypeName)
+		p.addDetailedError(i18n.ParserStructNeedsTypeName, message, "identifier", "expression", "", i18n.T(i18n.ParserHintStructTypeName))
 		return nil
 	}
 
 	structLiteral := &ast.StructLiteral{
+		Position: typeExpr.Pos(),
 		TypeName: typeName,
 		Fields:   make(map[string]ast.Expression),
 	}
@@ -944,8 +1402,7 @@ func (p *Parser) parseStructLiteral(typeExpr ast.Expression) ast.Expression {
 	for p.currentToken.Type != token.RBRACE && p.currentToken.Type != token.EOF {
 		// Parse Field Name - must be an identifier
 		if p.currentToken.Type != token.IDENT {
-			msg := fmt.Sprintf("struct field name must be identifier, got %s", p.currentToken.Type)
-			p.errors = append(p.errors, msg)
+			p.addError(i18n.ParserStructFieldName, p.currentToken.Type)
 			return nil
 		}
 
@@ -962,6 +1419,8 @@ func (p *Parser) parseStructLiteral(typeExpr ast.Expression) ast.Expression {
 			return nil
 		}
 		structLiteral.Fields[fieldName] = value
+		structLiteral.FieldNames = append(structLiteral.FieldNames, fieldName)
+		p.skipSemicolons()
 
 		// After parsing a value, p.currentToken is the last token of the value expression.
 		// p.peekToken is what comes AFTER the value expression (e.g., COMMA or RBRACE).
@@ -978,27 +1437,25 @@ func (p *Parser) parseStructLiteral(typeExpr ast.Expression) ast.Expression {
 			p.nextToken() // Advances p.currentToken to be the RBRACE.
 			break         // Exit loop, RBRACE is currentToken.
 		} else if p.peekToken.Type == token.EOF {
-			msg := "expected ',' or '}' after struct field value, got EOF"
-			p.errors = append(p.errors, msg)
+			p.addError(i18n.ParserStructSeparatorEOF)
 			return nil
 		} else {
-			msg := fmt.Sprintf("expected ',' or '}' after struct field value, got %s instead", p.peekToken.Type)
-			p.errors = append(p.errors, msg)
+			p.addError(i18n.ParserStructSeparator, p.peekToken.Type)
 			return nil
 		}
 	}
 
 	if p.currentToken.Type != token.RBRACE {
 		if len(p.detailedErrors) == 0 {
-			message := "expected '}' to close struct literal"
+			message := i18n.T(i18n.ParserExpectedCloseStruct)
 			expected := "}"
 			got := string(p.currentToken.Type)
 			context := ""
 			if p.currentToken.Literal != "" {
 				context = "found '" + p.currentToken.Literal + "'"
 			}
-			suggestion := "add missing '}' to close struct literal"
-			p.addDetailedError(message, expected, got, context, suggestion)
+			suggestion := i18n.T(i18n.ParserHintCloseStruct)
+			p.addDetailedError(i18n.ParserExpectedCloseStruct, message, expected, got, context, suggestion)
 		}
 		return nil
 	}
@@ -1008,16 +1465,25 @@ func (p *Parser) parseStructLiteral(typeExpr ast.Expression) ast.Expression {
 func (p *Parser) parseFunctionCall(functionExpression ast.Expression) ast.Expression {
 	// currentToken is LPAREN when this (infixParseFn) is called.
 	var functionName string
+	if member, ok := functionExpression.(*ast.MemberExpression); ok {
+		// value.method(args)
+		call := &ast.MethodCallExpression{Position: member.Pos(), Object: member.Object, Method: member.Property}
+		call.Arguments = p.parseCommaSeparatedExpressions(token.RPAREN)
+		if call.Arguments == nil {
+			call.Arguments = []ast.Expression{}
+		}
+		return call
+	}
 	if ident, ok := functionExpression.(*ast.Identifier); ok {
 		functionName = ident.Value
 	} else {
 		// This shouldn't happen in current Zeno language design, but let's handle it gracefully
-		message := "function call on non-identifier expression not supported"
-		p.addDetailedError(message, "identifier", "expression", "", "use a function name instead of expression")
+		message := i18n.T(i18n.ParserCallOnNonIdentifier)
+		p.addDetailedError(i18n.ParserCallOnNonIdentifier, message, "identifier", "expression", "", i18n.T(i18n.ParserHintCallOnNonIdentifier))
 		return nil
 	}
 
-	call := &ast.FunctionCall{Name: functionName}
+	call := &ast.FunctionCall{Position: functionExpression.Pos(), Name: functionName}
 	call.Arguments = p.parseCommaSeparatedExpressions(token.RPAREN)
 
 	// Handle parsing errors from parseCommaSeparatedExpressions
@@ -1029,8 +1495,8 @@ func (p *Parser) parseFunctionCall(functionExpression ast.Expression) ast.Expres
 	if len(call.Arguments) == 0 {
 		// Check if this is a function that requires arguments
 		if functionName == "println" || functionName == "print" {
-			message := "function '" + functionName + "' requires at least one argument"
-			p.addDetailedError(message, "at least one argument", "empty call", "in function call", "add an argument like println(\"Hello\")")
+			message := i18n.T(i18n.ParserCallNeedsArgument, functionName)
+			p.addDetailedError(i18n.ParserCallNeedsArgument, message, "at least one argument", "empty call", "in function call", i18n.T(i18n.ParserHintCallNeedsArgument))
 			// Return the call anyway to allow parser to continue
 		}
 	}
@@ -1042,6 +1508,7 @@ func (p *Parser) parseFunctionCall(functionExpression ast.Expression) ast.Expres
 // func (p *Parser) parseCallArguments() []ast.Expression { ... }
 
 func (p *Parser) parseIfStatement() *ast.IfStatement {
+	pos := p.pos()
 	p.nextToken()
 	condition := p.parseExpressionUntil(LOWEST, token.LBRACE)
 	if condition == nil {
@@ -1054,9 +1521,17 @@ func (p *Parser) parseIfStatement() *ast.IfStatement {
 	if thenBlock == nil {
 		return nil
 	}
+	if len(thenBlock.Statements) == 0 {
+		p.addWarning(i18n.ParserWarnEmptyIfBlock, "if "+condition.String(), i18n.T(i18n.ParserHintEmptyIfBlock))
+	}
 	var elseIfClauses []ast.ElseIfClause
 	var elseBlock *ast.Block
-	for p.peekToken.Type == token.ELSE {
+	for {
+		// else may start the line after the }
+		p.skipSemicolons()
+		if p.peekToken.Type != token.ELSE {
+			break
+		}
 		p.nextToken()
 		p.nextToken()
 		if p.currentToken.Type == token.IF {
@@ -1080,15 +1555,58 @@ func (p *Parser) parseIfStatement() *ast.IfStatement {
 			}
 			break
 		} else {
-			p.errors = append(p.errors, fmt.Sprintf("expected 'if' or '{' after 'else', got %s", p.currentToken.Type))
+			p.addError(i18n.ParserElseSyntax, p.currentToken.Type)
+			return nil
+		}
+	}
+	return &ast.IfStatement{Position: pos, Condition: condition, ThenBlock: thenBlock, ElseIfClauses: elseIfClauses, ElseBlock: elseBlock}
+}
+
+// parseGroupedExpression parses an expression in parentheses, or a tuple
+// literal when they hold several values separated by commas
+func (p *Parser) parseGroupedExpression() ast.Expression {
+	pos := p.pos()
+	p.nextToken()
+	first := p.parseExpression(LOWEST)
+	if p.peekToken.Type != token.COMMA {
+		if !p.expectPeek(token.RPAREN) {
 			return nil
 		}
+		return first
+	}
+	tuple := &ast.TupleLiteral{Position: pos, Elements: []ast.Expression{first}}
+	for p.peekToken.Type == token.COMMA {
+		p.nextToken()
+		p.nextToken()
+		tuple.Elements = append(tuple.Elements, p.parseExpression(LOWEST))
+	}
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	return tuple
+}
+
+// parseIfExpression parses an if used as a value, which needs an else block
+func (p *Parser) parseIfExpression() ast.Expression {
+	stmt := p.parseIfStatement()
+	if stmt == nil {
+		return nil
+	}
+	if stmt.ElseBlock == nil {
+		p.addError(i18n.ParserIfWithoutElse)
+		return nil
+	}
+	return &ast.IfExpression{
+		Position:      stmt.Position,
+		Condition:     stmt.Condition,
+		ThenBlock:     stmt.ThenBlock,
+		ElseIfClauses: stmt.ElseIfClauses,
+		ElseBlock:     stmt.ElseBlock,
 	}
-	return &ast.IfStatement{Condition: condition, ThenBlock: thenBlock, ElseIfClauses: elseIfClauses, ElseBlock: elseBlock}
 }
 
 func (p *Parser) parseBlockStatement() *ast.Block {
-	block := &ast.Block{}
+	block := &ast.Block{Position: p.pos()}
 	p.nextToken() // move past LBRACE
 	for p.currentToken.Type != token.RBRACE && p.currentToken.Type != token.EOF {
 		stmt := p.parseStatement()
@@ -1100,22 +1618,24 @@ func (p *Parser) parseBlockStatement() *ast.Block {
 	if p.currentToken.Type != token.RBRACE {
 		// Only report block close error if no prior errors
 		if len(p.detailedErrors) == 0 {
-			message := "expected '}' to close block"
+			message := i18n.T(i18n.ParserExpectedCloseBlock)
 			expected := "}"
 			got := string(p.currentToken.Type)
 			context := ""
 			if p.currentToken.Literal != "" {
 				context = "found '" + p.currentToken.Literal + "'"
 			}
-			suggestion := "add missing '}' to close block"
-			p.addDetailedError(message, expected, got, context, suggestion)
+			suggestion := i18n.T(i18n.ParserHintCloseBlock)
+			p.addDetailedError(i18n.ParserExpectedCloseBlock, message, expected, got, context, suggestion)
 		}
 		return nil
 	}
+	block.Rbrace = p.pos()
 	return block
 }
 
 func (p *Parser) parseWhileStatement() *ast.WhileStatement {
+	pos := p.pos()
 	p.nextToken()
 	condition := p.parseExpressionUntil(LOWEST, token.LBRACE)
 	if condition == nil {
@@ -1124,20 +1644,199 @@ func (p *Parser) parseWhileStatement() *ast.WhileStatement {
 	if !p.expectPeek(token.LBRACE) {
 		return nil
 	}
-	block := p.parseBlockStatement()
+	block := p.parseLoopBody()
 	if block == nil {
 		return nil
 	}
-	return &ast.WhileStatement{Condition: condition, Block: block}
+	if len(block.Statements) == 0 {
+		p.addWarning(i18n.ParserWarnEmptyWhileBody, "while "+condition.String(), i18n.T(i18n.ParserHintEmptyWhileBody))
+	}
+	return &ast.WhileStatement{Position: pos, Condition: condition, Block: block}
+}
+
+// parseLoopStatement parses 'loop { ... }'
+func (p *Parser) parseLoopStatement() *ast.LoopStatement {
+	pos := p.pos()
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	body := p.parseLoopBody()
+	if body == nil {
+		return nil
+	}
+	return &ast.LoopStatement{Position: pos, Body: body}
+}
+
+// parseLoopBody parses the block of a loop, in which break and continue
+// are allowed
+func (p *Parser) parseLoopBody() *ast.Block {
+	p.loopDepth++
+	defer func() { p.loopDepth-- }()
+	return p.parseBlockStatement()
+}
+
+// parseLoopControlStatement parses 'break' and 'continue'
+func (p *Parser) parseLoopControlStatement() ast.Statement {
+	pos := p.pos()
+	keyword := p.currentToken.Literal
+	if p.loopDepth == 0 {
+		p.addError(i18n.ParserOutsideLoop, keyword)
+	}
+	if p.peekToken.Type == token.SEMICOLON {
+		p.nextToken()
+	}
+	if keyword == "break" {
+		return &ast.BreakStatement{Position: pos}
+	}
+	return &ast.ContinueStatement{Position: pos}
+}
+
+// parseSpawnStatement parses 'spawn { ... }' and 'spawn f(x)'. The block is
+// not part of the loops around it, so break and continue cannot leave it.
+func (p *Parser) parseSpawnStatement() ast.Statement {
+	pos := p.pos()
+	if p.peekToken.Type == token.LBRACE {
+		p.nextToken()
+		depth := p.loopDepth
+		p.loopDepth = 0
+		body := p.parseBlockStatement()
+		p.loopDepth = depth
+		if body == nil {
+			return nil
+		}
+		return &ast.SpawnStatement{Position: pos, Body: body}
+	}
+	p.nextToken()
+	call := p.parseExpression(LOWEST)
+	switch call.(type) {
+	case *ast.FunctionCall, *ast.MethodCallExpression:
+		return &ast.SpawnStatement{Position: pos, Call: call}
+	case nil:
+		return nil
+	}
+	p.addError(i18n.ParserSpawnTarget, call)
+	return nil
+}
+
+// parseTryStatement parses 'try { ... } catch e { ... }', where the name of
+// the message is optional. The try block runs as a function literal, so
+// break and continue cannot leave it; the catch block is part of the loops
+// around it.
+func (p *Parser) parseTryStatement() ast.Statement {
+	pos := p.pos()
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	depth := p.loopDepth
+	p.loopDepth = 0
+	body := p.parseBlockStatement()
+	p.loopDepth = depth
+	if body == nil {
+		return nil
+	}
+	// catch may start the next line
+	p.skipSemicolons()
+	if !p.expectPeek(token.CATCH) {
+		return nil
+	}
+	try := &ast.TryStatement{Position: pos, Body: body}
+	if p.peekToken.Type == token.IDENT {
+		p.nextToken()
+		try.CatchName = p.currentToken.Literal
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	try.Catch = p.parseBlockStatement()
+	if try.Catch == nil {
+		return nil
+	}
+	return try
+}
+
+// parseWhenStatement parses 'when { ... }', whose arms are written like
+// those of a match: `name from ch`, `ch`, `send(ch, value)` or `_`, then
+// => and an expression or a block
+func (p *Parser) parseWhenStatement() ast.Statement {
+	// currentToken is WHEN
+	when := &ast.WhenStatement{Position: p.pos()}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	p.nextToken()
+	for p.currentToken.Type != token.RBRACE {
+		if p.currentToken.Type == token.EOF {
+			p.addError(i18n.ParserExpectedCloseBlock)
+			return nil
+		}
+		arm := ast.WhenArm{Position: p.pos()}
+		if p.currentToken.Type != token.IDENT || p.currentToken.Literal != "_" {
+			if p.currentToken.Type == token.IDENT && p.peekToken.Type == token.FROM {
+				arm.Name = p.currentToken.Literal
+				p.nextToken()
+				p.nextToken()
+			}
+			arm.Channel = p.parseExpression(LOWEST)
+			if arm.Channel == nil {
+				return nil
+			}
+			if call, ok := arm.Channel.(*ast.FunctionCall); ok && arm.Name == "" && call.Name == "send" && len(call.Arguments) == 2 {
+				arm.Channel, arm.Sent = call.Arguments[0], call.Arguments[1]
+			}
+		}
+		if !p.expectPeek(token.ARROW) {
+			return nil
+		}
+		p.nextToken()
+		if p.currentToken.Type == token.LBRACE {
+			arm.Block = p.parseBlockStatement()
+			if arm.Block == nil {
+				return nil
+			}
+		} else {
+			arm.Value = p.parseExpression(LOWEST)
+			if arm.Value == nil {
+				return nil
+			}
+		}
+		if !p.endArm(arm.Block != nil) {
+			return nil
+		}
+		when.Arms = append(when.Arms, arm)
+		p.nextToken()
+	}
+	when.Rbrace = p.pos()
+	return when
+}
+
+// endArm moves past the comma following an arm of a match or a when, which
+// may be left out after a block arm and before the closing }
+func (p *Parser) endArm(block bool) bool {
+	if p.peekToken.Type == token.COMMA {
+		p.nextToken()
+		return true
+	}
+	p.skipSemicolons()
+	return block || p.peekToken.Type == token.RBRACE || p.expectPeek(token.COMMA)
 }
 
 // parseForStatement parses 'for <ident> in <expression> { ... }'
 func (p *Parser) parseForStatement() *ast.ForStatement {
 	// currentToken is FOR
+	pos := p.pos()
 	if !p.expectPeek(token.IDENT) {
 		return nil
 	}
+	var indexName string
 	varName := p.currentToken.Literal
+	if p.peekToken.Type == token.COMMA {
+		// for i, v in items
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		indexName, varName = varName, p.currentToken.Literal
+	}
 	if !p.expectPeek(token.IN) {
 		return nil
 	}
@@ -1149,16 +1848,69 @@ func (p *Parser) parseForStatement() *ast.ForStatement {
 	if !p.expectPeek(token.LBRACE) {
 		return nil
 	}
-	body := p.parseBlockStatement()
+	body := p.parseLoopBody()
 	if body == nil {
 		return nil
 	}
-	return &ast.ForStatement{VarName: varName, Iterable: iterable, Body: body}
+	return &ast.ForStatement{Position: pos, IndexName: indexName, VarName: varName, Iterable: iterable, Body: body}
+}
+
+// parseMatchExpression parses 'match <expression> { <pattern> => <arm>, ... }'.
+// An arm is an expression or a block; the pattern _ matches any value. Like
+// in Rust, the comma after a block arm is optional.
+func (p *Parser) parseMatchExpression() ast.Expression {
+	// currentToken is MATCH
+	match := &ast.MatchExpression{Position: p.pos()}
+	p.nextToken()
+	match.Subject = p.parseExpressionUntil(LOWEST, token.LBRACE)
+	if match.Subject == nil {
+		return nil
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	p.nextToken()
+	for p.currentToken.Type != token.RBRACE {
+		if p.currentToken.Type == token.EOF {
+			p.addError(i18n.ParserExpectedCloseBlock)
+			return nil
+		}
+		arm := ast.MatchArm{Position: p.pos()}
+		if p.currentToken.Type != token.IDENT || p.currentToken.Literal != "_" {
+			arm.Pattern = p.parseExpression(LOWEST)
+			if arm.Pattern == nil {
+				return nil
+			}
+		}
+		if !p.expectPeek(token.ARROW) {
+			return nil
+		}
+		p.nextToken()
+		if p.currentToken.Type == token.LBRACE {
+			arm.Block = p.parseBlockStatement()
+			if arm.Block == nil {
+				return nil
+			}
+		} else {
+			arm.Value = p.parseExpression(LOWEST)
+			if arm.Value == nil {
+				return nil
+			}
+		}
+		if !p.endArm(arm.Block != nil) {
+			return nil
+		}
+		match.Arms = append(match.Arms, arm)
+		p.nextToken()
+	}
+	match.Rbrace = p.pos()
+	return match
 }
 
 // parseTypeDeclaration parses 'type Name<Generics> = { ... }'
 func (p *Parser) parseTypeDeclaration() *ast.TypeDeclaration {
 	// currentToken is TYPE
+	pos := p.pos()
 	if !p.expectPeek(token.IDENT) {
 		return nil
 	}
@@ -1184,27 +1936,158 @@ func (p *Parser) parseTypeDeclaration() *ast.TypeDeclaration {
 	if !p.expectPeek(token.LBRACE) {
 		return nil
 	}
-	// skip until matching '}'
-	depth := 1
-	for depth > 0 && p.currentToken.Type != token.EOF {
+	// fields: 'name: Type', separated by commas or newlines
+	var fields []ast.TypeField
+	for {
 		p.nextToken()
-		if p.currentToken.Type == token.LBRACE {
-			depth++
-		} else if p.currentToken.Type == token.RBRACE {
-			depth--
+		for p.currentToken.Type == token.COMMA || p.currentToken.Type == token.SEMICOLON {
+			p.nextToken()
+		}
+		if p.currentToken.Type == token.RBRACE {
+			break
+		}
+		if p.currentToken.Type != token.IDENT {
+			p.addError(i18n.ParserStructFieldName, p.currentToken.Type)
+			return nil
+		}
+		fieldPos, fieldName := p.pos(), p.currentToken.Literal
+		if !p.expectPeek(token.COLON) || !p.expectPeekType() {
+			return nil
+		}
+		fields = append(fields, ast.TypeField{Position: fieldPos, Name: fieldName, TypeAnn: p.parseTypeAnnotation()})
+	}
+	return &ast.TypeDeclaration{Position: pos, Name: name, Generics: generics, Fields: fields, Rbrace: p.pos()}
+}
+
+// parseEnumDeclaration parses 'enum Name { Variant(Type, ...), ... }'
+func (p *Parser) parseEnumDeclaration() *ast.EnumDeclaration {
+	// currentToken is ENUM
+	decl := &ast.EnumDeclaration{Position: p.pos()}
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	decl.Name = p.currentToken.Literal
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	// variants, separated by commas or newlines
+	for {
+		p.nextToken()
+		for p.currentToken.Type == token.COMMA || p.currentToken.Type == token.SEMICOLON {
+			p.nextToken()
+		}
+		if p.currentToken.Type == token.RBRACE {
+			break
+		}
+		if p.currentToken.Type != token.IDENT {
+			p.addError(i18n.ParserEnumVariantName, p.currentToken.Type)
+			return nil
+		}
+		variant := ast.EnumVariant{Position: p.pos(), Name: p.currentToken.Literal}
+		if p.peekToken.Type == token.LPAREN {
+			p.nextToken()
+			for p.peekToken.Type != token.RPAREN {
+				if !p.expectPeekType() {
+					return nil
+				}
+				variant.Fields = append(variant.Fields, p.parseTypeAnnotation())
+				if p.peekToken.Type == token.COMMA {
+					p.nextToken()
+				}
+			}
+			p.nextToken()
+		}
+		decl.Variants = append(decl.Variants, variant)
+	}
+	decl.Rbrace = p.pos()
+	return decl
+}
+
+// parseImplDeclaration parses impl Trait for Type { fn ... }. Which traits
+// and functions are allowed is left to the type checker.
+func (p *Parser) parseImplDeclaration() *ast.ImplDeclaration {
+	// currentToken is IMPL
+	decl := &ast.ImplDeclaration{Position: p.pos()}
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	decl.Trait = p.currentToken.Literal
+	if !p.expectPeek(token.FOR) {
+		return nil
+	}
+	if !p.expectPeekType() {
+		return nil
+	}
+	decl.TypeName = p.parseTypeAnnotation()
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	for {
+		p.nextToken()
+		for p.currentToken.Type == token.SEMICOLON {
+			p.nextToken()
+		}
+		if p.currentToken.Type == token.RBRACE {
+			break
+		}
+		if p.currentToken.Type != token.FN {
+			p.addError(i18n.ParserImplMember, p.currentToken.Type)
+			return nil
+		}
+		method := p.parseFunctionDefinition()
+		if method == nil {
+			return nil
+		}
+		decl.Methods = append(decl.Methods, method)
+	}
+	decl.Rbrace = p.pos()
+	return decl
+}
+
+// parseTryExpression parses the postfix ? operator. currentToken is '?'.
+func (p *Parser) parseTryExpression(left ast.Expression) ast.Expression {
+	return &ast.TryExpression{Position: left.Pos(), Value: left}
+}
+
+// parseIndexExpression parses items[i] and the slices items[low:high],
+// where either bound may be omitted. currentToken is '['.
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	p.nextToken()
+	var low ast.Expression
+	if p.currentToken.Type != token.COLON {
+		if low = p.parseExpression(LOWEST); low == nil {
+			return nil
 		}
+		if p.peekToken.Type != token.COLON {
+			if !p.expectPeek(token.RBRACKET) {
+				return nil
+			}
+			return &ast.IndexExpression{Position: left.Pos(), Left: left, Index: low}
+		}
+		p.nextToken()
+	}
+	// currentToken is ':'
+	slice := &ast.SliceExpression{Position: left.Pos(), Left: left, Low: low}
+	if p.peekToken.Type != token.RBRACKET {
+		p.nextToken()
+		if slice.High = p.parseExpression(LOWEST); slice.High == nil {
+			return nil
+		}
+	}
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
 	}
-	return &ast.TypeDeclaration{Name: name, Generics: generics, Fields: nil}
+	return slice
 }
 
 // parseMemberExpression parses property access expressions e.g., obj.field
 func (p *Parser) parseMemberExpression(left ast.Expression) ast.Expression {
-	expr := &ast.MemberExpression{Object: left}
+	expr := &ast.MemberExpression{Position: left.Pos(), Object: left}
 	// current token is DOT, advance to next (property name)
 	p.nextToken()
 	if p.currentToken.Type != token.IDENT {
 		// Unexpected token, record error and return nil
-		p.addDetailedError("expected property name after '.'", ">IDENT<", p.currentToken.Literal, p.input, "ensure valid identifier follows '.'")
+		p.addDetailedError(i18n.ParserExpectedPropertyName, i18n.T(i18n.ParserExpectedPropertyName), ">IDENT<", p.currentToken.Literal, p.input, i18n.T(i18n.ParserHintExpectedPropertyName))
 		return nil
 	}
 	expr.Property = p.currentToken.Literal
@@ -2,9 +2,12 @@ package parser
 
 import (
 	"fmt" // Added import for fmt
+	"math"
+	"strings"
 	"testing"
 
 	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/diagnostics"
 	"github.com/linkalls/zeno-lang/lexer"
 )
 
@@ -12,7 +15,8 @@ func TestLetStatements(t *testing.T) {
 	input := `
 let x = 5
 let y = 10
-let foobar = 838383
+let mut foobar = 838383
+const limit: int = 3
 `
 
 	l := lexer.New(input)
@@ -24,17 +28,20 @@ let foobar = 838383
 	if program == nil {
 		t.Fatalf("ParseProgram() returned nil")
 	}
-	if len(program.Statements) != 3 {
-		t.Fatalf("program.Statements does not contain 3 statements. got=%d",
+	if len(program.Statements) != 4 {
+		t.Fatalf("program.Statements does not contain 4 statements. got=%d",
 			len(program.Statements))
 	}
 
 	tests := []struct {
 		expectedIdentifier string
+		expectedMutable    bool
+		expectedConstant   bool
 	}{
-		{"x"},
-		{"y"},
-		{"foobar"},
+		{"x", false, false},
+		{"y", false, false},
+		{"foobar", true, false},
+		{"limit", false, true},
 	}
 
 	for i, tt := range tests {
@@ -42,6 +49,50 @@ let foobar = 838383
 		if !testLetStatement(t, stmt, tt.expectedIdentifier) {
 			return
 		}
+		if mutable := stmt.(*ast.LetDeclaration).Mutable; mutable != tt.expectedMutable {
+			t.Errorf("statement %d: Mutable = %t, want %t", i, mutable, tt.expectedMutable)
+		}
+		if constant := stmt.(*ast.LetDeclaration).Constant; constant != tt.expectedConstant {
+			t.Errorf("statement %d: Constant = %t, want %t", i, constant, tt.expectedConstant)
+		}
+	}
+}
+
+func TestTypeAnnotations(t *testing.T) {
+	input := `let xs: []int = [1, 2]
+let grid: [][]float = []
+fn sum(values: []int, fallback: Result<[]string, string>): Option<[]int> {
+    return none
+}
+type Bag = {
+    items: []string
+}
+enum Shape {
+    Poly([]float),
+}`
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	var got []string
+	for _, stmt := range program.Statements {
+		switch s := stmt.(type) {
+		case *ast.LetDeclaration:
+			got = append(got, *s.TypeAnn)
+		case *ast.FunctionDefinition:
+			for _, param := range s.Parameters {
+				got = append(got, param.Type)
+			}
+			got = append(got, *s.ReturnType)
+		case *ast.TypeDeclaration:
+			got = append(got, s.Fields[0].TypeAnn)
+		case *ast.EnumDeclaration:
+			got = append(got, s.Variants[0].Fields[0])
+		}
+	}
+	expected := []string{"[]int", "[][]float", "[]int", "Result<[]string,string>", "Option<[]int>", "[]string", "[]float"}
+	if strings.Join(got, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected type annotations %v, got %v", expected, got)
 	}
 }
 
@@ -137,6 +188,137 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestIntegerLiteralOutOfRange(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let big = 9223372036854775808", "integer literal 9223372036854775808 is out of range for int, which holds -9223372036854775808 to 9223372036854775807"},
+		{"let b = 256byte", "integer literal 256byte is out of range for byte, which holds 0 to 255"},
+		{"let n = 2147483648i32", "integer literal 2147483648i32 is out of range for i32, which holds -2147483648 to 2147483647"},
+		{"let n = 18446744073709551616u64", "integer literal 18446744073709551616u64 is out of range for u64, which holds 0 to 18446744073709551615"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		errors := p.DetailedErrors()
+		if len(errors) == 0 || errors[0].Code != "Z0032" {
+			t.Fatalf("expected error Z0032 for %q, got %v", tt.input, p.Errors())
+		}
+		if errors[0].Message != tt.expected {
+			t.Errorf("expected %q, got %q", tt.expected, errors[0].Message)
+		}
+	}
+}
+
+func TestNegatedIntegerLiteral(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+		suffix   string
+	}{
+		{"-9223372036854775808", math.MinInt64, ""},
+		{"-2147483648i32", math.MinInt32, "i32"},
+		{"-9223372036854775808i64", math.MinInt64, "i64"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("%q: expected *ast.IntegerLiteral, got %T", tt.input, stmt.Expression)
+		}
+		if literal.Value != tt.expected || literal.Suffix != tt.suffix {
+			t.Errorf("%q: got %d%s", tt.input, literal.Value, literal.Suffix)
+		}
+	}
+
+	// Literals in range stay negations
+	p := New(lexer.New("-5"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if _, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.UnaryExpression); !ok {
+		t.Errorf("-5: expected *ast.UnaryExpression")
+	}
+
+	p = New(lexer.New("let d = -9223372036854775809"))
+	p.ParseProgram()
+	if errors := p.DetailedErrors(); len(errors) == 0 || errors[0].Code != "Z0032" {
+		t.Errorf("expected error Z0032, got %v", p.Errors())
+	}
+}
+
+func TestCharLiteral(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected rune
+	}{
+		{`'a'`, 'a'},
+		{`'\''`, '\''},
+		{`'\n'`, '\n'},
+		{`'\u00e9'`, 'é'},
+		{`'\xff'`, 'ÿ'},
+		{`'日'`, '日'},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.CharLiteral)
+		if !ok {
+			t.Fatalf("expected *ast.CharLiteral for %s, got %T", tt.input, stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("expected %q for %s, got %q", tt.expected, tt.input, literal.Value)
+		}
+	}
+
+	for _, input := range []string{`''`, `'ab'`, `'\u00e9x'`} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		errors := p.DetailedErrors()
+		if len(errors) == 0 || errors[0].Code != "Z0033" {
+			t.Errorf("expected error Z0033 for %s, got %v", input, p.Errors())
+		}
+	}
+}
+
+func TestOperatorPrecedence(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a + b * c", "(a + (b * c))"},
+		{"a % b * c", "((a % b) * c)"},
+		{"a + b % c", "(a + (b % c))"},
+		{"a % b == 0", "((a % b) == 0)"},
+		{"-a % b", "((-a) % b)"},
+		{"a | b & c", "(a | (b & c))"},
+		{"a ^ b + c", "((a ^ b) + c)"},
+		{"1 << n - 1", "((1 << n) - 1)"},
+		{"a & mask == 0", "((a & mask) == 0)"},
+		{"a >> 2 | b << 2 && ok", "(((a >> 2) | (b << 2)) && ok)"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if len(program.Statements) != 1 {
+			t.Fatalf("%q: expected 1 statement, got %d", tt.input, len(program.Statements))
+		}
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("%q: expected *ast.ExpressionStatement, got %T", tt.input, program.Statements[0])
+		}
+		if got := stmt.Expression.String(); got != tt.expected {
+			t.Errorf("%q: expected %s, got %s", tt.input, tt.expected, got)
+		}
+	}
+}
+
 func testLetStatement(t *testing.T, s ast.Statement, name string) bool {
 	letStmt, ok := s.(*ast.LetDeclaration)
 	if !ok {
@@ -152,6 +334,37 @@ func testLetStatement(t *testing.T, s ast.Statement, name string) bool {
 	return true
 }
 
+// The element type a let annotates is expected of the elements of the array
+// literal it is set to, and ints convert to float
+func TestArrayLiteralAnnotatedElementType(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string // empty if no error is expected
+	}{
+		{"let xs: []float = [1, 2.5]", ""},
+		{"let xs: []float = [1, 2]", ""},
+		{"let xs: []int = [1, 2.5]", "mismatched types in array literal: expected INT, got FLOAT at index 1"},
+		{"let xs: []float = [2.5, 1]", ""},
+		{"let xs: []float = [1, \"a\"]", "mismatched types in array literal: expected FLOAT, got STRING at index 1"},
+		{"let xs: []float = [\"a\", 1]", "mismatched types in array literal: expected STRING, got INT at index 1"},
+		{"let xs: []float = [[1], [2.5]]", "array element type is not a primitive type (int, float, string, bool), got *ast.ArrayLiteral for first element"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		errors := p.Errors()
+		if tt.expected == "" {
+			if len(errors) > 0 {
+				t.Errorf("%q: unexpected errors %v", tt.input, errors)
+			}
+			continue
+		}
+		if len(errors) == 0 || errors[0] != tt.expected {
+			t.Errorf("%q: expected %q, got %v", tt.input, tt.expected, errors)
+		}
+	}
+}
+
 func TestArrayLiteralTypeChecking(t *testing.T) {
 	tests := []struct {
 		input          string
@@ -447,3 +660,759 @@ func testFloatLiteral(t *testing.T, exp ast.Expression, value float64) bool {
 	}
 	return true
 }
+
+func TestEmptyBlockWarnings(t *testing.T) {
+	input := `
+let x = 1
+if x > 0 {
+}
+while x < 10 {
+}
+`
+	l := lexer.New(input)
+	p := New(l)
+	p.ParseProgram()
+	checkParserErrors(t, p)
+
+	warnings := p.Warnings()
+	if len(warnings) != 2 {
+		t.Fatalf("expected 2 warnings, got %d: %v", len(warnings), warnings)
+	}
+	if warnings[0].Message != "empty block in 'if' statement" {
+		t.Errorf("unexpected first warning: %q", warnings[0].Message)
+	}
+	if warnings[1].Message != "empty body in 'while' loop" {
+		t.Errorf("unexpected second warning: %q", warnings[1].Message)
+	}
+	for _, w := range warnings {
+		if !w.Warning {
+			t.Errorf("warning %q not marked as warning", w.Message)
+		}
+	}
+
+	found := p.Diagnostics()
+	if len(found) != 2 || found[0].Severity != diagnostics.Warning || found[0].Pos.Line != warnings[0].Line || found[0].Code != warnings[0].Code {
+		t.Errorf("unexpected diagnostics: %v", found)
+	}
+}
+
+func TestDeprecatedAttribute(t *testing.T) {
+	input := `
+@deprecated("use sum instead")
+pub fn add(a: int, b: int): int {
+    return a + b
+}
+@deprecated
+type Pair = {
+    a: int
+}
+`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("expected 2 statements, got %d", len(program.Statements))
+	}
+	fn, ok := program.Statements[0].(*ast.FunctionDefinition)
+	if !ok {
+		t.Fatalf("expected *ast.FunctionDefinition, got %T", program.Statements[0])
+	}
+	if !fn.IsPublic || fn.Deprecated == nil || fn.Deprecated.Message != "use sum instead" {
+		t.Errorf("expected public deprecated function with hint, got public=%v deprecated=%v", fn.IsPublic, fn.Deprecated)
+	}
+	decl, ok := program.Statements[1].(*ast.TypeDeclaration)
+	if !ok {
+		t.Fatalf("expected *ast.TypeDeclaration, got %T", program.Statements[1])
+	}
+	if decl.Deprecated == nil || decl.Deprecated.Message != "" {
+		t.Errorf("expected deprecated type without hint, got %v", decl.Deprecated)
+	}
+}
+
+func TestInvalidAttributes(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"@inline\nfn f() {\n}", "unknown attribute '@inline'"},
+		{"@deprecated\nlet x = 1", "'@deprecated' must be followed by a function or type declaration"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		errors := p.Errors()
+		if len(errors) == 0 || errors[0] != tt.expectedError {
+			t.Errorf("expected error %q, got %v", tt.expectedError, errors)
+		}
+	}
+}
+
+func TestLoopStatement(t *testing.T) {
+	input := `
+fn main() {
+    loop {
+        if done() {
+            break
+        }
+        continue
+    }
+}
+`
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	fn, ok := program.Statements[0].(*ast.FunctionDefinition)
+	if !ok {
+		t.Fatalf("expected *ast.FunctionDefinition, got %T", program.Statements[0])
+	}
+	loop, ok := fn.Body[0].(*ast.LoopStatement)
+	if !ok {
+		t.Fatalf("expected *ast.LoopStatement, got %T", fn.Body[0])
+	}
+	if len(loop.Body.Statements) != 2 {
+		t.Fatalf("expected 2 statements in loop body, got %d", len(loop.Body.Statements))
+	}
+	ifStmt := loop.Body.Statements[0].(*ast.IfStatement)
+	if _, ok := ifStmt.ThenBlock.Statements[0].(*ast.BreakStatement); !ok {
+		t.Errorf("expected *ast.BreakStatement, got %T", ifStmt.ThenBlock.Statements[0])
+	}
+	if _, ok := loop.Body.Statements[1].(*ast.ContinueStatement); !ok {
+		t.Errorf("expected *ast.ContinueStatement, got %T", loop.Body.Statements[1])
+	}
+}
+
+func TestForStatement(t *testing.T) {
+	tests := []struct {
+		input     string
+		indexName string
+		varName   string
+	}{
+		{"for v in items {\n}", "", "v"},
+		{"for i, v in items {\n}", "i", "v"},
+		{"for _, v in items {\n}", "_", "v"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		stmt, ok := program.Statements[0].(*ast.ForStatement)
+		if !ok {
+			t.Fatalf("%q: expected *ast.ForStatement, got %T", tt.input, program.Statements[0])
+		}
+		if stmt.IndexName != tt.indexName || stmt.VarName != tt.varName {
+			t.Errorf("%q: expected variables %q, %q, got %q, %q", tt.input, tt.indexName, tt.varName, stmt.IndexName, stmt.VarName)
+		}
+	}
+}
+
+func TestIfExpression(t *testing.T) {
+	input := `
+let sign = if n < 0 {
+    -1
+} else if n == 0 {
+    0
+} else {
+    1
+}
+`
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	let, ok := program.Statements[0].(*ast.LetDeclaration)
+	if !ok {
+		t.Fatalf("expected *ast.LetDeclaration, got %T", program.Statements[0])
+	}
+	ifExpr, ok := let.ValueExpression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("expected *ast.IfExpression, got %T", let.ValueExpression)
+	}
+	if ifExpr.Condition.String() != "(n < 0)" {
+		t.Errorf("expected condition (n < 0), got %s", ifExpr.Condition)
+	}
+	if len(ifExpr.ElseIfClauses) != 1 || len(ifExpr.Blocks()) != 3 {
+		t.Errorf("expected 1 else if clause and 3 branches, got %d and %d", len(ifExpr.ElseIfClauses), len(ifExpr.Blocks()))
+	}
+
+	p = New(lexer.New("let x = if ok {\n    1\n}"))
+	p.ParseProgram()
+	errors := p.DetailedErrors()
+	if len(errors) != 1 || errors[0].Code != "Z0027" {
+		t.Errorf("expected a Z0027 error for an if without else, got %v", p.Errors())
+	}
+}
+
+func TestTuples(t *testing.T) {
+	input := `
+fn divmod(a: int, b: int): (int, int) {
+    return (a / b, a % b)
+}
+let mut (q, _) = divmod(7, 2)
+let x = (1 + 2) * 3
+`
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	fn, ok := program.Statements[0].(*ast.FunctionDefinition)
+	if !ok {
+		t.Fatalf("expected *ast.FunctionDefinition, got %T", program.Statements[0])
+	}
+	if fn.ReturnType == nil || *fn.ReturnType != "(int, int)" {
+		t.Errorf("expected return type (int, int), got %v", fn.ReturnType)
+	}
+	ret := fn.Body[0].(*ast.ReturnStatement)
+	if tuple, ok := ret.Value.(*ast.TupleLiteral); !ok || len(tuple.Elements) != 2 {
+		t.Errorf("expected a tuple of 2 elements, got %s", ret.Value)
+	}
+	let, ok := program.Statements[1].(*ast.LetDeclaration)
+	if !ok {
+		t.Fatalf("expected *ast.LetDeclaration, got %T", program.Statements[1])
+	}
+	if !let.Mutable || let.Name != "" || strings.Join(let.Names, ",") != "q,_" {
+		t.Errorf("expected let mut (q, _), got %s", let)
+	}
+	grouped := program.Statements[2].(*ast.LetDeclaration)
+	if grouped.ValueExpression.String() != "((1 + 2) * 3)" {
+		t.Errorf("expected ((1 + 2) * 3), got %s", grouped.ValueExpression)
+	}
+}
+
+func TestDestructuringLet(t *testing.T) {
+	tests := []struct {
+		input   string
+		pattern ast.Destructure
+		names   string
+	}{
+		{"let (q, r) = divmod(7, 2)", ast.DestructureTuple, "(q, r)"},
+		{"let {name, age} = person", ast.DestructureFields, "{name, age}"},
+		{"let mut [first, _] = items", ast.DestructureArray, "[first, _]"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		let, ok := program.Statements[0].(*ast.LetDeclaration)
+		if !ok {
+			t.Fatalf("expected *ast.LetDeclaration, got %T", program.Statements[0])
+		}
+		if let.Destructure != tt.pattern || let.Destructure.Enclose(let.Names) != tt.names {
+			t.Errorf("input %q: expected pattern %s, got %s", tt.input, tt.names, let.Destructure.Enclose(let.Names))
+		}
+		if let.String() != tt.input {
+			t.Errorf("expected %q, got %q", tt.input, let.String())
+		}
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	input := `
+let name = match n {
+    1 => "one",
+    -1 => "minus one"
+    _ => {
+        println(n)
+        "many"
+    }
+}
+`
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) == 0 {
+		t.Fatalf("expected an error for the missing comma, got none")
+	}
+
+	input = strings.Replace(input, `"minus one"`, `"minus one",`, 1)
+	p = New(lexer.New(input))
+	program = p.ParseProgram()
+	checkParserErrors(t, p)
+
+	let := program.Statements[0].(*ast.LetDeclaration)
+	match, ok := let.ValueExpression.(*ast.MatchExpression)
+	if !ok {
+		t.Fatalf("expected *ast.MatchExpression, got %T", let.ValueExpression)
+	}
+	if match.Subject.String() != "n" {
+		t.Errorf("expected subject n, got %s", match.Subject)
+	}
+	if len(match.Arms) != 3 {
+		t.Fatalf("expected 3 arms, got %d", len(match.Arms))
+	}
+	if match.Arms[1].Pattern.String() != "(-1)" || match.Arms[1].Value.String() != `"minus one"` {
+		t.Errorf("unexpected second arm %s => %s", match.Arms[1].Pattern, match.Arms[1].Value)
+	}
+	last := match.Arms[2]
+	if !last.IsWildcard() || last.Block == nil || len(last.Block.Statements) != 2 {
+		t.Errorf("expected a wildcard arm with a block of 2 statements, got %+v", last)
+	}
+	if match.Rbrace.Line != 9 {
+		t.Errorf("expected closing brace on line 9, got %d", match.Rbrace.Line)
+	}
+}
+
+func TestEnumDeclaration(t *testing.T) {
+	input := `@deprecated
+enum Shape {
+    Circle(float),
+    Rect(float, float)
+    Empty,
+}`
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	decl, ok := program.Statements[0].(*ast.EnumDeclaration)
+	if !ok {
+		t.Fatalf("expected *ast.EnumDeclaration, got %T", program.Statements[0])
+	}
+	if decl.Name != "Shape" || decl.Deprecated == nil {
+		t.Errorf("expected deprecated enum Shape, got %s (deprecated: %v)", decl.Name, decl.Deprecated)
+	}
+	var variants []string
+	for _, variant := range decl.Variants {
+		variants = append(variants, variant.String())
+	}
+	if got := strings.Join(variants, " "); got != "Circle(float) Rect(float, float) Empty" {
+		t.Errorf("unexpected variants %s", got)
+	}
+	if decl.Rbrace.Line != 6 {
+		t.Errorf("expected closing brace on line 6, got %d", decl.Rbrace.Line)
+	}
+
+	p = New(lexer.New("enum Shape {\n    \"circle\"\n}"))
+	p.ParseProgram()
+	if errors := p.Errors(); len(errors) == 0 || !strings.Contains(errors[0], "enum variant name must be identifier") {
+		t.Errorf("expected a variant name error, got %v", errors)
+	}
+}
+
+func TestTryExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let n = parse(s)?", "parse(s)?"},
+		{"let n = parse(s)? + 1", "(parse(s)? + 1)"},
+		{"let n = load(path)?.size", "load(path)?.size"},
+		{"let n = -parse(s)?", "(-parse(s)?)"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		let, ok := program.Statements[0].(*ast.LetDeclaration)
+		if !ok {
+			t.Fatalf("expected *ast.LetDeclaration, got %T", program.Statements[0])
+		}
+		if got := let.ValueExpression.String(); got != tt.expected {
+			t.Errorf("%q: expected %s, got %s", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestIndexExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = items[0]", "items[0]"},
+		{"let x = items[i + 1] * 2", "(items[(i + 1)] * 2)"},
+		{"let x = grid[0][1]", "grid[0][1]"},
+		{"let x = [1, 2, 3][1]", "[1, 2, 3][1]"},
+		{"let x = items[1:3]", "items[1:3]"},
+		{"let x = items[:n]", "items[:n]"},
+		{"let x = items[n:]", "items[n:]"},
+		{"let x = items[:]", "items[:]"},
+		{"let x = load()[0].name", "load()[0].name"},
+		{"let x = items\n[1, 2]", "items"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		let, ok := program.Statements[0].(*ast.LetDeclaration)
+		if !ok {
+			t.Fatalf("expected *ast.LetDeclaration, got %T", program.Statements[0])
+		}
+		if got := let.ValueExpression.String(); got != tt.expected {
+			t.Errorf("%q: expected %s, got %s", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestMethodCallExpression(t *testing.T) {
+	tests := []struct {
+		input     string
+		object    string
+		method    string
+		arguments int
+	}{
+		{"let x = name.toUpper()", "name", "toUpper", 0},
+		{"let x = line.split(\",\")", "line", "split", 1},
+		{"let x = items[0].contains(3)", "items[0]", "contains", 1},
+		{"let x = user.name.length()", "user.name", "length", 0},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		let := program.Statements[0].(*ast.LetDeclaration)
+		call, ok := let.ValueExpression.(*ast.MethodCallExpression)
+		if !ok {
+			t.Fatalf("%q: expected *ast.MethodCallExpression, got %T", tt.input, let.ValueExpression)
+		}
+		if call.Object.String() != tt.object || call.Method != tt.method || len(call.Arguments) != tt.arguments {
+			t.Errorf("%q: got %s", tt.input, call)
+		}
+	}
+}
+
+func TestSpawnStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"spawn work(1)", "spawn work(1)"},
+		{"spawn items.push(1)", "spawn items.push(1)"},
+		{"spawn {\n    work(1)\n}", "spawn {\n  work(1)\n}"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		spawn, ok := program.Statements[0].(*ast.SpawnStatement)
+		if !ok {
+			t.Fatalf("expected *ast.SpawnStatement for %q, got %T", tt.input, program.Statements[0])
+		}
+		if got := spawn.String(); got != tt.expected {
+			t.Errorf("expected %s for %q, got %s", tt.expected, tt.input, got)
+		}
+	}
+
+	errorTests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"spawn 1 + 2", "'spawn' must be followed by a block or a function call, got (1 + 2)"},
+		{"while true {\n    spawn {\n        break\n    }\n}", "'break' outside of a loop"},
+	}
+	for _, tt := range errorTests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		errors := p.Errors()
+		if len(errors) == 0 || errors[0] != tt.expectedError {
+			t.Errorf("expected error %q, got %v", tt.expectedError, errors)
+		}
+	}
+}
+
+func TestTryStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"try {\n    risky()\n} catch e {\n    println(e)\n}", "try {\n  risky()\n} catch e {\n  println(e)\n}"},
+		{"try {\n    risky()\n} catch {\n}", "try {\n  risky()\n} catch {\n}"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		try, ok := program.Statements[0].(*ast.TryStatement)
+		if !ok {
+			t.Fatalf("expected *ast.TryStatement for %q, got %T", tt.input, program.Statements[0])
+		}
+		if got := try.String(); got != tt.expected {
+			t.Errorf("expected %s for %q, got %s", tt.expected, tt.input, got)
+		}
+	}
+
+	errorTests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"try {\n    risky()\n}", "expected next token to be CATCH, got EOF instead"},
+		{"while true {\n    try {\n        break\n    } catch {\n    }\n}", "'break' outside of a loop"},
+	}
+	for _, tt := range errorTests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		errors := p.Errors()
+		if len(errors) == 0 || errors[0] != tt.expectedError {
+			t.Errorf("expected error %q, got %v", tt.expectedError, errors)
+		}
+	}
+}
+
+func TestImplDeclaration(t *testing.T) {
+	input := `impl Add for Money {
+    fn add(a: Money, b: Money): Money {
+        return Money{cents: a.cents + b.cents}
+    }
+}`
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	impl, ok := program.Statements[0].(*ast.ImplDeclaration)
+	if !ok {
+		t.Fatalf("expected *ast.ImplDeclaration, got %T", program.Statements[0])
+	}
+	if impl.Trait != "Add" || impl.TypeName != "Money" {
+		t.Errorf("expected impl Add for Money, got impl %s for %s", impl.Trait, impl.TypeName)
+	}
+	if len(impl.Methods) != 1 || impl.Methods[0].Name != "add" || len(impl.Methods[0].Parameters) != 2 {
+		t.Fatalf("expected the method add(a, b), got %v", impl.Methods)
+	}
+	if impl.Rbrace.Line != 5 {
+		t.Errorf("expected the closing brace on line 5, got %d", impl.Rbrace.Line)
+	}
+
+	p = New(lexer.New("impl Add for Money {\n    let zero = 0\n}"))
+	p.ParseProgram()
+	if errors := p.Errors(); len(errors) == 0 || errors[0] != "an impl block can only declare functions, got LET" {
+		t.Errorf("expected an impl member error, got %v", errors)
+	}
+}
+
+func TestImportAliases(t *testing.T) {
+	p := New(lexer.New(`import { type Regex, compile as pattern, matches } from "std/regex"`))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	imp, ok := program.Statements[0].(*ast.ImportStatement)
+	if !ok {
+		t.Fatalf("expected *ast.ImportStatement, got %T", program.Statements[0])
+	}
+	if len(imp.Imports) != 3 || imp.Imports[1].Name != "compile" || imp.Imports[1].LocalName() != "pattern" || imp.Imports[2].LocalName() != "matches" {
+		t.Fatalf("expected compile imported as pattern, got %v", imp.Imports)
+	}
+	if imp.String() != `import {type Regex, compile as pattern, matches} from "std/regex"` {
+		t.Errorf("unexpected import %q", imp.String())
+	}
+
+	p = New(lexer.New(`import * as regex from "std/regex"`))
+	program = p.ParseProgram()
+	checkParserErrors(t, p)
+	imp, ok = program.Statements[0].(*ast.ImportStatement)
+	if !ok || imp.Namespace != "regex" || imp.Module != "std/regex" || len(imp.Imports) != 0 {
+		t.Fatalf("expected std/regex imported as regex, got %v", program.Statements[0])
+	}
+
+	p = New(lexer.New(`pub import { compile } from "std/regex"`))
+	program = p.ParseProgram()
+	checkParserErrors(t, p)
+	imp, ok = program.Statements[0].(*ast.ImportStatement)
+	if !ok || !imp.IsPublic || imp.String() != `pub import {compile} from "std/regex"` {
+		t.Fatalf("expected a pub import, got %v", program.Statements[0])
+	}
+	p = New(lexer.New(`pub import * as regex from "std/regex"`))
+	p.ParseProgram()
+	if errors := p.Errors(); len(errors) != 1 || errors[0] != "pub import * as regex cannot re-export a whole module; list the items to re-export" {
+		t.Errorf("expected a pub namespace import error, got %v", errors)
+	}
+
+	p = New(lexer.New(`import { type Regex as Pattern } from "std/regex"`))
+	p.ParseProgram()
+	if errors := p.Errors(); len(errors) != 1 || errors[0] != "the type Regex cannot be imported under another name; only functions can be renamed with as" {
+		t.Errorf("expected a type alias error, got %v", errors)
+	}
+}
+
+func TestWhenStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"when {\n    ch => work(1),\n    _ => idle()\n}", "when { ch => work(1), _ => idle() }"},
+		{"when {\n    v from jobs => work(v)\n}", "when { v from jobs => work(v) }"},
+		{"when {\n    send(out, 1 + 2) => {\n        work(1)\n    }\n}", "when { send(out, (1 + 2)) => {\n  work(1)\n} }"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		when, ok := program.Statements[0].(*ast.WhenStatement)
+		if !ok {
+			t.Fatalf("expected *ast.WhenStatement for %q, got %T", tt.input, program.Statements[0])
+		}
+		if got := when.String(); got != tt.expected {
+			t.Errorf("expected %s for %q, got %s", tt.expected, tt.input, got)
+		}
+	}
+
+	when := New(lexer.New("when {\n    send(out, 1) => work(1)\n}")).ParseProgram().Statements[0].(*ast.WhenStatement)
+	if arm := when.Arms[0]; arm.Sent == nil || arm.Channel.String() != "out" {
+		t.Errorf("expected a send to out, got %+v", arm)
+	}
+}
+
+func TestLoopControlOutsideLoop(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"fn main() {\n    break\n}", "'break' outside of a loop"},
+		{"fn main() {\n    while true {\n    }\n    continue\n}", "'continue' outside of a loop"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		errors := p.Errors()
+		if len(errors) == 0 || errors[0] != tt.expectedError {
+			t.Errorf("expected error %q, got %v", tt.expectedError, errors)
+		}
+	}
+}
+
+func TestNodePositions(t *testing.T) {
+	input := `fn main() {
+    let total = add(1, 2) * 3
+    if total > 5 {
+        println(total)
+    }
+}`
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	fn := program.Statements[0].(*ast.FunctionDefinition)
+	let := fn.Body[0].(*ast.LetDeclaration)
+	product := let.ValueExpression.(*ast.BinaryExpression)
+	call := product.Left.(*ast.FunctionCall)
+	ifStmt := fn.Body[1].(*ast.IfStatement)
+	println := ifStmt.ThenBlock.Statements[0].(*ast.ExpressionStatement)
+
+	tests := []struct {
+		node     ast.Node
+		expected string
+	}{
+		{fn, "1:1"},
+		{let, "2:5"},
+		{product, "2:17"},
+		{call, "2:17"},
+		{call.Arguments[1], "2:24"},
+		{product.Right, "2:29"},
+		{ifStmt, "3:5"},
+		{ifStmt.Condition, "3:8"},
+		{println, "4:9"},
+	}
+	for _, tt := range tests {
+		if got := tt.node.Pos().String(); got != tt.expected {
+			t.Errorf("%T %q: expected position %s, got %s", tt.node, tt.node.String(), tt.expected, got)
+		}
+	}
+}
+
+func TestErrorPositions(t *testing.T) {
+	p := New(lexer.New("fn main() {\n    let x = 1\n    break\n}"))
+	p.ParseProgram()
+	errors := p.DetailedErrors()
+	if len(errors) != 1 {
+		t.Fatalf("expected 1 error, got %v", p.Errors())
+	}
+	if errors[0].Line != 3 || errors[0].Column != 5 {
+		t.Errorf("expected error at 3:5, got %d:%d", errors[0].Line, errors[0].Column)
+	}
+}
+
+func TestProgramComments(t *testing.T) {
+	input := `// greeting
+fn main() {
+    let x = 1 // one
+}`
+	p := New(lexer.NewWithComments(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("expected 1 statement, got %d", len(program.Statements))
+	}
+	expected := []string{"1:1 // greeting", "3:15 // one"}
+	if len(program.Comments) != len(expected) {
+		t.Fatalf("expected %d comments, got %d", len(expected), len(program.Comments))
+	}
+	for i, c := range program.Comments {
+		if got := c.Pos().String() + " " + c.Text; got != expected[i] {
+			t.Errorf("comment %d: expected %q, got %q", i, expected[i], got)
+		}
+	}
+
+	// Without NewWithComments comments are skipped as before
+	p = New(lexer.New(input))
+	if program := p.ParseProgram(); len(program.Comments) != 0 {
+		t.Errorf("expected no comments, got %d", len(program.Comments))
+	}
+}
+
+func TestNestedTypeArguments(t *testing.T) {
+	p := New(lexer.New("let x: Result<Option<int>> = f()\nlet y = x"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if len(program.Statements) != 2 {
+		t.Fatalf("expected 2 statements, got %d", len(program.Statements))
+	}
+	let, ok := program.Statements[0].(*ast.LetDeclaration)
+	if !ok || let.TypeAnn == nil {
+		t.Fatalf("expected a let with a type annotation, got %s", program.Statements[0])
+	}
+	if *let.TypeAnn != "Result<Option<int>>" {
+		t.Errorf("expected type Result<Option<int>>, got %s", *let.TypeAnn)
+	}
+}
+
+func TestGenericCall(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"decode<User>(text)", "decode<User>(text)"},
+		{"decode<Result<Option<int>>>(text)", "decode<Result<Option<int>>>(text)"},
+		{"pair<[]int, string>(a, b)", "pair<[]int, string>(a, b)"},
+		{"a < b", "(a < b)"},
+		{"a < b && c > d", "((a < b) && (c > d))"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if len(program.Statements) != 1 {
+			t.Fatalf("expected 1 statement for %q, got %d", tt.input, len(program.Statements))
+		}
+		if got := program.Statements[0].String(); got != tt.expected {
+			t.Errorf("expected %s for %q, got %s", tt.expected, tt.input, got)
+		}
+	}
+}
+
+func TestStatementTermination(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"f\n(x)", []string{"f", "x"}},
+		{"a\n-1", []string{"a", "(-1)"}},
+		{"let xs = a\n[1, 2]", []string{"let xs = a", "[1, 2]"}},
+		{"let a = 1; let b = 2", []string{"let a = 1", "let b = 2"}},
+		{"let total = a +\n    b", []string{"let total = (a + b)"}},
+		{"f(\n    1,\n    2\n)", []string{"f(1, 2)"}},
+		{"let p = Point{\n    x: 1,\n    y: 2\n}\np", []string{"let p = Point{x: 1, y: 2}", "p"}},
+		{"let n = match x {\n    1 => a,\n    _ => b\n}", []string{"let n = match x { 1 => a, _ => b }"}},
+		{"if a {\n    f()\n} else {\n    g()\n}", []string{"if a {\n  f()\n} else {\n  g()\n}"}},
+		{"if a {\n    f()\n}\nelse if b {\n    g()\n}\nelse {\n    h()\n}\nh()", []string{"if a {\n  f()\n} else if b {\n  g()\n} else {\n  h()\n}", "h()"}},
+		{"let n = if a {\n    1\n}\nelse {\n    2\n}", []string{"let n = if a {\n  1\n} else {\n  2\n}"}},
+		{"if a {\n    f()\n}\ng()", []string{"if a {\n  f()\n}", "g()"}},
+		{"try {\n    f()\n}\ncatch e {\n    g(e)\n}", []string{"try {\n  f()\n} catch e {\n  g(e)\n}"}},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		var got []string
+		for _, stmt := range program.Statements {
+			got = append(got, stmt.String())
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.expected, "\n") {
+			t.Errorf("expected %q for %q, got %q", tt.expected, tt.input, got)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Package repl implements the interactive Zeno prompt started by
+// `zeno repl`. Input is evaluated with the evaluator package, so variables
+// and functions persist from one entry to the next.
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/evaluator"
+	"github.com/linkalls/zeno-lang/lexer"
+	"github.com/linkalls/zeno-lang/parser"
+	"github.com/linkalls/zeno-lang/token"
+)
+
+const (
+	// Prompt is shown when the REPL waits for a new entry
+	Prompt = "zeno> "
+	// ContinuationPrompt is shown while an entry has unclosed brackets
+	ContinuationPrompt = "  ... "
+)
+
+// prelude is evaluated at startup so print and println work without an
+// import
+const prelude = `import { print, println } from "std/fmt"`
+
+const help = `Enter Zeno statements or expressions; the value of an expression is printed.
+Entries with unclosed brackets continue on the next line.
+Commands:
+  :help    show this help
+  :reset   forget all variables, functions and imports
+  :quit    leave the REPL (also :q or end of input)
+`
+
+// Start reads entries from in until it is exhausted or :quit is entered,
+// writing prompts, results and errors to out
+func Start(in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	ev := newEvaluator(out)
+	var entry strings.Builder
+
+	for {
+		if entry.Len() == 0 {
+			fmt.Fprint(out, Prompt)
+		} else {
+			fmt.Fprint(out, ContinuationPrompt)
+		}
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return scanner.Err()
+		}
+		line := scanner.Text()
+
+		if entry.Len() == 0 {
+			switch strings.TrimSpace(line) {
+			case "":
+				continue
+			case ":help":
+				fmt.Fprint(out, help)
+				continue
+			case ":reset":
+				ev = newEvaluator(out)
+				continue
+			case ":quit", ":q":
+				return nil
+			}
+		}
+
+		entry.WriteString(line)
+		entry.WriteByte('\n')
+		if depth(entry.String()) > 0 {
+			continue
+		}
+		source := entry.String()
+		entry.Reset()
+		eval(ev, source, out)
+	}
+}
+
+// newEvaluator creates an evaluator with the prelude loaded
+func newEvaluator(out io.Writer) *evaluator.Evaluator {
+	ev := evaluator.New(out)
+	eval(ev, prelude, out)
+	return ev
+}
+
+// eval parses and evaluates one entry, printing the value of a trailing
+// expression
+func eval(ev *evaluator.Evaluator, source string, out io.Writer) {
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if errors := p.Errors(); len(errors) > 0 {
+		for _, err := range errors {
+			fmt.Fprintf(out, "error: %s\n", err)
+		}
+		return
+	}
+	value, err := ev.Eval(program)
+	if err != nil {
+		fmt.Fprintf(out, "error: %v\n", err)
+		return
+	}
+	if value != nil {
+		fmt.Fprintln(out, inspect(value))
+	}
+}
+
+// inspect formats a value for display, quoting strings so they can be told
+// apart from other values
+func inspect(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprint(value)
+}
+
+// depth returns how many brackets are still open in source. Brackets in
+// strings and comments are skipped by the lexer.
+func depth(source string) int {
+	l := lexer.New(source)
+	open := 0
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		switch tok.Type {
+		case token.LBRACE, token.LBRACKET, token.LPAREN:
+			open++
+		case token.RBRACE, token.RBRACKET, token.RPAREN:
+			open--
+		}
+	}
+	return open
+}
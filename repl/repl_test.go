@@ -0,0 +1,68 @@
+package repl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/linkalls/zeno-lang/stdlib"
+)
+
+func TestStart(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	input := strings.Join([]string{
+		"let x = 20",
+		"x + 1",
+		`"a" + "b"`,
+		"fn greet(name: string) {",
+		`    println("hello", name)`,
+		"}",
+		`greet("zeno")`,
+		"missing",
+		":reset",
+		"x",
+		":quit",
+		"1",
+	}, "\n")
+	var out bytes.Buffer
+	if err := Start(strings.NewReader(input), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{
+		Prompt + Prompt + "21\n",
+		Prompt + `"ab"` + "\n",
+		Prompt + ContinuationPrompt + ContinuationPrompt + Prompt + "hello zeno\n",
+		Prompt + "error: undefined variable 'missing'",
+		Prompt + Prompt + "error: undefined variable 'x'",
+	}
+	got := out.String()
+	for _, want := range expected {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "\n1\n") {
+		t.Errorf("input after :quit was evaluated:\n%s", got)
+	}
+}
+
+func TestDepth(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"let x = 1", 0},
+		{"fn f() {", 1},
+		{"let a = [1, {b: (", 3},
+		{`let s = "{"`, 0},
+		{"// {", 0},
+		{"}", -1},
+	}
+	for _, tt := range tests {
+		if got := depth(tt.input); got != tt.expected {
+			t.Errorf("depth(%q) = %d, want %d", tt.input, got, tt.expected)
+		}
+	}
+}
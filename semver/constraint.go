@@ -0,0 +1,135 @@
+package semver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Constraint is a set of version ranges. A version satisfies the constraint
+// if it satisfies every comparison of at least one range.
+//
+// Supported syntax:
+//
+//	1.2.3, =1.2.3          exact version
+//	>1.2.3 >=1.2.3 <1.2.3 <=1.2.3 !=1.2.3
+//	^1.2.3                 >=1.2.3 <2.0.0 (>=0.2.3 <0.3.0 below 1.0.0)
+//	~1.2.3                 >=1.2.3 <1.3.0
+//	*                      any version
+//	>=1.0.0 <2.0.0         comparisons separated by spaces must all hold
+//	^1.0.0 || ^2.0.0       ranges separated by || are alternatives
+//
+// As with npm, a prerelease version only satisfies a range that mentions a
+// prerelease of the same MAJOR.MINOR.PATCH, so ">=1.0.0 <2.0.0" does not
+// match "2.0.0-beta".
+type Constraint struct {
+	ranges [][]comparison
+}
+
+type comparison struct {
+	op      string
+	version Version
+}
+
+// ParseConstraint parses a constraint expression
+func ParseConstraint(s string) (Constraint, error) {
+	var c Constraint
+	for _, alternative := range strings.Split(s, "||") {
+		fields := strings.Fields(alternative)
+		if len(fields) == 0 {
+			return Constraint{}, fmt.Errorf("invalid constraint %q: empty range", s)
+		}
+		var r []comparison
+		for _, field := range fields {
+			comparisons, err := parseComparison(field)
+			if err != nil {
+				return Constraint{}, fmt.Errorf("invalid constraint %q: %w", s, err)
+			}
+			r = append(r, comparisons...)
+		}
+		c.ranges = append(c.ranges, r)
+	}
+	return c, nil
+}
+
+// parseComparison expands one constraint term into plain comparisons
+func parseComparison(term string) ([]comparison, error) {
+	if term == "*" {
+		return nil, nil
+	}
+	op := ""
+	for _, prefix := range []string{">=", "<=", "!=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(term, prefix) {
+			op = prefix
+			break
+		}
+	}
+	v, err := Parse(term[len(op):])
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case "", "=":
+		return []comparison{{"=", v}}, nil
+	case "^":
+		upper := Version{Major: v.Major + 1}
+		if v.Major == 0 && v.Minor > 0 {
+			upper = Version{Minor: v.Minor + 1}
+		} else if v.Major == 0 {
+			upper = Version{Patch: v.Patch + 1}
+		}
+		return []comparison{{">=", v}, {"<", upper}}, nil
+	case "~":
+		return []comparison{{">=", v}, {"<", Version{Major: v.Major, Minor: v.Minor + 1}}}, nil
+	}
+	return []comparison{{op, v}}, nil
+}
+
+// Check reports whether v satisfies the constraint
+func (c Constraint) Check(v Version) bool {
+	for _, r := range c.ranges {
+		if matchesAll(r, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAll(r []comparison, v Version) bool {
+	if len(v.Prerelease) > 0 && !allowsPrerelease(r, v) {
+		return false
+	}
+	for _, cmp := range r {
+		result := Compare(v, cmp.version)
+		var ok bool
+		switch cmp.op {
+		case "=":
+			ok = result == 0
+		case "!=":
+			ok = result != 0
+		case ">":
+			ok = result > 0
+		case ">=":
+			ok = result >= 0
+		case "<":
+			ok = result < 0
+		case "<=":
+			ok = result <= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// allowsPrerelease reports whether a comparison of r names a prerelease of
+// the same release as v
+func allowsPrerelease(r []comparison, v Version) bool {
+	for _, cmp := range r {
+		w := cmp.version
+		if len(w.Prerelease) > 0 && w.Major == v.Major && w.Minor == v.Minor && w.Patch == v.Patch {
+			return true
+		}
+	}
+	return false
+}
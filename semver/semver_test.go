@@ -0,0 +1,108 @@
+package semver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1.2.3", "1.2.3"},
+		{"v0.10.0", "0.10.0"},
+		{"1.0.0-rc.1", "1.0.0-rc.1"},
+		{"1.0.0-alpha+build.7", "1.0.0-alpha+build.7"},
+	}
+	for _, tt := range tests {
+		v, err := Parse(tt.input)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if v.String() != tt.expected {
+			t.Errorf("Parse(%q) = %s, want %s", tt.input, v, tt.expected)
+		}
+	}
+
+	for _, input := range []string{"", "1.2", "1.2.3.4", "1.02.3", "a.b.c", "1.2.3-", "1.2.3-a..b", "1.2.3+"} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("Parse(%q) should fail", input)
+		}
+	}
+}
+
+func TestCompareAndSort(t *testing.T) {
+	// Ordered by increasing precedence, from the semver specification
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+		"1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.2.0", "2.0.0",
+	}
+	for i := 0; i+1 < len(ordered); i++ {
+		a, b := MustParse(ordered[i]), MustParse(ordered[i+1])
+		if Compare(a, b) != -1 || Compare(b, a) != 1 {
+			t.Errorf("expected %s < %s", a, b)
+		}
+	}
+	if Compare(MustParse("1.0.0+a"), MustParse("1.0.0+b")) != 0 {
+		t.Errorf("build metadata must not affect precedence")
+	}
+
+	var versions []Version
+	for i := len(ordered) - 1; i >= 0; i-- {
+		versions = append(versions, MustParse(ordered[i]))
+	}
+	Sort(versions)
+	var sorted []string
+	for _, v := range versions {
+		sorted = append(sorted, v.String())
+	}
+	if !reflect.DeepEqual(sorted, ordered) {
+		t.Errorf("Sort = %v, want %v", sorted, ordered)
+	}
+}
+
+func TestConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		expected   bool
+	}{
+		{"^1.2.0", "1.2.0", true},
+		{"^1.2.0", "1.9.9", true},
+		{"^1.2.0", "2.0.0", false},
+		{"^1.2.0", "1.1.9", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.0.3", "0.0.4", false},
+		{"~1.2.3", "1.2.9", true},
+		{"~1.2.3", "1.3.0", false},
+		{">=1.0.0 <2.0.0", "1.5.0", true},
+		{">=1.0.0 <2.0.0", "2.0.0", false},
+		{"^1.0.0 || ^3.0.0", "3.1.0", true},
+		{"^1.0.0 || ^3.0.0", "2.1.0", false},
+		{"1.2.3", "1.2.3", true},
+		{"!=1.2.3", "1.2.3", false},
+		{"*", "0.0.1", true},
+		{">=1.0.0 <2.0.0", "2.0.0-beta", false},
+		{"^1.0.0", "1.1.0-rc.1", false},
+		{">=1.1.0-rc.1", "1.1.0-rc.2", true},
+	}
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Errorf("ParseConstraint(%q) failed: %v", tt.constraint, err)
+			continue
+		}
+		if got := c.Check(MustParse(tt.version)); got != tt.expected {
+			t.Errorf("%q.Check(%s) = %v, want %v", tt.constraint, tt.version, got, tt.expected)
+		}
+	}
+
+	for _, input := range []string{"", "^", ">=1.x", "1.0.0 ||"} {
+		if _, err := ParseConstraint(input); err == nil {
+			t.Errorf("ParseConstraint(%q) should fail", input)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+// Package std holds the sources of the Zeno standard library, which are
+// built into zeno so that programs compile from any directory.
+package std
+
+import "embed"
+
+// FS holds the standard library modules, "io.zeno" for std/io
+//
+//go:embed *.zeno
+var FS embed.FS
@@ -0,0 +1,153 @@
+// Package stdlib locates the Zeno standard library modules and indexes the
+// functions they export, so that missing imports can be suggested.
+package stdlib
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/lexer"
+	"github.com/linkalls/zeno-lang/parser"
+	"github.com/linkalls/zeno-lang/std"
+)
+
+// Dir is a directory searched for standard library modules before the ones
+// built into zeno, set with --std-path or to $ZENO_HOME/std. The modules it
+// holds replace the built-in ones, and it may add modules of its own.
+var Dir = defaultDir()
+
+func defaultDir() string {
+	if home := os.Getenv("ZENO_HOME"); home != "" {
+		return filepath.Join(home, "std")
+	}
+	return ""
+}
+
+// programCache maps a module source path to its parsed program
+var programCache = make(map[string]*ast.Program)
+
+// Path returns the source file of a standard library module: the file in
+// Dir, or "std/io.zeno" for the built-in std/io.
+func Path(module string) string {
+	path, _ := locate(module)
+	return path
+}
+
+// Source reads the source of a standard library module and returns it with
+// the path of its file
+func Source(module string) ([]byte, string, error) {
+	path, builtin := locate(module)
+	if builtin {
+		content, err := std.FS.ReadFile(strings.TrimPrefix(path, "std/"))
+		return content, path, err
+	}
+	content, err := os.ReadFile(path)
+	return content, path, err
+}
+
+// locate returns the source file of a module, and whether it is built in
+func locate(module string) (string, bool) {
+	name := strings.TrimPrefix(module, "std/") + ".zeno"
+	if Dir != "" {
+		path := filepath.Join(Dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, false
+		}
+	}
+	return "std/" + name, true
+}
+
+// Modules returns the available standard library modules in import form
+// ("std/fmt", "std/io", ...), sorted by name.
+func Modules() []string {
+	seen := make(map[string]bool)
+	var modules []string
+	add := func(entries []fs.DirEntry) {
+		for _, entry := range entries {
+			name, ok := strings.CutSuffix(entry.Name(), ".zeno")
+			if !entry.IsDir() && ok && !seen[name] {
+				seen[name] = true
+				modules = append(modules, "std/"+name)
+			}
+		}
+	}
+	if Dir != "" {
+		entries, _ := os.ReadDir(Dir)
+		add(entries)
+	}
+	entries, _ := std.FS.ReadDir(".")
+	add(entries)
+	sort.Strings(modules)
+	return modules
+}
+
+// Program returns the parsed source of a module, or nil when the module
+// cannot be read or has syntax errors.
+func Program(module string) *ast.Program {
+	path := Path(module)
+	if program, ok := programCache[path]; ok {
+		return program
+	}
+	var program *ast.Program
+	if content, _, err := Source(module); err == nil {
+		p := parser.New(lexer.New(string(content)))
+		program = p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			program = nil
+		}
+	}
+	programCache[path] = program
+	return program
+}
+
+// Function returns the public function declared by module with the given
+// name, or nil
+func Function(module, name string) *ast.FunctionDefinition {
+	program := Program(module)
+	if program == nil {
+		return nil
+	}
+	for _, stmt := range program.Statements {
+		if fn, ok := stmt.(*ast.FunctionDefinition); ok && fn.IsPublic && fn.Name == name {
+			return fn
+		}
+	}
+	return nil
+}
+
+// Exports returns the public function names of a module
+func Exports(module string) []string {
+	program := Program(module)
+	if program == nil {
+		return nil
+	}
+	var names []string
+	for _, stmt := range program.Statements {
+		if fn, ok := stmt.(*ast.FunctionDefinition); ok && fn.IsPublic {
+			names = append(names, fn.Name)
+		}
+	}
+	return names
+}
+
+// ModulesExporting returns the standard library modules that export a
+// public function with the given name.
+func ModulesExporting(name string) []string {
+	var modules []string
+	for _, module := range Modules() {
+		if Function(module, name) != nil {
+			modules = append(modules, module)
+		}
+	}
+	return modules
+}
+
+// ImportLine formats an import statement for names from module
+func ImportLine(module string, names ...string) string {
+	return fmt.Sprintf("import { %s } from \"%s\"", strings.Join(names, ", "), module)
+}
@@ -7,6 +7,10 @@ type TokenType string
 type Token struct {
 	Type    TokenType
 	Literal string
+	// Line and Column locate the first character of the token, starting at 1.
+	// Columns count characters, not bytes.
+	Line   int
+	Column int
 }
 
 // Token types for the Zeno language
@@ -14,12 +18,14 @@ const (
 	// Special tokens
 	ILLEGAL TokenType = "ILLEGAL"
 	EOF     TokenType = "EOF"
+	COMMENT TokenType = "COMMENT" // only produced by lexers created with NewWithComments
 
 	// Identifiers + literals
 	IDENT  TokenType = "IDENT"  // add, foobar, x, y, ...
 	INT    TokenType = "INT"    // 1343456
 	FLOAT  TokenType = "FLOAT"  // 3.14159
 	STRING TokenType = "STRING" // "foobar"
+	CHAR   TokenType = "CHAR"   // 'a'
 
 	// Keywords
 	LET    TokenType = "LET"
@@ -41,6 +47,16 @@ const (
 	CONTINUE TokenType = "CONTINUE"
 	TYPE     TokenType = "TYPE"
 	IN       TokenType = "IN"
+	MATCH    TokenType = "MATCH"
+	ENUM     TokenType = "ENUM"
+	MUT      TokenType = "MUT"
+	SPAWN    TokenType = "SPAWN"
+	WHEN     TokenType = "WHEN"
+	TRY      TokenType = "TRY"
+	CATCH    TokenType = "CATCH"
+	IMPL     TokenType = "IMPL"
+	CONST    TokenType = "CONST"
+	AS       TokenType = "AS"
 
 	// Operators
 	ASSIGN   TokenType = "="
@@ -58,10 +74,16 @@ const (
 	GTE      TokenType = ">="
 	AND      TokenType = "&&"
 	OR       TokenType = "||"
+	BIT_AND  TokenType = "&"
+	BIT_OR   TokenType = "|"
+	BIT_XOR  TokenType = "^"
+	SHL      TokenType = "<<"
+	SHR      TokenType = ">>"
+	ARROW    TokenType = "=>"
 
 	// Delimiters
 	COMMA     TokenType = ","
-	SEMICOLON TokenType = ""
+	SEMICOLON TokenType = ";" // or the end of a line, see lexer.Lexer.NextToken
 	COLON     TokenType = ":"
 	DOT       TokenType = "."
 	DOTDOTDOT TokenType = "..."
@@ -72,6 +94,7 @@ const (
 	LBRACKET  TokenType = "["
 	RBRACKET  TokenType = "]"
 	QUESTION  TokenType = "?"
+	AT        TokenType = "@"
 )
 
 // keywords maps string literals to their token types
@@ -93,8 +116,22 @@ var keywords = map[string]TokenType{
 	"break":    BREAK,
 	"continue": CONTINUE,
 	"type":     TYPE,
+	"match":    MATCH,
+	"enum":     ENUM,
+	"mut":      MUT,
+	"spawn":    SPAWN,
+	"when":     WHEN,
+	"try":      TRY,
+	"catch":    CATCH,
+	"impl":     IMPL,
+	"const":    CONST,
+	"as":       AS,
 }
 
+// IntegerSuffixes are the sized integer types an integer literal can end
+// with, as in 10u64
+var IntegerSuffixes = map[string]bool{"i32": true, "i64": true, "u32": true, "u64": true, "byte": true}
+
 // LookupIdent checks if the identifier is a keyword
 func LookupIdent(ident string) TokenType {
 	if tok, ok := keywords[ident]; ok {
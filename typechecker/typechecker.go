@@ -0,0 +1,2354 @@
+// Package typechecker resolves the types of a Zeno program before code
+// generation and reports the type errors that would otherwise only show up
+// when the generated Go code is built.
+package typechecker
+
+import (
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/linkalls/zeno-lang/ast"
+	"github.com/linkalls/zeno-lang/deps"
+	"github.com/linkalls/zeno-lang/diagnostics"
+	"github.com/linkalls/zeno-lang/i18n"
+	"github.com/linkalls/zeno-lang/lexer"
+	"github.com/linkalls/zeno-lang/parser"
+	"github.com/linkalls/zeno-lang/stdlib"
+	"github.com/linkalls/zeno-lang/types"
+)
+
+// Error is a type error located in the Zeno source
+type Error struct {
+	Code       string // stable diagnostic code, e.g. Z0117
+	Message    string
+	Suggestion string       // optional fix shown as help
+	Pos        ast.Position // location in the Zeno source, zero if unknown
+}
+
+func (e *Error) Error() string {
+	message := e.Message
+	if e.Code != "" {
+		message = "[" + e.Code + "] " + message
+	}
+	message = i18n.T(i18n.LabelTypeError, message)
+	if e.Pos.IsValid() {
+		message += "\n  --> " + i18n.T(i18n.LabelLocation, e.Pos.Line, e.Pos.Column)
+	}
+	if e.Suggestion != "" {
+		message += "\n" + i18n.T(i18n.LabelHelp) + ": " + e.Suggestion
+	}
+	return message
+}
+
+// Diagnostic converts the error to the representation shared by all phases
+func (e *Error) Diagnostic(file string) diagnostics.Diagnostic {
+	return diagnostics.Diagnostic{
+		Severity:   diagnostics.Error,
+		Code:       e.Code,
+		Source:     "typechecker",
+		File:       file,
+		Pos:        e.Pos,
+		Message:    e.Message,
+		Suggestion: e.Suggestion,
+	}
+}
+
+// builtin describes a function available without an import, see
+// generator/builtins.go
+type builtin struct {
+	params     int
+	returnType types.Type
+}
+
+var builtins = map[string]builtin{
+	"len":    {params: 1, returnType: types.IntType},
+	"str":    {params: 1, returnType: types.StringType},
+	"int":    {params: 1, returnType: &types.ResultType{ValueType: types.IntType, ErrorType: types.StringType}},
+	"float":  {params: 1, returnType: &types.ResultType{ValueType: types.FloatType, ErrorType: types.StringType}},
+	"typeOf": {params: 1, returnType: types.StringType},
+	// The conversions, whose names say what they convert to
+	"toString":  {params: 1, returnType: types.StringType},
+	"toInt":     {params: 1, returnType: &types.ResultType{ValueType: types.IntType, ErrorType: types.StringType}},
+	"toFloat":   {params: 1, returnType: &types.ResultType{ValueType: types.FloatType, ErrorType: types.StringType}},
+	"parseBool": {params: 1, returnType: &types.ResultType{ValueType: types.BoolType, ErrorType: types.StringType}},
+	// The conversions to the sized integer types, which fail when the value
+	// does not fit
+	"i32":  {params: 1, returnType: &types.ResultType{ValueType: types.I32Type, ErrorType: types.StringType}},
+	"i64":  {params: 1, returnType: &types.ResultType{ValueType: types.I64Type, ErrorType: types.StringType}},
+	"u32":  {params: 1, returnType: &types.ResultType{ValueType: types.U32Type, ErrorType: types.StringType}},
+	"u64":  {params: 1, returnType: &types.ResultType{ValueType: types.U64Type, ErrorType: types.StringType}},
+	"byte": {params: 1, returnType: &types.ResultType{ValueType: types.ByteType, ErrorType: types.StringType}},
+	// The conversion of a code point or a one-character string
+	"char":  {params: 1, returnType: &types.ResultType{ValueType: types.CharType, ErrorType: types.StringType}},
+	"args":  {params: 0, returnType: &types.ArrayType{ElementType: types.StringType}},
+	"wait":  {params: 0, returnType: types.AnyType},
+	"panic": {params: 1, returnType: types.AnyType},
+	// The assertions of tests
+	"assertEq":   {params: 2, returnType: types.AnyType},
+	"assertTrue": {params: 1, returnType: types.AnyType},
+}
+
+// method describes a builtin method of strings or arrays, see
+// generator/methods.go
+type method struct {
+	params []methodParam
+	// returnType is nil for the methods whose type depends on the call
+	returnType types.Type
+}
+
+// methodParam is a parameter of a method. Its type is nil for the element
+// type of the array the method is called on.
+type methodParam struct {
+	name string
+	typ  types.Type
+}
+
+var stringMethods = map[string]method{
+	"length":     {returnType: types.IntType},
+	"toUpper":    {returnType: types.StringType},
+	"toLower":    {returnType: types.StringType},
+	"trim":       {returnType: types.StringType},
+	"contains":   {params: []methodParam{{"substr", types.StringType}}, returnType: types.BoolType},
+	"startsWith": {params: []methodParam{{"prefix", types.StringType}}, returnType: types.BoolType},
+	"endsWith":   {params: []methodParam{{"suffix", types.StringType}}, returnType: types.BoolType},
+	"split":      {params: []methodParam{{"sep", types.StringType}}, returnType: &types.ArrayType{ElementType: types.StringType}},
+	"replace":    {params: []methodParam{{"old", types.StringType}, {"new", types.StringType}}, returnType: types.StringType},
+	"indexOf":    {params: []methodParam{{"substr", types.StringType}}, returnType: types.IntType},
+}
+
+var arrayMethods = map[string]method{
+	"length":   {returnType: types.IntType},
+	"push":     {params: []methodParam{{"value", nil}}, returnType: types.AnyType},
+	"contains": {params: []methodParam{{"value", nil}}, returnType: types.BoolType},
+	"join":     {params: []methodParam{{"sep", types.StringType}}, returnType: types.StringType},
+	"map":      {params: []methodParam{{"f", types.AnyType}}},
+	"filter":   {params: []methodParam{{"f", types.AnyType}}},
+}
+
+// collectionMethod returns the method of a std/collections type, whose
+// parameters and result take the types of its elements
+func collectionMethod(t *types.CollectionType, name string) (method, bool) {
+	element := t.Elements[0]
+	value := []methodParam{{"value", element}}
+	switch name {
+	case "length":
+		return method{returnType: types.IntType}, true
+	case "values":
+		if t.Name == "SortedMap" {
+			return method{returnType: &types.ArrayType{ElementType: t.Elements[1]}}, true
+		}
+		return method{returnType: &types.ArrayType{ElementType: element}}, true
+	}
+	switch t.Name + "." + name {
+	case "Set.add", "Set.remove", "Set.contains":
+		return method{params: value, returnType: types.BoolType}, true
+	case "Queue.enqueue", "Stack.push":
+		return method{params: value, returnType: types.AnyType}, true
+	case "Queue.dequeue", "Queue.peek", "Stack.pop", "Stack.peek":
+		return method{returnType: &types.OptionType{ValueType: element}}, true
+	case "Queue.isEmpty", "Stack.isEmpty":
+		return method{returnType: types.BoolType}, true
+	case "SortedMap.set":
+		return method{params: []methodParam{{"key", element}, {"value", t.Elements[1]}}, returnType: types.AnyType}, true
+	case "SortedMap.get":
+		return method{params: []methodParam{{"key", element}}, returnType: &types.OptionType{ValueType: t.Elements[1]}}, true
+	case "SortedMap.remove", "SortedMap.contains":
+		return method{params: []methodParam{{"key", element}}, returnType: types.BoolType}, true
+	case "SortedMap.keys":
+		return method{returnType: &types.ArrayType{ElementType: element}}, true
+	}
+	return method{}, false
+}
+
+// checker holds the declarations visible in the checked file
+type checker struct {
+	dir       string // directory of the checked file, for relative imports
+	functions map[string]*ast.FunctionDefinition
+	typeDecls map[string]*ast.TypeDeclaration
+	enums     map[string]*ast.EnumDeclaration
+	variants  map[string]*ast.EnumDeclaration // enums by variant name
+	// local holds the types declared in the checked file, and impls the
+	// functions of the traits struct types implement, by type and trait
+	local   map[string]bool
+	impls   map[string]map[string]*ast.FunctionDefinition
+	imports map[string][]string // imported functions by module, as written in the import
+	// unresolved holds names imported from modules that could not be read
+	unresolved map[string]bool
+	current    *ast.FunctionDefinition
+	// spawned is the scope around the innermost spawn block being checked,
+	// whose variables the block has copies of, nil outside spawn blocks
+	spawned *types.SymbolTable
+	// tried is set while checking a try block, which runs as a function
+	// literal
+	tried bool
+	// module holds the module-level variables, which functions see, and top
+	// the variables of the top level, which are the same unless the top
+	// level runs as main. deps holds the module-level variables and
+	// functions each module-level variable and function refers to, for the
+	// ones in owners while their value or body is checked.
+	module *types.SymbolTable
+	top    *types.SymbolTable
+	owners []string
+	deps   map[string][]string
+	// moduleLets holds the module-level lets by name, which are checked
+	// when a declaration before them refers to them, and checkedLets the
+	// lets being checked or checked already
+	moduleLets  map[string]*ast.LetDeclaration
+	checkedLets map[*ast.LetDeclaration]bool
+	errors      []*Error
+	// exprTypes records the type of each expression checked, for Types
+	exprTypes map[ast.Expression]types.Type
+}
+
+// Check type checks program, read from sourceFile, and returns every error
+// found, ordered by position. Imported functions and types are resolved like
+// the generator resolves them; imports that cannot be read are left to it.
+func Check(program *ast.Program, sourceFile string) []*Error {
+	return checkProgram(program, sourceFile, nil)
+}
+
+// Types type checks program like Check and also returns the type of each
+// expression, for tools showing them
+func Types(program *ast.Program, sourceFile string) (map[ast.Expression]types.Type, []*Error) {
+	exprTypes := make(map[ast.Expression]types.Type)
+	return exprTypes, checkProgram(program, sourceFile, exprTypes)
+}
+
+func checkProgram(program *ast.Program, sourceFile string, exprTypes map[ast.Expression]types.Type) []*Error {
+	c := &checker{
+		dir:         filepath.Dir(sourceFile),
+		functions:   make(map[string]*ast.FunctionDefinition),
+		typeDecls:   make(map[string]*ast.TypeDeclaration),
+		enums:       make(map[string]*ast.EnumDeclaration),
+		variants:    make(map[string]*ast.EnumDeclaration),
+		local:       make(map[string]bool),
+		impls:       make(map[string]map[string]*ast.FunctionDefinition),
+		imports:     make(map[string][]string),
+		unresolved:  make(map[string]bool),
+		module:      types.NewSymbolTable(nil),
+		deps:        make(map[string][]string),
+		moduleLets:  make(map[string]*ast.LetDeclaration),
+		checkedLets: make(map[*ast.LetDeclaration]bool),
+		exprTypes:   exprTypes,
+	}
+	for _, stmt := range program.Statements {
+		switch s := stmt.(type) {
+		case *ast.ImportStatement:
+			c.importModule(s)
+		case *ast.FunctionDefinition:
+			c.functions[s.Name] = s
+		case *ast.TypeDeclaration:
+			c.typeDecls[s.Name] = s
+			c.local[s.Name] = true
+		case *ast.EnumDeclaration:
+			c.declareEnum(s)
+			c.local[s.Name] = true
+		case *ast.ImplDeclaration:
+			c.declareImpl(s)
+		}
+	}
+
+	c.top = c.module
+	if !program.ModuleLevel() {
+		c.top = types.NewSymbolTable(c.module)
+	}
+	for _, stmt := range program.Statements {
+		if let, ok := stmt.(*ast.LetDeclaration); ok && (c.top == c.module || let.Constant) {
+			for _, name := range let.BoundNames() {
+				c.moduleLets[name] = let
+			}
+		}
+	}
+	// Functions are checked after the top level, once the module-level
+	// variables they may refer to are declared
+	var functions []ast.Statement
+	for _, stmt := range program.Statements {
+		switch s := stmt.(type) {
+		case *ast.FunctionDefinition, *ast.ImplDeclaration:
+			functions = append(functions, stmt)
+		case *ast.LetDeclaration:
+			c.checkTopLet(s)
+		default:
+			c.checkStatement(stmt, c.top)
+		}
+	}
+	c.checkStatements(functions, c.top)
+	c.checkInitOrder(program)
+	sort.SliceStable(c.errors, func(i, j int) bool {
+		a, b := c.errors[i].Pos, c.errors[j].Pos
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return c.errors
+}
+
+func (c *checker) errorf(node ast.Node, id i18n.MessageID, args ...interface{}) *Error {
+	err := &Error{Code: i18n.Code(id), Message: i18n.T(id, args...)}
+	if node != nil {
+		err.Pos = node.Pos()
+	}
+	c.errors = append(c.errors, err)
+	return err
+}
+
+// importModule records the public functions and the types an import makes
+// available
+func (c *checker) importModule(stmt *ast.ImportStatement) {
+	module := c.loadModule(stmt.Module)
+	if stmt.Namespace != "" {
+		c.module.Define(stmt.Namespace, &types.NamespaceType{Module: stmt.Module})
+	}
+	if module == nil {
+		// The generator reports why; calls to the names are not checked
+		for _, item := range stmt.Imports {
+			c.unresolved[item.LocalName()] = true
+		}
+		if stmt.Namespace != "" {
+			c.unresolved[stmt.Namespace] = true
+		}
+		return
+	}
+	if stmt.Namespace != "" {
+		// The functions are known as io.readFile, and come with the types
+		// they use
+		for _, name := range module.Exports() {
+			_, declaring, original := c.resolveImport(stmt.Module, module, name, false)
+			for _, s := range declaring.Statements {
+				if decl, ok := s.(*ast.FunctionDefinition); ok && decl.IsPublic && decl.Name == original {
+					c.functions[stmt.Namespace+"."+name] = decl
+				}
+			}
+		}
+		for _, s := range module.Statements {
+			if decl, ok := s.(*ast.TypeDeclaration); ok {
+				c.typeDecls[decl.Name] = decl
+				c.declareImpls(module, decl.Name)
+			}
+		}
+		return
+	}
+	for _, item := range stmt.Imports {
+		path, declaring, name := c.resolveImport(stmt.Module, module, item.Name, item.IsType)
+		for _, s := range declaring.Statements {
+			switch decl := s.(type) {
+			case *ast.FunctionDefinition:
+				if !item.IsType && decl.IsPublic && decl.Name == name {
+					c.functions[item.LocalName()] = decl
+					c.imports[stmt.Module] = append(c.imports[stmt.Module], item.String())
+				}
+			case *ast.TypeDeclaration:
+				// The functions of std modules come with the types they use
+				if decl.Name == name || (!item.IsType && strings.HasPrefix(path, "std/")) {
+					c.typeDecls[decl.Name] = decl
+					c.declareImpls(declaring, decl.Name)
+				}
+			case *ast.EnumDeclaration:
+				if decl.Name == name {
+					c.declareEnum(decl)
+				}
+			}
+		}
+	}
+}
+
+// loadModule parses the standard library or user module imported from path,
+// or returns nil when it cannot be read
+func (c *checker) loadModule(path string) *ast.Program {
+	if strings.HasPrefix(path, "std/") {
+		return stdlib.Program(path)
+	}
+	return c.parseUserModule(path)
+}
+
+// resolveImport follows the pub imports re-exporting name from the module at
+// path to the module declaring it, and returns that module with its path
+// and the name declared there
+func (c *checker) resolveImport(path string, module *ast.Program, name string, isType bool) (string, *ast.Program, string) {
+	seen := map[string]bool{path: true}
+	for {
+		reexport, item, ok := module.Reexport(name, isType)
+		if !ok {
+			return path, module, name
+		}
+		next := reexport.ModulePath(path)
+		program := c.loadModule(next)
+		if program == nil || seen[next] {
+			return path, module, name
+		}
+		seen[next] = true
+		path, module, name = next, program, item.Name
+	}
+}
+
+// declareImpl records the function of the trait an impl implements, once
+// it is known to be one
+func (c *checker) declareImpl(impl *ast.ImplDeclaration) {
+	trait, ok := ast.Traits[impl.Trait]
+	if !ok {
+		return
+	}
+	if method, ok := impl.Method(trait.Method); ok {
+		if c.impls[impl.TypeName] == nil {
+			c.impls[impl.TypeName] = make(map[string]*ast.FunctionDefinition)
+		}
+		c.impls[impl.TypeName][impl.Trait] = method
+	}
+}
+
+// declareImpls records the traits a module implements for a type imported
+// from it, which its values bring along
+func (c *checker) declareImpls(module *ast.Program, typeName string) {
+	for _, s := range module.Statements {
+		if impl, ok := s.(*ast.ImplDeclaration); ok && impl.TypeName == typeName {
+			c.declareImpl(impl)
+		}
+	}
+}
+
+// declareEnum makes an enum and the constructors of its variants available
+func (c *checker) declareEnum(decl *ast.EnumDeclaration) {
+	c.enums[decl.Name] = decl
+	for _, variant := range decl.Variants {
+		c.variants[variant.Name] = decl
+	}
+}
+
+func (c *checker) parseUserModule(modulePath string) *ast.Program {
+	file := modulePath
+	if !strings.HasSuffix(file, ".zeno") {
+		file += ".zeno"
+	}
+	if strings.HasPrefix(file, "./") || strings.HasPrefix(file, "../") {
+		file = filepath.Join(c.dir, file)
+	} else if deps.IsRemote(modulePath) {
+		resolved, err := deps.Resolve(c.dir, modulePath)
+		if err != nil {
+			return nil
+		}
+		file = resolved
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	p := parser.New(lexer.New(string(content)))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		return nil
+	}
+	return program
+}
+
+// resolveType maps a type annotation to its type. Names that are not known,
+// such as type parameters, are any so that they never cause errors.
+func (c *checker) resolveType(name string) types.Type {
+	switch name {
+	case "int":
+		return types.IntType
+	case "float":
+		return types.FloatType
+	case "string":
+		return types.StringType
+	case "bool":
+		return types.BoolType
+	case "char":
+		return types.CharType
+	case "Iterator":
+		return types.IteratorType
+	case "Regex":
+		return types.RegexType
+	case "Mutex":
+		return types.MutexType
+	case "AtomicInt":
+		return types.AtomicIntType
+	}
+	if sized, ok := types.SizedInts[name]; ok {
+		return sized
+	}
+	if element, isArray := strings.CutPrefix(name, "[]"); isArray {
+		return &types.ArrayType{ElementType: c.resolveType(element)}
+	}
+	if inner, isTuple := strings.CutPrefix(name, "("); isTuple && strings.HasSuffix(inner, ")") {
+		tuple := &types.TupleType{}
+		for _, element := range types.SplitTypeList(strings.TrimSuffix(inner, ")")) {
+			tuple.Elements = append(tuple.Elements, c.resolveType(element))
+		}
+		return tuple
+	}
+	base := strings.SplitN(name, "<", 2)[0]
+	if args := typeArguments(name); base == "Result" && len(args) > 0 {
+		// Result<T> is short for Result<T, string>
+		result := &types.ResultType{ValueType: c.resolveType(args[0]), ErrorType: types.StringType}
+		if len(args) > 1 {
+			result.ErrorType = c.resolveType(args[1])
+		}
+		return result
+	}
+	if args := typeArguments(name); base == "Option" && len(args) == 1 {
+		return &types.OptionType{ValueType: c.resolveType(args[0])}
+	}
+	if args := typeArguments(name); base == "Channel" && len(args) == 1 {
+		return &types.ChannelType{ElementType: c.resolveType(args[0])}
+	}
+	if args := typeArguments(name); len(args) > 0 && types.Collections[base] == len(args) {
+		collection := &types.CollectionType{Name: base}
+		for _, arg := range args {
+			collection.Elements = append(collection.Elements, c.resolveType(arg))
+		}
+		return collection
+	}
+	if decl, ok := c.typeDecls[base]; ok {
+		return &types.StructType{Name: decl.Name}
+	}
+	if decl, ok := c.enums[base]; ok {
+		return &types.EnumType{Name: decl.Name}
+	}
+	return types.AnyType
+}
+
+// typeArguments returns the type arguments of a generic type name, e.g. int
+// and Pair<int, string> for Result<int, Pair<int, string>>
+func typeArguments(name string) []string {
+	_, rest, generic := strings.Cut(name, "<")
+	if !generic || !strings.HasSuffix(rest, ">") {
+		return nil
+	}
+	return types.SplitTypeList(strings.TrimSuffix(rest, ">"))
+}
+
+// fieldType returns the type of a field of a struct type. Fields typed with
+// a type parameter of the struct are any.
+func (c *checker) fieldType(decl *ast.TypeDeclaration, field ast.TypeField) types.Type {
+	for _, param := range decl.Generics {
+		if field.TypeAnn == param {
+			return types.AnyType
+		}
+	}
+	return c.resolveType(field.TypeAnn)
+}
+
+// paramType returns the type of a function parameter, without the variadic
+// array
+func (c *checker) paramType(fn *ast.FunctionDefinition, typeName string) types.Type {
+	for _, param := range fn.Generics {
+		if typeName == param {
+			return types.AnyType
+		}
+	}
+	return c.resolveType(typeName)
+}
+
+// returnType returns the declared return type of fn, nil if it has none
+func (c *checker) returnType(fn *ast.FunctionDefinition) types.Type {
+	if fn.ReturnType == nil || *fn.ReturnType == "void" {
+		return nil
+	}
+	return c.paramType(fn, *fn.ReturnType)
+}
+
+// assignable reports whether a value of type value can be stored where
+// target is expected. Integer literals convert to float like Go constants,
+// and expressions made of them to the sized integer types.
+func assignable(target, value types.Type, expr ast.Expression) bool {
+	if target == types.AnyType || value == types.AnyType {
+		return true
+	}
+	if target == types.FloatType && value == types.IntType && isConstant(expr) {
+		return true
+	}
+	if _, ok := foldInt(expr); ok && types.IsSized(target) && value == types.IntType {
+		return true
+	}
+	targetArray, ok1 := target.(*types.ArrayType)
+	valueArray, ok2 := value.(*types.ArrayType)
+	if ok1 && ok2 {
+		// The elements of a literal convert like single values
+		var first ast.Expression
+		if array, isLiteral := expr.(*ast.ArrayLiteral); isLiteral && len(array.Elements) > 0 {
+			first = array.Elements[0]
+		}
+		return assignable(elementType(targetArray), elementType(valueArray), first)
+	}
+	// ok(v) and err(e) leave the other type of the Result open, and v is
+	// converted like a value assigned to the type it is created with
+	targetResult, ok1 := target.(*types.ResultType)
+	valueResult, ok2 := value.(*types.ResultType)
+	if ok1 && ok2 {
+		var okArg, errArg ast.Expression
+		if call, isCall := expr.(*ast.FunctionCall); isCall && len(call.Arguments) == 1 {
+			switch call.Name {
+			case "ok":
+				okArg = call.Arguments[0]
+			case "err":
+				errArg = call.Arguments[0]
+			}
+		}
+		return assignable(targetResult.ValueType, valueResult.ValueType, okArg) &&
+			assignable(targetResult.ErrorType, valueResult.ErrorType, errArg)
+	}
+	// The elements of a tuple literal convert like single values
+	targetTuple, ok1 := target.(*types.TupleType)
+	valueTuple, ok2 := value.(*types.TupleType)
+	if ok1 && ok2 {
+		if len(targetTuple.Elements) != len(valueTuple.Elements) {
+			return false
+		}
+		literal, _ := expr.(*ast.TupleLiteral)
+		for i := range targetTuple.Elements {
+			var element ast.Expression
+			if literal != nil {
+				element = literal.Elements[i]
+			}
+			if !assignable(targetTuple.Elements[i], valueTuple.Elements[i], element) {
+				return false
+			}
+		}
+		return true
+	}
+	// none is an Option<any>
+	targetOption, ok1 := target.(*types.OptionType)
+	valueOption, ok2 := value.(*types.OptionType)
+	if ok1 && ok2 {
+		var someArg ast.Expression
+		if call, isCall := expr.(*ast.FunctionCall); isCall && call.Name == "some" && len(call.Arguments) == 1 {
+			someArg = call.Arguments[0]
+		}
+		return assignable(targetOption.ValueType, valueOption.ValueType, someArg)
+	}
+	// The collections created without type arguments hold any values
+	targetCollection, ok1 := target.(*types.CollectionType)
+	valueCollection, ok2 := value.(*types.CollectionType)
+	if ok1 && ok2 {
+		if targetCollection.Name != valueCollection.Name {
+			return false
+		}
+		for i := range targetCollection.Elements {
+			if !assignable(targetCollection.Elements[i], valueCollection.Elements[i], nil) {
+				return false
+			}
+		}
+		return true
+	}
+	// chan() without a type argument creates a channel of any values; the
+	// elements of other channels must be the same
+	targetChannel, ok1 := target.(*types.ChannelType)
+	valueChannel, ok2 := value.(*types.ChannelType)
+	if ok1 && ok2 {
+		return valueChannel.ElementType == types.AnyType || assignable(targetChannel.ElementType, valueChannel.ElementType, nil) && assignable(valueChannel.ElementType, targetChannel.ElementType, nil)
+	}
+	return target.String() == value.String()
+}
+
+func elementType(array *types.ArrayType) types.Type {
+	if array.ElementType == nil {
+		return types.AnyType
+	}
+	return array.ElementType
+}
+
+// isConstant reports whether expr is a numeric literal, which Go converts
+// to the type of the other operand. A literal with a suffix, as in 10u64,
+// has its type.
+func isConstant(expr ast.Expression) bool {
+	switch e := expr.(type) {
+	case *ast.IntegerLiteral:
+		return e.Suffix == ""
+	case *ast.FloatLiteral:
+		return true
+	case *ast.UnaryExpression:
+		return e.Operator == ast.UnaryOpMinus && isConstant(e.Right)
+	}
+	return false
+}
+
+func isNumeric(t types.Type) bool {
+	return t == types.FloatType || types.IsInteger(t)
+}
+
+func (c *checker) checkStatements(statements []ast.Statement, scope *types.SymbolTable) {
+	for _, stmt := range statements {
+		c.checkStatement(stmt, scope)
+	}
+}
+
+func (c *checker) checkBlock(block *ast.Block, scope *types.SymbolTable) {
+	if block != nil {
+		c.checkStatements(block.Statements, types.NewSymbolTable(scope))
+	}
+}
+
+func (c *checker) checkStatement(stmt ast.Statement, scope *types.SymbolTable) {
+	switch s := stmt.(type) {
+	case *ast.LetDeclaration:
+		valueType := c.checkExpression(s.ValueExpression, scope)
+		if s.Names != nil {
+			c.checkDestructuring(s, valueType, scope)
+			return
+		}
+		if _, isTuple := valueType.(*types.TupleType); isTuple {
+			c.errorf(s.ValueExpression, i18n.TypeTupleValue, valueType)
+		}
+		if s.Constant && !constant(s.ValueExpression, scope) {
+			c.errorf(s.ValueExpression, i18n.TypeConstValue, s.Name)
+		}
+		if s.TypeAnn != nil {
+			declared := c.resolveType(*s.TypeAnn)
+			if !assignable(declared, valueType, s.ValueExpression) {
+				c.errorf(s, i18n.TypeLetMismatch, s.Name, declared, valueType)
+			}
+			c.checkSizedConstant(declared, s.ValueExpression)
+			valueType = declared
+		}
+		// The consts of the top level are module-level even when its
+		// variables are local to main
+		if s.Constant && scope == c.top {
+			scope = c.module
+		}
+		symbol := scope.Define(s.Name, valueType)
+		symbol.Mutable, symbol.Constant = s.Mutable, s.Constant
+	case *ast.AssignmentStatement:
+		valueType := c.checkExpression(s.Value, scope)
+		symbol, ok := scope.Resolve(s.Name)
+		if !ok {
+			err := c.errorf(s, i18n.TypeAssignUndeclared, s.Name)
+			err.Suggestion = i18n.T(i18n.TypeHintDeclare, s.Name, s.Value)
+			return
+		}
+		c.checkMutable(s, symbol)
+		if !assignable(symbol.Type, valueType, s.Value) {
+			c.errorf(s, i18n.TypeAssignMismatch, valueType, s.Name, symbol.Type)
+		}
+		c.checkSizedConstant(symbol.Type, s.Value)
+	case *ast.ExpressionStatement:
+		if match, ok := s.Expression.(*ast.MatchExpression); ok {
+			c.checkMatch(match, scope, false)
+			return
+		}
+		if call, ok := s.Expression.(*ast.MethodCallExpression); ok {
+			c.checkMethodCall(call, scope, false)
+			return
+		}
+		c.checkExpression(s.Expression, scope)
+	case *ast.FunctionDefinition:
+		c.checkFunction(s)
+	case *ast.ImplDeclaration:
+		c.checkImpl(s)
+	case *ast.ReturnStatement:
+		c.checkReturn(s, scope)
+	case *ast.IfStatement:
+		c.checkCondition(s.Condition, scope)
+		c.checkBlock(s.ThenBlock, scope)
+		for _, clause := range s.ElseIfClauses {
+			c.checkCondition(clause.Condition, scope)
+			c.checkBlock(clause.Block, scope)
+		}
+		c.checkBlock(s.ElseBlock, scope)
+	case *ast.WhileStatement:
+		c.checkCondition(s.Condition, scope)
+		c.checkBlock(s.Block, scope)
+	case *ast.LoopStatement:
+		c.checkBlock(s.Body, scope)
+	case *ast.WhenStatement:
+		c.checkWhen(s, scope)
+	case *ast.TryStatement:
+		tried := c.tried
+		c.tried = true
+		c.checkBlock(s.Body, scope)
+		c.tried = tried
+		catch := types.NewSymbolTable(scope)
+		if s.CatchName != "" {
+			catch.Define(s.CatchName, types.StringType)
+		}
+		c.checkStatements(s.Catch.Statements, catch)
+	case *ast.SpawnStatement:
+		outer := c.spawned
+		c.spawned = scope
+		c.checkStatements(s.Statements(), types.NewSymbolTable(scope))
+		c.spawned = outer
+	case *ast.ForStatement:
+		iterable := c.checkExpression(s.Iterable, scope)
+		element := types.Type(types.AnyType)
+		switch t := iterable.(type) {
+		case *types.ArrayType:
+			element = elementType(t)
+		case *types.CollectionType:
+			element = t.Element()
+			if s.IndexName != "" {
+				c.errorf(s.Iterable, i18n.TypeIndexedIteration, iterable)
+			}
+		case *types.ChannelType:
+			element = t.ElementType
+			if s.IndexName != "" {
+				c.errorf(s.Iterable, i18n.TypeIndexedIteration, iterable)
+			}
+		default:
+			// A string is iterated over by character
+			if t == types.StringType {
+				element = types.CharType
+			}
+			switch {
+			case t != types.AnyType && t != types.IteratorType && t != types.StringType:
+				c.errorf(s.Iterable, i18n.TypeNotIterable, iterable)
+			case t != types.AnyType && s.IndexName != "":
+				// Only arrays have indexes
+				c.errorf(s.Iterable, i18n.TypeIndexedIteration, iterable)
+			}
+		}
+		body := types.NewSymbolTable(scope)
+		if s.IndexName != "" {
+			body.Define(s.IndexName, types.IntType)
+		}
+		body.Define(s.VarName, element)
+		if s.Body != nil {
+			c.checkStatements(s.Body.Statements, body)
+		}
+	}
+}
+
+// checkMutable reports a change by node of a variable not declared with
+// let mut, or copied into the spawn block being checked
+func (c *checker) checkMutable(node ast.Node, symbol *types.Symbol) {
+	if !symbol.Mutable {
+		err := c.errorf(node, i18n.TypeAssignImmutable, symbol.Name)
+		if !symbol.Constant {
+			err.Suggestion = i18n.T(i18n.TypeHintMutable, symbol.Name)
+		}
+		return
+	}
+	if c.spawned != nil {
+		if outer, ok := c.spawned.Resolve(symbol.Name); ok && outer == symbol {
+			c.errorf(node, i18n.TypeSpawnCapture, symbol.Name)
+		}
+	}
+}
+
+// checkFunction checks the body of fn. Functions see their parameters,
+// other functions and the module-level variables, but not the variables of
+// a top level that runs as main.
+func (c *checker) checkFunction(fn *ast.FunctionDefinition) {
+	outer, spawned, tried, owners := c.current, c.spawned, c.tried, c.owners
+	c.current, c.spawned, c.tried, c.owners = fn, nil, false, nil
+	defer func() { c.current, c.spawned, c.tried, c.owners = outer, spawned, tried, owners }()
+	if c.functions[fn.Name] == fn {
+		c.owners = []string{fn.Name}
+	}
+
+	scope := types.NewSymbolTable(c.module)
+	for _, param := range fn.Parameters {
+		paramType := c.paramType(fn, param.Type)
+		if param.Variadic {
+			paramType = &types.ArrayType{ElementType: paramType}
+		}
+		scope.Define(param.Name, paramType)
+	}
+	c.checkStatements(fn.Body, scope)
+
+	if returnType := c.returnType(fn); returnType != nil {
+		if fallThrough, ok := ast.FindFallThrough(fn.Body, fn.Rbrace); ok {
+			err := c.errorf(fallThrough.Position, i18n.TypeMissingReturn, fn.Name, returnType)
+			err.Suggestion = i18n.T(missingReturnHints[fallThrough.Kind])
+		}
+	}
+}
+
+// missingReturnHints holds the suggestion of TypeMissingReturn for each way
+// the end of a function is reached
+var missingReturnHints = map[ast.FallThroughKind]i18n.MessageID{
+	ast.FallsOffEnd:          i18n.TypeHintReturnEnd,
+	ast.IfWithoutElse:        i18n.TypeHintReturnElse,
+	ast.LoopExits:            i18n.TypeHintReturnLoop,
+	ast.MatchWithoutWildcard: i18n.TypeHintReturnWildcard,
+}
+
+// checkTopLet checks a let of the top level, unless it was checked when a
+// declaration before it referred to it
+func (c *checker) checkTopLet(let *ast.LetDeclaration) {
+	if c.checkedLets[let] {
+		return
+	}
+	c.checkedLets[let] = true
+	owners := c.owners
+	c.owners = let.BoundNames()
+	c.checkStatement(let, c.top)
+	c.owners = owners
+}
+
+// refer records that the module-level variables and functions whose value
+// or body is being checked refer to the module-level variable or function
+// name
+func (c *checker) refer(name string) {
+	for _, owner := range c.owners {
+		c.deps[owner] = append(c.deps[owner], name)
+	}
+}
+
+// checkInitOrder reports the module-level variables whose value depends on
+// the variable itself, through the variables and functions it refers to,
+// which leaves no order to initialize them in
+func (c *checker) checkInitOrder(program *ast.Program) {
+	reported := make(map[string]bool)
+	for _, stmt := range program.Statements {
+		let, ok := stmt.(*ast.LetDeclaration)
+		if !ok {
+			continue
+		}
+		for _, name := range let.BoundNames() {
+			if reported[name] {
+				continue
+			}
+			if path := c.dependencyPath(name, name, make(map[string]bool)); path != nil {
+				for _, dep := range path {
+					reported[dep] = true
+				}
+				c.errorf(let, i18n.TypeInitCycle, name, name+" -> "+strings.Join(path, " -> "))
+			}
+		}
+	}
+}
+
+// dependencyPath returns the module-level variables and functions through
+// which from refers to to, ending with to, or nil if it does not
+func (c *checker) dependencyPath(from, to string, visited map[string]bool) []string {
+	for _, dep := range c.deps[from] {
+		if dep == to {
+			return []string{dep}
+		}
+		if !visited[dep] {
+			visited[dep] = true
+			if path := c.dependencyPath(dep, to, visited); path != nil {
+				return append([]string{dep}, path...)
+			}
+		}
+	}
+	return nil
+}
+
+// constant reports whether e is known when compiling, as the value of a
+// const must be: a literal, a const or operators applied to them
+func constant(e ast.Expression, scope *types.SymbolTable) bool {
+	switch e := e.(type) {
+	case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral, *ast.CharLiteral, *ast.BooleanLiteral:
+		return true
+	case *ast.Identifier:
+		symbol, ok := scope.Resolve(e.Value)
+		return ok && symbol.Constant
+	case *ast.UnaryExpression:
+		return constant(e.Right, scope)
+	case *ast.BinaryExpression:
+		return constant(e.Left, scope) && constant(e.Right, scope)
+	}
+	return false
+}
+
+// checkImpl checks that an impl implements a known trait for a struct type
+// of the file with exactly the function the trait requires, and the bodies
+// of its functions
+func (c *checker) checkImpl(s *ast.ImplDeclaration) {
+	trait, known := ast.Traits[s.Trait]
+	if !known {
+		c.errorf(s, i18n.TypeUnknownTrait, s.Trait, strings.Join(ast.TraitNames, ", "))
+	} else if !c.implementable(s.TypeName) {
+		c.errorf(s, i18n.TypeImplTarget, s.TypeName)
+	} else {
+		returnType := trait.ReturnType
+		if returnType == "" {
+			returnType = s.TypeName
+		}
+		signature := trait.Method + "(a: " + s.TypeName + ", b: " + s.TypeName + "): " + returnType
+		for _, method := range s.Methods {
+			params := method.Parameters
+			if method.Name != trait.Method || len(method.Generics) > 0 || len(params) != 2 ||
+				params[0].Type != s.TypeName || params[1].Type != s.TypeName || params[1].Variadic ||
+				method.ReturnType == nil || *method.ReturnType != returnType {
+				c.errorf(method, i18n.TypeImplMethod, s.Trait, s.TypeName, signature)
+			}
+		}
+		if len(s.Methods) == 0 {
+			c.errorf(s, i18n.TypeImplMethod, s.Trait, s.TypeName, signature)
+		}
+	}
+	for _, method := range s.Methods {
+		c.checkFunction(method)
+	}
+}
+
+// implementable reports whether traits can be implemented for the type
+// called name: a struct type of the file without type parameters
+func (c *checker) implementable(name string) bool {
+	decl, ok := c.typeDecls[name]
+	return ok && c.local[name] && len(decl.Generics) == 0
+}
+
+// operatorImpl returns the function that gives the operator op to the
+// operands left and right, when they are values of a struct type that
+// implements the trait of op
+func (c *checker) operatorImpl(op ast.BinaryOperator, left, right types.Type) (*ast.FunctionDefinition, bool) {
+	structType, ok := left.(*types.StructType)
+	if !ok || left.String() != right.String() {
+		return nil, false
+	}
+	method, ok := c.impls[structType.Name][ast.OperatorTrait(op)]
+	return method, ok
+}
+
+// checkDestructuring checks a let that takes a value apart, which defines
+// each name with the type of the matching element of a tuple, field of a
+// struct or element of an array. _ discards an element.
+func (c *checker) checkDestructuring(s *ast.LetDeclaration, valueType types.Type, scope *types.SymbolTable) {
+	elements := make([]types.Type, len(s.Names))
+	switch t := valueType.(type) {
+	case *types.TupleType:
+		if s.Destructure != ast.DestructureTuple {
+			c.notDestructurable(s, valueType)
+		} else if len(t.Elements) != len(s.Names) {
+			c.errorf(s, i18n.TypeTupleArity, t, len(t.Elements), len(s.Names))
+		} else {
+			copy(elements, t.Elements)
+		}
+	case *types.StructType:
+		if s.Destructure != ast.DestructureFields {
+			c.notDestructurable(s, valueType)
+			break
+		}
+		decl := c.typeDecls[t.Name]
+		for i, name := range s.Names {
+			if field, ok := fieldByName(decl, name); ok {
+				elements[i] = c.fieldType(decl, field)
+			} else if name != "_" {
+				c.errorf(s, i18n.GenUnknownField, decl.Name, name)
+			}
+		}
+	case *types.ArrayType:
+		if s.Destructure != ast.DestructureArray {
+			c.notDestructurable(s, valueType)
+			break
+		}
+		for i := range elements {
+			elements[i] = elementType(t)
+		}
+	default:
+		// Maps are typed any
+		if t != types.AnyType {
+			c.notDestructurable(s, valueType)
+		}
+	}
+	for i, name := range s.Names {
+		if name == "_" {
+			continue
+		}
+		if elements[i] == nil {
+			elements[i] = types.AnyType
+		}
+		scope.Define(name, elements[i]).Mutable = s.Mutable
+	}
+}
+
+// notDestructurable reports a value that the pattern of s cannot take
+// apart
+func (c *checker) notDestructurable(s *ast.LetDeclaration, valueType types.Type) {
+	switch s.Destructure {
+	case ast.DestructureFields:
+		c.errorf(s.ValueExpression, i18n.TypeNotStructOrMap, valueType)
+	case ast.DestructureArray:
+		c.errorf(s.ValueExpression, i18n.TypeNotArray, valueType)
+	default:
+		c.errorf(s.ValueExpression, i18n.TypeNotTuple, valueType)
+	}
+}
+
+func (c *checker) checkReturn(s *ast.ReturnStatement, scope *types.SymbolTable) {
+	var valueType types.Type
+	if s.Value != nil {
+		valueType = c.checkExpression(s.Value, scope)
+	}
+	if c.spawned != nil {
+		c.errorf(s, i18n.TypeSpawnExit, "return")
+		return
+	}
+	if c.tried {
+		c.errorf(s, i18n.TypeTryExit, "return")
+		return
+	}
+	if c.current == nil {
+		return
+	}
+	expected := c.returnType(c.current)
+	if expected == nil {
+		// A value returned without a return type is reported by the generator
+		return
+	}
+	if s.Value == nil {
+		c.errorf(s, i18n.TypeMissingReturnValue, c.current.Name, expected)
+		return
+	}
+	if !assignable(expected, valueType, s.Value) {
+		c.errorf(s, i18n.TypeReturnMismatch, c.current.Name, expected, valueType)
+	}
+	c.checkSizedConstant(expected, s.Value)
+}
+
+// checkCondition checks the condition of an if or while. Numbers and
+// strings are accepted like the generator accepts them, with a warning.
+func (c *checker) checkCondition(expr ast.Expression, scope *types.SymbolTable) {
+	t := c.checkExpression(expr, scope)
+	if _, ok := t.(*types.OptionType); ok {
+		c.errorf(expr, i18n.TypeOptionNotUnwrapped, expr)
+		return
+	}
+	switch {
+	case t == types.BoolType, t == types.FloatType, t == types.StringType, t == types.AnyType, types.IsInteger(t):
+		return
+	}
+	c.errorf(expr, i18n.TypeInvalidCondition, t)
+}
+
+// checkExpression checks expr and returns its type. Expressions whose type
+// cannot be known are any.
+func (c *checker) checkExpression(expr ast.Expression, scope *types.SymbolTable) types.Type {
+	t := c.expressionType(expr, scope)
+	if c.exprTypes != nil {
+		c.exprTypes[expr] = t
+	}
+	return t
+}
+
+func (c *checker) expressionType(expr ast.Expression, scope *types.SymbolTable) types.Type {
+	switch e := expr.(type) {
+	case *ast.IntegerLiteral:
+		if e.Suffix != "" {
+			return types.SizedInts[e.Suffix]
+		}
+		return types.IntType
+	case *ast.FloatLiteral:
+		return types.FloatType
+	case *ast.StringLiteral:
+		return types.StringType
+	case *ast.CharLiteral:
+		return types.CharType
+	case *ast.BooleanLiteral:
+		return types.BoolType
+	case *ast.ArrayLiteral:
+		var element types.Type = types.AnyType
+		for i, el := range e.Elements {
+			t := c.checkExpression(el, scope)
+			if i == 0 {
+				element = t
+			}
+		}
+		return &types.ArrayType{ElementType: element}
+	case *ast.TupleLiteral:
+		tuple := &types.TupleType{}
+		for _, el := range e.Elements {
+			tuple.Elements = append(tuple.Elements, c.checkExpression(el, scope))
+		}
+		return tuple
+	case *ast.MapLiteral:
+		for _, key := range e.OrderedKeys() {
+			// Bare identifiers are string keys, as in {debug: true}
+			if _, ok := key.(*ast.Identifier); !ok {
+				c.checkExpression(key, scope)
+			}
+			c.checkExpression(e.Pairs[key], scope)
+		}
+		return types.AnyType
+	case *ast.StructLiteral:
+		return c.checkStructLiteral(e, scope)
+	case *ast.Identifier:
+		if symbol, ok := scope.Resolve(e.Value); ok {
+			if namespace, ok := symbol.Type.(*types.NamespaceType); ok {
+				c.errorf(e, i18n.TypeNamespaceValue, e.Value, namespace.Module)
+				return types.AnyType
+			}
+			if module, ok := c.module.Resolve(e.Value); ok && module == symbol {
+				c.refer(e.Value)
+			}
+			return symbol.Type
+		}
+		if _, ok := c.functions[e.Value]; ok || e.Value == "nil" {
+			c.refer(e.Value)
+			return types.AnyType
+		}
+		if e.Value == "none" {
+			return &types.OptionType{ValueType: types.AnyType}
+		}
+		// A module-level variable declared further down is checked first,
+		// unless its value is being checked, which is a cycle checkInitOrder
+		// reports
+		if let, ok := c.moduleLets[e.Value]; ok {
+			if !c.checkedLets[let] {
+				c.checkTopLet(let)
+				return c.expressionType(e, scope)
+			}
+			c.refer(e.Value)
+			return types.AnyType
+		}
+		if enum, ok := c.variants[e.Value]; ok {
+			variant, _ := enum.Variant(e.Value)
+			if len(variant.Fields) > 0 {
+				c.errorf(e, i18n.GenArgumentCount, e.Value, len(variant.Fields), 0, e.Value)
+			}
+			return &types.EnumType{Name: enum.Name}
+		}
+		c.errorf(e, i18n.TypeUndefinedVariable, e.Value)
+		return types.AnyType
+	case *ast.MemberExpression:
+		return c.checkMember(e, scope)
+	case *ast.MemberAccessExpression:
+		c.checkExpression(e.Expression, scope)
+		return types.AnyType
+	case *ast.UnaryExpression:
+		return c.checkUnary(e, scope)
+	case *ast.BinaryExpression:
+		return c.checkBinary(e, scope)
+	case *ast.FunctionCall:
+		return c.checkCall(e, scope)
+	case *ast.MatchExpression:
+		return c.checkMatch(e, scope, true)
+	case *ast.IfExpression:
+		return c.checkIf(e, scope)
+	case *ast.TryExpression:
+		return c.checkTry(e, scope)
+	case *ast.IndexExpression:
+		return c.checkIndex(e, scope)
+	case *ast.SliceExpression:
+		return c.checkSlice(e, scope)
+	case *ast.MethodCallExpression:
+		return c.checkMethodCall(e, scope, true)
+	}
+	return types.AnyType
+}
+
+// checkIndex checks items[i] and returns the type of the element: the
+// element type of an array, a one-character string, or any for maps
+func (c *checker) checkIndex(e *ast.IndexExpression, scope *types.SymbolTable) types.Type {
+	left := c.checkExpression(e.Left, scope)
+	index := c.checkExpression(e.Index, scope)
+	switch l := left.(type) {
+	case *types.ArrayType:
+		c.checkBound(e, left, e.Index, index)
+		return elementType(l)
+	case *types.OptionType:
+		c.errorf(e.Left, i18n.TypeOptionNotUnwrapped, e.Left)
+		return types.AnyType
+	}
+	switch left {
+	case types.StringType:
+		c.checkBound(e, left, e.Index, index)
+		return types.StringType
+	case types.AnyType:
+		// Maps are typed any and indexed with string keys
+		return types.AnyType
+	}
+	c.errorf(e.Left, i18n.TypeNotIndexable, left)
+	return types.AnyType
+}
+
+// checkSlice checks items[low:high], which has the type of items
+func (c *checker) checkSlice(e *ast.SliceExpression, scope *types.SymbolTable) types.Type {
+	left := c.checkExpression(e.Left, scope)
+	var low, high types.Type
+	if e.Low != nil {
+		low = c.checkExpression(e.Low, scope)
+	}
+	if e.High != nil {
+		high = c.checkExpression(e.High, scope)
+	}
+	switch left.(type) {
+	case *types.ArrayType:
+	case *types.OptionType:
+		c.errorf(e.Left, i18n.TypeOptionNotUnwrapped, e.Left)
+		return types.AnyType
+	default:
+		if left == types.AnyType {
+			return types.AnyType
+		}
+		if left != types.StringType {
+			c.errorf(e.Left, i18n.TypeNotIndexable, left)
+			return types.AnyType
+		}
+	}
+	if e.Low != nil {
+		c.checkBound(e, left, e.Low, low)
+	}
+	if e.High != nil {
+		c.checkBound(e, left, e.High, high)
+	}
+	lowValue, lowConstant := constantInt(e.Low)
+	highValue, highConstant := constantInt(e.High)
+	if lowConstant && highConstant && lowValue > highValue {
+		c.errorf(e, i18n.TypeInvertedSlice, lowValue, highValue)
+	}
+	return left
+}
+
+// checkBound checks an index or slice bound of node, whose left operand has
+// type left: it must be an int, and a constant may not be negative or, for
+// literals, past the end
+func (c *checker) checkBound(node ast.Node, left types.Type, bound ast.Expression, boundType types.Type) {
+	if boundType != types.IntType && boundType != types.AnyType {
+		c.errorf(bound, i18n.TypeIndexType, left, types.IntType, boundType)
+		return
+	}
+	value, ok := constantInt(bound)
+	if !ok {
+		return
+	}
+	if value < 0 {
+		c.errorf(bound, i18n.TypeNegativeIndex, value)
+		return
+	}
+	switch n := node.(type) {
+	case *ast.IndexExpression:
+		if length, ok := literalLength(n.Left); ok && value >= length {
+			c.errorf(bound, i18n.TypeIndexOutOfRange, value, length)
+		}
+	case *ast.SliceExpression:
+		// A slice may end at the length
+		if length, ok := literalLength(n.Left); ok && value > length {
+			c.errorf(bound, i18n.TypeIndexOutOfRange, value, length)
+		}
+	}
+}
+
+// constantInt returns the value of an integer literal, possibly negated
+func constantInt(expr ast.Expression) (int, bool) {
+	switch e := expr.(type) {
+	case *ast.IntegerLiteral:
+		return e.Value, true
+	case *ast.UnaryExpression:
+		if value, ok := constantInt(e.Right); ok && e.Operator == ast.UnaryOpMinus {
+			return -value, true
+		}
+	}
+	return 0, false
+}
+
+// Bounds of int, the 64-bit integers of Zeno, for constant folding
+var (
+	minInt = big.NewInt(math.MinInt)
+	maxInt = big.NewInt(math.MaxInt)
+)
+
+// foldInt returns the value of an expression made of int literals and the
+// operators of ints, if it and every part of it fit in an int
+func foldInt(expr ast.Expression) (*big.Int, bool) {
+	switch e := expr.(type) {
+	case *ast.IntegerLiteral:
+		if e.Suffix == "" {
+			return big.NewInt(int64(e.Value)), true
+		}
+	case *ast.UnaryExpression:
+		if value, ok := foldInt(e.Right); ok && e.Operator == ast.UnaryOpMinus {
+			value.Neg(value)
+			return value, fitsInt(value)
+		}
+	case *ast.BinaryExpression:
+		left, ok := foldInt(e.Left)
+		if !ok {
+			return nil, false
+		}
+		right, ok := foldInt(e.Right)
+		if !ok {
+			return nil, false
+		}
+		value, ok := applyInt(e.Operator, left, right)
+		return value, ok && fitsInt(value)
+	}
+	return nil, false
+}
+
+// applyInt returns the exact value of left op right, which may not fit in
+// an int. It fails for a division by zero, a shift by a negative or huge
+// count and the operators that do not give an int.
+func applyInt(op ast.BinaryOperator, left, right *big.Int) (*big.Int, bool) {
+	result := new(big.Int)
+	switch op {
+	case ast.BinaryOpPlus:
+		return result.Add(left, right), true
+	case ast.BinaryOpMinus:
+		return result.Sub(left, right), true
+	case ast.BinaryOpMultiply:
+		return result.Mul(left, right), true
+	case ast.BinaryOpDivide, ast.BinaryOpModulo:
+		if right.Sign() == 0 {
+			return nil, false
+		}
+		// Quo and Rem truncate toward zero, like Go
+		if op == ast.BinaryOpDivide {
+			return result.Quo(left, right), true
+		}
+		return result.Rem(left, right), true
+	case ast.BinaryOpBitAnd:
+		return result.And(left, right), true
+	case ast.BinaryOpBitOr:
+		return result.Or(left, right), true
+	case ast.BinaryOpBitXor:
+		return result.Xor(left, right), true
+	case ast.BinaryOpShiftLeft, ast.BinaryOpShiftRight:
+		// A shift of a nonzero int by 64 or more overflows or gives 0 or
+		// -1, so larger counts need not be folded
+		if right.Sign() < 0 || right.Cmp(big.NewInt(128)) > 0 {
+			return nil, false
+		}
+		if op == ast.BinaryOpShiftLeft {
+			return result.Lsh(left, uint(right.Int64())), true
+		}
+		return result.Rsh(left, uint(right.Int64())), true
+	}
+	return nil, false
+}
+
+// unsignedInts are the sized integer types without negative values
+var unsignedInts = map[types.Type]bool{types.U32Type: true, types.U64Type: true, types.ByteType: true}
+
+// sizedBounds returns the smallest and the largest value of a sized integer
+// type
+func sizedBounds(t types.Type) (min, max *big.Int) {
+	bits := map[types.Type]uint{types.I32Type: 32, types.I64Type: 64, types.U32Type: 32, types.U64Type: 64, types.ByteType: 8}[t]
+	one := big.NewInt(1)
+	if unsignedInts[t] {
+		return new(big.Int), new(big.Int).Sub(new(big.Int).Lsh(one, bits), one)
+	}
+	max = new(big.Int).Sub(new(big.Int).Lsh(one, bits-1), one)
+	return new(big.Int).Sub(new(big.Int).Neg(max), one), max
+}
+
+// checkSizedConstant reports an expression made of int literals that does
+// not fit in target, a sized integer type it is converted to, as the
+// literals with the suffix of the type are when parsing
+func (c *checker) checkSizedConstant(target types.Type, expr ast.Expression) {
+	if !types.IsSized(target) {
+		return
+	}
+	value, ok := foldInt(expr)
+	if !ok {
+		return
+	}
+	if min, max := sizedBounds(target); value.Cmp(min) < 0 || value.Cmp(max) > 0 {
+		c.errorf(expr, i18n.ParserIntegerOutOfRange, expr, target, min, max)
+	}
+}
+
+// fitsInt reports whether value can be stored in an int
+func fitsInt(value *big.Int) bool {
+	return value.Cmp(minInt) >= 0 && value.Cmp(maxInt) <= 0
+}
+
+// zeroDivisor reports whether expr, the divisor of / or %, is a constant
+// equal to 0: integer literals folded to 0, or a float literal 0.0
+func zeroDivisor(expr ast.Expression) bool {
+	if value, ok := foldInt(expr); ok {
+		return value.Sign() == 0
+	}
+	switch e := expr.(type) {
+	case *ast.FloatLiteral:
+		return e.Value == 0
+	case *ast.UnaryExpression:
+		return e.Operator == ast.UnaryOpMinus && zeroDivisor(e.Right)
+	}
+	return false
+}
+
+// checkConstantInt reports the division by zero and the overflow of e, when
+// its operands are made of integer literals. Only the innermost expression
+// overflowing is reported: the ones around it are not folded.
+func (c *checker) checkConstantInt(e *ast.BinaryExpression) {
+	if (e.Operator == ast.BinaryOpDivide || e.Operator == ast.BinaryOpModulo) && zeroDivisor(e.Right) {
+		c.errorf(e.Right, i18n.TypeDivisionByZero, e)
+		return
+	}
+	left, ok := foldInt(e.Left)
+	if !ok {
+		return
+	}
+	right, ok := foldInt(e.Right)
+	if !ok {
+		return
+	}
+	if value, ok := applyInt(e.Operator, left, right); ok && !fitsInt(value) {
+		c.errorf(e, i18n.TypeConstantOverflow, e, math.MinInt, math.MaxInt)
+	}
+}
+
+// literalLength returns the length of an array or string literal, in
+// characters for strings
+func literalLength(expr ast.Expression) (int, bool) {
+	switch e := expr.(type) {
+	case *ast.ArrayLiteral:
+		return len(e.Elements), true
+	case *ast.StringLiteral:
+		return utf8.RuneCountInString(e.Value), true
+	}
+	return 0, false
+}
+
+// checkMethodCall checks a call to a method of a string or an array and
+// returns its type. push appends to a variable, so it is only allowed as a
+// statement.
+func (c *checker) checkMethodCall(e *ast.MethodCallExpression, scope *types.SymbolTable, isValue bool) types.Type {
+	if name, call, ok := e.QualifiedCall(); ok {
+		if symbol, ok := scope.Resolve(name); ok {
+			if namespace, ok := symbol.Type.(*types.NamespaceType); ok {
+				return c.checkNamespaceCall(call, namespace, scope)
+			}
+		}
+	}
+	object := c.checkExpression(e.Object, scope)
+	argTypes := make([]types.Type, len(e.Arguments))
+	for i, arg := range e.Arguments {
+		argTypes[i] = c.checkExpression(arg, scope)
+	}
+
+	var m method
+	var ok bool
+	var element types.Type
+	switch t := object.(type) {
+	case *types.OptionType:
+		c.errorf(e.Object, i18n.TypeOptionNotUnwrapped, e.Object)
+		return types.AnyType
+	case *types.ArrayType:
+		m, ok = arrayMethods[e.Method]
+		element = elementType(t)
+	case *types.CollectionType:
+		m, ok = collectionMethod(t, e.Method)
+	default:
+		if object == types.AnyType {
+			return types.AnyType
+		}
+		if object == types.StringType {
+			m, ok = stringMethods[e.Method]
+		}
+	}
+	if !ok {
+		c.errorf(e, i18n.TypeUnknownMethod, object, e.Method)
+		return types.AnyType
+	}
+
+	if _, isArray := object.(*types.ArrayType); isArray && e.Method == "push" {
+		variable, isVariable := e.Object.(*ast.Identifier)
+		if !isVariable || isValue {
+			c.errorf(e, i18n.TypePushTarget)
+		} else if symbol, ok := scope.Resolve(variable.Value); ok {
+			c.checkMutable(e, symbol)
+		}
+	}
+	if len(e.Arguments) != len(m.params) {
+		c.errorf(e, i18n.GenArgumentCount, e.Method, len(m.params), len(e.Arguments), e.String())
+		return returnTypeOr(m.returnType, types.AnyType)
+	}
+	for i, param := range m.params {
+		paramType := param.typ
+		if paramType == nil {
+			paramType = element
+		}
+		if !assignable(paramType, argTypes[i], e.Arguments[i]) {
+			c.errorf(e.Arguments[i], i18n.GenArgumentType, i+1, e.Method, param.name, paramType, argTypes[i], e.String())
+		}
+	}
+	switch e.Method {
+	case "map":
+		// The elements take the return type of the function
+		var mapped types.Type = types.AnyType
+		if fn, isFunction := e.Arguments[0].(*ast.Identifier); isFunction {
+			if def, declared := c.functions[fn.Value]; declared {
+				mapped = returnTypeOr(c.returnType(def), types.AnyType)
+			}
+		}
+		return &types.ArrayType{ElementType: mapped}
+	case "filter":
+		return object
+	}
+	return m.returnType
+}
+
+// returnTypeOr returns t, or fallback when t is unknown
+func returnTypeOr(t, fallback types.Type) types.Type {
+	if t == nil {
+		return fallback
+	}
+	return t
+}
+
+// checkTry checks a ? operator and returns the type of the value it unwraps.
+// The enclosing function must return a Result that can hold the error.
+func (c *checker) checkTry(e *ast.TryExpression, scope *types.SymbolTable) types.Type {
+	operand := c.checkExpression(e.Value, scope)
+	result, ok := operand.(*types.ResultType)
+	if !ok {
+		if operand != types.AnyType {
+			c.errorf(e, i18n.TypeTryOperand, operand)
+		}
+		return types.AnyType
+	}
+	if c.spawned != nil {
+		c.errorf(e, i18n.TypeSpawnExit, "?")
+		return result.ValueType
+	}
+	if c.tried {
+		c.errorf(e, i18n.TypeTryExit, "?")
+		return result.ValueType
+	}
+	var expected *types.ResultType
+	if c.current != nil {
+		expected, _ = c.returnType(c.current).(*types.ResultType)
+	}
+	switch {
+	case expected == nil:
+		c.errorf(e, i18n.TypeTryOutsideResult)
+	case !assignable(expected.ErrorType, result.ErrorType, nil):
+		c.errorf(e, i18n.TypeTryErrorMismatch, c.current.Name, expected.ErrorType, result.ErrorType)
+	}
+	return result.ValueType
+}
+
+// checkMatch checks the patterns and arms of a match and returns the type of
+// its value. When the value is used, all arms must have compatible types.
+func (c *checker) checkMatch(e *ast.MatchExpression, scope *types.SymbolTable, isValue bool) types.Type {
+	subject := c.checkExpression(e.Subject, scope)
+	seen := make(map[string]bool)
+	var result types.Type
+	intConstants := true // whether the int arms so far are all literals
+	for i := range e.Arms {
+		arm := &e.Arms[i]
+		armScope := types.NewSymbolTable(scope)
+		if enum, variant, ok := c.variantPattern(arm.Pattern, scope); ok {
+			c.checkVariantPattern(arm.Pattern, enum, variant, subject, armScope)
+			if seen[variant.Name] {
+				c.errorf(arm.Pattern, i18n.TypeDuplicatePattern, arm.Pattern)
+			}
+			seen[variant.Name] = true
+		} else if name, ok := c.optionPattern(arm.Pattern, scope); ok {
+			c.checkOptionPattern(arm.Pattern, subject, armScope)
+			if seen[name] {
+				c.errorf(arm.Pattern, i18n.TypeDuplicatePattern, arm.Pattern)
+			}
+			seen[name] = true
+		} else if !arm.IsWildcard() {
+			pattern := c.checkExpression(arm.Pattern, scope)
+			if !assignable(subject, pattern, arm.Pattern) && !assignable(pattern, subject, e.Subject) {
+				c.errorf(arm.Pattern, i18n.TypeMatchPattern, arm.Pattern, pattern, subject)
+			}
+			if isLiteral(arm.Pattern) {
+				if seen[arm.Pattern.String()] {
+					c.errorf(arm.Pattern, i18n.TypeDuplicatePattern, arm.Pattern)
+				}
+				seen[arm.Pattern.String()] = true
+			}
+		}
+
+		armType, value := c.checkArm(arm, armScope, isValue)
+		if !isValue || armType == types.AnyType {
+			continue
+		}
+		joined, ok := branchType(result, armType, value, &intConstants)
+		if !ok {
+			c.errorf(arm, i18n.TypeMatchArmMismatch, armType, result)
+		}
+		result = joined
+	}
+	if result == nil {
+		return types.AnyType
+	}
+	return result
+}
+
+// checkIf checks an if used as a value, whose type is the type of the
+// values of its branches
+func (c *checker) checkIf(e *ast.IfExpression, scope *types.SymbolTable) types.Type {
+	c.checkCondition(e.Condition, scope)
+	for _, clause := range e.ElseIfClauses {
+		c.checkCondition(clause.Condition, scope)
+	}
+	var result types.Type
+	intConstants := true
+	for _, block := range e.Blocks() {
+		blockType, value := c.checkBlockValue(block, types.NewSymbolTable(scope))
+		if blockType == types.AnyType {
+			continue
+		}
+		joined, ok := branchType(result, blockType, value, &intConstants)
+		if !ok {
+			c.errorf(value, i18n.TypeIfBranchMismatch, blockType, result)
+		}
+		result = joined
+	}
+	if result == nil {
+		return types.AnyType
+	}
+	return result
+}
+
+// branchType joins result, the type of the values of the match arms or if
+// branches so far, with the type of value, and reports whether they agree.
+// intConstants tracks whether the int values so far are all literals.
+func branchType(result, valueType types.Type, value ast.Expression, intConstants *bool) (types.Type, bool) {
+	if valueType == types.IntType && !isConstant(value) {
+		*intConstants = false
+	}
+	switch {
+	case result == nil:
+		return valueType, true
+	case assignable(result, valueType, value):
+		return result, true
+	case result == types.IntType && valueType == types.FloatType && *intConstants:
+		// The int values are literals, which Go converts to float
+		return types.FloatType, true
+	}
+	return result, false
+}
+
+// variantPattern reports whether pattern names a variant of an enum, either
+// alone or with bindings for its fields: Empty or Circle(r)
+func (c *checker) variantPattern(pattern ast.Expression, scope *types.SymbolTable) (*ast.EnumDeclaration, *ast.EnumVariant, bool) {
+	var name string
+	switch p := pattern.(type) {
+	case *ast.Identifier:
+		if _, ok := scope.Resolve(p.Value); ok {
+			return nil, nil, false
+		}
+		name = p.Value
+	case *ast.FunctionCall:
+		if _, ok := c.functions[p.Name]; ok {
+			return nil, nil, false
+		}
+		name = p.Name
+	default:
+		return nil, nil, false
+	}
+	enum, ok := c.variants[name]
+	if !ok {
+		return nil, nil, false
+	}
+	variant, _ := enum.Variant(name)
+	return enum, variant, true
+}
+
+// checkVariantPattern checks that a variant pattern can match a value of
+// type subject and defines the names it binds in armScope. A variant named
+// without parentheses matches whatever its fields hold.
+func (c *checker) checkVariantPattern(pattern ast.Expression, enum *ast.EnumDeclaration, variant *ast.EnumVariant, subject types.Type, armScope *types.SymbolTable) {
+	enumType := &types.EnumType{Name: enum.Name}
+	if !assignable(subject, enumType, nil) {
+		c.errorf(pattern, i18n.TypeMatchPattern, pattern, enumType, subject)
+	}
+	call, ok := pattern.(*ast.FunctionCall)
+	if !ok {
+		return
+	}
+	if len(call.Arguments) != len(variant.Fields) {
+		c.errorf(pattern, i18n.TypeVariantArity, variant.Name, len(variant.Fields), pattern, len(call.Arguments))
+	}
+	for i, arg := range call.Arguments {
+		binding, ok := arg.(*ast.Identifier)
+		if !ok {
+			c.errorf(arg, i18n.TypeVariantBinding, pattern, variant.Name)
+			continue
+		}
+		if binding.Value == "_" {
+			continue
+		}
+		fieldType := types.Type(types.AnyType)
+		if i < len(variant.Fields) {
+			fieldType = c.resolveType(variant.Fields[i])
+		}
+		armScope.Define(binding.Value, fieldType)
+	}
+}
+
+// optionPattern reports whether pattern matches an Option, some(x) or none,
+// and returns which of the two. Functions, variables and variants of the
+// same name take precedence.
+func (c *checker) optionPattern(pattern ast.Expression, scope *types.SymbolTable) (string, bool) {
+	var name string
+	switch p := pattern.(type) {
+	case *ast.Identifier:
+		if _, ok := scope.Resolve(p.Value); ok || p.Value != "none" {
+			return "", false
+		}
+		name = p.Value
+	case *ast.FunctionCall:
+		if _, ok := c.functions[p.Name]; ok || p.Name != "some" {
+			return "", false
+		}
+		name = p.Name
+	default:
+		return "", false
+	}
+	if _, ok := c.variants[name]; ok {
+		return "", false
+	}
+	return name, true
+}
+
+// checkOptionPattern checks that an Option pattern can match a value of type
+// subject and defines the name some(x) binds in armScope
+func (c *checker) checkOptionPattern(pattern ast.Expression, subject types.Type, armScope *types.SymbolTable) {
+	option, ok := subject.(*types.OptionType)
+	if !ok {
+		option = &types.OptionType{ValueType: types.AnyType}
+		if subject != types.AnyType {
+			c.errorf(pattern, i18n.TypeMatchPattern, pattern, option, subject)
+		}
+	}
+	call, ok := pattern.(*ast.FunctionCall)
+	if !ok {
+		return
+	}
+	if len(call.Arguments) != 1 {
+		c.errorf(pattern, i18n.TypeVariantArity, call.Name, 1, pattern, len(call.Arguments))
+	}
+	for _, arg := range call.Arguments {
+		binding, ok := arg.(*ast.Identifier)
+		if !ok {
+			c.errorf(arg, i18n.TypeVariantBinding, pattern, call.Name)
+			continue
+		}
+		if binding.Value != "_" {
+			armScope.Define(binding.Value, option.ValueType)
+		}
+	}
+}
+
+// checkArm checks the body of a match arm in armScope, which holds the names
+// bound by its pattern. When the match is used as a value, it returns the
+// expression giving the arm's value, the last statement of a block, and its
+// type.
+func (c *checker) checkArm(arm *ast.MatchArm, armScope *types.SymbolTable, isValue bool) (types.Type, ast.Expression) {
+	if arm.Block == nil {
+		return c.checkExpression(arm.Value, armScope), arm.Value
+	}
+	if isValue {
+		return c.checkBlockValue(arm.Block, armScope)
+	}
+	c.checkStatements(arm.Block.Statements, armScope)
+	return types.AnyType, nil
+}
+
+// checkBlockValue checks a block used as a value and returns the type and
+// the expression of its last statement, which gives the value
+func (c *checker) checkBlockValue(block *ast.Block, scope *types.SymbolTable) (types.Type, ast.Expression) {
+	statements := block.Statements
+	if n := len(statements); n > 0 {
+		if last, ok := statements[n-1].(*ast.ExpressionStatement); ok {
+			c.checkStatements(statements[:n-1], scope)
+			return c.checkExpression(last.Expression, scope), last.Expression
+		}
+	}
+	// Blocks without a value are reported by the generator
+	c.checkStatements(statements, scope)
+	return types.AnyType, nil
+}
+
+// isLiteral reports whether expr is a literal, possibly negated
+func isLiteral(expr ast.Expression) bool {
+	switch e := expr.(type) {
+	case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral, *ast.CharLiteral, *ast.BooleanLiteral:
+		return true
+	case *ast.UnaryExpression:
+		return e.Operator == ast.UnaryOpMinus && isLiteral(e.Right)
+	}
+	return false
+}
+
+func (c *checker) checkStructLiteral(e *ast.StructLiteral, scope *types.SymbolTable) types.Type {
+	base := strings.SplitN(e.TypeName, "<", 2)[0]
+	decl, declared := c.typeDecls[base]
+	if !declared && !c.unresolved[base] {
+		c.errorf(e, i18n.TypeUnknownType, base, base)
+	}
+	for _, name := range e.OrderedFieldNames() {
+		value := e.Fields[name]
+		valueType := c.checkExpression(value, scope)
+		if !declared {
+			continue
+		}
+		field, ok := fieldByName(decl, name)
+		if !ok {
+			c.errorf(e, i18n.GenUnknownField, decl.Name, name)
+			continue
+		}
+		expected := c.fieldType(decl, field)
+		if !assignable(expected, valueType, value) {
+			c.errorf(value, i18n.TypeFieldMismatch, name, decl.Name, expected, valueType)
+		}
+		c.checkSizedConstant(expected, value)
+	}
+	if !declared {
+		return types.AnyType
+	}
+	return &types.StructType{Name: decl.Name}
+}
+
+func fieldByName(decl *ast.TypeDeclaration, name string) (ast.TypeField, bool) {
+	for _, field := range decl.Fields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return ast.TypeField{}, false
+}
+
+func (c *checker) checkMember(e *ast.MemberExpression, scope *types.SymbolTable) types.Type {
+	if ident, ok := e.Object.(*ast.Identifier); ok && ident.Value == "build" {
+		if _, shadowed := scope.Resolve("build"); !shadowed {
+			// Build constants are validated by the generator
+			return types.AnyType
+		}
+	}
+	object := c.checkExpression(e.Object, scope)
+	if result, ok := object.(*types.ResultType); ok {
+		switch e.Property {
+		case "ok":
+			return types.BoolType
+		case "value":
+			return result.ValueType
+		case "error":
+			return result.ErrorType
+		}
+		c.errorf(e, i18n.GenUnknownField, "Result", e.Property)
+		return types.AnyType
+	}
+	if _, ok := object.(*types.OptionType); ok {
+		c.errorf(e.Object, i18n.TypeOptionNotUnwrapped, e.Object)
+		return types.AnyType
+	}
+	structType, ok := object.(*types.StructType)
+	if !ok {
+		return types.AnyType
+	}
+	decl := c.typeDecls[structType.Name]
+	field, ok := fieldByName(decl, e.Property)
+	if !ok {
+		c.errorf(e, i18n.GenUnknownField, decl.Name, e.Property)
+		return types.AnyType
+	}
+	return c.fieldType(decl, field)
+}
+
+func (c *checker) checkUnary(e *ast.UnaryExpression, scope *types.SymbolTable) types.Type {
+	operand := c.checkExpression(e.Right, scope)
+	switch e.Operator {
+	case ast.UnaryOpBang:
+		if operand != types.BoolType && operand != types.AnyType {
+			c.errorf(e, i18n.TypeInvalidOperand, e.Operator, operand)
+		}
+		return types.BoolType
+	default:
+		if !isNumeric(operand) && operand != types.AnyType {
+			c.errorf(e, i18n.TypeInvalidOperand, e.Operator, operand)
+			return types.AnyType
+		}
+		// Like in Go, a negated unsigned literal is out of range
+		if literal, ok := e.Right.(*ast.IntegerLiteral); ok && literal.Value != 0 && unsignedInts[operand] {
+			min, max := sizedBounds(operand)
+			c.errorf(e, i18n.ParserIntegerOutOfRange, "-"+literal.String(), operand, min, max)
+		}
+		// -(-9223372036854775807 - 1) is the one negation overflowing
+		if right, ok := foldInt(e.Right); ok && !fitsInt(right.Neg(right)) {
+			c.errorf(e, i18n.TypeConstantOverflow, e, math.MinInt, math.MaxInt)
+		}
+		return operand
+	}
+}
+
+func (c *checker) checkBinary(e *ast.BinaryExpression, scope *types.SymbolTable) types.Type {
+	left := c.checkExpression(e.Left, scope)
+	right := c.checkExpression(e.Right, scope)
+	invalid := func() {
+		err := c.errorf(e, i18n.TypeInvalidOperands, e.Operator, left, right)
+		if trait := ast.OperatorTrait(e.Operator); trait != "" && c.implementable(left.String()) && left.String() == right.String() {
+			err.Suggestion = i18n.T(i18n.TypeHintImplTrait, trait, left, e.Operator)
+		}
+		switch {
+		case types.IsSized(left) && right == types.IntType:
+			err.Suggestion = i18n.T(i18n.TypeHintConvertSized, left, e.Right)
+		case types.IsSized(right) && left == types.IntType:
+			err.Suggestion = i18n.T(i18n.TypeHintConvertSized, right, e.Left)
+		}
+	}
+	if _, ok := left.(*types.OptionType); ok {
+		c.errorf(e.Left, i18n.TypeOptionNotUnwrapped, e.Left)
+		return types.AnyType
+	}
+	if _, ok := right.(*types.OptionType); ok {
+		c.errorf(e.Right, i18n.TypeOptionNotUnwrapped, e.Right)
+		return types.AnyType
+	}
+	// An integer literal takes the sized integer type of the other operand
+	switch {
+	case types.IsSized(left) && right == types.IntType && isConstant(e.Right):
+		c.checkSizedConstant(left, e.Right)
+		right = left
+	case types.IsSized(right) && left == types.IntType && isConstant(e.Left):
+		c.checkSizedConstant(right, e.Left)
+		left = right
+	}
+	if method, ok := c.operatorImpl(e.Operator, left, right); ok {
+		// Eq and Ord give comparisons, which are bools whatever eq and
+		// compare return
+		if e.Operator != ast.BinaryOpPlus {
+			return types.BoolType
+		}
+		return c.returnType(method)
+	}
+
+	switch e.Operator {
+	case ast.BinaryOpAnd, ast.BinaryOpOr:
+		if (left != types.BoolType && left != types.AnyType) || (right != types.BoolType && right != types.AnyType) {
+			invalid()
+		}
+		return types.BoolType
+	case ast.BinaryOpEq, ast.BinaryOpNotEq, ast.BinaryOpLt, ast.BinaryOpLte, ast.BinaryOpGt, ast.BinaryOpGte:
+		if _, ok := c.operandType(left, right); !ok {
+			invalid()
+			return types.BoolType
+		}
+		ordered := e.Operator != ast.BinaryOpEq && e.Operator != ast.BinaryOpNotEq
+		if ordered && !orderable(left) && !orderable(right) {
+			invalid()
+		}
+		return types.BoolType
+	case ast.BinaryOpShiftLeft, ast.BinaryOpShiftRight:
+		// The count may be of any integer type, and the result has the
+		// type of the value shifted
+		if types.IsInteger(left) && types.IsInteger(right) {
+			c.checkConstantInt(e)
+			return left
+		}
+	}
+
+	result, ok := c.operandType(left, right)
+	if !ok {
+		invalid()
+		return types.AnyType
+	}
+	c.checkConstantInt(e)
+	switch {
+	case result == types.AnyType:
+		return types.AnyType
+	case e.Operator.IntegerOnly() && !types.IsInteger(result):
+		invalid()
+		return types.AnyType
+	case e.Operator == ast.BinaryOpPlus && result == types.StringType:
+		return result
+	case !isNumeric(result):
+		invalid()
+		return types.AnyType
+	}
+	return result
+}
+
+// operandType returns the common type of two operands. An int mixed
+// with a float, a literal or not, is promoted to float, which the generator
+// converts it to. The sized integer types are not promoted.
+func (c *checker) operandType(left, right types.Type) (types.Type, bool) {
+	switch {
+	case left == types.AnyType || right == types.AnyType:
+		return types.AnyType, true
+	case left.String() == right.String():
+		return left, true
+	case (left == types.IntType || left == types.FloatType) && (right == types.IntType || right == types.FloatType):
+		return types.FloatType, true
+	}
+	return nil, false
+}
+
+func orderable(t types.Type) bool {
+	return isNumeric(t) || t == types.StringType || t == types.CharType || t == types.AnyType
+}
+
+func (c *checker) checkCall(call *ast.FunctionCall, scope *types.SymbolTable) types.Type {
+	argTypes := make([]types.Type, len(call.Arguments))
+	for i, arg := range call.Arguments {
+		argTypes[i] = c.checkExpression(arg, scope)
+	}
+
+	fn, ok := c.functions[call.Name]
+	if !ok {
+		if enum, ok := c.variants[call.Name]; ok {
+			return c.checkConstructor(call, enum, argTypes)
+		}
+		switch call.Name {
+		case "ok", "err":
+			return c.checkResultConstructor(call, argTypes)
+		case "some", "unwrapOr":
+			return c.checkOptionBuiltin(call, argTypes)
+		case "range":
+			return c.checkRange(call, argTypes)
+		case "chan", "send", "recv", "close":
+			return c.checkChannelBuiltin(call, argTypes)
+		}
+		if b, ok := builtins[call.Name]; ok {
+			if len(call.Arguments) != b.params {
+				c.errorf(call, i18n.GenArgumentCount, call.Name, b.params, len(call.Arguments), call.String())
+			} else if call.Name == "assertTrue" && !assignable(types.BoolType, argTypes[0], call.Arguments[0]) {
+				c.errorf(call.Arguments[0], i18n.GenArgumentType, 1, call.Name, "condition", types.BoolType, argTypes[0], call.String())
+			}
+			return b.returnType
+		}
+		if call.Name == "print" || call.Name == "println" || c.unresolved[call.Name] {
+			return types.AnyType
+		}
+		err := c.errorf(call, i18n.GenMissingImport, call.Name)
+		if modules := stdlib.ModulesExporting(call.Name); len(modules) > 0 {
+			names := append(append([]string{}, c.imports[modules[0]]...), call.Name)
+			err.Suggestion = i18n.T(i18n.GenHintAddImport, stdlib.ImportLine(modules[0], names...))
+		}
+		return types.AnyType
+	}
+	c.refer(call.Name)
+
+	// Type arguments fix the type parameters they stand for
+	typeArgs := call.TypeArguments
+	if len(typeArgs) > 0 && len(typeArgs) != len(fn.Generics) {
+		c.errorf(call, i18n.TypeTypeArgumentCount, call.Name, len(fn.Generics), len(typeArgs), call.String())
+		typeArgs = nil
+	}
+	required := len(fn.Parameters)
+	variadic := required > 0 && fn.Parameters[required-1].Variadic
+	if variadic {
+		required--
+	}
+	if len(call.Arguments) < required || (!variadic && len(call.Arguments) > required) {
+		if variadic {
+			c.errorf(call, i18n.GenArgumentCountAtLeast, call.Name, required, len(call.Arguments), call.String())
+		} else {
+			c.errorf(call, i18n.GenArgumentCount, call.Name, required, len(call.Arguments), call.String())
+		}
+	} else {
+		if len(typeArgs) == 0 && len(fn.Generics) > 0 {
+			typeArgs = c.inferTypeArguments(call, fn, argTypes)
+		}
+		for i, arg := range call.Arguments {
+			param := fn.Parameters[min(i, len(fn.Parameters)-1)]
+			paramType := c.paramType(fn, fn.Instantiate(param.Type, typeArgs))
+			if !assignable(paramType, argTypes[i], arg) {
+				c.errorf(arg, i18n.GenArgumentType, i+1, call.Name, param.Name, paramType, argTypes[i], call.String())
+			}
+			c.checkSizedConstant(paramType, arg)
+		}
+	}
+	if fn.ReturnType != nil && *fn.ReturnType != "void" && len(typeArgs) > 0 {
+		return c.paramType(fn, fn.Instantiate(*fn.ReturnType, typeArgs))
+	}
+	if returnType := c.returnType(fn); returnType != nil {
+		return returnType
+	}
+	return types.AnyType
+}
+
+// checkNamespaceCall checks io.readFile(path), a call of a function of the
+// module imported as io
+func (c *checker) checkNamespaceCall(call *ast.FunctionCall, namespace *types.NamespaceType, scope *types.SymbolTable) types.Type {
+	name, function, _ := strings.Cut(call.Name, ".")
+	if _, ok := c.functions[call.Name]; !ok {
+		for _, arg := range call.Arguments {
+			c.checkExpression(arg, scope)
+		}
+		if !c.unresolved[name] {
+			c.errorf(call, i18n.GenFunctionNotExported, function, namespace.Module)
+		}
+		return types.AnyType
+	}
+	return c.checkCall(call, scope)
+}
+
+// inferTypeArguments returns the type arguments of a call to the generic
+// function fn written without them, as inferred from the types of its
+// arguments. Type parameters that no argument stands for are reported and
+// taken to be any.
+func (c *checker) inferTypeArguments(call *ast.FunctionCall, fn *ast.FunctionDefinition, argTypes []types.Type) []string {
+	bindings := make(map[string]types.Type)
+	for i, argType := range argTypes {
+		param := fn.Parameters[min(i, len(fn.Parameters)-1)]
+		types.Infer(param.Type, fn.Generics, argType, bindings)
+	}
+	typeArgs := make([]string, len(fn.Generics))
+	for i, param := range fn.Generics {
+		if bound, ok := bindings[param]; ok {
+			typeArgs[i] = bound.String()
+			continue
+		}
+		typeArgs[i] = "any"
+		err := c.errorf(call, i18n.TypeCannotInfer, param, call.Name, call.String())
+		err.Suggestion = i18n.T(i18n.TypeHintTypeArguments, call.Name+"<"+strings.Join(fn.Generics, ", ")+">(...)")
+	}
+	return typeArgs
+}
+
+// checkResultConstructor checks a call to ok or err. The type they do not
+// set is any, to be fixed by the Result the value is assigned to.
+func (c *checker) checkResultConstructor(call *ast.FunctionCall, argTypes []types.Type) types.Type {
+	if len(call.Arguments) != 1 {
+		c.errorf(call, i18n.GenArgumentCount, call.Name, 1, len(call.Arguments), call.String())
+		return &types.ResultType{ValueType: types.AnyType, ErrorType: types.AnyType}
+	}
+	if call.Name == "ok" {
+		return &types.ResultType{ValueType: argTypes[0], ErrorType: types.AnyType}
+	}
+	return &types.ResultType{ValueType: types.AnyType, ErrorType: argTypes[0]}
+}
+
+// checkOptionBuiltin checks a call to some, which wraps a value in an
+// Option, or unwrapOr, which takes it out with a default for none
+func (c *checker) checkOptionBuiltin(call *ast.FunctionCall, argTypes []types.Type) types.Type {
+	params := 1
+	if call.Name == "unwrapOr" {
+		params = 2
+	}
+	if len(call.Arguments) != params {
+		c.errorf(call, i18n.GenArgumentCount, call.Name, params, len(call.Arguments), call.String())
+		return types.AnyType
+	}
+	if call.Name == "some" {
+		return &types.OptionType{ValueType: argTypes[0]}
+	}
+	option, ok := argTypes[0].(*types.OptionType)
+	if !ok {
+		if argTypes[0] != types.AnyType {
+			c.errorf(call.Arguments[0], i18n.GenArgumentType, 1, call.Name, "option", "Option", argTypes[0], call.String())
+		}
+		return argTypes[1]
+	}
+	if !assignable(option.ValueType, argTypes[1], call.Arguments[1]) {
+		c.errorf(call.Arguments[1], i18n.GenArgumentType, 2, call.Name, "fallback", option.ValueType, argTypes[1], call.String())
+	}
+	if option.ValueType == types.AnyType {
+		return argTypes[1]
+	}
+	return option.ValueType
+}
+
+// checkRange checks a call to range, which takes the end and optionally a
+// start before it and a step after it, and returns the numbers as an array
+func (c *checker) checkRange(call *ast.FunctionCall, argTypes []types.Type) types.Type {
+	result := &types.ArrayType{ElementType: types.IntType}
+	var params []string
+	switch len(call.Arguments) {
+	case 0:
+		c.errorf(call, i18n.GenArgumentCountAtLeast, call.Name, 1, 0, call.String())
+		return result
+	case 1:
+		params = []string{"end"}
+	case 2:
+		params = []string{"start", "end"}
+	case 3:
+		params = []string{"start", "end", "step"}
+	default:
+		c.errorf(call, i18n.GenArgumentCount, call.Name, 3, len(call.Arguments), call.String())
+		return result
+	}
+	for i, param := range params {
+		if argTypes[i] != types.IntType && argTypes[i] != types.AnyType {
+			c.errorf(call.Arguments[i], i18n.GenArgumentType, i+1, call.Name, param, types.IntType, argTypes[i], call.String())
+		}
+	}
+	if len(call.Arguments) == 3 {
+		if step, ok := constantInt(call.Arguments[2]); ok && step == 0 {
+			c.errorf(call.Arguments[2], i18n.TypeRangeStep)
+		}
+	}
+	return result
+}
+
+// checkChannelBuiltin checks a call to chan, send, recv or close. chan takes
+// the type of the values as a type argument, chan<int>(), and optionally the
+// number of values buffered.
+func (c *checker) checkChannelBuiltin(call *ast.FunctionCall, argTypes []types.Type) types.Type {
+	params := map[string][]string{"chan": {"capacity"}, "send": {"channel", "value"}, "recv": {"channel"}, "close": {"channel"}}[call.Name]
+	if call.Name == "chan" {
+		channel := &types.ChannelType{ElementType: types.AnyType}
+		if len(call.TypeArguments) > 1 {
+			c.errorf(call, i18n.TypeTypeArgumentCount, call.Name, 1, len(call.TypeArguments), call.String())
+		} else if len(call.TypeArguments) == 1 {
+			channel.ElementType = c.resolveType(call.TypeArguments[0])
+		}
+		if len(call.Arguments) > 1 {
+			c.errorf(call, i18n.GenArgumentCount, call.Name, 1, len(call.Arguments), call.String())
+		} else if len(call.Arguments) == 1 && !assignable(types.IntType, argTypes[0], call.Arguments[0]) {
+			c.errorf(call.Arguments[0], i18n.GenArgumentType, 1, call.Name, params[0], types.IntType, argTypes[0], call.String())
+		}
+		return channel
+	}
+	if len(call.Arguments) != len(params) {
+		c.errorf(call, i18n.GenArgumentCount, call.Name, len(params), len(call.Arguments), call.String())
+		return types.AnyType
+	}
+	channel, ok := argTypes[0].(*types.ChannelType)
+	if !ok {
+		if argTypes[0] != types.AnyType {
+			c.errorf(call.Arguments[0], i18n.GenArgumentType, 1, call.Name, params[0], "Channel", argTypes[0], call.String())
+		}
+		channel = &types.ChannelType{ElementType: types.AnyType}
+	}
+	switch call.Name {
+	case "send":
+		if !assignable(channel.ElementType, argTypes[1], call.Arguments[1]) {
+			c.errorf(call.Arguments[1], i18n.GenArgumentType, 2, call.Name, params[1], channel.ElementType, argTypes[1], call.String())
+		}
+	case "recv":
+		return &types.OptionType{ValueType: channel.ElementType}
+	}
+	return types.AnyType
+}
+
+// checkWhen checks the arms of a when: each receives from or sends to a
+// channel, and at most one is _
+func (c *checker) checkWhen(s *ast.WhenStatement, scope *types.SymbolTable) {
+	defaults := 0
+	for i := range s.Arms {
+		arm := &s.Arms[i]
+		armScope := types.NewSymbolTable(scope)
+		if arm.IsDefault() {
+			if defaults++; defaults > 1 {
+				c.errorf(arm, i18n.TypeDuplicatePattern, "_")
+			}
+		} else {
+			channelType := c.checkExpression(arm.Channel, scope)
+			channel, ok := channelType.(*types.ChannelType)
+			if !ok {
+				if channelType != types.AnyType {
+					c.errorf(arm.Channel, i18n.TypeNotChannel, channelType)
+				}
+				channel = &types.ChannelType{ElementType: types.AnyType}
+			}
+			if arm.Sent != nil {
+				sent := c.checkExpression(arm.Sent, scope)
+				if !assignable(channel.ElementType, sent, arm.Sent) {
+					c.errorf(arm.Sent, i18n.GenArgumentType, 2, "send", "value", channel.ElementType, sent, arm.Head())
+				}
+			}
+			if arm.Name != "" {
+				armScope.Define(arm.Name, channel.ElementType)
+			}
+		}
+		if arm.Block != nil {
+			c.checkStatements(arm.Block.Statements, armScope)
+		} else {
+			c.checkStatement(&ast.ExpressionStatement{Position: arm.Value.Pos(), Expression: arm.Value}, armScope)
+		}
+	}
+}
+
+// checkConstructor checks a call that creates a value of an enum variant
+func (c *checker) checkConstructor(call *ast.FunctionCall, enum *ast.EnumDeclaration, argTypes []types.Type) types.Type {
+	variant, _ := enum.Variant(call.Name)
+	if len(call.Arguments) != len(variant.Fields) {
+		c.errorf(call, i18n.GenArgumentCount, call.Name, len(variant.Fields), len(call.Arguments), call.String())
+		return &types.EnumType{Name: enum.Name}
+	}
+	for i, arg := range call.Arguments {
+		fieldType := c.resolveType(variant.Fields[i])
+		if !assignable(fieldType, argTypes[i], arg) {
+			c.errorf(arg, i18n.TypeVariantField, i+1, call.Name, fieldType, argTypes[i])
+		}
+	}
+	return &types.EnumType{Name: enum.Name}
+}
@@ -0,0 +1,361 @@
+package typechecker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/linkalls/zeno-lang/lexer"
+	"github.com/linkalls/zeno-lang/parser"
+	"github.com/linkalls/zeno-lang/stdlib"
+)
+
+func check(t *testing.T, input string) []*Error {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("parser errors for input:\n%s\nErrors: %v", input, p.Errors())
+	}
+	return Check(program, "main.zeno")
+}
+
+func TestCheckValidPrograms(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	tests := []string{
+		"let x = 1\nlet y: float = 2\nlet z = x * 3 + 1",
+		"let f = 1.5\nlet g = f * 2",
+		"let s = \"a\" + \"b\"\nif s == \"ab\" {\n    println(s)\n}",
+		"fn add(a: int, b: int): int {\n    return a + b\n}\nlet total = add(1, 2)",
+		"fn sum(...values: int): int {\n    let mut total = 0\n    for v in values {\n        total = total + v\n    }\n    return total\n}\nlet s = sum(1, 2, 3)",
+		"fn first<T>(value: T): T {\n    return value\n}\nlet a = first(1)\nlet b = first(\"x\")",
+		"fn pair<A, B>(a: A, b: B): (A, B) {\n    return (a, b)\n}\nlet (n, s) = pair(1, \"x\")\nlet m: int = n + 1\nlet t: string = s + \"y\"",
+		"fn orElse<T>(value: Option<T>, fallback: T): T {\n    return unwrapOr(value, fallback)\n}\nlet n: int = orElse(none, 4) + 1\nlet f: float = orElse(some(1), 2.5)",
+		"type Point = {\n    x: int\n    y: int\n}\nlet p = Point{x: 1, y: 2}\nlet sum = p.x + p.y",
+		"let config = {debug: true, level: 3}\nprintln(len(config), typeOf(config))",
+		"let parsed = int(\"42\")\nif parsed.ok {\n    println(parsed.value)\n}",
+		"let mut total = 0\nspawn {\n    let mut mine = total\n    mine = mine + 1\n}\nspawn println(total)\ntotal = 1\nwait()",
+		"fn safe(n: int): int {\n    let mut result = 0\n    try {\n        result = [1][n]\n    } catch e {\n        let message: string = e\n        return -1\n    }\n    return result\n}\npanic(\"done\")",
+		"let jobs: Channel<int> = chan(2)\nsend(jobs, 1)\nclose(jobs)\nfor j in jobs {\n    println(j + 1)\n}\nlet next: Option<int> = recv(jobs)\nwhen {\n    n from jobs => println(n + 1),\n    send(jobs, 2) => println(\"sent\"),\n    _ => println(\"idle\"),\n}",
+		"let n = toInt(\"4\")\nlet m: int = n.value + 1\nlet s: string = toString(m)\nlet on: bool = parseBool(\"true\").value\nlet f: float = toFloat(\"1.5\").value",
+		"import { readFile } from \"std/io\"\nlet text = readFile(\"a.txt\")\nlet n = len(text)",
+		"let mut count = 0\nwhile count < 3 {\n    count = count + 1\n}",
+		"let name = \"zeno\"\nif name {\n    println(name)\n}",
+		"println(build.VERSION)",
+		"let n = 2\nlet name = match n {\n    1 => \"one\",\n    _ => {\n        let s = str(n)\n        s\n    },\n}\nlet half: float = match n {\n    1 => 0.5,\n    _ => 1,\n}",
+		"enum Shape {\n    Circle(float),\n    Rect(float, float),\n    Empty,\n}\nfn area(s: Shape): float {\n    return match s {\n        Circle(r) => 3.0 * r * r,\n        Rect(w, _) => w,\n        Empty => 0.0,\n    }\n}\nlet s: Shape = Circle(1)\nlet a = area(Empty) + area(s)",
+		"fn half(n: int): Result<int, string> {\n    if n < 0 {\n        return err(\"negative\")\n    }\n    return ok(n / 2)\n}\nfn quarter(s: string): Result<int> {\n    let n = int(s)?\n    return ok(half(half(n)?)?)\n}\nlet q = quarter(\"8\")\nif q.ok {\n    println(q.value + 1)\n}",
+		"fn find(name: string): Option<int> {\n    let mut i = 0\n    for n in [\"a\", \"b\"] {\n        if n == name {\n            return some(i)\n        }\n        i = i + 1\n    }\n    return none\n}\nlet found = find(\"b\")\nlet index = match found {\n    some(i) => i + 1,\n    none => 0,\n}\nlet fallback = unwrapOr(found, 1) * 2\nlet maybe: Option<float> = some(1)",
+		"let items = [1, 2, 3]\nlet first: int = items[0]\nlet rest = items[1:]\nlet n = rest[len(rest) - 1] + items[:2][0]\nlet name = \"zeno\"\nlet initial: string = name[0] + name[1:3]\nlet config = {debug: true}\nprintln(config[\"debug\"], [1, 2][1])",
+		"fn sum(values: []int): int {\n    let mut total = 0\n    for v in values {\n        total = total + v\n    }\n    return total\n}\nfn names(): []string {\n    return []\n}\nlet xs: []float = [1, 2]\nlet first: string = names()[0]\nlet n = sum([1, 2]) + sum([])",
+		"fn double(n: int): int {\n    return n * 2\n}\nfn even(n: int): bool {\n    return n % 2 == 0\n}\nlet words = \"a,b\".split(\",\")\nlet n: int = words[0].toUpper().length() + \" x \".trim().indexOf(\"x\")\nlet mut items = [1, 2, 3]\nitems.push(4)\nlet doubled: []int = items.map(double).filter(even)\nlet text: string = words.join(\"-\")\nlet found: bool = items.contains(2) && text.replace(\"-\", \"\").startsWith(\"a\")",
+		"let mut total = 0\nfor i in range(10) {\n    total = total + i\n}\nfor i in range(10, 0, -2) {\n    total = total - i\n}\nlet xs: []int = range(1, 4)",
+		"let names = [\"a\", \"b\"]\nfor i, name in names {\n    let label: string = str(i + 1) + name\n    println(label)\n}",
+		"let flags = 1 << 3 | 1\nlet low: int = flags & 15 ^ 2 >> 1",
+		"let n = 3\nlet f = 2.5\nlet sum: float = n + f\nlet ratio: float = len(\"ab\") / f\nlet less: bool = n < f",
+		"let b: byte = 200\nlet n: i32 = -5 * 2\nlet big = 18446744073709551615u64\nlet sum: u64 = big / 2 + 1\nlet shifted: i32 = n << len(\"ab\")\nlet wide: i64 = i64(n).value\nlet back: int = int(b).value\nlet bytes: []byte = [104, 105]\nif b > 10 && sum != big {\n    println(typeOf(b))\n}",
+		"const SEP = ','\nfn isDigit(c: char): bool {\n    return c >= '0' && c <= '9'\n}\nlet mut digits: []char = []\nfor c in \"a1b2\" {\n    if isDigit(c) && c != SEP {\n        digits.push(c)\n    }\n}\nlet code: int = int('a').value\nlet next: char = char(code + 1).value",
+		"let argv: []string = args()\nlet first: string = argv[0]",
+		"let n = 3\nlet name: string = if n == 1 {\n    \"one\"\n} else if n == 2 {\n    \"two\"\n} else {\n    let s = str(n)\n    s\n}\nlet half: float = if n > 2 {\n    0.5\n} else {\n    1\n}",
+		"fn divmod(a: int, b: int): (int, int) {\n    return (a / b, a % b)\n}\nfn scale(): (float, string) {\n    return (1, \"x\")\n}\nlet (q, _) = divmod(7, 2)\nlet mut (f, s) = scale()\nf = f + 0.5\nlet sum: int = q + 1",
+		"type Person = {\n    name: string\n    age: int\n}\nlet p = Person{name: \"a\", age: 1}\nlet {name, age} = p\nlet label: string = name + str(age)\nlet [first, _] = [1.5, 2.5]\nlet half: float = first / 2\nlet {debug} = {debug: true}",
+		"let ok = true\nmatch ok {\n    true => println(1),\n    false => {\n        println(2)\n    }\n}",
+		"import { compile, matches } from \"std/regex\"\nlet re = compile(\"[a-z]+\")\nif re.ok {\n    let found: bool = matches(re.value, \"abc\")\n}",
+		"import { compile as pattern, matches } from \"std/regex\"\nfn compile(): int {\n    return 1\n}\nlet re = pattern(\"[a-z]+\")\nif re.ok {\n    let found: bool = matches(re.value, \"abc\")\n}\nlet n: int = compile()",
+		"import * as regex from \"std/regex\"\nlet re = regex.compile(\"[a-z]+\")\nif re.ok {\n    let found: bool = regex.matches(re.value, \"abc\")\n}\nfn check(regex: string): int {\n    return regex.length()\n}",
+		"import { newSet, newStack } from \"std/collections\"\nlet s = newSet<int>()\nlet added: bool = s.add(1)\nlet stack = newStack<string>()\nstack.push(\"a\")\nlet top: string = unwrapOr(stack.pop(), \"\")\nfor n in s {\n    let m: int = n + 1\n}",
+		"import { newMutex, withLock, newAtomicInt, atomicAdd } from \"std/sync\"\nfn tick(): int {\n    return 1\n}\nlet m = newMutex()\nlet hits = newAtomicInt(0)\nspawn {\n    let n: int = atomicAdd(hits, 1)\n}\nlet r = withLock(m, tick)",
+		"type Money = {\n    cents: int\n}\nimpl Add for Money {\n    fn add(a: Money, b: Money): Money {\n        return Money{cents: a.cents + b.cents}\n    }\n}\nimpl Ord for Money {\n    fn compare(a: Money, b: Money): int {\n        return a.cents - b.cents\n    }\n}\nlet m = Money{cents: 1}\nlet total: Money = m + m\nlet less: bool = m < total",
+		"let mut count = 0\nconst limit = 10\nconst half = limit / 2 + 1\nfn bump(): int {\n    count = count + 1\n    return count * half\n}\nfn main() {\n    let n: int = bump() + limit\n}",
+		"let total: int = base * 2\nconst limit = max + 1\nconst max = 4\nlet base = 21\nfn main() {\n    println(total, limit)\n}",
+		"println(limit)\nconst limit = 3",
+		"let d: byte = 255\nlet e: i32 = -2147483648\nlet f: u64 = 0\nlet g = d + 1",
+		"const greeting = \"hi\"\nfn show() {\n    println(greeting)\n}\nshow()",
+		"import { decode } from \"std/json\"\ntype User = {\n    name: string\n}\nlet user = decode<User>(\"{}\")\nif user.ok {\n    let name: string = user.value.name\n}",
+	}
+	for _, input := range tests {
+		if errs := check(t, input); len(errs) > 0 {
+			t.Errorf("unexpected type errors for input:\n%s\nErrors: %v", input, errs)
+		}
+	}
+}
+
+func TestCheckErrors(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	tests := []struct {
+		input   string
+		code    string
+		message string
+		line    int
+	}{
+		{"let count: int = \"three\"", "Z0117", "Variable 'count' is declared as int but initialized with string", 1},
+		{"let mut x = 1\nx = \"one\"", "Z0117", "Cannot assign string to variable 'x' of type int", 2},
+		{"type Point = {\n    x: int\n}\nlet p = Point{x: \"1\"}", "Z0117", "Field 'x' of 'Point' expects int, got string", 4},
+		{"type Point = {\n    x: int\n}\nlet p = Point{x: 1, z: 2}", "Z0116", "Type 'Point' has no field 'z'", 4},
+		{"type Point = {\n    x: int\n}\nlet p = Point{x: 1}\nlet y = p.y", "Z0116", "Type 'Point' has no field 'y'", 5},
+		{"fn name(): string {\n    return 42\n}", "Z0118", "Function 'name' returns string, but the return value is int", 2},
+		{"fn name(): string {\n    return\n}", "Z0118", "Function 'name' must return a value of type string", 2},
+		{"let n = 1\nlet x = if n > 0 {\n    1\n} else {\n    \"none\"\n}", "Z0117", "If branch has type string, but the previous branches have type int", 5},
+		{"let x = if [1] {\n    1\n} else {\n    2\n}", "Z0121", "Condition must be a bool, got []int", 1},
+		{"fn pair(): (int, string) {\n    return (1, 2)\n}", "Z0118", "Function 'pair' returns (int, string), but the return value is (int, int)", 2},
+		{"fn pair(): (int, string) {\n    return (1, \"a\")\n}\nlet (a, b, c) = pair()", "Z0138", "Tuple of type (int, string) has 2 elements, but 3 names are given", 4},
+		{"fn pair(): (int, string) {\n    return (1, \"a\")\n}\nlet t = pair()", "Z0138", "A tuple of type (int, string) cannot be stored in a variable", 4},
+		{"let (a, b) = 5", "Z0138", "Cannot take apart int with let (...)", 1},
+		{"type Person = {\n    name: string\n}\nlet p = Person{name: \"a\"}\nlet {nme} = p", "Z0116", "Type 'Person' has no field 'nme'", 5},
+		{"let {a} = 5", "Z0138", "Cannot take apart int with let {...}; it is not a struct or map", 1},
+		{"let [a] = \"ab\"", "Z0138", "Cannot take apart string with let [...]; it is not an array", 1},
+		{"let r = 7.5 % 2", "Z0119", "Operator % cannot be applied to float and int", 1},
+		{"let f = 1.5\nlet r = f << 2", "Z0119", "Operator << cannot be applied to float and int", 2},
+		{"let r = \"a\" | \"b\"", "Z0119", "Operator | cannot be applied to string and string", 1},
+		{"let s = \"items: \" + 3", "Z0119", "Operator + cannot be applied to string and int", 1},
+		{"let a = 1\nlet b = 1.5\nlet c = a % b", "Z0119", "Operator % cannot be applied to int and float", 3},
+		{"let a = 1\nlet b = 1.5\nlet c: int = a * b", "Z0117", "Variable 'c' is declared as int but initialized with float", 3},
+		{"let b = true\nlet c = b * 2", "Z0119", "Operator * cannot be applied to bool and int", 2},
+		{"let a = 1 && true", "Z0119", "Operator && cannot be applied to int and bool", 1},
+		{"let a = !1", "Z0119", "Operator ! cannot be applied to int", 1},
+		{"let n: i32 = 5\nlet m = 3\nlet a = n + m", "Z0119", "Operator + cannot be applied to i32 and int", 3},
+		{"let n: u32 = 5\nlet f = n * 1.5", "Z0119", "Operator * cannot be applied to u32 and float", 2},
+		{"let m = 3\nlet b: byte = m", "Z0117", "Variable 'b' is declared as byte but initialized with int", 2},
+		{"let a = 1i64 + 1i32", "Z0119", "Operator + cannot be applied to i64 and i32", 1},
+		{"let c = 'a'\nlet d = c + 1", "Z0119", "Operator + cannot be applied to char and int", 2},
+		{"let same = 'a' == \"a\"", "Z0119", "Operator == cannot be applied to char and string", 1},
+		{"let c: char = \"a\"", "Z0117", "Variable 'c' is declared as char but initialized with string", 1},
+		{"let n = 7\nlet half = n / 0", "Z0156", "Division by zero in (n / 0): the divisor is always 0", 2},
+		{"let n = 7\nlet r = n % (2 - 2)", "Z0156", "Division by zero in (n % (2 - 2))", 2},
+		{"let f = 1.5\nlet r = f / 0.0", "Z0156", "Division by zero", 2},
+		{"let big = 9223372036854775807 + 1", "Z0157", "The constant expression (9223372036854775807 + 1) overflows int", 1},
+		{"let big = (4611686018427387904 * 2 - 1) * 2", "Z0157", "(4611686018427387904 * 2) overflows int", 1},
+		{"let big = 1 << 63", "Z0157", "(1 << 63) overflows int", 1},
+		{"let big = -(-9223372036854775807 - 1)", "Z0157", "overflows int", 1},
+		{"fn show() {\n    println(message)\n}", "Z0120", "Undefined variable 'message'", 2},
+		{"let message = \"hi\"\nfn show() {\n    println(message)\n}\nshow()", "Z0120", "Undefined variable 'message'", 3},
+		{"if [1, 2] {\n}", "Z0121", "Condition must be a bool, got []int", 1},
+		{"for c in 42 {\n}", "Z0122", "Cannot iterate over int", 1},
+		{"fn add(a: int, b: int): int {\n    return a + b\n}\nlet x = add(1)", "Z0113", "Function 'add' expects 2 argument(s), got 1 in call add(1)", 4},
+		{"fn add(a: int, b: int): int {\n    return a + b\n}\nlet x = add(1, \"2\")", "Z0114", "Argument 2 of 'add' (parameter 'b') expects int, got string", 4},
+		{"import { readFile } from \"std/io\"\nlet text = readFile(1)", "Z0114", "Argument 1 of 'readFile' (parameter 'path') expects string, got int", 2},
+		{"let n = len(1, 2)", "Z0113", "Function 'len' expects 1 argument(s), got 2", 1},
+		{"let n = undefinedFunction(1)", "Z0106", "Function 'undefinedFunction' is not defined or imported", 1},
+		{"let n = 1\nmatch n {\n    \"one\" => println(n),\n}", "Z0124", "Pattern \"one\" of type string cannot match a value of type int", 3},
+		{"let n = 1\nmatch n {\n    1 => println(n),\n    1 => println(n),\n}", "Z0125", "Pattern 1 is already matched by an earlier arm", 4},
+		{"enum Shape {\n    Circle(float),\n}\nlet s = Circle(\"big\")", "Z0117", "Field 1 of variant 'Circle' expects float, got string", 4},
+		{"enum Shape {\n    Circle(float),\n}\nlet s = Circle()", "Z0113", "Function 'Circle' expects 1 argument(s), got 0", 4},
+		{"enum Shape {\n    Rect(float, float),\n}\nlet s = Rect(1.0, 2.0)\nmatch s {\n    Rect(w) => println(w),\n}", "Z0126", "Variant Rect has 2 field(s), but pattern Rect(w) binds 1", 6},
+		{"enum Shape {\n    Circle(float),\n}\nlet s = Circle(1.0)\nmatch s {\n    Circle(1) => println(s),\n}", "Z0126", "Pattern Circle(1) must bind each field of variant Circle to a name", 6},
+		{"enum Shape {\n    Empty,\n}\nlet n = 1\nmatch n {\n    Empty => println(n),\n}", "Z0124", "Pattern Empty of type Shape cannot match a value of type int", 6},
+		{"let n = 1\nlet s = match n {\n    1 => \"one\",\n    _ => 2,\n}", "Z0117", "Match arm has type int, but the previous arms have type string", 4},
+		{"fn next(n: int): Result<int> {\n    return ok(n? + 1)\n}", "Z0127", "The ? operator needs a Result, got int", 2},
+		{"fn main() {\n    let n = int(\"42\")?\n    println(n)\n}", "Z0128", "The ? operator can only be used in a function that returns a Result", 2},
+		{"fn code(): Result<int, int> {\n    return err(1)\n}\nfn parse(s: string): Result<int> {\n    let n = code()?\n    return ok(n)\n}", "Z0128", "Function 'parse' returns errors of type string, but ? passes on errors of type int", 5},
+		{"fn parse(s: string): Result<int> {\n    return ok(\"x\")\n}", "Z0118", "Function 'parse' returns Result<int, string>, but the return value is Result<string, any>", 2},
+		{"let n: Option<int> = some(1)\nlet m = n + 1", "Z0130", "n may be none; unwrap it with match or unwrapOr before using it", 2},
+		{"let n: Option<int> = none\nif n {\n}", "Z0130", "n may be none", 2},
+		{"let n: Option<string> = some(1)", "Z0117", "declared as Option<string> but initialized with Option<int>", 1},
+		{"let n = unwrapOr(1, 2)", "Z0114", "Argument 1 of 'unwrapOr' (parameter 'option') expects Option, got int", 1},
+		{"let n = some(1)\nlet m = unwrapOr(n, \"x\")", "Z0114", "Argument 2 of 'unwrapOr' (parameter 'fallback') expects int, got string", 2},
+		{"let n = some(1)\nmatch n {\n    some(a, b) => println(a),\n    none => println(0),\n}", "Z0126", "Variant some has 1 field(s), but pattern some(a, b) binds 2", 3},
+		{"let n = 1\nmatch n {\n    none => println(n),\n}", "Z0124", "Pattern none of type Option<any> cannot match a value of type int", 3},
+		{"let n = 42\nlet d = n[0]", "Z0131", "Cannot index a value of type int", 2},
+		{"let items = [1, 2]\nlet x = items[\"0\"]", "Z0132", "Index of []int must be int, got string", 2},
+		{"let items = [1, 2]\nlet x = items[0:1.5]", "Z0132", "Index of []int must be int, got float", 2},
+		{"let items = [1, 2]\nlet x = items[-1]", "Z0133", "Index -1 is negative", 2},
+		{"let x = [1, 2, 3][3]", "Z0133", "Index 3 is out of range for length 3", 1},
+		{"let x = \"ab\"[1:3]", "Z0133", "Index 3 is out of range for length 2", 1},
+		{"let items = [1, 2]\nlet x = items[2:1]", "Z0133", "Slice bounds 2:1 are inverted", 2},
+		{"let items = [1, 2]\nlet x: string = items[0]", "Z0117", "declared as string but initialized with int", 2},
+		{"let xs: []int = [\"a\"]", "Z0117", "Variable 'xs' is declared as []int but initialized with []string", 1},
+		{"fn sum(values: []int): int {\n    return 0\n}\nlet n = sum([1.5])", "Z0114", "Argument 1 of 'sum' (parameter 'values') expects []int, got []float", 4},
+		{"fn names(): []string {\n    return [1]\n}", "Z0118", "Function 'names' returns []string, but the return value is []int", 2},
+		{"let name = \"zeno\"\nlet x = name.upper()", "Z0134", "Type string has no method 'upper'", 2},
+		{"let n = 42\nlet x = n.length()", "Z0134", "Type int has no method 'length'", 2},
+		{"let items = [1, 2]\nlet x = items.toUpper()", "Z0134", "Type []int has no method 'toUpper'", 2},
+		{"let name = \"zeno\"\nlet x = name.contains(1)", "Z0114", "Argument 1 of 'contains' (parameter 'substr') expects string, got int", 2},
+		{"let mut items = [1, 2]\nitems.push(\"3\")", "Z0114", "Argument 1 of 'push' (parameter 'value') expects int, got string", 2},
+		{"let name = \"zeno\"\nlet x = name.split()", "Z0113", "Function 'split' expects 1 argument(s), got 0", 2},
+		{"let mut items = [1, 2]\nlet x = items.push(3)", "Z0135", "push must be called on a variable", 2},
+		{"[1, 2].push(3)", "Z0135", "push must be called on a variable", 1},
+		{"let n: Option<string> = none\nlet x = n.length()", "Z0130", "n may be none", 2},
+		{"let xs = range()", "Z0113", "Function 'range' expects at least 1 argument(s), got 0", 1},
+		{"let xs = range(1, 2, 3, 4)", "Z0113", "Function 'range' expects 3 argument(s), got 4", 1},
+		{"let argv = args(1)", "Z0113", "Function 'args' expects 0 argument(s), got 1", 1},
+		{"for i in range(1.5) {\n}", "Z0114", "Argument 1 of 'range' (parameter 'end') expects int, got float", 1},
+		{"for i in range(0, 10, 0) {\n}", "Z0136", "The step of range must not be 0", 1},
+		{"for i, c in \"abc\" {\n}", "Z0122", "Cannot iterate over string with an index", 1},
+		{"for i, v in [\"a\"] {\n    let s: string = i\n}", "Z0117", "declared as string but initialized with int", 2},
+		{"let count = 0\ncount = count + 1", "Z0137", "Cannot change immutable variable 'count'", 2},
+		{"fn grow(items: []int) {\n    items.push(1)\n}", "Z0137", "Cannot change immutable variable 'items'", 2},
+		{"for i in range(3) {\n    i = 0\n}", "Z0137", "Cannot change immutable variable 'i'", 2},
+		{"let r = int(\"1\")\nprintln(r.message)", "Z0116", "Type 'Result' has no field 'message'", 2},
+		{"import { newSet } from \"std/collections\"\nlet s = newSet<string>()\nlet added = s.add(1)", "Z0114", "Argument 1 of 'add' (parameter 'value') expects string, got int", 3},
+		{"import { newQueue } from \"std/collections\"\nlet q = newQueue<int>()\nq.push(1)", "Z0134", "Type Queue<int> has no method 'push'", 3},
+		{"import { matches } from \"std/regex\"\nlet found = matches(\"a+\", \"aa\")", "Z0114", "Argument 1 of 'matches' (parameter 're') expects Regex, got string", 2},
+		{"import { lock, newAtomicInt } from \"std/sync\"\nlet hits = newAtomicInt(0)\nlock(hits)", "Z0114", "Argument 1 of 'lock' (parameter 'm') expects Mutex, got AtomicInt", 3},
+		{"fn first<T>(items: []T): T {\n    return items[0]\n}\nlet x = first<int, string>([1])", "Z0140", "Function first takes 1 type argument(s), but 2 were given", 4},
+		{"fn first<T>(items: []T): T {\n    return items[0]\n}\nlet x = first<int>([\"a\"])", "Z0114", "Argument 1 of 'first' (parameter 'items') expects []int, got []string", 4},
+		{"fn empty<T>(): []T {\n    let items: []T = []\n    return items\n}\nlet e = empty()", "Z0145", "Cannot infer the type parameter T of empty", 5},
+		{"fn same<T>(a: T, b: T): T {\n    return b\n}\nlet s = same(1, \"a\")", "Z0114", "Argument 2 of 'same' (parameter 'b') expects int, got string", 4},
+		{"fn identity<T>(value: T): T {\n    return value\n}\nlet s: string = identity(5)", "Z0117", "Variable 's' is declared as string but initialized with int", 4},
+		{"fn f(): int {\n    spawn {\n        return 1\n    }\n    return 0\n}", "Z0141", "'return' cannot be used in a spawn block", 3},
+		{"fn f(s: string): Result<int> {\n    spawn {\n        let n = int(s)?\n    }\n    return ok(0)\n}", "Z0141", "'?' cannot be used in a spawn block", 3},
+		{"let mut total = 0\nspawn {\n    total = total + 1\n}", "Z0142", "Cannot change 'total' in a spawn block", 3},
+		{"let mut items = [1]\nspawn {\n    items.push(2)\n}", "Z0142", "Cannot change 'items' in a spawn block", 3},
+		{"fn f(): int {\n    try {\n        return 1\n    } catch {\n    }\n    return 0\n}", "Z0144", "'return' cannot be used in a try block", 3},
+		{"fn f(s: string): Result<int> {\n    try {\n        let n = int(s)?\n    } catch {\n    }\n    return ok(0)\n}", "Z0144", "'?' cannot be used in a try block", 3},
+		{"try {\n    println(1)\n} catch e {\n    let n: int = e\n}", "Z0117", "string", 4},
+		{"impl Hash for Money {\n}", "Z0146", "Unknown trait Hash; the traits are Add, Eq, Ord", 1},
+		{"impl Add for int {\n    fn add(a: int, b: int): int {\n        return a\n    }\n}", "Z0147", "not int", 1},
+		{"type Money = {\n    cents: int\n}\nimpl Eq for Money {\n    fn eq(a: Money, b: int): bool {\n        return true\n    }\n}", "Z0148", "impl Eq for Money must declare exactly fn eq(a: Money, b: Money): bool", 5},
+		{"type Money = {\n    cents: int\n}\nlet m = Money{cents: 1}\nlet less = m < m", "Z0119", "Operator < cannot be applied to Money and Money", 5},
+		{"const name = len(\"a\")", "Z0149", "The value of const name must be a constant", 1},
+		{"const limit = 10\nfn main() {\n    limit = 20\n}", "Z0137", "Cannot change immutable variable 'limit'", 3},
+		{"type Point = {\n    x: int\n}\nlet p = Pont{x: 1}", "Z0158", "Unknown type Pont", 4},
+		{"let h = Hidden{v: 1}", "Z0158", "Unknown type Hidden", 1},
+		{"let d: byte = 256", "Z0032", "integer literal 256 is out of range for byte, which holds 0 to 255", 1},
+		{"let d: i32 = 5000000000", "Z0032", "out of range for i32", 1},
+		{"let d: u32 = -1", "Z0032", "out of range for u32", 1},
+		{"let d = -1u32", "Z0032", "integer literal -1u32 is out of range for u32", 1},
+		{"fn f(b: byte): byte {\n    return b + 300\n}", "Z0032", "integer literal 300 is out of range for byte", 2},
+		{"let a = b + 1\nlet b = a\nfn main() {\n    println(a)\n}", "Z0150", "The initialization of a depends on itself: a -> b -> a", 1},
+		{"println(n)\nlet n = 3", "Z0120", "Undefined variable 'n'", 1},
+		{"let total = sum()\nfn sum(): int {\n    return count() + 1\n}\nfn count(): int {\n    return total\n}\nfn main() {\n    println(total)\n}", "Z0150", "The initialization of total depends on itself: total -> sum -> count -> total", 1},
+		{"let c = chan<int>()\nsend(c, \"x\")", "Z0114", "Argument 2 of 'send' (parameter 'value') expects int, got string", 2},
+		{"let c = chan<string>()\nlet n: int = unwrapOr(recv(c), \"\")", "Z0117", "string", 2},
+		{"let n = 1\nwhen {\n    v from n => println(v),\n}", "Z0143", "Expected a channel in a when arm, got int", 3},
+		{"let c = chan<int>()\nwhen {\n    send(c, true) => println(1),\n}", "Z0114", "Argument 2 of 'send' (parameter 'value') expects int, got bool in call send(c, true)", 3},
+		{"let c = chan<int>()\nwhen {\n    c => println(1),\n    _ => println(2),\n    _ => println(3),\n}", "Z0125", "Pattern _ is already matched by an earlier arm", 5},
+		{"import * as io from \"std/io\"\nlet n: int = io.readFile(\"a.txt\")", "Z0117", "Variable 'n' is declared as int but initialized with string", 2},
+		{"import * as io from \"std/io\"\nio.read(\"a.txt\")", "Z0109", "Function 'read' is not exported from module 'std/io'", 2},
+		{"import * as io from \"std/io\"\nlet files = io", "Z0151", "io names the module std/io and can only be used to call its functions", 2},
+		{"fn sign(n: int): int {\n    if n > 0 {\n        return 1\n    } else if n < 0 {\n        return -1\n    }\n}", "Z0155", "Function 'sign' can end without returning a value of type int", 2},
+		{"fn first(n: int): int {\n    loop {\n        if n > 0 {\n            break\n        }\n    }\n}", "Z0155", "Function 'first' can end without returning a value of type int", 2},
+		{"fn name(n: int): string {\n    match n {\n        1 => {\n            return \"one\"\n        },\n    }\n}", "Z0155", "Function 'name' can end without returning a value of type string", 2},
+		{"fn name(n: int): string {\n    if n > 0 {\n        return \"positive\"\n    } else {\n        println(n)\n    }\n}", "Z0155", "Function 'name' can end without returning a value of type string", 6},
+		{"fn pick(ch: Channel<int>): int {\n    when {\n        v from ch => println(v),\n    }\n}", "Z0155", "Function 'pick' can end without returning a value of type int", 3},
+		{"fn wait(ch: Channel<int>): int {\n    loop {\n        when {\n            v from ch => {\n                break\n            }\n        }\n    }\n}", "Z0155", "Function 'wait' can end without returning a value of type int", 2},
+	}
+	for _, tt := range tests {
+		errs := check(t, tt.input)
+		if len(errs) != 1 {
+			t.Errorf("expected 1 type error for input:\n%s\ngot %d: %v", tt.input, len(errs), errs)
+			continue
+		}
+		err := errs[0]
+		if err.Code != tt.code {
+			t.Errorf("expected code %s for input:\n%s\ngot %s", tt.code, tt.input, err.Code)
+		}
+		if !strings.Contains(err.Message, tt.message) {
+			t.Errorf("expected message %q for input:\n%s\ngot %q", tt.message, tt.input, err.Message)
+		}
+		if err.Pos.Line != tt.line {
+			t.Errorf("expected error on line %d for input:\n%s\ngot line %d", tt.line, tt.input, err.Pos.Line)
+		}
+	}
+}
+
+func TestTypes(t *testing.T) {
+	program := parser.New(lexer.New("let names = [\"a\"]\nlet n = len(names) + 1")).ParseProgram()
+	exprTypes, errs := Types(program, "main.zeno")
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	found := make(map[string]string)
+	for expr, typ := range exprTypes {
+		found[expr.String()] = typ.String()
+	}
+	for expr, want := range map[string]string{"[\"a\"]": "[]string", "len(names)": "int", "(len(names) + 1)": "int"} {
+		if found[expr] != want {
+			t.Errorf("expected %s to be %s, got %q", expr, want, found[expr])
+		}
+	}
+}
+
+func TestCheckSuggestsMut(t *testing.T) {
+	errs := check(t, "let total = 0\ntotal = 1")
+	if len(errs) != 1 || errs[0].Suggestion != "declare it with `let mut total` to allow changing it" {
+		t.Errorf("expected a suggestion to add mut, got %v", errs)
+	}
+}
+
+func TestCheckAssignUndeclared(t *testing.T) {
+	errs := check(t, "fn main() {\n    total = 1\n}")
+	if len(errs) != 1 || errs[0].Code != "Z0139" || errs[0].Pos.Line != 2 {
+		t.Fatalf("expected a Z0139 error on line 2, got %v", errs)
+	}
+	if errs[0].Suggestion != "did you mean `let total = 1`?" {
+		t.Errorf("expected a suggestion to declare total, got %q", errs[0].Suggestion)
+	}
+}
+
+func TestCheckMissingReturn(t *testing.T) {
+	errs := check(t, "fn count(n: int): int {\n    while n > 0 {\n        return n\n    }\n}")
+	if len(errs) != 1 || errs[0].Code != "Z0155" || errs[0].Suggestion != "the loop can end; add a `return` after it" {
+		t.Errorf("expected a Z0155 error suggesting a return after the loop, got %v", errs)
+	}
+
+	// A loop without a break, a wildcard arm and a return in every branch
+	// end the function
+	for _, input := range []string{
+		"fn next(n: int): int {\n    loop {\n        return n\n    }\n}",
+		"fn name(n: int): string {\n    match n {\n        1 => {\n            return \"one\"\n        },\n        _ => {\n            return \"other\"\n        },\n    }\n}",
+		"fn sign(n: int): int {\n    if n > 0 {\n        return 1\n    } else if n < 0 {\n        return -1\n    } else {\n        return 0\n    }\n}",
+		"fn show(n: int) {\n    if n > 0 {\n        println(n)\n    }\n}",
+	} {
+		if errs := check(t, input); len(errs) > 0 {
+			t.Errorf("unexpected type errors for input:\n%s\nErrors: %v", input, errs)
+		}
+	}
+}
+
+func TestCheckSuggestsSizedConversion(t *testing.T) {
+	errs := check(t, "let n: i32 = 5\nlet m = 3\nlet a = n * m")
+	if len(errs) != 1 || errs[0].Suggestion != "convert m with i32(m), which returns a Result that fails if the value does not fit" {
+		t.Errorf("expected a suggestion to convert m, got %v", errs)
+	}
+}
+
+func TestCheckSuggestsImpl(t *testing.T) {
+	errs := check(t, "type Money = {\n    cents: int\n}\nlet m = Money{cents: 1}\nlet sum = m + m")
+	if len(errs) != 1 || !strings.HasPrefix(errs[0].Suggestion, "Implement the trait Add for Money") {
+		t.Errorf("expected a suggestion to implement Eq, got %v", errs)
+	}
+}
+
+func TestCheckSuggestsImport(t *testing.T) {
+	defer func(dir string) { stdlib.Dir = dir }(stdlib.Dir)
+	stdlib.Dir = "../std"
+
+	errs := check(t, "let text = readFile(\"a.txt\")")
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 type error, got %d: %v", len(errs), errs)
+	}
+	if !strings.Contains(errs[0].Suggestion, "import { readFile } from \"std/io\"") {
+		t.Errorf("expected an import suggestion, got %q", errs[0].Suggestion)
+	}
+
+	// Functions imported under another name keep it in the suggestion
+	errs = check(t, "import { compile as pattern } from \"std/regex\"\nlet ok = matches(pattern(\"a\").value, \"a\")")
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 type error, got %d: %v", len(errs), errs)
+	}
+	if !strings.Contains(errs[0].Suggestion, "import { compile as pattern, matches } from \"std/regex\"") {
+		t.Errorf("expected an import suggestion, got %q", errs[0].Suggestion)
+	}
+}
+
+func TestCheckCollectsAllErrorsInOrder(t *testing.T) {
+	errs := check(t, "let a: int = \"x\"\nlet b: string = 1\nlet c = missing")
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 type errors, got %d: %v", len(errs), errs)
+	}
+	for i, err := range errs {
+		if err.Pos.Line != i+1 {
+			t.Errorf("error %d: expected line %d, got %d", i, i+1, err.Pos.Line)
+		}
+	}
+	if !strings.HasPrefix(errs[0].Error(), "Type Error: [Z0117]") {
+		t.Errorf("unexpected error format: %q", errs[0].Error())
+	}
+}
@@ -0,0 +1,89 @@
+package types
+
+import "strings"
+
+// Infer binds the type parameters params that typeName is written with to
+// the parts of t they stand for, e.g. T to int for []T and []int. A binding
+// to any, which is all that is known of none or [], gives way to a later
+// one, as does int to float, like Go constants. Any other later type is left
+// for the caller to report when it checks t against the instantiated type.
+func Infer(typeName string, params []string, t Type, bindings map[string]Type) {
+	if t == nil {
+		return
+	}
+	for _, param := range params {
+		if typeName != param {
+			continue
+		}
+		bound, ok := bindings[param]
+		if !ok || (bound == AnyType && t != AnyType) || (bound == IntType && t == FloatType) {
+			bindings[param] = t
+		}
+		return
+	}
+	if element, isArray := strings.CutPrefix(typeName, "[]"); isArray {
+		if array, ok := t.(*ArrayType); ok {
+			Infer(element, params, array.ElementType, bindings)
+		}
+		return
+	}
+	if inner, isTuple := strings.CutPrefix(typeName, "("); isTuple && strings.HasSuffix(inner, ")") {
+		elements := SplitTypeList(strings.TrimSuffix(inner, ")"))
+		if tuple, ok := t.(*TupleType); ok && len(tuple.Elements) == len(elements) {
+			for i, element := range elements {
+				Infer(element, params, tuple.Elements[i], bindings)
+			}
+		}
+		return
+	}
+	base, rest, generic := strings.Cut(typeName, "<")
+	if !generic || !strings.HasSuffix(rest, ">") {
+		return
+	}
+	args := SplitTypeList(strings.TrimSuffix(rest, ">"))
+	var parts []Type
+	switch t := t.(type) {
+	case *OptionType:
+		if base == "Option" {
+			parts = []Type{t.ValueType}
+		}
+	case *ResultType:
+		if base == "Result" {
+			parts = []Type{t.ValueType, t.ErrorType}
+		}
+	case *ChannelType:
+		if base == "Channel" {
+			parts = []Type{t.ElementType}
+		}
+	case *CollectionType:
+		if base == t.Name {
+			parts = t.Elements
+		}
+	}
+	for i, arg := range args {
+		if i < len(parts) {
+			Infer(arg, params, parts[i], bindings)
+		}
+	}
+}
+
+// SplitTypeList splits "int, Pair<int, string>" at the commas that are not
+// nested in type arguments or tuple types
+func SplitTypeList(list string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range list {
+		switch r {
+		case '<', '(':
+			depth++
+		case '>', ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(list[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(list[start:]))
+}
@@ -1,5 +1,7 @@
 package types
 
+import "strings"
+
 // Type represents a Zeno type
 type Type interface {
 	String() string
@@ -21,8 +23,47 @@ var (
 	StringType = &BasicType{Name: "string"}
 	FloatType  = &BasicType{Name: "float"}
 	AnyType    = &BasicType{Name: "any"} // Represents any type, similar to interface{}
+	// CharType is a Unicode character, a Go rune
+	CharType = &BasicType{Name: "char"}
+	// IteratorType is the lazy sequence type provided by std/iter
+	IteratorType = &BasicType{Name: "Iterator"}
+	// RegexType is the compiled regular expression type provided by std/regex
+	RegexType = &BasicType{Name: "Regex"}
+	// MutexType and AtomicIntType are the types provided by std/sync
+	MutexType     = &BasicType{Name: "Mutex"}
+	AtomicIntType = &BasicType{Name: "AtomicInt"}
+)
+
+// The sized integer types, for the Go APIs that need a specific width. int
+// is 64 bits wide too, but is not i64: converting between them is explicit.
+var (
+	I32Type  = &BasicType{Name: "i32"}
+	I64Type  = &BasicType{Name: "i64"}
+	U32Type  = &BasicType{Name: "u32"}
+	U64Type  = &BasicType{Name: "u64"}
+	ByteType = &BasicType{Name: "byte"}
 )
 
+// SizedInts are the sized integer types by name
+var SizedInts = map[string]*BasicType{
+	"i32":  I32Type,
+	"i64":  I64Type,
+	"u32":  U32Type,
+	"u64":  U64Type,
+	"byte": ByteType,
+}
+
+// IsSized reports whether t is a sized integer type
+func IsSized(t Type) bool {
+	basic, ok := t.(*BasicType)
+	return ok && SizedInts[basic.Name] == basic
+}
+
+// IsInteger reports whether t is int or a sized integer type
+func IsInteger(t Type) bool {
+	return t == IntType || IsSized(t)
+}
+
 // ArrayType represents an array type.
 type ArrayType struct {
 	ElementType Type // The type of the elements in the array
@@ -38,20 +79,121 @@ func (a *ArrayType) String() string {
 	return "[]" + a.ElementType.String()
 }
 
-// ResultType represents a Result<T> type for error handling
+// ResultType represents a Result<T, E> type for error handling. Result<T>
+// is short for Result<T, string>.
 type ResultType struct {
 	ValueType Type // The type of the success value
+	ErrorType Type // The type of the error
 }
 
 // String returns a string representation of the result type.
 func (r *ResultType) String() string {
-	return "Result<" + r.ValueType.String() + ">"
+	return "Result<" + r.ValueType.String() + ", " + r.ErrorType.String() + ">"
+}
+
+// OptionType represents an Option<T> value, which is either some value of
+// type T or none
+type OptionType struct {
+	ValueType Type // The type of the value
+}
+
+func (o *OptionType) String() string {
+	return "Option<" + o.ValueType.String() + ">"
+}
+
+// Collections are the names of the generic collection types provided by
+// std/collections, with the number of type arguments they take
+var Collections = map[string]int{"Set": 1, "Queue": 1, "Stack": 1, "SortedMap": 2}
+
+// CollectionType is one of the Collections with its type arguments, e.g.
+// Set<int> or SortedMap<string, float>
+type CollectionType struct {
+	Name     string
+	Elements []Type
+}
+
+func (c *CollectionType) String() string {
+	elements := make([]string, len(c.Elements))
+	for i, element := range c.Elements {
+		elements[i] = element.String()
+	}
+	return c.Name + "<" + strings.Join(elements, ", ") + ">"
+}
+
+// Element returns the type of the values a for-in loop over the collection
+// takes: the elements, or the keys of a SortedMap
+func (c *CollectionType) Element() Type {
+	return c.Elements[0]
+}
+
+// ChannelType is the type of the channels that spawned code communicates
+// over, Channel<T>, which carry values of type T
+type ChannelType struct {
+	ElementType Type
+}
+
+func (c *ChannelType) String() string {
+	return "Channel<" + c.ElementType.String() + ">"
+}
+
+// TupleType is the type of a tuple, written (int, string), whose values a
+// function returns as Go multiple values
+type TupleType struct {
+	Elements []Type
+}
+
+func (t *TupleType) String() string {
+	elements := make([]string, len(t.Elements))
+	for i, element := range t.Elements {
+		elements[i] = element.String()
+	}
+	return "(" + strings.Join(elements, ", ") + ")"
+}
+
+// TypeParameter is a type parameter of a generic function, e.g. T in
+// fn first<T>(items: []T): T, as seen from the body of the function
+type TypeParameter struct {
+	Name string
+}
+
+func (p *TypeParameter) String() string {
+	return p.Name
+}
+
+// StructType is a struct type declared with `type Name = { ... }`
+type StructType struct {
+	Name string
+}
+
+func (s *StructType) String() string {
+	return s.Name
+}
+
+// EnumType is a tagged union declared with `enum Name { ... }`
+type EnumType struct {
+	Name string
+}
+
+func (e *EnumType) String() string {
+	return e.Name
+}
+
+// NamespaceType is the type of the name a module is imported as with
+// import * as io from "std/io", whose functions are called as io.readFile()
+type NamespaceType struct {
+	Module string
+}
+
+func (n *NamespaceType) String() string {
+	return "module " + n.Module
 }
 
 // Symbol represents a variable or function in the symbol table
 type Symbol struct {
-	Name string
-	Type Type
+	Name     string
+	Type     Type
+	Mutable  bool // declared with let mut
+	Constant bool // declared with const
 }
 
 // SymbolTable manages variables and their types